@@ -0,0 +1,315 @@
+// Package agent serves the processes of a host over HTTP, for collection by
+// the proctor UI or other systems. The agent scans the host's processes
+// periodically and keeps a history of the processes that started and exited.
+// Notable processes starting, such as those running a binary never seen
+// before, fire alerts sent to webhooks or commands.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/arctir/proctor/baseline"
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	// processesPath serves the host's processes as a [Snapshot], in the same
+	// form as the UI so agents can be shown on its hosts page.
+	processesPath = "/api/processes"
+	// processPath serves a single process, e.g. /api/processes/42.
+	processPath = "/api/processes/"
+	// eventsPath serves the process events in the history.
+	eventsPath = "/api/events"
+	healthPath = "/healthz"
+	// driftPath serves how the host's processes drift from the configured
+	// baseline, as a [baseline.Report].
+	driftPath = "/api/drift"
+	// the query parameter of eventsPath limiting events to those after an
+	// RFC 3339 time.
+	sinceParam = "since"
+)
+
+// Snapshot is the processes of a host at a point in time, as served on
+// [processesPath].
+type Snapshot struct {
+	Host string
+	// Labels describing the host, as configured.
+	Labels      map[string]string `json:",omitempty"`
+	LastRefresh time.Time
+	Processes   []*plib.Process
+}
+
+// Enricher annotates processes, such as with the pods running them. Enricher
+// plugins are enrichers.
+type Enricher interface {
+	Enrich(ctx context.Context, ps plib.Processes) error
+}
+
+// Agent serves the processes of a host, as found by its inspector.
+type Agent struct {
+	inspector plib.Inspector
+	// held while the inspector loads or returns processes, since it's shared
+	// between the scans and the handlers.
+	inspectorLock sync.Mutex
+	// the process events within the retention, oldest first.
+	history     []plib.ProcessEvent
+	historyLock sync.Mutex
+	retention   time.Duration
+	now         func() time.Time
+	// the baseline served on driftPath, nil when none is configured.
+	baseline *baseline.Baseline
+	// the name and labels of the host served in snapshots.
+	host   string
+	labels map[string]string
+}
+
+// New returns an agent serving the processes found by inspector.
+func New(inspector plib.Inspector) *Agent {
+	return &Agent{inspector: inspector, retention: DefaultRetention, now: time.Now}
+}
+
+// Run serves the agent until it receives SIGINT or SIGTERM, at which point it
+// shuts down gracefully. See [Agent.Serve].
+func (a *Agent) Run(conf ...Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return a.Serve(ctx, conf...)
+}
+
+// Serve scans processes and serves them until ctx is cancelled, then stops
+// accepting connections and gives in-flight requests until the configured
+// shutdown timeout to complete. A nil error is returned when the agent shut
+// down gracefully.
+func (a *Agent) Serve(ctx context.Context, conf ...Config) error {
+	config := Config{}
+	if len(conf) > 0 {
+		config = conf[len(conf)-1]
+	}
+	config = config.withDefaults()
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return err
+	}
+	a.retention = config.Retention
+	a.baseline = config.Baseline
+	a.host = config.Host
+	a.labels = config.Labels
+	if len(config.Enrichers) > 0 {
+		a.inspector = enrichedInspector{Inspector: a.inspector, enrichers: config.Enrichers}
+	}
+
+	var al *alerter
+	if len(config.Notifiers) > 0 {
+		al, err = newAlerter(config, a.processes)
+		if err != nil {
+			return err
+		}
+	}
+
+	events, err := plib.Watch(ctx, a.inspector, plib.WatchOpts{Interval: config.ScanInterval, Lock: &a.inspectorLock})
+	if err != nil {
+		return fmt.Errorf("failed scanning processes: %s", err)
+	}
+	if al != nil {
+		// the processes running when the agent starts are those Watch compares
+		// later scans with, so only the binaries of processes started after
+		// are new.
+		if ps, err := a.processes(); err == nil {
+			al.see(ps)
+		}
+		go al.notify(ctx)
+	}
+	go func() {
+		for e := range events {
+			a.record(e)
+			if al != nil {
+				for _, alert := range al.detect(e) {
+					al.enqueue(alert)
+				}
+			}
+		}
+	}()
+
+	server := &http.Server{Addr: config.Address, Handler: a.Handler(), TLSConfig: tlsConfig}
+	serveErr := make(chan error, 1)
+	go func() {
+		if tlsConfig != nil {
+			slog.Info("serving", "url", "https://"+server.Addr)
+			// the certificate is already loaded into the TLS config.
+			serveErr <- server.ListenAndServeTLS("", "")
+			return
+		}
+		slog.Info("serving", "url", "http://"+server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	slog.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed shutting down gracefully: %s", err)
+	}
+	return nil
+}
+
+// Handler returns the handler serving the agent's API.
+func (a *Agent) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(processesPath, a.handleProcesses)
+	mux.HandleFunc(processPath, a.handleProcess)
+	mux.HandleFunc(eventsPath, a.handleEvents)
+	mux.HandleFunc(driftPath, a.handleDrift)
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// handleProcesses serves the processes of the host as a [Snapshot], ordered
+// by ID.
+func (a *Agent) handleProcesses(w http.ResponseWriter, r *http.Request) {
+	ps, err := a.processes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	a.inspectorLock.Lock()
+	lastRefresh := a.inspector.GetLastLoadTime()
+	a.inspectorLock.Unlock()
+	sorted, _ := ps.Sort("pid", false)
+	host := a.host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	writeJSON(w, Snapshot{Host: host, Labels: a.labels, LastRefresh: lastRefresh, Processes: sorted})
+}
+
+// handleProcess serves the process whose ID follows [processPath].
+func (a *Agent) handleProcess(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, processPath))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid process ID: %s", err), http.StatusBadRequest)
+		return
+	}
+	ps, err := a.processes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ps[pid] == nil {
+		http.Error(w, fmt.Sprintf("process %d does not exist", pid), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, ps[pid])
+}
+
+// handleEvents serves the process events in the history, oldest first,
+// limited to those after the since query parameter when it's set.
+func (a *Agent) handleEvents(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if s := r.URL.Query().Get(sinceParam); s != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid %s, expected an RFC 3339 time: %s", sinceParam, err), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, a.events(since))
+}
+
+// handleDrift serves how the host's processes drift from the configured
+// baseline.
+func (a *Agent) handleDrift(w http.ResponseWriter, r *http.Request) {
+	if a.baseline == nil {
+		http.Error(w, "no baseline is configured", http.StatusNotFound)
+		return
+	}
+	ps, err := a.processes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, a.baseline.Evaluate(ps, a.now()))
+}
+
+// processes returns the processes last found by the inspector.
+func (a *Agent) processes() (plib.Processes, error) {
+	a.inspectorLock.Lock()
+	defer a.inspectorLock.Unlock()
+	return a.inspector.GetProcesses()
+}
+
+// enrichedInspector annotates the processes loaded by the Inspector it embeds
+// with each enricher, once per scan, so requests are served the annotations
+// of the last scan rather than enriching the processes again. It's loaded
+// under the agent's inspectorLock.
+type enrichedInspector struct {
+	plib.Inspector
+	enrichers []Enricher
+}
+
+func (e enrichedInspector) LoadProcesses() error {
+	if err := e.Inspector.LoadProcesses(); err != nil {
+		return err
+	}
+	ps, err := e.Inspector.GetProcesses()
+	if err != nil {
+		return err
+	}
+	// enrichers that fail are logged, so processes are still served without
+	// their annotations.
+	for _, en := range e.enrichers {
+		if err := en.Enrich(context.Background(), ps); err != nil {
+			slog.Warn("failed enriching processes", "error", err)
+		}
+	}
+	return nil
+}
+
+// record adds e to the history, dropping events older than the retention.
+func (a *Agent) record(e plib.ProcessEvent) {
+	a.historyLock.Lock()
+	defer a.historyLock.Unlock()
+	a.history = append(a.history, e)
+	cutoff := a.now().Add(-a.retention)
+	// events are recorded in the order they're noticed, so the expired ones
+	// are at the start.
+	expired := sort.Search(len(a.history), func(i int) bool { return !a.history[i].Time.Before(cutoff) })
+	a.history = append([]plib.ProcessEvent{}, a.history[expired:]...)
+}
+
+// events returns the events in the history after since.
+func (a *Agent) events(since time.Time) []plib.ProcessEvent {
+	a.historyLock.Lock()
+	defer a.historyLock.Unlock()
+	result := []plib.ProcessEvent{}
+	for _, e := range a.history {
+		if e.Time.After(since) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,271 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arctir/proctor/baseline"
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/policy"
+)
+
+// stubInspector returns a fixed set of processes.
+type stubInspector struct {
+	ps plib.Processes
+}
+
+func (s *stubInspector) LoadProcesses() error                  { return nil }
+func (s *stubInspector) ClearProcessCache() error              { return nil }
+func (s *stubInspector) GetProcesses() (plib.Processes, error) { return s.ps, nil }
+func (s *stubInspector) GetLastLoadTime() time.Time            { return time.Time{} }
+
+func TestHandler(t *testing.T) {
+	a := New(&stubInspector{ps: plib.Processes{
+		42: {ID: 42, CommandName: "bash"},
+		1:  {ID: 1, CommandName: "init"},
+	}})
+	h := a.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", processesPath, nil))
+	var snapshot Snapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("failed decoding snapshot: %s", err)
+	}
+	if len(snapshot.Processes) != 2 || snapshot.Processes[0].ID != 1 || snapshot.Processes[1].ID != 42 {
+		t.Logf("fail: expected processes 1 and 42, actual: %v", snapshot.Processes)
+		t.Fail()
+	}
+
+	tests := map[string]int{
+		processPath + "42":              http.StatusOK,
+		processPath + "7":               http.StatusNotFound,
+		processPath + "abc":             http.StatusBadRequest,
+		eventsPath:                      http.StatusOK,
+		eventsPath + "?since=yesterday": http.StatusBadRequest,
+		healthPath:                      http.StatusOK,
+		driftPath:                       http.StatusNotFound,
+	}
+	for path, code := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != code {
+			t.Logf("fail: expected %s to respond %d, got %d", path, code, w.Code)
+			t.Fail()
+		}
+	}
+}
+
+// stubEnricher annotates every process with its name, counting the times
+// it's called.
+type stubEnricher struct {
+	calls int
+}
+
+func (s *stubEnricher) Enrich(ctx context.Context, ps plib.Processes) error {
+	s.calls++
+	for _, p := range ps {
+		p.Annotations = map[string]string{"name": p.CommandName}
+	}
+	return nil
+}
+
+func TestHandlerLabelsAndEnrichers(t *testing.T) {
+	enricher := &stubEnricher{}
+	inspector := enrichedInspector{Inspector: &stubInspector{ps: plib.Processes{1: {ID: 1, CommandName: "init"}}}, enrichers: []Enricher{enricher}}
+	a := New(inspector)
+	a.host = "node-1"
+	a.labels = map[string]string{"topology.kubernetes.io/zone": "us-east-1a"}
+	if err := inspector.LoadProcesses(); err != nil {
+		t.Fatalf("fail: unexpected error loading processes: %s", err)
+	}
+
+	var snapshot Snapshot
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		a.Handler().ServeHTTP(w, httptest.NewRequest("GET", processesPath, nil))
+		if err := json.Unmarshal(w.Body.Bytes(), &snapshot); err != nil {
+			t.Fatalf("failed decoding snapshot: %s", err)
+		}
+	}
+	if enricher.calls != 1 {
+		t.Logf("fail: expected the processes to be enriched once per scan, not per request, actual: %d times", enricher.calls)
+		t.Fail()
+	}
+	if snapshot.Host != "node-1" || snapshot.Labels["topology.kubernetes.io/zone"] != "us-east-1a" {
+		t.Logf("fail: expected the configured host and labels, actual: %s, %v", snapshot.Host, snapshot.Labels)
+		t.Fail()
+	}
+	if len(snapshot.Processes) != 1 || snapshot.Processes[0].Annotations["name"] != "init" {
+		t.Logf("fail: expected the processes to be enriched, actual: %+v", snapshot.Processes)
+		t.Fail()
+	}
+}
+
+func TestHandleDrift(t *testing.T) {
+	ps := plib.Processes{1: {ID: 1, CommandName: "init", CommandPath: "/sbin/init", BinarySHA: strings.Repeat("a", 64)}}
+	a := New(&stubInspector{ps: ps})
+	b := baseline.Record("web", plib.Processes{}, time.Now())
+	a.baseline = &b
+
+	w := httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, httptest.NewRequest("GET", driftPath, nil))
+	var report baseline.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed decoding drift report: %s", err)
+	}
+	if report.Role != "web" || len(report.Unapproved) != 1 || report.Unapproved[0].ID != 1 {
+		t.Logf("fail: expected init to be unapproved, actual: %+v", report)
+		t.Fail()
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := New(&stubInspector{})
+	a.retention = time.Hour
+	a.now = func() time.Time { return now }
+	a.record(plib.ProcessEvent{Type: plib.ProcessStarted, Process: plib.Process{ID: 1}, Time: now.Add(-2 * time.Hour)})
+	a.record(plib.ProcessEvent{Type: plib.ProcessStarted, Process: plib.Process{ID: 2}, Time: now.Add(-30 * time.Minute)})
+	a.record(plib.ProcessEvent{Type: plib.ProcessExited, Process: plib.Process{ID: 2}, Time: now})
+
+	events := a.events(time.Time{})
+	if len(events) != 2 || events[0].Process.ID != 2 || events[1].Type != plib.ProcessExited {
+		t.Logf("fail: expected the events within the last hour, actual: %v", events)
+		t.Fail()
+	}
+
+	w := httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, httptest.NewRequest("GET", eventsPath+"?since="+now.Add(-time.Minute).Format(time.RFC3339), nil))
+	if err := json.Unmarshal(w.Body.Bytes(), &events); err != nil {
+		t.Fatalf("failed decoding events: %s", err)
+	}
+	if len(events) != 1 || events[0].Type != plib.ProcessExited {
+		t.Logf("fail: expected the events in the last minute, actual: %v", events)
+		t.Fail()
+	}
+}
+
+func TestConfigTLS(t *testing.T) {
+	tlsConfig, err := Config{}.tlsConfig()
+	if err != nil || tlsConfig != nil {
+		t.Fatalf("fail: expected plain HTTP by default, got: %v, %v", tlsConfig, err)
+	}
+	for _, c := range []Config{{TLSCert: "cert.pem"}, {ClientCA: "ca.pem"}} {
+		if _, err := c.tlsConfig(); err == nil {
+			t.Logf("fail: expected an error for %+v", c)
+			t.Fail()
+		}
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	unit, err := SystemdUnit("/usr/local/bin/proctor", []string{"agent", "--address", ":8090", "--tls-cert", "/etc/proctor/my cert.pem"})
+	if err != nil {
+		t.Fatalf("failed generating unit: %s", err)
+	}
+	expected := `ExecStart=/usr/local/bin/proctor agent --address :8090 --tls-cert "/etc/proctor/my cert.pem"`
+	if !strings.Contains(unit, expected+"\n") {
+		t.Logf("fail: expected %q in the unit, actual: %s", expected, unit)
+		t.Fail()
+	}
+	if _, err := SystemdUnit("proctor", nil); err == nil {
+		t.Log("fail: expected an error for a relative binary path")
+		t.Fail()
+	}
+}
+
+func TestDetectAlerts(t *testing.T) {
+	dir := t.TempDir()
+	writable := filepath.Join(dir, "tmp")
+	os.Mkdir(writable, 0777)
+	os.Chmod(writable, 0777)
+	p, err := policy.Parse([]byte("rules:\n  - name: no-miners\n    severity: high\n    deny: '{{ eq .CommandName \"miner\" }}'"))
+	if err != nil {
+		t.Fatalf("failed parsing policy: %s", err)
+	}
+	ps := plib.Processes{1: {ID: 1, CommandName: "init", BinarySHA: strings.Repeat("a", 64)}}
+	seenFile := filepath.Join(dir, "seen")
+	al, err := newAlerter(Config{Policy: p, SeenBinariesFile: seenFile}, func() (plib.Processes, error) { return ps, nil })
+	if err != nil {
+		t.Fatalf("failed creating alerter: %s", err)
+	}
+	al.see(ps)
+
+	miner := plib.Process{ID: 2, CommandName: "miner", CommandPath: filepath.Join(writable, "miner"), BinarySHA: strings.Repeat("b", 64), OSSpecific: plib.ProcessStat{UID: 0}}
+	alerts := al.detect(plib.ProcessEvent{Type: plib.ProcessStarted, Process: miner})
+	types := []AlertType{}
+	for _, a := range alerts {
+		types = append(types, a.Type)
+	}
+	if len(alerts) != 3 || types[0] != NewBinaryAlert || types[1] != RootWorldWritableAlert || types[2] != PolicyViolationAlert || alerts[2].Finding.Rule != "no-miners" {
+		t.Logf("fail: expected new-binary, root-world-writable, and policy-violation alerts, actual: %v", types)
+		t.Fail()
+	}
+
+	// binaries already seen, including those of the running processes, don't
+	// fire new-binary alerts, and neither do exited processes.
+	for _, e := range []plib.ProcessEvent{
+		{Type: plib.ProcessStarted, Process: plib.Process{ID: 3, CommandName: "init", BinarySHA: strings.Repeat("a", 64)}},
+		{Type: plib.ProcessExited, Process: miner},
+	} {
+		if alerts := al.detect(e); len(alerts) != 0 {
+			t.Logf("fail: expected no alerts for %+v, actual: %+v", e, alerts)
+			t.Fail()
+		}
+	}
+	restarted, _ := newAlerter(Config{AlertTypes: []AlertType{NewBinaryAlert}, SeenBinariesFile: seenFile}, nil)
+	if !restarted.seen[miner.BinarySHA] {
+		t.Log("fail: expected the seen binaries to be remembered across restarts")
+		t.Fail()
+	}
+	if _, err := newAlerter(Config{AlertTypes: []AlertType{PolicyViolationAlert}}, nil); err == nil {
+		t.Log("fail: expected an error for policy-violation alerts without a policy")
+		t.Fail()
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	received := make(chan Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var a Alert
+		json.NewDecoder(r.Body).Decode(&a)
+		received <- a
+	}))
+	defer server.Close()
+	alert := Alert{Type: NewBinaryAlert, Process: plib.Process{ID: 2}}
+
+	if err := (Webhook{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}).Notify(context.Background(), alert); err != nil {
+		t.Fatalf("fail: unexpected error sending alert: %s", err)
+	}
+	if a := <-received; a.Type != NewBinaryAlert || a.Process.ID != 2 {
+		t.Logf("fail: webhook received the wrong alert: %+v", a)
+		t.Fail()
+	}
+	if err := (Webhook{URL: server.URL}).Notify(context.Background(), alert); err == nil {
+		t.Log("fail: expected an error when the webhook responds 401")
+		t.Fail()
+	}
+
+	out := filepath.Join(t.TempDir(), "alert")
+	cmd := Command{Path: "sh", Args: []string{"-c", `cat > "$0" && test "$PROCTOR_ALERT_TYPE" = new-binary`, out}}
+	if err := cmd.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("fail: unexpected error running alert command: %s", err)
+	}
+	content, _ := os.ReadFile(out)
+	if !strings.Contains(string(content), `"Type":"new-binary"`) {
+		t.Logf("fail: expected the alert on the command's stdin, actual: %s", content)
+		t.Fail()
+	}
+}
@@ -0,0 +1,360 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/policy"
+)
+
+// AlertType identifies what fired an [Alert].
+type AlertType string
+
+const (
+	// A process started running a binary whose SHA256 hadn't been seen
+	// before.
+	NewBinaryAlert AlertType = "new-binary"
+	// A process started as root running a binary from a world-writable
+	// directory, such as /tmp, where any user could have replaced it.
+	RootWorldWritableAlert AlertType = "root-world-writable"
+	// A process started that violates a rule of the configured policy.
+	PolicyViolationAlert AlertType = "policy-violation"
+)
+
+// AlertTypes are the types of alerts the agent fires.
+var AlertTypes = []AlertType{NewBinaryAlert, RootWorldWritableAlert, PolicyViolationAlert}
+
+const (
+	// The number of alerts queued for notifiers before further alerts are
+	// dropped.
+	alertQueueSize = 100
+	// The amount of time a notifier is given to deliver an alert.
+	notifyTimeout = 30 * time.Second
+	// The environment variable holding the alert type for commands run by
+	// [Command].
+	alertTypeEnv = "PROCTOR_ALERT_TYPE"
+)
+
+// Alert is a notable process started on the host, sent to the configured
+// notifiers.
+type Alert struct {
+	Type    AlertType
+	Host    string
+	Time    time.Time
+	Summary string
+	Process plib.Process
+	// The rule violated, set for policy-violation alerts.
+	Finding *policy.Finding `json:",omitempty"`
+}
+
+// Notifier delivers alerts, such as to a webhook.
+type Notifier interface {
+	Notify(ctx context.Context, a Alert) error
+}
+
+// Webhook is a notifier POSTing alerts, as JSON, to a URL.
+type Webhook struct {
+	URL string
+	// Headers set on each request, such as Authorization.
+	Headers map[string]string
+	// The client used to send alerts. Defaults to [http.DefaultClient].
+	HTTPClient *http.Client
+}
+
+// Notify POSTs a as JSON to the webhook's URL. An error is returned when the
+// webhook doesn't respond with a 2xx status.
+func (w Webhook) Notify(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed encoding alert: %s", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed creating webhook request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	client := w.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed sending alert to webhook: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("webhook responded %s %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Command is a notifier running a command for each alert, with the alert as
+// JSON on its stdin and its type in the PROCTOR_ALERT_TYPE environment
+// variable. The command isn't run by a shell.
+type Command struct {
+	Path string
+	Args []string
+}
+
+// Notify runs the command for a, returning an error when it exits non-zero.
+func (c Command) Notify(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed encoding alert: %s", err)
+	}
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), alertTypeEnv+"="+string(a.Type))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("alert command %s failed: %s %s", c.Path, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// alerter detects the alerts of process events and queues them for its
+// notifiers.
+type alerter struct {
+	notifiers []Notifier
+	types     map[AlertType]bool
+	policy    *policy.Policy
+	// the processes started processes are evaluated against by the policy,
+	// for rules relating processes.
+	processes func() (plib.Processes, error)
+	host      string
+	// the SHA256s of the binaries seen, and the file they're recorded in so
+	// they're remembered across restarts, if any.
+	seen     map[string]bool
+	seenFile string
+	seenLock sync.Mutex
+	queue    chan Alert
+}
+
+// newAlerter returns an alerter configured by c, whose policy evaluates
+// started processes against those returned by processes.
+func newAlerter(c Config, processes func() (plib.Processes, error)) (*alerter, error) {
+	al := &alerter{
+		notifiers: c.Notifiers,
+		types:     map[AlertType]bool{},
+		policy:    c.Policy,
+		processes: processes,
+		seen:      map[string]bool{},
+		seenFile:  c.SeenBinariesFile,
+		host:      c.Host,
+		queue:     make(chan Alert, alertQueueSize),
+	}
+	types := c.AlertTypes
+	if len(types) == 0 {
+		types = AlertTypes
+	}
+	for _, t := range types {
+		if !isAlertType(t) {
+			return nil, fmt.Errorf("unknown alert type %q; expected one of %s", t, joinAlertTypes(AlertTypes))
+		}
+		al.types[t] = true
+	}
+	if al.types[PolicyViolationAlert] && c.Policy == nil && len(c.AlertTypes) > 0 {
+		return nil, fmt.Errorf("%s alerts require a policy", PolicyViolationAlert)
+	}
+	if al.seenFile != "" {
+		if err := al.loadSeen(); err != nil {
+			return nil, err
+		}
+	}
+	return al, nil
+}
+
+// loadSeen reads the SHA256s recorded in the seen file, one per line. A seen
+// file that doesn't exist yet is created when a binary is first seen.
+func (al *alerter) loadSeen() error {
+	f, err := os.Open(al.seenFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed reading seen binaries: %s", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if sha := strings.TrimSpace(scanner.Text()); sha != "" {
+			al.seen[sha] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed reading seen binaries: %s", err)
+	}
+	return nil
+}
+
+// see records the binaries of ps as seen without alerting, such as those of
+// the processes running when the agent starts.
+func (al *alerter) see(ps plib.Processes) {
+	for _, p := range ps {
+		al.markSeen(p.BinarySHA)
+	}
+}
+
+// markSeen records sha as seen, returning whether it hadn't been before.
+func (al *alerter) markSeen(sha string) bool {
+	if sha == "" {
+		return false
+	}
+	al.seenLock.Lock()
+	defer al.seenLock.Unlock()
+	if al.seen[sha] {
+		return false
+	}
+	al.seen[sha] = true
+	if al.seenFile != "" {
+		if err := appendLine(al.seenFile, sha); err != nil {
+			slog.Warn("failed recording seen binary", "sha", sha, "error", err)
+		}
+	}
+	return true
+}
+
+// detect returns the alerts fired by e.
+func (al *alerter) detect(e plib.ProcessEvent) []Alert {
+	if e.Type != plib.ProcessStarted {
+		return nil
+	}
+	p := e.Process
+	alerts := []Alert{}
+	newAlert := func(t AlertType, summary string) Alert {
+		return Alert{Type: t, Host: al.host, Time: e.Time, Summary: summary, Process: p}
+	}
+	if al.markSeen(p.BinarySHA) && al.types[NewBinaryAlert] {
+		alerts = append(alerts, newAlert(NewBinaryAlert, fmt.Sprintf("process %d (%s) is running %s, whose SHA256 %s hasn't been seen before", p.ID, p.CommandName, p.CommandPath, p.BinarySHA)))
+	}
+	if al.types[RootWorldWritableAlert] && isRoot(p) && p.CommandPath != "" {
+		dir := filepath.Dir(p.CommandPath)
+		if info, err := os.Stat(dir); err == nil && info.Mode().Perm()&0002 != 0 {
+			alerts = append(alerts, newAlert(RootWorldWritableAlert, fmt.Sprintf("process %d (%s) is running as root from the world-writable directory %s", p.ID, p.CommandName, dir)))
+		}
+	}
+	if al.types[PolicyViolationAlert] && al.policy != nil {
+		ps, err := al.processes()
+		if err != nil {
+			slog.Warn("failed evaluating policy", "pid", p.ID, "error", err)
+			return alerts
+		}
+		// the process may have been looked up after ps, so it's evaluated as
+		// it was when it started.
+		evaluated := plib.Processes{}
+		for id, other := range ps {
+			evaluated[id] = other
+		}
+		evaluated[p.ID] = &p
+		findings, err := al.policy.EvaluateProcess(p.ID, evaluated)
+		if err != nil {
+			slog.Warn("failed evaluating policy", "pid", p.ID, "error", err)
+		}
+		for i := range findings {
+			f := findings[i]
+			a := newAlert(PolicyViolationAlert, fmt.Sprintf("process %d (%s) violates %s rule %s", p.ID, p.CommandName, f.Severity, f.Rule))
+			a.Finding = &f
+			alerts = append(alerts, a)
+		}
+	}
+	return alerts
+}
+
+// enqueue queues a for the notifiers, dropping it when the queue is full so
+// slow notifiers don't hold up scanning.
+func (al *alerter) enqueue(a Alert) {
+	select {
+	case al.queue <- a:
+	default:
+		slog.Warn("dropping alert; notifiers are falling behind", "type", a.Type, "pid", a.Process.ID)
+	}
+}
+
+// notify sends queued alerts to every notifier until ctx is cancelled.
+func (al *alerter) notify(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case a := <-al.queue:
+			slog.Info("alert", "type", a.Type, "pid", a.Process.ID, "summary", a.Summary)
+			for _, n := range al.notifiers {
+				notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+				if err := n.Notify(notifyCtx, a); err != nil {
+					slog.Warn("failed sending alert", "type", a.Type, "pid", a.Process.ID, "error", err)
+				}
+				cancel()
+			}
+		}
+	}
+}
+
+// isRoot returns whether p runs as root. Only Linux processes, whose user is
+// known, may.
+func isRoot(p plib.Process) bool {
+	stat, ok := p.OSSpecific.(plib.ProcessStat)
+	return ok && stat.UID == 0
+}
+
+// isAlertType returns whether t is one of [AlertTypes].
+func isAlertType(t AlertType) bool {
+	for _, known := range AlertTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAlertTypes parses the alert type names in names, such as new-binary,
+// returning an error for names that aren't one of [AlertTypes].
+func ParseAlertTypes(names []string) ([]AlertType, error) {
+	types := []AlertType{}
+	for _, n := range names {
+		t := AlertType(strings.TrimSpace(n))
+		if !isAlertType(t) {
+			return nil, fmt.Errorf("unknown alert type %q; expected one of %s", n, joinAlertTypes(AlertTypes))
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// joinAlertTypes returns types separated by commas.
+func joinAlertTypes(types []AlertType) string {
+	names := []string{}
+	for _, t := range types {
+		names = append(names, string(t))
+	}
+	return strings.Join(names, ", ")
+}
+
+// appendLine appends line to the file at path, creating it when it doesn't
+// exist.
+func appendLine(path string, line string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/arctir/proctor/baseline"
+	"github.com/arctir/proctor/policy"
+)
+
+const (
+	// DefaultAddress is the address the agent listens on when [Config]
+	// doesn't specify one. It's only reachable from the host itself, as the
+	// processes served include their command lines; serve other interfaces
+	// with TLS and client certificates.
+	DefaultAddress = "127.0.0.1:8090"
+	// DefaultRetention is how long process events are kept in the agent's
+	// history when [Config] doesn't specify it.
+	DefaultRetention = time.Hour
+	// DefaultShutdownTimeout is how long in-flight requests are given to
+	// complete when the agent is shut down, when [Config] doesn't specify it.
+	DefaultShutdownTimeout = 10 * time.Second
+)
+
+// Config configures how the agent is served.
+type Config struct {
+	// The address to listen on, in host:port form. Defaults to
+	// [DefaultAddress].
+	Address string
+	// The paths to a PEM encoded certificate and key to serve TLS with.
+	TLSCert string
+	TLSKey  string
+	// The path to PEM encoded CA certificates. When set, clients must present
+	// a certificate signed by one of them (mutual TLS). Requires TLSCert.
+	ClientCA string
+	// The time between scans of the host's processes. Defaults to
+	// [plib.DefaultWatchInterval].
+	ScanInterval time.Duration
+	// How long process events are kept in the history. Defaults to
+	// [DefaultRetention].
+	Retention time.Duration
+	// How long in-flight requests are given to complete when shutting down.
+	// Defaults to [DefaultShutdownTimeout].
+	ShutdownTimeout time.Duration
+	// The baseline the host's processes are evaluated against on
+	// /api/drift, which responds 404 when it isn't set.
+	Baseline *baseline.Baseline
+	// Where alerts about started processes are sent. No alerts are detected
+	// when there are none.
+	Notifiers []Notifier
+	// The types of alerts fired. Defaults to [AlertTypes].
+	AlertTypes []AlertType
+	// The policy started processes are evaluated against, firing a
+	// policy-violation alert for each rule they violate.
+	Policy *policy.Policy
+	// A file recording the SHA256s of the binaries seen, so new-binary alerts
+	// aren't fired again for them after the agent restarts. When unset, the
+	// binaries running when the agent starts are the only ones seen.
+	SeenBinariesFile string
+	// The name of the host served in snapshots and alerts. Defaults to the
+	// hostname, which is the pod's name when the agent runs in Kubernetes.
+	Host string
+	// Labels describing the host, such as its node's labels when the agent
+	// runs in Kubernetes, served in snapshots.
+	Labels map[string]string
+	// Annotate the host's processes, in order, once per scan, before they're
+	// served or alerted on.
+	Enrichers []Enricher
+}
+
+// withDefaults returns c with its unset fields set to their defaults.
+func (c Config) withDefaults() Config {
+	if c.Address == "" {
+		c.Address = DefaultAddress
+	}
+	if c.Host == "" {
+		c.Host, _ = os.Hostname()
+	}
+	if c.Retention <= 0 {
+		c.Retention = DefaultRetention
+	}
+	if c.ShutdownTimeout <= 0 {
+		c.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	return c
+}
+
+// tlsConfig returns the TLS configuration the agent should be served with, or
+// nil when it should be served over plain HTTP.
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if c.TLSCert == "" && c.TLSKey != "" || c.TLSCert != "" && c.TLSKey == "" {
+		return nil, fmt.Errorf("both a TLS certificate and key must be specified")
+	}
+	if c.TLSCert == "" {
+		if c.ClientCA != "" {
+			return nil, fmt.Errorf("a TLS certificate and key must be specified to verify client certificates")
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading TLS certificate: %s", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if c.ClientCA != "" {
+		pem, err := os.ReadFile(c.ClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading client CA: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("client CA (%s) contains no PEM encoded certificates", c.ClientCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	// DefaultUnitPath is where `proctor agent install` writes the agent's
+	// systemd unit by default.
+	DefaultUnitPath = "/etc/systemd/system/proctor-agent.service"
+	// DefaultAlertHeaderPath is where `proctor agent install` writes the
+	// headers set on alert webhook requests by default, as they may hold
+	// credentials that mustn't be written to the world-readable unit.
+	DefaultAlertHeaderPath = "/etc/proctor/alert-headers"
+)
+
+var unitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description=proctor agent, serving the processes of this host
+Documentation=https://github.com/arctir/proctor
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart={{ .ExecStart }}
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// SystemdUnit returns a systemd service unit running the agent with the
+// binary at binaryPath, passing it args, such as ["agent", "--address",
+// ":8090"]. Arguments containing whitespace or quotes are quoted as systemd
+// expects.
+func SystemdUnit(binaryPath string, args []string) (string, error) {
+	if binaryPath == "" || !strings.HasPrefix(binaryPath, "/") {
+		return "", fmt.Errorf("the agent binary path (%s) must be absolute", binaryPath)
+	}
+	words := []string{quoteSystemdArg(binaryPath)}
+	for _, arg := range args {
+		words = append(words, quoteSystemdArg(arg))
+	}
+	var buf bytes.Buffer
+	if err := unitTemplate.Execute(&buf, struct{ ExecStart string }{strings.Join(words, " ")}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// quoteSystemdArg returns arg quoted for an ExecStart line when it contains
+// characters systemd would otherwise split or interpret. Specifiers (%) and
+// variables ($) are escaped.
+func quoteSystemdArg(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	arg = strings.ReplaceAll(arg, `\`, `\\`)
+	arg = strings.ReplaceAll(arg, `"`, `\"`)
+	return `"` + arg + `"`
+}
@@ -0,0 +1,224 @@
+// Package attest wraps proctor's results, such as fingerprints and snapshots,
+// in signed in-toto attestations so process-integrity evidence can be stored
+// and verified later.
+//
+// A result is the predicate of an in-toto [Statement] whose subjects are the
+// binaries, by path and SHA256, it describes. The statement is signed in a
+// DSSE envelope, either with a key or keyless using sigstore, by the [cosign]
+// package. An [Attestation] holds the envelope along with the certificate and
+// transparency log entry needed to verify it offline.
+package attest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/arctir/proctor/agent"
+	"github.com/arctir/proctor/cosign"
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	// The type of in-toto statements, as of version 1 of the specification.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// The DSSE payload type of an in-toto statement.
+	PayloadType = "application/vnd.in-toto+json"
+	// The predicate type of a statement about a [plib.Fingerprint].
+	FingerprintPredicateType = "https://github.com/arctir/proctor/attestation/fingerprint/v1"
+	// The predicate type of a statement about an [agent.Snapshot].
+	SnapshotPredicateType = "https://github.com/arctir/proctor/attestation/snapshot/v1"
+)
+
+// Statement is an in-toto statement, which makes a claim (the predicate)
+// about software artifacts (the subjects).
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// Subject is an artifact a [Statement] is about, identified by its name and
+// digests.
+type Subject struct {
+	Name string `json:"name"`
+	// The digests of the artifact keyed by algorithm, such as sha256.
+	Digest map[string]string `json:"digest"`
+}
+
+// Envelope is a DSSE envelope, which signs a payload along with its type.
+type Envelope struct {
+	PayloadType string `json:"payloadType"`
+	// The base64 encoded payload.
+	Payload    string      `json:"payload"`
+	Signatures []Signature `json:"signatures"`
+}
+
+// Signature is a signature of an [Envelope].
+type Signature struct {
+	// Identifies the key that made the signature. For signatures made with a
+	// key, this is the hex encoded SHA256 of its public key. It is empty for
+	// keyless signatures, which are identified by their certificate.
+	KeyID string `json:"keyid"`
+	// The base64 encoded signature of the envelope's pre-authentication
+	// encoding.
+	Sig string `json:"sig"`
+}
+
+// Attestation is a signed statement and what's needed to verify it later.
+type Attestation struct {
+	Envelope Envelope `json:"dsseEnvelope"`
+	// The base64 encoded PEM of the signing certificate. Only set for keyless
+	// signatures.
+	Certificate string `json:"certificate,omitempty"`
+	// The transparency log entry recording the signature. Not set when the
+	// signature wasn't recorded.
+	RekorBundle *cosign.RekorBundle `json:"rekorBundle,omitempty"`
+}
+
+// NewStatement returns a statement claiming predicate, of predicateType,
+// about subjects.
+func NewStatement(predicateType string, predicate interface{}, subjects []Subject) (Statement, error) {
+	content, err := json.Marshal(predicate)
+	if err != nil {
+		return Statement{}, fmt.Errorf("failed encoding predicate: %s", err)
+	}
+	return Statement{Type: StatementType, Subject: subjects, PredicateType: predicateType, Predicate: content}, nil
+}
+
+// ForFingerprint returns a statement about fp, whose subjects are the binaries
+// of the processes in its lineage.
+func ForFingerprint(fp plib.Fingerprint) (Statement, error) {
+	binaries := map[string]bool{}
+	subjects := []Subject{}
+	for _, e := range fp.Lineage {
+		subjects = appendBinary(subjects, binaries, e.CommandPath, e.BinarySHA)
+	}
+	return NewStatement(FingerprintPredicateType, fp, sortSubjects(subjects))
+}
+
+// ForSnapshot returns a statement about s, whose subjects are the binaries of
+// its processes. Processes whose binary path or hash is unknown, such as
+// kernel threads, aren't subjects.
+func ForSnapshot(s agent.Snapshot) (Statement, error) {
+	binaries := map[string]bool{}
+	subjects := []Subject{}
+	for _, p := range s.Processes {
+		subjects = appendBinary(subjects, binaries, p.CommandPath, p.BinarySHA)
+	}
+	return NewStatement(SnapshotPredicateType, s, sortSubjects(subjects))
+}
+
+// appendBinary appends the binary at path with the SHA256 sha to subjects,
+// unless it's unknown or already in seen.
+func appendBinary(subjects []Subject, seen map[string]bool, path string, sha string) []Subject {
+	if path == "" || len(sha) != 64 {
+		return subjects
+	}
+	key := path + "@" + sha
+	if seen[key] {
+		return subjects
+	}
+	seen[key] = true
+	return append(subjects, Subject{Name: path, Digest: map[string]string{"sha256": sha}})
+}
+
+// sortSubjects sorts subjects by name, then digest.
+func sortSubjects(subjects []Subject) []Subject {
+	sort.Slice(subjects, func(i, j int) bool {
+		if subjects[i].Name != subjects[j].Name {
+			return subjects[i].Name < subjects[j].Name
+		}
+		return subjects[i].Digest["sha256"] < subjects[j].Digest["sha256"]
+	})
+	return subjects
+}
+
+// Sign signs st in a DSSE envelope, with a key or keyless, as configured by
+// opts. See [cosign.SignBlob].
+func Sign(st Statement, opts ...cosign.SignOpts) (*Attestation, error) {
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding statement: %s", err)
+	}
+	bundle, err := cosign.SignBlob(PAE(PayloadType, payload), opts...)
+	if err != nil {
+		return nil, err
+	}
+	sig := Signature{Sig: bundle.Base64Signature}
+	if len(opts) > 0 && opts[len(opts)-1].Key != nil {
+		pub, err := x509.MarshalPKIXPublicKey(opts[len(opts)-1].Key.Public())
+		if err != nil {
+			return nil, fmt.Errorf("failed encoding public key: %s", err)
+		}
+		id := sha256.Sum256(pub)
+		sig.KeyID = hex.EncodeToString(id[:])
+	}
+	return &Attestation{
+		Envelope: Envelope{
+			PayloadType: PayloadType,
+			Payload:     base64.StdEncoding.EncodeToString(payload),
+			Signatures:  []Signature{sig},
+		},
+		Certificate: bundle.Cert,
+		RekorBundle: bundle.RekorBundle,
+	}, nil
+}
+
+// Parse parses the content of an attestation written by [Sign].
+func Parse(content []byte) (*Attestation, error) {
+	a := &Attestation{}
+	if err := json.Unmarshal(content, a); err != nil {
+		return nil, fmt.Errorf("failed parsing attestation: %s", err)
+	}
+	if len(a.Envelope.Signatures) == 0 {
+		return nil, fmt.Errorf("failed parsing attestation: no signature found")
+	}
+	return a, nil
+}
+
+// Verify verifies the signature of a, and its certificate and transparency
+// log entry as configured by opts, then returns the statement it signs. See
+// [cosign.VerifyBundle]. When a has no transparency log entry, and
+// opts.SkipTlog isn't set, the entry is looked up in Rekor.
+func Verify(a *Attestation, opts ...cosign.VerifyOpts) (*Statement, *cosign.Result, error) {
+	if a.Envelope.PayloadType != PayloadType {
+		return nil, nil, fmt.Errorf("unsupported payload type: %s", a.Envelope.PayloadType)
+	}
+	if len(a.Envelope.Signatures) == 0 {
+		return nil, nil, fmt.Errorf("attestation has no signature")
+	}
+	payload, err := base64.StdEncoding.DecodeString(a.Envelope.Payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed decoding payload: %s", err)
+	}
+	bundle := &cosign.Bundle{
+		Base64Signature: a.Envelope.Signatures[0].Sig,
+		Cert:            a.Certificate,
+		RekorBundle:     a.RekorBundle,
+	}
+	result, err := cosign.VerifyBundle(bytes.NewReader(PAE(a.Envelope.PayloadType, payload)), bundle, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed verifying attestation: %s", err)
+	}
+	st := &Statement{}
+	if err := json.Unmarshal(payload, st); err != nil {
+		return nil, nil, fmt.Errorf("failed decoding statement: %s", err)
+	}
+	if st.Type != StatementType {
+		return nil, nil, fmt.Errorf("unsupported statement type: %s", st.Type)
+	}
+	return st, result, nil
+}
+
+// PAE returns the DSSE pre-authentication encoding of payload, which is what
+// an envelope's signatures sign.
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
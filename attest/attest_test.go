@@ -0,0 +1,105 @@
+package attest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arctir/proctor/agent"
+	"github.com/arctir/proctor/cosign"
+	"github.com/arctir/proctor/plib"
+)
+
+// sha returns a fake SHA256 made of c.
+func sha(c string) string {
+	return strings.Repeat(c, 64)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	fp := plib.Fingerprint{
+		Value: sha("f"),
+		Lineage: []plib.LineageEntry{
+			{ID: 20, CommandName: "bash", CommandPath: "/usr/bin/bash", BinarySHA: sha("b")},
+			{ID: 10, CommandName: "bash", CommandPath: "/usr/bin/bash", BinarySHA: sha("b")},
+			{ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", BinarySHA: sha("a")},
+		},
+	}
+	st, err := ForFingerprint(fp)
+	if err != nil {
+		t.Fatalf("fail: unexpected error creating statement: %s", err)
+	}
+	if len(st.Subject) != 2 || st.Subject[0].Name != "/usr/bin/bash" || st.Subject[0].Digest["sha256"] != sha("b") {
+		t.Logf("fail: subjects were wrong: %+v", st.Subject)
+		t.Fail()
+	}
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a, err := Sign(st, cosign.SignOpts{Key: key, SkipTlog: true})
+	if err != nil {
+		t.Fatalf("fail: unexpected error signing statement: %s", err)
+	}
+	if a.Envelope.Signatures[0].KeyID == "" || a.Certificate != "" {
+		t.Logf("fail: attestation signed with a key was wrong: %+v", a)
+		t.Fail()
+	}
+	content, _ := json.Marshal(a)
+	parsed, err := Parse(content)
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing attestation: %s", err)
+	}
+	verified, _, err := Verify(parsed, cosign.VerifyOpts{PublicKey: &key.PublicKey, SkipTlog: true})
+	if err != nil {
+		t.Fatalf("fail: unexpected error verifying attestation: %s", err)
+	}
+	got := plib.Fingerprint{}
+	json.Unmarshal(verified.Predicate, &got)
+	if verified.PredicateType != FingerprintPredicateType || got.Value != fp.Value {
+		t.Logf("fail: verified statement was wrong: %+v", verified)
+		t.Fail()
+	}
+
+	// tampering with the payload must fail verification
+	tampered := *parsed
+	payload, _ := base64.StdEncoding.DecodeString(parsed.Envelope.Payload)
+	tampered.Envelope.Payload = base64.StdEncoding.EncodeToString([]byte(strings.Replace(string(payload), sha("f"), sha("e"), 1)))
+	if _, _, err := Verify(&tampered, cosign.VerifyOpts{PublicKey: &key.PublicKey, SkipTlog: true}); err == nil {
+		t.Log("fail: expected error verifying a tampered attestation")
+		t.Fail()
+	}
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, _, err := Verify(parsed, cosign.VerifyOpts{PublicKey: &other.PublicKey, SkipTlog: true}); err == nil {
+		t.Log("fail: expected error verifying with another key")
+		t.Fail()
+	}
+}
+
+func TestForSnapshot(t *testing.T) {
+	st, err := ForSnapshot(agent.Snapshot{
+		Host:        "web-1",
+		LastRefresh: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Processes: []*plib.Process{
+			{ID: 1, CommandPath: "/usr/lib/systemd/systemd", BinarySHA: sha("a")},
+			{ID: 2, CommandName: "kthreadd"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("fail: unexpected error creating statement: %s", err)
+	}
+	if st.Type != StatementType || st.PredicateType != SnapshotPredicateType || len(st.Subject) != 1 {
+		t.Logf("fail: snapshot statement was wrong: %+v", st)
+		t.Fail()
+	}
+}
+
+func TestPAE(t *testing.T) {
+	// the example from the DSSE specification
+	if got := string(PAE("http://example.com/HelloWorld", []byte("hello world"))); got != "DSSEv1 29 http://example.com/HelloWorld 11 hello world" {
+		t.Logf("fail: pre-authentication encoding was wrong: %s", got)
+		t.Fail()
+	}
+}
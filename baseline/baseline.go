@@ -0,0 +1,153 @@
+// Package baseline records what a host of a role, such as web or db, is
+// expected to run, and reports how the processes of a host drift from it.
+//
+// A baseline approves the binaries, by path and SHA256, processes may run
+// and lists the binaries that are expected to always be running, such as the
+// host's services. Baselines
+// are recorded from a known-good host, updated as binaries are upgraded, and
+// stored in a [Store] for the CLI, agent, and UI to evaluate hosts against.
+package baseline
+
+import (
+	"sort"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// Baseline is the binaries approved for, and expected on, hosts of a role.
+type Baseline struct {
+	Role    string
+	Created time.Time
+	Updated time.Time
+	// The SHA256 of each approved version of a binary, keyed by its path.
+	Binaries map[string][]string
+	// The paths of the binaries that must always be running, sorted.
+	Expected []string
+}
+
+// RecordOpts configures how a baseline is recorded.
+type RecordOpts struct {
+	// The paths of the binaries that must always be running. When empty, the
+	// binaries of init (PID 1) and the services it started, its direct
+	// children, are expected. Other processes, such as shells and jobs, come
+	// and go, so aren't expected unless listed.
+	Expected []string
+}
+
+// Record returns the baseline of role, approving the binaries of ps, such as
+// the processes of a known-good host, as of the time at. Processes whose
+// binary path or hash is unknown, such as kernel threads, are left out.
+func Record(role string, ps plib.Processes, at time.Time, opts ...RecordOpts) Baseline {
+	conf := RecordOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	b := Baseline{Role: role, Created: at, Binaries: map[string][]string{}, Expected: []string{}}
+	b.Approve(ps, at)
+	expected := map[string]bool{}
+	for _, path := range conf.Expected {
+		expected[path] = true
+	}
+	if len(conf.Expected) == 0 {
+		for _, p := range ps {
+			if recordable(p) && (p.ID == 1 || p.ParentProcess == 1) {
+				expected[p.CommandPath] = true
+			}
+		}
+	}
+	for path := range expected {
+		b.Expected = append(b.Expected, path)
+	}
+	sort.Strings(b.Expected)
+	return b
+}
+
+// Approve adds the binaries of ps, and versions of already approved binaries,
+// to b, such as after upgrading the host b was recorded from. The binaries
+// expected to be running are unchanged.
+func (b *Baseline) Approve(ps plib.Processes, at time.Time) {
+	if b.Binaries == nil {
+		b.Binaries = map[string][]string{}
+	}
+	for _, p := range ps {
+		if !recordable(p) || containsSHA(b.Binaries[p.CommandPath], p.BinarySHA) {
+			continue
+		}
+		b.Binaries[p.CommandPath] = append(b.Binaries[p.CommandPath], p.BinarySHA)
+		sort.Strings(b.Binaries[p.CommandPath])
+	}
+	b.Updated = at
+}
+
+// Report is how the processes of a host drift from a [Baseline].
+type Report struct {
+	Role string
+	// When the processes were evaluated.
+	Evaluated time.Time
+	// The number of processes evaluated.
+	Processes int
+	// Processes running binaries the baseline doesn't approve, ordered by ID.
+	Unapproved []plib.Process
+	// Processes running an approved binary whose hash isn't one of the
+	// approved versions, such as a binary replaced on disk, ordered by ID.
+	Changed []BinaryChange
+	// The expected binaries no process is running, sorted.
+	Missing []string
+}
+
+// BinaryChange is a process whose binary's hash isn't approved.
+type BinaryChange struct {
+	Process plib.Process
+	// The approved hashes of the binary.
+	Approved []string
+}
+
+// HasDrift returns whether the processes drift from the baseline in any way.
+func (r Report) HasDrift() bool {
+	return len(r.Unapproved) > 0 || len(r.Changed) > 0 || len(r.Missing) > 0
+}
+
+// Evaluate returns how ps, such as the processes running on a host, drift
+// from b, as of the time at. Processes whose binary path is unknown, such as
+// kernel threads, aren't evaluated. Processes whose binary couldn't be hashed
+// are only evaluated by path.
+func (b Baseline) Evaluate(ps plib.Processes, at time.Time) Report {
+	r := Report{Role: b.Role, Evaluated: at, Processes: len(ps), Unapproved: []plib.Process{}, Changed: []BinaryChange{}, Missing: []string{}}
+	running := map[string]bool{}
+	for _, p := range ps {
+		if p.CommandPath == "" {
+			continue
+		}
+		running[p.CommandPath] = true
+		approved, ok := b.Binaries[p.CommandPath]
+		switch {
+		case !ok:
+			r.Unapproved = append(r.Unapproved, *p)
+		case plib.HashKnown(p.BinarySHA) && !containsSHA(approved, p.BinarySHA):
+			r.Changed = append(r.Changed, BinaryChange{Process: *p, Approved: approved})
+		}
+	}
+	for _, path := range b.Expected {
+		if !running[path] {
+			r.Missing = append(r.Missing, path)
+		}
+	}
+	sort.Slice(r.Unapproved, func(i, j int) bool { return r.Unapproved[i].ID < r.Unapproved[j].ID })
+	sort.Slice(r.Changed, func(i, j int) bool { return r.Changed[i].Process.ID < r.Changed[j].Process.ID })
+	return r
+}
+
+// recordable returns whether p's binary is known well enough to be approved.
+func recordable(p *plib.Process) bool {
+	return p.CommandPath != "" && plib.HashKnown(p.BinarySHA)
+}
+
+func containsSHA(shas []string, sha string) bool {
+	for _, s := range shas {
+		if s == sha {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package baseline
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// sha returns a fake SHA256 made of c.
+func sha(c string) string {
+	return strings.Repeat(c, 64)
+}
+
+func TestEvaluate(t *testing.T) {
+	recorded := plib.Processes{
+		1:  {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", BinarySHA: sha("a")},
+		2:  {ID: 2, CommandName: "kthreadd"},
+		10: {ID: 10, ParentProcess: 1, CommandName: "sshd", CommandPath: "/usr/sbin/sshd", BinarySHA: sha("b")},
+		20: {ID: 20, ParentProcess: 1, CommandName: "nginx", CommandPath: "/usr/sbin/nginx", BinarySHA: sha("c")},
+		30: {ID: 30, ParentProcess: 10, CommandName: "bash", CommandPath: "/usr/bin/bash", BinarySHA: sha("f")},
+	}
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := Record("web", recorded, at)
+	// the shell is approved, but only init and the services it started are
+	// expected.
+	if !reflect.DeepEqual(b.Expected, []string{"/usr/lib/systemd/systemd", "/usr/sbin/nginx", "/usr/sbin/sshd"}) {
+		t.Logf("fail: expected the services to be expected, actual: %v", b.Expected)
+		t.Fail()
+	}
+	if _, ok := b.Binaries["/usr/bin/bash"]; !ok {
+		t.Log("fail: expected the shell to be approved")
+		t.Fail()
+	}
+	if r := Record("web", recorded, at, RecordOpts{Expected: []string{"/usr/sbin/nginx"}}); !reflect.DeepEqual(r.Expected, []string{"/usr/sbin/nginx"}) {
+		t.Logf("fail: expected only the listed binaries to be expected, actual: %v", r.Expected)
+		t.Fail()
+	}
+	if r := b.Evaluate(recorded, at); r.HasDrift() {
+		t.Logf("fail: expected no drift from the recorded processes, actual: %+v", r)
+		t.Fail()
+	}
+
+	live := plib.Processes{
+		1:   {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", BinarySHA: sha("a")},
+		3:   {ID: 3, CommandName: "kworker"},
+		10:  {ID: 10, CommandName: "sshd", CommandPath: "/usr/sbin/sshd", BinarySHA: sha("d")},
+		700: {ID: 700, CommandName: "miner", CommandPath: "/tmp/miner", BinarySHA: sha("e")},
+	}
+	r := b.Evaluate(live, at)
+	if len(r.Unapproved) != 1 || r.Unapproved[0].ID != 700 {
+		t.Logf("fail: expected process 700 to be unapproved, actual: %v", r.Unapproved)
+		t.Fail()
+	}
+	if len(r.Changed) != 1 || r.Changed[0].Process.ID != 10 || r.Changed[0].Approved[0] != sha("b") {
+		t.Logf("fail: expected sshd to be changed, actual: %v", r.Changed)
+		t.Fail()
+	}
+	if !reflect.DeepEqual(r.Missing, []string{"/usr/sbin/nginx"}) {
+		t.Logf("fail: expected nginx to be missing, actual: %v", r.Missing)
+		t.Fail()
+	}
+
+	// approving the upgraded sshd keeps the version recorded.
+	b.Approve(live, at.Add(time.Hour))
+	if !reflect.DeepEqual(b.Binaries["/usr/sbin/sshd"], []string{sha("b"), sha("d")}) || !b.Updated.Equal(at.Add(time.Hour)) {
+		t.Logf("fail: expected both versions of sshd to be approved, actual: %+v", b)
+		t.Fail()
+	}
+	if r := b.Evaluate(live, at); len(r.Changed) != 0 || len(r.Unapproved) != 0 || len(r.Missing) != 1 {
+		t.Logf("fail: expected only nginx to be missing after approving, actual: %+v", r)
+		t.Fail()
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := NewStore(t.TempDir())
+	ps := plib.Processes{1: {ID: 1, CommandPath: "/usr/lib/systemd/systemd", BinarySHA: sha("a")}}
+	for _, role := range []string{"web", "db"} {
+		if err := s.Save(Record(role, ps, time.Now())); err != nil {
+			t.Fatalf("failed saving baseline: %s", err)
+		}
+	}
+	b, err := s.Load("web")
+	if err != nil {
+		t.Fatalf("failed loading baseline: %s", err)
+	}
+	if b.Role != "web" || len(b.Binaries["/usr/lib/systemd/systemd"]) != 1 {
+		t.Logf("fail: expected the saved baseline, actual: %+v", b)
+		t.Fail()
+	}
+	roles, err := s.List()
+	if err != nil || !reflect.DeepEqual(roles, []string{"db", "web"}) {
+		t.Logf("fail: expected roles db and web, actual: %v (%v)", roles, err)
+		t.Fail()
+	}
+	if err := s.Delete("db"); err != nil {
+		t.Fatalf("failed deleting baseline: %s", err)
+	}
+	if _, err := s.Load("db"); err == nil {
+		t.Log("fail: expected an error loading a deleted baseline")
+		t.Fail()
+	}
+	if err := s.Save(Baseline{Role: "../escape"}); err == nil {
+		t.Log("fail: expected an error saving a baseline with a path as its role")
+		t.Fail()
+	}
+}
@@ -0,0 +1,49 @@
+package baseline
+
+import (
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+	"github.com/arctir/proctor/plib"
+)
+
+// The directory, within [plib.CacheDirName], role baselines are stored in by
+// default.
+const DirName = "roles"
+
+// Store saves baselines as JSON files in a directory, one per role.
+type Store struct {
+	store *plib.JSONStore
+}
+
+// NewStore returns a store of the baselines in dir. When dir is empty, the
+// store is in $XDG_DATA_HOME/plib.CacheDirName/DirName.
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = filepath.Join(xdg.DataHome, plib.CacheDirName, DirName)
+	}
+	return &Store{store: plib.NewJSONStore(dir, "baseline")}
+}
+
+// Save stores b, replacing any baseline of the same role. Roles may only
+// contain letters, numbers, '.', '_', and '-'.
+func (s *Store) Save(b Baseline) error {
+	return s.store.Save(b.Role, b)
+}
+
+// Load returns the baseline of role.
+func (s *Store) Load(role string) (Baseline, error) {
+	var b Baseline
+	err := s.store.Load(role, &b)
+	return b, err
+}
+
+// List returns the roles with a stored baseline, sorted.
+func (s *Store) List() ([]string, error) {
+	return s.store.List()
+}
+
+// Delete removes the baseline of role.
+func (s *Store) Delete(role string) error {
+	return s.store.Delete(role)
+}
@@ -0,0 +1,277 @@
+// Package bundle packages evidence about a host, such as a snapshot of its
+// processes, its details, and copies of procfs files, into a single gzip
+// compressed tar archive for attaching to incident tickets.
+//
+// Every bundle contains a [Manifest], written last as manifest.json, listing
+// the size and SHA256 of each file, along with the version of proctor that
+// created it. The SHA256 of the archive itself is returned by
+// [Writer.Digest], so recipients can check the archive they received with
+// sha256sum and check its contents with [Verify].
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arctir/proctor/provenance"
+)
+
+// ManifestName is the name of the manifest in a bundle.
+const ManifestName = "manifest.json"
+
+var (
+	// ProcessFiles are the files of each process copied from procfs by
+	// [Writer.AddProcFiles]. The environ and cmdline files are left out since
+	// they commonly hold secrets; the redacted command line of each process
+	// is part of the process snapshot.
+	ProcessFiles = []string{"status", "stat", "cgroup", "limits", "maps", "mountinfo"}
+	// HostFiles are the files describing the host copied from procfs by
+	// [Writer.AddProcFiles].
+	HostFiles = []string{"meminfo", "loadavg", "uptime", "version"}
+)
+
+// Manifest describes the contents of a bundle.
+type Manifest struct {
+	Created  time.Time
+	Hostname string
+	// The build information of the proctor binary that created the bundle.
+	Tool *provenance.BuildInfo `json:",omitempty"`
+	// The operating system and architecture proctor ran on, such as linux
+	// and amd64.
+	OS   string
+	Arch string
+	// The IDs of the processes whose procfs files were copied, sorted.
+	PIDs []int
+	// Every file in the bundle other than the manifest, in the order they
+	// were added.
+	Files []File
+	// Errors contains an entry for each file that couldn't be added, where
+	// the key is the file's name in the bundle and the value is the error
+	// message.
+	Errors map[string]string `json:",omitempty"`
+}
+
+// File is a file in a bundle.
+type File struct {
+	// The path of the file within the bundle, such as proc/1/status.
+	Name   string
+	Size   int64
+	SHA256 string
+}
+
+// Writer writes a bundle.
+type Writer struct {
+	manifest Manifest
+	digest   hash.Hash
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	names    map[string]bool
+	closed   bool
+}
+
+// NewWriter returns a writer of a bundle to w, described by m. Files are
+// added to m.Files as they're written, and the manifest is written when the
+// writer is closed. When m.Created is zero, the current time is used.
+func NewWriter(w io.Writer, m Manifest) *Writer {
+	if m.Created.IsZero() {
+		m.Created = time.Now().UTC()
+	}
+	if m.Errors == nil {
+		m.Errors = map[string]string{}
+	}
+	m.Files = []File{}
+	digest := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(w, digest))
+	return &Writer{manifest: m, digest: digest, gz: gz, tw: tar.NewWriter(gz), names: map[string]bool{}}
+}
+
+// Add writes a file named name, a slash separated path within the bundle,
+// with contents.
+func (w *Writer) Add(name string, contents []byte) error {
+	if w.closed {
+		return fmt.Errorf("failed adding %s: the bundle is closed", name)
+	}
+	if name == ManifestName || !validName(name) {
+		return fmt.Errorf("failed adding %s: invalid name for a file in a bundle", name)
+	}
+	if w.names[name] {
+		return fmt.Errorf("failed adding %s: the bundle already contains it", name)
+	}
+	if err := w.write(name, contents); err != nil {
+		return fmt.Errorf("failed adding %s: %s", name, err)
+	}
+	w.names[name] = true
+	sum := sha256.Sum256(contents)
+	w.manifest.Files = append(w.manifest.Files, File{Name: name, Size: int64(len(contents)), SHA256: hex.EncodeToString(sum[:])})
+	return nil
+}
+
+// AddJSON writes v, encoded as indented JSON, as a file named name.
+func (w *Writer) AddJSON(name string, v any) error {
+	contents, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed encoding %s: %s", name, err)
+	}
+	return w.Add(name, contents)
+}
+
+// AddError records in the manifest that the file named name couldn't be
+// collected, so the bundle is still created with the evidence that could.
+func (w *Writer) AddError(name string, err error) {
+	w.manifest.Errors[name] = err.Error()
+}
+
+// AddProcFiles copies the [HostFiles] and the [ProcessFiles] of each process
+// in pids from the procfs at procfs, such as /proc, into the proc directory
+// of the bundle. Files that can't be read, such as those of processes that
+// exited or that the caller lacks permission to read, are recorded with
+// [Writer.AddError].
+func (w *Writer) AddProcFiles(procfs string, pids []int) {
+	for _, f := range HostFiles {
+		w.addProcFile(procfs, f)
+	}
+	sorted := append([]int{}, pids...)
+	sort.Ints(sorted)
+	for _, pid := range sorted {
+		for _, f := range ProcessFiles {
+			w.addProcFile(procfs, path.Join(strconv.Itoa(pid), f))
+		}
+		w.manifest.PIDs = append(w.manifest.PIDs, pid)
+	}
+}
+
+// addProcFile copies the file at rel within the procfs at procfs.
+func (w *Writer) addProcFile(procfs string, rel string) {
+	name := path.Join("proc", rel)
+	contents, err := os.ReadFile(filepath.Join(procfs, filepath.FromSlash(rel)))
+	if err == nil {
+		err = w.Add(name, contents)
+	}
+	if err != nil {
+		w.AddError(name, err)
+	}
+}
+
+// Close writes the manifest and finishes the archive. It doesn't close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if len(w.manifest.Errors) == 0 {
+		w.manifest.Errors = nil
+	}
+	manifest, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed encoding the manifest: %s", err)
+	}
+	if err := w.write(ManifestName, manifest); err != nil {
+		return fmt.Errorf("failed writing the manifest: %s", err)
+	}
+	if err := w.tw.Close(); err != nil {
+		return fmt.Errorf("failed finishing the archive: %s", err)
+	}
+	return w.gz.Close()
+}
+
+// Manifest returns the manifest of the bundle, as written by Close.
+func (w *Writer) Manifest() Manifest {
+	return w.manifest
+}
+
+// Digest returns the hex encoded SHA256 of the archive, which is only
+// complete once the writer is closed.
+func (w *Writer) Digest() string {
+	return hex.EncodeToString(w.digest.Sum(nil))
+}
+
+// write writes a regular file named name to the archive.
+func (w *Writer) write(name string, contents []byte) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(contents)),
+		ModTime:  w.manifest.Created,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(contents)
+	return err
+}
+
+// Verify reads the bundle from r and checks that it contains exactly the
+// files listed in its manifest, with their listed sizes and SHA256s. The
+// manifest is returned when the bundle is intact.
+func Verify(r io.Reader) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading bundle: %s", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	found := map[string]File{}
+	var manifest *Manifest
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed reading bundle: %s", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("bundle contains %s, which isn't a regular file", hdr.Name)
+		}
+		if hdr.Name == ManifestName {
+			manifest = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("failed decoding the bundle's manifest: %s", err)
+			}
+			continue
+		}
+		digest := sha256.New()
+		size, err := io.Copy(digest, tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading %s from bundle: %s", hdr.Name, err)
+		}
+		found[hdr.Name] = File{Name: hdr.Name, Size: size, SHA256: hex.EncodeToString(digest.Sum(nil))}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("bundle has no %s", ManifestName)
+	}
+	for _, f := range manifest.Files {
+		actual, ok := found[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing %s, which is listed in its manifest", f.Name)
+		}
+		if actual != f {
+			return nil, fmt.Errorf("%s doesn't match the bundle's manifest; expected SHA256 %s, actual: %s", f.Name, f.SHA256, actual.SHA256)
+		}
+		delete(found, f.Name)
+	}
+	for name := range found {
+		return nil, fmt.Errorf("bundle contains %s, which isn't listed in its manifest", name)
+	}
+	return manifest, nil
+}
+
+// validName returns whether name is a clean, relative, slash separated path.
+func validName(name string) bool {
+	return name != "" && name != "." && path.Clean(name) == name && !path.IsAbs(name) && name != ".." && !strings.HasPrefix(name, "../")
+}
@@ -0,0 +1,135 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeProcfs writes a procfs with the host files and the files of process 1
+// to a temporary directory.
+func writeProcfs(t *testing.T) string {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "1"), 0755); err != nil {
+		t.Fatalf("failed setting up sample data for test: %s", err)
+	}
+	files := map[string]string{"version": "Linux version 6.1.0\n", "meminfo": "MemTotal: 1024 kB\n", "loadavg": "0.00 0.01 0.05 1/100 1\n", "uptime": "10.00 20.00\n"}
+	for _, f := range ProcessFiles {
+		files[filepath.Join("1", f)] = f + " of init\n"
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed setting up sample data for test: %s", err)
+		}
+	}
+	return dir
+}
+
+func TestWriteAndVerify(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Manifest{Hostname: "web-1"})
+	if err := w.AddJSON("snapshot.json", map[string]string{"Host": "web-1"}); err != nil {
+		t.Fatalf("fail: unexpected error adding snapshot: %s", err)
+	}
+	for _, name := range []string{"snapshot.json", ManifestName, "../escape", "/etc/passwd"} {
+		if err := w.Add(name, []byte("x")); err == nil {
+			t.Logf("fail: expected an error adding %s", name)
+			t.Fail()
+		}
+	}
+	w.AddProcFiles(writeProcfs(t), []int{99, 1})
+	if err := w.Close(); err != nil {
+		t.Fatalf("fail: unexpected error closing bundle: %s", err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	if w.Digest() != hex.EncodeToString(sum[:]) {
+		t.Logf("fail: expected digest %x, actual: %s", sum, w.Digest())
+		t.Fail()
+	}
+	m, err := Verify(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("fail: unexpected error verifying bundle: %s", err)
+	}
+	// the snapshot, 4 host files, and the files of process 1.
+	if len(m.Files) != 5+len(ProcessFiles) || m.Files[0].Name != "snapshot.json" || m.Files[1].Name != "proc/meminfo" {
+		t.Logf("fail: files were wrong: %+v", m.Files)
+		t.Fail()
+	}
+	if m.Hostname != "web-1" || m.Created.IsZero() || len(m.PIDs) != 2 || m.PIDs[0] != 1 {
+		t.Logf("fail: manifest was wrong: %+v", m)
+		t.Fail()
+	}
+	if len(m.Errors) != len(ProcessFiles) || m.Errors["proc/99/status"] == "" {
+		t.Logf("fail: expected an error for each file of process 99, actual: %v", m.Errors)
+		t.Fail()
+	}
+}
+
+// tamper returns the bundle in b with the contents of the file named name
+// replaced by contents, or added when it's not in the bundle.
+func tamper(t *testing.T, b []byte, name string, contents string) []byte {
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed reading bundle: %s", err)
+	}
+	tr := tar.NewReader(gz)
+	var out bytes.Buffer
+	gzw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gzw)
+	replaced := false
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		var data bytes.Buffer
+		data.ReadFrom(tr)
+		if hdr.Name == name {
+			data.Reset()
+			data.WriteString(contents)
+			replaced = true
+		}
+		hdr.Size = int64(data.Len())
+		tw.WriteHeader(hdr)
+		tw.Write(data.Bytes())
+	}
+	if !replaced {
+		tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(contents))})
+		tw.Write([]byte(contents))
+	}
+	tw.Close()
+	gzw.Close()
+	return out.Bytes()
+}
+
+func TestVerifyTampered(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, Manifest{})
+	w.Add("notes.txt", []byte("nothing to see"))
+	w.Close()
+
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+		err      string
+	}{
+		{"modified file", "notes.txt", "something to see", "doesn't match"},
+		{"added file", "extra.txt", "surprise", "isn't listed"},
+		{"emptied manifest", ManifestName, "{}", "isn't listed"},
+	}
+	for _, test := range tests {
+		_, err := Verify(bytes.NewReader(tamper(t, buf.Bytes(), test.file, test.contents)))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Logf("fail: %s expected an error containing %q, actual: %v", test.name, test.err, err)
+			t.Fail()
+		}
+	}
+}
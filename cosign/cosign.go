@@ -0,0 +1,349 @@
+// cosign is a package that verifies the signatures cosign creates for
+// release artifacts (blobs), along with their entries in the Rekor
+// transparency log. It supports signatures made with a key pair and keyless
+// signatures, whose certificate identifies the signer. It can also sign
+// blobs, producing the same bundles cosign does.
+//
+// Signing and verification are implemented against cosign's file formats and
+// the REST APIs of Fulcio and Rekor directly, without depending on the
+// sigstore libraries.
+package cosign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// the Fulcio certificate extension containing the OIDC issuer that
+	// authenticated the signer, as a DER encoded string.
+	oidcIssuerOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+	// the deprecated Fulcio certificate extension containing the OIDC issuer
+	// as raw bytes.
+	legacyOIDCIssuerOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+)
+
+// VerifyOpts configures how signatures are verified. Every field is optional.
+type VerifyOpts struct {
+	// Verify signatures with this public key, rather than the certificate
+	// accompanying the signature. Set for signatures made with a key pair.
+	PublicKey crypto.PublicKey
+	// The roots a signing certificate must chain to, such as the Fulcio roots
+	// of the public sigstore instance. When nil, the certificate chain is not
+	// verified and Result.CertificateVerified is false, so the certificate,
+	// and the Signer it names, aren't trusted. See Result.Trusted.
+	Roots *x509.CertPool
+	// Intermediate certificates used to build the chain to Roots.
+	Intermediates *x509.CertPool
+	// The identity the signing certificate must be issued to, such as an
+	// email address or the URI of a CI workflow. When set, signatures made
+	// with a key pair, or a certificate issued to another identity, fail.
+	Identity string
+	// The OIDC issuer that must have authenticated the signer, such as
+	// https://token.actions.githubusercontent.com. When set, signatures made
+	// with a key pair, or a certificate from another issuer, fail.
+	Issuer string
+	// The Rekor instance to look up transparency log entries in. Defaults to
+	// [DefaultRekorURL].
+	RekorURL string
+	// The key Rekor signs log entries with. When nil, it is retrieved from
+	// RekorURL.
+	RekorPublicKey crypto.PublicKey
+	// Skip looking up and verifying the transparency log entry.
+	SkipTlog bool
+	// The client used to reach Rekor. Defaults to a client with a 30 second
+	// timeout.
+	HTTPClient *http.Client
+}
+
+// Result describes a verified signature. A Result is only returned when the
+// signature is valid for the artifact; the remaining fields report what else
+// could be verified.
+type Result struct {
+	// The SHA256 of the artifact, hex encoded.
+	Digest string
+	// The identity the signing certificate was issued to, such as an email
+	// address or the URI of a CI workflow. Empty for signatures made with a
+	// key pair.
+	Signer string
+	// The OIDC issuer that authenticated Signer, such as
+	// https://token.actions.githubusercontent.com.
+	Issuer string
+	// Whether the signing certificate chains to VerifyOpts.Roots.
+	CertificateVerified bool
+	// Whether the signature was made by a trusted signer: with
+	// VerifyOpts.PublicKey, or with a certificate chaining to
+	// VerifyOpts.Roots. Anyone can create a certificate naming any Signer, so
+	// when false, the signature only proves the artifact is what the holder
+	// of the certificate's key signed, not who that is.
+	Trusted bool
+	// Whether a transparency log entry for the signature was found and its
+	// signed entry timestamp verified.
+	TlogVerified bool
+	// The index of the signature's transparency log entry.
+	LogIndex int64
+	// The time the signature was added to the transparency log, which is
+	// when it is considered to have been made.
+	IntegratedTime time.Time
+}
+
+// Bundle is the file written by `cosign sign-blob --bundle`, which contains
+// the signature, certificate, and transparency log entry of an artifact.
+type Bundle struct {
+	Base64Signature string
+	// The base64 encoded PEM of the signing certificate. Empty when signed
+	// with a key pair.
+	Cert        string
+	RekorBundle *RekorBundle
+}
+
+// RekorBundle is the transparency log entry included in a [Bundle].
+type RekorBundle struct {
+	SignedEntryTimestamp string
+	Payload              RekorPayload
+}
+
+// RekorPayload is the content of a transparency log entry covered by its
+// signed entry timestamp. The field order is that of the canonical JSON the
+// timestamp signs.
+type RekorPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// ParseBundle parses the content of a cosign bundle.
+func ParseBundle(content []byte) (*Bundle, error) {
+	b := &Bundle{}
+	if err := json.Unmarshal(content, b); err != nil {
+		return nil, fmt.Errorf("failed parsing cosign bundle: %s", err)
+	}
+	if b.Base64Signature == "" {
+		return nil, fmt.Errorf("failed parsing cosign bundle: no signature found")
+	}
+	return b, nil
+}
+
+// VerifyBundle verifies the artifact read from artifact against a cosign
+// bundle. The transparency log entry in the bundle is verified rather than
+// looked up in Rekor. See [VerifyBlob].
+func VerifyBundle(artifact io.Reader, bundle *Bundle, opts ...VerifyOpts) (*Result, error) {
+	var cert []byte
+	if bundle.Cert != "" {
+		cert = []byte(bundle.Cert)
+	}
+	return verify(artifact, []byte(bundle.Base64Signature), cert, bundle.RekorBundle, resolveOpts(opts))
+}
+
+// VerifyBlob verifies that sig is a valid signature of the artifact read from
+// artifact. sig is the content of the .sig file written by
+// `cosign sign-blob`. cert is the content of the signing certificate (the
+// .pem file, either PEM or base64 encoded PEM); it may be nil when
+// opts.PublicKey is set.
+//
+// Unless opts.SkipTlog is set, the signature's entry in the Rekor transparency
+// log is looked up and verified. The certificate is checked to have been
+// valid when the entry was made, as keyless certificates are short-lived. An
+// error is returned when the signature is invalid or any check fails.
+func VerifyBlob(artifact io.Reader, sig []byte, cert []byte, opts ...VerifyOpts) (*Result, error) {
+	return verify(artifact, sig, cert, nil, resolveOpts(opts))
+}
+
+func resolveOpts(opts []VerifyOpts) VerifyOpts {
+	conf := VerifyOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if conf.RekorURL == "" {
+		conf.RekorURL = DefaultRekorURL
+	}
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = &http.Client{Timeout: rekorTimeout}
+	}
+	return conf
+}
+
+// verify checks the signature of artifact, then its certificate and
+// transparency log entry. When rekorBundle is nil, the entry is looked up in
+// Rekor.
+func verify(artifact io.Reader, b64Sig []byte, certContent []byte, rekorBundle *RekorBundle, conf VerifyOpts) (*Result, error) {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b64Sig)))
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding signature: %s", err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, artifact); err != nil {
+		return nil, fmt.Errorf("failed reading artifact: %s", err)
+	}
+	digest := h.Sum(nil)
+	result := &Result{Digest: hex.EncodeToString(digest)}
+
+	pub := conf.PublicKey
+	var cert *x509.Certificate
+	if pub == nil {
+		if certContent == nil {
+			return nil, fmt.Errorf("a certificate or public key is required to verify the signature")
+		}
+		cert, err = parseCertificate(certContent)
+		if err != nil {
+			return nil, err
+		}
+		pub = cert.PublicKey
+		result.Signer = certificateIdentity(cert)
+		result.Issuer = certificateIssuer(cert)
+	}
+	if err := verifySignature(pub, digest, sig); err != nil {
+		return nil, err
+	}
+	if conf.Identity != "" || conf.Issuer != "" {
+		if cert == nil {
+			return nil, fmt.Errorf("the signature has no certificate to check the signer's identity and issuer against")
+		}
+		if conf.Identity != "" && result.Signer != conf.Identity {
+			return nil, fmt.Errorf("signing certificate was issued to %s, not %s", result.Signer, conf.Identity)
+		}
+		if conf.Issuer != "" && result.Issuer != conf.Issuer {
+			return nil, fmt.Errorf("signing certificate was issued by %s, not %s", result.Issuer, conf.Issuer)
+		}
+	}
+
+	if !conf.SkipTlog {
+		entry, err := findTlogEntry(conf, rekorBundle, result.Digest, strings.TrimSpace(string(b64Sig)), cert, pub)
+		if err != nil {
+			return nil, err
+		}
+		result.TlogVerified = true
+		result.LogIndex = entry.LogIndex
+		result.IntegratedTime = time.Unix(entry.IntegratedTime, 0).UTC()
+	}
+
+	if cert != nil {
+		// the certificate must have been valid when the signature was made.
+		// Without a log entry, it is checked at the time it was issued.
+		signedAt := result.IntegratedTime
+		if signedAt.IsZero() {
+			signedAt = cert.NotBefore
+		}
+		if signedAt.Before(cert.NotBefore) || signedAt.After(cert.NotAfter) {
+			return nil, fmt.Errorf("signing certificate was not valid at %s, it is valid from %s to %s", signedAt, cert.NotBefore, cert.NotAfter)
+		}
+		if conf.Roots != nil {
+			_, err := cert.Verify(x509.VerifyOptions{
+				Roots:         conf.Roots,
+				Intermediates: conf.Intermediates,
+				CurrentTime:   signedAt,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed verifying signing certificate chain: %s", err)
+			}
+			result.CertificateVerified = true
+		}
+	}
+	result.Trusted = cert == nil || result.CertificateVerified
+	return result, nil
+}
+
+// verifySignature verifies that sig is the signature of an artifact with the
+// SHA256 digest, as made by cosign with the private key of pub.
+func verifySignature(pub crypto.PublicKey, digest []byte, sig []byte) error {
+	var valid bool
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(k, digest, sig)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, sig) == nil
+	case ed25519.PublicKey:
+		// ed25519 signs the artifact itself, which isn't retained, so it
+		// cannot be verified from the digest alone.
+		return fmt.Errorf("ed25519 signatures are not supported")
+	default:
+		return fmt.Errorf("unsupported public key type: %T", pub)
+	}
+	if !valid {
+		return fmt.Errorf("signature is not valid for the artifact")
+	}
+	return nil
+}
+
+// parseCertificate parses a PEM encoded certificate, which cosign may also
+// base64 encode.
+func parseCertificate(content []byte) (*x509.Certificate, error) {
+	content = []byte(strings.TrimSpace(string(content)))
+	if !strings.HasPrefix(string(content), "-----BEGIN") {
+		decoded, err := base64.StdEncoding.DecodeString(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding certificate: %s", err)
+		}
+		content = decoded
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("failed decoding certificate: no PEM block found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing certificate: %s", err)
+	}
+	return cert, nil
+}
+
+// ParsePublicKey parses a PEM encoded public key, such as the cosign.pub file
+// written by `cosign generate-key-pair`.
+func ParsePublicKey(content []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("failed decoding public key: no PEM block found")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing public key: %s", err)
+	}
+	return pub, nil
+}
+
+// certificateIdentity returns the identity a Fulcio certificate was issued
+// to, which is stored as its subject alternative name.
+func certificateIdentity(cert *x509.Certificate) string {
+	switch {
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	}
+	return cert.Subject.CommonName
+}
+
+// certificateIssuer returns the OIDC issuer recorded in a Fulcio certificate,
+// or an empty string when not present.
+func certificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidcIssuerOID) {
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		}
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(legacyOIDCIssuerOID) {
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,346 @@
+package cosign
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	testIdentity = "jane@example.com"
+	testIssuer   = "https://accounts.example.com"
+)
+
+var testArtifact = []byte("proctor binary")
+
+func TestVerifyBundle(t *testing.T) {
+	signer := newTestSigner(t)
+	rekorKey, bundle := newTestBundle(t, signer)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(signer.cert)
+	result, err := VerifyBundle(bytes.NewReader(testArtifact), bundle, VerifyOpts{Roots: roots, RekorPublicKey: &rekorKey.PublicKey})
+	if err != nil {
+		t.Fatalf("fail: unexpected error verifying bundle: %s", err)
+	}
+	if result.Signer != testIdentity || result.Issuer != testIssuer || !result.CertificateVerified || !result.Trusted || !result.TlogVerified || result.LogIndex != 42 {
+		t.Logf("fail: verification result was wrong: %+v", result)
+		t.Fail()
+	}
+
+	result, err = VerifyBundle(bytes.NewReader(testArtifact), bundle, VerifyOpts{Roots: roots, RekorPublicKey: &rekorKey.PublicKey, Identity: testIdentity, Issuer: testIssuer})
+	if err != nil || !result.Trusted {
+		t.Logf("fail: unexpected error verifying bundle signed by the expected identity: %v", err)
+		t.Fail()
+	}
+	for _, conf := range []VerifyOpts{
+		{Roots: roots, RekorPublicKey: &rekorKey.PublicKey, Identity: "mallory@example.com"},
+		{Roots: roots, RekorPublicKey: &rekorKey.PublicKey, Identity: testIdentity, Issuer: "https://other.example.com"},
+	} {
+		if _, err := VerifyBundle(bytes.NewReader(testArtifact), bundle, conf); err == nil {
+			t.Logf("fail: expected error verifying bundle against identity (%s) and issuer (%s)", conf.Identity, conf.Issuer)
+			t.Fail()
+		}
+	}
+
+	if _, err := VerifyBundle(strings.NewReader("tampered"), bundle, VerifyOpts{RekorPublicKey: &rekorKey.PublicKey}); err == nil {
+		t.Log("fail: expected error verifying a tampered artifact")
+		t.Fail()
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := VerifyBundle(bytes.NewReader(testArtifact), bundle, VerifyOpts{RekorPublicKey: &otherKey.PublicKey}); err == nil {
+		t.Log("fail: expected error verifying a log entry signed by another key")
+		t.Fail()
+	}
+
+	// a log entry recording the signature under another certificate doesn't
+	// vouch for this one.
+	otherDER, _ := x509.CreateCertificate(rand.Reader, signer.cert, signer.cert, &signer.key.PublicKey, signer.key)
+	tampered := *bundle
+	tampered.Cert = base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: otherDER}))
+	if _, err := VerifyBundle(bytes.NewReader(testArtifact), &tampered, VerifyOpts{RekorPublicKey: &rekorKey.PublicKey}); err == nil {
+		t.Log("fail: expected error verifying a log entry recording another certificate")
+		t.Fail()
+	}
+}
+
+func TestVerifyBlobRekor(t *testing.T) {
+	signer := newTestSigner(t)
+	rekorKey, bundle := newTestBundle(t, signer)
+	rekorPub, _ := x509.MarshalPKIXPublicKey(&rekorKey.PublicKey)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/index/retrieve":
+			w.Write([]byte(`["abc123"]`))
+		case "/api/v1/log/entries/abc123":
+			entry := rekorEntry{
+				Body:           bundle.RekorBundle.Payload.Body,
+				IntegratedTime: bundle.RekorBundle.Payload.IntegratedTime,
+				LogID:          bundle.RekorBundle.Payload.LogID,
+				LogIndex:       bundle.RekorBundle.Payload.LogIndex,
+			}
+			entry.Verification.SignedEntryTimestamp = bundle.RekorBundle.SignedEntryTimestamp
+			json.NewEncoder(w).Encode(map[string]rekorEntry{"abc123": entry})
+		case "/api/v1/log/publicKey":
+			pem.Encode(w, &pem.Block{Type: "PUBLIC KEY", Bytes: rekorPub})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// cosign writes the certificate as base64 encoded PEM
+	cert := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signer.cert.Raw}))
+	result, err := VerifyBlob(bytes.NewReader(testArtifact), []byte(bundle.Base64Signature+"\n"), []byte(cert), VerifyOpts{RekorURL: server.URL})
+	if err != nil {
+		t.Fatalf("fail: unexpected error verifying blob: %s", err)
+	}
+	if !result.TlogVerified || result.CertificateVerified || result.Trusted || result.Signer != testIdentity {
+		t.Logf("fail: verification result was wrong: %+v", result)
+		t.Fail()
+	}
+}
+
+func TestVerifyBlobPublicKey(t *testing.T) {
+	signer := newTestSigner(t)
+	pub, _ := x509.MarshalPKIXPublicKey(&signer.key.PublicKey)
+	key, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing public key: %s", err)
+	}
+
+	result, err := VerifyBlob(bytes.NewReader(testArtifact), []byte(signer.sign(t, testArtifact)), nil, VerifyOpts{PublicKey: key, SkipTlog: true})
+	if err != nil {
+		t.Fatalf("fail: unexpected error verifying blob with public key: %s", err)
+	}
+	if result.Signer != "" || result.TlogVerified || !result.Trusted {
+		t.Logf("fail: verification result was wrong: %+v", result)
+		t.Fail()
+	}
+
+	if _, err := VerifyBlob(bytes.NewReader(testArtifact), []byte(signer.sign(t, testArtifact)), nil, VerifyOpts{PublicKey: key, SkipTlog: true, Identity: testIdentity}); err == nil {
+		t.Log("fail: expected error checking the identity of a signature made with a key pair")
+		t.Fail()
+	}
+}
+
+// testSigner holds a key and a self-signed certificate mimicking one issued
+// by Fulcio.
+type testSigner struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestSigner(t *testing.T) testSigner {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("fail: error generating key: %s", err)
+	}
+	issuer, _ := asn1.Marshal(testIssuer)
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: "sigstore"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		EmailAddresses:  []string{testIdentity},
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{{Id: oidcIssuerOID, Value: issuer}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("fail: error creating certificate: %s", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return testSigner{key: key, cert: cert}
+}
+
+// sign returns the base64 encoded signature of content, as written by cosign.
+func (s testSigner) sign(t *testing.T, content []byte) string {
+	digest := sha256.Sum256(content)
+	sig, err := ecdsa.SignASN1(rand.Reader, s.key, digest[:])
+	if err != nil {
+		t.Fatalf("fail: error signing: %s", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+// newTestBundle signs testArtifact with signer and returns a bundle whose log
+// entry is signed by the returned Rekor key.
+func newTestBundle(t *testing.T, signer testSigner) (*ecdsa.PrivateKey, *Bundle) {
+	rekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("fail: error generating key: %s", err)
+	}
+	sig := signer.sign(t, testArtifact)
+	digest := sha256.Sum256(testArtifact)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signer.cert.Raw})
+	body := fmt.Sprintf(`{"apiVersion":"0.0.1","kind":"hashedrekord","spec":{"data":{"hash":{"algorithm":"sha256","value":"%s"}},"signature":{"content":"%s","publicKey":{"content":"%s"}}}}`, hex.EncodeToString(digest[:]), sig, base64.StdEncoding.EncodeToString(certPEM))
+	payload := RekorPayload{
+		Body:           base64.StdEncoding.EncodeToString([]byte(body)),
+		IntegratedTime: time.Now().Unix(),
+		LogID:          "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d",
+		LogIndex:       42,
+	}
+	canonical, _ := json.Marshal(payload)
+	setDigest := sha256.Sum256(canonical)
+	set, err := ecdsa.SignASN1(rand.Reader, rekorKey, setDigest[:])
+	if err != nil {
+		t.Fatalf("fail: error signing log entry: %s", err)
+	}
+	return rekorKey, &Bundle{
+		Base64Signature: sig,
+		Cert:            base64.StdEncoding.EncodeToString(certPEM),
+		RekorBundle: &RekorBundle{
+			SignedEntryTimestamp: base64.StdEncoding.EncodeToString(set),
+			Payload:              payload,
+		},
+	}
+}
+
+func TestSignBlob(t *testing.T) {
+	// a CA standing in for Fulcio's
+	ca := newTestSigner(t)
+	caTemplate := *ca.cert
+	caTemplate.IsCA, caTemplate.BasicConstraintsValid = true, true
+	caTemplate.KeyUsage |= x509.KeyUsageCertSign
+	caDER, _ := x509.CreateCertificate(rand.Reader, &caTemplate, &caTemplate, &ca.key.PublicKey, ca.key)
+	ca.cert, _ = x509.ParseCertificate(caDER)
+	rekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("fail: error generating key: %s", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/signingCert":
+			req := struct {
+				PublicKeyRequest struct {
+					PublicKey struct {
+						Content string `json:"content"`
+					} `json:"publicKey"`
+				} `json:"publicKeyRequest"`
+			}{}
+			json.NewDecoder(r.Body).Decode(&req)
+			pub, err := ParsePublicKey([]byte(req.PublicKeyRequest.PublicKey.Content))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			template := *ca.cert
+			template.SerialNumber = big.NewInt(2)
+			template.IsCA = false
+			der, _ := x509.CreateCertificate(rand.Reader, &template, ca.cert, pub, ca.key)
+			leaf := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+			fmt.Fprintf(w, `{"signedCertificateEmbeddedSct":{"chain":{"certificates":[%q]}}}`, leaf)
+		case "/api/v1/log/entries":
+			body, _ := io.ReadAll(r.Body)
+			payload := RekorPayload{
+				Body:           base64.StdEncoding.EncodeToString(body),
+				IntegratedTime: time.Now().Unix(),
+				LogID:          "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d",
+				LogIndex:       7,
+			}
+			canonical, _ := json.Marshal(payload)
+			digest := sha256.Sum256(canonical)
+			set, _ := ecdsa.SignASN1(rand.Reader, rekorKey, digest[:])
+			entry := rekorEntry{Body: payload.Body, IntegratedTime: payload.IntegratedTime, LogID: payload.LogID, LogIndex: payload.LogIndex}
+			entry.Verification.SignedEntryTimestamp = base64.StdEncoding.EncodeToString(set)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]rekorEntry{"def456": entry})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// a JWT whose payload is {"sub":"1234","email":"jane@example.com"}
+	token := "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1234","email":"`+testIdentity+`"}`)) + ".sig"
+	bundle, err := SignBlob(testArtifact, SignOpts{IdentityToken: token, FulcioURL: server.URL, RekorURL: server.URL})
+	if err != nil {
+		t.Fatalf("fail: unexpected error signing keyless: %s", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	result, err := VerifyBundle(bytes.NewReader(testArtifact), bundle, VerifyOpts{Roots: roots, RekorPublicKey: &rekorKey.PublicKey})
+	if err != nil {
+		t.Fatalf("fail: unexpected error verifying keyless bundle: %s", err)
+	}
+	if result.Signer != testIdentity || !result.CertificateVerified || !result.TlogVerified || result.LogIndex != 7 {
+		t.Logf("fail: verification result was wrong: %+v", result)
+		t.Fail()
+	}
+
+	if _, err := SignBlob(testArtifact); err == nil {
+		t.Log("fail: expected error signing without a key or identity token")
+		t.Fail()
+	}
+	bundle, err = SignBlob(testArtifact, SignOpts{Key: ca.key, SkipTlog: true})
+	if err != nil {
+		t.Fatalf("fail: unexpected error signing with a key: %s", err)
+	}
+	if _, err := VerifyBundle(bytes.NewReader(testArtifact), bundle, VerifyOpts{PublicKey: &ca.key.PublicKey, SkipTlog: true}); err != nil {
+		t.Logf("fail: unexpected error verifying bundle signed with a key: %s", err)
+		t.Fail()
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	if _, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil); err != nil {
+		t.Logf("fail: unexpected error parsing pkcs8 key: %s", err)
+		t.Fail()
+	}
+
+	// encrypt the key as `cosign generate-key-pair` does
+	password := []byte("hunter2")
+	ek := encryptedKey{}
+	ek.KDF.Name = "scrypt"
+	ek.KDF.Params.N, ek.KDF.Params.R, ek.KDF.Params.P = 1024, 8, 1
+	ek.KDF.Salt = []byte("0123456789abcdef0123456789abcdef")
+	ek.Cipher.Name = "nacl/secretbox"
+	ek.Cipher.Nonce = []byte("0123456789abcdef01234567")
+	derived, _ := scrypt.Key(password, ek.KDF.Salt, 1024, 8, 1, 32)
+	var nonce [24]byte
+	var secret [32]byte
+	copy(nonce[:], ek.Cipher.Nonce)
+	copy(secret[:], derived)
+	ek.Ciphertext = secretbox.Seal(nil, der, &nonce, &secret)
+	content, _ := json.Marshal(ek)
+	encrypted := pem.EncodeToMemory(&pem.Block{Type: encryptedSigstoreKeyType, Bytes: content})
+
+	signer, err := ParsePrivateKey(encrypted, password)
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing encrypted key: %s", err)
+	}
+	if !key.PublicKey.Equal(signer.Public()) {
+		t.Log("fail: decrypted key was wrong")
+		t.Fail()
+	}
+	if _, err := ParsePrivateKey(encrypted, []byte("wrong")); err == nil {
+		t.Log("fail: expected error decrypting key with the wrong password")
+		t.Fail()
+	}
+}
@@ -0,0 +1,285 @@
+package cosign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// The Rekor instance of the public sigstore deployment.
+	DefaultRekorURL = "https://rekor.sigstore.dev"
+	// The amount of time to wait for Rekor to respond.
+	rekorTimeout = 30 * time.Second
+	// The maximum number of log entries inspected when looking up an
+	// artifact's signature.
+	maxRekorEntries = 25
+)
+
+// rekorEntry is a transparency log entry as returned by Rekor's API.
+type rekorEntry struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+	Verification   struct {
+		SignedEntryTimestamp string `json:"signedEntryTimestamp"`
+	} `json:"verification"`
+}
+
+// hashedRekord is the body of a transparency log entry recording the
+// signature of an artifact's digest.
+type hashedRekord struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content   string `json:"content"`
+			PublicKey struct {
+				Content string `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// findTlogEntry returns the verified transparency log entry recording b64Sig
+// as the signature of the artifact with the hex encoded SHA256 digest, made by
+// the holder of cert, or of pub when cert is nil. The entry is taken from
+// bundle when set, otherwise it is looked up in Rekor.
+func findTlogEntry(conf VerifyOpts, bundle *RekorBundle, digest string, b64Sig string, cert *x509.Certificate, pub crypto.PublicKey) (*RekorPayload, error) {
+	candidates := []RekorBundle{}
+	if bundle != nil {
+		candidates = append(candidates, *bundle)
+	} else {
+		entries, err := searchRekor(conf, digest)
+		if err != nil {
+			return nil, err
+		}
+		candidates = entries
+	}
+
+	for _, c := range candidates {
+		if !entryRecords(c.Payload.Body, digest, b64Sig, cert, pub) {
+			continue
+		}
+		rekorKey := conf.RekorPublicKey
+		if rekorKey == nil {
+			var err error
+			rekorKey, err = fetchRekorPublicKey(conf)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := verifySignedEntryTimestamp(rekorKey, c); err != nil {
+			return nil, err
+		}
+		return &c.Payload, nil
+	}
+	return nil, fmt.Errorf("no transparency log entry found for the signature of artifact (sha256:%s)", digest)
+}
+
+// entryRecords returns whether the base64 encoded body of a log entry records
+// b64Sig as the signature of the artifact with digest, made by the holder of
+// cert, or of pub when cert is nil. Otherwise, an entry recording the same
+// signature under another certificate or key would vouch for it.
+func entryRecords(body string, digest string, b64Sig string, cert *x509.Certificate, pub crypto.PublicKey) bool {
+	decoded, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return false
+	}
+	rekord := hashedRekord{}
+	if err := json.Unmarshal(decoded, &rekord); err != nil {
+		return false
+	}
+	return rekord.Kind == "hashedrekord" &&
+		rekord.Spec.Data.Hash.Algorithm == "sha256" &&
+		strings.EqualFold(rekord.Spec.Data.Hash.Value, digest) &&
+		rekord.Spec.Signature.Content == b64Sig &&
+		entryVerifierMatches(rekord.Spec.Signature.PublicKey.Content, cert, pub)
+}
+
+// entryVerifierMatches returns whether content, the base64 encoded PEM
+// certificate or public key recorded in a log entry, is cert, or is or holds
+// pub when cert is nil.
+func entryVerifierMatches(content string, cert *x509.Certificate, pub crypto.PublicKey) bool {
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return false
+	}
+	block, _ := pem.Decode(decoded)
+	if block == nil {
+		return false
+	}
+	var entryKey crypto.PublicKey
+	switch block.Type {
+	case "CERTIFICATE":
+		if cert != nil {
+			return bytes.Equal(block.Bytes, cert.Raw)
+		}
+		entryCert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return false
+		}
+		entryKey = entryCert.PublicKey
+	case "PUBLIC KEY":
+		if cert != nil {
+			return false
+		}
+		entryKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+	k, ok := entryKey.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(pub)
+}
+
+// verifySignedEntryTimestamp verifies that Rekor, whose key is rekorKey,
+// signed the log entry in bundle, proving the entry was added to the log.
+func verifySignedEntryTimestamp(rekorKey crypto.PublicKey, bundle RekorBundle) error {
+	set, err := base64.StdEncoding.DecodeString(bundle.SignedEntryTimestamp)
+	if err != nil {
+		return fmt.Errorf("failed decoding signed entry timestamp: %s", err)
+	}
+	// the timestamp signs the canonical JSON of the payload, whose keys are
+	// sorted and which has no insignificant whitespace.
+	canonical, err := json.Marshal(bundle.Payload)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(canonical)
+	if err := verifySignature(rekorKey, digest[:], set); err != nil {
+		return fmt.Errorf("failed verifying signed entry timestamp of log entry %d: %s", bundle.Payload.LogIndex, err)
+	}
+	return nil
+}
+
+// searchRekor returns the log entries recording a signature of the artifact
+// with the hex encoded SHA256 digest.
+func searchRekor(conf VerifyOpts, digest string) ([]RekorBundle, error) {
+	query, _ := json.Marshal(map[string]string{"hash": "sha256:" + digest})
+	uuids := []string{}
+	err := rekorRequest(conf, http.MethodPost, "/api/v1/index/retrieve", query, &uuids)
+	if err != nil {
+		return nil, fmt.Errorf("failed searching transparency log: %s", err)
+	}
+	if len(uuids) > maxRekorEntries {
+		uuids = uuids[:maxRekorEntries]
+	}
+
+	bundles := []RekorBundle{}
+	for _, uuid := range uuids {
+		entries := map[string]rekorEntry{}
+		if err := rekorRequest(conf, http.MethodGet, "/api/v1/log/entries/"+uuid, nil, &entries); err != nil {
+			return nil, fmt.Errorf("failed retrieving transparency log entry (%s): %s", uuid, err)
+		}
+		for _, e := range entries {
+			bundles = append(bundles, RekorBundle{
+				SignedEntryTimestamp: e.Verification.SignedEntryTimestamp,
+				Payload: RekorPayload{
+					Body:           e.Body,
+					IntegratedTime: e.IntegratedTime,
+					LogID:          e.LogID,
+					LogIndex:       e.LogIndex,
+				},
+			})
+		}
+	}
+	return bundles, nil
+}
+
+// uploadToRekor records b64Sig as the signature of the artifact with the hex
+// encoded SHA256 digest in Rekor, along with verifier, the PEM encoded
+// certificate or public key that verifies it, and returns the new log entry.
+func uploadToRekor(conf VerifyOpts, digest string, b64Sig string, verifier []byte) (*RekorBundle, error) {
+	rekord := map[string]interface{}{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]interface{}{
+			"data": map[string]interface{}{
+				"hash": map[string]string{"algorithm": "sha256", "value": digest},
+			},
+			"signature": map[string]interface{}{
+				"content":   b64Sig,
+				"publicKey": map[string]string{"content": base64.StdEncoding.EncodeToString(verifier)},
+			},
+		},
+	}
+	body, _ := json.Marshal(rekord)
+	entries := map[string]rekorEntry{}
+	if err := rekorRequest(conf, http.MethodPost, "/api/v1/log/entries", body, &entries); err != nil {
+		return nil, fmt.Errorf("failed adding signature to transparency log: %s", err)
+	}
+	for _, e := range entries {
+		return &RekorBundle{
+			SignedEntryTimestamp: e.Verification.SignedEntryTimestamp,
+			Payload: RekorPayload{
+				Body:           e.Body,
+				IntegratedTime: e.IntegratedTime,
+				LogID:          e.LogID,
+				LogIndex:       e.LogIndex,
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("failed adding signature to transparency log: no entry was returned")
+}
+
+// fetchRekorPublicKey retrieves the key Rekor signs log entries with.
+func fetchRekorPublicKey(conf VerifyOpts) (crypto.PublicKey, error) {
+	res, err := conf.HTTPClient.Get(strings.TrimSuffix(conf.RekorURL, "/") + "/api/v1/log/publicKey")
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving transparency log public key: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed retrieving transparency log public key: %s", res.Status)
+	}
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving transparency log public key: %s", err)
+	}
+	return ParsePublicKey(content)
+}
+
+// rekorRequest makes a request to Rekor's API and decodes the JSON response
+// into v.
+func rekorRequest(conf VerifyOpts, method string, path string, body []byte, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(conf.RekorURL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := conf.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// entries are added with 201 Created.
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response from %s: %s", req.URL, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
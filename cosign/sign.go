@@ -0,0 +1,286 @@
+package cosign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// The Fulcio instance of the public sigstore deployment.
+	DefaultFulcioURL = "https://fulcio.sigstore.dev"
+	// The PEM block types of private keys written by `cosign
+	// generate-key-pair`, which are encrypted with a password.
+	encryptedSigstoreKeyType = "ENCRYPTED SIGSTORE PRIVATE KEY"
+	encryptedCosignKeyType   = "ENCRYPTED COSIGN PRIVATE KEY"
+)
+
+// SignOpts configures how artifacts are signed. Either Key or IdentityToken
+// must be set.
+type SignOpts struct {
+	// Sign with this key, such as one parsed with [ParsePrivateKey], rather
+	// than keyless.
+	Key crypto.Signer
+	// The OIDC identity token used to sign keyless, which Fulcio issues a
+	// short-lived certificate for. In CI, this is often provided by the
+	// SIGSTORE_ID_TOKEN environment variable.
+	IdentityToken string
+	// The Fulcio instance to request keyless certificates from. Defaults to
+	// [DefaultFulcioURL].
+	FulcioURL string
+	// The Rekor instance to record the signature in. Defaults to
+	// [DefaultRekorURL].
+	RekorURL string
+	// Skip recording the signature in the transparency log.
+	SkipTlog bool
+	// The client used to reach Fulcio and Rekor. Defaults to a client with a
+	// 30 second timeout.
+	HTTPClient *http.Client
+}
+
+// encryptedKey is the content of a private key encrypted by cosign.
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// fulcioCertificateChain is a certificate chain, leaf first, issued by
+// Fulcio.
+type fulcioCertificateChain struct {
+	Chain struct {
+		Certificates []string `json:"certificates"`
+	} `json:"chain"`
+}
+
+// SignBlob signs artifact, as `cosign sign-blob --bundle` does, and returns
+// the bundle that verifies it with [VerifyBundle].
+//
+// When opts.Key is set, artifact is signed with it. Otherwise, an ephemeral
+// key is generated and Fulcio issues a certificate for it to the identity of
+// opts.IdentityToken. Unless opts.SkipTlog is set, the signature is then
+// recorded in Rekor and the log entry is included in the bundle.
+func SignBlob(artifact []byte, opts ...SignOpts) (*Bundle, error) {
+	conf := SignOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if conf.FulcioURL == "" {
+		conf.FulcioURL = DefaultFulcioURL
+	}
+	rekorConf := resolveOpts([]VerifyOpts{{RekorURL: conf.RekorURL, HTTPClient: conf.HTTPClient}})
+
+	key := conf.Key
+	var certPEM []byte
+	if key == nil {
+		if conf.IdentityToken == "" {
+			return nil, fmt.Errorf("a key or identity token is required to sign")
+		}
+		ephemeral, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed generating signing key: %s", err)
+		}
+		key = ephemeral
+		certPEM, err = requestCertificate(rekorConf.HTTPClient, conf.FulcioURL, conf.IdentityToken, ephemeral)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := key.Public().(*ecdsa.PublicKey); !ok {
+		if _, ok := key.Public().(*rsa.PublicKey); !ok {
+			return nil, fmt.Errorf("unsupported private key type: %T", key.Public())
+		}
+	}
+
+	digest := sha256.Sum256(artifact)
+	sig, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed signing artifact: %s", err)
+	}
+	bundle := &Bundle{Base64Signature: base64.StdEncoding.EncodeToString(sig)}
+	verifier := certPEM
+	if certPEM != nil {
+		bundle.Cert = base64.StdEncoding.EncodeToString(certPEM)
+	} else {
+		pub, err := x509.MarshalPKIXPublicKey(key.Public())
+		if err != nil {
+			return nil, fmt.Errorf("failed encoding public key: %s", err)
+		}
+		verifier = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+	}
+
+	if !conf.SkipTlog {
+		bundle.RekorBundle, err = uploadToRekor(rekorConf, hex.EncodeToString(digest[:]), bundle.Base64Signature, verifier)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bundle, nil
+}
+
+// ParsePrivateKey parses a PEM encoded private key. Keys written by `cosign
+// generate-key-pair` are decrypted with password; unencrypted PKCS #8, EC,
+// and PKCS #1 (RSA) keys are also supported.
+func ParsePrivateKey(content []byte, password []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("failed decoding private key: no PEM block found")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case encryptedSigstoreKeyType, encryptedCosignKeyType:
+		der, decryptErr := decryptKey(block.Bytes, password)
+		if decryptErr != nil {
+			return nil, decryptErr
+		}
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing private key: %s", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type: %T", key)
+	}
+	return signer, nil
+}
+
+// decryptKey decrypts the DER of a private key encrypted by cosign, which
+// derives a key from the password with scrypt and seals the private key with
+// NaCl's secretbox.
+func decryptKey(content []byte, password []byte) ([]byte, error) {
+	ek := encryptedKey{}
+	if err := json.Unmarshal(content, &ek); err != nil {
+		return nil, fmt.Errorf("failed decoding encrypted private key: %s", err)
+	}
+	if ek.KDF.Name != "scrypt" || ek.Cipher.Name != "nacl/secretbox" || len(ek.Cipher.Nonce) != 24 {
+		return nil, fmt.Errorf("unsupported private key encryption: %s, %s", ek.KDF.Name, ek.Cipher.Name)
+	}
+	derived, err := scrypt.Key(password, ek.KDF.Salt, ek.KDF.Params.N, ek.KDF.Params.R, ek.KDF.Params.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed deriving private key encryption key: %s", err)
+	}
+	var nonce [24]byte
+	var secret [32]byte
+	copy(nonce[:], ek.Cipher.Nonce)
+	copy(secret[:], derived)
+	der, ok := secretbox.Open(nil, ek.Ciphertext, &nonce, &secret)
+	if !ok {
+		return nil, fmt.Errorf("failed decrypting private key: the password is incorrect")
+	}
+	return der, nil
+}
+
+// requestCertificate requests a certificate for key from Fulcio, proving
+// possession of key by signing the identity in token, and returns the PEM
+// of the issued certificate.
+func requestCertificate(client *http.Client, fulcioURL string, token string, key *ecdsa.PrivateKey) ([]byte, error) {
+	subject, err := tokenSubject(token)
+	if err != nil {
+		return nil, err
+	}
+	subjectDigest := sha256.Sum256([]byte(subject))
+	proof, err := ecdsa.SignASN1(rand.Reader, key, subjectDigest[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed signing proof of possession: %s", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding public key: %s", err)
+	}
+
+	req := map[string]interface{}{
+		"credentials": map[string]string{"oidcIdentityToken": token},
+		"publicKeyRequest": map[string]interface{}{
+			"publicKey": map[string]string{
+				"algorithm": "ECDSA",
+				"content":   string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})),
+			},
+			"proofOfPossession": base64.StdEncoding.EncodeToString(proof),
+		},
+	}
+	body, _ := json.Marshal(req)
+	res, err := client.Post(strings.TrimSuffix(fulcioURL, "/")+"/api/v2/signingCert", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed requesting signing certificate: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed requesting signing certificate: unexpected response from %s: %s", res.Request.URL, res.Status)
+	}
+	issued := struct {
+		Embedded *fulcioCertificateChain `json:"signedCertificateEmbeddedSct"`
+		Detached *fulcioCertificateChain `json:"signedCertificateDetachedSct"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&issued); err != nil {
+		return nil, fmt.Errorf("failed decoding signing certificate: %s", err)
+	}
+	chain := issued.Embedded
+	if chain == nil {
+		chain = issued.Detached
+	}
+	if chain == nil || len(chain.Chain.Certificates) == 0 {
+		return nil, fmt.Errorf("failed requesting signing certificate: Fulcio returned no certificate")
+	}
+	return []byte(chain.Chain.Certificates[0]), nil
+}
+
+// tokenSubject returns the identity Fulcio issues a certificate to for the
+// OIDC token, which is its email claim, when set, or its subject.
+func tokenSubject(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("failed parsing identity token: not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return "", fmt.Errorf("failed parsing identity token: %s", err)
+	}
+	claims := struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("failed parsing identity token: %s", err)
+	}
+	if claims.Email != "" {
+		return claims.Email, nil
+	}
+	if claims.Subject == "" {
+		return "", fmt.Errorf("failed parsing identity token: no subject found")
+	}
+	return claims.Subject, nil
+}
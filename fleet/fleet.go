@@ -0,0 +1,441 @@
+// Package fleet aggregates the snapshots of many hosts, retrieved from their
+// agents or imported from files, into fleet-wide views: which versions of
+// each binary run on which hosts, hosts running a version of a binary that
+// differs from the rest of the fleet, and hosts missing a process the rest of
+// the fleet runs.
+//
+// Snapshots are read into a [Host] each with [Collect], then aggregated into
+// a [Report] with [Aggregate].
+package fleet
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arctir/proctor/agent"
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	// DefaultTimeout is how long an agent is given to respond.
+	DefaultTimeout = 10 * time.Second
+	// DefaultExpectedRatio is the share of hosts a binary must run on for
+	// the rest of the fleet to be expected to run it.
+	DefaultExpectedRatio = 0.8
+	// the path agents, and the UI, serve their snapshot on.
+	processesPath = "/api/processes"
+)
+
+// Host is a host of the fleet and its snapshot.
+type Host struct {
+	// The name of the host, as reported by its snapshot. Hosts reporting the
+	// same name are told apart by their source.
+	Name string
+	// The URL of the agent or the file the snapshot was read from.
+	Source   string
+	Snapshot agent.Snapshot
+	// Set when the snapshot couldn't be read, in which case the host is left
+	// out of the report's views.
+	Error string `json:",omitempty"`
+}
+
+// CollectOpts configures how snapshots are read.
+type CollectOpts struct {
+	// The client agents are queried with. Defaults to a client timing out
+	// after [DefaultTimeout].
+	HTTPClient *http.Client
+}
+
+// Collect reads the snapshot of each source, which is either the URL of an
+// agent or UI (e.g. http://web-1:8080) or a file written by `proctor process
+// ls -o json` or saved from an agent's /api/processes. Sources are read
+// concurrently and returned in the order passed. A source that can't be read
+// is returned with its Error set, rather than failing the others.
+func Collect(ctx context.Context, sources []string, opts ...CollectOpts) []Host {
+	opt := CollectOpts{}
+	if len(opts) > 0 {
+		opt = opts[len(opts)-1]
+	}
+	if opt.HTTPClient == nil {
+		opt.HTTPClient = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	hosts := make([]Host, len(sources))
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source string) {
+			defer wg.Done()
+			var s agent.Snapshot
+			var err error
+			if isURL(source) {
+				s, err = Fetch(ctx, opt.HTTPClient, source)
+			} else {
+				s, err = Load(source)
+			}
+			hosts[i] = Host{Name: s.Host, Source: source, Snapshot: s}
+			if err != nil {
+				hosts[i].Error = err.Error()
+			}
+			if hosts[i].Name == "" {
+				hosts[i].Name = source
+			}
+		}(i, source)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for i := range hosts {
+		if seen[hosts[i].Name] {
+			hosts[i].Name = hosts[i].Source
+		}
+		seen[hosts[i].Name] = true
+	}
+	return hosts
+}
+
+// TLSOpts configures how agents served over TLS are connected to.
+type TLSOpts struct {
+	// A file of PEM encoded CA certificates agents' certificates are
+	// verified with. Defaults to the system's CA certificates.
+	CA string
+	// Files of the PEM encoded certificate, and its key, presented to agents
+	// requiring clients to present one (mutual TLS).
+	Cert string
+	Key  string
+}
+
+// NewHTTPClient returns a client, for [CollectOpts], timing out after
+// timeout and connecting to agents served over TLS as configured by opts.
+func NewHTTPClient(timeout time.Duration, opts TLSOpts) (*http.Client, error) {
+	if opts.Cert == "" && opts.Key != "" || opts.Cert != "" && opts.Key == "" {
+		return nil, fmt.Errorf("both a client certificate and key must be specified")
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CA != "" {
+		pem, err := os.ReadFile(opts.CA)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading CA: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA (%s) contains no PEM encoded certificates", opts.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if opts.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.Cert, opts.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// Fetch retrieves the snapshot served by the agent or UI at url.
+func Fetch(ctx context.Context, client *http.Client, url string) (agent.Snapshot, error) {
+	var s agent.Snapshot
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(url, "/")+processesPath, nil)
+	if err != nil {
+		return s, fmt.Errorf("failed retrieving processes from agent: %s", err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return s, fmt.Errorf("failed retrieving processes from agent: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return s, fmt.Errorf("failed retrieving processes from agent: %s", res.Status)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&s); err != nil {
+		return s, fmt.Errorf("failed decoding processes from agent: %s", err)
+	}
+	return s, nil
+}
+
+// Load reads the snapshot in the file at path, which is either a snapshot
+// saved from an agent or processes keyed by ID, as written by `proctor
+// process ls -o json`. The latter are named after the file, without its
+// extension, and taken when the file was last modified.
+func Load(path string) (agent.Snapshot, error) {
+	var s agent.Snapshot
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return s, fmt.Errorf("failed reading snapshot %s: %s", path, err)
+	}
+	// unlike processes keyed by ID, the agent's snapshots have a Processes
+	// field.
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return s, fmt.Errorf("failed decoding snapshot %s: %s", path, err)
+	}
+	if _, ok := fields["Processes"]; ok {
+		if err := json.Unmarshal(content, &s); err != nil {
+			return s, fmt.Errorf("failed decoding snapshot %s: %s", path, err)
+		}
+		return s, nil
+	}
+	ps := plib.Processes{}
+	if err := json.Unmarshal(content, &ps); err != nil {
+		return s, fmt.Errorf("failed decoding snapshot %s: %s", path, err)
+	}
+	s.Host = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if info, err := os.Stat(path); err == nil {
+		s.LastRefresh = info.ModTime().UTC()
+	}
+	s.Processes, _ = ps.Sort("pid", false)
+	return s, nil
+}
+
+// ReportOpts configures how a [Report] is aggregated.
+type ReportOpts struct {
+	// The names of binaries every host is expected to run, in addition to
+	// those running on ExpectedRatio of hosts.
+	Expected []string
+	// The share of hosts, from 0 to 1, a binary must run on for the other
+	// hosts to be expected to run it. Defaults to [DefaultExpectedRatio].
+	// Set above 1 to only expect the binaries in Expected.
+	ExpectedRatio float64
+}
+
+// Report is the fleet-wide views of the snapshots of many hosts.
+type Report struct {
+	// When the report was aggregated.
+	Generated time.Time
+	// Every host, in the order collected.
+	Hosts []HostSummary
+	// Every binary run across the fleet, sorted by path.
+	Binaries []Binary
+	// The versions of binaries run on fewer hosts than the version most
+	// hosts run, sorted by name and then hash.
+	Outliers []Outlier
+	// The binaries expected on every host that some hosts aren't running,
+	// sorted by name.
+	Missing []MissingBinary
+}
+
+// HostSummary describes a host in a [Report].
+type HostSummary struct {
+	Name        string
+	Source      string
+	Labels      map[string]string `json:",omitempty"`
+	LastRefresh time.Time
+	Processes   int
+	// Set when the host's snapshot couldn't be read.
+	Error string `json:",omitempty"`
+}
+
+// Binary is a binary, by path, run across the fleet.
+type Binary struct {
+	Path string
+	// Each version of the binary, by hash, ordered by the number of hosts
+	// running it, most first.
+	Versions []BinaryVersion
+}
+
+// BinaryVersion is a version of a [Binary] and the hosts running it.
+type BinaryVersion struct {
+	SHA256 string
+	// The names of the hosts running the version, sorted.
+	Hosts []string
+	// The number of processes running the version across the fleet.
+	Processes int
+}
+
+// Outlier is a version of a binary, by name, run on fewer hosts than the
+// version most hosts run, such as a binary that wasn't upgraded or was
+// replaced.
+type Outlier struct {
+	// The name of the binary, which is the base of its path.
+	Name   string
+	SHA256 string
+	// The paths the version is run from, sorted.
+	Paths []string
+	// The names of the hosts running the version, sorted.
+	Hosts []string
+	// The version of the binary most hosts run, and the number of hosts
+	// running it.
+	CommonSHA256 string
+	CommonHosts  int
+}
+
+// MissingBinary is a binary, by name, expected on every host that some hosts
+// aren't running.
+type MissingBinary struct {
+	// The name of the binary, which is the base of its path.
+	Name string
+	// The number of hosts running the binary.
+	RunningOn int
+	// The names of the hosts not running the binary, sorted.
+	Hosts []string
+}
+
+// Aggregate returns the fleet-wide views of hosts, as of the time at. Hosts
+// whose snapshot couldn't be read are only summarized. Processes whose binary
+// path is unknown, such as kernel threads, are left out, as are processes
+// whose binary couldn't be hashed from the views of versions.
+func Aggregate(hosts []Host, at time.Time, opts ...ReportOpts) Report {
+	opt := ReportOpts{}
+	if len(opts) > 0 {
+		opt = opts[len(opts)-1]
+	}
+	if opt.ExpectedRatio == 0 {
+		opt.ExpectedRatio = DefaultExpectedRatio
+	}
+
+	r := Report{Generated: at, Hosts: []HostSummary{}, Binaries: []Binary{}, Outliers: []Outlier{}, Missing: []MissingBinary{}}
+	// the hosts running each version of a binary, keyed by path then hash.
+	versions := map[string]map[string]*BinaryVersion{}
+	// the paths and hosts of each version of a binary, keyed by name then
+	// hash.
+	type namedVersion struct{ paths, hosts map[string]bool }
+	named := map[string]map[string]*namedVersion{}
+	// the hosts running a binary, keyed by name.
+	runningOn := map[string]map[string]bool{}
+	reachable := []string{}
+	for _, h := range hosts {
+		r.Hosts = append(r.Hosts, HostSummary{Name: h.Name, Source: h.Source, Labels: h.Snapshot.Labels, LastRefresh: h.Snapshot.LastRefresh, Processes: len(h.Snapshot.Processes), Error: h.Error})
+		if h.Error != "" {
+			continue
+		}
+		reachable = append(reachable, h.Name)
+		for _, p := range h.Snapshot.Processes {
+			if p.CommandPath == "" {
+				continue
+			}
+			name := filepath.Base(p.CommandPath)
+			if runningOn[name] == nil {
+				runningOn[name] = map[string]bool{}
+			}
+			runningOn[name][h.Name] = true
+			if !plib.HashKnown(p.BinarySHA) {
+				continue
+			}
+			if versions[p.CommandPath] == nil {
+				versions[p.CommandPath] = map[string]*BinaryVersion{}
+			}
+			v := versions[p.CommandPath][p.BinarySHA]
+			if v == nil {
+				v = &BinaryVersion{SHA256: p.BinarySHA}
+				versions[p.CommandPath][p.BinarySHA] = v
+			}
+			v.Processes++
+			if !contains(v.Hosts, h.Name) {
+				v.Hosts = append(v.Hosts, h.Name)
+			}
+			if named[name] == nil {
+				named[name] = map[string]*namedVersion{}
+			}
+			nv := named[name][p.BinarySHA]
+			if nv == nil {
+				nv = &namedVersion{paths: map[string]bool{}, hosts: map[string]bool{}}
+				named[name][p.BinarySHA] = nv
+			}
+			nv.paths[p.CommandPath] = true
+			nv.hosts[h.Name] = true
+		}
+	}
+
+	for path, byHash := range versions {
+		b := Binary{Path: path}
+		for _, v := range byHash {
+			sort.Strings(v.Hosts)
+			b.Versions = append(b.Versions, *v)
+		}
+		sort.Slice(b.Versions, func(i, j int) bool {
+			if len(b.Versions[i].Hosts) != len(b.Versions[j].Hosts) {
+				return len(b.Versions[i].Hosts) > len(b.Versions[j].Hosts)
+			}
+			return b.Versions[i].SHA256 < b.Versions[j].SHA256
+		})
+		r.Binaries = append(r.Binaries, b)
+	}
+	sort.Slice(r.Binaries, func(i, j int) bool { return r.Binaries[i].Path < r.Binaries[j].Path })
+
+	for name, byHash := range named {
+		if len(byHash) < 2 {
+			continue
+		}
+		common := ""
+		for sha, nv := range byHash {
+			if common == "" || len(nv.hosts) > len(byHash[common].hosts) || len(nv.hosts) == len(byHash[common].hosts) && sha < common {
+				common = sha
+			}
+		}
+		for sha, nv := range byHash {
+			if len(nv.hosts) < len(byHash[common].hosts) {
+				r.Outliers = append(r.Outliers, Outlier{Name: name, SHA256: sha, Paths: sortedKeys(nv.paths), Hosts: sortedKeys(nv.hosts), CommonSHA256: common, CommonHosts: len(byHash[common].hosts)})
+			}
+		}
+	}
+	sort.Slice(r.Outliers, func(i, j int) bool {
+		if r.Outliers[i].Name != r.Outliers[j].Name {
+			return r.Outliers[i].Name < r.Outliers[j].Name
+		}
+		return r.Outliers[i].SHA256 < r.Outliers[j].SHA256
+	})
+
+	expected := map[string]bool{}
+	for _, name := range opt.Expected {
+		expected[name] = true
+	}
+	// binaries running on a single host aren't expected elsewhere, no matter
+	// the ratio, since fleets of one or two hosts would expect everything.
+	minHosts := int(math.Max(2, math.Ceil(opt.ExpectedRatio*float64(len(reachable)))))
+	for name, on := range runningOn {
+		if len(on) >= minHosts {
+			expected[name] = true
+		}
+	}
+	for name := range expected {
+		m := MissingBinary{Name: name, RunningOn: len(runningOn[name]), Hosts: []string{}}
+		for _, h := range reachable {
+			if !runningOn[name][h] {
+				m.Hosts = append(m.Hosts, h)
+			}
+		}
+		if len(m.Hosts) > 0 {
+			sort.Strings(m.Hosts)
+			r.Missing = append(r.Missing, m)
+		}
+	}
+	sort.Slice(r.Missing, func(i, j int) bool { return r.Missing[i].Name < r.Missing[j].Name })
+	return r
+}
+
+// isURL returns whether source is the URL of an agent, rather than a file.
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
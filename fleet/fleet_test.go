@@ -0,0 +1,152 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arctir/proctor/agent"
+	"github.com/arctir/proctor/plib"
+)
+
+var (
+	nginxSHA    = strings.Repeat("a", 64)
+	oldNginxSHA = strings.Repeat("b", 64)
+	sshdSHA     = strings.Repeat("c", 64)
+	cronSHA     = strings.Repeat("d", 64)
+)
+
+// newHost returns a host named name running processes with binaries at the
+// paths and hashes of binaries, in path=sha form.
+func newHost(name string, binaries ...string) Host {
+	h := Host{Name: name, Source: "http://" + name, Snapshot: agent.Snapshot{Host: name}}
+	for i, b := range binaries {
+		path, sha, _ := strings.Cut(b, "=")
+		h.Snapshot.Processes = append(h.Snapshot.Processes, &plib.Process{ID: i + 1, CommandName: filepath.Base(path), CommandPath: path, BinarySHA: sha})
+	}
+	return h
+}
+
+func TestCollect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != processesPath {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(agent.Snapshot{Host: "web-1", Labels: map[string]string{"role": "web"}, Processes: []*plib.Process{{ID: 1, CommandName: "nginx"}}})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	ls := filepath.Join(dir, "db-1.json")
+	os.WriteFile(ls, []byte(`{"1": {"ID": 1, "CommandName": "postgres"}, "2": {"ID": 2, "CommandName": "sshd"}}`), 0644)
+	saved := filepath.Join(dir, "saved.json")
+	os.WriteFile(saved, []byte(`{"Host": "web-1", "Processes": [{"ID": 1, "CommandName": "nginx"}]}`), 0644)
+	missing := filepath.Join(dir, "missing.json")
+
+	hosts := Collect(context.Background(), []string{server.URL + "/", ls, saved, missing})
+	if len(hosts) != 4 {
+		t.Fatalf("fail: expected 4 hosts, actual: %+v", hosts)
+	}
+	if hosts[0].Name != "web-1" || hosts[0].Error != "" || hosts[0].Snapshot.Labels["role"] != "web" || len(hosts[0].Snapshot.Processes) != 1 {
+		t.Logf("fail: expected the agent's snapshot, actual: %+v", hosts[0])
+		t.Fail()
+	}
+	if hosts[1].Name != "db-1" || len(hosts[1].Snapshot.Processes) != 2 || hosts[1].Snapshot.Processes[0].ID != 1 || hosts[1].Snapshot.LastRefresh.IsZero() {
+		t.Logf("fail: expected the processes to be named after their file, actual: %+v", hosts[1])
+		t.Fail()
+	}
+	if hosts[2].Name != saved {
+		t.Logf("fail: expected the host reporting a name already seen to be named after its source, actual: %s", hosts[2].Name)
+		t.Fail()
+	}
+	if hosts[3].Name != missing || hosts[3].Error == "" {
+		t.Logf("fail: expected an error for the missing file, actual: %+v", hosts[3])
+		t.Fail()
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(agent.Snapshot{Host: "web-1"})
+	}))
+	defer server.Close()
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644)
+
+	// the agent's certificate isn't trusted without its CA.
+	client, err := NewHTTPClient(time.Second, TLSOpts{})
+	if err != nil {
+		t.Fatalf("fail: unexpected error creating client: %s", err)
+	}
+	if _, err := Fetch(context.Background(), client, server.URL); err == nil {
+		t.Log("fail: expected an error fetching from an agent with an untrusted certificate")
+		t.Fail()
+	}
+	client, err = NewHTTPClient(time.Second, TLSOpts{CA: ca})
+	if err != nil {
+		t.Fatalf("fail: unexpected error creating client: %s", err)
+	}
+	if s, err := Fetch(context.Background(), client, server.URL); err != nil || s.Host != "web-1" {
+		t.Logf("fail: expected the agent's snapshot, actual: %+v, %v", s, err)
+		t.Fail()
+	}
+
+	if _, err := NewHTTPClient(time.Second, TLSOpts{Cert: ca}); err == nil {
+		t.Log("fail: expected an error creating a client with a certificate but no key")
+		t.Fail()
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	hosts := []Host{
+		newHost("web-1", "/usr/sbin/nginx="+nginxSHA, "/usr/sbin/nginx="+nginxSHA, "/usr/sbin/sshd="+sshdSHA, "/usr/sbin/cron="+cronSHA, "="),
+		newHost("web-2", "/usr/sbin/nginx="+nginxSHA, "/usr/sbin/sshd="+sshdSHA, "/usr/sbin/cron="+cronSHA),
+		newHost("web-3", "/opt/nginx/nginx="+oldNginxSHA, "/usr/sbin/cron="),
+		{Name: "web-4", Source: "http://web-4", Error: "connection refused"},
+	}
+	at := time.Now()
+	r := Aggregate(hosts, at)
+	if !r.Generated.Equal(at) || len(r.Hosts) != 4 || r.Hosts[0].Processes != 5 || r.Hosts[3].Error == "" {
+		t.Logf("fail: hosts were wrong: %+v", r.Hosts)
+		t.Fail()
+	}
+	if len(r.Binaries) != 4 || r.Binaries[2].Path != "/usr/sbin/nginx" {
+		t.Fatalf("fail: binaries were wrong: %+v", r.Binaries)
+	}
+	if v := r.Binaries[2].Versions; len(v) != 1 || v[0].Processes != 3 || strings.Join(v[0].Hosts, ",") != "web-1,web-2" {
+		t.Logf("fail: expected nginx to run on web-1 and web-2, actual: %+v", v)
+		t.Fail()
+	}
+	if len(r.Outliers) != 1 || r.Outliers[0].Name != "nginx" || r.Outliers[0].SHA256 != oldNginxSHA || r.Outliers[0].Paths[0] != "/opt/nginx/nginx" || r.Outliers[0].Hosts[0] != "web-3" || r.Outliers[0].CommonSHA256 != nginxSHA || r.Outliers[0].CommonHosts != 2 {
+		t.Logf("fail: expected web-3's nginx to be an outlier, actual: %+v", r.Outliers)
+		t.Fail()
+	}
+	// sshd runs on 2 of 3 reachable hosts, short of the default ratio.
+	if len(r.Missing) != 0 {
+		t.Logf("fail: expected no missing binaries, actual: %+v", r.Missing)
+		t.Fail()
+	}
+
+	tests := []struct {
+		name string
+		opts ReportOpts
+	}{
+		{"expected", ReportOpts{Expected: []string{"sshd"}}},
+		{"ratio", ReportOpts{ExpectedRatio: 0.6}},
+	}
+	for _, test := range tests {
+		r := Aggregate(hosts, at, test.opts)
+		if len(r.Missing) != 1 || r.Missing[0].Name != "sshd" || r.Missing[0].RunningOn != 2 || strings.Join(r.Missing[0].Hosts, ",") != "web-3" {
+			t.Logf("fail: %s expected sshd to be missing on web-3, actual: %+v", test.name, r.Missing)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package host
+
+// getArch returns UnknownKey, since the machine's architecture is resolved
+// with uname, which only unix systems have.
+func getArch() string {
+	return UnknownKey
+}
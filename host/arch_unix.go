@@ -0,0 +1,20 @@
+//go:build unix
+
+package host
+
+import (
+	"bytes"
+
+	"golang.org/x/sys/unix"
+)
+
+// getArch call the equivalent of uname -m to get the architecture (e.g. x86 or aarch64)
+func getArch() string {
+	var utsname unix.Utsname
+	err := unix.Uname(&utsname)
+	if err != nil {
+		return UnknownKey
+	}
+	arch := string(bytes.Trim(utsname.Machine[:], "\x00"))
+	return arch
+}
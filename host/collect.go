@@ -0,0 +1,119 @@
+package host
+
+import (
+	"context"
+	"time"
+)
+
+// Sections of [HostInfo] used as keys in HostInfo.Errors.
+const (
+	OSSection       = "os"
+	KernelSection   = "kernel"
+	HardwareSection = "hardware"
+	IDSection       = "id"
+	LoadSection     = "load"
+)
+
+// HostInfo is an aggregate of every detail a [HostReader] can resolve about a
+// host. It is created by [Collect] and is intended to be serialized (e.g. to
+// JSON) for snapshots and remote APIs.
+type HostInfo struct {
+	// The time the details were collected.
+	CollectedAt time.Time
+	ID          string
+	OS          *OS
+	Kernel      *Kernel
+	Hardware    *Hardware
+	Load        *Load
+	// Errors contains an entry for each section that failed to resolve, where
+	// the key is the section name (e.g. [KernelSection]) and the value is the
+	// error message. A section that failed will be left empty in HostInfo.
+	Errors map[string]string
+}
+
+// sectionResult holds the outcome of resolving a single section of HostInfo.
+type sectionResult struct {
+	section string
+	value   any
+	err     error
+}
+
+// Collect runs every [HostReader] method concurrently and returns the
+// combined results as a [HostInfo]. A failure in one section does not stop
+// the others from resolving; instead, the failure is recorded in
+// HostInfo.Errors. If ctx is cancelled before every section completes, the
+// unfinished sections are recorded as errors and Collect returns immediately.
+//
+// The variadic nature of reader is only to make it optional. When no reader
+// is passed, a [LinuxReader] with default configuration is used. If more than
+// one is passed, the last reader is used.
+func Collect(ctx context.Context, reader ...HostReader) HostInfo {
+	var hr HostReader
+	if len(reader) > 0 {
+		hr = reader[len(reader)-1]
+	} else {
+		lr := NewLinuxReader(LinuxReaderConfig{})
+		hr = &lr
+	}
+
+	info := HostInfo{
+		CollectedAt: time.Now(),
+		Errors:      map[string]string{},
+	}
+	sections := map[string]func() (any, error){
+		OSSection:       func() (any, error) { return hr.GetOS() },
+		KernelSection:   func() (any, error) { return hr.GetKernel() },
+		HardwareSection: func() (any, error) { return hr.GetHardware() },
+		IDSection:       func() (any, error) { return hr.GetHostID() },
+		LoadSection:     func() (any, error) { return hr.GetLoad() },
+	}
+
+	// buffered so that goroutines finishing after cancellation don't block
+	results := make(chan sectionResult, len(sections))
+	for name, get := range sections {
+		go func(name string, get func() (any, error)) {
+			v, err := get()
+			results <- sectionResult{section: name, value: v, err: err}
+		}(name, get)
+	}
+
+	pending := map[string]bool{}
+	for name := range sections {
+		pending[name] = true
+	}
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			for name := range pending {
+				info.Errors[name] = ctx.Err().Error()
+			}
+			return info
+		case r := <-results:
+			delete(pending, r.section)
+			if r.err != nil {
+				info.Errors[r.section] = r.err.Error()
+				continue
+			}
+			setSection(&info, r)
+		}
+	}
+
+	return info
+}
+
+// setSection assigns a successfully resolved section's value to its field in
+// info.
+func setSection(info *HostInfo, r sectionResult) {
+	switch v := r.value.(type) {
+	case *OS:
+		info.OS = v
+	case *Kernel:
+		info.Kernel = v
+	case *Hardware:
+		info.Hardware = v
+	case *Load:
+		info.Load = v
+	case string:
+		info.ID = v
+	}
+}
@@ -0,0 +1,178 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDockerSocketPath = "/var/run/docker.sock"
+	DefaultPodmanSocketPath = "/run/podman/podman.sock"
+	// The API version requested from Docker-compatible engines. v1.24 is old
+	// enough to be supported by every maintained Docker and Podman release.
+	dockerAPIVersion = "v1.24"
+	// How long each engine is given to list and inspect its containers when
+	// the context has no deadline, so a hung engine can't hang proctor.
+	containerRuntimeTimeout = 10 * time.Second
+)
+
+// Container represents a container running on the host.
+type Container struct {
+	// The full identifier of the container as reported by its runtime.
+	ID   string
+	Name string
+	// The image reference the container was created from (e.g. nginx:1.23).
+	Image string
+	// The content-addressable ID of the image (e.g. sha256:...).
+	ImageID string
+	// The state of the container, such as running or paused.
+	State     string
+	StartedAt time.Time
+	// The socket of the runtime the container was discovered through.
+	RuntimeSocket string
+}
+
+// dockerContainer is the subset of the Docker Engine API's container list
+// response used to create a [Container].
+type dockerContainer struct {
+	ID      string `json:"Id"`
+	Names   []string
+	Image   string
+	ImageID string
+	State   string
+}
+
+// dockerContainerDetails is the subset of the Docker Engine API's container
+// inspect response used to resolve details missing from the list response.
+type dockerContainerDetails struct {
+	State struct {
+		StartedAt time.Time
+	}
+}
+
+// GetContainers enumerates running containers by querying every
+// Docker-compatible engine API (Docker and Podman) whose socket is present on
+// the host. Sockets are resolved relative to RootFS when it is set. Each
+// engine is given 10 seconds to respond when ctx has no deadline. Today,
+// containerd's native (gRPC) API is not supported; containers it manages are
+// only discovered when it is fronted by Docker.
+//
+// An error is returned when no container runtime socket is found or when
+// every found runtime fails to respond.
+func (h *LinuxReader) GetContainers(ctx context.Context) ([]Container, error) {
+	containers := []Container{}
+	var lastErr error
+	found := false
+	for _, socket := range h.containerSockets {
+		if _, err := os.Stat(socket); err != nil {
+			continue
+		}
+		found = true
+		cs, err := getDockerContainers(ctx, socket)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		containers = append(containers, cs...)
+	}
+	if !found {
+		return nil, fmt.Errorf("failed to find a container runtime socket in: %s", strings.Join(h.containerSockets, ", "))
+	}
+	if len(containers) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return containers, nil
+}
+
+// getDockerContainers lists running containers from the Docker-compatible
+// engine listening on socketPath. Each container is inspected to resolve its
+// start time.
+func getDockerContainers(ctx context.Context, socketPath string) ([]Container, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, containerRuntimeTimeout)
+		defer cancel()
+	}
+	client := newUnixSocketClient(socketPath)
+	var list []dockerContainer
+	err := getDockerJSON(ctx, client, "/containers/json", &list)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing containers from %s. Error was: %s", socketPath, err)
+	}
+
+	containers := []Container{}
+	for _, c := range list {
+		name := ""
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		container := Container{
+			ID:            c.ID,
+			Name:          name,
+			Image:         c.Image,
+			ImageID:       c.ImageID,
+			State:         c.State,
+			RuntimeSocket: socketPath,
+		}
+		// the start time is not part of the list response; when it cannot be
+		// inspected (e.g. the container exited in between calls), it is left
+		// as the zero value.
+		var details dockerContainerDetails
+		if err := getDockerJSON(ctx, client, "/containers/"+c.ID+"/json", &details); err == nil {
+			container.StartedAt = details.State.StartedAt
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// getDockerJSON makes a GET request against the engine API at path and
+// decodes the JSON response into v.
+func getDockerJSON(ctx context.Context, client *http.Client, path string, v any) error {
+	// the host portion of the URL is ignored since the client always dials
+	// the unix socket.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/"+dockerAPIVersion+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status from engine API: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// newUnixSocketClient returns an HTTP client whose connections are made to the
+// unix socket at socketPath. Requests time out after
+// containerRuntimeTimeout.
+func newUnixSocketClient(socketPath string) *http.Client {
+	return &http.Client{
+		Timeout: containerRuntimeTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+}
+
+// rebaseAll returns a copy of paths with each path joined onto root.
+func rebaseAll(root string, paths []string) []string {
+	rebased := make([]string, len(paths))
+	for i, p := range paths {
+		rebased[i] = filepath.Join(root, p)
+	}
+	return rebased
+}
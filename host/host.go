@@ -5,12 +5,11 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-
-	"golang.org/x/sys/unix"
 )
 
 const (
@@ -19,6 +18,8 @@ const (
 	OSReleaseFilePath    = "/etc/os-release"
 	OSKernelFilePath     = "sys/kernel/osrelease"
 	CPUInfoFilePath      = "cpuinfo"
+	LoadAvgFilePath      = "loadavg"
+	PressureDirPath      = "pressure"
 	UnknownKey           = "UNKNOWN"
 )
 
@@ -26,6 +27,8 @@ const (
 type OS struct {
 	Name    string
 	Version string
+	// The machine-readable version, such as 12 or 3.18.4, when reported.
+	VersionID string
 }
 
 // Kernel represents the operating-system's kernel's details.
@@ -45,6 +48,59 @@ type CPUInfo struct {
 	CPUCount int
 }
 
+// Load represents how busy the host is. It combines the traditional load
+// average with [pressure stall information] (PSI), which describes how long
+// tasks were stalled waiting on CPU, memory, or IO.
+//
+// [pressure stall information]: https://docs.kernel.org/accounting/psi.html
+type Load struct {
+	Average LoadAverage
+	// Pressure is nil when the kernel does not expose PSI (e.g. it was built
+	// without CONFIG_PSI or PSI is disabled at boot).
+	Pressure *Pressure
+}
+
+// LoadAverage represents the contents of /proc/loadavg.
+type LoadAverage struct {
+	// The number of jobs in the run queue or waiting on disk IO averaged over 1,
+	// 5, and 15 minutes.
+	OneMinute     float64
+	FiveMinute    float64
+	FifteenMinute float64
+	// The number of currently runnable scheduling entities (processes and
+	// threads).
+	RunnableTasks int
+	// The number of scheduling entities that currently exist on the system.
+	TotalTasks int
+	// The ID of the process most recently created on the system.
+	LastPID int
+}
+
+// Pressure contains the pressure stall information for each resource.
+type Pressure struct {
+	CPU    PressureStat
+	Memory PressureStat
+	IO     PressureStat
+}
+
+// PressureStat represents a single file in /proc/pressure. Some tracks the
+// share of time at least one task was stalled on the resource, while Full
+// tracks the share of time all non-idle tasks were stalled simultaneously.
+// Full is always zero for CPU on kernels older than 5.13.
+type PressureStat struct {
+	Some PressureLine
+	Full PressureLine
+}
+
+// PressureLine contains the stall percentages averaged over 10, 60, and 300
+// second windows along with the absolute stall time in microseconds.
+type PressureLine struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  uint64
+}
+
 // HostReader defines the actions available for retrieving information about a host.
 type HostReader interface {
 	// GetOS retrieves operating-system details
@@ -56,19 +112,54 @@ type HostReader interface {
 	GetHardware() (*Hardware, error)
 	// GetHostID retrieves a unique identifier that represents the host (physical/virtual machine).
 	GetHostID() (string, error)
+	// GetLoad retrieves the host's load average and, when available, pressure
+	// stall information.
+	GetLoad() (*Load, error)
 }
 
 // LinuxReader is the Linux-specific implementation of [HostReader].
 type LinuxReader struct {
+	// the root filesystem paths reported by container engines are resolved
+	// against, empty for the running host.
+	rootFS        string
 	procDir       string
 	machineIDPath string
+	osReleasePath string
+	// sockets of Docker-compatible engines used to enumerate containers.
+	containerSockets []string
+	// package manager databases used to enumerate installed packages.
+	dpkgDir   string
+	apkDBPath string
+	rpmDBDir  string
 }
 
+// LinuxReaderConfig provides the configuration used to create a LinuxReader
+// with [NewLinuxReader]. Any path left empty is set to its default.
 type LinuxReaderConfig struct {
+	// RootFS is the root filesystem every other path is resolved against. By
+	// default this is /, meaning the running host is inspected. Setting it to
+	// the location of a mounted root filesystem (e.g. an extracted image)
+	// enables inspecting that filesystem offline. Note that details which
+	// aren't backed by files, such as the architecture, are always resolved
+	// from the running host.
+	RootFS        string
 	ProcDirPath   string
 	MachineIDPath string
+	OSReleasePath string
+	// The unix sockets of Docker-compatible container engines queried by
+	// [LinuxReader.GetContainers]. By default, the Docker and Podman sockets
+	// are used.
+	ContainerSocketPaths []string
+	// The package manager databases read by [LinuxReader.GetPackages]. By
+	// default, the standard dpkg, apk, and rpm locations are used.
+	DpkgDirPath string
+	APKDBPath   string
+	RPMDBPath   string
 }
 
+// NewLinuxReader returns a LinuxReader based on conf. Paths not set in conf
+// are set to their defaults and all paths are rebased onto conf.RootFS when
+// it is set.
 func NewLinuxReader(conf LinuxReaderConfig) LinuxReader {
 	if conf.ProcDirPath == "" {
 		conf.ProcDirPath = DefaultProcRoot
@@ -76,9 +167,39 @@ func NewLinuxReader(conf LinuxReaderConfig) LinuxReader {
 	if conf.MachineIDPath == "" {
 		conf.MachineIDPath = DefaultMachineIDPath
 	}
+	if conf.OSReleasePath == "" {
+		conf.OSReleasePath = OSReleaseFilePath
+	}
+	if len(conf.ContainerSocketPaths) == 0 {
+		conf.ContainerSocketPaths = []string{DefaultDockerSocketPath, DefaultPodmanSocketPath}
+	}
+	if conf.DpkgDirPath == "" {
+		conf.DpkgDirPath = DefaultDpkgDirPath
+	}
+	if conf.APKDBPath == "" {
+		conf.APKDBPath = DefaultAPKDBPath
+	}
+	if conf.RPMDBPath == "" {
+		conf.RPMDBPath = DefaultRPMDBPath
+	}
+	if conf.RootFS != "" {
+		conf.ProcDirPath = filepath.Join(conf.RootFS, conf.ProcDirPath)
+		conf.MachineIDPath = filepath.Join(conf.RootFS, conf.MachineIDPath)
+		conf.OSReleasePath = filepath.Join(conf.RootFS, conf.OSReleasePath)
+		conf.ContainerSocketPaths = rebaseAll(conf.RootFS, conf.ContainerSocketPaths)
+		conf.DpkgDirPath = filepath.Join(conf.RootFS, conf.DpkgDirPath)
+		conf.APKDBPath = filepath.Join(conf.RootFS, conf.APKDBPath)
+		conf.RPMDBPath = filepath.Join(conf.RootFS, conf.RPMDBPath)
+	}
 	return LinuxReader{
-		procDir:       conf.ProcDirPath,
-		machineIDPath: conf.MachineIDPath,
+		rootFS:           conf.RootFS,
+		procDir:          conf.ProcDirPath,
+		machineIDPath:    conf.MachineIDPath,
+		osReleasePath:    conf.OSReleasePath,
+		containerSockets: conf.ContainerSocketPaths,
+		dpkgDir:          conf.DpkgDirPath,
+		apkDBPath:        conf.APKDBPath,
+		rpmDBDir:         conf.RPMDBPath,
 	}
 }
 
@@ -88,16 +209,17 @@ func NewLinuxReader(conf LinuxReaderConfig) LinuxReader {
 //
 // [freedesktop specification]: https://www.freedesktop.org/software/systemd/man/os-release.html
 func (h *LinuxReader) GetOS() (*OS, error) {
-	releaseFileData, err := os.ReadFile(OSReleaseFilePath)
+	releaseFileData, err := os.ReadFile(h.osReleasePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed locating OS details at %s. Error was: %s",
-			OSReleaseFilePath, err)
+			h.osReleasePath, err)
 	}
 
 	OSReleaseData := parseOSRelease(releaseFileData)
 	return &OS{
-		Name:    OSReleaseData["ID"],
-		Version: OSReleaseData["VERSION"],
+		Name:      OSReleaseData["ID"],
+		Version:   sanitizeOSVersion(OSReleaseData["VERSION"]),
+		VersionID: sanitizeOSVersion(OSReleaseData["VERSION_ID"]),
 	}, nil
 }
 
@@ -108,7 +230,7 @@ func (h *LinuxReader) GetKernel() (*Kernel, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed getting kernel version from %s. Error was: %s", OSKernelFilePath, err)
 	}
-	fmt.Printf("kernel version: %s\n\n", kernelFileData)
+	slog.Debug("read kernel version", "path", kernelFilePath, "version", strings.TrimSpace(string(kernelFileData)))
 	return &Kernel{
 		Type:    "Linux",
 		Version: string(kernelFileData),
@@ -142,6 +264,55 @@ func (h *LinuxReader) GetHostID() (string, error) {
 	return mid, nil
 }
 
+// GetLoad retrieves the load average from /proc/loadavg and pressure stall
+// information from /proc/pressure/{cpu,memory,io}. An error is returned when
+// the load average cannot be read. Since PSI is optional in the kernel, a
+// failure reading the pressure files is logged and results in a nil Pressure.
+func (h *LinuxReader) GetLoad() (*Load, error) {
+	loadAvgPath := filepath.Join(h.procDir, LoadAvgFilePath)
+	loadAvgData, err := os.ReadFile(loadAvgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading load average from %s. Error was: %s", loadAvgPath, err)
+	}
+	avg, err := parseLoadAvg(loadAvgData)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing load average from %s. Error was: %s", loadAvgPath, err)
+	}
+
+	load := &Load{Average: *avg}
+	pressure, err := h.getPressure()
+	if err != nil {
+		slog.Warn("failed retrieving pressure stall information", "error", err)
+		return load, nil
+	}
+	load.Pressure = pressure
+	return load, nil
+}
+
+// getPressure reads each PSI file within /proc/pressure. If any of the files
+// cannot be read or parsed, an error is returned.
+func (h *LinuxReader) getPressure() (*Pressure, error) {
+	pressure := &Pressure{}
+	resources := map[string]*PressureStat{
+		"cpu":    &pressure.CPU,
+		"memory": &pressure.Memory,
+		"io":     &pressure.IO,
+	}
+	for name, stat := range resources {
+		fp := filepath.Join(h.procDir, PressureDirPath, name)
+		data, err := os.ReadFile(fp)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parsePressure(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing %s: %s", fp, err)
+		}
+		*stat = *parsed
+	}
+	return pressure, nil
+}
+
 // getCPUInfo retrieves details about the system's CPU based on /proc/cpuinfo.
 // TOOD(joshrosso): Right now we just get CPU count, this can be expanded for more details, such as
 // clock speed. If there's an error reading necessary files, an empty CPU Info is returned.
@@ -150,7 +321,7 @@ func (h *LinuxReader) getCPUInfo() CPUInfo {
 	cpuInfoPath := filepath.Join(h.procDir, CPUInfoFilePath)
 	f, err := os.Open(cpuInfoPath)
 	if err != nil {
-		log.Printf("failed retrieving processor type from %s. Error was: %s", CPUInfoFilePath, err)
+		slog.Warn("failed retrieving processor type", "path", cpuInfoPath, "error", err)
 		return CPUInfo{}
 	}
 	scanner := bufio.NewScanner(bufio.NewReader(f))
@@ -171,23 +342,99 @@ func (h *LinuxReader) getCPUInfo() CPUInfo {
 	}
 }
 
-// getArch call the equivalent of uname -m to get the architecture (e.g. x86 or aarch64)
-func getArch() string {
-	var utsname unix.Utsname
-	err := unix.Uname(&utsname)
-	if err != nil {
-		return UnknownKey
-	}
-	arch := string(bytes.Trim(utsname.Machine[:], "\x00"))
-	return arch
-}
-
 // sanitizeOSVersion removes a double quote character from the beginning and end of a string if
 // present.
 func sanitizeOSVersion(version string) string {
 	return strings.Trim(version, "\"")
 }
 
+// parseLoadAvg takes the contents of /proc/loadavg, which looks like:
+//
+//	0.40 0.57 0.28 1/72 7098
+//
+// and returns the structured [LoadAverage]. An error is returned if the
+// contents are not in the expected format.
+func parseLoadAvg(loadAvgContents []byte) (*LoadAverage, error) {
+	fields := strings.Fields(string(loadAvgContents))
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, found %d", len(fields))
+	}
+	avg := &LoadAverage{}
+	var err error
+	if avg.OneMinute, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return nil, err
+	}
+	if avg.FiveMinute, err = strconv.ParseFloat(fields[1], 64); err != nil {
+		return nil, err
+	}
+	if avg.FifteenMinute, err = strconv.ParseFloat(fields[2], 64); err != nil {
+		return nil, err
+	}
+	tasks := strings.SplitN(fields[3], "/", 2)
+	if len(tasks) != 2 {
+		return nil, fmt.Errorf("expected runnable/total tasks, found %s", fields[3])
+	}
+	if avg.RunnableTasks, err = strconv.Atoi(tasks[0]); err != nil {
+		return nil, err
+	}
+	if avg.TotalTasks, err = strconv.Atoi(tasks[1]); err != nil {
+		return nil, err
+	}
+	if avg.LastPID, err = strconv.Atoi(fields[4]); err != nil {
+		return nil, err
+	}
+	return avg, nil
+}
+
+// parsePressure takes the contents of a PSI file (e.g. /proc/pressure/io),
+// which looks like:
+//
+//	some avg10=0.23 avg60=0.17 avg300=0.43 total=4000978
+//	full avg10=0.03 avg60=0.06 avg300=0.33 total=3175910
+//
+// and returns the structured [PressureStat]. Unknown keys are ignored so that
+// future kernel additions don't break parsing.
+func parsePressure(pressureContents []byte) (*PressureStat, error) {
+	stat := &PressureStat{}
+	scanner := bufio.NewScanner(bytes.NewReader(pressureContents))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 1 {
+			continue
+		}
+		var line *PressureLine
+		switch fields[0] {
+		case "some":
+			line = &stat.Some
+		case "full":
+			line = &stat.Full
+		default:
+			continue
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid field %s", field)
+			}
+			var err error
+			switch kv[0] {
+			case "avg10":
+				line.Avg10, err = strconv.ParseFloat(kv[1], 64)
+			case "avg60":
+				line.Avg60, err = strconv.ParseFloat(kv[1], 64)
+			case "avg300":
+				line.Avg300, err = strconv.ParseFloat(kv[1], 64)
+			case "total":
+				line.Total, err = strconv.ParseUint(kv[1], 10, 64)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stat, nil
+}
+
 // parseOSRelease takes the contents of an /etc/os-release file and returns a map containing each
 // key/value pair. The key/value pair is determined by parsing the syntax of $KEY=$VALUE within the
 // file.
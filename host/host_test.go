@@ -1,22 +1,35 @@
 package host
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
 const (
 	defaultCPUInfoFile   = "cpuinfo"
 	defaultMachineIDFile = "machine-id"
+	defaultOSReleaseFile = "os-release"
+	defaultLoadAvgFile   = "loadavg"
+	pressureFolder       = "pressure"
 	procFolder           = "proc"
 	etcFolder            = "etc"
 	cpuInfo1             = "hack/test/data/proc/cpuinfo-1"
 	machineID1           = "hack/test/data/etc/machine-id-1"
+	osRelease1           = "hack/test/data/etc/os-release-1"
+	loadAvg1             = "hack/test/data/proc/loadavg-1"
+	pressureDir1         = "hack/test/data/proc/pressure"
 	testDataDir          = "hack/test/data"
 	testRunDir           = "hack/test/run"
+	dockerContainerList1 = `[{"Id":"8dfafdbc3a40","Names":["/web"],"Image":"nginx:1.23","ImageID":"sha256:abc","State":"running"}]`
+	dockerContainer1     = `{"State":{"StartedAt":"2023-01-02T15:04:05Z"}}`
 )
 
 func TestGetHardware(t *testing.T) {
@@ -70,6 +83,352 @@ func TestGetHostID(t *testing.T) {
 	}
 }
 
+func TestGetLoad(t *testing.T) {
+	err := newTestRun()
+	if err != nil {
+		t.Fatalf("failed to prepare test case. Error was: %s", err)
+	}
+	generatedProcPath, err := createMockProc()
+	if err != nil {
+		t.Fatalf("failed to create mock proc dir. Error was: %s", err)
+	}
+	lr := NewLinuxReader(LinuxReaderConfig{
+		ProcDirPath: *generatedProcPath,
+	})
+
+	// without pressure files, load average should still resolve
+	load, err := lr.GetLoad()
+	if err != nil {
+		t.Fatalf("failed to make GetLoad call. Error was: %s", err)
+	}
+	if load.Average.OneMinute != 0.40 || load.Average.FifteenMinute != 0.28 {
+		t.Logf("failed load average check. expected: 0.40 and 0.28, actual: %v and %v.",
+			load.Average.OneMinute, load.Average.FifteenMinute)
+		t.Fail()
+	}
+	if load.Average.RunnableTasks != 1 || load.Average.TotalTasks != 72 || load.Average.LastPID != 7098 {
+		t.Logf("failed task count check. actual: %+v", load.Average)
+		t.Fail()
+	}
+	if load.Pressure != nil {
+		t.Log("expected nil pressure when pressure files are missing")
+		t.Fail()
+	}
+
+	err = addPressureFiles(filepath.Dir(*generatedProcPath), pressureDir1)
+	if err != nil {
+		t.Fatalf("failed to add mock pressure files. Error was: %s", err)
+	}
+	load, err = lr.GetLoad()
+	if err != nil {
+		t.Fatalf("failed to make GetLoad call. Error was: %s", err)
+	}
+	if load.Pressure == nil {
+		t.Fatal("expected pressure to be resolved, but it was nil")
+	}
+	if load.Pressure.CPU.Some.Avg10 != 6.03 {
+		t.Logf("failed cpu pressure check. expected: %v, actual: %v.", 6.03, load.Pressure.CPU.Some.Avg10)
+		t.Fail()
+	}
+	if load.Pressure.IO.Full.Total != 3175910 {
+		t.Logf("failed io pressure check. expected: %d, actual: %d.", 3175910, load.Pressure.IO.Full.Total)
+		t.Fail()
+	}
+}
+
+func TestRootFS(t *testing.T) {
+	err := newTestRun()
+	if err != nil {
+		t.Fatalf("failed to prepare test case. Error was: %s", err)
+	}
+	rootFS, err := os.MkdirTemp(testRunDir, "*")
+	if err != nil {
+		t.Fatalf("failed creating mock root filesystem. Error was: %s", err)
+	}
+	etcPath := filepath.Join(rootFS, etcFolder)
+	err = os.Mkdir(etcPath, 0777)
+	if err != nil {
+		t.Fatalf("failed creating mock etc dir. Error was: %s", err)
+	}
+	err = copyFile(osRelease1, filepath.Join(etcPath, defaultOSReleaseFile))
+	if err != nil {
+		t.Fatalf("failed adding mock os-release file. Error was: %s", err)
+	}
+	err = addMachineIDFile(etcPath, machineID1)
+	if err != nil {
+		t.Fatalf("failed adding mock machine-id file. Error was: %s", err)
+	}
+
+	lr := NewLinuxReader(LinuxReaderConfig{
+		RootFS: rootFS,
+	})
+	osDetails, err := lr.GetOS()
+	if err != nil {
+		t.Fatalf("failed to make GetOS call. Error was: %s", err)
+	}
+	if osDetails.Name != "debian" {
+		t.Logf("failed with unexpected OS name. Expected: %s, actual: %s", "debian", osDetails.Name)
+		t.Fail()
+	}
+	if osDetails.Version != "12 (bookworm)" {
+		t.Logf("failed with unexpected OS version. Expected: %s, actual: %s", "12 (bookworm)", osDetails.Version)
+		t.Fail()
+	}
+	if osDetails.VersionID != "12" {
+		t.Logf("failed with unexpected OS version ID. Expected: %s, actual: %s", "12", osDetails.VersionID)
+		t.Fail()
+	}
+	id, err := lr.GetHostID()
+	if err != nil {
+		t.Fatalf("failed resolving machine id. Error was: %s", err)
+	}
+	if id != "abc123xyz" {
+		t.Logf("failed with unexpected machine id. Expected: %s, actual: %s", "abc123xyz", id)
+		t.Fail()
+	}
+}
+
+func TestGetContainers(t *testing.T) {
+	err := newTestRun()
+	if err != nil {
+		t.Fatalf("failed to prepare test case. Error was: %s", err)
+	}
+	dir, err := os.MkdirTemp(testRunDir, "*")
+	if err != nil {
+		t.Fatalf("failed creating socket dir. Error was: %s", err)
+	}
+
+	// without any sockets present, an error should be returned
+	lr := NewLinuxReader(LinuxReaderConfig{
+		ContainerSocketPaths: []string{filepath.Join(dir, "missing.sock")},
+	})
+	_, err = lr.GetContainers(context.Background())
+	if err == nil {
+		t.Log("expected error when no container runtime socket exists, but did not receive one.")
+		t.Fail()
+	}
+
+	// serve a fake engine API over a unix socket
+	socketPath := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed listening on mock socket. Error was: %s", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.24/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(dockerContainerList1))
+	})
+	mux.HandleFunc("/v1.24/containers/8dfafdbc3a40/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(dockerContainer1))
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	lr = NewLinuxReader(LinuxReaderConfig{
+		ContainerSocketPaths: []string{socketPath},
+	})
+	containers, err := lr.GetContainers(context.Background())
+	if err != nil {
+		t.Fatalf("failed retrieving containers. Error was: %s", err)
+	}
+	if len(containers) != 1 {
+		t.Fatalf("failed with unexpected container count. Expected: %d, actual: %d", 1, len(containers))
+	}
+	if containers[0].Name != "web" || containers[0].Image != "nginx:1.23" {
+		t.Logf("failed with unexpected container details. actual: %+v", containers[0])
+		t.Fail()
+	}
+	if containers[0].StartedAt.Year() != 2023 {
+		t.Logf("failed resolving container start time. actual: %s", containers[0].StartedAt)
+		t.Fail()
+	}
+}
+
+func TestGetProcessLayers(t *testing.T) {
+	err := newTestRun()
+	if err != nil {
+		t.Fatalf("failed to prepare test case. Error was: %s", err)
+	}
+	dir, err := os.MkdirTemp(testRunDir, "*")
+	if err != nil {
+		t.Fatalf("failed creating layers dir. Error was: %s", err)
+	}
+	dir, _ = filepath.Abs(dir)
+	// the image's base layer provides sh and nginx, which the second layer
+	// replaces, and miner was added to the container at runtime.
+	files := []string{"l0/bin/sh", "l0/usr/sbin/nginx", "l1/usr/sbin/nginx", "upper/tmp/miner"}
+	for _, f := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0777)
+		if err := os.WriteFile(filepath.Join(dir, f), []byte(f), 0777); err != nil {
+			t.Fatalf("failed creating layer file. Error was: %s", err)
+		}
+	}
+	dockerID, containerdID := strings.Repeat("d", 64), strings.Repeat("c", 64)
+	procDir := filepath.Join(dir, "proc")
+	procFiles := map[string]string{
+		"42/cgroup":    "0::/system.slice/docker-" + dockerID + ".scope\n",
+		"43/cgroup":    "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + containerdID + ".scope\n",
+		"43/mountinfo": fmt.Sprintf("1 0 0:50 / / rw,relatime - overlay overlay rw,lowerdir=%s/l1:%s/l0,upperdir=%s/upper,workdir=%s/work\n", dir, dir, dir, dir),
+		"44/cgroup":    "0::/system.slice/sshd.service\n",
+	}
+	for f, contents := range procFiles {
+		os.MkdirAll(filepath.Join(procDir, filepath.Dir(f)), 0777)
+		if err := os.WriteFile(filepath.Join(procDir, f), []byte(contents), 0666); err != nil {
+			t.Fatalf("failed creating proc file. Error was: %s", err)
+		}
+	}
+
+	socketPath := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed listening on mock socket. Error was: %s", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.24/containers/"+dockerID+"/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"Id": %q, "Image": "sha256:abc", "Config": {"Image": "nginx:1.23"}, "GraphDriver": {"Name": "overlay2", "Data": {"LowerDir": "%s/init-init/diff:%s/l1:%s/l0", "UpperDir": "%s/upper"}}}`, dockerID, dir, dir, dir, dir)
+	})
+	mux.HandleFunc("/v1.24/images/sha256:abc/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"RootFS": {"Layers": ["sha256:l0", "sha256:l1"]}}`))
+	})
+	mux.HandleFunc("/v1.24/images/sha256:abc/history", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"CreatedBy": "CMD [\"nginx\"]"}, {"CreatedBy": "RUN /bin/sh -c apt-get install -y nginx # buildkit"}, {"CreatedBy": "/bin/sh -c #(nop)  CMD [\"bash\"]"}, {"CreatedBy": "/bin/sh -c #(nop) ADD file:123 in / "}]`))
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	defer srv.Close()
+	lr := NewLinuxReader(LinuxReaderConfig{ProcDirPath: procDir, ContainerSocketPaths: []string{socketPath}})
+
+	layers, err := lr.GetProcessLayers(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("failed retrieving layers of Docker container. Error was: %s", err)
+	}
+	if layers.Image != "nginx:1.23" || len(layers.Layers) != 2 || layers.Layers[0].DiffID != "sha256:l0" || layers.Layers[1].CreatedBy != "RUN /bin/sh -c apt-get install -y nginx # buildkit" {
+		t.Fatalf("failed with unexpected layers. actual: %+v", layers)
+	}
+	tests := []struct {
+		path    string
+		layer   int
+		runtime bool
+	}{
+		{"/bin/sh", 0, false},
+		{"/usr/sbin/nginx", 1, false},
+		{"/tmp/miner", -1, true},
+	}
+	for _, test := range tests {
+		origin, err := layers.Origin(test.path)
+		if err != nil {
+			t.Fatalf("failed resolving origin of %s. Error was: %s", test.path, err)
+		}
+		if origin.AddedAtRuntime != test.runtime || !test.runtime && origin.Layer.Index != test.layer {
+			t.Logf("failed with unexpected origin of %s. actual: %+v", test.path, origin)
+			t.Fail()
+		}
+	}
+	if _, err := layers.Origin("/usr/bin/missing"); err == nil {
+		t.Log("expected error resolving the origin of a file in no layer, but did not receive one.")
+		t.Fail()
+	}
+
+	// the containerd container isn't known to the engine, so its layers are
+	// resolved from its root mount.
+	layers, err = lr.GetProcessLayers(context.Background(), 43)
+	if err != nil {
+		t.Fatalf("failed retrieving layers of containerd container. Error was: %s", err)
+	}
+	if layers.ContainerID != containerdID || len(layers.Layers) != 2 || layers.Layers[1].Dir != filepath.Join(dir, "l1") || layers.UpperDir != filepath.Join(dir, "upper") {
+		t.Logf("failed with unexpected layers. actual: %+v", layers)
+		t.Fail()
+	}
+	if _, err := lr.GetProcessLayers(context.Background(), 44); err == nil {
+		t.Log("expected error retrieving layers of a process outside a container, but did not receive one.")
+		t.Fail()
+	}
+}
+
+func TestGetPackages(t *testing.T) {
+	// without any package databases present, an error should be returned
+	lr := NewLinuxReader(LinuxReaderConfig{
+		RootFS: filepath.Join(testDataDir, "missing"),
+	})
+	_, err := lr.GetPackages()
+	if err == nil {
+		t.Log("expected error when no package database exists, but did not receive one.")
+		t.Fail()
+	}
+
+	lr = NewLinuxReader(LinuxReaderConfig{
+		RootFS: testDataDir,
+	})
+	packages, err := lr.GetPackages()
+	if err != nil {
+		t.Fatalf("failed retrieving packages. Error was: %s", err)
+	}
+	// vim was removed, leaving only its config files, so shouldn't be listed
+	names := []string{"bash", "busybox", "libc6", "musl"}
+	if len(packages) != len(names) {
+		t.Fatalf("failed with unexpected package count. Expected: %d, actual: %d", len(names), len(packages))
+	}
+	for i, name := range names {
+		if packages[i].Name != name {
+			t.Logf("failed with unexpected package. Expected: %s, actual: %s", name, packages[i].Name)
+			t.Fail()
+		}
+	}
+	bash := packages[0]
+	if bash.Manager != DpkgManager || bash.Version != "5.2.15-2+b2" || len(bash.Files) != 2 || bash.Files[0] != "/bin/bash" {
+		t.Logf("failed with unexpected dpkg package details. actual: %+v", bash)
+		t.Fail()
+	}
+	if libc := packages[2]; libc.Source != "glibc" || len(libc.Files) != 1 || libc.Files[0] != "/lib/x86_64-linux-gnu/libc.so.6" {
+		t.Logf("failed resolving files of multi-arch dpkg package. actual: %+v", libc)
+		t.Fail()
+	}
+	busybox := packages[1]
+	if busybox.Manager != APKManager || busybox.Source != "" || busybox.Arch != "x86_64" || len(busybox.Files) != 2 || busybox.Files[0] != "/bin/busybox" {
+		t.Logf("failed with unexpected apk package details. actual: %+v", busybox)
+		t.Fail()
+	}
+}
+
+// fakeReader is a [HostReader] returning canned values, where GetKernel always
+// fails.
+type fakeReader struct{}
+
+func (f *fakeReader) GetOS() (*OS, error)             { return &OS{Name: "arch"}, nil }
+func (f *fakeReader) GetKernel() (*Kernel, error)     { return nil, fmt.Errorf("no kernel") }
+func (f *fakeReader) GetHardware() (*Hardware, error) { return &Hardware{Architecture: "x86_64"}, nil }
+func (f *fakeReader) GetHostID() (string, error)      { return "abc123xyz", nil }
+func (f *fakeReader) GetLoad() (*Load, error)         { return &Load{}, nil }
+
+func TestCollect(t *testing.T) {
+	info := Collect(context.Background(), &fakeReader{})
+	if info.ID != "abc123xyz" {
+		t.Logf("failed with unexpected host id. Expected: %s, actual: %s", "abc123xyz", info.ID)
+		t.Fail()
+	}
+	if info.OS == nil || info.OS.Name != "arch" {
+		t.Logf("failed resolving OS section. actual: %+v", info.OS)
+		t.Fail()
+	}
+	if info.Hardware == nil || info.Load == nil {
+		t.Log("failed resolving hardware and load sections.")
+		t.Fail()
+	}
+	if info.Kernel != nil {
+		t.Log("expected kernel section to be empty since it failed to resolve.")
+		t.Fail()
+	}
+	if info.Errors[KernelSection] != "no kernel" {
+		t.Logf("expected kernel error to be recorded. actual errors: %v", info.Errors)
+		t.Fail()
+	}
+	if len(info.Errors) != 1 {
+		t.Logf("expected exactly 1 error. actual errors: %v", info.Errors)
+		t.Fail()
+	}
+}
+
 func createMockMachineID() (*string, error) {
 	dir, err := os.MkdirTemp(testRunDir, "*")
 	if err != nil {
@@ -124,9 +483,45 @@ func createMockProc() (*string, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = copyFile(loadAvg1, filepath.Join(generatedProcPath, defaultLoadAvgFile))
+	if err != nil {
+		return nil, err
+	}
 	return &generatedProcPath, nil
 }
 
+// addPressureFiles copies each $RESOURCE-1 file found in pressureDataDir into
+// testDir/proc/pressure/$RESOURCE.
+func addPressureFiles(testDir, pressureDataDir string) error {
+	generatedPressurePath := filepath.Join(testDir, procFolder, pressureFolder)
+	err := os.Mkdir(generatedPressurePath, 0777)
+	if err != nil {
+		return err
+	}
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		err = copyFile(filepath.Join(pressureDataDir, resource+"-1"), filepath.Join(generatedPressurePath, resource))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+	_, err = io.Copy(dstFile, srcFile)
+	return err
+}
+
 func addCPUInfoFile(testDir, cpuInfoFile string) error {
 	cpuInfoDataFile, err := os.Open(cpuInfoFile)
 	if err != nil {
@@ -0,0 +1,351 @@
+package host
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the files of procfs identifying the container of a process and the
+	// mounts of its filesystem.
+	cgroupFile    = "cgroup"
+	mountInfoFile = "mountinfo"
+	// the filesystem type of the overlay mounts container runtimes assemble
+	// containers' filesystems with.
+	overlayFSType = "overlay"
+	// the suffix of the layer Docker adds between the image's layers and the
+	// container's writable layer, holding files such as /etc/hosts.
+	dockerInitLayerSuffix = "-init/diff"
+)
+
+var (
+	// containerCgroup matches the cgroup of a container, as named by Docker,
+	// Podman, containerd, and CRI-O with either the cgroupfs or systemd
+	// cgroup driver (e.g. docker-<id>.scope or /docker/<id>).
+	containerCgroup = regexp.MustCompile(`^(?:(?:docker|libpod|cri-containerd|crio)-)?([0-9a-f]{64})(?:\.scope)?$`)
+)
+
+// Layer is a layer of a container image.
+type Layer struct {
+	// The position of the layer in the image, from 0 for its first (base)
+	// layer.
+	Index int
+	// The digest of the layer's uncompressed contents (e.g. sha256:...).
+	// Only known for containers of Docker-compatible engines.
+	DiffID string `json:",omitempty"`
+	// The instruction that created the layer, from the image's history (e.g.
+	// RUN apt-get install -y curl). Only known for containers of
+	// Docker-compatible engines.
+	CreatedBy string `json:",omitempty"`
+	// The directory holding the layer's files on the host.
+	Dir string
+}
+
+// ContainerLayers are the layers of a container's filesystem: those of its
+// image and the writable layer files added or modified at runtime are
+// written to.
+type ContainerLayers struct {
+	ContainerID string
+	// The image reference the container was created from (e.g. nginx:1.23).
+	// Only known for containers of Docker-compatible engines.
+	Image string `json:",omitempty"`
+	// The image's layers, from its first (base) layer.
+	Layers []Layer
+	// The directory holding the container's writable layer on the host.
+	UpperDir string
+}
+
+// FileOrigin is where a file in a container's filesystem came from.
+type FileOrigin struct {
+	Path string
+	// Whether the file was added or modified after the container started,
+	// rather than coming from its image.
+	AddedAtRuntime bool
+	// The image layer that provided the file, nil when it was added at
+	// runtime.
+	Layer *Layer `json:",omitempty"`
+}
+
+// dockerContainerLayers is the subset of the Docker Engine API's container
+// inspect response used to resolve a container's layers.
+type dockerContainerLayers struct {
+	ID     string `json:"Id"`
+	Image  string
+	Config struct {
+		Image string
+	}
+	GraphDriver struct {
+		Name string
+		Data map[string]string
+	}
+}
+
+// dockerImage is the subset of the Docker Engine API's image inspect
+// response used to resolve an image's layers.
+type dockerImage struct {
+	RootFS struct {
+		Layers []string
+	}
+}
+
+// dockerHistory is an entry of the Docker Engine API's image history
+// response, newest first.
+type dockerHistory struct {
+	CreatedBy string
+}
+
+// GetProcessContainerID returns the ID of the container process pid runs in,
+// read from its cgroup. An empty ID is returned for processes that don't run
+// in a container.
+func (h *LinuxReader) GetProcessContainerID(pid int) (string, error) {
+	contents, err := os.ReadFile(filepath.Join(h.procDir, strconv.Itoa(pid), cgroupFile))
+	if err != nil {
+		return "", fmt.Errorf("failed reading cgroup of process %d: %s", pid, err)
+	}
+	id := ""
+	scanner := bufio.NewScanner(strings.NewReader(string(contents)))
+	for scanner.Scan() {
+		// lines are hierarchy-ID:controllers:path; the container is the
+		// innermost cgroup naming one.
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		for _, segment := range strings.Split(fields[2], "/") {
+			if m := containerCgroup.FindStringSubmatch(segment); m != nil {
+				id = m[1]
+			}
+		}
+	}
+	return id, nil
+}
+
+// GetProcessLayers returns the layers of the filesystem of the container
+// process pid runs in. The layers are resolved with the Docker-compatible
+// engine running the container, or, for containers of other runtimes such as
+// containerd, from the overlay mount of the process's root filesystem, in
+// which case the layers' diff IDs and history aren't known. An error is
+// returned for processes that don't run in a container.
+func (h *LinuxReader) GetProcessLayers(ctx context.Context, pid int) (*ContainerLayers, error) {
+	id, err := h.GetProcessContainerID(pid)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, fmt.Errorf("process %d doesn't run in a container", pid)
+	}
+	layers, engineErr := h.GetContainerLayers(ctx, id)
+	if engineErr == nil {
+		return layers, nil
+	}
+	layers, err = h.getMountLayers(pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed resolving layers of container %s: %s; %s", id, engineErr, err)
+	}
+	layers.ContainerID = id
+	return layers, nil
+}
+
+// GetContainerLayers returns the layers of the filesystem of the container
+// identified by id, as reported by the Docker-compatible engines whose
+// sockets are present on the host. Only containers stored with the overlay
+// storage driver, the default of Docker and Podman, are supported.
+func (h *LinuxReader) GetContainerLayers(ctx context.Context, id string) (*ContainerLayers, error) {
+	var lastErr error
+	for _, socket := range h.containerSockets {
+		if _, err := os.Stat(socket); err != nil {
+			continue
+		}
+		layers, err := h.getDockerContainerLayers(ctx, socket, id)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return layers, nil
+	}
+	if lastErr == nil {
+		return nil, fmt.Errorf("failed to find a container runtime socket in: %s", strings.Join(h.containerSockets, ", "))
+	}
+	return nil, lastErr
+}
+
+// getDockerContainerLayers resolves the layers of the container identified
+// by id from the engine listening on socketPath.
+func (h *LinuxReader) getDockerContainerLayers(ctx context.Context, socketPath string, id string) (*ContainerLayers, error) {
+	client := newUnixSocketClient(socketPath)
+	var c dockerContainerLayers
+	if err := getDockerJSON(ctx, client, "/containers/"+id+"/json", &c); err != nil {
+		return nil, fmt.Errorf("failed inspecting container %s from %s. Error was: %s", id, socketPath, err)
+	}
+	if !strings.HasPrefix(c.GraphDriver.Name, overlayFSType) || c.GraphDriver.Data["UpperDir"] == "" {
+		return nil, fmt.Errorf("container %s is stored with the %q storage driver; only overlay is supported", id, c.GraphDriver.Name)
+	}
+	layers := &ContainerLayers{ContainerID: c.ID, Image: c.Config.Image, UpperDir: h.rebase(c.GraphDriver.Data["UpperDir"])}
+	lowerDirs := []string{}
+	for _, dir := range strings.Split(c.GraphDriver.Data["LowerDir"], ":") {
+		if dir != "" && !strings.HasSuffix(dir, dockerInitLayerSuffix) {
+			lowerDirs = append(lowerDirs, h.rebase(dir))
+		}
+	}
+	layers.Layers = newLayers(lowerDirs)
+
+	// the layers are only described when they line up with the image's, since
+	// a layer described wrongly is worse than one not described.
+	var image dockerImage
+	if err := getDockerJSON(ctx, client, "/images/"+c.Image+"/json", &image); err != nil || len(image.RootFS.Layers) != len(layers.Layers) {
+		return layers, nil
+	}
+	for i := range layers.Layers {
+		layers.Layers[i].DiffID = image.RootFS.Layers[i]
+	}
+	var history []dockerHistory
+	if err := getDockerJSON(ctx, client, "/images/"+c.Image+"/history", &history); err != nil {
+		return layers, nil
+	}
+	createdBy := []string{}
+	for i := len(history) - 1; i >= 0; i-- {
+		if createsLayer(history[i].CreatedBy) {
+			createdBy = append(createdBy, strings.TrimSpace(history[i].CreatedBy))
+		}
+	}
+	if len(createdBy) == len(layers.Layers) {
+		for i := range layers.Layers {
+			layers.Layers[i].CreatedBy = createdBy[i]
+		}
+	}
+	return layers, nil
+}
+
+// createsLayer returns whether the image history instruction createdBy
+// created a layer, rather than only changing the image's configuration like
+// ENV does. Instructions are recorded as run by the shell by the classic
+// builder (e.g. /bin/sh -c #(nop) COPY ...) and as written by BuildKit.
+func createsLayer(createdBy string) bool {
+	createdBy = strings.TrimSpace(createdBy)
+	if rest, ok := strings.CutPrefix(createdBy, "/bin/sh -c #(nop) "); ok {
+		rest = strings.TrimSpace(rest)
+		return strings.HasPrefix(rest, "ADD ") || strings.HasPrefix(rest, "COPY ")
+	}
+	for _, prefix := range []string{"/bin/sh -c ", "RUN ", "ADD ", "COPY "} {
+		if strings.HasPrefix(createdBy, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getMountLayers resolves the layers of the filesystem of the container
+// process pid runs in from the overlay mounted as its root.
+func (h *LinuxReader) getMountLayers(pid int) (*ContainerLayers, error) {
+	f, err := os.Open(filepath.Join(h.procDir, strconv.Itoa(pid), mountInfoFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed reading mounts of process %d: %s", pid, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// lines are: ID parent-ID major:minor root mount-point options
+		// [optional fields...] - type source super-options.
+		fields := strings.Fields(scanner.Text())
+		sep := -1
+		for i, field := range fields {
+			if field == "-" {
+				sep = i
+				break
+			}
+		}
+		if len(fields) < 5 || fields[4] != "/" || sep < 0 || len(fields) < sep+4 || fields[sep+1] != overlayFSType {
+			continue
+		}
+		layers := &ContainerLayers{}
+		lowerDirs := []string{}
+		for _, option := range strings.Split(fields[sep+3], ",") {
+			key, value, _ := strings.Cut(option, "=")
+			switch key {
+			case "lowerdir":
+				for _, dir := range strings.Split(value, ":") {
+					lowerDirs = append(lowerDirs, h.rebase(dir))
+				}
+			case "upperdir":
+				layers.UpperDir = h.rebase(value)
+			}
+		}
+		layers.Layers = newLayers(lowerDirs)
+		return layers, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading mounts of process %d: %s", pid, err)
+	}
+	return nil, fmt.Errorf("the root filesystem of process %d isn't an overlay", pid)
+}
+
+// newLayers returns the layers in lowerDirs, which are ordered from the top
+// (last) layer, as overlay mounts order them.
+func newLayers(lowerDirs []string) []Layer {
+	layers := make([]Layer, len(lowerDirs))
+	for i, dir := range lowerDirs {
+		index := len(lowerDirs) - 1 - i
+		layers[index] = Layer{Index: index, Dir: dir}
+	}
+	return layers
+}
+
+// rebase returns path within the root filesystem the reader inspects.
+func (h *LinuxReader) rebase(path string) string {
+	if h.rootFS == "" {
+		return path
+	}
+	return filepath.Join(h.rootFS, path)
+}
+
+// Origin returns where the file at path, within the container's filesystem,
+// came from: the container's writable layer, when it was added or modified
+// at runtime, or otherwise the top-most image layer holding it. An error is
+// returned when the file isn't in any layer or was deleted.
+func (c *ContainerLayers) Origin(path string) (*FileOrigin, error) {
+	path = filepath.Clean("/" + path)
+	origin := &FileOrigin{Path: path}
+	found, err := inLayer(c.UpperDir, path)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		origin.AddedAtRuntime = true
+		return origin, nil
+	}
+	for i := len(c.Layers) - 1; i >= 0; i-- {
+		found, err := inLayer(c.Layers[i].Dir, path)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			layer := c.Layers[i]
+			origin.Layer = &layer
+			return origin, nil
+		}
+	}
+	return nil, fmt.Errorf("%s wasn't found in the layers of container %s", path, c.ContainerID)
+}
+
+// inLayer returns whether the layer in dir holds the file at path. An error
+// is returned when the layer deletes the file, which overlay marks with a
+// character device (a whiteout) in its place.
+func inLayer(dir string, path string) (bool, error) {
+	if dir == "" {
+		return false, nil
+	}
+	info, err := os.Lstat(filepath.Join(dir, path))
+	if err != nil {
+		return false, nil
+	}
+	if info.Mode()&os.ModeCharDevice != 0 {
+		return false, fmt.Errorf("%s was deleted from the container's filesystem", path)
+	}
+	return true, nil
+}
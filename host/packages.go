@@ -0,0 +1,225 @@
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultDpkgDirPath = "/var/lib/dpkg"
+	DefaultAPKDBPath   = "/lib/apk/db/installed"
+	DefaultRPMDBPath   = "/var/lib/rpm"
+	// The file, within the dpkg directory, listing every package dpkg knows of.
+	dpkgStatusFile = "status"
+	// The directory, within the dpkg directory, holding the files installed by
+	// each package in a <package>.list file.
+	dpkgInfoDir = "info"
+)
+
+// Package manager names reported in [Package].
+const (
+	DpkgManager = "dpkg"
+	APKManager  = "apk"
+)
+
+// Package represents an operating-system package installed on the host.
+type Package struct {
+	// The package manager that installed the package, such as dpkg or apk.
+	Manager string
+	Name    string
+	Version string
+	// The source package the package was built from, such as glibc for
+	// libc6. Empty when the package was built from a source package of the
+	// same name.
+	Source string
+	// The architecture the package was built for (e.g. amd64), if any.
+	Arch string
+	// The absolute paths of the files installed by the package, sorted.
+	Files []string
+}
+
+// GetPackages enumerates the packages installed on the host by reading the
+// databases of the dpkg (Debian, Ubuntu) and apk (Alpine) package managers.
+// Databases are resolved relative to RootFS when it is set. Today, rpm's
+// database, a Berkeley DB or SQLite file, is not supported.
+//
+// An error is returned when no supported package database is found.
+func (h *LinuxReader) GetPackages() ([]Package, error) {
+	packages := []Package{}
+	found := false
+	if _, err := os.Stat(filepath.Join(h.dpkgDir, dpkgStatusFile)); err == nil {
+		found = true
+		ps, err := h.getDpkgPackages()
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, ps...)
+	}
+	if _, err := os.Stat(h.apkDBPath); err == nil {
+		found = true
+		ps, err := h.getAPKPackages()
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, ps...)
+	}
+	if !found {
+		if _, err := os.Stat(h.rpmDBDir); err == nil {
+			return nil, fmt.Errorf("failed reading packages; rpm databases (%s) are not supported", h.rpmDBDir)
+		}
+		return nil, fmt.Errorf("failed reading packages; no dpkg (%s) or apk (%s) database was found", h.dpkgDir, h.apkDBPath)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Arch < packages[j].Arch
+	})
+	return packages, nil
+}
+
+// getDpkgPackages reads the packages installed by dpkg from its status file
+// and the files each installed from its <package>.list file.
+func (h *LinuxReader) getDpkgPackages() ([]Package, error) {
+	statusPath := filepath.Join(h.dpkgDir, dpkgStatusFile)
+	data, err := os.ReadFile(statusPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading dpkg status at %s. Error was: %s", statusPath, err)
+	}
+	packages := []Package{}
+	for _, fields := range parseStanzas(data) {
+		// packages that were removed, but not purged, remain in the status
+		// file with a status such as "deinstall ok config-files".
+		if !strings.HasSuffix(fields["Status"], " installed") {
+			continue
+		}
+		// the source includes its version when it differs from the
+		// package's, e.g. glibc (2.36-9).
+		source, _, _ := strings.Cut(fields["Source"], " ")
+		p := Package{
+			Manager: DpkgManager,
+			Name:    fields["Package"],
+			Version: fields["Version"],
+			Source:  source,
+			Arch:    fields["Architecture"],
+		}
+		// multi-arch packages are listed as <package>:<arch>.list.
+		for _, list := range []string{p.Name + ":" + p.Arch + ".list", p.Name + ".list"} {
+			files, err := readDpkgList(filepath.Join(h.dpkgDir, dpkgInfoDir, list))
+			if err == nil {
+				p.Files = files
+				break
+			}
+		}
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+// readDpkgList returns the files, excluding directories, in the dpkg .list
+// file at path.
+func readDpkgList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	dirs := map[string]bool{}
+	for _, l := range lines {
+		dirs[filepath.Dir(l)] = true
+	}
+	files := []string{}
+	for _, l := range lines {
+		if l != "" && l != "/." && !dirs[l] {
+			files = append(files, l)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// getAPKPackages reads the packages, and the files each installed, from apk's
+// installed database.
+func (h *LinuxReader) getAPKPackages() ([]Package, error) {
+	f, err := os.Open(h.apkDBPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading apk database at %s. Error was: %s", h.apkDBPath, err)
+	}
+	defer f.Close()
+	packages := []Package{}
+	var p *Package
+	dir := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if p != nil {
+				sort.Strings(p.Files)
+				packages = append(packages, *p)
+			}
+			p, dir = nil, ""
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if p == nil {
+			p = &Package{Manager: APKManager, Files: []string{}}
+		}
+		switch key {
+		case "P":
+			p.Name = value
+		case "V":
+			p.Version = value
+		case "A":
+			p.Arch = value
+		case "o":
+			// apk records the origin of every package, even one of the same
+			// name.
+			if value != p.Name {
+				p.Source = value
+			}
+		case "F":
+			dir = value
+		case "R":
+			p.Files = append(p.Files, "/"+filepath.Join(dir, value))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading apk database at %s. Error was: %s", h.apkDBPath, err)
+	}
+	if p != nil {
+		sort.Strings(p.Files)
+		packages = append(packages, *p)
+	}
+	return packages, nil
+}
+
+// parseStanzas parses the blank-line separated stanzas of "Key: value" fields
+// used by dpkg's status file. Continuation lines, which begin with a space,
+// are ignored.
+func parseStanzas(data []byte) []map[string]string {
+	stanzas := []map[string]string{}
+	for _, block := range bytes.Split(data, []byte("\n\n")) {
+		fields := map[string]string{}
+		for _, line := range strings.Split(string(block), "\n") {
+			if line == "" || line[0] == ' ' || line[0] == '\t' {
+				continue
+			}
+			key, value, ok := strings.Cut(line, ":")
+			if ok {
+				fields[key] = strings.TrimSpace(value)
+			}
+		}
+		if len(fields) > 0 {
+			stanzas = append(stanzas, fields)
+		}
+	}
+	return stanzas
+}
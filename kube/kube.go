@@ -0,0 +1,320 @@
+// Package kube attributes the processes of a Kubernetes node to the pods and
+// containers running them, for running proctor as a DaemonSet. Processes are
+// matched to pods by the cgroup the kubelet places their containers in, and
+// pods are named by the Kubernetes API.
+package kube
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// The annotations added to the processes of pods by [Attributor.Enrich].
+const (
+	PodNameAnnotation       = "k8s.pod.name"
+	PodNamespaceAnnotation  = "k8s.pod.namespace"
+	PodUIDAnnotation        = "k8s.pod.uid"
+	ContainerNameAnnotation = "k8s.container.name"
+	ContainerIDAnnotation   = "k8s.container.id"
+)
+
+const (
+	// NodeNameEnv is the environment variable the name of the node is read
+	// from, set in the DaemonSet with the downward API.
+	NodeNameEnv = "NODE_NAME"
+	// DefaultProcfsPath is where the DaemonSet mounts the node's procfs.
+	DefaultProcfsPath = "/host/proc"
+	// ServiceAccountDir is where the pod's service account token and the
+	// cluster's CA certificate are mounted.
+	ServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	// the least amount of time between listing the node's pods, so processes
+	// of pods that were deleted don't cause a request every scan.
+	minRefreshInterval = 10 * time.Second
+	// how long the Kubernetes API is given to respond.
+	apiTimeout = 30 * time.Second
+	cgroupFile = "cgroup"
+)
+
+var (
+	// podCgroup matches the cgroup of a pod, as named by the kubelet's cgroupfs
+	// (pod<uid>) and systemd (kubepods-burstable-pod<uid>.slice) drivers. The
+	// systemd driver replaces the dashes of the UID with underscores.
+	podCgroup = regexp.MustCompile(`^(?:kubepods(?:-[a-z]+)*-)?pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})(?:\.slice)?$`)
+	// containerCgroup matches the cgroup of a container within a pod's, as
+	// named by containerd, CRI-O, and Docker.
+	containerCgroup = regexp.MustCompile(`^(?:(?:cri-containerd|crio|docker)-)?([0-9a-f]{64})(?:\.scope)?$`)
+)
+
+// Pod is a pod scheduled to a node.
+type Pod struct {
+	UID       string
+	Name      string
+	Namespace string
+	// The names of the pod's containers, keyed by container ID (without the
+	// runtime prefix, e.g. containerd://).
+	Containers map[string]string
+}
+
+// Node is a node of the cluster.
+type Node struct {
+	Name   string
+	Labels map[string]string
+}
+
+// Client is a minimal client of the Kubernetes API, authenticating with a
+// service account token.
+type Client struct {
+	// The URL of the API server, e.g. https://10.96.0.1:443.
+	URL string
+	// The file the bearer token is read from on each request, since
+	// projected service account tokens are rotated.
+	TokenFile  string
+	HTTPClient *http.Client
+}
+
+// NewInClusterClient returns a client of the cluster the pod runs in, using
+// the pod's service account.
+func NewInClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("failed finding the Kubernetes API; KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set, as they are in pods")
+	}
+	ca, err := os.ReadFile(filepath.Join(ServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed reading the cluster's CA certificate: %s", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("the cluster's CA certificate contains no PEM encoded certificates")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &Client{
+		URL:        "https://" + net.JoinHostPort(host, port),
+		TokenFile:  filepath.Join(ServiceAccountDir, "token"),
+		HTTPClient: &http.Client{Transport: transport, Timeout: apiTimeout},
+	}, nil
+}
+
+// podList is the subset of the API's pod list response used to create pods.
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			UID       string
+			Name      string
+			Namespace string
+		}
+		Status struct {
+			ContainerStatuses          []containerStatus
+			InitContainerStatuses      []containerStatus
+			EphemeralContainerStatuses []containerStatus
+		}
+	}
+}
+
+type containerStatus struct {
+	Name        string
+	ContainerID string
+}
+
+// Pods returns the pods scheduled to the node named node.
+func (c *Client) Pods(ctx context.Context, node string) ([]Pod, error) {
+	list := podList{}
+	query := url.Values{"fieldSelector": {"spec.nodeName=" + node}}
+	if err := c.get(ctx, "/api/v1/pods?"+query.Encode(), &list); err != nil {
+		return nil, fmt.Errorf("failed listing pods of node %s: %s", node, err)
+	}
+	pods := []Pod{}
+	for _, item := range list.Items {
+		p := Pod{UID: item.Metadata.UID, Name: item.Metadata.Name, Namespace: item.Metadata.Namespace, Containers: map[string]string{}}
+		statuses := append(append(item.Status.ContainerStatuses, item.Status.InitContainerStatuses...), item.Status.EphemeralContainerStatuses...)
+		for _, s := range statuses {
+			if s.ContainerID == "" {
+				continue
+			}
+			// IDs are prefixed by their runtime, e.g. containerd://<id>.
+			_, id, _ := strings.Cut(s.ContainerID, "://")
+			p.Containers[id] = s.Name
+		}
+		pods = append(pods, p)
+	}
+	return pods, nil
+}
+
+// Node returns the node named name.
+func (c *Client) Node(ctx context.Context, name string) (*Node, error) {
+	node := struct {
+		Metadata struct {
+			Name   string
+			Labels map[string]string
+		}
+	}{}
+	if err := c.get(ctx, "/api/v1/nodes/"+url.PathEscape(name), &node); err != nil {
+		return nil, fmt.Errorf("failed getting node %s: %s", name, err)
+	}
+	return &Node{Name: node.Metadata.Name, Labels: node.Metadata.Labels}, nil
+}
+
+// get decodes the JSON response of the API to a GET of path into v.
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.URL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.TokenFile != "" {
+		token, err := os.ReadFile(c.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed reading service account token: %s", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("API responded %s %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// ParseCgroup returns the UID of the pod and the ID of the container a
+// process runs in, from the contents of its /proc/${PID}/cgroup file. Both
+// are empty for processes that don't run in a pod, and the container ID is
+// empty for processes of the pod that aren't in a container, such as its
+// sandbox on some runtimes.
+func ParseCgroup(contents string) (podUID string, containerID string) {
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		// lines are hierarchy-ID:controllers:path.
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		segments := strings.Split(fields[2], "/")
+		for i, segment := range segments {
+			m := podCgroup.FindStringSubmatch(segment)
+			if m == nil {
+				continue
+			}
+			podUID = strings.ReplaceAll(m[1], "_", "-")
+			if i+1 < len(segments) {
+				if m := containerCgroup.FindStringSubmatch(segments[i+1]); m != nil {
+					containerID = m[1]
+				}
+			}
+			return podUID, containerID
+		}
+	}
+	return "", ""
+}
+
+// Attributor annotates the processes of a node with the pods and containers
+// running them.
+type Attributor struct {
+	client *Client
+	node   string
+	procfs string
+	// the pods of the node, keyed by UID, as last listed.
+	pods        map[string]Pod
+	lastRefresh time.Time
+	lock        sync.Mutex
+}
+
+// NewAttributor returns an attributor of the processes of the node named
+// node, whose procfs is mounted at procfs. Pods are listed with client.
+func NewAttributor(client *Client, node string, procfs string) *Attributor {
+	return &Attributor{client: client, node: node, procfs: procfs, pods: map[string]Pod{}}
+}
+
+// Enrich annotates the processes of ps running in pods with their pod's
+// UID, name, and namespace and their container's ID and name. The node's
+// pods are listed again when a process runs in a pod or container that
+// wasn't seen before. When listing pods fails, processes are still annotated
+// with the UID and container ID found in their cgroup, and the error is
+// returned.
+func (a *Attributor) Enrich(ctx context.Context, ps plib.Processes) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	type placement struct{ podUID, containerID string }
+	placements := map[int]placement{}
+	stale := false
+	for id := range ps {
+		contents, err := os.ReadFile(filepath.Join(a.procfs, strconv.Itoa(id), cgroupFile))
+		if err != nil {
+			continue
+		}
+		podUID, containerID := ParseCgroup(string(contents))
+		if podUID == "" {
+			continue
+		}
+		placements[id] = placement{podUID, containerID}
+		pod, ok := a.pods[podUID]
+		if !ok || containerID != "" && pod.Containers[containerID] == "" {
+			stale = true
+		}
+	}
+
+	var err error
+	if stale && time.Since(a.lastRefresh) >= minRefreshInterval {
+		err = a.refresh(ctx)
+	}
+	for id, pl := range placements {
+		p := ps[id]
+		if p.Annotations == nil {
+			p.Annotations = map[string]string{}
+		}
+		p.Annotations[PodUIDAnnotation] = pl.podUID
+		if pl.containerID != "" {
+			p.Annotations[ContainerIDAnnotation] = pl.containerID
+		}
+		pod, ok := a.pods[pl.podUID]
+		if !ok {
+			continue
+		}
+		p.Annotations[PodNameAnnotation] = pod.Name
+		p.Annotations[PodNamespaceAnnotation] = pod.Namespace
+		if name := pod.Containers[pl.containerID]; name != "" {
+			p.Annotations[ContainerNameAnnotation] = name
+		}
+	}
+	return err
+}
+
+// refresh lists the node's pods.
+func (a *Attributor) refresh(ctx context.Context) error {
+	a.lastRefresh = time.Now()
+	pods, err := a.client.Pods(ctx, a.node)
+	if err != nil {
+		return err
+	}
+	a.pods = map[string]Pod{}
+	for _, p := range pods {
+		a.pods[p.UID] = p
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+var (
+	testPodUID       = "7c1f5b4e-2a3d-4f6e-9b8a-1c2d3e4f5a6b"
+	testContainerID  = strings.Repeat("ab", 32)
+	testContainerID2 = strings.Repeat("cd", 32)
+)
+
+func TestParseCgroup(t *testing.T) {
+	tests := []struct {
+		name        string
+		cgroup      string
+		podUID      string
+		containerID string
+	}{
+		{
+			"cgroup v1 cgroupfs",
+			"12:pids:/kubepods/burstable/pod" + testPodUID + "/" + testContainerID + "\n11:memory:/kubepods/burstable/pod" + testPodUID + "/" + testContainerID,
+			testPodUID, testContainerID,
+		},
+		{
+			"cgroup v2 systemd containerd",
+			"0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod" + strings.ReplaceAll(testPodUID, "-", "_") + ".slice/cri-containerd-" + testContainerID + ".scope",
+			testPodUID, testContainerID,
+		},
+		{
+			"guaranteed pod systemd crio",
+			"0::/kubepods.slice/kubepods-pod" + strings.ReplaceAll(testPodUID, "-", "_") + ".slice/crio-" + testContainerID + ".scope",
+			testPodUID, testContainerID,
+		},
+		{
+			"pod without container",
+			"0::/kubepods.slice/kubepods-pod" + strings.ReplaceAll(testPodUID, "-", "_") + ".slice",
+			testPodUID, "",
+		},
+		{"host process", "0::/system.slice/sshd.service", "", ""},
+		{"docker container", "0::/system.slice/docker-" + testContainerID + ".scope", "", ""},
+	}
+	for _, test := range tests {
+		podUID, containerID := ParseCgroup(test.cgroup)
+		if podUID != test.podUID || containerID != test.containerID {
+			t.Logf("fail: %s expected pod %q and container %q, actual: %q and %q", test.name, test.podUID, test.containerID, podUID, containerID)
+			t.Fail()
+		}
+	}
+}
+
+// writeCgroup writes the cgroup file of process pid to the procfs at dir.
+func writeCgroup(t *testing.T, dir string, pid int, contents string) {
+	pidDir := filepath.Join(dir, fmt.Sprint(pid))
+	if err := os.MkdirAll(pidDir, 0755); err != nil {
+		t.Fatalf("failed setting up sample data for test: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(pidDir, cgroupFile), []byte(contents), 0644); err != nil {
+		t.Fatalf("failed setting up sample data for test: %s", err)
+	}
+}
+
+func TestAttributor(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/v1/pods" || r.URL.Query().Get("fieldSelector") != "spec.nodeName=node-1" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"items": [{"metadata": {"uid": %q, "name": "web-0", "namespace": "shop"}, "status": {"containerStatuses": [{"name": "nginx", "containerID": "containerd://%s"}]}}]}`, testPodUID, testContainerID)
+	}))
+	defer server.Close()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(tokenFile, []byte("secret\n"), 0600)
+
+	procfs := t.TempDir()
+	writeCgroup(t, procfs, 1, "0::/init.scope")
+	writeCgroup(t, procfs, 10, "0::/kubepods.slice/kubepods-pod"+strings.ReplaceAll(testPodUID, "-", "_")+".slice/cri-containerd-"+testContainerID+".scope")
+	writeCgroup(t, procfs, 11, "0::/kubepods.slice/kubepods-pod"+strings.ReplaceAll(testPodUID, "-", "_")+".slice/cri-containerd-"+testContainerID2+".scope")
+	ps := plib.Processes{
+		1:  {ID: 1, CommandName: "systemd"},
+		10: {ID: 10, CommandName: "nginx"},
+		11: {ID: 11, CommandName: "sidecar"},
+		12: {ID: 12, CommandName: "exited"},
+	}
+
+	a := NewAttributor(&Client{URL: server.URL, TokenFile: tokenFile}, "node-1", procfs)
+	if err := a.Enrich(context.Background(), ps); err != nil {
+		t.Fatalf("fail: unexpected error enriching processes: %s", err)
+	}
+	expected := map[string]string{
+		PodUIDAnnotation:        testPodUID,
+		PodNameAnnotation:       "web-0",
+		PodNamespaceAnnotation:  "shop",
+		ContainerIDAnnotation:   testContainerID,
+		ContainerNameAnnotation: "nginx",
+	}
+	for k, v := range expected {
+		if ps[10].Annotations[k] != v {
+			t.Logf("fail: expected process 10 to be annotated %s=%s, actual: %v", k, v, ps[10].Annotations)
+			t.Fail()
+		}
+	}
+	if ps[1].Annotations != nil || ps[12].Annotations != nil {
+		t.Logf("fail: expected processes outside of pods not to be annotated, actual: %v, %v", ps[1].Annotations, ps[12].Annotations)
+		t.Fail()
+	}
+	if ps[11].Annotations[PodNameAnnotation] != "web-0" || ps[11].Annotations[ContainerNameAnnotation] != "" {
+		t.Logf("fail: expected process 11 to be annotated with its pod but no container name, actual: %v", ps[11].Annotations)
+		t.Fail()
+	}
+
+	// the unknown container of process 11 doesn't list pods again so soon.
+	a.Enrich(context.Background(), ps)
+	if requests != 1 {
+		t.Logf("fail: expected pods to be listed once, actual: %d", requests)
+		t.Fail()
+	}
+}
@@ -0,0 +1,70 @@
+// Package logging configures the structured logger proctor's packages write
+// diagnostics to. Packages log through the default [slog.Logger] (e.g.
+// slog.Debug), so programs importing them control where and how records are
+// written with [slog.SetDefault] or [Configure].
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+const (
+	// TextFormat writes records as key=value pairs.
+	TextFormat = "text"
+	// JSONFormat writes records as a JSON object per line.
+	JSONFormat = "json"
+)
+
+// Config determines which records a logger writes and how.
+type Config struct {
+	// The minimum level of records written. Defaults to slog.LevelInfo.
+	Level slog.Level
+	// The format records are written in, [TextFormat] (default) or
+	// [JSONFormat].
+	Format string
+	// Where records are written. Defaults to os.Stderr.
+	Output io.Writer
+}
+
+// New creates a logger from c. An error is returned when c.Format isn't
+// supported.
+func New(c Config) (*slog.Logger, error) {
+	if c.Output == nil {
+		c.Output = os.Stderr
+	}
+	handlerOpts := &slog.HandlerOptions{Level: c.Level}
+	switch c.Format {
+	case "", TextFormat:
+		return slog.New(slog.NewTextHandler(c.Output, handlerOpts)), nil
+	case JSONFormat:
+		return slog.New(slog.NewJSONHandler(c.Output, handlerOpts)), nil
+	}
+	return nil, fmt.Errorf("log format (%s) is not supported, expected %s or %s", c.Format, TextFormat, JSONFormat)
+}
+
+// Configure creates a logger from c, see [New], and makes it the default
+// logger.
+func Configure(c Config) error {
+	logger, err := New(c)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// LevelFor returns the level of records written by a program run with
+// verbose or quiet output. Verbose includes debug records, quiet only
+// includes errors, and otherwise informational records and above are written.
+func LevelFor(verbose, quiet bool) slog.Level {
+	switch {
+	case verbose:
+		return slog.LevelDebug
+	case quiet:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(Config{Level: LevelFor(false, false), Format: JSONFormat, Output: &buf})
+	if err != nil {
+		t.Fatalf("fail: unexpected error creating logger: %s", err)
+	}
+	logger.Debug("hidden")
+	logger.Info("shown", "pid", 1)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("fail: expected only the info record to be written, actual: %q", buf.String())
+	}
+	record := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("fail: record was not JSON: %s", err)
+	}
+	if record["msg"] != "shown" || record["pid"] != float64(1) {
+		t.Logf("fail: unexpected record: %v", record)
+		t.Fail()
+	}
+
+	if _, err := New(Config{Format: "xml"}); err == nil {
+		t.Logf("fail: expected an error for an unsupported format")
+		t.Fail()
+	}
+}
+
+func TestLevelFor(t *testing.T) {
+	tests := []struct {
+		verbose, quiet bool
+		expected       slog.Level
+	}{
+		{false, false, slog.LevelInfo},
+		{true, false, slog.LevelDebug},
+		{false, true, slog.LevelError},
+	}
+	for _, test := range tests {
+		if actual := LevelFor(test.verbose, test.quiet); actual != test.expected {
+			t.Logf("fail: level for verbose %t and quiet %t was wrong, expected: %s, actual: %s", test.verbose, test.quiet, test.expected, actual)
+			t.Fail()
+		}
+	}
+}
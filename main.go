@@ -0,0 +1,23 @@
+// Proctor is a command-line tool for inspecting software, from source to
+// runtime. This package lets it be installed from the root of the module:
+//
+//	go install github.com/arctir/proctor@latest
+//
+// It builds the same CLI as the proctor directory.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arctir/proctor/proctor/cmd"
+)
+
+func main() {
+	proctorCmd := cmd.SetupCLI()
+
+	if err := proctorCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
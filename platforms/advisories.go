@@ -0,0 +1,39 @@
+package platforms
+
+import "time"
+
+// Advisory is a security advisory published by the maintainers of a
+// repository, describing a vulnerability in its releases.
+type Advisory struct {
+	// The platform's identifier of the advisory, e.g. GHSA-xxxx-xxxx-xxxx.
+	ID string
+	// The CVE assigned to the vulnerability, if any.
+	CVE      string
+	Summary  string
+	Severity string
+	URL      string
+	// When the advisory was published.
+	Published time.Time
+	// The packages affected by the vulnerability.
+	Affected []AffectedPackage
+}
+
+// AffectedPackage is a package, and its versions, affected by an [Advisory].
+type AffectedPackage struct {
+	Ecosystem string
+	Name      string
+	// The affected versions, as a comma separated list of constraints (e.g.
+	// ">= 1.0.0, < 1.2.3").
+	VulnerableVersions string
+	// The versions the vulnerability is fixed in, e.g. 1.2.3.
+	PatchedVersions string
+}
+
+// AdvisorySource is implemented by platforms that publish security
+// advisories for the repositories they host. Use a type assertion to check
+// whether a [Platform] supports it.
+type AdvisorySource interface {
+	// GetSecurityAdvisories returns the published security advisories of a
+	// repository, newest first.
+	GetSecurityAdvisories(repo string) ([]Advisory, error)
+}
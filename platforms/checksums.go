@@ -0,0 +1,109 @@
+package platforms
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+var (
+	// matches the names of checksums files commonly attached to releases,
+	// such as SHA256SUMS, checksums.txt, or proctor_1.0.0_checksums.txt.
+	checksumsFileRegex = regexp.MustCompile(`(?i)(^|[._-])(sha(1|256|512)sums|checksums)(\.txt)?$`)
+	// matches a line of a checksums file in the BSD style output by
+	// `shasum --tag`, e.g. SHA256 (proctor) = <hex>.
+	bsdChecksumRegex = regexp.MustCompile(`^(SHA1|SHA256|SHA512) \((.+)\) = ([0-9a-fA-F]+)$`)
+	// matches a hex encoded digest.
+	hexDigestRegex = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+)
+
+// The digest algorithms of checksums files, keyed by the length of their hex
+// encoded digests.
+var digestAlgorithms = map[int]string{
+	40:  "sha1",
+	64:  "sha256",
+	128: "sha512",
+}
+
+// IsChecksumsFile returns whether the artifact named name is a checksums
+// file, such as SHA256SUMS or checksums.txt, listing the digests of a
+// release's other artifacts.
+func IsChecksumsFile(name string) bool {
+	return checksumsFileRegex.MatchString(name)
+}
+
+// ParseChecksums parses the content of a checksums file and returns the
+// digest of each file it lists, keyed by file name. Both the format output by
+// sha256sum (<hex>  <name>) and the BSD format (SHA256 (<name>) = <hex>) are
+// supported. Digests are formatted as <algorithm>:<hex>, e.g. sha256:ab12...,
+// with the algorithm determined by the digest's length. An error is returned
+// for lines that can't be parsed.
+func ParseChecksums(content []byte) (map[string]string, error) {
+	digests := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var name, hex string
+		if m := bsdChecksumRegex.FindStringSubmatch(text); m != nil {
+			name, hex = m[2], m[3]
+		} else {
+			fields := strings.SplitN(text, " ", 2)
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("failed parsing checksum on line %d: expected a digest followed by a file name", line)
+			}
+			// a leading * marks files that were read in binary mode.
+			hex, name = fields[0], strings.TrimPrefix(strings.TrimSpace(fields[1]), "*")
+		}
+		algorithm, ok := digestAlgorithms[len(hex)]
+		if !ok || !hexDigestRegex.MatchString(hex) || name == "" {
+			return nil, fmt.Errorf("failed parsing checksum on line %d: (%s) is not a sha1, sha256, or sha512 digest", line, hex)
+		}
+		digests[name] = algorithm + ":" + strings.ToLower(hex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading checksums: %s", err)
+	}
+	return digests, nil
+}
+
+// AttachDigests sets the Digest of each artifact listed in the checksums files
+// among artifacts, using download to retrieve the content of the checksums
+// files. Artifacts without a listed digest are left unchanged. Checksums files
+// that cannot be parsed are skipped with a warning; an error is only returned
+// when a checksums file cannot be downloaded.
+func AttachDigests(artifacts []Artifact, download func(Artifact) ([]byte, error)) error {
+	digests := map[string]string{}
+	for _, a := range artifacts {
+		if !IsChecksumsFile(a.Name) {
+			continue
+		}
+		content, err := download(a)
+		if err != nil {
+			return fmt.Errorf("failed downloading checksums file (%s): %s", a.Name, err)
+		}
+		parsed, err := ParseChecksums(content)
+		if err != nil {
+			// a file named like a checksums file may be in another format,
+			// so it is skipped rather than failing every other artifact.
+			slog.Warn("skipping checksums file that could not be parsed", "artifact", a.Name, "error", err)
+			continue
+		}
+		for name, digest := range parsed {
+			digests[name] = digest
+		}
+	}
+	for i := range artifacts {
+		if d, ok := digests[artifacts[i].Name]; ok {
+			artifacts[i].Digest = d
+		}
+	}
+	return nil
+}
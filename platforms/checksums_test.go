@@ -0,0 +1,94 @@
+package platforms
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const (
+	sha256One = "6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b"
+	sha256Two = "D4735E3A265E16EEE03F59718B9B5D03019C07D8B6C51F90DA3A666EEC13AB35"
+)
+
+func TestIsChecksumsFile(t *testing.T) {
+	tests := map[string]bool{
+		"SHA256SUMS":                  true,
+		"checksums.txt":               true,
+		"proctor_1.0.0_checksums.txt": true,
+		"sha512sums.txt":              true,
+		"proctor_linux_amd64.tar.gz":  false,
+		"mychecksumstool":             false,
+	}
+	for name, expected := range tests {
+		if IsChecksumsFile(name) != expected {
+			t.Logf("fail: checksums file detection of %s was wrong, expected: %t", name, expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	content := fmt.Sprintf("%s  proctor_linux_amd64.tar.gz\n%s *proctor_windows.zip\n\nSHA256 (proctor.sbom) = %s\n", sha256One, sha256Two, sha256One)
+	digests, err := ParseChecksums([]byte(content))
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing checksums: %s", err)
+	}
+	expected := map[string]string{
+		"proctor_linux_amd64.tar.gz": "sha256:" + sha256One,
+		"proctor_windows.zip":        "sha256:" + strings.ToLower(sha256Two),
+		"proctor.sbom":               "sha256:" + sha256One,
+	}
+	if len(digests) != len(expected) {
+		t.Fatalf("fail: parsed digests were wrong: %v", digests)
+	}
+	for name, d := range expected {
+		if digests[name] != d {
+			t.Logf("fail: digest of %s was wrong, expected: %s, actual: %s", name, d, digests[name])
+			t.Fail()
+		}
+	}
+
+	if _, err := ParseChecksums([]byte("abc123 file\n")); err == nil {
+		t.Log("fail: expected error parsing digest of unknown length")
+		t.Fail()
+	}
+}
+
+func TestAttachDigests(t *testing.T) {
+	artifacts := []Artifact{
+		{Name: "proctor_linux_amd64.tar.gz"},
+		{Name: "proctor_darwin_arm64.tar.gz"},
+		{Name: "checksums.txt"},
+	}
+	err := AttachDigests(artifacts, func(a Artifact) ([]byte, error) {
+		return []byte(sha256One + "  proctor_linux_amd64.tar.gz\n"), nil
+	})
+	if err != nil {
+		t.Fatalf("fail: unexpected error attaching digests: %s", err)
+	}
+	if artifacts[0].Digest != "sha256:"+sha256One || artifacts[1].Digest != "" {
+		t.Logf("fail: attached digests were wrong: %+v", artifacts)
+		t.Fail()
+	}
+
+	// a checksums file that can't be parsed is skipped.
+	artifacts = []Artifact{
+		{Name: "proctor_linux_amd64.tar.gz"},
+		{Name: "checksums.txt"},
+		{Name: "SHA256SUMS"},
+	}
+	err = AttachDigests(artifacts, func(a Artifact) ([]byte, error) {
+		if a.Name == "checksums.txt" {
+			return []byte("not a checksum\n"), nil
+		}
+		return []byte(sha256One + "  proctor_linux_amd64.tar.gz\n"), nil
+	})
+	if err != nil {
+		t.Fatalf("fail: unexpected error attaching digests with a malformed checksums file: %s", err)
+	}
+	if artifacts[0].Digest != "sha256:"+sha256One {
+		t.Logf("fail: expected the digest from the valid checksums file: %+v", artifacts)
+		t.Fail()
+	}
+}
@@ -0,0 +1,152 @@
+package platforms
+
+import (
+	"sort"
+	"strings"
+)
+
+// ArtifactDiff describes how the artifacts of two releases differ.
+type ArtifactDiff struct {
+	// Artifacts only in the newer release.
+	Added []Artifact
+	// Artifacts only in the older release.
+	Removed []Artifact
+	// Artifacts with the same content, by digest, under a different name.
+	Renamed []ArtifactChange
+	// Artifacts in both releases whose size or digest differs. Binaries are
+	// typically rebuilt for every release, so a changed digest is expected;
+	// a large change in size is more notable.
+	Changed []ArtifactChange
+}
+
+// ArtifactChange is an artifact present, in some form, in both releases
+// compared by [DiffArtifacts].
+type ArtifactChange struct {
+	From Artifact
+	To   Artifact
+}
+
+// SizeChanged returns whether the artifact's size differs between releases.
+func (c ArtifactChange) SizeChanged() bool {
+	return c.From.Size != c.To.Size
+}
+
+// DigestChanged returns whether the artifact's digest differs between
+// releases. When either digest is unknown, it is not considered changed.
+func (c ArtifactChange) DigestChanged() bool {
+	return c.From.Digest != "" && c.To.Digest != "" && c.From.Digest != c.To.Digest
+}
+
+// DiffArtifacts compares the artifacts of the release tagged fromTag with
+// those of the release tagged toTag. Artifacts are matched by name, ignoring
+// the release's version within names, so proctor_1.0.0_linux.tar.gz in
+// v1.0.0 is the same artifact as proctor_1.1.0_linux.tar.gz in v1.1.0.
+// Unmatched artifacts with the same digest are reported as renamed. Each list
+// in the diff is sorted by name.
+func DiffArtifacts(fromTag string, from []Artifact, toTag string, to []Artifact) ArtifactDiff {
+	diff := ArtifactDiff{
+		Added:   []Artifact{},
+		Removed: []Artifact{},
+		Renamed: []ArtifactChange{},
+		Changed: []ArtifactChange{},
+	}
+
+	toByName := map[string]Artifact{}
+	for _, a := range to {
+		toByName[versionlessName(a.Name, toTag)] = a
+	}
+	removed := []Artifact{}
+	for _, a := range from {
+		key := versionlessName(a.Name, fromTag)
+		match, ok := toByName[key]
+		if !ok {
+			removed = append(removed, a)
+			continue
+		}
+		delete(toByName, key)
+		c := ArtifactChange{From: a, To: match}
+		if c.SizeChanged() || c.DigestChanged() {
+			diff.Changed = append(diff.Changed, c)
+		}
+	}
+
+	toByDigest := map[string]Artifact{}
+	for _, a := range toByName {
+		if a.Digest != "" {
+			toByDigest[a.Digest] = a
+		}
+	}
+	for _, a := range removed {
+		if match, ok := toByDigest[a.Digest]; ok && a.Digest != "" {
+			delete(toByDigest, a.Digest)
+			delete(toByName, versionlessName(match.Name, toTag))
+			diff.Renamed = append(diff.Renamed, ArtifactChange{From: a, To: match})
+			continue
+		}
+		diff.Removed = append(diff.Removed, a)
+	}
+	for _, a := range toByName {
+		diff.Added = append(diff.Added, a)
+	}
+
+	sortArtifacts(diff.Added)
+	sortArtifacts(diff.Removed)
+	sort.Slice(diff.Renamed, func(i, j int) bool { return diff.Renamed[i].From.Name < diff.Renamed[j].From.Name })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].From.Name < diff.Changed[j].From.Name })
+	return diff
+}
+
+// versionlessName returns name with the version of tag (e.g. 1.0.0 of tag
+// v1.0.0) replaced by a placeholder. Only versions bounded by the start or end
+// of name, '-', '_', or '.' are replaced, so a version that is part of a
+// longer one, such as 1.0 in 11.0 or 1.0.1, is left unchanged.
+func versionlessName(name string, tag string) string {
+	if tag == "" {
+		return name
+	}
+	name = replaceVersion(name, tag)
+	if version := strings.TrimPrefix(tag, "v"); version != "" {
+		name = replaceVersion(name, version)
+	}
+	return name
+}
+
+// replaceVersion replaces each occurrence of version in name that is bounded
+// by the start or end of name, '-', '_', or '.' with a placeholder.
+func replaceVersion(name string, version string) string {
+	// a '.' only bounds the version when it isn't followed (or, before the
+	// version, preceded) by a digit, as it would otherwise separate the
+	// components of a longer version, such as 1.0 in 1.0.1.
+	isBoundary := func(i int, next int) bool {
+		if i < 0 || i >= len(name) {
+			return true
+		}
+		if name[i] == '.' {
+			return next < 0 || next >= len(name) || name[next] < '0' || name[next] > '9'
+		}
+		return name[i] == '-' || name[i] == '_'
+	}
+	var b strings.Builder
+	start := 0
+	for {
+		i := strings.Index(name[start:], version)
+		if i < 0 {
+			break
+		}
+		i += start
+		end := i + len(version)
+		if isBoundary(i-1, i-2) && isBoundary(end, end+1) {
+			b.WriteString(name[start:i])
+			b.WriteString("{version}")
+		} else {
+			b.WriteString(name[start:end])
+		}
+		start = end
+	}
+	b.WriteString(name[start:])
+	return b.String()
+}
+
+func sortArtifacts(artifacts []Artifact) {
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Name < artifacts[j].Name })
+}
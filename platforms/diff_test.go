@@ -0,0 +1,61 @@
+package platforms
+
+import "testing"
+
+func TestDiffArtifacts(t *testing.T) {
+	from := []Artifact{
+		{Name: "proctor_1.0.0_linux_amd64.tar.gz", Size: 100, Digest: "sha256:01"},
+		{Name: "proctor_1.0.0_darwin_amd64.tar.gz", Size: 100, Digest: "sha256:02"},
+		{Name: "checksums.txt", Size: 10, Digest: ""},
+		{Name: "install.sh", Size: 5, Digest: "sha256:03"},
+		{Name: "proctor_1.0.0_windows_amd64.zip", Size: 100, Digest: "sha256:04"},
+	}
+	to := []Artifact{
+		{Name: "proctor_1.1.0_linux_amd64.tar.gz", Size: 100, Digest: "sha256:11"},
+		{Name: "proctor_1.1.0_darwin_amd64.tar.gz", Size: 100, Digest: "sha256:02"},
+		{Name: "checksums.txt", Size: 10, Digest: ""},
+		{Name: "setup.sh", Size: 5, Digest: "sha256:03"},
+		{Name: "proctor_1.1.0_linux_arm64.tar.gz", Size: 90, Digest: "sha256:15"},
+	}
+	diff := DiffArtifacts("v1.0.0", from, "v1.1.0", to)
+
+	if len(diff.Changed) != 1 || diff.Changed[0].To.Name != "proctor_1.1.0_linux_amd64.tar.gz" || !diff.Changed[0].DigestChanged() || diff.Changed[0].SizeChanged() {
+		t.Logf("fail: changed artifacts were wrong: %+v", diff.Changed)
+		t.Fail()
+	}
+	if len(diff.Renamed) != 1 || diff.Renamed[0].From.Name != "install.sh" || diff.Renamed[0].To.Name != "setup.sh" {
+		t.Logf("fail: renamed artifacts were wrong: %+v", diff.Renamed)
+		t.Fail()
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Name != "proctor_1.1.0_linux_arm64.tar.gz" {
+		t.Logf("fail: added artifacts were wrong: %+v", diff.Added)
+		t.Fail()
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "proctor_1.0.0_windows_amd64.zip" {
+		t.Logf("fail: removed artifacts were wrong: %+v", diff.Removed)
+		t.Fail()
+	}
+}
+
+func TestVersionlessName(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		expected string
+	}{
+		{"proctor_1.0.0_linux_amd64.tar.gz", "v1.0.0", "proctor_{version}_linux_amd64.tar.gz"},
+		{"proctor-v1.0.0-linux", "v1.0.0", "proctor-{version}-linux"},
+		{"1.0.0.tar.gz", "1.0.0", "{version}.tar.gz"},
+		// versions within longer tokens are left unchanged.
+		{"proctor_11.0_linux", "v1.0", "proctor_11.0_linux"},
+		{"proctor_1.0_linux_x86", "v1", "proctor_1.0_linux_x86"},
+		{"proctor_0.1.0_linux", "v1.0", "proctor_0.1.0_linux"},
+		{"proctor_linux_amd64", "", "proctor_linux_amd64"},
+	}
+	for _, test := range tests {
+		if actual := versionlessName(test.name, test.tag); actual != test.expected {
+			t.Logf("fail: versionlessName(%s, %s) expected: %s, actual: %s", test.name, test.tag, test.expected, actual)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package platforms
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// The digest algorithms artifacts can be verified with, keyed by the prefix
+// of [Artifact.Digest].
+var digestHashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// DownloadOpts configures [Download]. Every field is optional.
+type DownloadOpts struct {
+	// Called as the artifact is written with the number of bytes written so
+	// far.
+	Progress func(written int64)
+}
+
+// DownloadedArtifact is an artifact written to disk by [Download].
+type DownloadedArtifact struct {
+	Artifact Artifact
+	// The path the artifact was written to.
+	Path string
+	// The digest of the written content, formatted as <algorithm>:<hex>. The
+	// algorithm is that of Artifact.Digest, or sha256 when it isn't set.
+	Digest string
+	// Whether the content matched Artifact.Digest, as listed in the release's
+	// checksums file. False when the artifact has no listed digest.
+	ChecksumVerified bool
+}
+
+// Download writes the content of a, retrieved from p, to a file named after it
+// in dir. When a has a Digest, the content is verified against it and an error
+// is returned on mismatch. Content is written to a temporary file that's only
+// renamed into place once complete and verified, so a failed download never
+// leaves a partial or unverified artifact in dir.
+func Download(p Platform, a Artifact, dir string, opts ...DownloadOpts) (DownloadedArtifact, error) {
+	var opt DownloadOpts
+	if len(opts) > 0 {
+		opt = opts[len(opts)-1]
+	}
+	result := DownloadedArtifact{Artifact: a}
+	name := filepath.Base(a.Name)
+	if name != a.Name || name == "." || name == ".." {
+		return result, fmt.Errorf("artifact name (%s) is not a valid file name", a.Name)
+	}
+
+	algorithm, expected := "sha256", ""
+	if a.Digest != "" {
+		algorithm, expected, _ = strings.Cut(a.Digest, ":")
+	}
+	newHash, ok := digestHashes[algorithm]
+	if !ok {
+		return result, fmt.Errorf("artifact (%s) has a digest (%s) with an unsupported algorithm", a.Name, a.Digest)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return result, fmt.Errorf("failed creating directory (%s): %s", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+name+".*")
+	if err != nil {
+		return result, fmt.Errorf("failed creating file for artifact (%s): %s", a.Name, err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	body, err := p.DownloadArtifact(a)
+	if err != nil {
+		return result, err
+	}
+	defer body.Close()
+	h := newHash()
+	w := io.MultiWriter(tmp, h)
+	if opt.Progress != nil {
+		w = &progressWriter{w: w, fn: opt.Progress}
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		return result, fmt.Errorf("failed downloading artifact (%s): %s", a.Name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return result, fmt.Errorf("failed writing artifact (%s): %s", a.Name, err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	result.Digest = algorithm + ":" + actual
+	if expected != "" {
+		if !strings.EqualFold(expected, actual) {
+			return result, fmt.Errorf("checksum of artifact (%s) did not match, expected: %s, actual: %s", a.Name, a.Digest, result.Digest)
+		}
+		result.ChecksumVerified = true
+	}
+
+	result.Path = filepath.Join(dir, name)
+	if err := os.Rename(tmp.Name(), result.Path); err != nil {
+		return result, fmt.Errorf("failed writing artifact (%s): %s", a.Name, err)
+	}
+	return result, nil
+}
+
+// MatchArtifacts returns the artifacts whose name matches any of patterns,
+// which use the syntax of [path.Match] (e.g. *linux_amd64*). All artifacts are
+// returned when there are no patterns. An error is returned for malformed
+// patterns.
+func MatchArtifacts(artifacts []Artifact, patterns []string) ([]Artifact, error) {
+	if len(patterns) == 0 {
+		return artifacts, nil
+	}
+	matched := []Artifact{}
+	for _, a := range artifacts {
+		for _, pattern := range patterns {
+			ok, err := path.Match(pattern, a.Name)
+			if err != nil {
+				return nil, fmt.Errorf("artifact pattern (%s) is invalid: %s", pattern, err)
+			}
+			if ok {
+				matched = append(matched, a)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// progressWriter reports the number of bytes written through it to fn.
+type progressWriter struct {
+	w       io.Writer
+	fn      func(int64)
+	written int64
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.written += int64(n)
+	pw.fn(pw.written)
+	return n, err
+}
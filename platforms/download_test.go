@@ -0,0 +1,70 @@
+package platforms
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownload(t *testing.T) {
+	dir := t.TempDir()
+	// fakePlatform serves an artifact's name as its content, and sha256One is
+	// the digest of "1".
+	var written int64
+	d, err := Download(fakePlatform{}, Artifact{Name: "1", Digest: "sha256:" + sha256One}, dir, DownloadOpts{
+		Progress: func(n int64) { written = n },
+	})
+	if err != nil {
+		t.Fatalf("fail: unexpected error downloading artifact: %s", err)
+	}
+	if !d.ChecksumVerified || d.Digest != "sha256:"+sha256One || written != 1 {
+		t.Logf("fail: unexpected download: %+v, progress: %d", d, written)
+		t.Fail()
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "1")); err != nil || string(content) != "1" {
+		t.Logf("fail: artifact was not written, content: %q, error: %v", content, err)
+		t.Fail()
+	}
+
+	d, err = Download(fakePlatform{}, Artifact{Name: "2"}, dir)
+	if err != nil || d.ChecksumVerified || !strings.HasPrefix(d.Digest, "sha256:"+strings.ToLower(sha256Two)) {
+		t.Logf("fail: unexpected download without digest: %+v, error: %v", d, err)
+		t.Fail()
+	}
+
+	if _, err := Download(fakePlatform{}, Artifact{Name: "3", Digest: "sha256:" + sha256One}, dir); err == nil {
+		t.Logf("fail: expected an error when the checksum doesn't match")
+		t.Fail()
+	}
+	if _, err := os.Stat(filepath.Join(dir, "3")); !os.IsNotExist(err) {
+		t.Logf("fail: expected an artifact failing its checksum to be removed")
+		t.Fail()
+	}
+	if _, err := Download(fakePlatform{}, Artifact{Name: "../4"}, dir); err == nil {
+		t.Logf("fail: expected an error for an artifact name outside dir")
+		t.Fail()
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Logf("fail: expected only the 2 downloaded artifacts in dir, actual: %d", len(entries))
+		t.Fail()
+	}
+}
+
+func TestMatchArtifacts(t *testing.T) {
+	artifacts := []Artifact{{Name: "proctor_linux_amd64.tar.gz"}, {Name: "proctor_darwin_arm64.tar.gz"}, {Name: "checksums.txt"}}
+	matched, err := MatchArtifacts(artifacts, []string{"*linux*", "checksums.txt"})
+	if err != nil || len(matched) != 2 || matched[1].Name != "checksums.txt" {
+		t.Logf("fail: unexpected matches: %+v, error: %v", matched, err)
+		t.Fail()
+	}
+	if matched, _ := MatchArtifacts(artifacts, nil); len(matched) != 3 {
+		t.Logf("fail: expected all artifacts without patterns, actual: %d", len(matched))
+		t.Fail()
+	}
+	if _, err := MatchArtifacts(artifacts, []string{"["}); err == nil {
+		t.Logf("fail: expected an error for a malformed pattern")
+		t.Fail()
+	}
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arctir/proctor/platforms"
+)
+
+// securityAdvisory is a repository security advisory as returned by GitHub's
+// API, which go-github does not yet support.
+type securityAdvisory struct {
+	GHSAID          string    `json:"ghsa_id"`
+	CVEID           string    `json:"cve_id"`
+	HTMLURL         string    `json:"html_url"`
+	Summary         string    `json:"summary"`
+	Severity        string    `json:"severity"`
+	PublishedAt     time.Time `json:"published_at"`
+	Vulnerabilities []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		VulnerableVersionRange string `json:"vulnerable_version_range"`
+		PatchedVersions        string `json:"patched_versions"`
+	} `json:"vulnerabilities"`
+}
+
+// GetSecurityAdvisories returns the published security advisories of repoURL,
+// represented as $ORG_NAME/$REPO_NAME, newest first. Only the first 100
+// advisories are returned.
+func (g *GHManager) GetSecurityAdvisories(repoURL string) ([]platforms.Advisory, error) {
+	owner, name, err := splitRepo(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("repos/%s/%s/security-advisories?state=published&sort=published&direction=desc&per_page=100", owner, name)
+	req, err := g.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating request for security advisories: %s", err)
+	}
+	advisories := []securityAdvisory{}
+	res, err := g.client.Do(context.Background(), req, &advisories)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving security advisories from GitHub for (%s). Error was: %s%s", repoURL, err, describeRateLimit(res))
+	}
+
+	a := []platforms.Advisory{}
+	for _, advisory := range advisories {
+		affected := []platforms.AffectedPackage{}
+		for _, v := range advisory.Vulnerabilities {
+			affected = append(affected, platforms.AffectedPackage{
+				Ecosystem:          v.Package.Ecosystem,
+				Name:               v.Package.Name,
+				VulnerableVersions: v.VulnerableVersionRange,
+				PatchedVersions:    v.PatchedVersions,
+			})
+		}
+		a = append(a, platforms.Advisory{
+			ID:        advisory.GHSAID,
+			CVE:       advisory.CVEID,
+			Summary:   advisory.Summary,
+			Severity:  advisory.Severity,
+			URL:       advisory.HTMLURL,
+			Published: advisory.PublishedAt,
+			Affected:  affected,
+		})
+	}
+	return a, nil
+}
@@ -0,0 +1,267 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// The API GitHub App installation tokens are requested from.
+	defaultAPIURL = "https://api.github.com/"
+	// How long the JWT authenticating a GitHub App is valid for. GitHub
+	// allows at most 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// How far into the past a GitHub App JWT is issued, to allow for clock
+	// drift between the host and GitHub.
+	appJWTClockSkew = time.Minute
+)
+
+// The environment variables a GitHub token is read from, in order of
+// precedence. This matches the precedence of the gh CLI.
+var tokenEnvVars = []string{"GH_TOKEN", "GITHUB_TOKEN"}
+
+// ResolveToken discovers a GitHub token for host (e.g. github.com) from the
+// environment. It checks, in order, the GH_TOKEN and GITHUB_TOKEN environment
+// variables and the hosts.yml file written by the gh CLI. An empty string is
+// returned when no token is found. Tokens gh stores in the system keyring are
+// not read, as running gh is comparatively slow; [NewGHManager] only runs `gh
+// auth token` once GitHub rejects an anonymous request.
+func ResolveToken(host string) string {
+	for _, env := range tokenEnvVars {
+		if token := os.Getenv(env); token != "" {
+			return token
+		}
+	}
+	if token, err := readGHHostsToken(ghConfigDir(), host); err == nil && token != "" {
+		return token
+	}
+	return ""
+}
+
+// ghAuthToken returns the token for host from `gh auth token`, which reads
+// tokens gh stores in the system keyring. An empty string is returned when gh
+// isn't installed or has no token for host.
+func ghAuthToken(host string) string {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return ""
+	}
+	out, err := exec.Command("gh", "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// lazyTokenTransport makes requests anonymously until one is rejected, then
+// looks up a token and retries the request, and those following it, with the
+// token. The token is looked up at most once.
+type lazyTokenTransport struct {
+	base http.RoundTripper
+	// returns the token, or an empty string when there is none.
+	lookup func() string
+	mu     sync.Mutex
+	looked bool
+	token  string
+}
+
+func newLazyTokenTransport(base http.RoundTripper, lookup func() string) *lazyTokenTransport {
+	return &lazyTokenTransport{base: base, lookup: lookup}
+}
+
+func (t *lazyTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	token := t.token
+	t.mu.Unlock()
+	if token != "" {
+		return t.base.RoundTrip(withToken(req, token))
+	}
+	res, err := t.base.RoundTrip(req)
+	if err != nil || !canRetry(req) || req.Header.Get("Authorization") != "" {
+		return res, err
+	}
+	// GitHub responds to anonymous requests for private repositories with
+	// 404 rather than 401.
+	switch res.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+	default:
+		return res, nil
+	}
+	t.mu.Lock()
+	if !t.looked {
+		t.looked = true
+		t.token = t.lookup()
+	}
+	token = t.token
+	t.mu.Unlock()
+	if token == "" {
+		return res, nil
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	retry := withToken(req, token)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return t.base.RoundTrip(retry)
+}
+
+// withToken returns a copy of req authenticated with token.
+func withToken(req *http.Request, token string) *http.Request {
+	authed := req.Clone(req.Context())
+	authed.Header.Set("Authorization", "Bearer "+token)
+	return authed
+}
+
+// ghConfigDir returns the directory the gh CLI stores its configuration in.
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(xdg.ConfigHome, "gh")
+}
+
+// readGHHostsToken returns the token for host stored in the hosts.yml file
+// within configDir.
+func readGHHostsToken(configDir string, host string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(configDir, "hosts.yml"))
+	if err != nil {
+		return "", err
+	}
+	hosts := map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}{}
+	if err := yaml.Unmarshal(content, &hosts); err != nil {
+		return "", fmt.Errorf("failed parsing gh hosts.yml: %s", err)
+	}
+	return hosts[host].OAuthToken, nil
+}
+
+// appTokenSource creates installation tokens for a GitHub App, which
+// authenticate as the app's installation in an organization or account.
+type appTokenSource struct {
+	appID          int64
+	installationID int64
+	// the app's PEM encoded private key.
+	privateKey []byte
+	apiURL     string
+	client     *http.Client
+}
+
+// newAppTokenSource returns a token source creating installation tokens for
+// the GitHub App appID, using its PEM encoded private key. Tokens are reused
+// until they are about to expire. The private key is parsed when the first
+// token is created, so an invalid key is reported by the first request made.
+func newAppTokenSource(appID int64, installationID int64, privateKey []byte, apiURL string, client *http.Client) oauth2.TokenSource {
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	return oauth2.ReuseTokenSource(nil, &appTokenSource{
+		appID:          appID,
+		installationID: installationID,
+		privateKey:     privateKey,
+		apiURL:         strings.TrimSuffix(apiURL, "/") + "/",
+		client:         client,
+	})
+}
+
+// Token exchanges a JWT signed by the app's private key for an installation
+// token.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	key, err := parseAppPrivateKey(s.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	jwt, err := s.jwt(key, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", s.apiURL, s.installationID)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating GitHub App installation token: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed creating GitHub App installation token: %s", res.Status)
+	}
+	body := struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed decoding GitHub App installation token: %s", err)
+	}
+	return &oauth2.Token{AccessToken: body.Token, TokenType: "token", Expiry: body.ExpiresAt}, nil
+}
+
+// jwt returns a JSON Web Token, signed with RS256 using key, that
+// authenticates as the app at time now.
+func (s *appTokenSource) jwt(key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]int64{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": s.appID,
+	})
+	var buf bytes.Buffer
+	buf.WriteString(base64.RawURLEncoding.EncodeToString(header))
+	buf.WriteByte('.')
+	buf.WriteString(base64.RawURLEncoding.EncodeToString(claims))
+	digest := sha256.Sum256(buf.Bytes())
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed signing GitHub App JWT: %s", err)
+	}
+	buf.WriteByte('.')
+	buf.WriteString(base64.RawURLEncoding.EncodeToString(sig))
+	return buf.String(), nil
+}
+
+// parseAppPrivateKey parses the PEM encoded RSA private key of a GitHub App,
+// which GitHub provides in PKCS #1 form.
+func parseAppPrivateKey(privateKey []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, fmt.Errorf("failed decoding GitHub App private key: no PEM block found")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err == nil {
+		return key, nil
+	}
+	parsed, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if rsaKey, ok := parsed.(*rsa.PrivateKey); pkcs8Err == nil && ok {
+		return rsaKey, nil
+	}
+	return nil, fmt.Errorf("failed parsing GitHub App private key, expected an RSA key: %s", err)
+}
@@ -0,0 +1,134 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveToken(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("GH_CONFIG_DIR", configDir)
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "env-token")
+	if token := ResolveToken(githubHost); token != "env-token" {
+		t.Logf("fail: token from environment was wrong: %s", token)
+		t.Fail()
+	}
+
+	t.Setenv("GITHUB_TOKEN", "")
+	hosts := "github.com:\n    user: jane\n    oauth_token: gh-cli-token\n    git_protocol: https\n"
+	if err := os.WriteFile(filepath.Join(configDir, "hosts.yml"), []byte(hosts), 0600); err != nil {
+		t.Fatalf("fail: error writing hosts.yml: %s", err)
+	}
+	token, err := readGHHostsToken(configDir, githubHost)
+	if err != nil {
+		t.Fatalf("fail: unexpected error reading hosts.yml: %s", err)
+	}
+	if token != "gh-cli-token" {
+		t.Logf("fail: token from hosts.yml was wrong: %s", token)
+		t.Fail()
+	}
+}
+
+func TestLazyTokenTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gh-keyring-token" && r.URL.Path == "/private" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	lookups := 0
+	client := &http.Client{Transport: newLazyTokenTransport(http.DefaultTransport, func() string {
+		lookups++
+		return "gh-keyring-token"
+	})}
+	get := func(path string) (int, string) {
+		res, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("fail: unexpected error requesting %s: %s", path, err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	// requests that succeed anonymously don't look up a token.
+	if status, auth := get("/public"); status != http.StatusOK || auth != "" || lookups != 0 {
+		t.Logf("fail: expected an anonymous request without a lookup, actual: %d %q, %d lookups", status, auth, lookups)
+		t.Fail()
+	}
+	// a rejected request is retried with the token, which is then reused.
+	for _, path := range []string{"/private", "/public"} {
+		if status, auth := get(path); status != http.StatusOK || auth != "Bearer gh-keyring-token" {
+			t.Logf("fail: expected %s to be authenticated, actual: %d %q", path, status, auth)
+			t.Fail()
+		}
+	}
+	if lookups != 1 {
+		t.Logf("fail: expected the token to be looked up once, actual: %d", lookups)
+		t.Fail()
+	}
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("fail: error generating key: %s", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/7/access_tokens" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// verify the JWT was signed by the app's key and issued by it.
+		parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+		if len(parts) != 3 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		claims := map[string]int64{}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		json.Unmarshal(payload, &claims)
+		if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig) != nil || claims["iss"] != 42 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token": "ghs_installation", "expires_at": "` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`))
+	}))
+	defer server.Close()
+
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	token, err := newAppTokenSource(42, 7, privateKey, server.URL, http.DefaultClient).Token()
+	if err != nil {
+		t.Fatalf("fail: unexpected error creating installation token: %s", err)
+	}
+	if token.AccessToken != "ghs_installation" {
+		t.Logf("fail: installation token was wrong: %s", token.AccessToken)
+		t.Fail()
+	}
+
+	if _, err := newAppTokenSource(42, 7, []byte("not a key"), server.URL, http.DefaultClient).Token(); err == nil {
+		t.Log("fail: expected error creating installation token with an invalid key")
+		t.Fail()
+	}
+}
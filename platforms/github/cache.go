@@ -0,0 +1,209 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+const (
+	// The amount of time a cached response is used without asking GitHub
+	// whether it changed, when not set in GHManagerConfig.
+	DefaultCacheTTL = 5 * time.Minute
+	// How long a cached response that isn't used again is kept, when not set
+	// in GHManagerConfig.
+	DefaultCacheMaxAge = 30 * 24 * time.Hour
+)
+
+// The cache directories pruned by this process, as they only need pruning
+// once.
+var prunedCacheDirs sync.Map
+
+// DefaultCacheDir returns the directory responses from GitHub are cached in
+// when not set in GHManagerConfig, $XDG_CACHE_HOME/proctor/github.
+func DefaultCacheDir() string {
+	return filepath.Join(xdg.CacheHome, "proctor", "github")
+}
+
+// cacheEntry is a response from GitHub stored on disk.
+type cacheEntry struct {
+	URL          string
+	ETag         string
+	LastModified string
+	StoredAt     time.Time
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+}
+
+// cacheTransport caches GitHub's responses to GET requests on disk. A cached
+// response younger than ttl is returned without making a request. Older
+// responses are revalidated with a conditional request (If-None-Match or
+// If-Modified-Since); GitHub answers those with 304 Not Modified when the
+// response is unchanged, which does not count against the rate limit.
+// Artifact downloads are not cached.
+type cacheTransport struct {
+	base http.RoundTripper
+	dir  string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// newCacheTransport returns a transport caching responses in dir. Entries
+// that weren't stored or revalidated within maxAge are removed; a negative
+// maxAge keeps every entry.
+func newCacheTransport(base http.RoundTripper, dir string, ttl time.Duration, maxAge time.Duration) *cacheTransport {
+	t := &cacheTransport{base: base, dir: dir, ttl: ttl, now: time.Now}
+	if _, pruned := prunedCacheDirs.LoadOrStore(dir, true); !pruned && maxAge >= 0 {
+		t.prune(maxAge)
+	}
+	return t
+}
+
+// prune removes the entries, and any temporary files left by interrupted
+// writes, last written more than maxAge ago. Failing to prune doesn't affect
+// requests, so errors are ignored.
+func (t *cacheTransport) prune(maxAge time.Duration) {
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err == nil && t.now().Sub(info.ModTime()) > maxAge {
+			os.Remove(filepath.Join(t.dir, e.Name()))
+		}
+	}
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Accept") == artifactMediaType || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+	path := t.path(req)
+	entry, cached := t.load(path)
+	if cached && t.now().Sub(entry.StoredAt) < t.ttl {
+		return entry.response(req), nil
+	}
+
+	if cached {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && cached:
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		// the headers of the revalidation, such as the rate limit, are more
+		// recent than those cached.
+		for k, v := range res.Header {
+			entry.Header[k] = v
+		}
+		entry.StoredAt = t.now()
+		t.store(path, entry)
+		return entry.response(req), nil
+	case res.StatusCode == http.StatusOK && (res.Header.Get("ETag") != "" || res.Header.Get("Last-Modified") != ""):
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		t.store(path, &cacheEntry{
+			URL:          req.URL.String(),
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+			StoredAt:     t.now(),
+			StatusCode:   res.StatusCode,
+			Header:       res.Header,
+			Body:         body,
+		})
+	}
+	return res, nil
+}
+
+// path returns the file the response to req is cached in. Responses are
+// keyed by the credentials they were requested with, as well as the URL, so
+// responses to private repositories are never returned to other users.
+func (t *cacheTransport) path(req *http.Request) string {
+	key := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept") + "\n" + req.Header.Get("Authorization")))
+	return filepath.Join(t.dir, hex.EncodeToString(key[:])+".json")
+}
+
+// load returns the entry cached at path, if any.
+func (t *cacheTransport) load(path string) (*cacheEntry, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(b, entry); err != nil || entry.Header == nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+// store writes entry to path. Failing to cache a response doesn't fail the
+// request, so errors are ignored.
+func (t *cacheTransport) store(path string, entry *cacheEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return
+	}
+	// write to a temporary file first so concurrent readers never see a
+	// partially written entry.
+	tmp, err := os.CreateTemp(t.dir, "entry-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// response returns the cached response to req.
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheTransport(t *testing.T) {
+	requests, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"tag_name": "v0.1.0"}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	transport := newCacheTransport(http.DefaultTransport, t.TempDir(), time.Minute, -1)
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+	get := func(accept string) string {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		req.Header.Set("Accept", accept)
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("fail: unexpected error making request: %s", err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("fail: unexpected status: %s", res.Status)
+		}
+		b, _ := io.ReadAll(res.Body)
+		return string(b)
+	}
+
+	get("application/json")
+	// within the ttl, the cached response is returned without a request
+	if body := get("application/json"); body != `{"tag_name": "v0.1.0"}` || requests != 1 {
+		t.Logf("fail: expected cached response without a request, made %d requests: %s", requests, body)
+		t.Fail()
+	}
+	// once expired, the response is revalidated with its etag
+	now = now.Add(2 * time.Minute)
+	if body := get("application/json"); body != `{"tag_name": "v0.1.0"}` || requests != 2 || notModified != 1 {
+		t.Logf("fail: expected revalidated response, made %d requests (%d not modified): %s", requests, notModified, body)
+		t.Fail()
+	}
+	// artifact downloads are never cached
+	get(artifactMediaType)
+	get(artifactMediaType)
+	if requests != 4 {
+		t.Logf("fail: expected artifact downloads to bypass the cache, made %d requests", requests)
+		t.Fail()
+	}
+}
+
+func TestCacheTransportPrune(t *testing.T) {
+	dir := t.TempDir()
+	stale, fresh := filepath.Join(dir, "stale.json"), filepath.Join(dir, "fresh.json")
+	for _, path := range []string{stale, fresh} {
+		if err := os.WriteFile(path, []byte("{}"), 0600); err != nil {
+			t.Fatalf("fail: error writing cache entry: %s", err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatalf("fail: error aging cache entry: %s", err)
+	}
+
+	newCacheTransport(http.DefaultTransport, dir, time.Minute, 24*time.Hour)
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Log("fail: expected the entry older than the max age to be removed")
+		t.Fail()
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Logf("fail: expected the recent entry to be kept: %s", err)
+		t.Fail()
+	}
+}
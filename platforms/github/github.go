@@ -3,23 +3,43 @@ package github
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/arctir/proctor/platforms"
 	"github.com/google/go-github/v48/github"
 	"golang.org/x/oauth2"
 )
 
-type Release struct {
-	Name      string
-	Tag       string
-	Artifacts []Artifact
+// Release is a GitHub release. It is an alias of [platforms.Release].
+type Release = platforms.Release
+
+// Artifact is an asset attached to a GitHub release. It is an alias of
+// [platforms.Artifact].
+type Artifact = platforms.Artifact
+
+const (
+	// The host GitHub repositories are served from.
+	githubHost = "github.com"
+	// The media type requested to download the content of an artifact rather
+	// than its metadata.
+	artifactMediaType = "application/octet-stream"
+)
+
+func init() {
+	Register()
 }
 
-type Artifact struct {
-	Name        string
-	URL         string
-	ContentType string
+// Register makes GitHub available through [platforms.ForURL], creating each
+// [GHManager] with conf. It is called with the default configuration when
+// this package is imported; call it again to change the configuration.
+func Register(conf ...GHManagerConfig) {
+	platforms.Register(githubHost, func() platforms.Platform {
+		gm := NewGHManager(conf...)
+		return &gm
+	})
 }
 
 type GHRetriever interface {
@@ -34,8 +54,38 @@ type GHManager struct {
 // GHManagerConfig provide configuration options for creating a GitHub Manager.
 type GHManagerConfig struct {
 	// the access token to use when interacting with GitHub. If you plan to
-	// access private repositories, this must be set.
+	// access private repositories, this must be set. When empty, a token is
+	// discovered from the environment using [ResolveToken] or, once GitHub
+	// rejects an anonymous request, from `gh auth token`.
 	GHToken string
+	// access GitHub anonymously rather than discovering a token when GHToken
+	// is not set. Anonymous access has a much lower rate limit.
+	Anonymous bool
+	// authenticate as a GitHub App's installation, which is useful for
+	// org-wide access. When AppID is set, AppInstallationID and
+	// AppPrivateKey, the app's PEM encoded private key, must also be set and
+	// GHToken is ignored.
+	AppID             int64
+	AppInstallationID int64
+	AppPrivateKey     []byte
+	// the number of times a request is retried when it fails due to a
+	// secondary rate limit or a transient server error. Defaults to
+	// [DefaultMaxRetries]; a negative value disables retries.
+	MaxRetries int
+	// don't cache responses from GitHub. By default, responses are cached on
+	// disk and revalidated with their ETag, so repeated lookups don't count
+	// against the rate limit.
+	NoCache bool
+	// the directory responses are cached in. Defaults to [DefaultCacheDir].
+	CacheDir string
+	// how long a cached response is used before being revalidated with
+	// GitHub. Defaults to [DefaultCacheTTL]; a negative value revalidates
+	// every response.
+	CacheTTL time.Duration
+	// how long a cached response that isn't used again is kept before being
+	// removed. Defaults to [DefaultCacheMaxAge]; a negative value keeps
+	// responses indefinitely.
+	CacheMaxAge time.Duration
 }
 
 // NewGHManager takes an optional configuration (conf) and returns a
@@ -47,49 +97,234 @@ func NewGHManager(conf ...GHManagerConfig) GHManager {
 	if len(conf) > 0 {
 		opts = conf[len(conf)-1]
 	}
-	var httpClient *http.Client
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = DefaultMaxRetries
+	}
+	if opts.CacheDir == "" {
+		opts.CacheDir = DefaultCacheDir()
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	if opts.CacheMaxAge == 0 {
+		opts.CacheMaxAge = DefaultCacheMaxAge
+	}
+	var transport http.RoundTripper = newRetryTransport(http.DefaultTransport, opts.MaxRetries)
+	if !opts.NoCache {
+		transport = newCacheTransport(transport, opts.CacheDir, opts.CacheTTL, opts.CacheMaxAge)
+	}
+	httpClient := &http.Client{Transport: transport}
 
-	// if the GHToken was set, create an HTTP client with the oauth2 token;
-	// otherwise nil will be passed.
-	if opts.GHToken != "" {
-		srcToken := oauth2.StaticTokenSource(
+	if opts.GHToken == "" && opts.AppID == 0 && !opts.Anonymous {
+		opts.GHToken = ResolveToken(githubHost)
+		// tokens in gh's keyring are only looked up once they're needed.
+		if opts.GHToken == "" {
+			httpClient.Transport = newLazyTokenTransport(transport, func() string { return ghAuthToken(githubHost) })
+		}
+	}
+
+	// if the app or GHToken was set, wrap the HTTP client with the oauth2
+	// token.
+	var srcToken oauth2.TokenSource
+	switch {
+	case opts.AppID != 0:
+		srcToken = newAppTokenSource(opts.AppID, opts.AppInstallationID, opts.AppPrivateKey, defaultAPIURL, httpClient)
+	case opts.GHToken != "":
+		srcToken = oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: opts.GHToken},
 		)
-		httpClient = oauth2.NewClient(context.Background(), srcToken)
+	}
+	if srcToken != nil {
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+		httpClient = oauth2.NewClient(ctx, srcToken)
 	}
 	c := github.NewClient(httpClient)
 
 	return GHManager{GHManagerConfig: opts, client: c}
 }
 
-func (g *GHManager) GetArtifacts(repoURL string) ([]Release, error) {
-	repo := strings.Split(repoURL, "/")
-	if len(repo) < 2 {
-		return nil, fmt.Errorf("repoURL (%s) was invalid. Repository should be represented with $ORG_NAME/$REPO_NAME. For example, golang's repo would be (golang/go).", repoURL)
+// GetReleases returns the releases of repoURL, represented as
+// $ORG_NAME/$REPO_NAME, along with the artifacts attached to each. To avoid
+// downloading a checksums file per release, artifact digests are not set; use
+// [GHManager.GetArtifacts] to retrieve them.
+func (g *GHManager) GetReleases(repoURL string) ([]Release, error) {
+	owner, name, err := splitRepo(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	listOpts := &github.ListOptions{PerPage: 100}
+	r := []Release{}
+	for {
+		releases, res, err := g.client.Repositories.ListReleases(context.Background(), owner, name, listOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving releases from GitHub for (%s). Error was: %s%s", repoURL, err, describeRateLimit(res))
+		}
+		for _, release := range releases {
+			r = append(r, newRelease(release))
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		listOpts.Page = res.NextPage
 	}
-	// TODO(joshrosso): this is where we'll introduce pagination when we're ready.
-	releases, _, err := g.client.Repositories.ListReleases(context.Background(), repo[0], repo[1], &github.ListOptions{})
+
+	return r, nil
+}
+
+// GetReleaseByTag returns the release of repoURL, represented as
+// $ORG_NAME/$REPO_NAME, tagged tag, along with its artifacts. Only the single
+// release is requested, which avoids listing every release of repositories
+// with many. As with [GHManager.GetReleases], artifact digests are not set.
+func (g *GHManager) GetReleaseByTag(repoURL string, tag string) (*Release, error) {
+	owner, name, err := splitRepo(repoURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed retrieving releases from GitHub for (%s). Error was: %s", repoURL, err)
+		return nil, err
 	}
+	release, res, err := g.client.Repositories.GetReleaseByTag(context.Background(), owner, name, tag)
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("failed to find release with tag (%s) in (%s)", tag, repoURL)
+		}
+		return nil, fmt.Errorf("failed retrieving release (%s) from GitHub for (%s). Error was: %s%s", tag, repoURL, err, describeRateLimit(res))
+	}
+	r := newRelease(release)
+	return &r, nil
+}
 
-	r := []Release{}
-	// Print the names and URLs of the downloads (artifacts).
-	for _, release := range releases {
-		a := []Artifact{}
-		for _, asset := range release.Assets {
-			a = append(a, Artifact{
-				Name:        asset.GetName(),
-				URL:         asset.GetURL(),
-				ContentType: asset.GetContentType(),
+// newRelease converts a GitHub release into a [Release], including the names
+// and URLs of the downloads (artifacts).
+func newRelease(release *github.RepositoryRelease) Release {
+	a := []Artifact{}
+	for _, asset := range release.Assets {
+		a = append(a, Artifact{
+			Name:        asset.GetName(),
+			URL:         asset.GetURL(),
+			ContentType: asset.GetContentType(),
+			Size:        int64(asset.GetSize()),
+			Downloads:   asset.GetDownloadCount(),
+		})
+	}
+	return Release{
+		Name:        release.GetName(),
+		Tag:         release.GetTagName(),
+		Draft:       release.GetDraft(),
+		Prerelease:  release.GetPrerelease(),
+		PublishedAt: release.GetPublishedAt().Time,
+		Artifacts:   a,
+	}
+}
+
+// GetArtifacts returns the artifacts attached to the release of repoURL,
+// represented as $ORG_NAME/$REPO_NAME, tagged tag. An error is returned when
+// no release has the tag.
+func (g *GHManager) GetArtifacts(repoURL string, tag string) ([]Artifact, error) {
+	r, err := g.GetReleaseByTag(repoURL, tag)
+	if err != nil {
+		return nil, err
+	}
+	if err := platforms.AttachDigests(r.Artifacts, g.downloadArtifact); err != nil {
+		return nil, err
+	}
+	return r.Artifacts, nil
+}
+
+// DownloadArtifact returns the content of an artifact, which the caller must
+// close.
+func (g *GHManager) DownloadArtifact(a Artifact) (io.ReadCloser, error) {
+	req, err := g.client.NewRequest(http.MethodGet, a.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", artifactMediaType)
+	res, err := g.client.BareDo(context.Background(), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed downloading artifact (%s). Error was: %s%s", a.Name, err, describeRateLimit(res))
+	}
+	return res.Body, nil
+}
+
+// downloadArtifact returns the content of an artifact. It is only intended
+// for small artifacts, such as checksums files, as the content is held in
+// memory.
+func (g *GHManager) downloadArtifact(a Artifact) ([]byte, error) {
+	body, err := g.DownloadArtifact(a)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// GetRepoMetadata returns details about repoURL, represented as
+// $ORG_NAME/$REPO_NAME.
+func (g *GHManager) GetRepoMetadata(repoURL string) (platforms.RepoMetadata, error) {
+	owner, name, err := splitRepo(repoURL)
+	if err != nil {
+		return platforms.RepoMetadata{}, err
+	}
+	repo, res, err := g.client.Repositories.Get(context.Background(), owner, name)
+	if err != nil {
+		return platforms.RepoMetadata{}, fmt.Errorf("failed retrieving repository from GitHub for (%s). Error was: %s%s", repoURL, err, describeRateLimit(res))
+	}
+	return platforms.RepoMetadata{
+		FullName:      repo.GetFullName(),
+		Description:   repo.GetDescription(),
+		URL:           repo.GetHTMLURL(),
+		Homepage:      repo.GetHomepage(),
+		DefaultBranch: repo.GetDefaultBranch(),
+		License:       repo.GetLicense().GetSPDXID(),
+		Topics:        repo.Topics,
+		Stars:         repo.GetStargazersCount(),
+		Forks:         repo.GetForksCount(),
+		OpenIssues:    repo.GetOpenIssuesCount(),
+		Archived:      repo.GetArchived(),
+		Fork:          repo.GetFork(),
+		// GitHub reports the size in kilobytes.
+		Size:      int64(repo.GetSize()) * 1024,
+		CreatedAt: repo.GetCreatedAt().Time,
+		PushedAt:  repo.GetPushedAt().Time,
+	}, nil
+}
+
+// GetContributors returns the contributors of repoURL, represented as
+// $ORG_NAME/$REPO_NAME, ordered by contributions. Contributions are counted
+// by GitHub from the default branch, without cloning the repository, so this
+// is much faster than walking its history. GitHub only attributes
+// contributions to the first 500 authors by email; the rest are omitted.
+func (g *GHManager) GetContributors(repoURL string) ([]platforms.Contributor, error) {
+	owner, name, err := splitRepo(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	listOpts := &github.ListContributorsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	c := []platforms.Contributor{}
+	for {
+		contributors, res, err := g.client.Repositories.ListContributors(context.Background(), owner, name, listOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving contributors from GitHub for (%s). Error was: %s%s", repoURL, err, describeRateLimit(res))
+		}
+		for _, contributor := range contributors {
+			c = append(c, platforms.Contributor{
+				Login:         contributor.GetLogin(),
+				Contributions: contributor.GetContributions(),
 			})
 		}
-		r = append(r, Release{
-			Name:      release.GetName(),
-			Tag:       release.GetTagName(),
-			Artifacts: a,
-		})
+		if res.NextPage == 0 {
+			break
+		}
+		listOpts.Page = res.NextPage
 	}
+	return c, nil
+}
 
-	return r, nil
+// splitRepo returns the owner and name of repoURL, represented as
+// $ORG_NAME/$REPO_NAME.
+func splitRepo(repoURL string) (string, string, error) {
+	repo := strings.Split(repoURL, "/")
+	if len(repo) < 2 {
+		return "", "", fmt.Errorf("repoURL (%s) was invalid. Repository should be represented with $ORG_NAME/$REPO_NAME. For example, golang's repo would be (golang/go).", repoURL)
+	}
+	return repo[0], repo[1], nil
 }
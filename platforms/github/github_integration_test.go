@@ -18,7 +18,7 @@ func TestFailWithBadToken(t *testing.T) {
 	}
 	gm := NewGHManager(conf)
 
-	_, err := gm.GetArtifacts(k8sRepo)
+	_, err := gm.GetReleases(k8sRepo)
 	if err == nil {
 		t.Log("fail: expected to receive error from using bad token, but did not")
 		t.Fail()
@@ -27,7 +27,7 @@ func TestFailWithBadToken(t *testing.T) {
 
 func TestFailWithInvalidRepo(t *testing.T) {
 	gm := NewGHManager()
-	_, err := gm.GetArtifacts(badRepo)
+	_, err := gm.GetReleases(badRepo)
 	if err == nil {
 		t.Log("fail: expected error from using bad repository, but did not")
 		t.Fail()
@@ -36,7 +36,7 @@ func TestFailWithInvalidRepo(t *testing.T) {
 
 func TestGetArtifacts(t *testing.T) {
 	gm := NewGHManager()
-	repos, err := gm.GetArtifacts(k8sRepo)
+	repos, err := gm.GetReleases(k8sRepo)
 	if err != nil {
 		t.Logf("fail: error when trying to retrieve release data: %s", err)
 		t.Fail()
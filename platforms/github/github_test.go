@@ -0,0 +1,201 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetReleaseByTag(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path != "/repos/arctir/proctor/releases/tags/v0.1.0" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+			return
+		}
+		w.Write([]byte(`{"name": "v0.1.0", "tag_name": "v0.1.0", "assets": [{"name": "proctor_linux_amd64", "url": "https://example.com/1", "content_type": "application/octet-stream"}]}`))
+	}))
+	defer server.Close()
+
+	gm := NewGHManager(GHManagerConfig{Anonymous: true, NoCache: true})
+	gm.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	r, err := gm.GetReleaseByTag("arctir/proctor", "v0.1.0")
+	if err != nil {
+		t.Fatalf("fail: unexpected error retrieving release: %s", err)
+	}
+	if r.Tag != "v0.1.0" || len(r.Artifacts) != 1 || r.Artifacts[0].Name != "proctor_linux_amd64" {
+		t.Logf("fail: release was wrong: %+v", r)
+		t.Fail()
+	}
+	// only the single release is requested, rather than listing all releases
+	if len(requests) != 1 {
+		t.Logf("fail: expected 1 request, actual: %v", requests)
+		t.Fail()
+	}
+
+	if _, err := gm.GetReleaseByTag("arctir/proctor", "v9.9.9"); err == nil {
+		t.Log("fail: expected error retrieving a missing release")
+		t.Fail()
+	}
+}
+
+func TestGetReleasesPaginates(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`[{"tag_name": "v0.1.0"}]`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/repos/arctir/proctor/releases?page=2>; rel="next"`, server.URL))
+		w.Write([]byte(`[{"tag_name": "v0.2.0"}]`))
+	}))
+	defer server.Close()
+
+	gm := NewGHManager(GHManagerConfig{Anonymous: true, NoCache: true})
+	gm.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	releases, err := gm.GetReleases("arctir/proctor")
+	if err != nil {
+		t.Fatalf("fail: unexpected error retrieving releases: %s", err)
+	}
+	if len(releases) != 2 || releases[0].Tag != "v0.2.0" || releases[1].Tag != "v0.1.0" {
+		t.Logf("fail: expected releases from both pages, actual: %+v", releases)
+		t.Fail()
+	}
+}
+
+func TestGetIssueStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/issues":
+			total := 0
+			switch q := r.URL.Query().Get("q"); {
+			case strings.HasSuffix(q, "is:issue is:open"):
+				total = 7
+			case strings.HasSuffix(q, "is:pr is:merged"):
+				total = 3
+			}
+			fmt.Fprintf(w, `{"total_count": %d, "items": []}`, total)
+		case "/repos/arctir/proctor/pulls":
+			w.Write([]byte(`[
+				{"number": 1, "created_at": "2023-01-01T00:00:00Z", "merged_at": "2023-01-01T02:00:00Z"},
+				{"number": 2, "created_at": "2023-01-01T00:00:00Z"},
+				{"number": 3, "created_at": "2023-01-01T00:00:00Z", "merged_at": "2023-01-01T04:00:00Z"},
+				{"number": 4, "created_at": "2023-01-01T00:00:00Z", "merged_at": "2023-01-02T00:00:00Z"}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	gm := NewGHManager(GHManagerConfig{Anonymous: true, NoCache: true})
+	gm.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	stats, err := gm.GetIssueStats("arctir/proctor")
+	if err != nil {
+		t.Fatalf("fail: unexpected error retrieving issue stats: %s", err)
+	}
+	if stats.OpenIssues != 7 || stats.MergedPullRequests != 3 || stats.ClosedIssues != 0 {
+		t.Logf("fail: issue counts were wrong: %+v", stats)
+		t.Fail()
+	}
+	if stats.MedianTimeToMerge != 4*time.Hour {
+		t.Logf("fail: median time to merge was wrong, expected: %s, actual: %s", 4*time.Hour, stats.MedianTimeToMerge)
+		t.Fail()
+	}
+}
+
+func TestGetSBOM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/arctir/proctor/dependency-graph/sbom" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"sbom": {"spdxVersion": "SPDX-2.3", "SPDXID": "SPDXRef-DOCUMENT", "packages": []}}`))
+	}))
+	defer server.Close()
+
+	gm := NewGHManager(GHManagerConfig{Anonymous: true, NoCache: true})
+	gm.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	sbom, err := gm.GetSBOM("arctir/proctor")
+	if err != nil {
+		t.Fatalf("fail: unexpected error retrieving SBOM: %s", err)
+	}
+	if string(sbom) != `{"spdxVersion": "SPDX-2.3", "SPDXID": "SPDXRef-DOCUMENT", "packages": []}` {
+		t.Logf("fail: SBOM was wrong: %s", sbom)
+		t.Fail()
+	}
+	if _, err := gm.GetSBOM("arctir/missing"); err == nil {
+		t.Log("fail: expected error retrieving SBOM of a missing repository")
+		t.Fail()
+	}
+}
+
+func TestGetRepoSnapshot(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		req := struct {
+			Variables map[string]interface{}
+		}{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/graphql" || req.Variables["name"] != "proctor" {
+			w.Write([]byte(`{"data": {"repository": null}, "errors": [{"message": "Could not resolve to a Repository"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"repository": {
+			"nameWithOwner": "arctir/proctor",
+			"defaultBranchRef": {"name": "main"},
+			"licenseInfo": {"spdxId": "Apache-2.0"},
+			"issues": {"totalCount": 2},
+			"pullRequests": {"totalCount": 1},
+			"diskUsage": 2,
+			"releases": {"nodes": [{"tagName": "v0.2.0", "publishedAt": "2023-01-02T00:00:00Z", "releaseAssets": {"nodes": [{"name": "proctor_linux_amd64", "size": 10, "downloadCount": 4}]}}]},
+			"refs": {"nodes": [
+				{"name": "v0.2.0", "target": {"oid": "bbbb", "target": {"oid": "aaaa"}}},
+				{"name": "v0.1.0", "target": {"oid": "cccc"}}
+			]}
+		}}}`))
+	}))
+	defer server.Close()
+
+	gm := NewGHManager(GHManagerConfig{Anonymous: true, NoCache: true})
+	gm.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	snapshot, err := gm.GetRepoSnapshot("arctir/proctor")
+	if err != nil {
+		t.Fatalf("fail: unexpected error retrieving snapshot: %s", err)
+	}
+	if requests != 1 {
+		t.Logf("fail: expected a single request, actual: %d", requests)
+		t.Fail()
+	}
+	if m := snapshot.Metadata; m.FullName != "arctir/proctor" || m.DefaultBranch != "main" || m.License != "Apache-2.0" || m.OpenIssues != 3 || m.Size != 2048 {
+		t.Logf("fail: metadata was wrong: %+v", m)
+		t.Fail()
+	}
+	if len(snapshot.Releases) != 1 || snapshot.Releases[0].Downloads() != 4 || snapshot.Releases[0].PublishedAt.IsZero() {
+		t.Logf("fail: releases were wrong: %+v", snapshot.Releases)
+		t.Fail()
+	}
+	// annotated tags resolve to their commit
+	if len(snapshot.Tags) != 2 || snapshot.Tags[0].Commit != "aaaa" || snapshot.Tags[1].Commit != "cccc" {
+		t.Logf("fail: tags were wrong: %+v", snapshot.Tags)
+		t.Fail()
+	}
+
+	if _, err := gm.GetRepoSnapshot("arctir/missing"); err == nil {
+		t.Log("fail: expected error retrieving snapshot of a missing repository")
+		t.Fail()
+	}
+}
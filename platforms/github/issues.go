@@ -0,0 +1,71 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arctir/proctor/platforms"
+	"github.com/google/go-github/v48/github"
+)
+
+// The number of most recently closed pull requests the median time to merge
+// is calculated from.
+const timeToMergeSampleSize = 100
+
+// GetIssueStats returns a summary of the issues and pull requests of repoURL,
+// represented as $ORG_NAME/$REPO_NAME. Counts are retrieved with GitHub's
+// search API, which has a lower rate limit than other requests. The median
+// time to merge is calculated from the 100 most recently updated closed pull
+// requests.
+func (g *GHManager) GetIssueStats(repoURL string) (platforms.IssueStats, error) {
+	owner, name, err := splitRepo(repoURL)
+	if err != nil {
+		return platforms.IssueStats{}, err
+	}
+	now := time.Now()
+	recent := now.Add(-platforms.RecentActivityWindow).UTC().Format("2006-01-02")
+	stats := platforms.IssueStats{MeasuredAt: now}
+	counts := []struct {
+		query string
+		count *int
+	}{
+		{"is:issue is:open", &stats.OpenIssues},
+		{"is:issue is:closed", &stats.ClosedIssues},
+		{"is:pr is:open", &stats.OpenPullRequests},
+		{"is:pr is:closed is:unmerged", &stats.ClosedPullRequests},
+		{"is:pr is:merged", &stats.MergedPullRequests},
+		{"is:pr created:>=" + recent, &stats.RecentPullRequestsOpened},
+		{"is:pr merged:>=" + recent, &stats.RecentPullRequestsMerged},
+	}
+	for _, c := range counts {
+		query := fmt.Sprintf("repo:%s/%s %s", owner, name, c.query)
+		result, res, err := g.client.Search.Issues(context.Background(), query, &github.SearchOptions{
+			ListOptions: github.ListOptions{PerPage: 1},
+		})
+		if err != nil {
+			return platforms.IssueStats{}, fmt.Errorf("failed searching GitHub for (%s). Error was: %s%s", query, err, describeRateLimit(res))
+		}
+		*c.count = result.GetTotal()
+	}
+
+	prs, res, err := g.client.PullRequests.List(context.Background(), owner, name, &github.PullRequestListOptions{
+		State:       "closed",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: timeToMergeSampleSize},
+	})
+	if err != nil {
+		return platforms.IssueStats{}, fmt.Errorf("failed retrieving pull requests from GitHub for (%s). Error was: %s%s", repoURL, err, describeRateLimit(res))
+	}
+	closed := []platforms.PullRequest{}
+	for _, pr := range prs {
+		closed = append(closed, platforms.PullRequest{
+			Number:    pr.GetNumber(),
+			CreatedAt: pr.GetCreatedAt(),
+			MergedAt:  pr.GetMergedAt(),
+		})
+	}
+	stats.MedianTimeToMerge = platforms.MedianTimeToMerge(closed)
+	return stats, nil
+}
@@ -0,0 +1,154 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v48/github"
+)
+
+const (
+	// The number of times a request is retried when not set in
+	// GHManagerConfig.
+	DefaultMaxRetries = 3
+	// The delay before the first retry, which doubles with each following
+	// retry.
+	defaultRetryDelay = time.Second
+	// The longest a single retry waits, including delays requested by GitHub
+	// through the Retry-After header.
+	maxRetryDelay = time.Minute
+)
+
+// retryTransport retries requests that fail due to GitHub's secondary rate
+// limits or transient server errors, waiting exponentially longer between
+// each attempt. Requests exceeding the primary rate limit are not retried, as
+// the limit may take up to an hour to reset.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	delay      time.Duration
+}
+
+func newRetryTransport(base http.RoundTripper, maxRetries int) *retryTransport {
+	return &retryTransport{base: base, maxRetries: maxRetries, delay: defaultRetryDelay}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries || !canRetry(req) {
+			return res, err
+		}
+		retry, wait := t.shouldRetry(res, attempt)
+		if !retry {
+			return res, nil
+		}
+		// the body must be drained for the connection to be reused.
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// shouldRetry returns whether res should be retried and how long to wait
+// before doing so. Secondary rate limits are retried after the delay GitHub
+// requests, or an exponential delay when not specified; server errors are
+// retried after an exponential delay.
+func (t *retryTransport) shouldRetry(res *http.Response, attempt int) (bool, time.Duration) {
+	backoff := t.delay << attempt
+	// add up to 10% jitter, so clients that failed together don't retry
+	// together.
+	if backoff > 0 {
+		backoff += time.Duration(rand.Int63n(int64(backoff)/10 + 1))
+	}
+	if backoff > maxRetryDelay {
+		backoff = maxRetryDelay
+	}
+	switch {
+	case res.StatusCode == http.StatusInternalServerError,
+		res.StatusCode == http.StatusBadGateway,
+		res.StatusCode == http.StatusServiceUnavailable,
+		res.StatusCode == http.StatusGatewayTimeout:
+		return true, backoff
+	case res.StatusCode == http.StatusForbidden, res.StatusCode == http.StatusTooManyRequests:
+		if !isSecondaryRateLimit(res) {
+			return false, 0
+		}
+		if after, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			wait := time.Duration(after) * time.Second
+			if wait > maxRetryDelay {
+				wait = maxRetryDelay
+			}
+			return true, wait
+		}
+		return true, backoff
+	}
+	return false, 0
+}
+
+// isSecondaryRateLimit returns whether res was rejected by one of GitHub's
+// secondary rate limits, which GitHub indicates with a Retry-After header or
+// in the body of the response. When the body is read, it is replaced so it
+// can be read again.
+func isSecondaryRateLimit(res *http.Response) bool {
+	if res.Header.Get("Retry-After") != "" {
+		return true
+	}
+	// the primary rate limit was exceeded.
+	if res.Header.Get("X-RateLimit-Remaining") == "0" {
+		return false
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+// canRetry returns whether req can be sent again, which requires its body, if
+// any, to be recreatable.
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// sleepContext waits for d to elapse, returning early with an error when ctx
+// is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// describeRateLimit returns the remaining rate limit quota reported in res,
+// formatted to be appended to an error, or an empty string when res doesn't
+// report it.
+func describeRateLimit(res *github.Response) string {
+	if res == nil || res.Rate.Limit == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (rate limit: %d of %d requests remaining, resets at %s)",
+		res.Rate.Remaining, res.Rate.Limit, res.Rate.Reset.Time.Format(time.RFC3339))
+}
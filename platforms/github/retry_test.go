@@ -0,0 +1,58 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := map[string]struct {
+		responses []int
+		headers   map[string]string
+		body      string
+		expected  int
+		attempts  int
+	}{
+		"server error is retried":         {responses: []int{502, 503, 200}, expected: 200, attempts: 3},
+		"retries are limited":             {responses: []int{500, 500, 500, 500, 500}, expected: 500, attempts: 4},
+		"secondary rate limit is retried": {responses: []int{403, 200}, body: "You have exceeded a secondary rate limit.", expected: 200, attempts: 2},
+		"retry after is retried":          {responses: []int{429, 200}, headers: map[string]string{"Retry-After": "0"}, expected: 200, attempts: 2},
+		"primary rate limit is not retried": {
+			responses: []int{403, 200},
+			headers:   map[string]string{"X-RateLimit-Remaining": "0"},
+			expected:  403,
+			attempts:  1,
+		},
+		"client error is not retried": {responses: []int{404, 200}, expected: 404, attempts: 1},
+	}
+
+	for name, test := range tests {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := test.responses[attempts]
+			attempts++
+			if status != http.StatusOK {
+				for k, v := range test.headers {
+					w.Header().Set(k, v)
+				}
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(test.body))
+		}))
+		transport := newRetryTransport(http.DefaultTransport, 3)
+		transport.delay = 0
+		client := &http.Client{Transport: transport}
+
+		res, err := client.Get(server.URL)
+		server.Close()
+		if err != nil {
+			t.Fatalf("fail: %s: unexpected error making request: %s", name, err)
+		}
+		res.Body.Close()
+		if res.StatusCode != test.expected || attempts != test.attempts {
+			t.Logf("fail: %s: expected status %d after %d attempts, actual: status %d after %d attempts", name, test.expected, test.attempts, res.StatusCode, attempts)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// GetSBOM returns the SBOM of repoURL, represented as $ORG_NAME/$REPO_NAME,
+// as an SPDX JSON document generated from GitHub's dependency graph. The
+// dependency graph must be enabled for the repository.
+func (g *GHManager) GetSBOM(repoURL string) ([]byte, error) {
+	owner, name, err := splitRepo(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := g.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/dependency-graph/sbom", owner, name), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating request for SBOM: %s", err)
+	}
+	// the SPDX document is wrapped in an object, e.g. {"sbom": {...}}.
+	sbom := struct {
+		SBOM json.RawMessage `json:"sbom"`
+	}{}
+	res, err := g.client.Do(context.Background(), req, &sbom)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving SBOM from GitHub for (%s). Error was: %s%s", repoURL, err, describeRateLimit(res))
+	}
+	if len(sbom.SBOM) == 0 {
+		return nil, fmt.Errorf("GitHub returned no SBOM for (%s)", repoURL)
+	}
+	return sbom.SBOM, nil
+}
@@ -0,0 +1,63 @@
+package platforms
+
+import (
+	"sort"
+	"time"
+)
+
+// The period, ending when statistics are retrieved, in which pull request
+// activity is considered recent.
+const RecentActivityWindow = 30 * 24 * time.Hour
+
+// IssueStats summarizes the issues and pull requests of a repository. Along
+// with the repository's git history, it describes how actively the repository
+// is maintained.
+type IssueStats struct {
+	// The time the statistics were retrieved at.
+	MeasuredAt   time.Time
+	OpenIssues   int
+	ClosedIssues int
+	// The number of pull requests that are open, closed without merging and
+	// merged.
+	OpenPullRequests   int
+	ClosedPullRequests int
+	MergedPullRequests int
+	// The median time from a pull request being opened to it being merged,
+	// calculated from the most recently merged pull requests. 0 when no pull
+	// requests have been merged.
+	MedianTimeToMerge time.Duration
+	// The number of pull requests opened and merged within the
+	// [RecentActivityWindow].
+	RecentPullRequestsOpened int
+	RecentPullRequestsMerged int
+}
+
+// PullRequest is a pull request (or merge request) of a repository.
+type PullRequest struct {
+	Number    int
+	CreatedAt time.Time
+	// When the pull request was merged, zero when it has not been.
+	MergedAt time.Time
+}
+
+// MedianTimeToMerge returns the median time from creation to merge of the
+// merged pull requests in prs. Pull requests that have not been merged are
+// ignored. 0 is returned when none have been merged.
+func MedianTimeToMerge(prs []PullRequest) time.Duration {
+	durations := []time.Duration{}
+	for _, pr := range prs {
+		if pr.MergedAt.IsZero() {
+			continue
+		}
+		durations = append(durations, pr.MergedAt.Sub(pr.CreatedAt))
+	}
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	mid := len(durations) / 2
+	if len(durations)%2 == 0 {
+		return (durations[mid-1] + durations[mid]) / 2
+	}
+	return durations[mid]
+}
@@ -0,0 +1,29 @@
+package platforms
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMedianTimeToMerge(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	prs := []PullRequest{
+		{Number: 1, CreatedAt: start, MergedAt: start.Add(4 * time.Hour)},
+		{Number: 2, CreatedAt: start, MergedAt: start.Add(1 * time.Hour)},
+		{Number: 3, CreatedAt: start},
+		{Number: 4, CreatedAt: start, MergedAt: start.Add(10 * time.Hour)},
+	}
+	if median := MedianTimeToMerge(prs); median != 4*time.Hour {
+		t.Logf("fail: median was wrong, expected: %s, actual: %s", 4*time.Hour, median)
+		t.Fail()
+	}
+	prs = append(prs, PullRequest{Number: 5, CreatedAt: start, MergedAt: start.Add(6 * time.Hour)})
+	if median := MedianTimeToMerge(prs); median != 5*time.Hour {
+		t.Logf("fail: median of even count was wrong, expected: %s, actual: %s", 5*time.Hour, median)
+		t.Fail()
+	}
+	if median := MedianTimeToMerge(prs[2:3]); median != 0 {
+		t.Logf("fail: median without merged pull requests was wrong: %s", median)
+		t.Fail()
+	}
+}
@@ -0,0 +1,166 @@
+// Package platforms defines the operations proctor performs against the
+// platforms hosting source repositories, such as GitHub, and the types they
+// return. Each platform is implemented in its own package, which registers
+// itself by host using [Register]; callers then retrieve the platform for a
+// repository with [ForURL], without depending on a specific platform.
+package platforms
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	registryMu sync.RWMutex
+	// platform constructors, keyed by the host they serve.
+	registry = map[string]Factory{}
+)
+
+// Release is a published release of a repository and the artifacts attached
+// to it.
+type Release struct {
+	Name string
+	Tag  string
+	// Whether the release is a draft, which is only visible to maintainers,
+	// or marked as a pre-release.
+	Draft      bool
+	Prerelease bool
+	// When the release was published, zero for drafts.
+	PublishedAt time.Time
+	Artifacts   []Artifact
+}
+
+// Downloads returns the number of times the release's artifacts have been
+// downloaded, in total.
+func (r Release) Downloads() int {
+	total := 0
+	for _, a := range r.Artifacts {
+		total += a.Downloads
+	}
+	return total
+}
+
+// Artifact is a file attached to a release, such as a binary or archive.
+type Artifact struct {
+	Name        string
+	URL         string
+	ContentType string
+	// The size of the artifact in bytes.
+	Size int64
+	// The number of times the artifact has been downloaded, as counted by the
+	// platform.
+	Downloads int
+	// The digest of the artifact, formatted as <algorithm>:<hex> (e.g.
+	// sha256:ab12...), as listed in a checksums file attached to the same
+	// release. Empty when the release has no checksums file or it doesn't
+	// list the artifact. See [AttachDigests].
+	Digest string
+}
+
+// RepoMetadata describes a repository as reported by the platform hosting
+// it. Fields the platform doesn't report are left to their zero value.
+type RepoMetadata struct {
+	// The full name of the repository, e.g. arctir/proctor.
+	FullName      string
+	Description   string
+	URL           string
+	Homepage      string
+	DefaultBranch string
+	// The SPDX identifier of the repository's license, e.g. Apache-2.0.
+	License    string
+	Topics     []string
+	Stars      int
+	Forks      int
+	OpenIssues int
+	Archived   bool
+	Fork       bool
+	// The size of the repository in bytes.
+	Size      int64
+	CreatedAt time.Time
+	PushedAt  time.Time
+}
+
+// Contributor is an account that contributed to a repository.
+type Contributor struct {
+	Login string
+	// The number of commits attributed to the contributor.
+	Contributions int
+}
+
+// Platform retrieves information about repositories from the platform
+// hosting them. Repositories are identified by their path on the platform,
+// e.g. arctir/proctor.
+type Platform interface {
+	// GetReleases returns the releases of a repository, newest first.
+	// Artifact digests may not be set.
+	GetReleases(repo string) ([]Release, error)
+	// GetArtifacts returns the artifacts attached to the release of a
+	// repository tagged tag, with their Digest set when the release includes
+	// a checksums file.
+	GetArtifacts(repo string, tag string) ([]Artifact, error)
+	// GetRepoMetadata returns details about a repository.
+	GetRepoMetadata(repo string) (RepoMetadata, error)
+	// GetContributors returns the contributors of a repository, ordered by
+	// contributions.
+	GetContributors(repo string) ([]Contributor, error)
+	// DownloadArtifact returns the content of an artifact, which the caller
+	// must close.
+	DownloadArtifact(a Artifact) (io.ReadCloser, error)
+	// GetIssueStats returns a summary of a repository's issues and pull
+	// requests.
+	GetIssueStats(repo string) (IssueStats, error)
+}
+
+// Factory creates a [Platform].
+type Factory func() Platform
+
+// Register makes the platform created by factory available, through [ForURL],
+// for repositories hosted at host (e.g. github.com). Registering a host again
+// replaces its factory. Register is typically called from the init function of
+// the package implementing the platform.
+func Register(host string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(host)] = factory
+}
+
+// ForURL returns the [Platform] hosting the repository at repoURL, such as
+// https://github.com/arctir/proctor, and the repository's path on it (e.g.
+// arctir/proctor). An error is returned when repoURL is invalid or no
+// platform is registered for its host.
+func ForURL(repoURL string) (Platform, string, error) {
+	host, repo, err := SplitRepoURL(repoURL)
+	if err != nil {
+		return nil, "", err
+	}
+	registryMu.RLock()
+	factory, ok := registry[host]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, "", fmt.Errorf("no supported platform hosts repository (%s), host (%s) is not supported", repoURL, host)
+	}
+	return factory(), repo, nil
+}
+
+// SplitRepoURL returns the host and path of a repository's URL, such as
+// github.com and arctir/proctor for https://github.com/arctir/proctor. URLs
+// without a scheme are assumed to be https. A trailing .git is removed from the
+// path.
+func SplitRepoURL(repoURL string) (string, string, error) {
+	if !strings.Contains(repoURL, "://") {
+		repoURL = "https://" + repoURL
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", "", fmt.Errorf("repository url (%s) was invalid: %s", repoURL, err)
+	}
+	repo := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if u.Host == "" || strings.Count(repo, "/") < 1 {
+		return "", "", fmt.Errorf("repository url (%s) was invalid. Repositories should be represented as https://$HOST/$ORG/$REPO", repoURL)
+	}
+	return strings.ToLower(u.Host), repo, nil
+}
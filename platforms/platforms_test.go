@@ -0,0 +1,48 @@
+package platforms
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+type fakePlatform struct{}
+
+func (f fakePlatform) GetReleases(repo string) ([]Release, error) { return nil, nil }
+func (f fakePlatform) GetArtifacts(repo string, tag string) ([]Artifact, error) {
+	return nil, nil
+}
+func (f fakePlatform) GetRepoMetadata(repo string) (RepoMetadata, error) {
+	return RepoMetadata{FullName: repo}, nil
+}
+func (f fakePlatform) GetContributors(repo string) ([]Contributor, error) { return nil, nil }
+func (f fakePlatform) DownloadArtifact(a Artifact) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(a.Name)), nil
+}
+func (f fakePlatform) GetIssueStats(repo string) (IssueStats, error) { return IssueStats{}, nil }
+
+func TestForURL(t *testing.T) {
+	Register("git.example.com", func() Platform { return fakePlatform{} })
+
+	p, repo, err := ForURL("https://git.example.com/arctir/proctor.git")
+	if err != nil {
+		t.Fatalf("fail: unexpected error finding platform: %s", err)
+	}
+	if repo != "arctir/proctor" {
+		t.Logf("fail: repo was wrong: %s", repo)
+		t.Fail()
+	}
+	if _, ok := p.(fakePlatform); !ok {
+		t.Logf("fail: platform was wrong: %T", p)
+		t.Fail()
+	}
+
+	if _, _, err := ForURL("https://unknown.example.com/arctir/proctor"); err == nil {
+		t.Log("fail: expected error finding platform for unregistered host")
+		t.Fail()
+	}
+	if _, _, err := ForURL("git.example.com/proctor"); err == nil {
+		t.Log("fail: expected error finding platform for url without an org")
+		t.Fail()
+	}
+}
@@ -0,0 +1,43 @@
+package platforms
+
+import "sort"
+
+// ReleaseFilterOpts selects and orders the releases returned by
+// [FilterReleases].
+type ReleaseFilterOpts struct {
+	// Exclude releases marked as pre-releases.
+	ExcludePrereleases bool
+	// Exclude draft releases.
+	ExcludeDrafts bool
+	// Order releases by PublishedAt, newest first, rather than the order the
+	// platform returned them in. Releases that haven't been published, such
+	// as drafts, are last.
+	SortByPublished bool
+}
+
+// FilterReleases returns the releases matching opts. releases is not
+// modified. While opts is variadic, only the last opts argument passed will
+// be used.
+func FilterReleases(releases []Release, opts ...ReleaseFilterOpts) []Release {
+	conf := ReleaseFilterOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	filtered := []Release{}
+	for _, r := range releases {
+		if (conf.ExcludePrereleases && r.Prerelease) || (conf.ExcludeDrafts && r.Draft) {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	if conf.SortByPublished {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			a, b := filtered[i].PublishedAt, filtered[j].PublishedAt
+			if a.IsZero() || b.IsZero() {
+				return !a.IsZero()
+			}
+			return a.After(b)
+		})
+	}
+	return filtered
+}
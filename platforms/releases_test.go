@@ -0,0 +1,57 @@
+package platforms
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterReleases(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2023, 1, n, 0, 0, 0, 0, time.UTC) }
+	releases := []Release{
+		{Tag: "v1.1.0-rc.1", Prerelease: true, PublishedAt: day(5)},
+		{Tag: "v1.0.0", PublishedAt: day(1)},
+		{Tag: "v1.2.0", Draft: true},
+		{Tag: "v1.0.1", PublishedAt: day(3)},
+	}
+	tags := func(rs []Release) []string {
+		t := []string{}
+		for _, r := range rs {
+			t = append(t, r.Tag)
+		}
+		return t
+	}
+
+	tests := map[string]struct {
+		opts     ReleaseFilterOpts
+		expected []string
+	}{
+		"unfiltered keeps order":  {ReleaseFilterOpts{}, []string{"v1.1.0-rc.1", "v1.0.0", "v1.2.0", "v1.0.1"}},
+		"exclude prereleases":     {ReleaseFilterOpts{ExcludePrereleases: true}, []string{"v1.0.0", "v1.2.0", "v1.0.1"}},
+		"exclude drafts":          {ReleaseFilterOpts{ExcludeDrafts: true}, []string{"v1.1.0-rc.1", "v1.0.0", "v1.0.1"}},
+		"sort by published":       {ReleaseFilterOpts{SortByPublished: true}, []string{"v1.1.0-rc.1", "v1.0.1", "v1.0.0", "v1.2.0"}},
+		"stable sorted published": {ReleaseFilterOpts{ExcludePrereleases: true, ExcludeDrafts: true, SortByPublished: true}, []string{"v1.0.1", "v1.0.0"}},
+	}
+	for name, test := range tests {
+		actual := tags(FilterReleases(releases, test.opts))
+		if len(actual) != len(test.expected) {
+			t.Logf("fail: %s: expected %v, actual: %v", name, test.expected, actual)
+			t.Fail()
+			continue
+		}
+		for i := range actual {
+			if actual[i] != test.expected[i] {
+				t.Logf("fail: %s: expected %v, actual: %v", name, test.expected, actual)
+				t.Fail()
+				break
+			}
+		}
+	}
+}
+
+func TestReleaseDownloads(t *testing.T) {
+	r := Release{Artifacts: []Artifact{{Name: "a", Downloads: 3}, {Name: "b", Downloads: 7}}}
+	if r.Downloads() != 10 {
+		t.Logf("fail: release downloads were wrong, expected: %d, actual: %d", 10, r.Downloads())
+		t.Fail()
+	}
+}
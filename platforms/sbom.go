@@ -0,0 +1,12 @@
+package platforms
+
+// SBOMSource is implemented by platforms that generate a software bill of
+// materials (SBOM) for the repositories they host, such as from GitHub's
+// dependency graph. Use a type assertion to check whether a [Platform]
+// supports it.
+type SBOMSource interface {
+	// GetSBOM returns the SBOM of a repository as an SPDX JSON document. The
+	// SBOM describes the dependencies the platform detected in the
+	// repository's manifests, without cloning or building it.
+	GetSBOM(repo string) ([]byte, error)
+}
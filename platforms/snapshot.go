@@ -0,0 +1,30 @@
+package platforms
+
+// Tag is a tag of a repository, as reported by the platform hosting it.
+type Tag struct {
+	Name string
+	// The hash of the commit the tag points to. Annotated tags are resolved
+	// to their commit.
+	Commit string
+}
+
+// RepoSnapshot is a repository's metadata, releases, and tags, retrieved
+// together.
+type RepoSnapshot struct {
+	Metadata RepoMetadata
+	// The most recently created releases, newest first.
+	Releases []Release
+	// The tags pointing to the most recently committed commits, newest
+	// first.
+	Tags []Tag
+}
+
+// SnapshotSource is implemented by platforms that can retrieve a
+// [RepoSnapshot] with fewer requests than calling [Platform.GetRepoMetadata]
+// and [Platform.GetReleases] separately, such as with a single GraphQL query.
+// Use a type assertion to check whether a [Platform] supports it.
+type SnapshotSource interface {
+	// GetRepoSnapshot returns the metadata, releases, and tags of a
+	// repository.
+	GetRepoSnapshot(repo string) (RepoSnapshot, error)
+}
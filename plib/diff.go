@@ -0,0 +1,136 @@
+package plib
+
+import "sort"
+
+// ProcessesDiff describes how one snapshot of processes differs from another.
+// Each list is ordered by process ID.
+type ProcessesDiff struct {
+	// Processes only in the newer snapshot.
+	Added []Process
+	// Processes only in the older snapshot.
+	Removed []Process
+	// Processes in both snapshots whose binary hash changed, such as when a
+	// process re-executed itself after its binary was upgraded.
+	Changed []ProcessChange
+}
+
+// ProcessChange is a process as it was in each snapshot.
+type ProcessChange struct {
+	From Process
+	To   Process
+}
+
+// DiffOpts configures how snapshots of processes are compared.
+type DiffOpts struct {
+	// Match processes by the path of their binary, rather than their ID, so
+	// snapshots taken across restarts or reboots can be compared. A process
+	// is then added when no process in the older snapshot ran its binary, and
+	// changed when its hash matches none of the older snapshot's processes
+	// running the binary. Processes without a known path, such as kernel
+	// threads, are matched by name.
+	ByPath bool
+}
+
+// Diff returns how the current snapshot of processes differs from ps.
+// Processes are matched by ID, unless configured otherwise in opts. A process
+// whose hash couldn't be read in either snapshot isn't considered changed.
+//
+// The variadic nature of opts is only to make it optional. If more than one
+// is passed, the last is used.
+func (ps Processes) Diff(current Processes, opts ...DiffOpts) ProcessesDiff {
+	conf := DiffOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if conf.ByPath {
+		return ps.diffByPath(current)
+	}
+	diff := ProcessesDiff{Added: []Process{}, Removed: []Process{}, Changed: []ProcessChange{}}
+	for id, p := range current {
+		prev, ok := ps[id]
+		if !ok {
+			diff.Added = append(diff.Added, *p)
+			continue
+		}
+		if HashKnown(prev.BinarySHA) && HashKnown(p.BinarySHA) && prev.BinarySHA != p.BinarySHA {
+			diff.Changed = append(diff.Changed, ProcessChange{From: *prev, To: *p})
+		}
+	}
+	for id, p := range ps {
+		if _, ok := current[id]; !ok {
+			diff.Removed = append(diff.Removed, *p)
+		}
+	}
+	diff.sort()
+	return diff
+}
+
+// diffByPath returns how current differs from ps, matching processes by the
+// path of their binary. See [DiffOpts].
+func (ps Processes) diffByPath(current Processes) ProcessesDiff {
+	diff := ProcessesDiff{Added: []Process{}, Removed: []Process{}, Changed: []ProcessChange{}}
+	previous, live := ps.byBinary(), current.byBinary()
+	for key, procs := range live {
+		prev, ok := previous[key]
+		if !ok {
+			for _, p := range procs {
+				diff.Added = append(diff.Added, *p)
+			}
+			continue
+		}
+		known := map[string]bool{}
+		var from *Process
+		for _, p := range prev {
+			if HashKnown(p.BinarySHA) {
+				known[p.BinarySHA] = true
+				if from == nil {
+					from = p
+				}
+			}
+		}
+		for _, p := range procs {
+			if from != nil && HashKnown(p.BinarySHA) && !known[p.BinarySHA] {
+				diff.Changed = append(diff.Changed, ProcessChange{From: *from, To: *p})
+			}
+		}
+	}
+	for key, procs := range previous {
+		if _, ok := live[key]; !ok {
+			for _, p := range procs {
+				diff.Removed = append(diff.Removed, *p)
+			}
+		}
+	}
+	diff.sort()
+	return diff
+}
+
+// byBinary groups ps by the path of their binary, or their name when the path
+// isn't known. Each group is ordered by ID.
+func (ps Processes) byBinary() map[string][]*Process {
+	groups := map[string][]*Process{}
+	for _, p := range ps {
+		key := p.CommandPath
+		if key == "" {
+			key = "[" + p.CommandName + "]"
+		}
+		groups[key] = append(groups[key], p)
+	}
+	for _, procs := range groups {
+		sort.Slice(procs, func(i, j int) bool { return procs[i].ID < procs[j].ID })
+	}
+	return groups
+}
+
+// sort orders each list of d by process ID.
+func (d ProcessesDiff) sort() {
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].ID < d.Added[j].ID })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].ID < d.Removed[j].ID })
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].To.ID < d.Changed[j].To.ID })
+}
+
+// HasDrift returns whether processes were added or changed, which, unlike
+// processes being removed, can indicate something unexpected is running.
+func (d ProcessesDiff) HasDrift() bool {
+	return len(d.Added) > 0 || len(d.Changed) > 0
+}
@@ -0,0 +1,71 @@
+package plib
+
+import "testing"
+
+func TestProcessesDiff(t *testing.T) {
+	previous := Processes{
+		1:  {ID: 1, CommandName: "systemd", BinarySHA: "aa11"},
+		42: {ID: 42, CommandName: "containerd", BinarySHA: "bb22"},
+		50: {ID: 50, CommandName: "sshd", BinarySHA: "cc33"},
+		60: {ID: 60, CommandName: "kthreadd", BinarySHA: ""},
+	}
+	current := Processes{
+		1:   {ID: 1, CommandName: "systemd", BinarySHA: "aa11"},
+		42:  {ID: 42, CommandName: "containerd", BinarySHA: "bb99"},
+		60:  {ID: 60, CommandName: "kthreadd", BinarySHA: shaReadError},
+		700: {ID: 700, CommandName: "bash", BinarySHA: "dd44"},
+		70:  {ID: 70, CommandName: "bash", BinarySHA: "dd44"},
+	}
+	diff := previous.Diff(current)
+	if len(diff.Added) != 2 || diff.Added[0].ID != 70 || diff.Added[1].ID != 700 {
+		t.Logf("fail: expected processes 70 and 700 to be added, actual: %v", diff.Added)
+		t.Fail()
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != 50 {
+		t.Logf("fail: expected process 50 to be removed, actual: %v", diff.Removed)
+		t.Fail()
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].From.BinarySHA != "bb22" || diff.Changed[0].To.BinarySHA != "bb99" {
+		t.Logf("fail: expected process 42 to be changed, actual: %v", diff.Changed)
+		t.Fail()
+	}
+}
+
+func TestProcessesDiffByPath(t *testing.T) {
+	previous := Processes{
+		1:  {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", BinarySHA: "aa11"},
+		42: {ID: 42, CommandName: "containerd", CommandPath: "/usr/bin/containerd", BinarySHA: "bb22"},
+		50: {ID: 50, CommandName: "sshd", CommandPath: "/usr/sbin/sshd", BinarySHA: "cc33"},
+		51: {ID: 51, CommandName: "sshd", CommandPath: "/usr/sbin/sshd", BinarySHA: "cc44"},
+		60: {ID: 60, CommandName: "kthreadd"},
+	}
+	// process IDs differ, as they would after a reboot.
+	current := Processes{
+		1:   {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", BinarySHA: "aa11"},
+		2:   {ID: 2, CommandName: "kthreadd"},
+		300: {ID: 300, CommandName: "containerd", CommandPath: "/usr/bin/containerd", BinarySHA: "bb99"},
+		310: {ID: 310, CommandName: "sshd", CommandPath: "/usr/sbin/sshd", BinarySHA: "cc44"},
+		700: {ID: 700, CommandName: "miner", CommandPath: "/tmp/miner", BinarySHA: "dd44"},
+	}
+	diff := previous.Diff(current, DiffOpts{ByPath: true})
+	if len(diff.Added) != 1 || diff.Added[0].ID != 700 {
+		t.Logf("fail: expected process 700 to be added, actual: %v", diff.Added)
+		t.Fail()
+	}
+	if len(diff.Removed) != 0 {
+		t.Logf("fail: expected no processes to be removed, actual: %v", diff.Removed)
+		t.Fail()
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].From.ID != 42 || diff.Changed[0].To.ID != 300 {
+		t.Logf("fail: expected containerd to be changed, actual: %v", diff.Changed)
+		t.Fail()
+	}
+	if !diff.HasDrift() {
+		t.Logf("fail: expected the diff to have drift")
+		t.Fail()
+	}
+	if previous.Diff(previous, DiffOpts{ByPath: true}).HasDrift() {
+		t.Logf("fail: expected no drift comparing a snapshot with itself")
+		t.Fail()
+	}
+}
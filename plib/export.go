@@ -0,0 +1,46 @@
+package plib
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// The header row of processes written as CSV.
+var processCSVHeader = []string{"pid", "ppid", "name", "path", "args", "sha", "kernel", "has_permission"}
+
+// WriteProcessesJSON writes ps to w as a JSON object keyed by process ID.
+func WriteProcessesJSON(w io.Writer, ps Processes) error {
+	return json.NewEncoder(w).Encode(ps)
+}
+
+// WriteProcessesCSV writes ps to w as CSV, with a header row followed by a row
+// per process, ordered by process ID. Operating system-specific details are
+// not included.
+func WriteProcessesCSV(w io.Writer, ps Processes) error {
+	ids := make([]int, 0, len(ps))
+	for id := range ps {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	cw := csv.NewWriter(w)
+	cw.Write(processCSVHeader)
+	for _, id := range ids {
+		p := ps[id]
+		cw.Write([]string{
+			strconv.Itoa(p.ID),
+			strconv.Itoa(p.ParentProcess),
+			p.CommandName,
+			p.CommandPath,
+			p.FlagsAndArgs,
+			p.BinarySHA,
+			strconv.FormatBool(p.IsKernel),
+			strconv.FormatBool(p.HasPermission),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,39 @@
+package plib
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteProcesses(t *testing.T) {
+	ps := Processes{
+		42: {ID: 42, ParentProcess: 1, CommandName: "containerd", CommandPath: "/usr/bin/containerd", FlagsAndArgs: "--config, /etc/containerd.toml", BinarySHA: "bb22", HasPermission: true},
+		1:  {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", BinarySHA: "aa11", HasPermission: true},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteProcessesCSV(&buf, ps); err != nil {
+		t.Fatalf("failed writing CSV: %s", err)
+	}
+	expected := "pid,ppid,name,path,args,sha,kernel,has_permission\n" +
+		"1,0,systemd,/usr/lib/systemd/systemd,,aa11,false,true\n" +
+		"42,1,containerd,/usr/bin/containerd,\"--config, /etc/containerd.toml\",bb22,false,true\n"
+	if buf.String() != expected {
+		t.Logf("fail: expected CSV:\n%s\nactual:\n%s", expected, buf.String())
+		t.Fail()
+	}
+
+	buf.Reset()
+	if err := WriteProcessesJSON(&buf, ps); err != nil {
+		t.Fatalf("failed writing JSON: %s", err)
+	}
+	decoded := Processes{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed decoding JSON: %s", err)
+	}
+	if len(decoded) != 2 || decoded[42].CommandName != "containerd" {
+		t.Logf("fail: processes did not round trip, actual: %v", decoded)
+		t.Fail()
+	}
+}
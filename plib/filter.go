@@ -0,0 +1,143 @@
+package plib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Filter selects processes by their details. A process must match every field
+// that's set; fields left empty match every process.
+type Filter struct {
+	// Matched against the process's CommandName.
+	Name *regexp.Regexp
+	// The real user IDs of the users owning the process. Only Linux processes,
+	// whose OSSpecific field is a [ProcessStat], can match.
+	UIDs []int
+	// The states, such as R or S, the process may be in. Only Linux processes,
+	// whose OSSpecific field is a [ProcessStat], can match.
+	States []string
+	// The hex encoded SHA256 the process's binary must have, matched
+	// regardless of case. Processes whose binary couldn't be hashed never
+	// match.
+	BinarySHA string
+}
+
+// Matches returns whether p matches every field of f that's set.
+func (f Filter) Matches(p *Process) bool {
+	if f.Name != nil && !f.Name.MatchString(p.CommandName) {
+		return false
+	}
+	if f.BinarySHA != "" && !strings.EqualFold(f.BinarySHA, p.BinarySHA) {
+		return false
+	}
+	stat, isLinux := p.OSSpecific.(ProcessStat)
+	if len(f.UIDs) > 0 {
+		if !isLinux || !containsInt(f.UIDs, stat.UID) {
+			return false
+		}
+	}
+	if len(f.States) > 0 {
+		if !isLinux || !containsString(f.States, stat.State) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the processes of ps that match f.
+func (ps Processes) Filter(f Filter) Processes {
+	result := Processes{}
+	for id, p := range ps {
+		if f.Matches(p) {
+			result[id] = p
+		}
+	}
+	return result
+}
+
+// ProcessLess reports whether process a sorts before process b by a detail of
+// the processes.
+type ProcessLess func(a, b *Process) bool
+
+// ProcessSorts are the orders [Processes.Sort] accepts, keyed by name. Sorts on
+// details only known on Linux, such as rss, treat other processes as 0.
+var ProcessSorts = map[string]ProcessLess{
+	"pid":   func(a, b *Process) bool { return a.ID < b.ID },
+	"name":  func(a, b *Process) bool { return strings.ToLower(a.CommandName) < strings.ToLower(b.CommandName) },
+	"rss":   func(a, b *Process) bool { return linuxStat(a).ResidentSetMemSize < linuxStat(b).ResidentSetMemSize },
+	"cpu":   func(a, b *Process) bool { return cpuTicks(a) < cpuTicks(b) },
+	"start": func(a, b *Process) bool { return linuxStat(a).StartTime < linuxStat(b).StartTime },
+}
+
+// Sort returns the processes of ps in the order of the sort in
+// [ProcessSorts] named key, reversed when desc is true. Processes that are
+// equal by the sort are ordered by ID.
+func (ps Processes) Sort(key string, desc bool) ([]*Process, error) {
+	less, ok := ProcessSorts[key]
+	if !ok {
+		keys := []string{}
+		for k := range ProcessSorts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return nil, fmt.Errorf("sort (%s) is not supported, expected one of %s", key, strings.Join(keys, ", "))
+	}
+	return SortProcesses(ps, less, desc), nil
+}
+
+// SortProcesses returns the processes of ps ordered by less, reversed when
+// desc is true. Processes that are equal by less are ordered by ID.
+func SortProcesses(ps Processes, less ProcessLess, desc bool) []*Process {
+	result := make([]*Process, 0, len(ps))
+	for _, p := range ps {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// linuxStat returns the Linux specific details of p, which are empty for
+// processes from other operating systems.
+func linuxStat(p *Process) ProcessStat {
+	s, _ := p.OSSpecific.(ProcessStat)
+	return s
+}
+
+// cpuTicks returns the clock ticks p has been scheduled for, in user and
+// kernel mode.
+func cpuTicks(p *Process) int {
+	s := linuxStat(p)
+	return s.UserModeTime + s.KernalTime
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package plib
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestProcessesFilter(t *testing.T) {
+	ps := Processes{
+		1:   {ID: 1, CommandName: "systemd", OSSpecific: ProcessStat{UID: 0, State: "S"}},
+		42:  {ID: 42, CommandName: "bash", BinarySHA: "aa11", OSSpecific: ProcessStat{UID: 1000, State: "S"}},
+		43:  {ID: 43, CommandName: "bash", OSSpecific: ProcessStat{UID: 1000, State: "R"}},
+		50:  {ID: 50, CommandName: "zsh", OSSpecific: ProcessStat{UID: 1001, State: "Z"}},
+		100: {ID: 100, CommandName: "bash"},
+	}
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []int
+	}{
+		{"empty", Filter{}, []int{1, 42, 43, 50, 100}},
+		{"name", Filter{Name: regexp.MustCompile("^(ba|z)sh$")}, []int{42, 43, 50, 100}},
+		{"user", Filter{UIDs: []int{1000, 1001}}, []int{42, 43, 50}},
+		{"state", Filter{States: []string{"R", "Z"}}, []int{43, 50}},
+		{"sha", Filter{BinarySHA: "AA11"}, []int{42}},
+		{"combined", Filter{Name: regexp.MustCompile("bash"), UIDs: []int{1000}, States: []string{"S"}}, []int{42}},
+	}
+	for _, test := range tests {
+		filtered := ps.Filter(test.filter)
+		if len(filtered) != len(test.expected) {
+			t.Logf("fail: filter %s expected %v, actual: %v", test.name, test.expected, filtered)
+			t.Fail()
+			continue
+		}
+		for _, id := range test.expected {
+			if filtered[id] == nil {
+				t.Logf("fail: filter %s expected process %d", test.name, id)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestProcessesSort(t *testing.T) {
+	ps := Processes{
+		1:  {ID: 1, CommandName: "systemd", OSSpecific: ProcessStat{ResidentSetMemSize: 300}},
+		42: {ID: 42, CommandName: "bash", OSSpecific: ProcessStat{ResidentSetMemSize: 100}},
+		7:  {ID: 7, CommandName: "Xorg", OSSpecific: ProcessStat{ResidentSetMemSize: 300}},
+	}
+	sorted, err := ps.Sort("rss", true)
+	if err != nil {
+		t.Fatalf("failed sorting: %s", err)
+	}
+	// processes with the same rss are ordered by ID.
+	for i, id := range []int{1, 7, 42} {
+		if sorted[i].ID != id {
+			t.Logf("fail: expected %d at %d, actual: %d", id, i, sorted[i].ID)
+			t.Fail()
+		}
+	}
+	if _, err := ps.Sort("bogus", false); err == nil {
+		t.Log("fail: expected an error sorting by an unknown key")
+		t.Fail()
+	}
+}
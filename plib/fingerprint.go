@@ -0,0 +1,190 @@
+package plib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// The name of the directory, within CacheDirName, storing baselines.
+const BaselineDirName = "baselines"
+
+// LineageEntry is a process in the lineage of a [Fingerprint].
+type LineageEntry struct {
+	ID          int
+	CommandName string
+	CommandPath string
+	BinarySHA   string
+}
+
+// Fingerprint is a checksum representing a process's binary and the binaries
+// of its parents, up to the root process (likely the init system). Processes
+// started from the same binaries by the same chain of binaries share a
+// fingerprint, regardless of their IDs.
+type Fingerprint struct {
+	// The hex encoded SHA256 of the BinarySHA of each process in Lineage,
+	// concatenated in order.
+	Value string
+	// The process, then each of its ancestors, ending with the root.
+	Lineage []LineageEntry
+}
+
+// Fingerprint returns the fingerprint of the process with ID pid. An error is
+// returned when the process or any of its ancestors can't be found or is
+// missing its binary's checksum, such as when the caller lacks permission to
+// inspect it, since the fingerprint would not be valid.
+func (ps Processes) Fingerprint(pid int) (Fingerprint, error) {
+	fp := Fingerprint{Lineage: []LineageEntry{}}
+	if ps[pid] == nil {
+		return fp, fmt.Errorf("failed to find process with id: %d", pid)
+	}
+	combinedHashes := ""
+	visited := map[int]bool{}
+	for id := pid; id != 0 && !visited[id]; id = ps[id].ParentProcess {
+		// if we can't resolve details about a parent process, there may be an
+		// issue with permission and the fingerprint will not be valid.
+		if ps[id] == nil {
+			return fp, fmt.Errorf("could not gather details on parent process: %d and thus could not generate a finger print", id)
+		}
+		visited[id] = true
+		p := ps[id]
+		if !HashKnown(p.BinarySHA) {
+			if id == pid {
+				return fp, fmt.Errorf("process %d is missing details about its binary checksum", pid)
+			}
+			return fp, fmt.Errorf("parent process %d of process %d is missing details about its binary checksum and thus could not generate a finger print", id, pid)
+		}
+		combinedHashes += p.BinarySHA
+		fp.Lineage = append(fp.Lineage, LineageEntry{ID: p.ID, CommandName: p.CommandName, CommandPath: p.CommandPath, BinarySHA: p.BinarySHA})
+	}
+	sum := sha256.Sum256([]byte(combinedHashes))
+	fp.Value = hex.EncodeToString(sum[:])
+	return fp, nil
+}
+
+// FingerprintReport is the fingerprint of every process on a machine.
+type FingerprintReport struct {
+	// The machine the processes ran on. See [Process].
+	MachineID string
+	// When the report was created.
+	Created   time.Time
+	Processes []FingerprintReportEntry
+}
+
+// FingerprintReportEntry is the fingerprint of a process in a
+// [FingerprintReport].
+type FingerprintReportEntry struct {
+	PID         int
+	CommandName string
+	CommandPath string
+	// Empty when the process couldn't be fingerprinted, in which case Error is
+	// why.
+	Fingerprint string `json:",omitempty"`
+	Error       string `json:",omitempty"`
+}
+
+// FingerprintReport returns the fingerprint of every process in ps, ordered by
+// ID, as of the time at. Processes that can't be fingerprinted are included
+// with the reason.
+func (ps Processes) FingerprintReport(at time.Time) FingerprintReport {
+	r := FingerprintReport{Created: at, Processes: []FingerprintReportEntry{}}
+	ids := make([]int, 0, len(ps))
+	for id := range ps {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		p := ps[id]
+		if r.MachineID == "" {
+			r.MachineID = p.MachineID
+		}
+		e := FingerprintReportEntry{PID: id, CommandName: p.CommandName, CommandPath: p.CommandPath}
+		fp, err := ps.Fingerprint(id)
+		if err != nil {
+			e.Error = err.Error()
+		} else {
+			e.Fingerprint = fp.Value
+		}
+		r.Processes = append(r.Processes, e)
+	}
+	return r
+}
+
+// LineageDrift is a difference between the lineage of two fingerprints, at
+// the same depth below the root process.
+type LineageDrift struct {
+	// The number of processes between the root and this one.
+	Depth int
+	// The process in each lineage, nil if the lineage doesn't reach this
+	// depth.
+	Expected *LineageEntry
+	Actual   *LineageEntry
+}
+
+// Drift returns the processes of actual whose binaries differ from those at
+// the same depth of f's lineage, counting from the root. Drift returns nothing
+// when the fingerprints match.
+func (f Fingerprint) Drift(actual Fingerprint) []LineageDrift {
+	drift := []LineageDrift{}
+	if f.Value == actual.Value {
+		return drift
+	}
+	at := func(lineage []LineageEntry, depth int) *LineageEntry {
+		if depth >= len(lineage) {
+			return nil
+		}
+		e := lineage[len(lineage)-1-depth]
+		return &e
+	}
+	depth := len(f.Lineage)
+	if len(actual.Lineage) > depth {
+		depth = len(actual.Lineage)
+	}
+	for d := 0; d < depth; d++ {
+		expected, got := at(f.Lineage, d), at(actual.Lineage, d)
+		if expected == nil || got == nil || expected.BinarySHA != got.BinarySHA {
+			drift = append(drift, LineageDrift{Depth: d, Expected: expected, Actual: got})
+		}
+	}
+	return drift
+}
+
+// Baseline is a fingerprint saved under a name, which processes can later be
+// verified against.
+type Baseline struct {
+	Name    string
+	Created time.Time
+	Fingerprint
+}
+
+// BaselineStore saves baselines as JSON files in a directory.
+type BaselineStore struct {
+	store *JSONStore
+}
+
+// NewBaselineStore returns a store of the baselines in dir. When dir is empty,
+// the store is in $XDG_DATA_HOME/CacheDirName/BaselineDirName.
+func NewBaselineStore(dir string) *BaselineStore {
+	if dir == "" {
+		dir = filepath.Join(xdg.DataHome, CacheDirName, BaselineDirName)
+	}
+	return &BaselineStore{store: NewJSONStore(dir, "baseline")}
+}
+
+// Save stores b, replacing any baseline with the same name. Names may only
+// contain letters, numbers, '.', '_', and '-'.
+func (s *BaselineStore) Save(b Baseline) error {
+	return s.store.Save(b.Name, b)
+}
+
+// Load returns the baseline saved as name.
+func (s *BaselineStore) Load(name string) (Baseline, error) {
+	var b Baseline
+	err := s.store.Load(name, &b)
+	return b, err
+}
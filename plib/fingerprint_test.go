@@ -0,0 +1,132 @@
+package plib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestProcessesFingerprint(t *testing.T) {
+	ps := Processes{
+		1:  {ID: 1, CommandName: "systemd", BinarySHA: "aa"},
+		10: {ID: 10, ParentProcess: 1, CommandName: "sshd", BinarySHA: "bb"},
+		20: {ID: 20, ParentProcess: 10, CommandName: "bash", BinarySHA: "cc"},
+		30: {ID: 30, ParentProcess: 99, CommandName: "orphan", BinarySHA: "dd"},
+		40: {ID: 40, ParentProcess: 1, CommandName: "kthread"},
+		45: {ID: 45, ParentProcess: 1, CommandName: "sudo", BinarySHA: permDenied},
+		50: {ID: 50, ParentProcess: 45, CommandName: "vim", BinarySHA: "ee"},
+		60: {ID: 60, ParentProcess: 1, CommandName: "cron", BinarySHA: shaReadError},
+	}
+	fp, err := ps.Fingerprint(20)
+	if err != nil {
+		t.Fatalf("failed fingerprinting process: %s", err)
+	}
+	sum := sha256.Sum256([]byte("ccbbaa"))
+	if fp.Value != hex.EncodeToString(sum[:]) {
+		t.Logf("fail: expected the checksum of the lineage's binaries, actual: %s", fp.Value)
+		t.Fail()
+	}
+	if len(fp.Lineage) != 3 || fp.Lineage[0].ID != 20 || fp.Lineage[2].ID != 1 {
+		t.Logf("fail: expected the lineage 20, 10, 1, actual: %v", fp.Lineage)
+		t.Fail()
+	}
+
+	// processes whose own or an ancestor's checksum is missing can't be
+	// fingerprinted.
+	for _, pid := range []int{30, 40, 50, 60, 70} {
+		if _, err := ps.Fingerprint(pid); err == nil {
+			t.Logf("fail: expected an error fingerprinting process %d", pid)
+			t.Fail()
+		}
+	}
+}
+
+func TestFingerprintReport(t *testing.T) {
+	ps := Processes{
+		10: {ID: 10, ParentProcess: 1, MachineID: "m1", CommandName: "sshd", BinarySHA: "bb"},
+		1:  {ID: 1, MachineID: "m1", CommandName: "systemd", BinarySHA: "aa"},
+		40: {ID: 40, ParentProcess: 1, MachineID: "m1", CommandName: "kthread"},
+	}
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := ps.FingerprintReport(at)
+	if r.MachineID != "m1" || !r.Created.Equal(at) || len(r.Processes) != 3 {
+		t.Fatalf("fail: unexpected report: %+v", r)
+	}
+	fp, _ := ps.Fingerprint(10)
+	if r.Processes[1].PID != 10 || r.Processes[1].Fingerprint != fp.Value {
+		t.Logf("fail: expected the fingerprint of process 10, actual: %+v", r.Processes[1])
+		t.Fail()
+	}
+	if r.Processes[2].PID != 40 || r.Processes[2].Fingerprint != "" || r.Processes[2].Error == "" {
+		t.Logf("fail: expected an error fingerprinting process 40, actual: %+v", r.Processes[2])
+		t.Fail()
+	}
+}
+
+func TestFingerprintDrift(t *testing.T) {
+	ps := Processes{
+		1:  {ID: 1, BinarySHA: "aa"},
+		10: {ID: 10, ParentProcess: 1, BinarySHA: "bb"},
+		20: {ID: 20, ParentProcess: 10, BinarySHA: "cc"},
+	}
+	expected, _ := ps.Fingerprint(20)
+	// the same binaries under different IDs.
+	same := Processes{
+		1:  {ID: 1, BinarySHA: "aa"},
+		11: {ID: 11, ParentProcess: 1, BinarySHA: "bb"},
+		21: {ID: 21, ParentProcess: 11, BinarySHA: "cc"},
+	}
+	actual, _ := same.Fingerprint(21)
+	if drift := expected.Drift(actual); len(drift) != 0 {
+		t.Logf("fail: expected no drift, actual: %v", drift)
+		t.Fail()
+	}
+
+	changed := Processes{
+		1:  {ID: 1, BinarySHA: "aa"},
+		10: {ID: 10, ParentProcess: 1, BinarySHA: "b2"},
+		20: {ID: 20, ParentProcess: 10, BinarySHA: "cc"},
+		30: {ID: 30, ParentProcess: 20, BinarySHA: "ee"},
+	}
+	actual, _ = changed.Fingerprint(30)
+	drift := expected.Drift(actual)
+	if len(drift) != 2 {
+		t.Fatalf("fail: expected drift at depths 1 and 3, actual: %v", drift)
+	}
+	if drift[0].Depth != 1 || drift[0].Expected.BinarySHA != "bb" || drift[0].Actual.BinarySHA != "b2" {
+		t.Logf("fail: expected the changed parent binary, actual: %+v", drift[0])
+		t.Fail()
+	}
+	if drift[1].Depth != 3 || drift[1].Expected != nil || drift[1].Actual.ID != 30 {
+		t.Logf("fail: expected the extra process, actual: %+v", drift[1])
+		t.Fail()
+	}
+}
+
+func TestBaselineStore(t *testing.T) {
+	s := NewBaselineStore(t.TempDir())
+	ps := Processes{1: {ID: 1, BinarySHA: "aa"}, 10: {ID: 10, ParentProcess: 1, BinarySHA: "bb"}}
+	fp, _ := ps.Fingerprint(10)
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := s.Save(Baseline{Name: "web", Created: created, Fingerprint: fp}); err != nil {
+		t.Fatalf("failed saving baseline: %s", err)
+	}
+	b, err := s.Load("web")
+	if err != nil {
+		t.Fatalf("failed loading baseline: %s", err)
+	}
+	if b.Value != fp.Value || !b.Created.Equal(created) || len(b.Lineage) != 2 {
+		t.Logf("fail: expected the saved baseline, actual: %+v", b)
+		t.Fail()
+	}
+
+	if _, err := s.Load("missing"); err == nil {
+		t.Log("fail: expected an error loading a missing baseline")
+		t.Fail()
+	}
+	if err := s.Save(Baseline{Name: "../escape"}); err == nil {
+		t.Log("fail: expected an error saving a baseline with a path as its name")
+		t.Fail()
+	}
+}
@@ -0,0 +1,45 @@
+package plib
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NewSHAFromProcess takes a path to a file (likely a binary) and returns a
+// SHA256 checksum representing its contents.
+func NewSHAFromProcess(path string) string {
+	if path == "" {
+		return ""
+	}
+	sha, err := HashFile(path)
+	if err != nil {
+		return shaReadError
+	}
+	return sha
+}
+
+// HashKnown returns whether sha is the hash of a binary, as recorded in a
+// process's BinarySHA, rather than empty or the placeholder for a binary that
+// couldn't be read or proctor lacked permission to read.
+func HashKnown(sha string) bool {
+	return sha != "" && sha != shaReadError && sha != permDenied
+}
+
+// HashFile returns the hex encoded SHA256 of the file at path, the same
+// checksum recorded as a process's BinarySHA. An error is returned when the
+// file cannot be read.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed opening %s: %s", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed reading %s: %s", path, err)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
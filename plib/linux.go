@@ -1,19 +1,31 @@
+//go:build linux
+
 package plib
 
 import (
-	"crypto/sha256"
 	"encoding/gob"
 	"fmt"
-	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/arctir/proctor/host"
 )
 
+// newInspector returns the inspector of the host's operating system, a
+// [LinuxInspector]. See [NewInspector].
+func newInspector(opts ...InspectorConfig) (Inspector, error) {
+	insp, err := newLinuxInspector(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return insp, nil
+}
+
 // newLinuxInspector takes an optional [LinuxInspectorConfig] and returns a
 // configured LinuxInspector, which can be used to operator on processes with
 // functions like [LinuxInspector.GetProcesses].
@@ -74,23 +86,32 @@ func (l *LinuxInspector) LoadProcesses() error {
 		return err
 	}
 
-	knownSHAs := map[string]string{}
+	start := time.Now()
+	loader := newProcessLoader(l.LinuxConfig.ProcfsFilePath, map[string]string{})
+	skippedKernel, skippedPermission := 0, 0
 	// for each pid, load its data
 	for _, p := range ps {
-		loadedProcess := LoadProcessStat(l.LinuxConfig.ProcfsFilePath, p, knownSHAs)
+		loadedProcess := loader.load(p)
 		// when the process is a kernel process and inspect is configured to not
 		// include them, skip this process.
 		if !l.LinuxConfig.IncludeKernel && loadedProcess.IsKernel {
+			skippedKernel++
 			continue
 		}
 		// when the user doesn't have permission to access process details and the
 		// inspector is configured to not include these, skip this process.
 		if !l.LinuxConfig.IncludePermissionIssues && !loadedProcess.HasPermission {
+			skippedPermission++
 			continue
 		}
 
+		if l.Redactor != nil {
+			l.Redactor.Process(&loadedProcess)
+		}
 		l.ps[p] = &loadedProcess
 	}
+	slog.Debug("loaded processes", "procfs", l.LinuxConfig.ProcfsFilePath, "processes", len(l.ps),
+		"skipped_kernel", skippedKernel, "skipped_permission", skippedPermission, "duration", time.Since(start))
 
 	// if config says to ignore cache, then exit here.
 	if l.IgnoreCache {
@@ -101,6 +122,7 @@ func (l *LinuxInspector) LoadProcesses() error {
 	if err != nil {
 		return fmt.Errorf("failed persisting process details (cache) to filesystem: %s", err)
 	}
+	slog.Debug("wrote process cache", "path", filepath.Join(l.CacheFilePath, CacheFileName))
 	return nil
 }
 
@@ -130,6 +152,14 @@ func (l *LinuxInspector) GetProcesses() (Processes, error) {
 	if l.ps == nil {
 		if !l.IgnoreCache {
 			l.ps = loadProcessesFromCache(l.CacheFilePath)
+			if l.ps != nil {
+				// the cache may have been written by an inspector masking fewer
+				// secrets.
+				if l.Redactor != nil {
+					l.Redactor.Processes(l.ps)
+				}
+				slog.Debug("loaded processes from cache", "path", filepath.Join(l.CacheFilePath, CacheFileName), "processes", len(l.ps))
+			}
 		}
 	}
 
@@ -306,26 +336,6 @@ func GetProcessNameFromBinary(procfsFp string, pid int) (string, error) {
 	return dirs[len(dirs)-1], nil
 }
 
-// NewSHAFromProcess takes a path to a file (likely a binary) and returns a
-// SHA256 checksum representing its contents.
-func NewSHAFromProcess(path string) string {
-	if path == "" {
-		return ""
-	}
-	f, err := os.Open(path)
-	if err != nil {
-		return shaReadError
-	}
-	defer f.Close()
-
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return shaReadError
-	}
-
-	return fmt.Sprintf("%x", h.Sum(nil))
-}
-
 // GetProcessPath returns the path, or location, of the binary being executed
 // as a process. To reliably determine the path, it reads the symbolic link in
 // /proc/${PID}/exe and resolves the final file.
@@ -337,6 +347,13 @@ func GetProcessPath(procfsFp string, pid int) (string, error) {
 	return exeLink, nil
 }
 
+// GetProcessArgs returns the flags and arguments the process was run with,
+// separated by spaces, by reading /proc/${PID}/cmdline. The command itself
+// (argv[0]) isn't included. Kernel processes have no arguments.
+func GetProcessArgs(procfsFp string, pid int) (string, error) {
+	return newProcReader(procfsFp).args(pid)
+}
+
 // LoadProcessStat introspects the process's directory in procfs to retrieve
 // relevant information and product a instance of Process. The generated
 // Process object is then returned. No error is returned, as missing
@@ -345,68 +362,91 @@ func GetProcessPath(procfsFp string, pid int) (string, error) {
 // SHA values where the key is the binary path. This enables lookup of already
 // known SHAs without needing to rehash. To force rehash, use an empty map.
 func LoadProcessStat(procfsFp string, pid int, knownSHAs map[string]string) Process {
-	hasPerm := true
-	isK := false
-	var sha string
-	name, err := GetProcessNameFromBinary(procfsFp, pid)
+	return newProcessLoader(procfsFp, knownSHAs).load(pid)
+}
 
-	// when error is bubbled up, determine why to set name correctly
-	if err != nil {
-		switch {
-		case os.IsPermission(err):
-			name = permDenied
-			hasPerm = false
-		case os.IsNotExist(err):
-			stat, err := os.ReadFile(filepath.Join(procfsFp, strconv.Itoa(pid), statDir))
-			if err != nil {
-				name = "ERROR_RESOLVING_NAME"
-				// when there is an error resolving the name, break out of the rest of
-				// the logic in this case as we cannot resolve the name from the 2nd
-				// value in stat.
-				break
-			}
-			//TODO(joshrosso): But does this handle nullCharacter?
-			parsedStats := strings.Split(string(stat), " ")
-			name = parsedStats[1]
-			isK = true
-		default:
-			name = "ERROR_UNKNOWN"
-		}
+// processLoader loads processes from procfs, sharing what's the same for
+// every process, such as the host's ID, the hashes of binaries, and the
+// buffers files are read into, across the processes it loads.
+type processLoader struct {
+	procfsFp  string
+	reader    *procReader
+	knownSHAs map[string]string
+	hostID    string
+}
 
-	}
-	path, err := GetProcessPath(procfsFp, pid)
+// newProcessLoader returns a loader of the processes in the procfs at
+// procfsFp. See [LoadProcessStat] for knownSHAs.
+func newProcessLoader(procfsFp string, knownSHAs map[string]string) *processLoader {
+	lr := host.NewLinuxReader(host.LinuxReaderConfig{})
+	hostID, err := lr.GetHostID()
 	if err != nil {
-		if os.IsPermission(err) {
-			path = permDenied
-			sha = permDenied
-		} else {
-			path = statError
-			sha = statError
-		}
+		hostID = "ERROR_UNKNOWN"
+	}
+	return &processLoader{procfsFp: procfsFp, reader: newProcReader(procfsFp), knownSHAs: knownSHAs, hostID: hostID}
+}
 
-	} else {
+// load returns the process with ID pid. See [LoadProcessStat].
+func (pl *processLoader) load(pid int) Process {
+	hasPerm := true
+	isK := false
+	var name, sha string
+	stat := pl.reader.stat(pid)
+	path, err := GetProcessPath(pl.procfsFp, pid)
+
+	// when error is bubbled up, determine why to set name and path correctly
+	switch {
+	case err == nil:
+		name = filepath.Base(path)
+		// when procfs is mounted from another host's mount namespace, such as
+		// a Kubernetes node's procfs mounted into a DaemonSet's pod, path only
+		// resolves within the process's own mount namespace. The binary is read
+		// through its exe link instead and known by its device and inode.
+		binPath, key := path, path
+		if pl.procfsFp != defaultProcDir {
+			binPath = filepath.Join(pl.procfsFp, strconv.Itoa(pid), exeDir)
+			key = binaryKey(binPath)
+		}
 		// determine if sha is already known, if now, calculate it from file.
-		if sum, ok := knownSHAs[path]; ok {
+		if sum, ok := pl.knownSHAs[key]; ok {
 			sha = sum
 		} else {
-			sha = NewSHAFromProcess(path)
-			knownSHAs[path] = sha
+			sha = NewSHAFromProcess(binPath)
+			pl.knownSHAs[key] = sha
 		}
+	case os.IsPermission(err):
+		name = permDenied
+		hasPerm = false
+		path = permDenied
+		sha = permDenied
+	case os.IsNotExist(err):
+		// kernel processes have no binary, so they're named by their stat
+		// file.
+		name = stat.FileName
+		if name == "" {
+			name = "ERROR_RESOLVING_NAME"
+		} else {
+			isK = true
+		}
+		path = statError
+		sha = statError
+	default:
+		name = "ERROR_UNKNOWN"
+		path = statError
+		sha = statError
 	}
-	stat := NewProcessStatFromFile(procfsFp, pid)
-	lr := host.NewLinuxReader(host.LinuxReaderConfig{})
-	hostID, err := lr.GetHostID()
-	if err != nil {
-		hostID = "ERROR_UNKNOWN"
-	}
+	// like the environment, the command line of another user's process may
+	// not be readable, in which case it's left empty.
+	args, _ := pl.reader.args(pid)
 
 	p := Process{
 		ID:            pid,
-		MachineID:     hostID,
+		MachineID:     pl.hostID,
 		IsKernel:      isK,
 		HasPermission: hasPerm,
 		CommandName:   name,
 		CommandPath:   path,
+		FlagsAndArgs:  args,
 		ParentProcess: stat.ParentID,
 		BinarySHA:     sha,
 		Type:          linuxProcessType,
@@ -416,131 +456,35 @@ func LoadProcessStat(procfsFp string, pid int, knownSHAs map[string]string) Proc
 	return p
 }
 
+// binaryKey returns a key identifying the file at path by its device, inode,
+// and modification time, which are the same across mount namespaces. path is
+// returned when the file can't be stat'd.
+func binaryKey(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return path
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return path
+	}
+	return fmt.Sprintf("%d:%d:%d", stat.Dev, stat.Ino, info.ModTime().UnixNano())
+}
+
 // NewProcessStatFromFile translates fields in the stat file
 // (/proc/${PID}/stat) into structured data. See the [kernel docs] for a table
 // of values found in a stat file.
 //
 // [kernel docs]: https://www.kernel.org/doc/html/latest/filesystems/proc.html#id10.
 func NewProcessStatFromFile(procfsFp string, pid int) ProcessStat {
-	ps := ProcessStat{}
-	stat, err := os.ReadFile(filepath.Join(procfsFp, strconv.Itoa(pid), statDir))
-	if err != nil {
-		return ps
-	}
-	parsedStats := strings.Split(string(stat), " ")
-
-	for i, stat := range parsedStats {
-		//fmt.Printf("stat %d: %s\n", i, stat)
-		switch i {
-		case 0:
-			ps.ID, _ = strconv.Atoi(stat)
-		case 1:
-			ps.FileName = stat
-		case 2:
-			ps.State = stat
-		case 3:
-			ps.ParentID, _ = strconv.Atoi(stat)
-		case 4:
-			ps.ProcessGroup, _ = strconv.Atoi(stat)
-		case 5:
-			ps.SessionID, _ = strconv.Atoi(stat)
-		case 6:
-			ps.TTY, _ = strconv.Atoi(stat)
-		case 7:
-			ps.TTYProcessGroup, _ = strconv.Atoi(stat)
-		case 8:
-			ps.TaskFlags = stat
-		case 9:
-			ps.MinorFaultQuantity, _ = strconv.Atoi(stat)
-		case 10:
-			ps.MinorFaultWithChildQuantity, _ = strconv.Atoi(stat)
-		case 11:
-			ps.MajorFaultQuantity, _ = strconv.Atoi(stat)
-		case 12:
-			ps.MajorFaultWithChildQuantity, _ = strconv.Atoi(stat)
-		case 13:
-			ps.UserModeTime, _ = strconv.Atoi(stat)
-		case 14:
-			ps.KernalTime, _ = strconv.Atoi(stat)
-		case 15:
-			ps.UserModeTimeWithChild, _ = strconv.Atoi(stat)
-		case 16:
-			ps.KernalTimeWithChild, _ = strconv.Atoi(stat)
-		case 17:
-			ps.Priority, _ = strconv.Atoi(stat)
-		case 18:
-			ps.Nice, _ = strconv.Atoi(stat)
-		case 19:
-			ps.ThreadQuantity, _ = strconv.Atoi(stat)
-		case 20:
-			ps.ItRealValue, _ = strconv.Atoi(stat)
-		case 21:
-			ps.StartTime, _ = strconv.Atoi(stat)
-		case 22:
-			ps.VirtualMemSize, _ = strconv.Atoi(stat)
-		case 23:
-			ps.ResidentSetMemSize, _ = strconv.Atoi(stat)
-		case 24:
-			ps.RSSByteLimit, _ = strconv.Atoi(stat)
-		case 25:
-			ps.StartCode = ConvertToHexMemoryAddress(stat)
-		case 26:
-			ps.EndCode = ConvertToHexMemoryAddress(stat)
-		case 27:
-			ps.StartStack = ConvertToHexMemoryAddress(stat)
-		case 28:
-			ps.ExtendedStackPointerAddress, _ = strconv.Atoi(stat)
-		case 29:
-			ps.ExtendedInstructionPointer, _ = strconv.Atoi(stat)
-		case 30:
-			ps.SignalPendingQuantity, _ = strconv.Atoi(stat)
-		case 31:
-			ps.SignalsBlockedQuantity, _ = strconv.Atoi(stat)
-		case 32:
-			ps.SignalsIgnoredQuantity, _ = strconv.Atoi(stat)
-		case 33:
-			ps.SiganlsCaughtQuantity, _ = strconv.Atoi(stat)
-		case 34:
-			ps.PlaceHolder1, _ = strconv.Atoi(stat)
-		case 35:
-			ps.PlaceHolder2, _ = strconv.Atoi(stat)
-		case 36:
-			ps.PlaceHolder3, _ = strconv.Atoi(stat)
-		case 37:
-			signalNumeric, _ := strconv.Atoi(stat)
-			ps.ExitSignal = Signal(signalNumeric)
-		case 38:
-			ps.CPU, _ = strconv.Atoi(stat)
-		case 39:
-			ps.RealtimePriority, _ = strconv.Atoi(stat)
-		case 40:
-			ps.SchedulingPolicy, _ = strconv.Atoi(stat)
-		case 41:
-			ps.TimeSpentOnBlockIO, _ = strconv.Atoi(stat)
-		case 42:
-			ps.GuestTime, _ = strconv.Atoi(stat)
-		case 43:
-			ps.GuestTimeWithChild, _ = strconv.Atoi(stat)
-		case 44:
-			ps.StartDataAddress = ConvertToHexMemoryAddress(stat)
-		case 45:
-			ps.EndDataAddress = ConvertToHexMemoryAddress(stat)
-		case 46:
-			ps.HeapExpansionAddress = ConvertToHexMemoryAddress(stat)
-		case 47:
-			ps.StartCMDAddress = ConvertToHexMemoryAddress(stat)
-		case 48:
-			ps.EndCMDAddress = ConvertToHexMemoryAddress(stat)
-		case 49:
-			ps.StartEnvAddress = ConvertToHexMemoryAddress(stat)
-		case 50:
-			ps.EndEnvAddress = ConvertToHexMemoryAddress(stat)
-		case 51:
-			ps.ExitCode, _ = strconv.Atoi(stat)
-		}
-	}
+	return newProcReader(procfsFp).stat(pid)
+}
 
-	return ps
+// getProcessUID returns the real user ID of the process, read from the Uid
+// line of /proc/${PID}/status, or -1 when it can't be read. The line holds the
+// real, effective, saved, and filesystem user IDs, in that order.
+func getProcessUID(procfsFp string, pid int) int {
+	return newProcReader(procfsFp).uid(pid)
 }
 
 // ConvertToHexMemoryAddress takes a memory address, represented in [decimal
@@ -12,6 +12,7 @@ const (
 	linuxProcessType = "linux"
 	cmdDir           = "cmdline"
 	statDir          = "stat"
+	statusFile       = "status"
 	exeDir           = "exe"
 	nullCharacter    = "\x00"
 	permDenied       = "PERM_DENIED"
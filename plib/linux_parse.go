@@ -0,0 +1,331 @@
+//go:build linux
+
+package plib
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// initialReadBufferSize is the capacity a procReader's buffer starts with,
+// which fits the stat, status, and cmdline files of most processes.
+const initialReadBufferSize = 4096
+
+// uidPrefix starts the line of a status file holding the process's user IDs.
+var uidPrefix = []byte("Uid:")
+
+// procReader reads and parses the files of processes in procfs. The files
+// are read into a buffer, and their paths built in another, that are reused
+// across reads, so loading every process on a host doesn't allocate a buffer
+// per file or a string per field. A procReader isn't safe for concurrent
+// use.
+type procReader struct {
+	procfs string
+	buf    []byte
+	path   []byte
+}
+
+// newProcReader returns a reader of the processes in the procfs at procfs.
+func newProcReader(procfs string) *procReader {
+	return &procReader{procfs: procfs, buf: make([]byte, 0, initialReadBufferSize)}
+}
+
+// filePath returns the path of the file named name of process pid.
+func (r *procReader) filePath(pid int, name string) string {
+	r.path = append(r.path[:0], r.procfs...)
+	r.path = append(r.path, os.PathSeparator)
+	r.path = strconv.AppendInt(r.path, int64(pid), 10)
+	r.path = append(r.path, os.PathSeparator)
+	r.path = append(r.path, name...)
+	return string(r.path)
+}
+
+// read returns the contents of the file named name of process pid. The
+// contents are only valid until the next read.
+func (r *procReader) read(pid int, name string) ([]byte, error) {
+	f, err := os.Open(r.filePath(pid, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// procfs reports a size of 0 for its files, so they're read until EOF
+	// rather than into a buffer of their size.
+	r.buf = r.buf[:0]
+	for {
+		if len(r.buf) == cap(r.buf) {
+			r.buf = append(r.buf, 0)[:len(r.buf)]
+		}
+		n, err := f.Read(r.buf[len(r.buf):cap(r.buf)])
+		r.buf = r.buf[:len(r.buf)+n]
+		if err == io.EOF {
+			return r.buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// stat returns the parsed stat file of process pid, along with its real user
+// ID. See [NewProcessStatFromFile].
+func (r *procReader) stat(pid int) ProcessStat {
+	ps := ProcessStat{UID: r.uid(pid)}
+	data, err := r.read(pid, statDir)
+	if err != nil {
+		return ps
+	}
+	parseStat(data, &ps)
+	return ps
+}
+
+// uid returns the real user ID of process pid. See [getProcessUID].
+func (r *procReader) uid(pid int) int {
+	data, err := r.read(pid, statusFile)
+	if err != nil {
+		return -1
+	}
+	return parseStatusUID(data)
+}
+
+// args returns the flags and arguments of process pid. See [GetProcessArgs].
+func (r *procReader) args(pid int) (string, error) {
+	data, err := r.read(pid, cmdDir)
+	if err != nil {
+		return "", err
+	}
+	return parseCmdlineArgs(data), nil
+}
+
+// parseStat sets the fields of ps from data, the contents of a stat file.
+// The file name (the second field) is enclosed in parentheses and may itself
+// contain spaces and parentheses, so it spans to the last closing
+// parenthesis, and the remaining fields are counted from there.
+func parseStat(data []byte, ps *ProcessStat) {
+	data = bytes.TrimRight(data, "\n")
+	open := bytes.IndexByte(data, '(')
+	close := bytes.LastIndexByte(data, ')')
+	if open < 1 || close < open {
+		return
+	}
+	ps.ID = atoi(bytes.TrimSpace(data[:open]))
+	ps.FileName = string(data[open : close+1])
+
+	rest := bytes.TrimLeft(data[close+1:], " ")
+	for i := 2; len(rest) > 0; i++ {
+		field := rest
+		if end := bytes.IndexByte(rest, ' '); end >= 0 {
+			field, rest = rest[:end], rest[end+1:]
+		} else {
+			rest = nil
+		}
+		setStatField(ps, i, field)
+	}
+}
+
+// setStatField sets the field of ps at index i of a stat file to field.
+// Numbers that fail to parse are left as 0. See the [kernel docs] for the
+// table of fields.
+//
+// [kernel docs]: https://www.kernel.org/doc/html/latest/filesystems/proc.html#id10.
+func setStatField(ps *ProcessStat, i int, field []byte) {
+	switch i {
+	case 2:
+		ps.State = string(field)
+	case 3:
+		ps.ParentID = atoi(field)
+	case 4:
+		ps.ProcessGroup = atoi(field)
+	case 5:
+		ps.SessionID = atoi(field)
+	case 6:
+		ps.TTY = atoi(field)
+	case 7:
+		ps.TTYProcessGroup = atoi(field)
+	case 8:
+		ps.TaskFlags = string(field)
+	case 9:
+		ps.MinorFaultQuantity = atoi(field)
+	case 10:
+		ps.MinorFaultWithChildQuantity = atoi(field)
+	case 11:
+		ps.MajorFaultQuantity = atoi(field)
+	case 12:
+		ps.MajorFaultWithChildQuantity = atoi(field)
+	case 13:
+		ps.UserModeTime = atoi(field)
+	case 14:
+		ps.KernalTime = atoi(field)
+	case 15:
+		ps.UserModeTimeWithChild = atoi(field)
+	case 16:
+		ps.KernalTimeWithChild = atoi(field)
+	case 17:
+		ps.Priority = atoi(field)
+	case 18:
+		ps.Nice = atoi(field)
+	case 19:
+		ps.ThreadQuantity = atoi(field)
+	case 20:
+		ps.ItRealValue = atoi(field)
+	case 21:
+		ps.StartTime = atoi(field)
+	case 22:
+		ps.VirtualMemSize = atoi(field)
+	case 23:
+		ps.ResidentSetMemSize = atoi(field)
+	case 24:
+		ps.RSSByteLimit = atoi(field)
+	case 25:
+		ps.StartCode = hexAddress(field)
+	case 26:
+		ps.EndCode = hexAddress(field)
+	case 27:
+		ps.StartStack = hexAddress(field)
+	case 28:
+		ps.ExtendedStackPointerAddress = atoi(field)
+	case 29:
+		ps.ExtendedInstructionPointer = atoi(field)
+	case 30:
+		ps.SignalPendingQuantity = atoi(field)
+	case 31:
+		ps.SignalsBlockedQuantity = atoi(field)
+	case 32:
+		ps.SignalsIgnoredQuantity = atoi(field)
+	case 33:
+		ps.SiganlsCaughtQuantity = atoi(field)
+	case 34:
+		ps.PlaceHolder1 = atoi(field)
+	case 35:
+		ps.PlaceHolder2 = atoi(field)
+	case 36:
+		ps.PlaceHolder3 = atoi(field)
+	case 37:
+		ps.ExitSignal = Signal(atoi(field))
+	case 38:
+		ps.CPU = atoi(field)
+	case 39:
+		ps.RealtimePriority = atoi(field)
+	case 40:
+		ps.SchedulingPolicy = atoi(field)
+	case 41:
+		ps.TimeSpentOnBlockIO = atoi(field)
+	case 42:
+		ps.GuestTime = atoi(field)
+	case 43:
+		ps.GuestTimeWithChild = atoi(field)
+	case 44:
+		ps.StartDataAddress = hexAddress(field)
+	case 45:
+		ps.EndDataAddress = hexAddress(field)
+	case 46:
+		ps.HeapExpansionAddress = hexAddress(field)
+	case 47:
+		ps.StartCMDAddress = hexAddress(field)
+	case 48:
+		ps.EndCMDAddress = hexAddress(field)
+	case 49:
+		ps.StartEnvAddress = hexAddress(field)
+	case 50:
+		ps.EndEnvAddress = hexAddress(field)
+	case 51:
+		ps.ExitCode = atoi(field)
+	}
+}
+
+// parseStatusUID returns the real user ID from data, the contents of a
+// status file, or -1 when it has none. The Uid line holds the real,
+// effective, saved, and filesystem user IDs, in that order.
+func parseStatusUID(data []byte) int {
+	for len(data) > 0 {
+		line := data
+		if end := bytes.IndexByte(data, '\n'); end >= 0 {
+			line, data = data[:end], data[end+1:]
+		} else {
+			data = nil
+		}
+		if !bytes.HasPrefix(line, uidPrefix) {
+			continue
+		}
+		fields := bytes.TrimLeft(line[len(uidPrefix):], " \t")
+		if end := bytes.IndexAny(fields, " \t"); end >= 0 {
+			fields = fields[:end]
+		}
+		if uid, ok := parseInt(fields); ok {
+			return uid
+		}
+		return -1
+	}
+	return -1
+}
+
+// parseCmdlineArgs returns the arguments in data, the contents of a cmdline
+// file, after the command itself (argv[0]), separated by spaces. The null
+// characters separating the arguments in data are replaced in place.
+func parseCmdlineArgs(data []byte) string {
+	data = bytes.TrimRight(data, nullCharacter)
+	start := bytes.IndexByte(data, 0)
+	if start < 0 {
+		return ""
+	}
+	args := data[start+1:]
+	for i, c := range args {
+		if c == 0 {
+			args[i] = ' '
+		}
+	}
+	return string(args)
+}
+
+// atoi returns the base 10 integer in b, like strconv.Atoi with its error
+// ignored, without converting b to a string. So, it's 0 when b isn't an
+// integer, and clamped to the range of int when b is out of it, such as the
+// unsigned RSS limit of most processes.
+func atoi(b []byte) int {
+	n, _ := parseInt(b)
+	return n
+}
+
+// parseInt returns the base 10 integer in b and whether b holds one that
+// fits in an int. See [atoi] for what's returned when it doesn't.
+func parseInt(b []byte) (int, bool) {
+	neg := false
+	if len(b) > 0 && (b[0] == '-' || b[0] == '+') {
+		neg = b[0] == '-'
+		b = b[1:]
+	}
+	if len(b) == 0 {
+		return 0, false
+	}
+	limit := uint64(math.MaxInt)
+	if neg {
+		limit++
+	}
+	var n uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		d := uint64(c - '0')
+		if n > (limit-d)/10 {
+			if neg {
+				return math.MinInt, false
+			}
+			return math.MaxInt, false
+		}
+		n = n*10 + d
+	}
+	if neg {
+		return -int(n-1) - 1, true
+	}
+	return int(n), true
+}
+
+// hexAddress returns the memory address in b, in decimal notation, in
+// hexadecimal notation with the 0x prefix. See [ConvertToHexMemoryAddress].
+func hexAddress(b []byte) string {
+	var buf [24]byte
+	return string(strconv.AppendInt(append(buf[:0], "0x"...), int64(atoi(b)), 16))
+}
@@ -0,0 +1,154 @@
+//go:build linux
+
+package plib
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// benchmarkProcesses is the number of processes in the procfs benchmarks
+// load, which is typical of a busy host.
+const benchmarkProcesses = 500
+
+// writeBenchmarkProcfs writes a procfs of n processes, each with a stat,
+// status, and cmdline file and an exe link to the same binary, to dir.
+func writeBenchmarkProcfs(tb testing.TB, dir string, n int) {
+	bin := filepath.Join(dir, "bin")
+	if err := os.WriteFile(bin, []byte("binary"), 0755); err != nil {
+		tb.Fatalf("failed setting up sample data for test: %s", err)
+	}
+	status := "Name:\tThunar\nUmask:\t0022\nState:\tS (sleeping)\nTgid:\t1002\nNgid:\t0\nPid:\t1002\nPPid:\t898\nTracerPid:\t0\nUid:\t1000\t1000\t1000\t1000\nGid:\t1000\t1000\t1000\t1000\nFDSize:\t64\nVmRSS:\t58180 kB\nThreads:\t3\n"
+	for pid := 1; pid <= n; pid++ {
+		pidDir := filepath.Join(dir, strconv.Itoa(pid))
+		if err := os.MkdirAll(pidDir, 0755); err != nil {
+			tb.Fatalf("failed setting up sample data for test: %s", err)
+		}
+		files := map[string]string{
+			statDir:    StatData1002 + "\n",
+			statusFile: status,
+			cmdDir:     "/usr/bin/Thunar\x00--daemon\x00--sm-client-id\x00" + fmt.Sprint(pid) + "\x00",
+		}
+		for name, contents := range files {
+			if err := os.WriteFile(filepath.Join(pidDir, name), []byte(contents), 0644); err != nil {
+				tb.Fatalf("failed setting up sample data for test: %s", err)
+			}
+		}
+		if err := os.Symlink(bin, filepath.Join(pidDir, exeDir)); err != nil {
+			tb.Fatalf("failed setting up sample data for test: %s", err)
+		}
+	}
+}
+
+func TestParseStat(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		id       int
+		fileName string
+		parentID int
+	}{
+		{"fixture", StatData1002, 1002, "(Thunar)", 898},
+		{"trailing newline", StatData68657 + "\n", 68657, "(chromium)", 68654},
+		{"spaces and parentheses in name", "4242 (Web Content (1)) S 68654 68650 68650 0 -1 4194560 1462096 116023 16 0 13834 4693 47 34 20 0 23 0 7679775 35172757504 80617 18446744073709551615 94708279918592 0\n", 4242, "(Web Content (1))", 68654},
+	}
+	for _, test := range tests {
+		var ps ProcessStat
+		parseStat([]byte(test.data), &ps)
+		if ps.ID != test.id || ps.FileName != test.fileName || ps.ParentID != test.parentID || ps.State != "S" {
+			t.Logf("fail: %s parsed the wrong fields: %+v", test.name, ps)
+			t.Fail()
+		}
+	}
+
+	// every field must match how they were parsed before stat was scanned.
+	var ps ProcessStat
+	parseStat([]byte(StatData1002), &ps)
+	if ps.RSSByteLimit != math.MaxInt || ps.StartCode != ConvertToHexMemoryAddress("94657007656960") || ps.ExitSignal != Signal(17) || ps.EndEnvAddress != ConvertToHexMemoryAddress("140727172497384") {
+		t.Logf("fail: parsed the wrong fields: %+v", ps)
+		t.Fail()
+	}
+}
+
+func TestParseStatusUID(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		uid    int
+	}{
+		{"real uid", "Name:\tbash\nUid:\t1000\t1001\t1001\t1001\nGid:\t1000\t1000\t1000\t1000\n", 1000},
+		{"last line", "Name:\tbash\nUid:\t0\t0\t0\t0", 0},
+		{"no uid", "Name:\tbash\n", -1},
+		{"invalid uid", "Uid:\tnobody\n", -1},
+	}
+	for _, test := range tests {
+		if uid := parseStatusUID([]byte(test.status)); uid != test.uid {
+			t.Logf("fail: %s expected %d, actual: %d", test.name, test.uid, uid)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseCmdlineArgs(t *testing.T) {
+	tests := []struct {
+		cmdline string
+		args    string
+	}{
+		{"/usr/bin/Thunar\x00--daemon\x00--sm-client-id\x00", "--daemon --sm-client-id"},
+		{"/usr/bin/Thunar\x00", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if args := parseCmdlineArgs([]byte(test.cmdline)); args != test.args {
+			t.Logf("fail: expected %q, actual: %q", test.args, args)
+			t.Fail()
+		}
+	}
+}
+
+func TestAtoi(t *testing.T) {
+	for _, s := range []string{"0", "-1", "+7", "1002", "", "-", "12a", "18446744073709551615", "9223372036854775808", "-9223372036854775808", "-99999999999999999999"} {
+		expected, _ := strconv.Atoi(s)
+		if actual := atoi([]byte(s)); actual != expected {
+			t.Logf("fail: %q expected %d, actual: %d", s, expected, actual)
+			t.Fail()
+		}
+		if expected, actual := ConvertToHexMemoryAddress(s), hexAddress([]byte(s)); actual != expected {
+			t.Logf("fail: %q expected %s, actual: %s", s, expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func BenchmarkNewProcessStatFromFile(b *testing.B) {
+	procFp := b.TempDir()
+	writeBenchmarkProcfs(b, procFp, 1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewProcessStatFromFile(procFp, 1)
+	}
+}
+
+func BenchmarkLoadProcesses(b *testing.B) {
+	procFp := b.TempDir()
+	writeBenchmarkProcfs(b, procFp, benchmarkProcesses)
+	li, err := newLinuxInspector(InspectorConfig{
+		LinuxConfig: LinuxInspectorConfig{ProcfsFilePath: procFp},
+		IgnoreCache: true,
+	})
+	if err != nil {
+		b.Fatalf("error, failed creating a linux inspector: %s", err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := li.LoadProcesses(); err != nil {
+			b.Fatalf("fail: unexpected error loading processes: %s", err)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+//go:build linux
+
+package plib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	environFile = "environ"
+	limitsFile  = "limits"
+	fdDir       = "fd"
+	nsDir       = "ns"
+	netDir      = "net"
+	unixNetFile = "unix"
+	// the prefix of a file descriptor's target when it's a socket, followed by
+	// the socket's inode and a closing bracket.
+	socketTargetPrefix = "socket:["
+)
+
+// the protocols, named after their file in /proc/${PID}/net, that are read for
+// sockets.
+var inetProtocols = []string{"tcp", "tcp6", "udp", "udp6"}
+
+// the tcp states found in /proc/net/tcp, as defined in the kernel's
+// include/net/tcp_states.h.
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// GetEnvironment returns the environment variables of the process with the
+// pid argument. See [GetProcessEnvironment].
+func (l *LinuxInspector) GetEnvironment(pid int) ([]string, error) {
+	env, err := GetProcessEnvironment(l.LinuxConfig.ProcfsFilePath, pid)
+	if err != nil || l.Redactor == nil {
+		return env, err
+	}
+	return l.Redactor.Environment(env), nil
+}
+
+// GetOpenFiles returns the file descriptors the process with the pid argument
+// holds open. See [GetProcessOpenFiles].
+func (l *LinuxInspector) GetOpenFiles(pid int) ([]OpenFile, error) {
+	return GetProcessOpenFiles(l.LinuxConfig.ProcfsFilePath, pid)
+}
+
+// GetSockets returns the sockets the process with the pid argument holds
+// open. See [GetProcessSockets].
+func (l *LinuxInspector) GetSockets(pid int) ([]Socket, error) {
+	return GetProcessSockets(l.LinuxConfig.ProcfsFilePath, pid)
+}
+
+// GetLimits returns the resource limits of the process with the pid argument.
+// See [GetProcessLimits].
+func (l *LinuxInspector) GetLimits(pid int) ([]Limit, error) {
+	return GetProcessLimits(l.LinuxConfig.ProcfsFilePath, pid)
+}
+
+// GetNamespaces returns the namespaces the process with the pid argument
+// belongs to. See [GetProcessNamespaces].
+func (l *LinuxInspector) GetNamespaces(pid int) ([]Namespace, error) {
+	return GetProcessNamespaces(l.LinuxConfig.ProcfsFilePath, pid)
+}
+
+// GetProcessEnvironment returns the environment variables the process was
+// started with by reading /proc/${PID}/environ. Changes the process makes to
+// its environment after starting aren't reflected. Reading another user's
+// process's environment requires elevated permissions.
+func GetProcessEnvironment(procfsFp string, pid int) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(procfsFp, strconv.Itoa(pid), environFile))
+	if err != nil {
+		return nil, err
+	}
+	env := []string{}
+	for _, v := range bytes.Split(data, []byte{0}) {
+		if len(v) > 0 {
+			env = append(env, string(v))
+		}
+	}
+	return env, nil
+}
+
+// GetProcessOpenFiles returns the file descriptors the process holds open by
+// resolving the symlinks in /proc/${PID}/fd. Descriptors closed while they're
+// being read are skipped.
+func GetProcessOpenFiles(procfsFp string, pid int) ([]OpenFile, error) {
+	fdFp := filepath.Join(procfsFp, strconv.Itoa(pid), fdDir)
+	entries, err := os.ReadDir(fdFp)
+	if err != nil {
+		return nil, err
+	}
+	files := []OpenFile{}
+	for _, e := range entries {
+		fd, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(fdFp, e.Name()))
+		if err != nil {
+			continue
+		}
+		files = append(files, OpenFile{FD: fd, Target: target})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].FD < files[j].FD })
+	return files, nil
+}
+
+// GetProcessSockets returns the network and unix sockets the process holds
+// open. The inodes of its socket file descriptors are matched against the
+// sockets listed in /proc/${PID}/net, which lists the sockets of the process's
+// network namespace.
+func GetProcessSockets(procfsFp string, pid int) ([]Socket, error) {
+	inodes, err := getSocketInodes(procfsFp, pid)
+	if err != nil {
+		return nil, err
+	}
+	if len(inodes) == 0 {
+		return []Socket{}, nil
+	}
+	return readSocketTable(procfsFp, pid).match(inodes), nil
+}
+
+// getProcessesSockets returns the sockets of each process in pids whose
+// sockets can be read, keyed by process ID. The socket tables of each network
+// namespace are read once, rather than once per process.
+func (l *LinuxInspector) getProcessesSockets(pids []int) map[int][]Socket {
+	procfsFp := l.LinuxConfig.ProcfsFilePath
+	// the socket tables read, keyed by the network namespace they list.
+	tables := map[string]*socketTable{}
+	result := map[int][]Socket{}
+	for _, pid := range pids {
+		inodes, err := getSocketInodes(procfsFp, pid)
+		if err != nil {
+			continue
+		}
+		if len(inodes) == 0 {
+			result[pid] = []Socket{}
+			continue
+		}
+		// the namespace is identified by its link, e.g. net:[4026531840].
+		// When it can't be read, the process's own tables are used.
+		ns, err := os.Readlink(filepath.Join(procfsFp, strconv.Itoa(pid), nsDir, netDir))
+		if err != nil {
+			ns = strconv.Itoa(pid)
+		}
+		table, ok := tables[ns]
+		if !ok {
+			table = readSocketTable(procfsFp, pid)
+			tables[ns] = table
+		}
+		result[pid] = table.match(inodes)
+	}
+	return result
+}
+
+// getSocketInodes returns the inodes of the sockets the process holds open.
+func getSocketInodes(procfsFp string, pid int) ([]uint64, error) {
+	files, err := GetProcessOpenFiles(procfsFp, pid)
+	if err != nil {
+		return nil, err
+	}
+	inodes := []uint64{}
+	for _, f := range files {
+		if !strings.HasPrefix(f.Target, socketTargetPrefix) {
+			continue
+		}
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(f.Target, socketTargetPrefix), "]"), 10, 64)
+		if err == nil {
+			inodes = append(inodes, inode)
+		}
+	}
+	return inodes, nil
+}
+
+// socketTable is the sockets of a network namespace, as listed in
+// /proc/${PID}/net.
+type socketTable struct {
+	sockets []Socket
+	// the index of each socket in sockets, keyed by its inode.
+	byInode map[uint64]int
+}
+
+// readSocketTable reads the sockets of the network namespace of pid.
+// Protocols that are unavailable, such as tcp6 when IPv6 is disabled, are
+// skipped.
+func readSocketTable(procfsFp string, pid int) *socketTable {
+	netFp := filepath.Join(procfsFp, strconv.Itoa(pid), netDir)
+	t := &socketTable{byInode: map[uint64]int{}}
+	for _, protocol := range inetProtocols {
+		if s, err := readInetSockets(filepath.Join(netFp, protocol), protocol); err == nil {
+			t.sockets = append(t.sockets, s...)
+		}
+	}
+	if s, err := readUnixSockets(filepath.Join(netFp, unixNetFile)); err == nil {
+		t.sockets = append(t.sockets, s...)
+	}
+	for i, s := range t.sockets {
+		t.byInode[s.Inode] = i
+	}
+	return t
+}
+
+// match returns the sockets with inodes, in the order they're listed.
+func (t *socketTable) match(inodes []uint64) []Socket {
+	indexes := []int{}
+	for _, inode := range inodes {
+		if i, ok := t.byInode[inode]; ok {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+	result := []Socket{}
+	for i, index := range indexes {
+		// a socket is listed once, even when held by several descriptors.
+		if i > 0 && index == indexes[i-1] {
+			continue
+		}
+		result = append(result, t.sockets[index])
+	}
+	return result
+}
+
+// readInetSockets parses a socket table, such as /proc/net/tcp, whose lines
+// are in the form:
+//
+//	sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+func readInetSockets(fp string, protocol string) ([]Socket, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sockets := []Socket{}
+	scanner := bufio.NewScanner(f)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		local, err := parseSocketAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		remote, err := parseSocketAddress(fields[2])
+		if err != nil {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			continue
+		}
+		s := Socket{Protocol: protocol, LocalAddress: local, RemoteAddress: remote, Inode: inode}
+		if strings.HasPrefix(protocol, "tcp") {
+			s.State = tcpStates[fields[3]]
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets, scanner.Err()
+}
+
+// readUnixSockets parses /proc/net/unix, whose lines are in the form:
+//
+//	Num RefCount Protocol Flags Type St Inode Path
+func readUnixSockets(fp string) ([]Socket, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sockets := []Socket{}
+	scanner := bufio.NewScanner(f)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 7 {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[6], 10, 64)
+		if err != nil {
+			continue
+		}
+		s := Socket{Protocol: "unix", Inode: inode}
+		if len(fields) > 7 {
+			s.LocalAddress = fields[7]
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets, scanner.Err()
+}
+
+// parseSocketAddress converts an address from a socket table, such as
+// 0100007F:0035, to host:port form. The kernel prints the address as 32-bit
+// words in host byte order, which is assumed to be little-endian.
+func parseSocketAddress(addr string) (string, error) {
+	parts := strings.Split(addr, ":")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid socket address %s", addr)
+	}
+	b, err := hex.DecodeString(parts[0])
+	if err != nil || (len(b) != net.IPv4len && len(b) != net.IPv6len) {
+		return "", fmt.Errorf("invalid socket address %s", addr)
+	}
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid socket port %s", addr)
+	}
+	for i := 0; i < len(b); i += 4 {
+		b[i], b[i+1], b[i+2], b[i+3] = b[i+3], b[i+2], b[i+1], b[i]
+	}
+	return net.JoinHostPort(net.IP(b).String(), strconv.FormatUint(port, 10)), nil
+}
+
+// GetProcessLimits returns the resource limits of the process by reading
+// /proc/${PID}/limits. The file is a table aligned in columns, whose positions
+// are taken from its header, since the names of limits contain spaces.
+func GetProcessLimits(procfsFp string, pid int) ([]Limit, error) {
+	data, err := os.ReadFile(filepath.Join(procfsFp, strconv.Itoa(pid), limitsFile))
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(data), "\n")
+	header := lines[0]
+	soft := strings.Index(header, "Soft Limit")
+	hard := strings.Index(header, "Hard Limit")
+	units := strings.Index(header, "Units")
+	if soft < 0 || hard < soft || units < hard {
+		return nil, fmt.Errorf("failed parsing limits, unexpected header: %s", header)
+	}
+	column := func(line string, start, end int) string {
+		if start > len(line) {
+			return ""
+		}
+		if end > len(line) {
+			end = len(line)
+		}
+		return strings.TrimSpace(line[start:end])
+	}
+	limits := []Limit{}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		limits = append(limits, Limit{
+			Name:  column(line, 0, soft),
+			Soft:  column(line, soft, hard),
+			Hard:  column(line, hard, units),
+			Units: column(line, units, len(line)),
+		})
+	}
+	return limits, nil
+}
+
+// GetProcessNamespaces returns the namespaces the process belongs to by
+// reading the links in /proc/${PID}/ns, which take the form type:[inode].
+// Reading another user's process's namespaces requires elevated permissions.
+func GetProcessNamespaces(procfsFp string, pid int) ([]Namespace, error) {
+	dir := filepath.Join(procfsFp, strconv.Itoa(pid), nsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := []Namespace{}
+	for _, e := range entries {
+		target, err := os.Readlink(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		_, inode, ok := strings.Cut(target, ":[")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSuffix(inode, "]"), 10, 64)
+		if err != nil {
+			continue
+		}
+		namespaces = append(namespaces, Namespace{Type: e.Name(), Inode: n})
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Type < namespaces[j].Type })
+	return namespaces, nil
+}
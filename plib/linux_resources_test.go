@@ -0,0 +1,177 @@
+//go:build linux
+
+package plib
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+const (
+	testTCPTable = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0035 00000000:0000 0A 00000000:00000000 00:00000000 00000000   101        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000  1000        0 1002 1 0000000000000000 20 4 30 10 -1
+`
+	testTCP6Table = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1003 1 0000000000000000 100 0 0 10 0
+`
+	testUnixTable = `Num       RefCount Protocol Flags    Type St Inode Path
+0000000000000000: 00000002 00000000 00010000 0001 01 1004 /run/proctor.sock
+0000000000000000: 00000003 00000000 00000000 0001 03 1005
+`
+	testLimits = `Limit                     Soft Limit           Hard Limit           Units     
+Max cpu time              unlimited            unlimited            seconds   
+Max processes             63704                63704                processes 
+Max open files            1024                 524288               files     
+Max nice priority         0                    0                    
+`
+)
+
+func TestGetProcessResources(t *testing.T) {
+	procFp := t.TempDir()
+	pidFp := filepath.Join(procFp, "42")
+	for _, dir := range []string{fdDir, netDir, nsDir} {
+		if err := os.MkdirAll(filepath.Join(pidFp, dir), DefaultFilePerms); err != nil {
+			t.Fatalf("failed setting up sample data for test: %s", err)
+		}
+	}
+	files := map[string]string{
+		environFile:                   "HOME=/root\x00PATH=/usr/bin\x00",
+		cmdDir:                        "/usr/bin/psql\x00--host\x00db\x00--password\x00hunter2\x00",
+		limitsFile:                    testLimits,
+		filepath.Join(netDir, "tcp"):  testTCPTable,
+		filepath.Join(netDir, "tcp6"): testTCP6Table,
+		filepath.Join(netDir, "unix"): testUnixTable,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(pidFp, name), []byte(content), DefaultFilePerms); err != nil {
+			t.Fatalf("failed setting up sample data for test: %s", err)
+		}
+	}
+	links := map[string]string{
+		"0":  "/dev/null",
+		"3":  "socket:[1002]",
+		"10": "socket:[1003]",
+		"4":  "socket:[1004]",
+		"5":  "pipe:[2001]",
+	}
+	namespaces := map[string]string{
+		"net": "net:[4026531840]",
+		"mnt": "mnt:[4026531832]",
+	}
+	for fd, target := range links {
+		if err := os.Symlink(target, filepath.Join(pidFp, fdDir, fd)); err != nil {
+			t.Fatalf("failed setting up sample data for test: %s", err)
+		}
+	}
+
+	for ns, target := range namespaces {
+		if err := os.Symlink(target, filepath.Join(pidFp, nsDir, ns)); err != nil {
+			t.Fatalf("failed setting up sample data for test: %s", err)
+		}
+	}
+
+	env, err := GetProcessEnvironment(procFp, 42)
+	if err != nil {
+		t.Fatalf("failed getting environment: %s", err)
+	}
+	if len(env) != 2 || env[0] != "HOME=/root" || env[1] != "PATH=/usr/bin" {
+		t.Logf("fail: unexpected environment: %v", env)
+		t.Fail()
+	}
+
+	args, err := GetProcessArgs(procFp, 42)
+	if err != nil || args != "--host db --password hunter2" {
+		t.Logf("fail: unexpected args: %q, %v", args, err)
+		t.Fail()
+	}
+
+	// an inspector with a redactor masks secrets in environments.
+	os.WriteFile(filepath.Join(pidFp, environFile), []byte("HOME=/root\x00DB_PASSWORD=hunter2\x00"), DefaultFilePerms)
+	li := &LinuxInspector{InspectorConfig: InspectorConfig{LinuxConfig: LinuxInspectorConfig{ProcfsFilePath: procFp}, Redactor: DefaultRedactor}}
+	if env, err := li.GetEnvironment(42); err != nil || len(env) != 2 || env[1] != "DB_PASSWORD="+RedactedValue {
+		t.Logf("fail: expected the password to be redacted, actual: %v, %v", env, err)
+		t.Fail()
+	}
+
+	openFiles, err := GetProcessOpenFiles(procFp, 42)
+	if err != nil {
+		t.Fatalf("failed getting open files: %s", err)
+	}
+	expectedFDs := []int{0, 3, 4, 5, 10}
+	if len(openFiles) != len(expectedFDs) {
+		t.Fatalf("fail: expected %d open files, actual: %v", len(expectedFDs), openFiles)
+	}
+	for i, fd := range expectedFDs {
+		if openFiles[i].FD != fd || openFiles[i].Target != links[strconv.Itoa(fd)] {
+			t.Logf("fail: expected fd %d at index %d, actual: %v", fd, i, openFiles)
+			t.Fail()
+		}
+	}
+
+	sockets, err := GetProcessSockets(procFp, 42)
+	if err != nil {
+		t.Fatalf("failed getting sockets: %s", err)
+	}
+	expected := []Socket{
+		{Protocol: "tcp", LocalAddress: "127.0.0.1:8080", RemoteAddress: "127.0.0.1:50000", State: "ESTABLISHED", Inode: 1002},
+		{Protocol: "tcp6", LocalAddress: "[::1]:8080", RemoteAddress: "[::]:0", State: "LISTEN", Inode: 1003},
+		{Protocol: "unix", LocalAddress: "/run/proctor.sock", Inode: 1004},
+	}
+	if len(sockets) != len(expected) {
+		t.Fatalf("fail: expected %d sockets, actual: %v", len(expected), sockets)
+	}
+	for i := range expected {
+		if sockets[i] != expected[i] {
+			t.Logf("fail: expected socket: %+v, actual: %+v", expected[i], sockets[i])
+			t.Fail()
+		}
+	}
+
+	// a process in the same network namespace uses the tables already read,
+	// so its own aren't needed.
+	otherFp := filepath.Join(procFp, "43")
+	for _, dir := range []string{fdDir, nsDir} {
+		if err := os.MkdirAll(filepath.Join(otherFp, dir), DefaultFilePerms); err != nil {
+			t.Fatalf("failed setting up sample data for test: %s", err)
+		}
+	}
+	os.Symlink("socket:[1001]", filepath.Join(otherFp, fdDir, "3"))
+	os.Symlink(namespaces["net"], filepath.Join(otherFp, nsDir, "net"))
+	byPID := li.getProcessesSockets([]int{42, 43})
+	if len(byPID[42]) != len(expected) || len(byPID[43]) != 1 || byPID[43][0].Inode != 1001 {
+		t.Logf("fail: expected the sockets of both processes from the shared tables, actual: %+v", byPID)
+		t.Fail()
+	}
+
+	limits, err := GetProcessLimits(procFp, 42)
+	if err != nil {
+		t.Fatalf("failed getting limits: %s", err)
+	}
+	expectedLimits := []Limit{
+		{Name: "Max cpu time", Soft: "unlimited", Hard: "unlimited", Units: "seconds"},
+		{Name: "Max processes", Soft: "63704", Hard: "63704", Units: "processes"},
+		{Name: "Max open files", Soft: "1024", Hard: "524288", Units: "files"},
+		{Name: "Max nice priority", Soft: "0", Hard: "0"},
+	}
+	if len(limits) != len(expectedLimits) {
+		t.Fatalf("fail: expected %d limits, actual: %v", len(expectedLimits), limits)
+	}
+	for i := range expectedLimits {
+		if limits[i] != expectedLimits[i] {
+			t.Logf("fail: expected limit: %+v, actual: %+v", expectedLimits[i], limits[i])
+			t.Fail()
+		}
+	}
+
+	ns, err := GetProcessNamespaces(procFp, 42)
+	if err != nil {
+		t.Fatalf("failed getting namespaces: %s", err)
+	}
+	if len(ns) != 2 || ns[0] != (Namespace{Type: "mnt", Inode: 4026531832}) || ns[1] != (Namespace{Type: "net", Inode: 4026531840}) {
+		t.Logf("fail: unexpected namespaces: %v", ns)
+		t.Fail()
+	}
+}
@@ -1,3 +1,5 @@
+//go:build linux
+
 package plib
 
 import (
@@ -491,3 +493,43 @@ func cleanTestData() {
 		}
 	}
 }
+
+func TestHashFile(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "binary")
+	if err := os.WriteFile(fp, []byte("1"), 0644); err != nil {
+		t.Fatalf("fail: failed writing test file: %s", err)
+	}
+	expected := "6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b"
+	sha, err := HashFile(fp)
+	if err != nil || sha != expected {
+		t.Logf("fail: hash was wrong, expected: %s, actual: %s, error: %v", expected, sha, err)
+		t.Fail()
+	}
+	if NewSHAFromProcess(fp) != expected {
+		t.Logf("fail: expected NewSHAFromProcess to match HashFile")
+		t.Fail()
+	}
+	if _, err := HashFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Logf("fail: expected an error hashing a missing file")
+		t.Fail()
+	}
+}
+
+func TestGetProcessUID(t *testing.T) {
+	procFp := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(procFp, "42"), DefaultFilePerms); err != nil {
+		t.Fatalf("failed setting up sample data for test: %s", err)
+	}
+	status := "Name:\tbash\nUmask:\t0022\nState:\tS (sleeping)\nUid:\t1000\t1001\t1001\t1001\nGid:\t1000\t1000\t1000\t1000\n"
+	if err := os.WriteFile(filepath.Join(procFp, "42", statusFile), []byte(status), DefaultFilePerms); err != nil {
+		t.Fatalf("failed setting up sample data for test: %s", err)
+	}
+	if uid := getProcessUID(procFp, 42); uid != 1000 {
+		t.Logf("fail: expected the real uid 1000, actual: %d", uid)
+		t.Fail()
+	}
+	if uid := getProcessUID(procFp, 43); uid != -1 {
+		t.Logf("fail: expected -1 for a missing process, actual: %d", uid)
+		t.Fail()
+	}
+}
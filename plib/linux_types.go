@@ -278,4 +278,9 @@ type ProcessStat struct {
 	// The exit code that is reported to the parent process based on this process
 	// ending. Only relevant if a process has exited.
 	ExitCode int
+	// The real user ID of the user that owns the process, or -1 when it
+	// couldn't be read. Unlike the fields above, it's read from
+	// /proc/${PID}/status.
+	// Also known as Uid.
+	UID int
 }
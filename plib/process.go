@@ -3,9 +3,10 @@
 package plib
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
-	"runtime"
 	"time"
 
 	"github.com/adrg/xdg"
@@ -20,6 +21,11 @@ const (
 	CacheFileName = "proc.cache"
 )
 
+// ErrUnsupported is returned, wrapped, by functions that can't inspect
+// processes on the host's operating system. Today, only Linux is supported.
+// Check for it with errors.Is.
+var ErrUnsupported = errors.ErrUnsupported
+
 // Process is an operating system's representation of execution. Details
 // available about processes vary between operating systems. As such, Process
 // contains multiple common fields that are resolvable in most operating
@@ -39,7 +45,8 @@ type Process struct {
 	// The name of the command that was triggered for execution.
 	CommandName string
 	// The full path of the command (binary) that was run.
-	CommandPath  string
+	CommandPath string
+	// The flags and arguments the command was run with, separated by spaces.
 	FlagsAndArgs string
 	// The parent process's numeric identifier. The parent is typically the
 	// process which kicked off this process.
@@ -62,6 +69,44 @@ type Process struct {
 	// process orginigated from to inform casting this interface to a concrete
 	// struct type.
 	OSSpecific any
+	// Details added by enricher plugins, such as the team owning the process
+	// in a CMDB, keyed by name. Empty unless enrichers were run.
+	Annotations map[string]string `json:",omitempty"`
+}
+
+// UnmarshalJSON decodes a Process encoded as JSON. The OSSpecific field is
+// decoded into the type matching the Type field, such as [ProcessStat] for
+// Linux processes, rather than a map.
+func (p *Process) UnmarshalJSON(data []byte) error {
+	// process has the fields of Process, without its methods, so decoding
+	// into it doesn't recurse.
+	type process Process
+	var raw struct {
+		process
+		OSSpecific json.RawMessage
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = Process(raw.process)
+	if len(raw.OSSpecific) == 0 || string(raw.OSSpecific) == "null" {
+		return nil
+	}
+	switch p.Type {
+	case linuxProcessType:
+		var stat ProcessStat
+		if err := json.Unmarshal(raw.OSSpecific, &stat); err != nil {
+			return fmt.Errorf("failed decoding linux process details: %s", err)
+		}
+		p.OSSpecific = stat
+	default:
+		var v any
+		if err := json.Unmarshal(raw.OSSpecific, &v); err != nil {
+			return err
+		}
+		p.OSSpecific = v
+	}
+	return nil
 }
 
 // Processes is a map of Process pointers where the key is the ID of each
@@ -117,31 +162,22 @@ type InspectorConfig struct {
 	// behavior. This field is only respected when creating an inspector to
 	// operate on Linux.
 	LinuxConfig LinuxInspectorConfig
+	// Masks secrets in the processes loaded, before they're cached, and in
+	// the environments returned by [ResourceInspector.GetEnvironment]. By
+	// default, nothing is masked.
+	Redactor *Redactor
 }
 
 // NewInspector returns an Inspector instance based on the host's operating
-// system. If the host's operating system cannot be detected or the operating
-// system is unsupported, an error is returned.
+// system. If the host's operating system is unsupported, an error wrapping
+// [ErrUnsupported] is returned.
 //
 // An [InspectorConfig] can be optionally passed if you'd like to change the
 // defaults. Note that while NewInspector accepts multiple opts arguments, it
 // is recommended you only pass one. If you pass more than one, the last opts
 // instance in the list will be used.
 func NewInspector(opts ...InspectorConfig) (Inspector, error) {
-	switch runtime.GOOS {
-	// TODO(joshrosso): Other target architectures
-	case "linux":
-		insp, err := newLinuxInspector(opts...)
-		if err != nil {
-			return nil, err
-		}
-		return insp, nil
-	}
-
-	return nil, fmt.Errorf(
-		"failed to create inspector because operating system %s is unsupported\n",
-		runtime.GOOS,
-	)
+	return newInspector(opts...)
 }
 
 // GetDefaultCacheLocation returns the location where process details can be
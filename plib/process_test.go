@@ -0,0 +1,32 @@
+package plib
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestProcessJSONRoundTrip(t *testing.T) {
+	p := Process{ID: 42, CommandName: "containerd", Type: linuxProcessType, OSSpecific: ProcessStat{State: "S", ThreadQuantity: 12}}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("failed encoding process: %s", err)
+	}
+	var decoded Process
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed decoding process: %s", err)
+	}
+	stat, ok := decoded.OSSpecific.(ProcessStat)
+	if decoded.ID != 42 || decoded.CommandName != "containerd" || !ok || stat.State != "S" || stat.ThreadQuantity != 12 {
+		t.Logf("fail: process did not round trip, actual: %+v", decoded)
+		t.Fail()
+	}
+
+	// details of unknown operating systems are kept as decoded by default.
+	if err := json.Unmarshal([]byte(`{"ID": 7, "Type": "plan9", "OSSpecific": {"Note": "x"}}`), &decoded); err != nil {
+		t.Fatalf("failed decoding process: %s", err)
+	}
+	if m, ok := decoded.OSSpecific.(map[string]any); decoded.ID != 7 || !ok || m["Note"] != "x" {
+		t.Logf("fail: unexpected process: %+v", decoded)
+		t.Fail()
+	}
+}
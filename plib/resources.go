@@ -0,0 +1,149 @@
+package plib
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// OpenFile is a file descriptor a process holds open.
+type OpenFile struct {
+	// The file descriptor's number.
+	FD int
+	// What the descriptor refers to. For regular files this is a path, for
+	// other types it's a description such as socket:[1234] or pipe:[5678].
+	Target string
+}
+
+// Socket is a network or unix socket a process holds open.
+type Socket struct {
+	// The socket's protocol: tcp, tcp6, udp, udp6, or unix.
+	Protocol string
+	// The local and remote addresses in host:port form. For unix sockets,
+	// LocalAddress is the socket's path, if it's bound to one.
+	LocalAddress  string
+	RemoteAddress string
+	// The connection state, such as LISTEN or ESTABLISHED, for tcp sockets.
+	State string
+	// The socket's inode, which relates it to the process's file descriptors.
+	Inode uint64
+}
+
+// IsListening returns whether s accepts connections or datagrams from any
+// peer: tcp sockets in the LISTEN state and udp sockets without a remote
+// address.
+func (s Socket) IsListening() bool {
+	switch {
+	case strings.HasPrefix(s.Protocol, "tcp"):
+		return s.State == "LISTEN"
+	case strings.HasPrefix(s.Protocol, "udp"):
+		return !s.hasRemote()
+	}
+	return false
+}
+
+// IsEstablished returns whether s is connected to a peer: tcp sockets in the
+// ESTABLISHED state and udp sockets with a remote address.
+func (s Socket) IsEstablished() bool {
+	switch {
+	case strings.HasPrefix(s.Protocol, "tcp"):
+		return s.State == "ESTABLISHED"
+	case strings.HasPrefix(s.Protocol, "udp"):
+		return s.hasRemote()
+	}
+	return false
+}
+
+// hasRemote returns whether the remote address of s has a port, which
+// unconnected sockets lack.
+func (s Socket) hasRemote() bool {
+	_, port, err := net.SplitHostPort(s.RemoteAddress)
+	return err == nil && port != "0"
+}
+
+// ProcessSocket is a socket and the process holding it open.
+type ProcessSocket struct {
+	PID         int
+	CommandName string
+	Socket
+}
+
+// batchSocketInspector is implemented by resource inspectors that retrieve
+// the sockets of many processes more efficiently than one at a time.
+type batchSocketInspector interface {
+	// getProcessesSockets returns the sockets of each process in ids whose
+	// sockets can be retrieved, keyed by process ID.
+	getProcessesSockets(ids []int) map[int][]Socket
+}
+
+// GetProcessesSockets returns the sockets held open by each process in ps,
+// ordered by process ID. Processes whose sockets can't be retrieved, such as
+// those the caller lacks permission to inspect, are skipped.
+func GetProcessesSockets(ri ResourceInspector, ps Processes) []ProcessSocket {
+	ids := make([]int, 0, len(ps))
+	for id := range ps {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var socketsByID map[int][]Socket
+	if b, ok := ri.(batchSocketInspector); ok {
+		socketsByID = b.getProcessesSockets(ids)
+	} else {
+		socketsByID = map[int][]Socket{}
+		for _, id := range ids {
+			if sockets, err := ri.GetSockets(id); err == nil {
+				socketsByID[id] = sockets
+			}
+		}
+	}
+
+	result := []ProcessSocket{}
+	for _, id := range ids {
+		for _, s := range socketsByID[id] {
+			result = append(result, ProcessSocket{PID: id, CommandName: ps[id].CommandName, Socket: s})
+		}
+	}
+	return result
+}
+
+// Limit is a resource limit of a process, as set with setrlimit.
+type Limit struct {
+	// The resource limited, such as "Max open files".
+	Name string
+	// The soft limit, which the kernel enforces, and the hard limit, which the
+	// soft limit can be raised to. Either may be "unlimited".
+	Soft string
+	Hard string
+	// The unit the limits are measured in, such as bytes. Empty for limits
+	// that are a count, such as of processes.
+	Units string
+}
+
+// Namespace is a Linux namespace a process belongs to. Processes with the
+// same inode for a type of namespace share that namespace.
+type Namespace struct {
+	// The namespace's type, such as mnt, net, or pid.
+	Type  string
+	Inode uint64
+}
+
+// ResourceInspector is implemented by an [Inspector] able to retrieve the
+// resources a process holds, which aren't cached with its other details since
+// they change frequently. Callers should type assert an Inspector to check
+// whether it's supported.
+type ResourceInspector interface {
+	// GetEnvironment returns the environment variables, in KEY=value form, the
+	// process was started with.
+	GetEnvironment(pid int) ([]string, error)
+	// GetOpenFiles returns the file descriptors the process holds open,
+	// ordered by descriptor.
+	GetOpenFiles(pid int) ([]OpenFile, error)
+	// GetSockets returns the network and unix sockets the process holds open.
+	GetSockets(pid int) ([]Socket, error)
+	// GetLimits returns the resource limits of the process.
+	GetLimits(pid int) ([]Limit, error)
+	// GetNamespaces returns the namespaces the process belongs to, ordered by
+	// type.
+	GetNamespaces(pid int) ([]Namespace, error)
+}
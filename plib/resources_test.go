@@ -0,0 +1,68 @@
+package plib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSocketState(t *testing.T) {
+	tests := []struct {
+		socket      Socket
+		listening   bool
+		established bool
+	}{
+		{Socket{Protocol: "tcp", LocalAddress: "0.0.0.0:22", RemoteAddress: "0.0.0.0:0", State: "LISTEN"}, true, false},
+		{Socket{Protocol: "tcp6", LocalAddress: "[::1]:22", RemoteAddress: "[::1]:51000", State: "ESTABLISHED"}, false, true},
+		{Socket{Protocol: "tcp", LocalAddress: "10.0.0.2:51000", RemoteAddress: "10.0.0.1:443", State: "TIME_WAIT"}, false, false},
+		{Socket{Protocol: "udp", LocalAddress: "0.0.0.0:53", RemoteAddress: "0.0.0.0:0"}, true, false},
+		{Socket{Protocol: "udp", LocalAddress: "10.0.0.2:40000", RemoteAddress: "10.0.0.1:53"}, false, true},
+		{Socket{Protocol: "unix", LocalAddress: "/run/docker.sock"}, false, false},
+	}
+	for _, test := range tests {
+		if test.socket.IsListening() != test.listening || test.socket.IsEstablished() != test.established {
+			t.Logf("fail: expected %+v listening: %t, established: %t", test.socket, test.listening, test.established)
+			t.Fail()
+		}
+	}
+}
+
+// socketsInspector returns fixed sockets for each process, failing for
+// processes without any.
+type socketsInspector struct {
+	ResourceInspector
+	sockets map[int][]Socket
+}
+
+func (s socketsInspector) GetSockets(pid int) ([]Socket, error) {
+	if s.sockets[pid] == nil {
+		return nil, fmt.Errorf("permission denied")
+	}
+	return s.sockets[pid], nil
+}
+
+func TestGetProcessesSockets(t *testing.T) {
+	ps := Processes{
+		300: {ID: 300, CommandName: "nginx"},
+		20:  {ID: 20, CommandName: "sshd"},
+		1:   {ID: 1, CommandName: "systemd"},
+	}
+	ri := socketsInspector{sockets: map[int][]Socket{
+		20:  {{Protocol: "tcp", LocalAddress: "0.0.0.0:22", State: "LISTEN"}},
+		300: {{Protocol: "tcp", LocalAddress: "0.0.0.0:80", State: "LISTEN"}, {Protocol: "tcp", LocalAddress: "0.0.0.0:443", State: "LISTEN"}},
+	}}
+	actual := GetProcessesSockets(ri, ps)
+	expected := []ProcessSocket{
+		{PID: 20, CommandName: "sshd", Socket: Socket{Protocol: "tcp", LocalAddress: "0.0.0.0:22", State: "LISTEN"}},
+		{PID: 300, CommandName: "nginx", Socket: Socket{Protocol: "tcp", LocalAddress: "0.0.0.0:80", State: "LISTEN"}},
+		{PID: 300, CommandName: "nginx", Socket: Socket{Protocol: "tcp", LocalAddress: "0.0.0.0:443", State: "LISTEN"}},
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("fail: expected %v, actual: %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Logf("fail: expected %v, actual: %v", expected[i], actual[i])
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package plib
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// the signals that can be sent with [SignalProcess] and the operating
+// system's signal each corresponds to.
+var sendableSignals = map[Signal]syscall.Signal{
+	SIGHUP:  syscall.SIGHUP,
+	SIGINT:  syscall.SIGINT,
+	SIGTERM: syscall.SIGTERM,
+	SIGKILL: syscall.SIGKILL,
+}
+
+// the names of the signals that can be sent with [SignalProcess].
+var signalNames = map[Signal]string{
+	SIGHUP:  "SIGHUP",
+	SIGINT:  "SIGINT",
+	SIGTERM: "SIGTERM",
+	SIGKILL: "SIGKILL",
+}
+
+// ParseSignal returns the Signal named name, such as SIGTERM, which can be
+// sent with [SignalProcess]. An error is returned for any other name.
+func ParseSignal(name string) (Signal, error) {
+	for sig, n := range signalNames {
+		if n == name {
+			return sig, nil
+		}
+	}
+	return 0, fmt.Errorf("signal (%s) is not supported, expected one of SIGHUP, SIGINT, SIGTERM, or SIGKILL", name)
+}
+
+// SignalName returns the name of sig, such as SIGTERM, when it can be sent
+// with [SignalProcess], otherwise its number.
+func SignalName(sig Signal) string {
+	if name, ok := signalNames[sig]; ok {
+		return name
+	}
+	return fmt.Sprintf("signal %d", int(sig))
+}
+
+// SignalProcess sends sig to the process with the pid argument. Only SIGHUP,
+// SIGINT, SIGTERM, and SIGKILL are supported. An error is returned when the
+// process doesn't exist or the user lacks permission to signal it.
+func SignalProcess(pid int, sig Signal) error {
+	s, ok := sendableSignals[sig]
+	if !ok {
+		return fmt.Errorf("sending %s is not supported", SignalName(sig))
+	}
+	// windows can only terminate processes, not signal them.
+	if runtime.GOOS == "windows" && sig != SIGKILL {
+		return fmt.Errorf("failed sending %s to process %d: %w", SignalName(sig), pid, ErrUnsupported)
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed finding process %d: %s", pid, err)
+	}
+	if err := p.Signal(s); err != nil {
+		return fmt.Errorf("failed sending %s to process %d: %s", SignalName(sig), pid, err)
+	}
+	return nil
+}
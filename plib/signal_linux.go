@@ -0,0 +1,46 @@
+//go:build linux
+
+package plib
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// SignalVerifiedProcess sends sig to the process with the pid argument, as
+// [SignalProcess] does, only when the process still runs the binary whose
+// SHA256 is sha. The process is held by a pidfd while its binary is hashed,
+// so the signal can never reach another process that reused the pid in the
+// meantime. An error is returned when the binary doesn't match or the kernel
+// doesn't support pidfds (Linux 5.3 and later do).
+func SignalVerifiedProcess(pid int, sig Signal, sha string) error {
+	s, ok := sendableSignals[sig]
+	if !ok {
+		return fmt.Errorf("sending %s is not supported", SignalName(sig))
+	}
+	fd, err := unix.PidfdOpen(pid, 0)
+	if err != nil {
+		if errors.Is(err, unix.ENOSYS) {
+			return fmt.Errorf("failed opening process %d, verifying a process before signaling it requires Linux 5.3 or later: %w", pid, ErrUnsupported)
+		}
+		return fmt.Errorf("failed opening process %d: %s", pid, err)
+	}
+	defer unix.Close(fd)
+	// the exe link is the binary the process is running, even when the file
+	// at its path was since replaced.
+	actual, err := HashFile(filepath.Join(defaultProcDir, strconv.Itoa(pid), exeDir))
+	if err != nil {
+		return fmt.Errorf("failed verifying the binary of process %d: %s", pid, err)
+	}
+	if actual != sha {
+		return fmt.Errorf("refusing to send %s to process %d, its binary SHA changed to %s", SignalName(sig), pid, actual)
+	}
+	if err := unix.PidfdSendSignal(fd, s, nil, 0); err != nil {
+		return fmt.Errorf("failed sending %s to process %d: %s", SignalName(sig), pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build linux
+
+package plib
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestSignalVerifiedProcess(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("unable to start a process to signal: %s", err)
+	}
+	defer cmd.Process.Kill()
+	sha, err := HashFile(filepath.Join(defaultProcDir, strconv.Itoa(cmd.Process.Pid), exeDir))
+	if err != nil {
+		t.Fatalf("fail: unexpected error hashing the process's binary: %s", err)
+	}
+
+	if err := SignalVerifiedProcess(cmd.Process.Pid, SIGTERM, "aa"); err == nil {
+		t.Fatal("fail: expected an error signaling a process running another binary")
+	}
+	if err := SignalVerifiedProcess(cmd.Process.Pid, SIGTERM, sha); err != nil {
+		t.Fatalf("fail: unexpected error signaling process: %s", err)
+	}
+	if err := cmd.Wait(); err == nil || cmd.ProcessState.String() != "signal: terminated" {
+		t.Logf("fail: expected the process to be terminated, actual: %s", cmd.ProcessState)
+		t.Fail()
+	}
+}
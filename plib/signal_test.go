@@ -0,0 +1,33 @@
+package plib
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestSignalProcess(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("unable to start a process to signal: %s", err)
+	}
+	sig, err := ParseSignal("SIGTERM")
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing SIGTERM: %s", err)
+	}
+	if err := SignalProcess(cmd.Process.Pid, sig); err != nil {
+		t.Fatalf("fail: unexpected error signaling process: %s", err)
+	}
+	if err := cmd.Wait(); err == nil || cmd.ProcessState.String() != "signal: terminated" {
+		t.Logf("fail: expected the process to be terminated, actual: %s", cmd.ProcessState)
+		t.Fail()
+	}
+
+	if _, err := ParseSignal("SIGSEGV"); err == nil {
+		t.Logf("fail: expected an error parsing an unsupported signal")
+		t.Fail()
+	}
+	if err := SignalProcess(cmd.Process.Pid, SIGCHLD); err == nil {
+		t.Logf("fail: expected an error sending an unsupported signal")
+		t.Fail()
+	}
+}
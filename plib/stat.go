@@ -0,0 +1,190 @@
+package plib
+
+import (
+	"fmt"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// StatField is a field of a process's stat file (/proc/${PID}/stat), named and
+// converted so it's readable without consulting proc(5).
+type StatField struct {
+	// The field's position in the stat file, starting at 1 as in proc(5).
+	Number int
+	// The field's name in proc(5), such as utime.
+	Name string
+	// The name of the [ProcessStat] field holding the value, such as
+	// UserModeTime.
+	Field string
+	// The value as parsed into ProcessStat.
+	Raw string
+	// The value converted into readable units, such as a duration for times
+	// measured in clock ticks.
+	Value string
+}
+
+// The names of the scheduling policies reported in the policy field, as
+// defined in sched.h.
+var schedulingPolicies = map[int]string{
+	0: "SCHED_NORMAL",
+	1: "SCHED_FIFO",
+	2: "SCHED_RR",
+	3: "SCHED_BATCH",
+	5: "SCHED_IDLE",
+	6: "SCHED_DEADLINE",
+}
+
+// The descriptions of the states reported in the state field.
+var processStates = map[string]string{
+	"R": "running",
+	"S": "sleeping",
+	"D": "uninterruptible sleep",
+	"Z": "zombie",
+	"T": "stopped",
+	"t": "tracing stop",
+	"X": "dead",
+	"x": "dead",
+	"K": "wakekill",
+	"W": "waking",
+	"P": "parked",
+	"I": "idle",
+}
+
+// StatFields returns all 52 fields of s, in the order of the stat file, with
+// values converted into readable units: clock ticks into durations, sizes and
+// page counts into bytes, addresses and signal masks into hexadecimal, and
+// codes, such as the scheduling policy, into their names. pageSize is the size
+// of a memory page in bytes, as returned by os.Getpagesize, used to convert
+// the resident set size.
+func (s ProcessStat) StatFields(pageSize int) []StatField {
+	fields := []StatField{}
+	add := func(name, field string, raw interface{}, value string) {
+		fields = append(fields, StatField{
+			Number: len(fields) + 1,
+			Name:   name,
+			Field:  field,
+			Raw:    fmt.Sprint(raw),
+			Value:  value,
+		})
+	}
+	plain := func(name, field string, v int) { add(name, field, v, strconv.Itoa(v)) }
+	ticks := func(name, field string, v int) { add(name, field, v, ticksToDuration(v).String()) }
+	hex := func(name, field string, v int) { add(name, field, v, fmt.Sprintf("0x%x", v)) }
+	address := func(name, field string, v string) { add(name, field, v, v) }
+
+	plain("pid", "ID", s.ID)
+	add("comm", "FileName", s.FileName, s.FileName)
+	add("state", "State", s.State, describe(s.State, processStates))
+	plain("ppid", "ParentID", s.ParentID)
+	plain("pgrp", "ProcessGroup", s.ProcessGroup)
+	plain("session", "SessionID", s.SessionID)
+	add("tty_nr", "TTY", s.TTY, formatTTY(s.TTY))
+	plain("tpgid", "TTYProcessGroup", s.TTYProcessGroup)
+	add("flags", "TaskFlags", s.TaskFlags, formatFlags(s.TaskFlags))
+	plain("minflt", "MinorFaultQuantity", s.MinorFaultQuantity)
+	plain("cminflt", "MinorFaultWithChildQuantity", s.MinorFaultWithChildQuantity)
+	plain("majflt", "MajorFaultQuantity", s.MajorFaultQuantity)
+	plain("cmajflt", "MajorFaultWithChildQuantity", s.MajorFaultWithChildQuantity)
+	ticks("utime", "UserModeTime", s.UserModeTime)
+	ticks("stime", "KernalTime", s.KernalTime)
+	ticks("cutime", "UserModeTimeWithChild", s.UserModeTimeWithChild)
+	ticks("cstime", "KernalTimeWithChild", s.KernalTimeWithChild)
+	plain("priority", "Priority", s.Priority)
+	plain("nice", "Nice", s.Nice)
+	plain("num_threads", "ThreadQuantity", s.ThreadQuantity)
+	plain("itrealvalue", "ItRealValue", s.ItRealValue)
+	add("starttime", "StartTime", s.StartTime, ticksToDuration(s.StartTime).String()+" after boot")
+	add("vsize", "VirtualMemSize", s.VirtualMemSize, formatStatBytes(s.VirtualMemSize))
+	add("rss", "ResidentSetMemSize", s.ResidentSetMemSize, fmt.Sprintf("%d pages, %s", s.ResidentSetMemSize, formatStatBytes(s.ResidentSetMemSize*pageSize)))
+	add("rsslim", "RSSByteLimit", s.RSSByteLimit, formatStatBytes(s.RSSByteLimit))
+	address("startcode", "StartCode", s.StartCode)
+	address("endcode", "EndCode", s.EndCode)
+	address("startstack", "StartStack", s.StartStack)
+	hex("kstkesp", "ExtendedStackPointerAddress", s.ExtendedStackPointerAddress)
+	hex("kstkeip", "ExtendedInstructionPointer", s.ExtendedInstructionPointer)
+	hex("signal", "SignalPendingQuantity", s.SignalPendingQuantity)
+	hex("blocked", "SignalsBlockedQuantity", s.SignalsBlockedQuantity)
+	hex("sigignore", "SignalsIgnoredQuantity", s.SignalsIgnoredQuantity)
+	hex("sigcatch", "SiganlsCaughtQuantity", s.SiganlsCaughtQuantity)
+	plain("wchan", "PlaceHolder1", s.PlaceHolder1)
+	plain("nswap", "PlaceHolder2", s.PlaceHolder2)
+	plain("cnswap", "PlaceHolder3", s.PlaceHolder3)
+	add("exit_signal", "ExitSignal", int(s.ExitSignal), formatSignal(s.ExitSignal))
+	plain("processor", "CPU", s.CPU)
+	plain("rt_priority", "RealtimePriority", s.RealtimePriority)
+	add("policy", "SchedulingPolicy", s.SchedulingPolicy, describe(s.SchedulingPolicy, schedulingPolicies))
+	ticks("delayacct_blkio_ticks", "TimeSpentOnBlockIO", s.TimeSpentOnBlockIO)
+	ticks("guest_time", "GuestTime", s.GuestTime)
+	ticks("cguest_time", "GuestTimeWithChild", s.GuestTimeWithChild)
+	address("start_data", "StartDataAddress", s.StartDataAddress)
+	address("end_data", "EndDataAddress", s.EndDataAddress)
+	address("start_brk", "HeapExpansionAddress", s.HeapExpansionAddress)
+	address("arg_start", "StartCMDAddress", s.StartCMDAddress)
+	address("arg_end", "EndCMDAddress", s.EndCMDAddress)
+	address("env_start", "StartEnvAddress", s.StartEnvAddress)
+	address("env_end", "EndEnvAddress", s.EndEnvAddress)
+	plain("exit_code", "ExitCode", s.ExitCode)
+	return fields
+}
+
+// ticksToDuration converts a time measured in clock ticks into a duration.
+func ticksToDuration(ticks int) time.Duration {
+	return time.Duration(ticks) * time.Second / clockTicksPerSecond
+}
+
+// describe returns v followed by its description in descriptions, or v alone
+// when it has none.
+func describe[K comparable](v K, descriptions map[K]string) string {
+	if d, ok := descriptions[v]; ok {
+		return fmt.Sprintf("%v (%s)", v, d)
+	}
+	return fmt.Sprint(v)
+}
+
+// formatTTY returns the device numbers of the controlling terminal encoded in
+// tty, or none when the process has no controlling terminal.
+func formatTTY(tty int) string {
+	if tty == 0 {
+		return "none"
+	}
+	// the minor number is held in bits 31 to 20 and 7 to 0, the major number
+	// in bits 15 to 8.
+	major := (tty >> 8) & 0xff
+	minor := (tty & 0xff) | ((tty >> 12) & 0xfff00)
+	return fmt.Sprintf("%d (major %d, minor %d)", tty, major, minor)
+}
+
+// formatFlags returns the decimal task flags as hexadecimal, as they're
+// defined in the kernel.
+func formatFlags(flags string) string {
+	n, err := strconv.ParseUint(flags, 10, 64)
+	if err != nil {
+		return flags
+	}
+	return fmt.Sprintf("0x%x", n)
+}
+
+// formatSignal returns the number and description of sig, such as 17 (child
+// exited).
+func formatSignal(sig Signal) string {
+	if sig == 0 {
+		return "0 (none)"
+	}
+	return fmt.Sprintf("%d (%s)", int(sig), syscall.Signal(sig).String())
+}
+
+// formatStatBytes returns n bytes followed by a readable size, such as
+// 1048576 bytes (1.0 MiB).
+func formatStatBytes(n int) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d bytes", n)
+	}
+	div, exp := unit, 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%d bytes (%.1f %ciB)", n, float64(n)/float64(div), "KMGTPE"[exp])
+}
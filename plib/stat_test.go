@@ -0,0 +1,52 @@
+package plib
+
+import (
+	"testing"
+)
+
+func TestStatFields(t *testing.T) {
+	s := ProcessStat{
+		ID:                     42,
+		State:                  "S",
+		TTY:                    34817,
+		TaskFlags:              "4194560",
+		UserModeTime:           150,
+		StartTime:              6000,
+		ResidentSetMemSize:     256,
+		SignalsIgnoredQuantity: 4096,
+		ExitSignal:             17,
+		SchedulingPolicy:       1,
+		StartCode:              "0x55d0c0a00000",
+	}
+	fields := s.StatFields(4096)
+	if len(fields) != 52 {
+		t.Fatalf("fail: expected 52 stat fields, actual: %d", len(fields))
+	}
+	expected := map[string]string{
+		"pid":         "42",
+		"state":       "S (sleeping)",
+		"tty_nr":      "34817 (major 136, minor 1)",
+		"flags":       "0x400100",
+		"utime":       "1.5s",
+		"starttime":   "1m0s after boot",
+		"rss":         "256 pages, 1048576 bytes (1.0 MiB)",
+		"sigignore":   "0x1000",
+		"exit_signal": "17 (child exited)",
+		"policy":      "1 (SCHED_FIFO)",
+		"startcode":   "0x55d0c0a00000",
+	}
+	for i, f := range fields {
+		if f.Number != i+1 {
+			t.Logf("fail: field %s was numbered %d, expected: %d", f.Name, f.Number, i+1)
+			t.Fail()
+		}
+		if v, ok := expected[f.Name]; ok && f.Value != v {
+			t.Logf("fail: value of %s was wrong, expected: %s, actual: %s", f.Name, v, f.Value)
+			t.Fail()
+		}
+	}
+	if fields[51].Name != "exit_code" || fields[13].Field != "UserModeTime" {
+		t.Logf("fail: fields were out of order: %+v, %+v", fields[51], fields[13])
+		t.Fail()
+	}
+}
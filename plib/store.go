@@ -0,0 +1,111 @@
+package plib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// validStoreName matches the names values may be saved with in a [JSONStore],
+// which are used as file names.
+var validStoreName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// JSONStore saves values as JSON files in a directory, one per name, such as
+// the baselines processes are verified against.
+type JSONStore struct {
+	dir string
+	// what the store holds, such as baseline, used in errors.
+	kind string
+}
+
+// NewJSONStore returns a store of the values in dir. kind describes what the
+// values are, such as baseline, for errors.
+func NewJSONStore(dir string, kind string) *JSONStore {
+	return &JSONStore{dir: dir, kind: kind}
+}
+
+// Save stores v as name, replacing any value with the same name. Names may
+// only contain letters, numbers, '.', '_', and '-'.
+func (s *JSONStore) Save(name string, v any) error {
+	fp, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0777); err != nil {
+		return fmt.Errorf("failed creating %s directory: %s", s.kind, err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(fp, data, 0666); err != nil {
+		return fmt.Errorf("failed saving %s %s: %s", s.kind, name, err)
+	}
+	return nil
+}
+
+// Load decodes the value saved as name into v.
+func (s *JSONStore) Load(name string, v any) error {
+	fp, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s %s does not exist", s.kind, name)
+		}
+		return fmt.Errorf("failed reading %s %s: %s", s.kind, name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed decoding %s %s: %s", s.kind, name, err)
+	}
+	return nil
+}
+
+// List returns the names of the saved values, sorted.
+func (s *JSONStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed listing %ss: %s", s.kind, err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if ok && !e.IsDir() && validStoreName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete removes the value saved as name.
+func (s *JSONStore) Delete(name string) error {
+	fp, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fp); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s %s does not exist", s.kind, name)
+		}
+		return fmt.Errorf("failed deleting %s %s: %s", s.kind, name, err)
+	}
+	return nil
+}
+
+// path returns the file the value saved as name is stored in.
+func (s *JSONStore) path(name string) (string, error) {
+	if !validStoreName.MatchString(name) {
+		return "", fmt.Errorf("%s name (%s) may only contain letters, numbers, '.', '_', and '-'", s.kind, name)
+	}
+	return filepath.Join(s.dir, name+".json"), nil
+}
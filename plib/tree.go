@@ -0,0 +1,74 @@
+package plib
+
+import "sort"
+
+// ProcessTree is a process and the processes it started, forming a tree.
+type ProcessTree struct {
+	Process Process
+	// Ordered by ID.
+	Children []*ProcessTree
+}
+
+// Tree returns the tree of the process with ID pid and its ancestors. The root
+// is the most parent ancestor, and each ancestor has the next as its only
+// child, ending with the process. When withChildren is true, the process's
+// descendants are included below it. Ancestors that can't be found, such as
+// those the caller lacks permission to inspect, end the tree. Tree returns nil
+// if pid isn't in ps.
+func (ps Processes) Tree(pid int, withChildren bool) *ProcessTree {
+	if ps[pid] == nil {
+		return nil
+	}
+	node := &ProcessTree{Process: *ps[pid], Children: []*ProcessTree{}}
+	if withChildren {
+		node = ps.descendants(pid)
+	}
+	visited := map[int]bool{pid: true}
+	for parent := ps[pid].ParentProcess; ps[parent] != nil && !visited[parent]; parent = ps[parent].ParentProcess {
+		visited[parent] = true
+		node = &ProcessTree{Process: *ps[parent], Children: []*ProcessTree{node}}
+	}
+	return node
+}
+
+// descendants returns the process with ID pid as the root of a tree of all
+// its descendants.
+func (ps Processes) descendants(pid int) *ProcessTree {
+	children := map[int][]int{}
+	for id, p := range ps {
+		// some processes, such as pid 0 on Linux, are their own parent.
+		if id != p.ParentProcess {
+			children[p.ParentProcess] = append(children[p.ParentProcess], id)
+		}
+	}
+	for _, ids := range children {
+		sort.Ints(ids)
+	}
+
+	visited := map[int]bool{}
+	var build func(pid int) *ProcessTree
+	build = func(pid int) *ProcessTree {
+		visited[pid] = true
+		node := &ProcessTree{Process: *ps[pid], Children: []*ProcessTree{}}
+		for _, child := range children[pid] {
+			if !visited[child] {
+				node.Children = append(node.Children, build(child))
+			}
+		}
+		return node
+	}
+	return build(pid)
+}
+
+// Walk calls fn for each process in the tree, parents before their children,
+// with the depth of the process below the root.
+func (t *ProcessTree) Walk(fn func(p *Process, depth int)) {
+	var walk func(n *ProcessTree, depth int)
+	walk = func(n *ProcessTree, depth int) {
+		fn(&n.Process, depth)
+		for _, c := range n.Children {
+			walk(c, depth+1)
+		}
+	}
+	walk(t, 0)
+}
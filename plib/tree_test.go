@@ -0,0 +1,48 @@
+package plib
+
+import "testing"
+
+func TestProcessesTree(t *testing.T) {
+	ps := Processes{
+		1:  {ID: 1, ParentProcess: 0},
+		10: {ID: 10, ParentProcess: 1},
+		20: {ID: 20, ParentProcess: 10},
+		22: {ID: 22, ParentProcess: 20},
+		21: {ID: 21, ParentProcess: 20},
+		30: {ID: 30, ParentProcess: 21},
+		40: {ID: 40, ParentProcess: 1},
+	}
+	tests := []struct {
+		name         string
+		pid          int
+		withChildren bool
+		expected     []int
+	}{
+		{"ancestors", 20, false, []int{1, 10, 20}},
+		{"children", 20, true, []int{1, 10, 20, 21, 30, 22}},
+		{"root", 1, false, []int{1}},
+	}
+	for _, test := range tests {
+		actual := []int{}
+		ps.Tree(test.pid, test.withChildren).Walk(func(p *Process, depth int) {
+			actual = append(actual, p.ID)
+		})
+		if len(actual) != len(test.expected) {
+			t.Logf("fail: tree %s expected %v, actual: %v", test.name, test.expected, actual)
+			t.Fail()
+			continue
+		}
+		for i := range actual {
+			if actual[i] != test.expected[i] {
+				t.Logf("fail: tree %s expected %v, actual: %v", test.name, test.expected, actual)
+				t.Fail()
+				break
+			}
+		}
+	}
+
+	if tree := ps.Tree(99, true); tree != nil {
+		t.Logf("fail: expected no tree for a missing process, actual: %v", tree)
+		t.Fail()
+	}
+}
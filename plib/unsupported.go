@@ -0,0 +1,20 @@
+//go:build !linux
+
+package plib
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// newInspector returns an error wrapping [ErrUnsupported], since processes
+// can only be inspected on Linux. See [NewInspector].
+func newInspector(opts ...InspectorConfig) (Inspector, error) {
+	return nil, fmt.Errorf("failed to create inspector because operating system %s is unsupported: %w", runtime.GOOS, ErrUnsupported)
+}
+
+// SignalVerifiedProcess returns an error wrapping [ErrUnsupported], since a
+// process's binary can only be verified on Linux.
+func SignalVerifiedProcess(pid int, sig Signal, sha string) error {
+	return fmt.Errorf("failed verifying process %d because operating system %s is unsupported: %w", pid, runtime.GOOS, ErrUnsupported)
+}
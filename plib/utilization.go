@@ -0,0 +1,72 @@
+package plib
+
+import (
+	"os"
+	"time"
+)
+
+// The clock ticks per second that CPU times in [ProcessStat] are measured in.
+// Linux reports these times in USER_HZ, which is 100 on every architecture it
+// supports.
+const clockTicksPerSecond = 100
+
+// Utilization is the resources used by a process, as calculated by a
+// [UtilizationSampler].
+type Utilization struct {
+	// The percentage of a single CPU's time the process was scheduled for
+	// between samples. Processes scheduled on multiple CPUs at once may exceed
+	// 100.
+	CPUPercent float64
+	// The memory resident for the process when sampled, in bytes.
+	RSSBytes int
+}
+
+// UtilizationSampler calculates the utilization of processes from
+// consecutive samples, such as those loaded periodically by [Watch]. The CPU
+// used by a process is the CPU time it accrued between samples, so a process's
+// CPU is only known once it's been in two samples. Only Linux processes,
+// whose OSSpecific field is a [ProcessStat], are sampled.
+type UtilizationSampler struct {
+	pageSize int
+	last     time.Time
+	// the CPU time of each process in the last sample.
+	ticks map[sampleKey]int
+}
+
+// sampleKey identifies a process across samples. The start time tells apart a
+// process from a later one given the same ID.
+type sampleKey struct {
+	id        int
+	startTime int
+}
+
+// NewUtilizationSampler returns a sampler without any samples.
+func NewUtilizationSampler() *UtilizationSampler {
+	return &UtilizationSampler{pageSize: os.Getpagesize(), ticks: map[sampleKey]int{}}
+}
+
+// Sample records the CPU time of ps, taken at the time at, and returns the
+// utilization of each process keyed by ID. The CPU of processes that weren't
+// in the previous sample is 0.
+func (s *UtilizationSampler) Sample(ps Processes, at time.Time) map[int]Utilization {
+	elapsed := at.Sub(s.last).Seconds()
+	result := map[int]Utilization{}
+	ticks := map[sampleKey]int{}
+	for id, p := range ps {
+		stat, ok := p.OSSpecific.(ProcessStat)
+		if !ok {
+			continue
+		}
+		// the resident set size is reported in pages.
+		u := Utilization{RSSBytes: stat.ResidentSetMemSize * s.pageSize}
+		key := sampleKey{id: id, startTime: stat.StartTime}
+		ticks[key] = stat.UserModeTime + stat.KernalTime
+		if prev, ok := s.ticks[key]; ok && elapsed > 0 && ticks[key] >= prev {
+			u.CPUPercent = float64(ticks[key]-prev) / clockTicksPerSecond / elapsed * 100
+		}
+		result[id] = u
+	}
+	s.ticks = ticks
+	s.last = at
+	return result
+}
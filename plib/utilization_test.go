@@ -0,0 +1,40 @@
+package plib
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestUtilizationSampler(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewUtilizationSampler()
+	first := s.Sample(Processes{
+		1:  {ID: 1, OSSpecific: ProcessStat{UserModeTime: 100, KernalTime: 50, StartTime: 1, ResidentSetMemSize: 10}},
+		42: {ID: 42, OSSpecific: ProcessStat{UserModeTime: 10, StartTime: 500}},
+		// processes without Linux details aren't sampled.
+		7: {ID: 7},
+	}, start)
+	if len(first) != 2 || first[1].CPUPercent != 0 {
+		t.Fatalf("fail: expected processes 1 and 42 without CPU in the first sample, actual: %v", first)
+	}
+	if first[1].RSSBytes != 10*os.Getpagesize() {
+		t.Logf("fail: expected RSS of 10 pages, actual: %d", first[1].RSSBytes)
+		t.Fail()
+	}
+
+	second := s.Sample(Processes{
+		// 2s of CPU time over 4s.
+		1: {ID: 1, OSSpecific: ProcessStat{UserModeTime: 250, KernalTime: 100, StartTime: 1}},
+		// a new process given the ID of one that exited.
+		42: {ID: 42, OSSpecific: ProcessStat{UserModeTime: 300, StartTime: 900}},
+	}, start.Add(4*time.Second))
+	if second[1].CPUPercent != 50 {
+		t.Logf("fail: expected process 1 to use 50%% CPU, actual: %v", second[1].CPUPercent)
+		t.Fail()
+	}
+	if second[42].CPUPercent != 0 {
+		t.Logf("fail: expected the new process 42 without CPU, actual: %v", second[42].CPUPercent)
+		t.Fail()
+	}
+}
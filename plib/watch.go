@@ -0,0 +1,131 @@
+package plib
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The time between process lookups when not set in [WatchOpts].
+const DefaultWatchInterval = 5 * time.Second
+
+// ProcessEventType describes what happened to a process.
+type ProcessEventType string
+
+const (
+	// The process was found for the first time.
+	ProcessStarted ProcessEventType = "started"
+	// The process is no longer running.
+	ProcessExited ProcessEventType = "exited"
+)
+
+// ProcessEvent is a change in the processes running on a host, as reported
+// by [Watch].
+type ProcessEvent struct {
+	Type    ProcessEventType
+	Process Process
+	// When the change was noticed. Since processes are looked up
+	// periodically, the change may have happened up to an interval earlier.
+	Time time.Time
+}
+
+// WatchOpts configures how processes are watched.
+type WatchOpts struct {
+	// The time between process lookups. Defaults to [DefaultWatchInterval].
+	Interval time.Duration
+	// When set, Lock is held while processes are loaded. Use it when the
+	// inspector is shared with other goroutines, since loading replaces the
+	// inspector's processes.
+	Lock sync.Locker
+}
+
+// Watch looks up processes with inspector every opts.Interval and sends an
+// event for each process started or exited since the previous lookup. The
+// processes running when Watch is called are the baseline, so no events are
+// sent for them. The returned channel is closed once ctx is done. Lookups
+// that fail are skipped.
+//
+// Processes are identified by their ID, so a process started with the ID of a
+// process that exited between lookups is not noticed.
+func Watch(ctx context.Context, inspector Inspector, opts ...WatchOpts) (<-chan ProcessEvent, error) {
+	conf := WatchOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = DefaultWatchInterval
+	}
+	load := func() (Processes, error) {
+		if conf.Lock != nil {
+			conf.Lock.Lock()
+			defer conf.Lock.Unlock()
+		}
+		if err := inspector.LoadProcesses(); err != nil {
+			return nil, err
+		}
+		ps, err := inspector.GetProcesses()
+		if err != nil {
+			return nil, err
+		}
+		// copy the processes, as the inspector's are replaced by the next
+		// load.
+		result := make(Processes, len(ps))
+		for id, p := range ps {
+			result[id] = p
+		}
+		return result, nil
+	}
+
+	previous, err := load()
+	if err != nil {
+		return nil, fmt.Errorf("failed loading processes to watch: %s", err)
+	}
+	events := make(chan ProcessEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(conf.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current, err := load()
+			if err != nil {
+				continue
+			}
+			for _, e := range DiffProcesses(previous, current, time.Now()) {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+			previous = current
+		}
+	}()
+	return events, nil
+}
+
+// DiffProcesses returns the events that turn previous into current: a
+// started event for every process only in current and an exited event for
+// every process only in previous. Events are ordered by process ID and have
+// their Time set to now.
+func DiffProcesses(previous Processes, current Processes, now time.Time) []ProcessEvent {
+	events := []ProcessEvent{}
+	for id, p := range current {
+		if _, ok := previous[id]; !ok {
+			events = append(events, ProcessEvent{Type: ProcessStarted, Process: *p, Time: now})
+		}
+	}
+	for id, p := range previous {
+		if _, ok := current[id]; !ok {
+			events = append(events, ProcessEvent{Type: ProcessExited, Process: *p, Time: now})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Process.ID < events[j].Process.ID })
+	return events
+}
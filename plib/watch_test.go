@@ -0,0 +1,66 @@
+package plib
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDiffProcesses(t *testing.T) {
+	previous := Processes{1: {ID: 1}, 2: {ID: 2}, 3: {ID: 3}}
+	current := Processes{1: {ID: 1}, 3: {ID: 3}, 4: {ID: 4}}
+	events := DiffProcesses(previous, current, time.Now())
+	if len(events) != 2 {
+		t.Fatalf("fail: expected 2 events, actual: %+v", events)
+	}
+	if events[0].Type != ProcessExited || events[0].Process.ID != 2 {
+		t.Logf("fail: expected process 2 to exit, actual: %+v", events[0])
+		t.Fail()
+	}
+	if events[1].Type != ProcessStarted || events[1].Process.ID != 4 {
+		t.Logf("fail: expected process 4 to start, actual: %+v", events[1])
+		t.Fail()
+	}
+}
+
+// fakeInspector returns the next set of processes in loads each time
+// processes are loaded.
+type fakeInspector struct {
+	loads []Processes
+	ps    Processes
+}
+
+func (f *fakeInspector) LoadProcesses() error {
+	if len(f.loads) > 0 {
+		f.ps, f.loads = f.loads[0], f.loads[1:]
+	}
+	return nil
+}
+func (f *fakeInspector) ClearProcessCache() error         { return nil }
+func (f *fakeInspector) GetProcesses() (Processes, error) { return f.ps, nil }
+func (f *fakeInspector) GetLastLoadTime() time.Time       { return time.Time{} }
+
+func TestWatch(t *testing.T) {
+	inspector := &fakeInspector{loads: []Processes{
+		{1: {ID: 1}},
+		{1: {ID: 1}, 2: {ID: 2}},
+		{2: {ID: 2}},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := Watch(ctx, inspector, WatchOpts{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("fail: unexpected error watching processes: %s", err)
+	}
+	expected := []ProcessEvent{{Type: ProcessStarted, Process: Process{ID: 2}}, {Type: ProcessExited, Process: Process{ID: 1}}}
+	for _, e := range expected {
+		actual := <-events
+		if actual.Type != e.Type || actual.Process.ID != e.Process.ID {
+			t.Logf("fail: expected %s of process %d, actual: %s of process %d", e.Type, e.Process.ID, actual.Type, actual.Process.ID)
+			t.Fail()
+		}
+	}
+	cancel()
+	for range events {
+	}
+}
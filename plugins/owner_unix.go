@@ -0,0 +1,19 @@
+//go:build unix
+
+package plugins
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// ownedByUserOrRoot returns whether the file described by info is owned by
+// the user proctor runs as or root.
+func ownedByUserOrRoot(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	return stat.Uid == 0 || int(stat.Uid) == os.Geteuid()
+}
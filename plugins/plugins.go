@@ -0,0 +1,292 @@
+// Package plugins runs third-party plugins extending proctor without
+// modifying it. Enricher plugins annotate processes with details proctor
+// doesn't know, such as their owner in an internal CMDB, and output plugins
+// ship processes to other systems, such as Splunk or Elasticsearch.
+//
+// A plugin is an executable in the plugins directory, written in any
+// language. Each time proctor calls a plugin, it runs the executable with a
+// JSON [Request] on its stdin and reads a JSON [Response] from its stdout.
+// Anything written to stderr is logged. Plugins are first called with the
+// describe method, to which they respond with the kinds of plugin they are.
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	// The version of the protocol plugins are called with. It's incremented
+	// when requests or responses change incompatibly.
+	ProtocolVersion = 1
+	// The directory, within [plib.CacheDirName], plugins are discovered in by
+	// default.
+	DirName = "plugins"
+	// The amount of time a plugin is given to respond when its context has
+	// no deadline.
+	DefaultTimeout = 30 * time.Second
+)
+
+// The methods plugins are called with.
+const (
+	// Respond with the plugin's kinds and description.
+	DescribeMethod = "describe"
+	// Respond with annotations for the request's processes.
+	EnrichMethod = "enrich"
+	// Ship the request's processes, responding once they're shipped.
+	OutputMethod = "output"
+)
+
+// Kind is a kind of plugin. A plugin may be of several kinds.
+type Kind string
+
+const (
+	// Annotates processes.
+	EnricherKind Kind = "enricher"
+	// Ships processes to another system.
+	OutputKind Kind = "output"
+)
+
+// Request is what plugins are called with, as JSON on their stdin.
+type Request struct {
+	ProtocolVersion int
+	Method          string
+	// The host the processes run on. Not set for the describe method.
+	Host string `json:",omitempty"`
+	// The processes to enrich or output, ordered by ID. Not set for the
+	// describe method.
+	Processes []*plib.Process `json:",omitempty"`
+}
+
+// Response is what plugins respond with, as JSON on their stdout.
+type Response struct {
+	// The kinds of the plugin, in response to the describe method.
+	Kinds []Kind `json:",omitempty"`
+	// What the plugin does, in response to the describe method.
+	Description string `json:",omitempty"`
+	// The annotations to add to processes, keyed by process ID, in response
+	// to the enrich method. Annotations replace those of the same name.
+	Annotations map[int]map[string]string `json:",omitempty"`
+	// Why the plugin failed, if it did.
+	Error string `json:",omitempty"`
+}
+
+// Plugin is a plugin discovered in a plugins directory.
+type Plugin struct {
+	// The plugin's file name, without its extension.
+	Name        string
+	Path        string
+	Kinds       []Kind
+	Description string
+}
+
+// DefaultDir returns the directory plugins are discovered in by default,
+// $XDG_DATA_HOME/plib.CacheDirName/DirName.
+func DefaultDir() string {
+	return filepath.Join(xdg.DataHome, plib.CacheDirName, DirName)
+}
+
+// DiscoverOpts configures how plugins are discovered.
+type DiscoverOpts struct {
+	// The names of the plugins to discover. Other plugins are never run.
+	// When empty, every plugin is discovered.
+	Names []string
+}
+
+// Discover returns the plugins in dir, ordered by name, describing each. When
+// dir is empty, [DefaultDir] is used. Files that aren't executable are
+// ignored. Plugins failing to describe themselves are left out, and their
+// errors returned along with the other plugins. A directory that doesn't
+// exist has no plugins.
+//
+// Since plugins run as the user proctor runs as, the directory and plugins
+// must be owned by that user or root, and not be writable by their group or
+// other users. When the directory isn't, an error is returned. Plugins that
+// aren't are left out, and their errors returned with the other plugins.
+func Discover(ctx context.Context, dir string, opts ...DiscoverOpts) ([]Plugin, error) {
+	conf := DiscoverOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	dirInfo, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return []Plugin{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed reading plugins directory: %s", err)
+	}
+	if err := checkTrusted(dirInfo); err != nil {
+		return nil, fmt.Errorf("refusing to run plugins from %s: %s", dir, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading plugins directory: %s", err)
+	}
+	plugins := []Plugin{}
+	errs := []error{}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		p := Plugin{Name: strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())), Path: path}
+		if len(conf.Names) > 0 && !contains(conf.Names, p.Name) {
+			continue
+		}
+		if err := checkTrusted(info); err != nil {
+			errs = append(errs, fmt.Errorf("refusing to run plugin %s: %s", p.Name, err))
+			continue
+		}
+		res, err := p.call(ctx, Request{Method: DescribeMethod})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p.Kinds = res.Kinds
+		p.Description = res.Description
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins, errors.Join(errs...)
+}
+
+// checkTrusted returns an error when the file described by info could have
+// been written by anyone other than the user proctor runs as or root.
+func checkTrusted(info fs.FileInfo) error {
+	if !ownedByUserOrRoot(info) {
+		return fmt.Errorf("%s isn't owned by the current user or root", info.Name())
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("%s is writable by its group or other users (%s)", info.Name(), info.Mode().Perm())
+	}
+	return nil
+}
+
+// Find returns the plugin named name, of kind, in plugins.
+func Find(plugins []Plugin, name string, kind Kind) (Plugin, error) {
+	for _, p := range plugins {
+		if p.Name != name {
+			continue
+		}
+		if !p.Is(kind) {
+			return Plugin{}, fmt.Errorf("plugin %s isn't an %s", name, kind)
+		}
+		return p, nil
+	}
+	return Plugin{}, fmt.Errorf("plugin %s wasn't found", name)
+}
+
+// Is returns whether p is of kind.
+func (p Plugin) Is(kind Kind) bool {
+	for _, k := range p.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Enrich calls the enricher p with ps, adding the annotations it responds
+// with to the processes.
+func (p Plugin) Enrich(ctx context.Context, ps plib.Processes) error {
+	res, err := p.call(ctx, Request{Method: EnrichMethod, Host: hostname(), Processes: sorted(ps)})
+	if err != nil {
+		return err
+	}
+	for id, annotations := range res.Annotations {
+		process, ok := ps[id]
+		if !ok {
+			continue
+		}
+		if process.Annotations == nil {
+			process.Annotations = map[string]string{}
+		}
+		for k, v := range annotations {
+			process.Annotations[k] = v
+		}
+	}
+	return nil
+}
+
+// Output calls the output plugin p with ps to ship them.
+func (p Plugin) Output(ctx context.Context, ps plib.Processes) error {
+	_, err := p.call(ctx, Request{Method: OutputMethod, Host: hostname(), Processes: sorted(ps)})
+	return err
+}
+
+// call runs p with req, returning its response. An error is returned when p
+// exits non-zero, doesn't respond with JSON, or responds with an error.
+func (p Plugin) call(ctx context.Context, req Request) (*Response, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+	req.ProtocolVersion = ProtocolVersion
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding request for plugin %s: %s", p.Name, err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if stderr.Len() > 0 {
+		slog.Debug("plugin stderr", "plugin", p.Name, "method", req.Method, "stderr", strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s failed (%s): %s %s", p.Name, req.Method, err, strings.TrimSpace(stderr.String()))
+	}
+	res := &Response{}
+	if err := json.Unmarshal(stdout.Bytes(), res); err != nil {
+		return nil, fmt.Errorf("plugin %s responded with invalid JSON (%s): %s", p.Name, req.Method, err)
+	}
+	if res.Error != "" {
+		return nil, fmt.Errorf("plugin %s failed (%s): %s", p.Name, req.Method, res.Error)
+	}
+	return res, nil
+}
+
+// sorted returns the processes of ps ordered by ID.
+func sorted(ps plib.Processes) []*plib.Process {
+	result, _ := ps.Sort("pid", false)
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// hostname returns the name of the host, or an empty string when it can't be
+// determined.
+func hostname() string {
+	host, _ := os.Hostname()
+	return host
+}
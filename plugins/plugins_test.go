@@ -0,0 +1,122 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// cmdbPlugin is an enricher annotating process 1 with its owner.
+const cmdbPlugin = `#!/bin/sh
+request=$(cat)
+case "$request" in
+*'"Method":"describe"'*) echo '{"Kinds": ["enricher"], "Description": "Owners from the CMDB"}' ;;
+*'"Method":"enrich"'*) echo '{"Annotations": {"1": {"owner": "platform"}, "99": {"owner": "nobody"}}}' ;;
+esac
+`
+
+// shipPlugin is an output plugin writing the request to the file in $OUT.
+const shipPlugin = `#!/bin/sh
+request=$(cat)
+case "$request" in
+*'"Method":"describe"'*) echo '{"Kinds": ["output"]}' ;;
+*) echo "$request" > "$OUT"; echo '{}' ;;
+esac
+`
+
+// writePlugin writes an executable plugin named name to dir.
+func writePlugin(t *testing.T, dir string, name string, content string) {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+		t.Fatalf("failed writing plugin: %s", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "cmdb.sh", cmdbPlugin)
+	writePlugin(t, dir, "ship", shipPlugin)
+	writePlugin(t, dir, "broken", "#!/bin/sh\necho not json\n")
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a plugin"), 0644)
+
+	plugins, err := Discover(context.Background(), dir)
+	if err == nil || !strings.Contains(err.Error(), "plugin broken") {
+		t.Logf("fail: expected an error for the broken plugin, actual: %v", err)
+		t.Fail()
+	}
+	if len(plugins) != 2 || plugins[0].Name != "cmdb" || !plugins[0].Is(EnricherKind) || plugins[0].Description != "Owners from the CMDB" || plugins[1].Name != "ship" {
+		t.Fatalf("fail: expected the cmdb and ship plugins, actual: %+v", plugins)
+	}
+	if _, err := Find(plugins, "ship", EnricherKind); err == nil {
+		t.Log("fail: expected an error finding an output plugin as an enricher")
+		t.Fail()
+	}
+	if _, err := Find(plugins, "missing", OutputKind); err == nil {
+		t.Log("fail: expected an error finding a plugin that doesn't exist")
+		t.Fail()
+	}
+
+	if plugins, err := Discover(context.Background(), filepath.Join(dir, "missing")); err != nil || len(plugins) != 0 {
+		t.Logf("fail: expected no plugins in a directory that doesn't exist, actual: %+v, %v", plugins, err)
+		t.Fail()
+	}
+
+	// only the named plugins are run, so the broken plugin isn't.
+	plugins, err = Discover(context.Background(), dir, DiscoverOpts{Names: []string{"cmdb"}})
+	if err != nil || len(plugins) != 1 || plugins[0].Name != "cmdb" {
+		t.Logf("fail: expected only the cmdb plugin, actual: %+v, %v", plugins, err)
+		t.Fail()
+	}
+
+	// plugins others can write to are refused, as is a directory others can
+	// write to.
+	os.Chmod(filepath.Join(dir, "ship"), 0777)
+	plugins, err = Discover(context.Background(), dir, DiscoverOpts{Names: []string{"ship"}})
+	if err == nil || len(plugins) != 0 {
+		t.Logf("fail: expected a world writable plugin to be refused, actual: %+v, %v", plugins, err)
+		t.Fail()
+	}
+	os.Chmod(dir, 0777)
+	if plugins, err := Discover(context.Background(), dir); err == nil || plugins != nil {
+		t.Logf("fail: expected a world writable directory to be refused, actual: %+v, %v", plugins, err)
+		t.Fail()
+	}
+}
+
+func TestEnrichAndOutput(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "cmdb", cmdbPlugin)
+	writePlugin(t, dir, "ship", shipPlugin)
+	plugins, err := Discover(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("fail: unexpected error discovering plugins: %s", err)
+	}
+
+	ps := plib.Processes{
+		1: {ID: 1, CommandName: "systemd", Annotations: map[string]string{"owner": "old", "tier": "0"}},
+		2: {ID: 2, CommandName: "bash"},
+	}
+	cmdb, _ := Find(plugins, "cmdb", EnricherKind)
+	if err := cmdb.Enrich(context.Background(), ps); err != nil {
+		t.Fatalf("fail: unexpected error enriching processes: %s", err)
+	}
+	if ps[1].Annotations["owner"] != "platform" || ps[1].Annotations["tier"] != "0" || ps[2].Annotations != nil {
+		t.Logf("fail: annotations were wrong: %v, %v", ps[1].Annotations, ps[2].Annotations)
+		t.Fail()
+	}
+
+	out := filepath.Join(t.TempDir(), "shipped")
+	t.Setenv("OUT", out)
+	ship, _ := Find(plugins, "ship", OutputKind)
+	if err := ship.Output(context.Background(), ps); err != nil {
+		t.Fatalf("fail: unexpected error outputting processes: %s", err)
+	}
+	shipped, _ := os.ReadFile(out)
+	if !strings.Contains(string(shipped), `"ProtocolVersion":1,"Method":"output"`) || !strings.Contains(string(shipped), `"owner":"platform"`) {
+		t.Logf("fail: expected the enriched processes to be shipped, actual: %s", shipped)
+		t.Fail()
+	}
+}
@@ -0,0 +1,310 @@
+// Package policy evaluates processes against rules, such as "no processes
+// run from /tmp", reporting the processes that violate them as findings.
+//
+// Rules are written as Go template expressions, as used by proctor's
+// go-template output, evaluated against an [Input] for each process. A rule
+// is violated when its expression evaluates to true.
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/plib"
+	"gopkg.in/yaml.v3"
+)
+
+// The severities a rule may have, from least to most severe.
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// severities ranks each severity, higher being more severe.
+var severities = map[string]int{
+	SeverityLow:      0,
+	SeverityMedium:   1,
+	SeverityHigh:     2,
+	SeverityCritical: 3,
+}
+
+// funcs are the functions available to rules, in addition to the builtin
+// functions of text/template, such as and, not, and eq.
+var funcs = template.FuncMap{
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"contains":  strings.Contains,
+	"lower":     strings.ToLower,
+	"matches": func(pattern, s string) (bool, error) {
+		return regexp.MatchString(pattern, s)
+	},
+}
+
+// Rule is a condition processes must not meet.
+type Rule struct {
+	// Identifies the rule in findings, such as no-tmp-binaries.
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// One of low, medium, high, or critical. Defaults to medium.
+	Severity string `yaml:"severity"`
+	// A Go template expression evaluated against the [Input] of each
+	// process, which is violated when it's true, such as
+	// hasPrefix .CommandPath "/tmp/". As with an if action, any value other
+	// than its type's zero value is true. Templates, containing {{ }}, can be
+	// used instead, and must render true or false.
+	Deny string `yaml:"deny"`
+
+	tmpl *template.Template
+}
+
+// Policy is a set of rules, as written in a rules file.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Load reads the policy in the YAML file at path. See [Parse].
+func Load(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading policy %s: %s", path, err)
+	}
+	return Parse(content)
+}
+
+// Parse returns the policy in content, a YAML document listing rules:
+//
+//	rules:
+//	  - name: no-tmp-binaries
+//	    description: Binaries must not run from /tmp.
+//	    severity: high
+//	    deny: hasPrefix .CommandPath "/tmp/"
+//
+// An error is returned when a rule is missing its name or expression, or its
+// expression or severity is invalid.
+func Parse(content []byte) (*Policy, error) {
+	p := &Policy{}
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("failed decoding policy: %s", err)
+	}
+	names := map[string]bool{}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("rule %s is defined more than once", r.Name)
+		}
+		names[r.Name] = true
+		if r.Severity == "" {
+			r.Severity = SeverityMedium
+		}
+		if _, ok := severities[r.Severity]; !ok {
+			return nil, fmt.Errorf("rule %s has an invalid severity (%s), expected low, medium, high, or critical", r.Name, r.Severity)
+		}
+		if strings.TrimSpace(r.Deny) == "" {
+			return nil, fmt.Errorf("rule %s has no deny expression", r.Name)
+		}
+		expr := strings.TrimSpace(r.Deny)
+		if !strings.Contains(expr, "{{") {
+			// like an if action, the expression is true when it's not the
+			// zero value of its type, so and and or, which return one of
+			// their arguments, work as expected.
+			expr = "{{ if " + expr + " }}true{{ else }}false{{ end }}"
+		}
+		tmpl, err := template.New(r.Name).Funcs(funcs).Option("missingkey=error").Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s has an invalid deny expression: %s", r.Name, err)
+		}
+		r.tmpl = tmpl
+	}
+	return p, nil
+}
+
+// Input is what rules are evaluated against for each process. The fields of
+// the process are promoted, so rules refer to them directly, such as
+// .CommandPath.
+type Input struct {
+	plib.Process
+	// The host the process runs on, nil when it wasn't collected.
+	Host *host.HostInfo
+	// The sockets the process holds open, empty when they weren't collected.
+	Sockets []plib.Socket
+	// Every process evaluated, keyed by ID, for rules relating processes.
+	Processes plib.Processes
+}
+
+// ListensOn returns whether the process listens on port, over tcp or udp.
+func (in Input) ListensOn(port int) bool {
+	for _, s := range in.Sockets {
+		if !s.IsListening() {
+			continue
+		}
+		if _, p, err := net.SplitHostPort(s.LocalAddress); err == nil && p == strconv.Itoa(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parent returns the process's parent, or nil when it wasn't evaluated.
+func (in Input) Parent() *plib.Process {
+	return in.Processes[in.ParentProcess]
+}
+
+// Finding is a process violating a rule.
+type Finding struct {
+	Rule        string
+	Severity    string
+	Description string
+	PID         int
+	CommandName string
+	CommandPath string
+	BinarySHA   string
+}
+
+// Report is the outcome of evaluating processes against a policy.
+type Report struct {
+	// The number of rules and processes evaluated.
+	Rules     int
+	Processes int
+	// Ordered by severity, most severe first, then rule and process ID.
+	Findings []Finding
+}
+
+// EvalOpts provides the details, beyond the processes, rules are evaluated
+// against. Every field is optional, though rules referring to details that
+// aren't provided fail or never match.
+type EvalOpts struct {
+	Host *host.HostInfo
+	// The sockets each process holds open, keyed by process ID.
+	Sockets map[int][]plib.Socket
+}
+
+// Evaluate returns the findings of evaluating every process in ps against
+// every rule of p. An error is returned when a rule can't be evaluated, such
+// as when its expression refers to a field that doesn't exist or doesn't
+// evaluate to true or false.
+//
+// The variadic nature of opts is only to make it optional. If more than one is
+// passed, the last is used.
+func (p *Policy) Evaluate(ps plib.Processes, opts ...EvalOpts) (Report, error) {
+	conf := EvalOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	report := Report{Rules: len(p.Rules), Processes: len(ps), Findings: []Finding{}}
+	ids := make([]int, 0, len(ps))
+	for id := range ps {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, r := range p.Rules {
+		for _, id := range ids {
+			in := Input{Process: *ps[id], Host: conf.Host, Sockets: conf.Sockets[id], Processes: ps}
+			denied, err := r.denies(in)
+			if err != nil {
+				return report, err
+			}
+			if denied {
+				report.Findings = append(report.Findings, r.finding(in))
+			}
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return severities[report.Findings[i].Severity] > severities[report.Findings[j].Severity]
+	})
+	return report, nil
+}
+
+// EvaluateProcess returns the findings of evaluating the process with id in
+// ps against every rule of p, ordered by severity, most severe first. Rules
+// relating processes, such as those referring to .Parent, are evaluated
+// against the rest of ps. See [Policy.Evaluate].
+func (p *Policy) EvaluateProcess(id int, ps plib.Processes, opts ...EvalOpts) ([]Finding, error) {
+	conf := EvalOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	process, ok := ps[id]
+	if !ok {
+		return nil, fmt.Errorf("process %d wasn't found", id)
+	}
+	in := Input{Process: *process, Host: conf.Host, Sockets: conf.Sockets[id], Processes: ps}
+	findings := []Finding{}
+	for _, r := range p.Rules {
+		denied, err := r.denies(in)
+		if err != nil {
+			return nil, err
+		}
+		if denied {
+			findings = append(findings, r.finding(in))
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severities[findings[i].Severity] > severities[findings[j].Severity]
+	})
+	return findings, nil
+}
+
+// finding returns the finding of in violating r.
+func (r Rule) finding(in Input) Finding {
+	return Finding{
+		Rule:        r.Name,
+		Severity:    r.Severity,
+		Description: r.Description,
+		PID:         in.ID,
+		CommandName: in.CommandName,
+		CommandPath: in.CommandPath,
+		BinarySHA:   in.BinarySHA,
+	}
+}
+
+// denies returns whether in violates r.
+func (r Rule) denies(in Input) (bool, error) {
+	if r.tmpl == nil {
+		return false, fmt.Errorf("rule %s wasn't parsed, use Parse or Load", r.Name)
+	}
+	var out bytes.Buffer
+	if err := r.tmpl.Execute(&out, in); err != nil {
+		return false, fmt.Errorf("failed evaluating rule %s for process %d: %s", r.Name, in.ID, err)
+	}
+	result, err := strconv.ParseBool(strings.TrimSpace(out.String()))
+	if err != nil {
+		return false, fmt.Errorf("rule %s evaluated to %q for process %d, expected true or false", r.Name, out.String(), in.ID)
+	}
+	return result, nil
+}
+
+// ParseSeverity validates severity, returning an error when it isn't low,
+// medium, high, or critical.
+func ParseSeverity(severity string) (string, error) {
+	if _, ok := severities[severity]; !ok {
+		return "", fmt.Errorf("severity (%s) is invalid, expected low, medium, high, or critical", severity)
+	}
+	return severity, nil
+}
+
+// AtLeast returns the findings of r whose severity is at least severity.
+func (r Report) AtLeast(severity string) []Finding {
+	result := []Finding{}
+	for _, f := range r.Findings {
+		if severities[f.Severity] >= severities[severity] {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const testPolicy = `
+rules:
+  - name: no-tmp-binaries
+    description: Binaries must not run from /tmp.
+    severity: high
+    deny: hasPrefix .CommandPath "/tmp/"
+  - name: only-sshd-on-22
+    deny: '{{ and (.ListensOn 22) (ne .CommandName "sshd") }}'
+  - name: shell-under-nginx
+    severity: critical
+    deny: and .Parent (eq .Parent.CommandName "nginx") (matches "^(ba|z)?sh$" .CommandName)
+`
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("failed parsing policy: %s", err)
+	}
+	ps := plib.Processes{
+		1:  {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd"},
+		10: {ID: 10, ParentProcess: 1, CommandName: "sshd", CommandPath: "/usr/sbin/sshd"},
+		20: {ID: 20, ParentProcess: 1, CommandName: "miner", CommandPath: "/tmp/miner"},
+		30: {ID: 30, ParentProcess: 1, CommandName: "nginx", CommandPath: "/usr/sbin/nginx"},
+		31: {ID: 31, ParentProcess: 30, CommandName: "sh", CommandPath: "/usr/bin/sh"},
+	}
+	sockets := map[int][]plib.Socket{
+		10: {{Protocol: "tcp", LocalAddress: "0.0.0.0:22", State: "LISTEN"}},
+		20: {{Protocol: "tcp6", LocalAddress: "[::]:22", State: "LISTEN"}},
+		30: {{Protocol: "tcp", LocalAddress: "10.0.0.1:22", RemoteAddress: "10.0.0.2:5000", State: "ESTABLISHED"}},
+	}
+	report, err := p.Evaluate(ps, EvalOpts{Sockets: sockets})
+	if err != nil {
+		t.Fatalf("failed evaluating policy: %s", err)
+	}
+
+	expected := []struct {
+		rule string
+		pid  int
+	}{
+		{"shell-under-nginx", 31},
+		{"no-tmp-binaries", 20},
+		{"only-sshd-on-22", 20},
+	}
+	if len(report.Findings) != len(expected) {
+		t.Fatalf("fail: expected %d findings, actual: %+v", len(expected), report.Findings)
+	}
+	for i, e := range expected {
+		if report.Findings[i].Rule != e.rule || report.Findings[i].PID != e.pid {
+			t.Logf("fail: expected finding %d to be %s for process %d, actual: %+v", i, e.rule, e.pid, report.Findings[i])
+			t.Fail()
+		}
+	}
+	if report.Findings[2].Severity != SeverityMedium {
+		t.Logf("fail: expected the default severity medium, actual: %s", report.Findings[2].Severity)
+		t.Fail()
+	}
+	if high := report.AtLeast(SeverityHigh); len(high) != 2 {
+		t.Logf("fail: expected 2 findings of at least high severity, actual: %+v", high)
+		t.Fail()
+	}
+
+	// a single process is evaluated against its relatives.
+	findings, err := p.EvaluateProcess(31, ps)
+	if err != nil || len(findings) != 1 || findings[0].Rule != "shell-under-nginx" {
+		t.Logf("fail: expected process 31 to violate shell-under-nginx, actual: %+v, %v", findings, err)
+		t.Fail()
+	}
+	if _, err := p.EvaluateProcess(99, ps); err == nil {
+		t.Log("fail: expected an error evaluating a process that wasn't found")
+		t.Fail()
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		expected string
+	}{
+		{"no name", "rules:\n  - deny: 'true'", "has no name"},
+		{"no deny", "rules:\n  - name: a", "no deny expression"},
+		{"severity", "rules:\n  - name: a\n    severity: urgent\n    deny: 'true'", "invalid severity"},
+		{"expression", "rules:\n  - name: a\n    deny: '{{ and .ID'", "invalid deny expression"},
+		{"duplicate", "rules:\n  - name: a\n    deny: 'true'\n  - name: a\n    deny: 'true'", "more than once"},
+		{"unknown field", "rules:\n  - name: a\n    when: 'true'", "failed decoding"},
+	}
+	for _, test := range tests {
+		_, err := Parse([]byte(test.policy))
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Logf("fail: %s expected an error containing %q, actual: %v", test.name, test.expected, err)
+			t.Fail()
+		}
+	}
+
+	p, err := Parse([]byte("rules:\n  - name: a\n    deny: '{{ .CommandName }}'"))
+	if err != nil {
+		t.Fatalf("failed parsing policy: %s", err)
+	}
+	if _, err := p.Evaluate(plib.Processes{1: {ID: 1, CommandName: "bash"}}); err == nil {
+		t.Log("fail: expected an error for an expression that isn't true or false")
+		t.Fail()
+	}
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arctir/proctor/agent"
+	"github.com/arctir/proctor/kube"
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/policy"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runAgent defines the behavior of running:
+// `proctor agent ...`
+func runAgent(cmd *cobra.Command, args []string) {
+	fs := cmd.Flags()
+	conf := agent.Config{}
+	conf.Address, _ = fs.GetString(addressFlag)
+	conf.TLSCert, _ = fs.GetString(tlsCertFlag)
+	conf.TLSKey, _ = fs.GetString(tlsKeyFlag)
+	conf.ClientCA, _ = fs.GetString(clientCAFlag)
+	conf.ScanInterval, _ = fs.GetDuration(scanIntervalFlag)
+	conf.Retention, _ = fs.GetDuration(retentionFlag)
+	conf.Baseline = loadRoleBaseline(cmd)
+	conf.Notifiers = newAlertNotifiers(cmd)
+	alertOn, _ := fs.GetStringSlice(alertOnFlag)
+	alertTypes, err := agent.ParseAlertTypes(alertOn)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid --%s: %s", alertOnFlag, err))
+	}
+	conf.AlertTypes = alertTypes
+	if file, _ := fs.GetString(policyFlag); file != "" {
+		conf.Policy, err = policy.Load(file)
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+	}
+	conf.SeenBinariesFile, _ = fs.GetString(seenBinariesFlag)
+	procfs, _ := fs.GetString(procfsFlag)
+	conf.Labels = parseLabels(cmd)
+	if k8s, _ := fs.GetBool(kubernetesFlag); k8s {
+		if procfs == "" {
+			procfs = kube.DefaultProcfsPath
+		}
+		configureKubernetes(cmd, &conf, procfs)
+	}
+	insp, err := plib.NewInspector(plib.InspectorConfig{LinuxConfig: plib.LinuxInspectorConfig{ProcfsFilePath: procfs}, Redactor: newRedactor(fs)})
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed setting up library to retrieve processes: %s", err))
+	}
+	if err := agent.New(insp).Run(conf); err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed serving the agent: %s", err))
+	}
+}
+
+// configureKubernetes configures conf for running as a DaemonSet, reading the
+// node's processes from procfs. Processes are attributed to the pods running
+// them, and the agent is named after its node and labelled with the node's
+// labels, which the --label flags override.
+func configureKubernetes(cmd *cobra.Command, conf *agent.Config, procfs string) {
+	node, _ := cmd.Flags().GetString(nodeNameFlag)
+	if node == "" {
+		node = os.Getenv(kube.NodeNameEnv)
+	}
+	if node == "" {
+		outputErrorAndFail(fmt.Sprintf("--%s requires the node's name, set with --%s or $%s", kubernetesFlag, nodeNameFlag, kube.NodeNameEnv))
+	}
+	client, err := kube.NewInClusterClient()
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	labels := map[string]string{}
+	if n, err := client.Node(context.Background(), node); err != nil {
+		slog.Warn("serving without the node's labels", "node", node, "error", err)
+	} else {
+		labels = n.Labels
+	}
+	for k, v := range conf.Labels {
+		labels[k] = v
+	}
+	conf.Host = node
+	conf.Labels = labels
+	conf.Enrichers = append(conf.Enrichers, kube.NewAttributor(client, node, procfs))
+}
+
+// parseLabels returns the labels set with the --label flags of
+// `proctor agent`.
+func parseLabels(cmd *cobra.Command) map[string]string {
+	labels := map[string]string{}
+	rawLabels, _ := cmd.Flags().GetStringArray(labelFlag)
+	for _, l := range rawLabels {
+		key, value, ok := strings.Cut(l, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			outputErrorAndFail(fmt.Sprintf("invalid --%s %q, expected key=value", labelFlag, l))
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return labels
+}
+
+// agentPathFlags are the agent flags whose values are paths, which `agent
+// install` makes absolute.
+var agentPathFlags = map[string]bool{
+	roleDirFlag:      true,
+	tlsCertFlag:      true,
+	tlsKeyFlag:       true,
+	clientCAFlag:     true,
+	policyFlag:       true,
+	seenBinariesFlag: true,
+	procfsFlag:       true,
+}
+
+// runAgentInstall defines the behavior of running:
+// `proctor agent install ...`
+// The unit runs this binary with the agent flags passed to install.
+func runAgentInstall(cmd *cobra.Command, args []string) {
+	bin, err := os.Executable()
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed finding the proctor binary: %s", err))
+	}
+	if resolved, err := filepath.EvalSymlinks(bin); err == nil {
+		bin = resolved
+	}
+	agentArgs := []string{agentCmd.Name()}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		// headers are written to the header file rather than the unit, which
+		// is readable by every user, as is the agent's command line.
+		if agentCmd.PersistentFlags().Lookup(f.Name) == nil || f.Name == alertHeaderFlag || f.Name == alertHeaderFileFlag {
+			return
+		}
+		// slices are passed as a flag per value, since their string form,
+		// e.g. [a,b], isn't parsed back.
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range slice.GetSlice() {
+				agentArgs = append(agentArgs, "--"+f.Name, v)
+			}
+			return
+		}
+		value := f.Value.String()
+		// the unit runs from /, so relative paths are resolved against the
+		// directory install is run in.
+		if agentPathFlags[f.Name] && value != "" {
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				outputErrorAndFail(fmt.Sprintf("failed resolving --%s: %s", f.Name, err))
+			}
+			value = abs
+		}
+		agentArgs = append(agentArgs, "--"+f.Name, value)
+	})
+	headers, _ := cmd.Flags().GetStringArray(alertHeaderFlag)
+	headerFile, _ := cmd.Flags().GetString(alertHeaderFileFlag)
+	if len(headers) > 0 && headerFile == "" {
+		headerFile = agent.DefaultAlertHeaderPath
+	}
+	if headerFile != "" {
+		if headerFile, err = filepath.Abs(headerFile); err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed resolving --%s: %s", alertHeaderFileFlag, err))
+		}
+	}
+	if headerFile != "" {
+		agentArgs = append(agentArgs, "--"+alertHeaderFileFlag, headerFile)
+	}
+	unit, err := agent.SystemdUnit(bin, agentArgs)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed generating systemd unit: %s", err))
+	}
+
+	if print, _ := cmd.Flags().GetBool(printFlag); print {
+		output([]byte(unit))
+		return
+	}
+	wrote := []string{}
+	if len(headers) > 0 {
+		// validate the headers before writing them.
+		parseAlertHeaders(headers, alertHeaderFlag)
+		if err := os.MkdirAll(filepath.Dir(headerFile), 0755); err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed writing alert headers: %s", err))
+		}
+		if err := os.WriteFile(headerFile, []byte(strings.Join(headers, "\n")+"\n"), 0600); err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed writing alert headers: %s", err))
+		}
+		// WriteFile doesn't change the mode of an existing file.
+		if err := os.Chmod(headerFile, 0600); err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed writing alert headers: %s", err))
+		}
+		wrote = append(wrote, headerFile)
+	}
+	unitPath, _ := cmd.Flags().GetString(unitPathFlag)
+	if err := os.WriteFile(unitPath, []byte(unit), 0644); err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed writing systemd unit: %s", err))
+	}
+	wrote = append(wrote, unitPath)
+	unitName := filepath.Base(unitPath)
+	output([]byte(fmt.Sprintf("wrote %s\nstart the agent with:\n  systemctl daemon-reload\n  systemctl enable --now %s\n", strings.Join(wrote, ", "), unitName)))
+}
+
+// parseAlertHeaders returns the headers, in "Key: Value" form, set by flag,
+// keyed by their name.
+func parseAlertHeaders(rawHeaders []string, flag string) map[string]string {
+	headers := map[string]string{}
+	for _, h := range rawHeaders {
+		key, value, ok := strings.Cut(h, ":")
+		if !ok {
+			outputErrorAndFail(fmt.Sprintf("invalid --%s %q, expected \"Key: Value\"", flag, h))
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+// readAlertHeaderFile returns the headers in the --alert-header-file at path,
+// one "Key: Value" per line. Since the headers may hold credentials, the file
+// must not be accessible to other users.
+func readAlertHeaderFile(path string) map[string]string {
+	info, err := os.Stat(path)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed reading --%s: %s", alertHeaderFileFlag, err))
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		outputErrorAndFail(fmt.Sprintf("--%s (%s) is accessible to other users (mode %s); restrict it with chmod 600", alertHeaderFileFlag, path, info.Mode().Perm()))
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed reading --%s: %s", alertHeaderFileFlag, err))
+	}
+	lines := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return parseAlertHeaders(lines, alertHeaderFileFlag)
+}
+
+// newAlertNotifiers returns the notifiers configured by the --alert-webhook
+// and --alert-exec flags of `proctor agent`. Webhook requests set the headers
+// of the --alert-header-file, and then the --alert-header flags.
+func newAlertNotifiers(cmd *cobra.Command) []agent.Notifier {
+	fs := cmd.Flags()
+	notifiers := []agent.Notifier{}
+	headers := map[string]string{}
+	if file, _ := fs.GetString(alertHeaderFileFlag); file != "" {
+		headers = readAlertHeaderFile(file)
+	}
+	rawHeaders, _ := fs.GetStringArray(alertHeaderFlag)
+	for k, v := range parseAlertHeaders(rawHeaders, alertHeaderFlag) {
+		headers[k] = v
+	}
+	webhooks, _ := fs.GetStringArray(alertWebhookFlag)
+	for _, url := range webhooks {
+		notifiers = append(notifiers, agent.Webhook{URL: url, Headers: headers})
+	}
+	commands, _ := fs.GetStringArray(alertExecFlag)
+	for _, c := range commands {
+		fields := strings.Fields(c)
+		if len(fields) == 0 {
+			outputErrorAndFail(fmt.Sprintf("--%s must not be empty", alertExecFlag))
+		}
+		notifiers = append(notifiers, agent.Command{Path: fields[0], Args: fields[1:]})
+	}
+	return notifiers
+}
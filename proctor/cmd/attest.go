@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/arctir/proctor/agent"
+	"github.com/arctir/proctor/attest"
+	"github.com/arctir/proctor/cosign"
+	"github.com/arctir/proctor/fleet"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// The environment variable read for the identity token used to sign
+	// keyless when --identity-token isn't passed, as set by sigstore's CI
+	// integrations.
+	identityTokenEnv = "SIGSTORE_ID_TOKEN"
+	// The environment variable read for the password of an encrypted cosign
+	// private key, as cosign does.
+	cosignPasswordEnv = "COSIGN_PASSWORD"
+)
+
+// verifiedAttestation is the outcome of `proctor verify-attestation`.
+type verifiedAttestation struct {
+	Statement attest.Statement
+	Result    cosign.Result
+}
+
+// runAttest defines the behavior of running:
+// `proctor attest`
+func runAttest(cmd *cobra.Command, args []string) {
+	// if proctor is run without a command (argument), print help.
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+}
+
+// runAttestFingerprint defines the behavior of running:
+// `proctor attest fingerprint ...`
+func runAttestFingerprint(cmd *cobra.Command, args []string) {
+	st, err := attest.ForFingerprint(fingerprintFromArgs(cmd, args))
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	outputAttestation(cmd, st)
+}
+
+// runAttestSnapshot defines the behavior of running:
+// `proctor attest snapshot ...`
+func runAttestSnapshot(cmd *cobra.Command, args []string) {
+	var snapshot agent.Snapshot
+	if len(args) > 0 {
+		// the host and time of the agent's snapshots are kept.
+		var err error
+		snapshot, err = fleet.Load(args[0])
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+	} else {
+		ps := liveProcesses(cmd)
+		snapshot.Host, _ = os.Hostname()
+		snapshot.LastRefresh = time.Now().UTC()
+		snapshot.Processes, _ = ps.Sort("pid", false)
+	}
+	st, err := attest.ForSnapshot(snapshot)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	outputAttestation(cmd, st)
+}
+
+// outputAttestation signs st as configured by the flags of `proctor attest`
+// and outputs the attestation.
+func outputAttestation(cmd *cobra.Command, st attest.Statement) {
+	opts := cosign.SignOpts{}
+	opts.IdentityToken, _ = cmd.Flags().GetString(identityTokenFlag)
+	if opts.IdentityToken == "" {
+		opts.IdentityToken = os.Getenv(identityTokenEnv)
+	}
+	opts.FulcioURL, _ = cmd.Flags().GetString(fulcioURLFlag)
+	opts.RekorURL, _ = cmd.Flags().GetString(rekorURLFlag)
+	opts.SkipTlog, _ = cmd.Flags().GetBool(skipTlogFlag)
+	if keyFile, _ := cmd.Flags().GetString(keyFlag); keyFile != "" {
+		content, err := os.ReadFile(keyFile)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed reading --%s: %s", keyFlag, err))
+		}
+		opts.Key, err = cosign.ParsePrivateKey(content, []byte(os.Getenv(cosignPasswordEnv)))
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+	} else if opts.IdentityToken == "" {
+		outputErrorAndFail(fmt.Sprintf("please pass a private key with --%s, or an identity token to sign keyless with --%s or $%s", keyFlag, identityTokenFlag, identityTokenEnv))
+	}
+
+	a, err := attest.Sign(st, opts)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed signing attestation: %s", err))
+	}
+	out, _ := json.Marshal(a)
+	output(out)
+}
+
+// runVerifyAttestation defines the behavior of running:
+// `proctor verify-attestation ...`
+func runVerifyAttestation(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed reading attestation: %s", err))
+	}
+	a, err := attest.Parse(content)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	conf := newCosignVerifyOpts(cmd.Flags())
+	st, result, err := attest.Verify(a, conf)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	if err := checkSignatureTrusted(result, conf); err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed verifying attestation: %s", err))
+	}
+
+	var out []byte
+	switch resolveOutputType(cmd.Flags()) {
+	case jsonOut:
+		out, _ = json.Marshal(verifiedAttestation{Statement: *st, Result: *result})
+	default:
+		out = newVerifyTableOutput(fmt.Sprintf("%s (%s, %d subjects)", args[0], st.PredicateType, len(st.Subject)), result)
+	}
+	output(out)
+}
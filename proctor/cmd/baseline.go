@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arctir/proctor/baseline"
+	"github.com/arctir/proctor/plib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// runBaseline defines what should occur when `proctor baseline ...` is run.
+func runBaseline(cmd *cobra.Command, args []string) {
+	// if proctor is run without a command (argument), print help.
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+}
+
+// runBaselineRecord defines the behavior of running:
+// `proctor baseline record ...`
+func runBaselineRecord(cmd *cobra.Command, args []string) {
+	role := roleFromFlags(cmd)
+	store := newRoleBaselineStore(cmd)
+	if force, _ := cmd.Flags().GetBool(forceFlag); !force {
+		if _, err := store.Load(role); err == nil {
+			outputErrorAndFail(fmt.Sprintf("a baseline of role %s exists, pass --%s to replace it or use `baseline update` to approve new binaries", role, forceFlag))
+		}
+	}
+	ps := liveProcesses(cmd)
+	expected, _ := cmd.Flags().GetStringSlice(expectFlag)
+	b := baseline.Record(role, ps, time.Now(), baseline.RecordOpts{Expected: expected})
+	if err := store.Save(b); err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	output([]byte(fmt.Sprintf("recorded baseline of role %s: %d approved binaries, %d expected to run\n", role, len(b.Binaries), len(b.Expected))))
+}
+
+// runBaselineUpdate defines the behavior of running:
+// `proctor baseline update ...`
+func runBaselineUpdate(cmd *cobra.Command, args []string) {
+	role := roleFromFlags(cmd)
+	store := newRoleBaselineStore(cmd)
+	b, err := store.Load(role)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	ps := liveProcesses(cmd)
+	report := b.Evaluate(ps, time.Now())
+	approved := len(report.Unapproved) + len(report.Changed)
+	b.Approve(ps, time.Now())
+	if err := store.Save(b); err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	output([]byte(fmt.Sprintf("updated baseline of role %s: approved the binaries of %d processes\n", role, approved)))
+}
+
+// runBaselineCheck defines the behavior of running:
+// `proctor baseline check ...`
+// It exits with driftExitCode when the processes drift from the baseline.
+func runBaselineCheck(cmd *cobra.Command, args []string) {
+	role := roleFromFlags(cmd)
+	b, err := newRoleBaselineStore(cmd).Load(role)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	report := b.Evaluate(liveProcesses(cmd), time.Now())
+	var out []byte
+	switch resolveOutputType(cmd.Flags()) {
+	case jsonOut:
+		out, _ = json.Marshal(report)
+	default:
+		out = newDriftReportTableOutput(report)
+	}
+	output(out)
+	if report.HasDrift() {
+		os.Exit(driftExitCode)
+	}
+}
+
+// runBaselineList defines the behavior of running:
+// `proctor baseline ls ...`
+func runBaselineList(cmd *cobra.Command, args []string) {
+	store := newRoleBaselineStore(cmd)
+	roles, err := store.List()
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	baselines := []baseline.Baseline{}
+	for _, role := range roles {
+		b, err := store.Load(role)
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+		baselines = append(baselines, b)
+	}
+	var out []byte
+	switch resolveOutputType(cmd.Flags()) {
+	case jsonOut:
+		out, _ = json.Marshal(baselines)
+	default:
+		rows := [][]string{}
+		for _, b := range baselines {
+			rows = append(rows, []string{b.Role, strconv.Itoa(len(b.Binaries)), strconv.Itoa(len(b.Expected)), b.Created.Format(time.RFC3339), b.Updated.Format(time.RFC3339)})
+		}
+		var buf bytes.Buffer
+		table := tablewriter.NewWriter(&buf)
+		table.SetHeader([]string{"Role", "Binaries", "Expected", "Created", "Updated"})
+		table.AppendBulk(rows)
+		table.Render()
+		out = buf.Bytes()
+	}
+	output(out)
+}
+
+// runBaselineDelete defines the behavior of running:
+// `proctor baseline rm ...`
+func runBaselineDelete(cmd *cobra.Command, args []string) {
+	role := roleFromFlags(cmd)
+	if err := newRoleBaselineStore(cmd).Delete(role); err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	output([]byte(fmt.Sprintf("deleted baseline of role %s\n", role)))
+}
+
+// roleFromFlags returns the role passed with --role, failing when it's
+// missing.
+func roleFromFlags(cmd *cobra.Command) string {
+	role, _ := cmd.Flags().GetString(roleFlag)
+	if role == "" {
+		outputErrorAndFail(fmt.Sprintf("please pass the role of the baseline, such as web or db, with --%s", roleFlag))
+	}
+	return role
+}
+
+// liveProcesses returns the processes running now, bypassing the cache.
+func liveProcesses(cmd *cobra.Command) plib.Processes {
+	opts := newProctorOptions(cmd.Flags())
+	opts.resetCache = true
+	ps, err := createInspectorAndGetProcesses(opts)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
+	}
+	return ps
+}
+
+// newRoleBaselineStore returns the store of role baselines in the
+// --role-dir directory, or the default.
+func newRoleBaselineStore(cmd *cobra.Command) *baseline.Store {
+	dir, _ := cmd.Flags().GetString(roleDirFlag)
+	return baseline.NewStore(dir)
+}
+
+// loadRoleBaseline returns the baseline of the role passed with
+// --baseline-role, or nil when it isn't set, for the agent and UI to serve.
+func loadRoleBaseline(cmd *cobra.Command) *baseline.Baseline {
+	role, _ := cmd.Flags().GetString(baselineRoleFlag)
+	if role == "" {
+		return nil
+	}
+	b, err := newRoleBaselineStore(cmd).Load(role)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	return &b
+}
+
+// newDriftReportTableOutput renders the drift in r as a table with a row per
+// unapproved or changed process and missing binary.
+func newDriftReportTableOutput(r baseline.Report) []byte {
+	var buf bytes.Buffer
+	if !r.HasDrift() {
+		fmt.Fprintf(&buf, "%d processes match the baseline of role %s\n", r.Processes, r.Role)
+		return buf.Bytes()
+	}
+	rows := [][]string{}
+	for _, p := range r.Unapproved {
+		rows = append(rows, []string{"unapproved", strconv.Itoa(p.ID), p.CommandName, p.CommandPath, shortSHA(p.BinarySHA)})
+	}
+	for _, c := range r.Changed {
+		approved := []string{}
+		for _, sha := range c.Approved {
+			approved = append(approved, shortSHA(sha))
+		}
+		rows = append(rows, []string{"changed", strconv.Itoa(c.Process.ID), c.Process.CommandName, c.Process.CommandPath, fmt.Sprintf("%s (approved: %s)", shortSHA(c.Process.BinarySHA), strings.Join(approved, ", "))})
+	}
+	for _, path := range r.Missing {
+		rows = append(rows, []string{"missing", "", "", path, ""})
+	}
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Drift", "PID", "Name", "Path", "SHA"})
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
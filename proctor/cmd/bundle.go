@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arctir/proctor/agent"
+	"github.com/arctir/proctor/bundle"
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/provenance"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// The names of the files collected into a bundle by `proctor bundle`, along
+// with the procfs copies and the manifest.
+const (
+	bundleSnapshotName     = "snapshot.json"
+	bundleHostName         = "host.json"
+	bundleFingerprintsName = "fingerprints.json"
+	// the suffix of the file the archive's SHA256 is written to, in the format
+	// of sha256sum.
+	digestFileSuffix = ".sha256"
+	bundleProcfs     = "/proc"
+)
+
+// runBundle defines the behavior of running:
+// `proctor bundle [PID...]`
+// The procfs files of each process passed, and of its ancestors, are copied
+// into the bundle.
+func runBundle(cmd *cobra.Command, args []string) {
+	ps := liveProcesses(cmd)
+	pids := []int{}
+	seen := map[int]bool{}
+	for _, arg := range args {
+		pid, err := strconv.Atoi(arg)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("invalid process ID %q: %s", arg, err))
+		}
+		if ps[pid] == nil {
+			outputErrorAndFail(fmt.Sprintf("process %d does not exist", pid))
+		}
+		for id := pid; id != 0 && ps[id] != nil && !seen[id]; id = ps[id].ParentProcess {
+			seen[id] = true
+			pids = append(pids, id)
+		}
+	}
+
+	m := bundle.Manifest{Created: time.Now().UTC(), OS: runtime.GOOS, Arch: runtime.GOARCH}
+	m.Hostname, _ = os.Hostname()
+	m.Tool, _ = provenance.Self()
+	path, _ := cmd.Flags().GetString(fileFlag)
+	if path == "" {
+		path = fmt.Sprintf("proctor-bundle-%s-%s.tar.gz", m.Hostname, m.Created.Format("20060102T150405Z"))
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed creating bundle: %s", err))
+	}
+	defer f.Close()
+
+	w := bundle.NewWriter(f, m)
+	snapshot := agent.Snapshot{Host: m.Hostname, LastRefresh: m.Created}
+	snapshot.Processes, _ = ps.Sort("pid", false)
+	if err := w.AddJSON(bundleSnapshotName, snapshot); err != nil {
+		w.AddError(bundleSnapshotName, err)
+	}
+	if err := w.AddJSON(bundleFingerprintsName, ps.FingerprintReport(m.Created)); err != nil {
+		w.AddError(bundleFingerprintsName, err)
+	}
+	lr := host.NewLinuxReader(host.LinuxReaderConfig{})
+	if err := w.AddJSON(bundleHostName, host.Collect(context.Background(), &lr)); err != nil {
+		w.AddError(bundleHostName, err)
+	}
+	w.AddProcFiles(bundleProcfs, pids)
+	if err := w.Close(); err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed creating bundle: %s", err))
+	}
+	if err := f.Close(); err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed creating bundle: %s", err))
+	}
+	digest := fmt.Sprintf("%s  %s\n", w.Digest(), filepath.Base(path))
+	if err := os.WriteFile(path+digestFileSuffix, []byte(digest), 0644); err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed writing the bundle's digest: %s", err))
+	}
+
+	m = w.Manifest()
+	out := fmt.Sprintf("wrote bundle %s with %d files\nsha256: %s\n", path, len(m.Files), w.Digest())
+	if len(m.Errors) > 0 {
+		out += fmt.Sprintf("%d files couldn't be collected; see the Errors of its manifest\n", len(m.Errors))
+	}
+	output([]byte(out))
+}
+
+// runBundleVerify defines the behavior of running:
+// `proctor bundle verify ARCHIVE`
+// It exits with a non-zero code when the archive doesn't match its digest,
+// written alongside it by `proctor bundle`, or its contents don't match its
+// manifest.
+func runBundleVerify(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		outputErrorAndFail("please pass the bundle to verify")
+	}
+	contents, err := os.ReadFile(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed reading bundle: %s", err))
+	}
+	// the digest is checked when it was kept alongside the bundle, since
+	// tickets commonly only carry the archive.
+	if expected, err := os.ReadFile(args[0] + digestFileSuffix); err == nil {
+		sum := sha256.Sum256(contents)
+		actual := hex.EncodeToString(sum[:])
+		if fields := strings.Fields(string(expected)); len(fields) == 0 || fields[0] != actual {
+			outputErrorAndFail(fmt.Sprintf("bundle %s doesn't match the SHA256 in %s; actual: %s", args[0], args[0]+digestFileSuffix, actual))
+		}
+	}
+	m, err := bundle.Verify(bytes.NewReader(contents))
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("bundle %s failed verification: %s", args[0], err))
+	}
+
+	var out []byte
+	switch resolveOutputType(cmd.Flags()) {
+	case jsonOut:
+		out, _ = json.Marshal(m)
+	default:
+		out = newBundleManifestTableOutput(m)
+	}
+	output(out)
+}
+
+func newBundleManifestTableOutput(m *bundle.Manifest) []byte {
+	version := ""
+	if m.Tool != nil {
+		version = m.Tool.ModuleVersion
+		if m.Tool.Revision != "" {
+			version += " (" + m.Tool.Revision + ")"
+		}
+	}
+	pids := []string{}
+	for _, pid := range m.PIDs {
+		pids = append(pids, strconv.Itoa(pid))
+	}
+	rows := [][]string{
+		{"Created", m.Created.Local().Format(timeDateFormat)},
+		{"Host", m.Hostname},
+		{"Platform", m.OS + "/" + m.Arch},
+		{"Proctor", version},
+		{"PIDs", strings.Join(pids, ", ")},
+	}
+	for _, f := range m.Files {
+		rows = append(rows, []string{f.Name, f.SHA256})
+	}
+	failed := []string{}
+	for name := range m.Errors {
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	for _, name := range failed {
+		rows = append(rows, []string{name, "not collected: " + m.Errors[name]})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Field", "Value"})
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
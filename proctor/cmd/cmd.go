@@ -2,18 +2,29 @@ package cmd
 
 import (
 	"bytes"
-	"crypto/sha256"
-	"encoding/hex"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/user"
+	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/arctir/proctor/platforms/github"
+	"github.com/arctir/proctor/cosign"
+	"github.com/arctir/proctor/fleet"
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/logging"
+	"github.com/arctir/proctor/platforms"
 	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/provenance"
 	"github.com/arctir/proctor/source"
 	"github.com/arctir/proctor/ui"
+	"github.com/arctir/proctor/vuln"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -21,6 +32,9 @@ import (
 
 const (
 	timeDateFormat = "2006-01-02 15:04"
+	// The environment variable read for the UI's action token when
+	// --action-token-file isn't passed.
+	actionTokenEnv = "PROCTOR_ACTION_TOKEN"
 )
 
 // SetupCLI constructs the cobra hierachry to create the proctor CLI.
@@ -31,22 +45,96 @@ const (
 // [plib]: https://github.com/arctir/proctor/tree/main/plib
 func SetupCLI() *cobra.Command {
 	proctorCmd.AddCommand(uiCmd)
+	proctorCmd.AddCommand(agentCmd)
+	agentCmd.AddCommand(agentInstallCmd)
 	proctorCmd.AddCommand(processCmd)
 	proctorCmd.AddCommand(sourceCmd)
+	proctorCmd.AddCommand(hostCmd)
+	proctorCmd.AddCommand(policyCmd)
+	proctorCmd.AddCommand(runtimeSBOMCmd)
+	proctorCmd.AddCommand(attestCmd)
+	attestCmd.AddCommand(attestFingerprintCmd)
+	attestCmd.AddCommand(attestSnapshotCmd)
+	proctorCmd.AddCommand(verifyAttestationCmd)
+	proctorCmd.AddCommand(baselineCmd)
+	proctorCmd.AddCommand(pluginCmd)
+	proctorCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleVerifyCmd)
+	proctorCmd.AddCommand(fleetCmd)
+	fleetCmd.AddCommand(fleetReportCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+	pluginCmd.AddCommand(pluginOutputCmd)
+	baselineCmd.AddCommand(baselineRecordCmd)
+	baselineCmd.AddCommand(baselineUpdateCmd)
+	baselineCmd.AddCommand(baselineCheckCmd)
+	baselineCmd.AddCommand(baselineListCmd)
+	baselineCmd.AddCommand(baselineDeleteCmd)
+	policyCmd.AddCommand(policyEvalCmd)
+	hostCmd.AddCommand(hostInfoCmd)
+	hostCmd.AddCommand(hostIDCmd)
+	hostCmd.AddCommand(hostHardwareCmd)
+	hostCmd.AddCommand(hostContainersCmd)
 	sourceCmd.AddCommand(commitCmd)
 	sourceCmd.AddCommand(artifactsCmd)
+	sourceCmd.AddCommand(releaseNotesCmd)
+	sourceCmd.AddCommand(submodulesCmd)
+	sourceCmd.AddCommand(dependenciesCmd)
+	sourceCmd.AddCommand(healthCmd)
+	dependenciesCmd.AddCommand(dependenciesDiffCmd)
+	dependenciesCmd.AddCommand(dependenciesVulnsCmd)
+	sourceCmd.AddCommand(advisoriesCmd)
+	sourceCmd.AddCommand(sbomCmd)
 	artifactsCmd.AddCommand(artifactsListCmd)
 	artifactsCmd.AddCommand(artifactsGetCmd)
+	artifactsCmd.AddCommand(artifactsVerifyCmd)
+	artifactsCmd.AddCommand(artifactsDiffCmd)
+	artifactsCmd.AddCommand(artifactsDownloadCmd)
 	commitCmd.AddCommand(contribListCmd)
 	commitCmd.AddCommand(contribDiffCmd)
+	commitCmd.AddCommand(contribStatsCmd)
+	commitCmd.AddCommand(contribActivityCmd)
+	commitCmd.AddCommand(contribGrepCmd)
 	processCmd.AddCommand(listCmd)
 	processCmd.AddCommand(getCmd)
 	processCmd.AddCommand(treeCmd)
+	processCmd.AddCommand(statCmd)
 	processCmd.AddCommand(fpCmd)
+	fpCmd.AddCommand(fpSaveCmd)
+	fpCmd.AddCommand(fpVerifyCmd)
+	processCmd.AddCommand(envCmd)
+	processCmd.AddCommand(portsCmd)
+	processCmd.AddCommand(provenanceCmd)
+	processCmd.AddCommand(processArtifactCmd)
+	processCmd.AddCommand(hashCmd)
+	processCmd.AddCommand(diffCmd)
+	processCmd.AddCommand(killCmd)
+	processCmd.AddCommand(vulnsCmd)
+	processCmd.AddCommand(reputationCmd)
+	processCmd.AddCommand(layersCmd)
+	registerCompletions()
+	registerSchemas()
+	cobra.OnInitialize(setupLogging)
 
 	return proctorCmd
 }
 
+// setupLogging configures the logger proctor's packages write to from the
+// --verbose, --quiet, and --log-format flags. It runs once flags are parsed,
+// before any command.
+func setupLogging() {
+	fs := proctorCmd.PersistentFlags()
+	verbose, _ := fs.GetBool(verboseFlag)
+	quiet, _ := fs.GetBool(quietFlag)
+	format, _ := fs.GetString(logFormatFlag)
+	if verbose && quiet {
+		outputErrorAndFail(fmt.Sprintf("--%s and --%s can't be combined", verboseFlag, quietFlag))
+	}
+	err := logging.Configure(logging.Config{Level: logging.LevelFor(verbose, quiet), Format: format})
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid value for --%s: %s", logFormatFlag, err))
+	}
+}
+
 // runProctor defines what should occur when `proctor ...` is run.
 func runProctor(cmd *cobra.Command, args []string) {
 	// if proctor is run without a command (argument), print help.
@@ -68,17 +156,89 @@ func runProcess(cmd *cobra.Command, args []string) {
 // runUI defines the behavior of running:
 // `proctor ui ...`
 func runUI(cmd *cobra.Command, args []string) {
-	ui.New().RunUI()
+	setupPlatforms(cmd)
+	fs := cmd.Flags()
+	conf := ui.UIConfig{}
+	conf.Address, _ = fs.GetString(addressFlag)
+	conf.Port, _ = fs.GetInt(portFlag)
+	conf.TLSCert, _ = fs.GetString(tlsCertFlag)
+	conf.TLSKey, _ = fs.GetString(tlsKeyFlag)
+	conf.SelfSigned, _ = fs.GetBool(selfSignedFlag)
+	conf.Agents, _ = fs.GetStringSlice(agentFlag)
+	tlsOpts := fleet.TLSOpts{}
+	tlsOpts.CA, _ = fs.GetString(agentCAFlag)
+	tlsOpts.Cert, _ = fs.GetString(agentCertFlag)
+	tlsOpts.Key, _ = fs.GetString(agentKeyFlag)
+	client, err := fleet.NewHTTPClient(fleet.DefaultTimeout, tlsOpts)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid --%s, --%s, or --%s: %s", agentCAFlag, agentCertFlag, agentKeyFlag, err))
+	}
+	conf.AgentClient = client
+	conf.ActionToken = readActionToken(fs)
+	// the audit trail of process actions is kept regardless of --quiet.
+	logFormat, _ := proctorCmd.PersistentFlags().GetString(logFormatFlag)
+	conf.AuditLog, _ = logging.New(logging.Config{Level: slog.LevelDebug, Format: logFormat})
+	conf.DisableAccessLog, _ = fs.GetBool(noAccessLogFlag)
+	conf.TemplateDir, _ = fs.GetString(themeDirFlag)
+	conf.ScanInterval, _ = fs.GetDuration(scanIntervalFlag)
+	conf.SourceHosts, _ = fs.GetStringSlice(sourceHostFlag)
+	conf.SourceTimeout, _ = fs.GetDuration(sourceTimeoutFlag)
+	conf.Baseline = loadRoleBaseline(cmd)
+	if err := ui.New(plib.InspectorConfig{Redactor: newRedactor(fs)}).RunUI(conf); err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed serving the UI: %s", err))
+	}
+}
+
+// readActionToken returns the token authenticating process actions in the UI,
+// read from the --action-token-file or $PROCTOR_ACTION_TOKEN. It isn't
+// accepted as a flag, since the command line is visible to other users. Since
+// the file holds a credential, it must not be accessible to other users.
+func readActionToken(fs *pflag.FlagSet) string {
+	path, _ := fs.GetString(actionTokenFileFlag)
+	if path == "" {
+		return os.Getenv(actionTokenEnv)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed reading --%s: %s", actionTokenFileFlag, err))
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		outputErrorAndFail(fmt.Sprintf("--%s (%s) is accessible to other users (mode %s); restrict it with chmod 600", actionTokenFileFlag, path, info.Mode().Perm()))
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed reading --%s: %s", actionTokenFileFlag, err))
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		outputErrorAndFail(fmt.Sprintf("--%s (%s) is empty", actionTokenFileFlag, path))
+	}
+	return token
 }
 
 // runListProcesses defines the behavior of running:
 // `proctor process ls ...`
 func runListProcesses(cmd *cobra.Command, args []string) {
 	opts := newProctorOptions(cmd.Flags())
+	if watch, _ := cmd.Flags().GetBool(watchFlag); watch {
+		if opts.idsOnly {
+			outputErrorAndFail(fmt.Sprintf("--%s can't be combined with --%s", idsFlag, watchFlag))
+		}
+		if enrich, _ := cmd.Flags().GetStringSlice(enrichFlag); len(enrich) > 0 {
+			outputErrorAndFail(fmt.Sprintf("--%s can't be combined with --%s", enrichFlag, watchFlag))
+		}
+		interval, _ := cmd.Flags().GetDuration(watchIntervalFlag)
+		if err := watchProcesses(opts, interval); err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed watching processes: %s", err))
+		}
+		return
+	}
 	ps, err := createInspectorAndGetProcesses(opts)
 	if err != nil {
 		outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
 	}
+	ps = ps.Filter(opts.filter)
+	enrichProcesses(cmd, ps)
 	out, err := createListOutput(ps, opts)
 	if err != nil {
 		outputErrorAndFail(fmt.Sprintf("failed creating output for retrieved processes: %s", err))
@@ -104,6 +264,14 @@ func runGetProcess(cmd *cobra.Command, args []string) {
 	switch {
 	case id != 0:
 		p := ps[id]
+		if opts.idsOnly {
+			matched := plib.Processes{}
+			if p != nil {
+				matched[id] = p
+			}
+			out, err = createIDListOutput(matched, opts)
+			break
+		}
 		out, err = createSingleOutput(p, opts)
 		if err != nil {
 			outputErrorAndFail(fmt.Sprintf("failed creating output for process: %s", err))
@@ -124,11 +292,38 @@ func runGetProcess(cmd *cobra.Command, args []string) {
 // runTreeProcess defines the behavior of running:
 // `proctor process tree ...`
 func runTreeProcess(cmd *cobra.Command, args []string) {
+	opts := newProctorOptions(cmd.Flags())
+	children, _ := cmd.Flags().GetBool(childrenFlag)
+	// when --name is specified, render the tree of every process with that
+	// name.
+	if name, _ := cmd.Flags().GetString(nameFlag); name != "" {
+		ps, err := createInspectorAndGetProcesses(opts)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
+		}
+		matched, err := findAllProcessesWithName(name, ps).Sort("pid", false)
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+		if len(matched) == 0 {
+			outputErrorAndFail(fmt.Sprintf("failed to find process with name: %s", name))
+		}
+		trees := []*plib.ProcessTree{}
+		for _, p := range matched {
+			trees = append(trees, ps.Tree(p.ID, children))
+		}
+		o, err := createTreesOutput(trees, opts)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed creating output for processes: %s", err))
+		}
+		output(o)
+		return
+	}
+
 	pid, err := parseID(args)
 	if err != nil {
-		outputErrorAndFail(fmt.Sprintf("please pass a valid pid (int); we received: %s", args[0]))
+		outputErrorAndFail(fmt.Sprintf("please pass a valid pid (int) or --%s; we received: %s", nameFlag, args))
 	}
-	opts := newProctorOptions(cmd.Flags())
 	ps, err := createInspectorAndGetProcesses(opts)
 	if err != nil {
 		outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
@@ -137,37 +332,19 @@ func runTreeProcess(cmd *cobra.Command, args []string) {
 		outputErrorAndFail(fmt.Sprintf("failed to find process with id: %d", pid))
 	}
 
-	// collect all processes from the specified and recursively to every parent.
-	relatedPs := []plib.Process{}
-	relatedPs = append(relatedPs, *ps[pid])
-	currentParentPid := ps[pid].ParentProcess
-	for {
-		// we've reached the root (likely the init system).
-		if currentParentPid == 0 {
-			break
-		}
-		// if we can't resolve details about the parent process, stop gathering the
-		// hierarchy.
-		if ps[currentParentPid] == nil {
-			break
-		}
-		relatedPs = append(relatedPs, *ps[currentParentPid])
-		currentParentPid = ps[currentParentPid].ParentProcess
-	}
-
-	o, err := createSliceListOutput(relatedPs, opts)
+	o, err := createTreeOutput(ps.Tree(pid, children), opts)
 	if err != nil {
 		outputErrorAndFail(fmt.Sprintf("failed creating output for processes: %s", err))
 	}
 	output(o)
 }
 
-// runFingerPrintProcess defines the behavior for running:
-// `proctor process finger-print ...`
-func runFingerPrintProcess(cmd *cobra.Command, args []string) {
+// runStatProcess defines the behavior of running:
+// `proctor process stat ...`
+func runStatProcess(cmd *cobra.Command, args []string) {
 	pid, err := parseID(args)
 	if err != nil {
-		outputErrorAndFail(fmt.Sprintf("please pass a valid pid (int); we received: %s", args[0]))
+		outputErrorAndFail(fmt.Sprintf("please pass a valid pid (int); we received: %s", args))
 	}
 	opts := newProctorOptions(cmd.Flags())
 	ps, err := createInspectorAndGetProcesses(opts)
@@ -177,29 +354,36 @@ func runFingerPrintProcess(cmd *cobra.Command, args []string) {
 	if ps[pid] == nil {
 		outputErrorAndFail(fmt.Sprintf("failed to find process with id: %d", pid))
 	}
-
-	if ps[pid].BinarySHA == "" {
-		outputErrorAndFail(fmt.Sprintf("process %d is missing details about its binary binary checksum.", pid))
+	stat, ok := ps[pid].OSSpecific.(plib.ProcessStat)
+	if !ok {
+		outputErrorAndFail(fmt.Sprintf("process %d has no stat details", pid))
 	}
-	combinedHashes := ps[pid].BinarySHA
-	// collect all processes from the specified and recursively to every parent.
-	currentParentPid := ps[pid].ParentProcess
-	for {
-		// we've reached the root (likely the init system).
-		if currentParentPid == 0 {
-			break
-		}
-		// if we can't resolve details about the parent process, there may be an
-		// issue with permission and the finger print will not be valid.
-		if ps[currentParentPid] == nil {
-			outputErrorAndFail(fmt.Sprintf("could not gather details on parent process: %d and thus could not generate a finger print. error: %s", currentParentPid, err))
-		}
-		combinedHashes += ps[currentParentPid].BinarySHA
-		currentParentPid = ps[currentParentPid].ParentProcess
+
+	fields := stat.StatFields(os.Getpagesize())
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(fields)
+	default:
+		out = newStatTableOutput(fields)
 	}
+	output(out)
+}
 
-	fp := sha256.Sum256([]byte(combinedHashes))
-	output([]byte(hex.EncodeToString(fp[:])))
+// newStatTableOutput renders the fields of a process's stat file as a table.
+func newStatTableOutput(fields []plib.StatField) []byte {
+	rows := [][]string{}
+	for _, f := range fields {
+		rows = append(rows, []string{strconv.Itoa(f.Number), f.Name, f.Field, f.Value})
+	}
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"#", "Name", "Field", "Value"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+	return buf.Bytes()
 }
 
 // parseID is a helper function to determine if the first argument passed to
@@ -221,15 +405,9 @@ func parseID(args []string) (int, error) {
 // 2. Setup configuration
 // 3. Retrieve a list of processes
 func createInspectorAndGetProcesses(opts proctorOpts) (plib.Processes, error) {
-	conf := plib.InspectorConfig{
-		LinuxConfig: plib.LinuxInspectorConfig{
-			IncludeKernel:           opts.includeKernel,
-			IncludePermissionIssues: opts.includePermIssue,
-		},
-	}
-	insp, err := plib.NewInspector(conf)
+	insp, err := createInspector(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed setting up library to retrieve processes: %s", err)
+		return nil, err
 	}
 	// if reset cache was set, clear the cache before attempting to load processes
 	if opts.resetCache {
@@ -242,6 +420,27 @@ func createInspectorAndGetProcesses(opts proctorOpts) (plib.Processes, error) {
 	return ps, nil
 }
 
+// createInspector creates a new inspector configured by opts.
+func createInspector(opts proctorOpts) (plib.Inspector, error) {
+	conf := plib.InspectorConfig{
+		LinuxConfig: plib.LinuxInspectorConfig{
+			IncludeKernel:           opts.includeKernel,
+			IncludePermissionIssues: opts.includePermIssue,
+		},
+		Redactor: opts.redactor,
+	}
+	// secrets are masked even when the options weren't created from flags,
+	// so they never reach the cache.
+	if conf.Redactor == nil {
+		conf.Redactor = plib.DefaultRedactor
+	}
+	insp, err := plib.NewInspector(conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed setting up library to retrieve processes: %s", err)
+	}
+	return insp, nil
+}
+
 // findAllProcessesWithName looks through all processes (ps) and find any
 // process where the [plib.Process]'s CommandName is equal to the provided
 // name. Since there can be multiple processes with the same command name, this
@@ -272,6 +471,11 @@ func createSingleOutput(ps *plib.Process, opts proctorOpts) ([]byte, error) {
 	switch opts.outType {
 	case jsonOut:
 		out = createJSONSingleOutput(ps)
+	case customColumnsOut, goTemplateOut:
+		if ps == nil {
+			return []byte{}, nil
+		}
+		return createFormattedOutput([]*plib.Process{ps}, opts)
 	default:
 		out = createTableSingleOutput(ps)
 	}
@@ -279,38 +483,54 @@ func createSingleOutput(ps *plib.Process, opts proctorOpts) ([]byte, error) {
 	return out, nil
 }
 
-func createSliceListOutput(ps []plib.Process, opts proctorOpts) ([]byte, error) {
-	var out []byte
-	switch opts.outType {
-	case jsonOut:
-		out = createJSONSliceListOutput(ps)
-	default:
-		out = createTableSliceListOutput(ps)
-	}
-
-	return out, nil
-}
-
 func createListOutput(ps plib.Processes, opts proctorOpts) ([]byte, error) {
+	if opts.idsOnly {
+		return createIDListOutput(ps, opts)
+	}
 	var out []byte
 	switch opts.outType {
 	case jsonOut:
 		out = createJSONListOutput(ps)
+	case csvOut:
+		out = createCSVListOutput(ps)
 	default:
-		out = createTableListOutput(ps)
+		sorted, err := ps.Sort(opts.sortBy, opts.sortDesc)
+		if err != nil {
+			return nil, err
+		}
+		if opts.outType == customColumnsOut || opts.outType == goTemplateOut {
+			return createFormattedOutput(sorted, opts)
+		}
+		out = createTableListOutput(sorted)
 	}
 
 	return out, nil
 }
 
-func createJSONSliceListOutput(ps []plib.Process) []byte {
-	out, _ := json.Marshal(ps)
-	return out
+// createIDListOutput lists the IDs of ps, one per line, in the order set by
+// opts, so they can be piped to other tools such as xargs.
+func createIDListOutput(ps plib.Processes, opts proctorOpts) ([]byte, error) {
+	sorted, err := ps.Sort(opts.sortBy, opts.sortDesc)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, p := range sorted {
+		fmt.Fprintln(&buf, p.ID)
+	}
+	return buf.Bytes(), nil
 }
 
 func createJSONListOutput(ps plib.Processes) []byte {
-	out, _ := json.Marshal(ps)
-	return out
+	var buf bytes.Buffer
+	plib.WriteProcessesJSON(&buf, ps)
+	return buf.Bytes()
+}
+
+func createCSVListOutput(ps plib.Processes) []byte {
+	var buf bytes.Buffer
+	plib.WriteProcessesCSV(&buf, ps)
+	return buf.Bytes()
 }
 
 func createJSONSingleOutput(ps *plib.Process) []byte {
@@ -338,6 +558,42 @@ func createTableSingleOutput(p *plib.Process) []byte {
 	return buf.Bytes()
 }
 
+func newProvenanceTableOutput(prov *provenance.Provenance) []byte {
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Module", "Version", "Repository", "Commit", "Tags", "Modified"})
+	table.Append([]string{
+		prov.ModulePath,
+		prov.ModuleVersion,
+		prov.RepoURL,
+		prov.Commit.Hash.String(),
+		strings.Join(prov.Tags, ", "),
+		strconv.FormatBool(prov.Modified),
+	})
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newArtifactMatchTableOutput(match *provenance.ArtifactMatch) []byte {
+	var buf bytes.Buffer
+	if !match.Matched {
+		buf.WriteString(fmt.Sprintf("unknown binary: no artifact in the latest %d releases has SHA256 %s\n", match.ReleasesSearched, match.BinarySHA))
+		return buf.Bytes()
+	}
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Release", "Tag", "Artifact", "Digest"})
+	table.Append([]string{
+		match.Release.Name,
+		match.Release.Tag,
+		match.Artifact.Name,
+		match.Artifact.Digest,
+	})
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
 // newCommitTableOutput takes a list of commits and create a table output
 // represented in bytes. It offers a lengthLimit argument which allows
 // limitting the amount of bytes used when printing in the table.
@@ -442,11 +698,11 @@ func newCommitDiffTableOutput(commitsOnlyIn1 []source.Commit, tag1 string, commi
 	return buf.Bytes()
 }
 
-func newAuthorTableOutput(authors []authorWrapper) []byte {
+func newAuthorTableOutput(authors []source.Author) []byte {
 	listOfAuthors := [][]string{}
 	for _, a := range authors {
 		listOfAuthors = append(listOfAuthors, []string{
-			strconv.Itoa(a.commitCount),
+			strconv.Itoa(a.CommitCount),
 			a.Name,
 			a.Email,
 		})
@@ -461,65 +717,626 @@ func newAuthorTableOutput(authors []authorWrapper) []byte {
 	return buf.Bytes()
 }
 
-func newArtifactListTableOutput(releases []github.Release) []byte {
+// newCommitsOutput renders commits as ot, where tables are limited to the
+// first 30 characters of each commit title.
+func newCommitsOutput(commits []source.Commit, ot outputType) ([]byte, error) {
+	var buf bytes.Buffer
+	switch ot {
+	case jsonOut:
+		if err := source.WriteCommitsJSON(&buf, commits); err != nil {
+			return nil, err
+		}
+	case csvOut:
+		if err := source.WriteCommitsCSV(&buf, commits); err != nil {
+			return nil, err
+		}
+	default:
+		return newCommitTableOutput(commits, 30), nil
+	}
+	return buf.Bytes(), nil
+}
+
+func newAuthorsOutput(authors []source.Author, ot outputType) ([]byte, error) {
+	var buf bytes.Buffer
+	switch ot {
+	case jsonOut:
+		if err := source.WriteAuthorsJSON(&buf, authors); err != nil {
+			return nil, err
+		}
+	case csvOut:
+		if err := source.WriteAuthorsCSV(&buf, authors); err != nil {
+			return nil, err
+		}
+	default:
+		return newAuthorTableOutput(authors), nil
+	}
+	return buf.Bytes(), nil
+}
+
+// newOrganizationsOutput renders authors grouped by email domain as ot.
+func newOrganizationsOutput(orgs []source.Organization, ot outputType) ([]byte, error) {
+	var buf bytes.Buffer
+	switch ot {
+	case jsonOut:
+		if err := source.WriteOrganizationsJSON(&buf, orgs); err != nil {
+			return nil, err
+		}
+	case csvOut:
+		if err := source.WriteOrganizationsCSV(&buf, orgs); err != nil {
+			return nil, err
+		}
+	default:
+		rows := [][]string{}
+		for _, o := range orgs {
+			rows = append(rows, []string{
+				strconv.Itoa(o.CommitCount),
+				strconv.Itoa(o.AuthorCount),
+				o.Name,
+				strings.Join(o.Domains, ", "),
+			})
+		}
+		table := tablewriter.NewWriter(&buf)
+		table.SetHeader([]string{"Commits", "Authors", "Organization", "Domains"})
+		table.AppendBulk(rows)
+		table.SetAutoWrapText(false)
+		table.Render()
+	}
+	return buf.Bytes(), nil
+}
+
+// newContributorsOutput renders contributors, as reported by the platform
+// hosting a repository, as ot.
+func newContributorsOutput(contributors []platforms.Contributor, ot outputType) ([]byte, error) {
+	var buf bytes.Buffer
+	switch ot {
+	case jsonOut:
+		if err := json.NewEncoder(&buf).Encode(contributors); err != nil {
+			return nil, err
+		}
+	case csvOut:
+		cw := csv.NewWriter(&buf)
+		if err := cw.Write([]string{"login", "contributions"}); err != nil {
+			return nil, err
+		}
+		for _, c := range contributors {
+			if err := cw.Write([]string{c.Login, strconv.Itoa(c.Contributions)}); err != nil {
+				return nil, err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return nil, err
+		}
+	default:
+		rows := [][]string{}
+		for _, c := range contributors {
+			rows = append(rows, []string{strconv.Itoa(c.Contributions), c.Login})
+		}
+		table := tablewriter.NewWriter(&buf)
+		table.SetHeader([]string{"Commits", "Login"})
+		table.AppendBulk(rows)
+		table.SetAutoWrapText(false)
+		table.Render()
+	}
+	return buf.Bytes(), nil
+}
+
+func newContribStatsOutput(stats source.ContributorStats, ot outputType) ([]byte, error) {
+	var buf bytes.Buffer
+	switch ot {
+	case jsonOut:
+		if err := source.WriteContributorStatsJSON(&buf, stats); err != nil {
+			return nil, err
+		}
+	case csvOut:
+		if err := source.WriteContributorStatsCSV(&buf, stats); err != nil {
+			return nil, err
+		}
+	default:
+		return newContribStatsTableOutput(stats), nil
+	}
+	return buf.Bytes(), nil
+}
+
+func newActivityOutput(buckets []source.ActivityBucket, ot outputType) ([]byte, error) {
+	var buf bytes.Buffer
+	switch ot {
+	case jsonOut:
+		if err := source.WriteActivityJSON(&buf, buckets); err != nil {
+			return nil, err
+		}
+	case csvOut:
+		if err := source.WriteActivityCSV(&buf, buckets); err != nil {
+			return nil, err
+		}
+	default:
+		return newActivityTableOutput(buckets), nil
+	}
+	return buf.Bytes(), nil
+}
+
+// newOwnerCoverageOutput renders the code owners responsible for commits as
+// ot.
+func newOwnerCoverageOutput(coverage []source.OwnerCoverage, ot outputType) ([]byte, error) {
+	var buf bytes.Buffer
+	switch ot {
+	case jsonOut:
+		if err := source.WriteOwnerCoverageJSON(&buf, coverage); err != nil {
+			return nil, err
+		}
+	case csvOut:
+		if err := source.WriteOwnerCoverageCSV(&buf, coverage); err != nil {
+			return nil, err
+		}
+	default:
+		return newOwnerCoverageTableOutput(coverage), nil
+	}
+	return buf.Bytes(), nil
+}
+
+func newOwnerCoverageTableOutput(coverage []source.OwnerCoverage) []byte {
+	rows := [][]string{}
+	for _, c := range coverage {
+		owner := c.Owner
+		if owner == "" {
+			owner = "(unowned)"
+		}
+		rows = append(rows, []string{
+			owner,
+			strconv.Itoa(c.Commits),
+			strconv.Itoa(c.Files),
+		})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Owner", "Commits", "Files"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+// newContribStatsTableOutput creates a table summarizing the contributor
+// stats followed by a table of the top authors.
+func newContribStatsTableOutput(stats source.ContributorStats) []byte {
+	window := "all time"
+	if !stats.Since.IsZero() || !stats.Until.IsZero() {
+		since, until := "beginning", "now"
+		if !stats.Since.IsZero() {
+			since = stats.Since.Format(timeDateFormat)
+		}
+		if !stats.Until.IsZero() {
+			until = stats.Until.Format(timeDateFormat)
+		}
+		window = fmt.Sprintf("%s to %s", since, until)
+	}
+	newContributors := "n/a (requires --since)"
+	if !stats.Since.IsZero() {
+		newContributors = strconv.Itoa(len(stats.NewContributors))
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Metric", "Value"})
+	table.AppendBulk([][]string{
+		{"Window", window},
+		{"Commits", strconv.Itoa(stats.TotalCommits)},
+		{"Active Contributors", strconv.Itoa(stats.ActiveContributors)},
+		{"New Contributors", newContributors},
+		{fmt.Sprintf("Commits by Top %d Authors", stats.TopN), fmt.Sprintf("%.1f%%", stats.TopAuthorsShare)},
+		{"Bus Factor", strconv.Itoa(stats.BusFactor)},
+	})
+	table.SetAutoWrapText(false)
+	table.Render()
+
+	top := stats.Authors
+	if len(top) > stats.TopN {
+		top = top[:stats.TopN]
+	}
+	buf.Write(newAuthorTableOutput(top))
+	return buf.Bytes()
+}
+
+func newHealthTableOutput(health source.RepoHealth) []byte {
+	orNone := func(s string) string {
+		if s == "" {
+			return "none"
+		}
+		return s
+	}
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Metric", "Value"})
+	table.AppendBulk([][]string{
+		{"Last Commit", health.LastCommit.Format(timeDateFormat)},
+		{"Days Since Last Commit", fmt.Sprintf("%.1f", health.DaysSinceLastCommit)},
+		{"Commits", strconv.Itoa(health.TotalCommits)},
+		{"Merge Commits", fmt.Sprintf("%d (%.1f%%)", health.MergeCommits, health.MergeCommitRatio*100)},
+		{"Tags", strconv.Itoa(health.Tags)},
+		{"Avg Days Between Tags", fmt.Sprintf("%.1f", health.AverageDaysBetweenTags)},
+		{"Days Since Last Tag", fmt.Sprintf("%.1f", health.DaysSinceLastTag)},
+		{"Releases", strconv.Itoa(health.Releases)},
+		{"Latest Release", orNone(health.LatestRelease)},
+		{"Avg Days Between Releases", fmt.Sprintf("%.1f", health.AverageDaysBetweenReleases)},
+		{"Days Since Last Release", fmt.Sprintf("%.1f", health.DaysSinceLastRelease)},
+	})
+	if issues := health.Issues; issues != nil {
+		table.AppendBulk([][]string{
+			{"Open Issues", strconv.Itoa(issues.OpenIssues)},
+			{"Closed Issues", strconv.Itoa(issues.ClosedIssues)},
+			{"Open Pull Requests", strconv.Itoa(issues.OpenPullRequests)},
+			{"Merged Pull Requests", strconv.Itoa(issues.MergedPullRequests)},
+			{"Closed Pull Requests", strconv.Itoa(issues.ClosedPullRequests)},
+			{"Median Time To Merge", issues.MedianTimeToMerge.Round(time.Minute).String()},
+			{"Pull Requests Opened (30d)", strconv.Itoa(issues.RecentPullRequestsOpened)},
+			{"Pull Requests Merged (30d)", strconv.Itoa(issues.RecentPullRequestsMerged)},
+		})
+	}
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newActivityTableOutput(buckets []source.ActivityBucket) []byte {
+	rows := [][]string{}
+	for _, b := range buckets {
+		rows = append(rows, []string{
+			b.Start.Format("2006-01-02"),
+			strconv.Itoa(b.Commits),
+			strconv.Itoa(b.UniqueAuthors),
+		})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Period Start", "Commits", "Authors"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newDependencyTableOutput(deps []source.Dependency) []byte {
+	rows := [][]string{}
+	for _, d := range deps {
+		rows = append(rows, []string{d.Manifest, d.Ecosystem, d.Name, d.Version})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Manifest", "Ecosystem", "Name", "Version"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newFindingTableOutput(findings []vuln.Finding) []byte {
+	rows := [][]string{}
+	for _, f := range findings {
+		rows = append(rows, []string{
+			f.Dependency.Manifest,
+			f.Dependency.Name,
+			f.Dependency.Version,
+			f.Vulnerability.ID,
+			strings.Join(f.Vulnerability.Aliases, ", "),
+			strings.Join(f.FixedVersions, ", "),
+			f.Vulnerability.Summary,
+		})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Manifest", "Name", "Version", "Vulnerability", "Aliases", "Fixed In", "Summary"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newAdvisoryTableOutput(affected []vuln.AffectedRelease) []byte {
+	rows := [][]string{}
+	for _, a := range affected {
+		releases := []string{}
+		for _, t := range a.Releases {
+			releases = append(releases, t.Name)
+		}
+		patched := []string{}
+		for _, p := range a.Advisory.Affected {
+			if p.PatchedVersions != "" {
+				patched = append(patched, p.PatchedVersions)
+			}
+		}
+		rows = append(rows, []string{
+			a.Advisory.ID,
+			a.Advisory.CVE,
+			a.Advisory.Severity,
+			a.Advisory.Published.Format("2006-01-02"),
+			strings.Join(releases, ", "),
+			strings.Join(patched, ", "),
+			a.Advisory.Summary,
+		})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"ID", "CVE", "Severity", "Published", "Affected Releases", "Patched In", "Summary"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newDependencyDiffTableOutput(diff source.DependencyDiff) []byte {
+	rows := [][]string{}
+	for _, d := range diff.Added {
+		rows = append(rows, []string{"added", d.Manifest, d.Name, "", d.Version})
+	}
+	for _, d := range diff.Removed {
+		rows = append(rows, []string{"removed", d.Manifest, d.Name, d.Version, ""})
+	}
+	for _, d := range diff.Changed {
+		rows = append(rows, []string{"changed", d.Manifest, d.Name, d.PreviousVersion, d.Version})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Change", "Manifest", "Name", "From", "To"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+// newSubmoduleTableOutput lists each submodule, including those nested within
+// other submodules. Nested submodules are shown with their full path.
+func newSubmoduleTableOutput(submodules []source.Submodule) []byte {
+	rows := [][]string{}
+	var addRows func(prefix string, submodules []source.Submodule)
+	addRows = func(prefix string, submodules []source.Submodule) {
+		for _, sm := range submodules {
+			p := path.Join(prefix, sm.Path)
+			rows = append(rows, []string{p, sm.URL, sm.Commit.String()})
+			addRows(p, sm.Submodules)
+		}
+	}
+	addRows("", submodules)
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Path", "URL", "Commit"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+// newHostInfoTableOutput creates a table where each row is a detail about the
+// host. Sections that failed to resolve are listed with their error.
+func newHostInfoTableOutput(info host.HostInfo) []byte {
+	rows := [][]string{
+		{"ID", info.ID},
+	}
+	if info.OS != nil {
+		rows = append(rows, []string{"OS", info.OS.Name}, []string{"OS Version", info.OS.Version})
+	}
+	if info.Kernel != nil {
+		rows = append(rows, []string{"Kernel", fmt.Sprintf("%s %s", info.Kernel.Type, strings.TrimSpace(info.Kernel.Version))})
+	}
+	if info.Hardware != nil {
+		rows = append(rows,
+			[]string{"Architecture", info.Hardware.Architecture},
+			[]string{"CPUs", strconv.Itoa(info.Hardware.CPU.CPUCount)},
+		)
+	}
+	if info.Load != nil {
+		avg := info.Load.Average
+		rows = append(rows, []string{"Load Average", fmt.Sprintf("%.2f %.2f %.2f", avg.OneMinute, avg.FiveMinute, avg.FifteenMinute)})
+		if info.Load.Pressure != nil {
+			p := info.Load.Pressure
+			rows = append(rows, []string{"Pressure (some avg10)",
+				fmt.Sprintf("cpu=%.2f memory=%.2f io=%.2f", p.CPU.Some.Avg10, p.Memory.Some.Avg10, p.IO.Some.Avg10)})
+		}
+	}
+	sections := make([]string, 0, len(info.Errors))
+	for section := range info.Errors {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		rows = append(rows, []string{fmt.Sprintf("Error (%s)", section), info.Errors[section]})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Field", "Value"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newHardwareTableOutput(hw *host.Hardware) []byte {
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Architecture", "CPUs"})
+	table.Append([]string{hw.Architecture, strconv.Itoa(hw.CPU.CPUCount)})
+	table.Render()
+	return buf.Bytes()
+}
+
+func newContainerTableOutput(containers []host.Container) []byte {
+	rows := [][]string{}
+	for _, c := range containers {
+		id := c.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		started := ""
+		if !c.StartedAt.IsZero() {
+			started = c.StartedAt.Local().Format(timeDateFormat)
+		}
+		rows = append(rows, []string{id, c.Name, c.Image, c.State, started})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"ID", "Name", "Image", "State", "Started"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newArtifactListTableOutput(releases []platforms.Release) []byte {
 	listOfArtifacts := [][]string{}
 	for _, r := range releases {
 		count := len(r.Artifacts)
+		published := "draft"
+		if !r.PublishedAt.IsZero() {
+			published = r.PublishedAt.Format("2006-01-02")
+		}
 		listOfArtifacts = append(listOfArtifacts, []string{
-			r.Name,
 			r.Tag,
+			r.Name,
+			published,
+			strconv.FormatBool(r.Prerelease),
 			strconv.Itoa(count),
+			strconv.Itoa(r.Downloads()),
 		})
 	}
 
 	var buf bytes.Buffer
 	table := tablewriter.NewWriter(&buf)
-	table.SetHeader([]string{"Tag", "Title", "Artifacts"})
+	table.SetHeader([]string{"Tag", "Title", "Published", "Prerelease", "Artifacts", "Downloads"})
 	table.AppendBulk(listOfArtifacts)
 	table.SetAutoWrapText(false)
 	table.Render()
 	return buf.Bytes()
 }
 
-func newArtifactGetTableOutput(releases []github.Artifact) []byte {
+func newArtifactDiffTableOutput(diff platforms.ArtifactDiff) []byte {
+	size := func(a platforms.Artifact) string { return strconv.FormatInt(a.Size, 10) }
+	rows := [][]string{}
+	for _, a := range diff.Added {
+		rows = append(rows, []string{"added", a.Name, "", size(a), ""})
+	}
+	for _, a := range diff.Removed {
+		rows = append(rows, []string{"removed", a.Name, size(a), "", ""})
+	}
+	for _, c := range diff.Renamed {
+		rows = append(rows, []string{"renamed", fmt.Sprintf("%s -> %s", c.From.Name, c.To.Name), size(c.From), size(c.To), "false"})
+	}
+	for _, c := range diff.Changed {
+		rows = append(rows, []string{"changed", c.To.Name, size(c.From), size(c.To), strconv.FormatBool(c.DigestChanged())})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Change", "Artifact", "Size From", "Size To", "Digest Changed"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newArtifactGetTableOutput(releases []platforms.Artifact) []byte {
+	// downloads are shown alongside their share of the release's downloads.
+	total := platforms.Release{Artifacts: releases}.Downloads()
 	listOfArtifacts := [][]string{}
 	for _, r := range releases {
+		downloads := strconv.Itoa(r.Downloads)
+		if total > 0 {
+			downloads = fmt.Sprintf("%d (%.1f%%)", r.Downloads, float64(r.Downloads)/float64(total)*100)
+		}
 		listOfArtifacts = append(listOfArtifacts, []string{
 			r.Name,
 			r.ContentType,
+			downloads,
+			r.Digest,
 			r.URL,
 		})
 	}
 
 	var buf bytes.Buffer
 	table := tablewriter.NewWriter(&buf)
-	table.SetHeader([]string{"Assset", "Content-Type", "URL"})
+	table.SetHeader([]string{"Assset", "Content-Type", "Downloads", "Digest", "URL"})
 	table.AppendBulk(listOfArtifacts)
 	table.SetAutoWrapText(false)
 	table.Render()
 	return buf.Bytes()
 }
 
-func createTableListOutput(ps plib.Processes) []byte {
-	listOfPs := [][]string{}
-	for _, p := range ps {
-		listOfPs = append(listOfPs, []string{
-			strconv.Itoa(p.ID),
-			p.CommandName,
-			p.CommandPath,
-			p.BinarySHA,
-		})
+func newVerifyTableOutput(name string, result *cosign.Result) []byte {
+	signer := result.Signer
+	if signer == "" {
+		signer = "public key"
+	}
+	chain := "verified"
+	if result.Signer == "" {
+		chain = "n/a"
+	}
+	tlog := "not checked"
+	if result.TlogVerified {
+		tlog = fmt.Sprintf("verified (index %d, %s)", result.LogIndex, result.IntegratedTime.Format(time.RFC3339))
 	}
 
 	var buf bytes.Buffer
 	table := tablewriter.NewWriter(&buf)
-	table.SetHeader([]string{"PID", "name", "location", "SHA"})
-	table.AppendBulk(listOfPs)
+	table.SetHeader([]string{"Check", "Result"})
+	table.AppendBulk([][]string{
+		{"Artifact", name},
+		{"SHA256", result.Digest},
+		{"Signature", "verified"},
+		{"Signer", signer},
+		{"Issuer", result.Issuer},
+		{"Certificate Chain", chain},
+		{"Transparency Log", tlog},
+	})
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
+
+// artifactDownload is an artifact written to disk by `proctor source
+// artifacts download`.
+type artifactDownload struct {
+	platforms.DownloadedArtifact
+	// The result of verifying the artifact's cosign signature, nil when it
+	// wasn't verified.
+	Signature *cosign.Result
+}
+
+// newDownloadTableOutput renders downloaded artifacts and the checks they
+// passed as a table.
+func newDownloadTableOutput(downloads []artifactDownload) []byte {
+	rows := [][]string{}
+	for _, d := range downloads {
+		checksum := "not listed"
+		if d.ChecksumVerified {
+			checksum = "verified"
+		}
+		signature := "not checked"
+		if d.Signature != nil {
+			signature = "verified"
+			if d.Signature.Signer != "" {
+				signature += " (" + d.Signature.Signer + ")"
+			}
+		}
+		rows = append(rows, []string{d.Path, formatBytes(d.Artifact.Size), d.Digest, checksum, signature})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Path", "Size", "Digest", "Checksum", "Signature"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
 	table.Render()
 	return buf.Bytes()
 }
 
-func createTableSliceListOutput(ps []plib.Process) []byte {
+func createTableListOutput(ps []*plib.Process) []byte {
 	listOfPs := [][]string{}
 	for _, p := range ps {
 		listOfPs = append(listOfPs, []string{
@@ -550,6 +1367,39 @@ type sourceOpts struct {
 	// used when you want to compare commits between 2 tags, required tagOne to
 	// be set.
 	tagTwo string
+	// used when you want to retrieve commits from a branch other than HEAD.
+	branch string
+	// the tag release notes start from (exclusive).
+	fromTag string
+	// the tag release notes end at (inclusive).
+	toTag string
+	// the start of the time window commits are considered in. Can be a date or
+	// a duration into the past, see parseTimeFlag.
+	since string
+	// the end of the time window commits are considered in.
+	until string
+	// the number of top authors to report on.
+	topN int
+	// the size of each bucket in an activity time series (week or month).
+	interval string
+	// whether submodules should be resolved recursively.
+	recursive bool
+	// whether patterns should be matched regardless of case.
+	ignoreCase bool
+	// used when you want to summarize the code owners covering commits.
+	owners bool
+	// whether issue and pull request statistics should be retrieved from the
+	// platform hosting the repository.
+	issues bool
+	// which releases are listed and in what order.
+	releaseFilter platforms.ReleaseFilterOpts
+	// used when you want to retrieve data from the platform hosting the
+	// repository rather than cloning it.
+	remote bool
+	// used when you want authors grouped by their email domain.
+	byDomain bool
+	// maps email domains to the organization they're grouped under.
+	domainAliases map[string]string
 }
 
 func newSourceOptions(fs *pflag.FlagSet) sourceOpts {
@@ -557,29 +1407,134 @@ func newSourceOptions(fs *pflag.FlagSet) sourceOpts {
 	singleTag, _ := fs.GetString(tagFlag)
 	t1, _ := fs.GetString(tagOneFlag)
 	t2, _ := fs.GetString(tagTwoFlag)
+	branch, _ := fs.GetString(branchFlag)
+	from, _ := fs.GetString(fromFlag)
+	to, _ := fs.GetString(toFlag)
+	since, _ := fs.GetString(sinceFlag)
+	until, _ := fs.GetString(untilFlag)
+	topN, _ := fs.GetInt(topFlag)
+	interval, _ := fs.GetString(intervalFlag)
+	recursive, _ := fs.GetBool(recursiveFlag)
+	ignoreCase, _ := fs.GetBool(ignoreCaseFlag)
+	owners, _ := fs.GetBool(ownersFlag)
+	issues, _ := fs.GetBool(issuesFlag)
+	excludePre, _ := fs.GetBool(excludePreFlag)
+	excludeDrafts, _ := fs.GetBool(excludeDraftsFlag)
+	sortBy, _ := fs.GetString(sortFlag)
+	remote, _ := fs.GetBool(remoteFlag)
+	byDomain, _ := fs.GetBool(byDomainFlag)
+	domainAliases, _ := fs.GetStringToString(domainAliasFlag)
 
 	return sourceOpts{
 		retrieveOnlyAuthors: roa,
 		singleTag:           singleTag,
 		tagOne:              t1,
 		tagTwo:              t2,
+		branch:              branch,
+		fromTag:             from,
+		toTag:               to,
+		since:               since,
+		until:               until,
+		topN:                topN,
+		interval:            interval,
+		recursive:           recursive,
+		ignoreCase:          ignoreCase,
+		owners:              owners,
+		issues:              issues,
+		releaseFilter: platforms.ReleaseFilterOpts{
+			ExcludePrereleases: excludePre,
+			ExcludeDrafts:      excludeDrafts,
+			SortByPublished:    sortBy == "published",
+		},
+		remote:        remote,
+		byDomain:      byDomain,
+		domainAliases: domainAliases,
 	}
 }
 
 func newProctorOptions(fs *pflag.FlagSet) proctorOpts {
 	ot := resolveOutputType(fs)
+	of, _ := fs.GetString(outputFlag)
+	spec, err := resolveOutputSpec(of)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
 	fko, _ := fs.GetBool(includeKernelFlag)
 	ipi, _ := fs.GetBool(includePermIssueFlag)
 	rc, _ := fs.GetBool(resetCacheFlag)
+	filter, err := newProcessFilter(fs)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	sortBy, _ := fs.GetString(sortFlag)
+	if sortBy == "" {
+		sortBy = "pid"
+	}
+	if _, ok := plib.ProcessSorts[sortBy]; !ok {
+		outputErrorAndFail(fmt.Sprintf("invalid --%s (%s), expected pid, name, rss, cpu, or start", sortFlag, sortBy))
+	}
+	desc, _ := fs.GetBool(descFlag)
+	idsOnly, _ := fs.GetBool(idsFlag)
 
 	return proctorOpts{
 		outType:          ot,
+		outSpec:          spec,
 		includeKernel:    fko,
 		includePermIssue: ipi,
 		resetCache:       rc,
+		filter:           filter,
+		sortBy:           sortBy,
+		sortDesc:         desc,
+		idsOnly:          idsOnly,
+		redactor:         newRedactor(fs),
 	}
 }
 
+// newRedactor returns the redactor masking the default secrets and those
+// matched by the --redaction-rules file.
+func newRedactor(fs *pflag.FlagSet) *plib.Redactor {
+	path, _ := fs.GetString(redactionRulesFlag)
+	if path == "" {
+		return plib.DefaultRedactor
+	}
+	rules, err := plib.LoadRedactionRules(path)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	redactor, err := plib.NewRedactor(rules)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	return redactor
+}
+
+// newProcessFilter returns the filter set by the --filter-name, --user, and
+// --state flags. Users may be given by name or ID.
+func newProcessFilter(fs *pflag.FlagSet) (plib.Filter, error) {
+	f := plib.Filter{}
+	if name, _ := fs.GetString(filterNameFlag); name != "" {
+		re, err := regexp.Compile(name)
+		if err != nil {
+			return f, fmt.Errorf("invalid --%s (%s): %s", filterNameFlag, name, err)
+		}
+		f.Name = re
+	}
+	users, _ := fs.GetStringSlice(userFlag)
+	for _, u := range users {
+		uid, err := strconv.Atoi(u)
+		if err != nil {
+			found, err := user.Lookup(u)
+			if err != nil {
+				return f, fmt.Errorf("failed looking up user for --%s: %s", userFlag, err)
+			}
+			uid, _ = strconv.Atoi(found.Uid)
+		}
+		f.UIDs = append(f.UIDs, uid)
+	}
+	f.States, _ = fs.GetStringSlice(stateFlag)
+	return f, nil
+}
+
 func resolveOutputType(fs *pflag.FlagSet) outputType {
 	of, err := fs.GetString(outputFlag)
 	// default if there are ever issues finding flag
@@ -591,6 +1546,14 @@ func resolveOutputType(fs *pflag.FlagSet) outputType {
 		return jsonOut
 	case "table":
 		return tableOut
+	case "csv":
+		return csvOut
+	}
+	switch {
+	case strings.HasPrefix(of, customColumnsPrefix):
+		return customColumnsOut
+	case strings.HasPrefix(of, goTemplatePrefix), strings.HasPrefix(of, goTemplateFilePrefix):
+		return goTemplateOut
 	}
 
 	// default OutputType
@@ -11,9 +11,22 @@ var proctorCmd = &cobra.Command{
 }
 
 var uiCmd = &cobra.Command{
-	Use:   "ui",
-	Short: "Run the web-based UI",
-	Run:   runUI,
+	Use:     "ui",
+	Aliases: []string{"serve"},
+	Short:   "Run the web-based UI",
+	Run:     runUI,
+}
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Serve this host's processes, and a history of those that started and exited, over an HTTP API. Agents can be shown in the UI with its --agent flag.",
+	Run:   runAgent,
+}
+
+var agentInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a systemd unit running the agent with the agent flags passed to install.",
+	Run:   runAgentInstall,
 }
 
 var processCmd = &cobra.Command{
@@ -23,17 +36,150 @@ var processCmd = &cobra.Command{
 	Run:     runProcess,
 }
 
+var hostCmd = &cobra.Command{
+	Use:   "host",
+	Short: "Introspect the host proctor is running on.",
+	Run:   runHost,
+}
+
+var runtimeSBOMCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "Output a runtime SBOM of the host, describing the binaries of running processes, their hashes, the Go modules they were built with, and the OS packages that installed them.",
+	Run:   runRuntimeSBOM,
+}
+
+var attestCmd = &cobra.Command{
+	Use:   "attest",
+	Short: "Sign fingerprints and snapshots as in-toto attestations in DSSE envelopes, with a key or keyless using sigstore, so they can be verified later.",
+	Run:   runAttest,
+}
+
+var attestFingerprintCmd = &cobra.Command{
+	Use:   "fingerprint [pid]",
+	Short: "Output a signed attestation of a process's fingerprint, whose subjects are the binaries of its lineage.",
+	Run:   runAttestFingerprint,
+}
+
+var attestSnapshotCmd = &cobra.Command{
+	Use:   "snapshot [snapshot]",
+	Short: "Output a signed attestation of the live processes, or of a snapshot saved with `process ls -o json`, whose subjects are their binaries.",
+	Run:   runAttestSnapshot,
+}
+
+var verifyAttestationCmd = &cobra.Command{
+	Use:   "verify-attestation [attestation]",
+	Short: "Verify the signature, certificate, and transparency log entry of an attestation created by `proctor attest`.",
+	Run:   runVerifyAttestation,
+}
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Evaluate processes against rules, such as no processes running from /tmp.",
+	Run:   runPolicy,
+}
+
+var policyEvalCmd = &cobra.Command{
+	Use:   "eval --file RULES",
+	Short: "Report the processes violating the rules in a YAML file. Exits 2 when processes violate rules of at least the --fail-on severity.",
+	Run:   runPolicyEval,
+}
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage plugins, executables in the plugins directory that enrich processes with details such as their CMDB owner, or send processes to systems such as Splunk.",
+	Run:   runPlugin,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the plugins discovered in the plugins directory and their kinds.",
+	Run:     runPluginList,
+}
+
+var pluginOutputCmd = &cobra.Command{
+	Use:   "output [plugin]",
+	Short: "Send the live processes, optionally annotated by --enrich plugins, to an output plugin.",
+	Run:   runPluginOutput,
+}
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Record the binaries approved for hosts of a role, such as web or db, and check hosts for drift from them.",
+	Run:   runBaseline,
+}
+
+var baselineRecordCmd = &cobra.Command{
+	Use:   "record --role ROLE",
+	Short: "Record the binaries running now as the approved and expected binaries of a role.",
+	Run:   runBaselineRecord,
+}
+
+var baselineUpdateCmd = &cobra.Command{
+	Use:   "update --role ROLE",
+	Short: "Approve the binaries running now, such as after an upgrade, keeping those already approved.",
+	Run:   runBaselineUpdate,
+}
+
+var baselineCheckCmd = &cobra.Command{
+	Use:   "check --role ROLE",
+	Short: "Report processes running unapproved or changed binaries and expected binaries that aren't running. Exits 2 on drift.",
+	Run:   runBaselineCheck,
+}
+
+var baselineListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the recorded baselines.",
+	Run:     runBaselineList,
+}
+
+var baselineDeleteCmd = &cobra.Command{
+	Use:     "delete --role ROLE",
+	Aliases: []string{"rm"},
+	Short:   "Delete the baseline of a role.",
+	Run:     runBaselineDelete,
+}
+
+var hostInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Retrieves all known details about the host.",
+	Run:   runHostInfo,
+}
+
+var hostIDCmd = &cobra.Command{
+	Use:   "id",
+	Short: "Retrieves the unique identifier of the host.",
+	Run:   runHostID,
+}
+
+var hostHardwareCmd = &cobra.Command{
+	Use:     "hardware",
+	Aliases: []string{"hw"},
+	Short:   "Retrieves hardware details about the host.",
+	Run:     runHostHardware,
+}
+
+var hostContainersCmd = &cobra.Command{
+	Use:   "containers",
+	Short: "Lists the containers running on the host, through the Docker or Podman API. containerd's own API isn't supported.",
+	Run:   runHostContainers,
+}
+
 var sourceCmd = &cobra.Command{
 	Use:     "source",
 	Aliases: []string{"src"},
 	Short:   "Introspect source repositories.",
 	Run:     runSource,
+	// applies flags shared by every source command.
+	PersistentPreRun: setupSource,
 }
 
 var commitCmd = &cobra.Command{
-	Use:   "commits",
-	Short: "Access commit details within a repository.",
-	Run:   runContrib,
+	Use:     "commits",
+	Aliases: []string{"contrib"},
+	Short:   "Access commit details within a repository.",
+	Run:     runContrib,
 }
 
 var artifactsCmd = &cobra.Command{
@@ -44,31 +190,117 @@ var artifactsCmd = &cobra.Command{
 }
 
 var artifactsListCmd = &cobra.Command{
-	Use:     "list",
+	Use:     "list [repo]",
 	Aliases: []string{"ls"},
 	Short:   "Lists all artifacts in a given repository",
 	Run:     runListArtifacts,
 }
 
 var artifactsGetCmd = &cobra.Command{
-	Use:   "get",
+	Use:   "get [repo]",
 	Short: "Gets all artifacts for a tag, using the --tag flag.",
 	Run:   runGetArtifacts,
 }
 
+var artifactsVerifyCmd = &cobra.Command{
+	Use:   "verify [repo]",
+	Short: "Verify the cosign signature of a release artifact, using the --tag and --artifact flags.",
+	Run:   runVerifyArtifact,
+}
+
+var artifactsDiffCmd = &cobra.Command{
+	Use:   "diff [repo]",
+	Short: "Report the artifacts added, removed, renamed, or changed between two releases, using the --tag1 and --tag2 flags.",
+	Run:   runDiffArtifacts,
+}
+
+var artifactsDownloadCmd = &cobra.Command{
+	Use:   "download [repo]",
+	Short: "Download the artifacts of a release, using the --tag flag, verifying them against the release's checksums file.",
+	Run:   runDownloadArtifacts,
+}
+
 var contribListCmd = &cobra.Command{
-	Use:     "list",
+	Use:     "list [repo]",
 	Aliases: []string{"ls"},
 	Short:   "List all contributions that have occured in this repository.",
 	Run:     runContribList,
 }
 
 var contribDiffCmd = &cobra.Command{
-	Use:   "diff",
-	Short: "Retrieve the contribution differences between two tags.",
+	Use:   "diff [repo]",
+	Short: "Retrieve the contribution differences between two tags, set with --tag1 and --tag2.",
 	Run:   runDiffSource,
 }
 
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes [repo]",
+	Short: "Generate Markdown release notes from the commits between two tags.",
+	Run:   runReleaseNotes,
+}
+
+var contribStatsCmd = &cobra.Command{
+	Use:   "stats [repo]",
+	Short: "Summarize contributors, including active contributors and bus factor, over a time window.",
+	Run:   runContribStats,
+}
+
+var contribActivityCmd = &cobra.Command{
+	Use:   "activity [repo]",
+	Short: "Show commit and author counts per week or month.",
+	Run:   runContribActivity,
+}
+
+var contribGrepCmd = &cobra.Command{
+	Use:   "grep [repo] [pattern]",
+	Short: "Find commits whose message matches a regular expression, such as CVE- or \"security fix\".",
+	Run:   runContribGrep,
+}
+
+var healthCmd = &cobra.Command{
+	Use:   "health [repo]",
+	Short: "Report how actively a repository is maintained, including commit recency and release cadence.",
+	Run:   runHealth,
+}
+
+var submodulesCmd = &cobra.Command{
+	Use:   "submodules [repo]",
+	Short: "List the submodules of a repository and the commits they pin.",
+	Run:   runSubmodules,
+}
+
+var dependenciesCmd = &cobra.Command{
+	Use:     "dependencies [repo]",
+	Aliases: []string{"deps"},
+	Short:   "List the dependencies declared in a repository's manifests (go.mod, package.json, requirements.txt).",
+	Run:     runDependencies,
+}
+
+var dependenciesDiffCmd = &cobra.Command{
+	Use:   "diff [repo]",
+	Short: "Show the dependencies added, removed, or changed between two tags.",
+	Run:   runDependenciesDiff,
+}
+
+var dependenciesVulnsCmd = &cobra.Command{
+	Use:     "vulns [repo]",
+	Aliases: []string{"vulnerabilities"},
+	Short:   "List the published vulnerabilities, from OSV.dev, affecting the pinned dependencies of a repository.",
+	Run:     runDependenciesVulns,
+}
+
+var advisoriesCmd = &cobra.Command{
+	Use:   "advisories [repo]",
+	Short: "List the security advisories published for a repository and the releases they affect.",
+	Run:   runAdvisories,
+}
+
+var sbomCmd = &cobra.Command{
+	Use:   "sbom [repo]",
+	Short: "Output an SPDX SBOM of a repository's dependencies, generated by the platform hosting it (e.g. GitHub's dependency graph), without cloning or building it.",
+	Run:   runSBOM,
+}
+
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
@@ -84,13 +316,117 @@ var getCmd = &cobra.Command{
 
 var treeCmd = &cobra.Command{
 	Use:   "tree [pid]",
-	Short: "Retrieve a process and all its relatives. Takes a process ID.",
+	Short: "Retrieve a process and all its relatives. Takes a process ID, or --name for every process with that name.",
 	Run:   runTreeProcess,
 }
 
+var envCmd = &cobra.Command{
+	Use:   "env [pid]",
+	Short: "Retrieve the environment variables a process was started with. Takes a process ID.",
+	Run:   runProcessEnv,
+}
+
+var portsCmd = &cobra.Command{
+	Use:   "ports [pid]",
+	Short: "List the sockets a process is listening on or connected with. Takes a process ID, or --all for every process.",
+	Run:   runProcessPorts,
+}
+
+var processArtifactCmd = &cobra.Command{
+	Use:     "artifact [pid] [repo]",
+	Aliases: []string{"match"},
+	Short:   "Identify the release artifact a process's binary came from. The repo defaults to the one a Go binary was built from.",
+	Run:     runProcessArtifact,
+}
+
+var statCmd = &cobra.Command{
+	Use:   "stat [pid]",
+	Short: "Print every field of a process's stat file (/proc/[pid]/stat) with its name and a readable value.",
+	Run:   runStatProcess,
+}
+
+var hashCmd = &cobra.Command{
+	Use:   "hash [path...]",
+	Short: "Print the SHA256 of files, as recorded for process binaries, optionally matching them to release artifacts with --repo.",
+	Run:   runProcessHash,
+}
+
+var provenanceCmd = &cobra.Command{
+	Use:     "provenance [pid]",
+	Aliases: []string{"prov"},
+	Short:   "Resolve the repository, commit, and tag a process's (Go) binary was built from.",
+	Run:     runProcessProvenance,
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [snapshot] [snapshot]",
+	Short: "Compare snapshots saved with `process ls -o json`, or a snapshot and the live processes with --live. Exits 2 when processes were added or their binaries changed.",
+	Run:   runProcessDiff,
+}
+
+var killCmd = &cobra.Command{
+	Use:   "kill --name PATTERN",
+	Short: "Send a signal to every process whose name matches a regular expression, optionally only those running a binary with a given SHA256.",
+	Run:   runKillProcesses,
+}
+
+var vulnsCmd = &cobra.Command{
+	Use:   "vulns",
+	Short: "Report the running processes executing Go modules or OS packages affected by vulnerabilities published in OSV. Exits 2 when vulnerabilities are found.",
+	Run:   runProcessVulns,
+}
+
+var reputationCmd = &cobra.Command{
+	Use:   "reputation",
+	Short: "Classify the binaries of running processes as known-good, unknown, or flagged using allow and deny lists, NSRL hash sets, and VirusTotal. Exits 2 when a binary is flagged.",
+	Run:   runProcessReputation,
+}
+
+var layersCmd = &cobra.Command{
+	Use:   "layers [PID...]",
+	Short: "Show the image layer that provided the binary of each process running in a container, or whether the binary was added at runtime. Without PIDs, every containerized process is shown.",
+	Run:   runProcessLayers,
+}
+
 var fpCmd = &cobra.Command{
 	Use:     "finger-print",
 	Aliases: []string{"fp"},
-	Short:   "Provides a unique checksum representing the process's binary and its parents' binaries combined.",
+	Short:   "Provides a unique checksum representing the process's binary and its parents' binaries combined. Takes a process ID, or --all for every process.",
 	Run:     runFingerPrintProcess,
 }
+
+var fpSaveCmd = &cobra.Command{
+	Use:   "save [pid]",
+	Short: "Save a process's finger print as a named baseline. Takes a process ID and --name.",
+	Run:   runFingerPrintSave,
+}
+
+var fpVerifyCmd = &cobra.Command{
+	Use:   "verify [pid]",
+	Short: "Verify a process's finger print matches a saved baseline, exiting non-zero if it has drifted. Takes a process ID and --against.",
+	Run:   runFingerPrintVerify,
+}
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle [PID...]",
+	Short: "Package a snapshot of the host's processes, their fingerprints, the host's details, procfs files of the processes passed and their ancestors, and proctor's version into a compressed archive with a manifest and digest, for attaching to incident tickets.",
+	Run:   runBundle,
+}
+
+var bundleVerifyCmd = &cobra.Command{
+	Use:   "verify [archive]",
+	Short: "Verify a bundle's contents match its manifest, and the archive matches the digest written alongside it, exiting non-zero if they don't.",
+	Run:   runBundleVerify,
+}
+
+var fleetCmd = &cobra.Command{
+	Use:   "fleet",
+	Short: "Aggregate the snapshots of many hosts, from their agents or files, into fleet-wide views.",
+	Run:   runFleet,
+}
+
+var fleetReportCmd = &cobra.Command{
+	Use:   "report [source...]",
+	Short: "Report which versions of each binary run on which hosts, hosts running a version of a binary most hosts don't, and hosts missing a binary most hosts run. Takes the URLs of agents (e.g. http://web-1:8080) and snapshot files saved with `process ls -o json` or from an agent.",
+	Run:   runFleetReport,
+}
@@ -1,10 +1,27 @@
 package cmd
 
+import (
+	"github.com/arctir/proctor/agent"
+	"github.com/arctir/proctor/cosign"
+	"github.com/arctir/proctor/fleet"
+	"github.com/arctir/proctor/kube"
+	"github.com/arctir/proctor/logging"
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/policy"
+	"github.com/arctir/proctor/source"
+	"github.com/arctir/proctor/ui"
+)
+
 type outputType int
 
 const (
 	jsonOut outputType = iota
 	tableOut
+	csvOut
+	// kubectl style formats, whose specification follows the type in the
+	// --output flag.
+	customColumnsOut
+	goTemplateOut
 )
 
 const (
@@ -18,31 +35,268 @@ const (
 	resetCacheFlag       = "reset-cache"
 	nameFlag             = "name"
 	idFlag               = "id"
+	rootFSFlag           = "root"
+	branchFlag           = "branch"
+	fromFlag             = "from"
+	toFlag               = "to"
+	sinceFlag            = "since"
+	untilFlag            = "until"
+	topFlag              = "top"
+	intervalFlag         = "interval"
+	recursiveFlag        = "recursive"
+	timeoutFlag          = "timeout"
+	ignoreCaseFlag       = "ignore-case"
+	ownersFlag           = "owners"
+	artifactFlag         = "artifact"
+	keyFlag              = "key"
+	rootsFlag            = "roots"
+	certIdentityFlag     = "certificate-identity"
+	certOIDCIssuerFlag   = "certificate-oidc-issuer"
+	sourceHostFlag       = "source-host"
+	sourceTimeoutFlag    = "source-timeout"
+	skipTlogFlag         = "skip-tlog"
+	issuesFlag           = "issues"
+	noCacheFlag          = "no-cache"
+	excludePreFlag       = "exclude-prereleases"
+	excludeDraftsFlag    = "exclude-drafts"
+	sortFlag             = "sort"
+	remoteFlag           = "remote"
+	addressFlag          = "address"
+	portFlag             = "port"
+	tlsCertFlag          = "tls-cert"
+	tlsKeyFlag           = "tls-key"
+	selfSignedFlag       = "self-signed"
+	agentFlag            = "agent"
+	actionTokenFileFlag  = "action-token-file"
+	noAccessLogFlag      = "no-access-log"
+	themeDirFlag         = "theme-dir"
+	scanIntervalFlag     = "scan-interval"
+	watchFlag            = "watch"
+	watchIntervalFlag    = "interval"
+	descFlag             = "desc"
+	filterNameFlag       = "filter-name"
+	userFlag             = "user"
+	stateFlag            = "state"
+	childrenFlag         = "children"
+	redactFlag           = "redact"
+	allFlag              = "all"
+	againstFlag          = "against"
+	baselineDirFlag      = "baseline-dir"
+	clientCAFlag         = "client-ca"
+	retentionFlag        = "retention"
+	unitPathFlag         = "unit-path"
+	printFlag            = "print"
+	byDomainFlag         = "by-domain"
+	domainAliasFlag      = "domain-alias"
+	dirFlag              = "dir"
+	requireChecksumFlag  = "require-checksum"
+	verifySignatureFlag  = "verify-signature"
+	verboseFlag          = "verbose"
+	quietFlag            = "quiet"
+	idsFlag              = "ids"
+	logFormatFlag        = "log-format"
+	repoFlag             = "repo"
+	schemaFlag           = "schema"
+	liveFlag             = "live"
+	matchByFlag          = "match-by"
+	signalFlag           = "signal"
+	yesFlag              = "yes"
+	shaFlag              = "sha"
+	fileFlag             = "file"
+	failOnFlag           = "fail-on"
+	roleFlag             = "role"
+	forceFlag            = "force"
+	baselineRoleFlag     = "baseline-role"
+	roleDirFlag          = "role-dir"
+	caFlag               = "ca"
+	agentCAFlag          = "agent-ca"
+	agentCertFlag        = "agent-cert"
+	agentKeyFlag         = "agent-key"
+	certFlag             = "cert"
+	sbomFormatFlag       = "format"
+	allPackagesFlag      = "all-packages"
+	identityTokenFlag    = "identity-token"
+	fulcioURLFlag        = "fulcio-url"
+	rekorURLFlag         = "rekor-url"
+	severityFlag         = "severity"
+	allowlistFlag        = "allowlist"
+	denylistFlag         = "denylist"
+	nsrlFlag             = "nsrl"
+	virusTotalKeyFlag    = "virustotal-key"
+	minDetectionsFlag    = "min-detections"
+	alertWebhookFlag     = "alert-webhook"
+	alertHeaderFlag      = "alert-header"
+	alertHeaderFileFlag  = "alert-header-file"
+	alertExecFlag        = "alert-exec"
+	alertOnFlag          = "alert-on"
+	policyFlag           = "policy"
+	seenBinariesFlag     = "seen-binaries"
+	enrichFlag           = "enrich"
+	pluginDirFlag        = "plugin-dir"
+	procfsFlag           = "procfs"
+	kubernetesFlag       = "kubernetes"
+	nodeNameFlag         = "node-name"
+	labelFlag            = "label"
+	redactionRulesFlag   = "redaction-rules"
+	expectFlag           = "expect"
+	expectedRatioFlag    = "expected-ratio"
 )
 
 type proctorOpts struct {
-	outType          outputType
+	outType outputType
+	// the columns or template of a custom-columns or go-template outType.
+	outSpec          string
 	includeKernel    bool
 	includePermIssue bool
 	resetCache       bool
+	// the processes to list, set by the --filter-name, --user, and --state
+	// flags.
+	filter plib.Filter
+	// the name of the plib.ProcessSorts order listed processes are in.
+	sortBy   string
+	sortDesc bool
+	// whether only process IDs are output, set by the --quiet flag of
+	// commands listing processes.
+	idsOnly bool
+	// masks secrets in the processes and environments read, set by the
+	// --redaction-rules flag. When nil, plib.DefaultRedactor is used.
+	redactor *plib.Redactor
 }
 
 // CLI flags to intialize
 func init() {
+	// logging
+	proctorCmd.PersistentFlags().BoolP(verboseFlag, "v", false, "Log debug details, such as caches read and repositories cloned, to stderr.")
+	proctorCmd.PersistentFlags().BoolP(quietFlag, "q", false, "Only log errors to stderr.")
+	proctorCmd.PersistentFlags().String(logFormatFlag, logging.TextFormat, "The format logs are written to stderr in [text (default), json].")
+
+	// redaction
+	proctorCmd.PersistentFlags().String(redactionRulesFlag, "", "A YAML file of keys and values patterns matching secrets to mask in command lines and environment variables, in addition to the defaults, before they're cached, printed, exported, or served.")
+
 	// output
-	getCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
-	listCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
-	treeCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	getCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json, custom-columns=SPEC, go-template=TEMPLATE, go-template-file=PATH]. e.g. custom-columns=PID:.ID,NAME:.CommandName,SHA:.BinarySHA")
+	listCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json, csv, custom-columns=SPEC, go-template=TEMPLATE, go-template-file=PATH]. e.g. custom-columns=PID:.ID,NAME:.CommandName,SHA:.BinarySHA")
+	treeCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json, custom-columns=SPEC, go-template=TEMPLATE, go-template-file=PATH]. e.g. custom-columns=PID:.ID,NAME:.CommandName,SHA:.BinarySHA")
+	hostInfoCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	hostIDCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	hostHardwareCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	processArtifactCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	processArtifactCmd.Flags().Bool(noCacheFlag, false, "Don't use or store cached responses from platforms such as GitHub.")
+	statCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	hashCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	diffCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	diffCmd.Flags().Bool(liveFlag, false, "Compare the snapshot against the processes running now, rather than a second snapshot.")
+	diffCmd.Flags().String(matchByFlag, matchByPath, "How processes are matched between snapshots [path (default), pid]. Matching by the path of their binary allows comparing snapshots taken across reboots.")
+	diffCmd.Flags().Bool(includeKernelFlag, false, "Include kernel processes in the live processes, default is false.")
+	diffCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues in the live processes.")
+	killCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	killCmd.Flags().String(nameFlag, "", "Signal the processes whose name matches this regular expression, e.g. ^nginx$.")
+	killCmd.Flags().StringP(signalFlag, "s", "SIGTERM", "The signal to send [SIGTERM (default), SIGINT, SIGHUP, SIGKILL]. The SIG prefix is optional.")
+	killCmd.Flags().BoolP(yesFlag, "y", false, "Signal the matched processes without asking for confirmation.")
+	killCmd.Flags().String(shaFlag, "", "Only signal processes whose binary has this SHA256. Processes matching --name but running another binary are reported and skipped.")
+	killCmd.Flags().StringSlice(userFlag, nil, "Only signal processes owned by this user name or ID. Repeat or comma separate for multiple users.")
+	killCmd.Flags().StringSlice(stateFlag, nil, "Only signal processes in this state, such as R (running) or S (sleeping). Repeat or comma separate for multiple states.")
+	policyEvalCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	policyEvalCmd.Flags().StringP(fileFlag, "f", "", "The YAML file of rules to evaluate.")
+	policyEvalCmd.Flags().String(failOnFlag, policy.SeverityLow, "Exit 2 when a rule of at least this severity is violated [low (default), medium, high, critical].")
+	policyEvalCmd.Flags().Bool(includeKernelFlag, false, "Include kernel processes in the evaluated processes, default is false.")
+	policyEvalCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues in the evaluated processes.")
+	runtimeSBOMCmd.Flags().String(sbomFormatFlag, spdxFormat, "The format of the SBOM [spdx (default), cyclonedx].")
+	runtimeSBOMCmd.Flags().Bool(allPackagesFlag, false, "Include every installed OS package, rather than only those that installed a running binary.")
+	vulnsCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	vulnsCmd.Flags().String(severityFlag, "", "Only report vulnerabilities of at least this severity [low, moderate, high, critical]. Vulnerabilities whose severity isn't published, such as those of the Go vulnerability database, are always reported.")
+	vulnsCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues.")
+	reputationCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	reputationCmd.Flags().StringSlice(allowlistFlag, nil, "A file of known-good SHA256s, one per line as written by sha256sum. Repeat for multiple files.")
+	reputationCmd.Flags().StringSlice(denylistFlag, nil, "A file of SHA256s to flag, one per line as written by sha256sum. Repeat for multiple files. Denylists are consulted before every other source.")
+	reputationCmd.Flags().String(nsrlFlag, "", "An NSRL hash set of known software, as a CSV export of the Reference Data Set with a sha256 column or a file of SHA256s.")
+	reputationCmd.Flags().String(virusTotalKeyFlag, "", "Look binaries unknown to the other sources up in VirusTotal with this API key. Defaults to $VT_API_KEY.")
+	reputationCmd.Flags().Int(minDetectionsFlag, 1, "The number of VirusTotal engines that must detect a binary as malicious to flag it. Binaries with fewer malicious or suspicious detections are reported as unknown, not known-good.")
+	reputationCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues.")
+	layersCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	bundleCmd.Flags().StringP(fileFlag, "f", "", "Where to write the bundle. Defaults to proctor-bundle-HOSTNAME-TIME.tar.gz in the current directory. Its SHA256 is written alongside it, with a .sha256 suffix.")
+	bundleCmd.Flags().Bool(includeKernelFlag, false, "Include kernel processes in the snapshot, default is false.")
+	bundleCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues in the snapshot.")
+	bundleVerifyCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	fleetReportCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json]. Only the JSON output includes the versions of every binary.")
+	fleetReportCmd.Flags().StringSlice(expectFlag, nil, "The name of a binary, such as sshd, every host is expected to run. Repeat or comma separate for multiple binaries.")
+	fleetReportCmd.Flags().Float64(expectedRatioFlag, fleet.DefaultExpectedRatio, "The share of hosts, from 0 to 1, a binary must run on for the other hosts to be reported missing it. Set above 1 to only report the --expect binaries.")
+	fleetReportCmd.Flags().Duration(timeoutFlag, fleet.DefaultTimeout, "How long each agent is given to respond.")
+	fleetReportCmd.Flags().String(caFlag, "", "Verify agents served over TLS with these PEM encoded CA certificates rather than the system's.")
+	fleetReportCmd.Flags().String(certFlag, "", "Present this PEM encoded client certificate to agents requiring one (mutual TLS). Requires --key.")
+	fleetReportCmd.Flags().String(keyFlag, "", "The PEM encoded key of the --cert certificate.")
+	pluginCmd.PersistentFlags().String(pluginDirFlag, "", "The directory plugins are discovered in. Defaults to $XDG_DATA_HOME/proctor/plugins.")
+	pluginListCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	pluginOutputCmd.Flags().StringSlice(enrichFlag, nil, "Annotate the processes with these enricher plugins, in order, before sending them. Repeat or comma separate for multiple plugins.")
+	pluginOutputCmd.Flags().Bool(includeKernelFlag, false, "Include kernel processes in the processes sent, default is false.")
+	pluginOutputCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues in the processes sent.")
+	listCmd.Flags().StringSlice(enrichFlag, nil, "Annotate processes with these enricher plugins, in order. Annotations are shown in the JSON output, and custom-columns such as OWNER:.Annotations.owner. Repeat or comma separate for multiple plugins.")
+	listCmd.Flags().String(pluginDirFlag, "", "The directory --enrich plugins are discovered in. Defaults to $XDG_DATA_HOME/proctor/plugins.")
+	attestCmd.PersistentFlags().String(keyFlag, "", "Sign with this PEM encoded private key, such as the cosign.key written by \"cosign generate-key-pair\", rather than keyless. Encrypted keys are decrypted with $COSIGN_PASSWORD.")
+	attestCmd.PersistentFlags().String(identityTokenFlag, "", "The OIDC identity token used to sign keyless, which Fulcio issues a short-lived certificate for. Defaults to $SIGSTORE_ID_TOKEN.")
+	attestCmd.PersistentFlags().String(fulcioURLFlag, cosign.DefaultFulcioURL, "The Fulcio instance to request keyless signing certificates from.")
+	attestCmd.PersistentFlags().String(rekorURLFlag, cosign.DefaultRekorURL, "The Rekor instance to record the signature in.")
+	attestCmd.PersistentFlags().Bool(skipTlogFlag, false, "Skip recording the signature in the transparency log.")
+	attestSnapshotCmd.Flags().Bool(includeKernelFlag, false, "Include kernel processes in the snapshot of the live processes, default is false.")
+	attestSnapshotCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues in the snapshot of the live processes.")
+	verifyAttestationCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	verifyAttestationCmd.Flags().String(keyFlag, "", "Verify with this PEM encoded public key rather than the signature's certificate.")
+	verifyAttestationCmd.Flags().String(rootsFlag, "", "Verify the signing certificate chains to the PEM encoded certificates in this file (e.g. the Fulcio roots). Required to verify keyless attestations.")
+	verifyAttestationCmd.Flags().String(certIdentityFlag, "", "The identity a keyless attestation's certificate must be issued to, such as an email address or CI workflow URI. Required to verify keyless attestations.")
+	verifyAttestationCmd.Flags().String(certOIDCIssuerFlag, "", "The OIDC issuer that must have authenticated a keyless signer, such as https://token.actions.githubusercontent.com. Required to verify keyless attestations.")
+	verifyAttestationCmd.Flags().Bool(skipTlogFlag, false, "Skip verifying the signature's transparency log entry.")
+	verifyAttestationCmd.Flags().String(rekorURLFlag, cosign.DefaultRekorURL, "The Rekor instance to look up the signature's transparency log entry in, when the attestation doesn't include it.")
+	baselineCmd.PersistentFlags().String(roleDirFlag, "", "The directory role baselines are saved in. Defaults to $XDG_DATA_HOME/proctor/roles.")
+	baselineRecordCmd.Flags().String(roleFlag, "", "The role of the hosts the baseline is for, such as web or db.")
+	baselineUpdateCmd.Flags().String(roleFlag, "", "The role of the hosts the baseline is for, such as web or db.")
+	baselineCheckCmd.Flags().String(roleFlag, "", "The role of the hosts the baseline is for, such as web or db.")
+	baselineDeleteCmd.Flags().String(roleFlag, "", "The role of the hosts the baseline is for, such as web or db.")
+	baselineRecordCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues.")
+	baselineUpdateCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues.")
+	baselineCheckCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues.")
+	baselineRecordCmd.Flags().Bool(forceFlag, false, "Replace the baseline of the role if it exists.")
+	baselineRecordCmd.Flags().StringSlice(expectFlag, nil, "The path of a binary, such as /usr/sbin/sshd, hosts of the role must always run. Repeat or comma separate for multiple binaries. Defaults to the binaries of init and the services it started.")
+	baselineCheckCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	baselineListCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	hashCmd.Flags().Bool(noCacheFlag, false, "Don't use or store cached responses from platforms such as GitHub.")
+	hashCmd.Flags().String(repoFlag, "", "Compare each file's SHA256 with the digests of this repository's release artifacts (e.g. https://github.com/arctir/proctor), exiting non-zero if any file doesn't match.")
+	provenanceCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	envCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	portsCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	hostContainersCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
 
 	// cache-reset
+	listCmd.Flags().BoolP(watchFlag, "w", false, "Keep listing processes, updating the table as processes start and exit. With --output json, a JSON line is streamed for each started or exited process.")
+	listCmd.Flags().Duration(watchIntervalFlag, plib.DefaultWatchInterval, "The time between process lookups with --watch.")
+	listCmd.Flags().String(sortFlag, "pid", "The order processes are listed in [pid (default), name, rss, cpu, start].")
+	listCmd.Flags().Bool(descFlag, false, "List processes in descending order of --sort.")
+	listCmd.Flags().String(filterNameFlag, "", "Only list processes whose name matches this regular expression.")
+	listCmd.Flags().StringSlice(userFlag, nil, "Only list processes owned by this user name or ID. Repeat or comma separate for multiple users.")
+	listCmd.Flags().StringSlice(stateFlag, nil, "Only list processes in this state, such as R (running), S (sleeping), or Z (zombie). Repeat or comma separate for multiple states.")
+	listCmd.Flags().Bool(idsFlag, false, "Only print process IDs, one per line, for piping to tools such as xargs. Overrides --output.")
+	getCmd.Flags().Bool(idsFlag, false, "Only print process IDs, one per line, for piping to tools such as xargs. Overrides --output.")
 	listCmd.Flags().Bool(resetCacheFlag, false, "Refreshs the cache, making this call read all its data from the OS, replenish the cache, and output.")
 	getCmd.Flags().Bool(resetCacheFlag, false, "Refreshs the cache, making this call read all its data from the OS, replenish the cache, and output.")
+	treeCmd.Flags().String(nameFlag, "", "Retrieve the tree of every process with this name rather than a single process ID.")
+	statCmd.Flags().Bool(resetCacheFlag, false, "Refreshs the cache, making this call read all its data from the OS, replenish the cache, and output.")
 	treeCmd.Flags().Bool(resetCacheFlag, false, "Refreshs the cache, making this call read all its data from the OS, replenish the cache, and output.")
 	fpCmd.Flags().Bool(resetCacheFlag, false, "Refreshs the cache, making this call read all its data from the OS, replenish the cache, and output.")
+	fpSaveCmd.Flags().Bool(resetCacheFlag, false, "Refreshs the cache, making this call read all its data from the OS, replenish the cache, and output.")
+	fpVerifyCmd.Flags().Bool(resetCacheFlag, false, "Refreshs the cache, making this call read all its data from the OS, replenish the cache, and output.")
+	portsCmd.Flags().Bool(resetCacheFlag, false, "Refreshs the cache, making this call read all its data from the OS, replenish the cache, and output.")
 
 	// kernel filter
 	getCmd.Flags().Bool(includeKernelFlag, false, "Include kernel processes in out, default is false.")
 	listCmd.Flags().Bool(includeKernelFlag, false, "Include kernel processes in out, default is false.")
+	envCmd.Flags().Bool(redactFlag, true, "Mask the values of variables whose keys look like they hold secrets, such as *_TOKEN or *_PASSWORD, and values that look like secrets. Pass --redact=false to show them.")
+	portsCmd.Flags().Bool(allFlag, false, "List the ports of every process rather than a single process.")
+	fpSaveCmd.Flags().String(nameFlag, "", "The name to save the process's fingerprint as, to verify processes against later.")
+	fpVerifyCmd.Flags().String(againstFlag, "", "The name of the saved baseline to verify the process's fingerprint against.")
+	fpVerifyCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json].")
+	fpCmd.Flags().Bool(allFlag, false, "Report the finger print of every process rather than a single process.")
+	fpCmd.Flags().StringP(outputFlag, "o", "table", "Output type for --all [table (default), json].")
+	fpCmd.PersistentFlags().String(baselineDirFlag, "", "The directory baselines are saved in. Defaults to $XDG_DATA_HOME/proctor/baselines.")
+	treeCmd.Flags().Bool(childrenFlag, false, "Include the process's descendants below it in the tree.")
 	treeCmd.Flags().Bool(includeKernelFlag, false, "Include kernel processes in out, default is false.")
 
 	// permission filter
@@ -50,15 +304,121 @@ func init() {
 	treeCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues.")
 	getCmd.Flags().Bool(includePermIssueFlag, false, "Include processes that proctor failed to introspect due to permission issues.")
 
+	// ui flags
+	uiCmd.Flags().String(addressFlag, "", "The address to bind the UI to. Binds to all interfaces by default.")
+	uiCmd.Flags().IntP(portFlag, "p", ui.DefaultPort, "The port to serve the UI on.")
+	uiCmd.Flags().String(tlsCertFlag, "", "Serve the UI over TLS with this PEM encoded certificate. Requires --tls-key.")
+	uiCmd.Flags().String(tlsKeyFlag, "", "The PEM encoded key of the --tls-cert certificate.")
+	uiCmd.Flags().StringSlice(agentFlag, nil, "The URL of another host's proctor UI or agent (e.g. http://db-1:8080) to show on the hosts page. Repeat for each host.")
+	uiCmd.Flags().String(agentCAFlag, "", "Verify --agent hosts served over TLS with these PEM encoded CA certificates rather than the system's.")
+	uiCmd.Flags().String(agentCertFlag, "", "Present this PEM encoded client certificate to --agent hosts requiring one (mutual TLS), as agents served off loopback do. Requires --agent-key.")
+	uiCmd.Flags().String(agentKeyFlag, "", "The PEM encoded key of the --agent-cert certificate.")
+	uiCmd.Flags().String(actionTokenFileFlag, "", "Enable sending SIGTERM and SIGKILL to processes from the UI, authenticated by entering the token in this file, which must not be accessible to other users (e.g. mode 0600). Defaults to $PROCTOR_ACTION_TOKEN. Actions are logged, and require TLS unless --address is a loopback address.")
+	uiCmd.Flags().String(themeDirFlag, "", "A directory of templates and static files, laid out as templates/*.html and static/*, overriding the built-in ones.")
+	uiCmd.Flags().Duration(scanIntervalFlag, 0, "The time between scans of the host's processes, which update the live process table and CPU utilization. Defaults to 5s.")
+	uiCmd.Flags().Bool(noAccessLogFlag, false, "Don't write a JSON line to stderr for every request served.")
+	uiCmd.Flags().String(baselineRoleFlag, "", "Show how the host's processes drift from the baseline of this role, recorded with \"proctor baseline record\", on the drift page.")
+	uiCmd.Flags().String(roleDirFlag, "", "The directory role baselines are saved in. Defaults to $XDG_DATA_HOME/proctor/roles.")
+	uiCmd.Flags().StringSlice(sourceHostFlag, nil, "Enable browsing the repositories on this host (e.g. github.com) on the source pages, which clone them over HTTPS. Repeat for each host. The source pages are disabled by default.")
+	uiCmd.Flags().Bool(noCacheFlag, false, "Don't use or store cached responses from platforms such as GitHub.")
+	uiCmd.Flags().Duration(sourceTimeoutFlag, ui.DefaultSourceTimeout, "The maximum amount of time the source pages spend cloning or fetching a repository.")
+	uiCmd.Flags().Bool(selfSignedFlag, false, "Serve the UI over TLS with a self-signed certificate generated at startup, when --tls-cert isn't set.")
+	agentCmd.PersistentFlags().String(addressFlag, agent.DefaultAddress, "The address, in host:port form, to serve the agent's API on. The default is only reachable from the host; serve other interfaces with --tls-cert and --client-ca.")
+	agentCmd.PersistentFlags().String(baselineRoleFlag, "", "Serve how the host's processes drift from the baseline of this role, recorded with \"proctor baseline record\", on /api/drift.")
+	agentCmd.PersistentFlags().String(roleDirFlag, "", "The directory role baselines are saved in. Defaults to $XDG_DATA_HOME/proctor/roles.")
+	agentCmd.PersistentFlags().String(tlsCertFlag, "", "Serve the agent over TLS with this PEM encoded certificate. Requires --tls-key.")
+	agentCmd.PersistentFlags().String(tlsKeyFlag, "", "The PEM encoded key of the --tls-cert certificate.")
+	agentCmd.PersistentFlags().String(clientCAFlag, "", "Require clients to present a certificate signed by these PEM encoded CA certificates (mutual TLS). Requires --tls-cert.")
+	agentCmd.PersistentFlags().Duration(scanIntervalFlag, plib.DefaultWatchInterval, "The time between scans of the host's processes.")
+	agentCmd.PersistentFlags().Duration(retentionFlag, agent.DefaultRetention, "How long the processes that started and exited are kept in the agent's history.")
+	agentCmd.PersistentFlags().StringArray(alertWebhookFlag, nil, "POST alerts about started processes, as JSON, to this URL. Repeat for multiple webhooks.")
+	agentCmd.PersistentFlags().StringArray(alertHeaderFlag, nil, "A header, as \"Key: Value\", set on requests to the --alert-webhook URLs. Repeat for multiple headers. Headers holding credentials, such as Authorization, are visible to other users in the process list; set them with --alert-header-file instead.")
+	agentCmd.PersistentFlags().String(alertHeaderFileFlag, "", "A file of headers, one \"Key: Value\" per line, set on requests to the --alert-webhook URLs. It must not be accessible to other users (e.g. mode 0600). \"agent install\" writes the --alert-header flags passed to it here, defaulting to "+agent.DefaultAlertHeaderPath+".")
+	agentCmd.PersistentFlags().StringArray(alertExecFlag, nil, "Run this command for each alert, with the alert as JSON on stdin and its type in $PROCTOR_ALERT_TYPE. The command is split on spaces, not run by a shell. Repeat for multiple commands.")
+	agentCmd.PersistentFlags().StringSlice(alertOnFlag, nil, "The alerts to fire [new-binary, root-world-writable, policy-violation]. Defaults to every alert.")
+	agentCmd.PersistentFlags().String(policyFlag, "", "A YAML file of rules, as evaluated by \"proctor policy eval\", that started processes fire a policy-violation alert for violating.")
+	agentCmd.PersistentFlags().String(seenBinariesFlag, "", "A file recording the SHA256s of the binaries seen, so new-binary alerts aren't fired again after the agent restarts.")
+	agentCmd.PersistentFlags().String(procfsFlag, "", "The procfs the host's processes are read from, such as the host's procfs mounted into a container. Defaults to /proc, or "+kube.DefaultProcfsPath+" with --kubernetes.")
+	agentCmd.PersistentFlags().Bool(kubernetesFlag, false, "Run as a Kubernetes DaemonSet: attribute processes to the pods running them, and serve the node's name and labels. Requires the pod's service account to get nodes and list pods.")
+	agentCmd.PersistentFlags().String(nodeNameFlag, "", "The name of the node the agent runs on with --kubernetes. Defaults to $"+kube.NodeNameEnv+".")
+	agentCmd.PersistentFlags().StringArray(labelFlag, nil, "A label, as key=value, describing the host in the agent's snapshots. Overrides the node's labels of the same key with --kubernetes. Repeat for multiple labels.")
+	agentInstallCmd.Flags().String(unitPathFlag, agent.DefaultUnitPath, "Where to write the systemd unit.")
+	agentInstallCmd.Flags().Bool(printFlag, false, "Print the systemd unit rather than writing it.")
+
 	// get flags
 	getCmd.Flags().String(nameFlag, "", "Get processes by the name. This will return a list of processes since processes may share the same command name.")
 	getCmd.Flags().Int(idFlag, 0, "Get processes ID. This returns a single process since IDs are unique to processes")
 
 	// contrib flags
+	contribListCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json, csv].")
+	contribGrepCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json, csv].")
+	contribStatsCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json, csv].")
+	contribActivityCmd.Flags().StringP(outputFlag, "o", "table", "Output type for command [table (default), json, csv].")
 	contribListCmd.Flags().Bool(authorsFlag, false, "Limit output to details about contributing authors.")
-	contribListCmd.Flags().StringP(tagFlag, "t", "", "Limit the results to a single tag.")
-	contribDiffCmd.Flags().String(tagOneFlag, "", "Output type for command [table (default), json].")
-	contribDiffCmd.Flags().String(tagTwoFlag, "", "Output type for command [table (default), json].")
+	contribListCmd.Flags().StringP(tagFlag, "t", "", "Limit the results to the commits released in a single tag, since the previous release.")
+	contribListCmd.Flags().StringP(branchFlag, "b", "", "Retrieve commits from this branch rather than the default branch.")
+	contribListCmd.Flags().Bool(remoteFlag, false, "List contributors as counted by the platform hosting the repository (e.g. GitHub) rather than cloning it. Faster, but can't be combined with --tag, --branch, --owners, --by-domain, --since, or --until.")
+	contribListCmd.Flags().String(sinceFlag, "", "Only consider commits since this date (2006-01-02) or duration ago (e.g. 90d).")
+	contribListCmd.Flags().String(untilFlag, "", "Only consider commits until this date (2006-01-02) or duration ago (e.g. 30d).")
+	contribListCmd.Flags().Bool(byDomainFlag, false, "Limit output to commit and author counts grouped by author email domain, showing which organizations contribute.")
+	contribListCmd.Flags().StringToString(domainAliasFlag, nil, "Group an email domain, and its subdomains, under an organization name with --by-domain. e.g. --domain-alias vmware.com=VMware,pivotal.io=VMware")
+	contribListCmd.Flags().Bool(ownersFlag, false, "Limit output to the CODEOWNERS owners covering the changed files, read at --tag or HEAD.")
+	contribDiffCmd.Flags().String(tagOneFlag, "", "The tag whose commits are compared. Defaults to the latest stable release.")
+	contribDiffCmd.Flags().String(tagTwoFlag, "", "The tag compared against. Defaults to the release preceding --tag1.")
+	contribDiffCmd.Flags().Bool(authorsFlag, false, "Limit output to the authors of the commits only in --tag1.")
+
+	// host flags
+	sourceCmd.PersistentFlags().Duration(timeoutFlag, 0, "The maximum amount of time to spend cloning or fetching a repository (e.g. 5m). No limit by default.")
+	sourceCmd.PersistentFlags().Bool(noCacheFlag, false, "Don't use or store cached responses from platforms such as GitHub.")
+	hostCmd.PersistentFlags().String(rootFSFlag, "", "Inspect the root filesystem mounted at this location rather than the running host.")
+
+	contribStatsCmd.Flags().String(sinceFlag, "", "Only consider commits since this date (2006-01-02) or duration ago (e.g. 90d).")
+	contribStatsCmd.Flags().String(untilFlag, "", "Only consider commits until this date (2006-01-02) or duration ago (e.g. 30d).")
+	contribStatsCmd.Flags().Int(topFlag, source.DefaultTopAuthors, "The number of top authors to report on.")
+	contribStatsCmd.Flags().StringP(branchFlag, "b", "", "Retrieve commits from this branch rather than the default branch.")
+
+	contribActivityCmd.Flags().String(intervalFlag, "week", "The size of each period in the time series [week (default), month].")
+	contribActivityCmd.Flags().String(sinceFlag, "", "Only consider commits since this date (2006-01-02) or duration ago (e.g. 90d).")
+	contribActivityCmd.Flags().StringP(branchFlag, "b", "", "Retrieve commits from this branch rather than the default branch.")
+
+	contribGrepCmd.Flags().BoolP(ignoreCaseFlag, "i", false, "Match the pattern regardless of case.")
+	contribGrepCmd.Flags().String(sinceFlag, "", "Only search commits since this date (2006-01-02) or duration ago (e.g. 90d).")
+	contribGrepCmd.Flags().StringP(branchFlag, "b", "", "Search commits from this branch rather than the default branch.")
+
+	healthCmd.Flags().Bool(issuesFlag, false, "Include issue and pull request statistics from the platform hosting the repository (e.g. GitHub).")
+
+	submodulesCmd.Flags().BoolP(recursiveFlag, "r", false, "Resolve each submodule's repository and list its submodules as well.")
+
+	dependenciesCmd.Flags().StringP(tagFlag, "t", "", "Read the manifests at this tag, branch, or commit rather than HEAD.")
+	dependenciesVulnsCmd.Flags().StringP(tagFlag, "t", "", "Check the dependencies at this tag, branch, or commit rather than HEAD.")
+	dependenciesDiffCmd.Flags().String(fromFlag, "", "The tag to compare dependencies from. Defaults to the release preceding --to.")
+	dependenciesDiffCmd.Flags().String(toFlag, "", "The tag to compare dependencies to. Defaults to the latest stable release.")
+
+	// release notes flags
+	releaseNotesCmd.Flags().String(fromFlag, "", "The tag release notes start from (exclusive). Defaults to the release preceding --to.")
+	releaseNotesCmd.Flags().String(toFlag, "", "The tag release notes end at (inclusive). Defaults to the latest stable release.")
 
+	artifactsListCmd.Flags().Bool(excludePreFlag, false, "Exclude releases marked as pre-releases.")
+	artifactsListCmd.Flags().Bool(excludeDraftsFlag, false, "Exclude draft releases.")
+	artifactsListCmd.Flags().String(sortFlag, "api", "The order releases are listed in [api (default), published]. published lists the most recently published first.")
 	artifactsGetCmd.Flags().StringP(tagFlag, "t", "", "Limit the results to a single tag.")
+	artifactsDiffCmd.Flags().String(tagOneFlag, "", "The tag of the release to compare from.")
+	artifactsDiffCmd.Flags().String(tagTwoFlag, "", "The tag of the release to compare to.")
+	artifactsVerifyCmd.Flags().StringP(tagFlag, "t", "", "The tag of the release containing the artifact.")
+	artifactsVerifyCmd.Flags().String(artifactFlag, "", "The name of the artifact to verify.")
+	artifactsVerifyCmd.Flags().String(keyFlag, "", "Verify with this PEM encoded public key rather than the signature's certificate.")
+	artifactsVerifyCmd.Flags().String(rootsFlag, "", "Verify the signing certificate chains to the PEM encoded certificates in this file (e.g. the Fulcio roots). Required to verify keyless signatures.")
+	artifactsVerifyCmd.Flags().String(certIdentityFlag, "", "The identity a keyless signature's certificate must be issued to, such as an email address or CI workflow URI. Required to verify keyless signatures.")
+	artifactsVerifyCmd.Flags().String(certOIDCIssuerFlag, "", "The OIDC issuer that must have authenticated a keyless signer, such as https://token.actions.githubusercontent.com. Required to verify keyless signatures.")
+	artifactsVerifyCmd.Flags().Bool(skipTlogFlag, false, "Skip verifying the signature's transparency log entry.")
+	artifactsDownloadCmd.Flags().StringP(tagFlag, "t", "", "The tag of the release to download artifacts from.")
+	artifactsDownloadCmd.Flags().StringSlice(artifactFlag, nil, "Only download artifacts whose name matches this pattern (e.g. '*linux_amd64*'). Repeat or comma separate for multiple patterns. Defaults to all artifacts.")
+	artifactsDownloadCmd.Flags().StringP(dirFlag, "d", ".", "The directory to download artifacts to.")
+	artifactsDownloadCmd.Flags().Bool(requireChecksumFlag, false, "Fail, before downloading, when an artifact isn't listed in the release's checksums file.")
+	artifactsDownloadCmd.Flags().Bool(verifySignatureFlag, false, "Verify the cosign signature of each artifact, removing and failing on any that don't verify. Signature files are downloaded but not verified themselves.")
+	artifactsDownloadCmd.Flags().String(keyFlag, "", "With --verify-signature, verify with this PEM encoded public key rather than the signature's certificate.")
+	artifactsDownloadCmd.Flags().String(rootsFlag, "", "With --verify-signature, verify the signing certificate chains to the PEM encoded certificates in this file (e.g. the Fulcio roots). Required to verify keyless signatures.")
+	artifactsDownloadCmd.Flags().String(certIdentityFlag, "", "With --verify-signature, the identity a keyless signature's certificate must be issued to, such as an email address or CI workflow URI. Required to verify keyless signatures.")
+	artifactsDownloadCmd.Flags().String(certOIDCIssuerFlag, "", "With --verify-signature, the OIDC issuer that must have authenticated a keyless signer, such as https://token.actions.githubusercontent.com. Required to verify keyless signatures.")
+	artifactsDownloadCmd.Flags().Bool(skipTlogFlag, false, "With --verify-signature, skip verifying the signature's transparency log entry.")
 }
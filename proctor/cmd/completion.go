@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/source"
+	"github.com/spf13/cobra"
+)
+
+// registerCompletions adds the dynamic shell completion of process IDs,
+// process names, cached repositories, and their tags to the commands and
+// flags accepting them. Completions are read from proctor's caches, so
+// completing is quick and never reaches the network.
+func registerCompletions() {
+	for _, c := range []*cobra.Command{treeCmd, statCmd, envCmd, portsCmd, provenanceCmd, fpCmd, fpSaveCmd, fpVerifyCmd, attestFingerprintCmd, layersCmd, bundleCmd} {
+		c.ValidArgsFunction = completeArgs(completePIDs)
+	}
+	processArtifactCmd.ValidArgsFunction = completeArgs(completePIDs, completeRepos)
+	hashCmd.RegisterFlagCompletionFunc(repoFlag, completeRepos)
+	getCmd.RegisterFlagCompletionFunc(nameFlag, completeProcessNames)
+	treeCmd.RegisterFlagCompletionFunc(nameFlag, completeProcessNames)
+	killCmd.RegisterFlagCompletionFunc(nameFlag, completeProcessNames)
+	listCmd.RegisterFlagCompletionFunc(filterNameFlag, completeProcessNames)
+
+	for _, c := range []*cobra.Command{
+		artifactsListCmd, artifactsGetCmd, artifactsVerifyCmd, artifactsDiffCmd, artifactsDownloadCmd,
+		contribListCmd, contribDiffCmd, contribStatsCmd, contribActivityCmd, contribGrepCmd,
+		releaseNotesCmd, healthCmd, submodulesCmd, dependenciesCmd, dependenciesDiffCmd,
+		dependenciesVulnsCmd, advisoriesCmd, sbomCmd,
+	} {
+		c.ValidArgsFunction = completeArgs(completeRepos)
+		for _, flag := range []string{tagFlag, tagOneFlag, tagTwoFlag, fromFlag, toFlag} {
+			if c.Flags().Lookup(flag) != nil {
+				c.RegisterFlagCompletionFunc(flag, completeTags)
+			}
+		}
+	}
+}
+
+// completeFunc completes the value of an argument or flag beginning with
+// toComplete.
+type completeFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completeArgs returns a completeFunc completing each positional argument with
+// the function at its position. Arguments beyond those are not completed.
+func completeArgs(positional ...completeFunc) completeFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= len(positional) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return positional[len(args)](cmd, args, toComplete)
+	}
+}
+
+// completePIDs completes the IDs of cached processes, described by their
+// names.
+func completePIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ps, err := completionProcesses()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	sorted, _ := ps.Sort("pid", false)
+	result := []string{}
+	for _, p := range sorted {
+		if id := fmt.Sprint(p.ID); strings.HasPrefix(id, toComplete) {
+			result = append(result, id+"\t"+p.CommandName)
+		}
+	}
+	return result, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProcessNames completes the distinct names of cached processes.
+func completeProcessNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ps, err := completionProcesses()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := map[string]bool{}
+	for _, p := range ps {
+		if strings.HasPrefix(p.CommandName, toComplete) {
+			names[p.CommandName] = true
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRepos completes the URLs of the repositories in proctor's cache.
+func completeRepos(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	urls, err := source.GetCachedRepoURLs()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	result := []string{}
+	for _, url := range urls {
+		if strings.HasPrefix(url, toComplete) {
+			result = append(result, url)
+		}
+	}
+	return result, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTags completes the tags of the cached repository passed as the
+// command's first argument.
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	tags, err := source.GetCachedTagNames(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	result := []string{}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, toComplete) {
+			result = append(result, tag)
+		}
+	}
+	return result, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionProcesses returns the processes completions are made from, which
+// are read from the cache when it exists.
+func completionProcesses() (plib.Processes, error) {
+	return createInspectorAndGetProcesses(proctorOpts{})
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/arctir/proctor/fleet"
+	"github.com/arctir/proctor/plib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// The exit code of `proctor process diff` when processes were added or their
+// binaries changed. It differs from the catchall for errors (1), so scheduled
+// integrity checks can tell drift apart from failing to check.
+const driftExitCode = 2
+
+// The values of --match-by.
+const (
+	matchByPath = "path"
+	matchByPID  = "pid"
+)
+
+// runProcessDiff defines the behavior of running:
+// `proctor process diff ...`
+// It exits with driftExitCode when processes were added or their binaries
+// changed since the baseline snapshot.
+func runProcessDiff(cmd *cobra.Command, args []string) {
+	fs := cmd.Flags()
+	live, _ := fs.GetBool(liveFlag)
+	switch {
+	case live && len(args) != 1:
+		outputErrorAndFail(fmt.Sprintf("please pass the snapshot to compare the live processes against; we received: %s", args))
+	case !live && len(args) != 2:
+		outputErrorAndFail(fmt.Sprintf("please pass the snapshots to compare, or one snapshot and --%s; we received: %s", liveFlag, args))
+	}
+	conf := plib.DiffOpts{}
+	switch matchBy, _ := fs.GetString(matchByFlag); matchBy {
+	case matchByPath:
+		conf.ByPath = true
+	case matchByPID:
+	default:
+		outputErrorAndFail(fmt.Sprintf("invalid --%s (%s), expected %s or %s", matchByFlag, matchBy, matchByPath, matchByPID))
+	}
+
+	opts := newProctorOptions(fs)
+	baseline, err := loadSnapshot(args[0])
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	var current plib.Processes
+	if live {
+		// drift must be checked against the processes running now, never the
+		// cache.
+		opts.resetCache = true
+		current, err = createInspectorAndGetProcesses(opts)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
+		}
+	} else {
+		current, err = loadSnapshot(args[1])
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+	}
+
+	diff := baseline.Diff(current, conf)
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(diff)
+	default:
+		out = newDiffTableOutput(diff)
+	}
+	output(out)
+	if diff.HasDrift() {
+		os.Exit(driftExitCode)
+	}
+}
+
+// loadSnapshot reads the processes saved at path, either by
+// `proctor process ls -o json` or from the agent's /api/processes. See
+// [fleet.Load].
+func loadSnapshot(path string) (plib.Processes, error) {
+	snapshot, err := fleet.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	ps := plib.Processes{}
+	for _, p := range snapshot.Processes {
+		ps[p.ID] = p
+	}
+	return ps, nil
+}
+
+// newDiffTableOutput renders diff as a table with a row per added, changed,
+// and removed process.
+func newDiffTableOutput(diff plib.ProcessesDiff) []byte {
+	rows := [][]string{}
+	for _, p := range diff.Added {
+		rows = append(rows, []string{"added", strconv.Itoa(p.ID), p.CommandName, p.CommandPath, shortSHA(p.BinarySHA)})
+	}
+	for _, c := range diff.Changed {
+		rows = append(rows, []string{"changed", strconv.Itoa(c.To.ID), c.To.CommandName, c.To.CommandPath, shortSHA(c.From.BinarySHA) + " -> " + shortSHA(c.To.BinarySHA)})
+	}
+	for _, p := range diff.Removed {
+		rows = append(rows, []string{"removed", strconv.Itoa(p.ID), p.CommandName, p.CommandPath, shortSHA(p.BinarySHA)})
+	}
+
+	var buf bytes.Buffer
+	if len(rows) == 0 {
+		buf.WriteString("no processes differ\n")
+		return buf.Bytes()
+	}
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Change", "PID", "Name", "Path", "SHA"})
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// baselineVerifyResult is the outcome of `proctor process fp verify`.
+type baselineVerifyResult struct {
+	PID         int
+	Baseline    string
+	Expected    string
+	Fingerprint plib.Fingerprint
+	Matches     bool
+	Drift       []plib.LineageDrift
+}
+
+// runFingerPrintProcess defines the behavior for running:
+// `proctor process finger-print ...`
+func runFingerPrintProcess(cmd *cobra.Command, args []string) {
+	if all, _ := cmd.Flags().GetBool(allFlag); all {
+		opts := newProctorOptions(cmd.Flags())
+		ps, err := createInspectorAndGetProcesses(opts)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
+		}
+		report := ps.FingerprintReport(time.Now())
+		var out []byte
+		switch opts.outType {
+		case jsonOut:
+			out, _ = json.Marshal(report)
+		default:
+			out = newFingerprintReportTableOutput(report)
+		}
+		output(out)
+		return
+	}
+	fp := fingerprintFromArgs(cmd, args)
+	output([]byte(fp.Value))
+}
+
+// runFingerPrintSave defines the behavior for running:
+// `proctor process finger-print save ...`
+func runFingerPrintSave(cmd *cobra.Command, args []string) {
+	name, _ := cmd.Flags().GetString(nameFlag)
+	if name == "" {
+		outputErrorAndFail(fmt.Sprintf("please pass the name to save the baseline as with --%s", nameFlag))
+	}
+	fp := fingerprintFromArgs(cmd, args)
+	store := newBaselineStore(cmd)
+	if err := store.Save(plib.Baseline{Name: name, Created: time.Now(), Fingerprint: fp}); err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	output([]byte(fmt.Sprintf("saved baseline %s: %s\n", name, fp.Value)))
+}
+
+// runFingerPrintVerify defines the behavior for running:
+// `proctor process finger-print verify ...`
+// It exits with a non-zero code when the process has drifted from the
+// baseline.
+func runFingerPrintVerify(cmd *cobra.Command, args []string) {
+	name, _ := cmd.Flags().GetString(againstFlag)
+	if name == "" {
+		outputErrorAndFail(fmt.Sprintf("please pass the baseline to verify against with --%s", againstFlag))
+	}
+	b, err := newBaselineStore(cmd).Load(name)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	fp := fingerprintFromArgs(cmd, args)
+	drift := b.Drift(fp)
+	result := baselineVerifyResult{
+		PID:         fp.Lineage[0].ID,
+		Baseline:    b.Name,
+		Expected:    b.Value,
+		Fingerprint: fp,
+		Matches:     len(drift) == 0,
+		Drift:       drift,
+	}
+
+	var out []byte
+	switch resolveOutputType(cmd.Flags()) {
+	case jsonOut:
+		out, _ = json.Marshal(result)
+	default:
+		out = newBaselineVerifyTableOutput(result)
+	}
+	output(out)
+	if !result.Matches {
+		os.Exit(1)
+	}
+}
+
+// fingerprintFromArgs returns the fingerprint of the process whose ID is the
+// first argument, failing if it can't be created.
+func fingerprintFromArgs(cmd *cobra.Command, args []string) plib.Fingerprint {
+	pid, err := parseID(args)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("please pass a valid pid (int); we received: %s", args))
+	}
+	opts := newProctorOptions(cmd.Flags())
+	ps, err := createInspectorAndGetProcesses(opts)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
+	}
+	fp, err := ps.Fingerprint(pid)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	return fp
+}
+
+func newBaselineStore(cmd *cobra.Command) *plib.BaselineStore {
+	dir, _ := cmd.Flags().GetString(baselineDirFlag)
+	return plib.NewBaselineStore(dir)
+}
+
+func newBaselineVerifyTableOutput(r baselineVerifyResult) []byte {
+	var buf bytes.Buffer
+	if r.Matches {
+		fmt.Fprintf(&buf, "process %d matches baseline %s: %s\n", r.PID, r.Baseline, r.Expected)
+		return buf.Bytes()
+	}
+	fmt.Fprintf(&buf, "process %d has drifted from baseline %s\nexpected: %s\nactual:   %s\n", r.PID, r.Baseline, r.Expected, r.Fingerprint.Value)
+
+	describe := func(e *plib.LineageEntry) string {
+		if e == nil {
+			return "(none)"
+		}
+		return fmt.Sprintf("%s (%s)", e.CommandName, shortSHA(e.BinarySHA))
+	}
+	rows := [][]string{}
+	for _, d := range r.Drift {
+		rows = append(rows, []string{strconv.Itoa(d.Depth), describe(d.Expected), describe(d.Actual)})
+	}
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Depth", "Expected", "Actual"})
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
+
+func newFingerprintReportTableOutput(r plib.FingerprintReport) []byte {
+	rows := [][]string{}
+	for _, e := range r.Processes {
+		fp := e.Fingerprint
+		if fp == "" {
+			fp = "unavailable: " + e.Error
+		}
+		rows = append(rows, []string{strconv.Itoa(e.PID), e.CommandName, fp})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"PID", "Name", "Finger Print"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
+
+// shortSHA returns the first 12 characters of sha, enough to tell binaries
+// apart when displayed.
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
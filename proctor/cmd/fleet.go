@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arctir/proctor/fleet"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// runFleet defines what should occur when `proctor fleet ...` is run.
+func runFleet(cmd *cobra.Command, args []string) {
+	// if proctor is run without a command (argument), print help.
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+}
+
+// runFleetReport defines the behavior of running:
+// `proctor fleet report SOURCE...`
+// Each source is the URL of an agent or a snapshot file.
+func runFleetReport(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		outputErrorAndFail("please pass the URLs of agents, or snapshot files, to report on")
+	}
+	fs := cmd.Flags()
+	timeout, _ := fs.GetDuration(timeoutFlag)
+	opts := fleet.ReportOpts{}
+	opts.Expected, _ = fs.GetStringSlice(expectFlag)
+	opts.ExpectedRatio, _ = fs.GetFloat64(expectedRatioFlag)
+	if opts.ExpectedRatio <= 0 {
+		outputErrorAndFail(fmt.Sprintf("--%s must be greater than 0; we received: %v", expectedRatioFlag, opts.ExpectedRatio))
+	}
+
+	tlsOpts := fleet.TLSOpts{}
+	tlsOpts.CA, _ = fs.GetString(caFlag)
+	tlsOpts.Cert, _ = fs.GetString(certFlag)
+	tlsOpts.Key, _ = fs.GetString(keyFlag)
+	client, err := fleet.NewHTTPClient(timeout, tlsOpts)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+
+	hosts := fleet.Collect(context.Background(), args, fleet.CollectOpts{HTTPClient: client})
+	report := fleet.Aggregate(hosts, time.Now(), opts)
+
+	var out []byte
+	switch resolveOutputType(fs) {
+	case jsonOut:
+		out, _ = json.Marshal(report)
+	default:
+		out = newFleetReportTableOutput(report)
+	}
+	output(out)
+}
+
+// newFleetReportTableOutput renders r as a table of hosts, followed by a
+// table with a row per outlier and missing binary. The versions of every
+// binary are only in the JSON output, since fleets run too many to read.
+func newFleetReportTableOutput(r fleet.Report) []byte {
+	var buf bytes.Buffer
+	rows := [][]string{}
+	for _, h := range r.Hosts {
+		refreshed := ""
+		if !h.LastRefresh.IsZero() {
+			refreshed = h.LastRefresh.Local().Format(timeDateFormat)
+		}
+		rows = append(rows, []string{h.Name, h.Source, strconv.Itoa(h.Processes), refreshed, h.Error})
+	}
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Host", "Source", "Processes", "Last Refresh", "Error"})
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+
+	if len(r.Outliers) == 0 && len(r.Missing) == 0 {
+		fmt.Fprintf(&buf, "\nno outliers or missing binaries across %d binaries\n", len(r.Binaries))
+		return buf.Bytes()
+	}
+	rows = [][]string{}
+	for _, o := range r.Outliers {
+		rows = append(rows, []string{"outlier", o.Name, fmt.Sprintf("%s (%d hosts run %s)", shortSHA(o.SHA256), o.CommonHosts, shortSHA(o.CommonSHA256)), strings.Join(o.Hosts, ", ")})
+	}
+	for _, m := range r.Missing {
+		rows = append(rows, []string{"missing", m.Name, fmt.Sprintf("(%d hosts run it)", m.RunningOn), strings.Join(m.Hosts, ", ")})
+	}
+	buf.WriteString("\n")
+	table = tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Finding", "Binary", "SHA", "Hosts"})
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/arctir/proctor/host"
+	"github.com/spf13/cobra"
+)
+
+// runHost defines what should occur when `proctor host ...` is run.
+func runHost(cmd *cobra.Command, args []string) {
+	// if proctor is run without a command (argument), print help.
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+}
+
+// runHostInfo defines the behavior of running:
+// `proctor host info ...`
+func runHostInfo(cmd *cobra.Command, args []string) {
+	opts := newProctorOptions(cmd.Flags())
+	info := host.Collect(context.Background(), newHostReader(cmd))
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(info)
+	default:
+		out = newHostInfoTableOutput(info)
+	}
+	output(out)
+}
+
+// runHostID defines the behavior of running:
+// `proctor host id ...`
+func runHostID(cmd *cobra.Command, args []string) {
+	opts := newProctorOptions(cmd.Flags())
+	id, err := newHostReader(cmd).GetHostID()
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving host id: %s", err))
+	}
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(id)
+	default:
+		out = []byte(id + "\n")
+	}
+	output(out)
+}
+
+// runHostHardware defines the behavior of running:
+// `proctor host hardware ...`
+func runHostHardware(cmd *cobra.Command, args []string) {
+	opts := newProctorOptions(cmd.Flags())
+	hw, err := newHostReader(cmd).GetHardware()
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving hardware details: %s", err))
+	}
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(hw)
+	default:
+		out = newHardwareTableOutput(hw)
+	}
+	output(out)
+}
+
+// runHostContainers defines the behavior of running:
+// `proctor host containers ...`
+func runHostContainers(cmd *cobra.Command, args []string) {
+	opts := newProctorOptions(cmd.Flags())
+	containers, err := newHostReader(cmd).GetContainers(context.Background())
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed listing containers: %s", err))
+	}
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(containers)
+	default:
+		out = newContainerTableOutput(containers)
+	}
+	output(out)
+}
+
+// newHostReader is a helper function returning the [host.LinuxReader] used by
+// all host commands. When the --root flag is set, the reader inspects the root
+// filesystem at that location rather than the running host, which is only
+// supported on Linux.
+func newHostReader(cmd *cobra.Command) *host.LinuxReader {
+	rootFS, _ := cmd.Flags().GetString(rootFSFlag)
+	if rootFS == "" && runtime.GOOS != "linux" {
+		outputErrorAndFail(fmt.Sprintf("inspecting the running host is unsupported on %s; use --%s to inspect a Linux root filesystem", runtime.GOOS, rootFSFlag))
+	}
+	lr := host.NewLinuxReader(host.LinuxReaderConfig{RootFS: rootFS})
+	return &lr
+}
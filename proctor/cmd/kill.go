@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arctir/proctor/plib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// signalResult is the outcome of signaling a process with
+// `proctor process kill`.
+type signalResult struct {
+	PID         int
+	CommandName string
+	BinarySHA   string
+	Signal      string
+	// Whether the signal was sent. When false, Error is why.
+	Sent  bool
+	Error string `json:",omitempty"`
+}
+
+// runKillProcesses defines the behavior of running:
+// `proctor process kill ...`
+// It exits non-zero when no process was targeted or the signal couldn't be
+// sent to every targeted process.
+func runKillProcesses(cmd *cobra.Command, args []string) {
+	fs := cmd.Flags()
+	name, _ := fs.GetString(nameFlag)
+	if name == "" {
+		outputErrorAndFail(fmt.Sprintf("please pass a pattern matching the names of the processes to signal with --%s", nameFlag))
+	}
+	re, err := regexp.Compile(name)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid --%s (%s): %s", nameFlag, name, err))
+	}
+	signalName, _ := fs.GetString(signalFlag)
+	sig, err := plib.ParseSignal(normalizeSignalName(signalName))
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	opts := newProctorOptions(fs)
+	filter := opts.filter
+	filter.Name = re
+
+	// processes must be targeted by what's running now, never the cache.
+	opts.resetCache = true
+	ps, err := createInspectorAndGetProcesses(opts)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
+	}
+	// proctor never signals itself, even when its name matches.
+	delete(ps, os.Getpid())
+	matched := ps.Filter(filter)
+	if len(matched) == 0 {
+		outputErrorAndFail(fmt.Sprintf("no processes matched --%s %s", nameFlag, name))
+	}
+
+	// with --sha, processes matching by name but running another binary are
+	// reported but never signaled.
+	targets := matched
+	sha, _ := fs.GetString(shaFlag)
+	if sha != "" {
+		filter.BinarySHA = sha
+		targets = matched.Filter(filter)
+	}
+	sorted, _ := matched.Sort("pid", false)
+	results := []signalResult{}
+	for _, p := range sorted {
+		r := signalResult{PID: p.ID, CommandName: p.CommandName, BinarySHA: p.BinarySHA, Signal: plib.SignalName(sig)}
+		if targets[p.ID] == nil {
+			r.Error = fmt.Sprintf("skipped, binary SHA doesn't match --%s", shaFlag)
+		}
+		results = append(results, r)
+	}
+
+	if len(targets) > 0 {
+		if yes, _ := fs.GetBool(yesFlag); !yes {
+			os.Stderr.Write(newSignalResultsTableOutput(results, false))
+			if !confirm(fmt.Sprintf("Send %s to %d process(es)?", plib.SignalName(sig), len(targets))) {
+				outputErrorAndFail("aborted, no signals were sent")
+			}
+		}
+	}
+	failed := len(targets) == 0
+	for i, r := range results {
+		if targets[r.PID] == nil {
+			continue
+		}
+		// with --sha, the binary is verified again right before the process is
+		// signaled, as it may have exited, and its pid been reused, while the
+		// user confirmed.
+		signal := plib.SignalProcess
+		if sha != "" {
+			signal = func(pid int, sig plib.Signal) error { return plib.SignalVerifiedProcess(pid, sig, sha) }
+		}
+		if err := signal(r.PID, sig); err != nil {
+			results[i].Error = err.Error()
+			failed = true
+			continue
+		}
+		results[i].Sent = true
+	}
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(results)
+	default:
+		out = newSignalResultsTableOutput(results, true)
+	}
+	output(out)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// normalizeSignalName returns name as accepted by [plib.ParseSignal], so
+// signals can be passed as TERM or sigterm as well as SIGTERM.
+func normalizeSignalName(name string) string {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	return name
+}
+
+// confirm asks the user question on stderr and returns whether they answered
+// yes. When stdin isn't a terminal, no one can answer, so it returns false.
+func confirm(question string) bool {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		fmt.Fprintf(os.Stderr, "%s refusing without a terminal to confirm, pass --%s to skip confirming\n", question, yesFlag)
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// newSignalResultsTableOutput renders results as a table. When sent is false,
+// the processes are only about to be signaled, so no result is shown for
+// those that will be.
+func newSignalResultsTableOutput(results []signalResult, sent bool) []byte {
+	rows := [][]string{}
+	for _, r := range results {
+		result := r.Error
+		switch {
+		case r.Sent:
+			result = "sent " + r.Signal
+		case !sent && result == "":
+			result = "will send " + r.Signal
+		}
+		rows = append(rows, []string{strconv.Itoa(r.PID), r.CommandName, shortSHA(r.BinarySHA), result})
+	}
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"PID", "Name", "SHA", "Result"})
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/plib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// processLayer is the image layer that provided the binary of a process
+// running in a container, as output by `proctor process layers`.
+type processLayer struct {
+	PID         int
+	CommandName string
+	CommandPath string
+	ContainerID string
+	Image       string           `json:",omitempty"`
+	Origin      *host.FileOrigin `json:",omitempty"`
+	// Why the origin of the binary couldn't be resolved.
+	Error string `json:",omitempty"`
+}
+
+// runProcessLayers defines the behavior of running:
+// `proctor process layers [PID...]`
+// Without PIDs, every process running in a container is reported.
+func runProcessLayers(cmd *cobra.Command, args []string) {
+	ps := liveProcesses(cmd)
+	selected := []*plib.Process{}
+	for _, arg := range args {
+		pid, err := strconv.Atoi(arg)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("invalid process ID %q: %s", arg, err))
+		}
+		if ps[pid] == nil {
+			outputErrorAndFail(fmt.Sprintf("process %d does not exist", pid))
+		}
+		selected = append(selected, ps[pid])
+	}
+	if len(args) == 0 {
+		for _, p := range ps {
+			selected = append(selected, p)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].ID < selected[j].ID })
+
+	lr := host.NewLinuxReader(host.LinuxReaderConfig{})
+	// containers usually run several processes, so each container's layers
+	// are only resolved once.
+	type containerLayers struct {
+		layers *host.ContainerLayers
+		err    error
+	}
+	containers := map[string]containerLayers{}
+	results := []processLayer{}
+	for _, p := range selected {
+		result := processLayer{PID: p.ID, CommandName: p.CommandName, CommandPath: p.CommandPath}
+		id, err := lr.GetProcessContainerID(p.ID)
+		if id == "" && len(args) == 0 {
+			continue
+		}
+		if err == nil && id == "" {
+			err = fmt.Errorf("process %d doesn't run in a container", p.ID)
+		}
+		if err == nil {
+			result.ContainerID = id
+			c, ok := containers[id]
+			if !ok {
+				c.layers, c.err = lr.GetProcessLayers(context.Background(), p.ID)
+				containers[id] = c
+			}
+			err = c.err
+			if err == nil {
+				result.Image = c.layers.Image
+				result.Origin, err = c.layers.Origin(p.CommandPath)
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	var out []byte
+	switch resolveOutputType(cmd.Flags()) {
+	case jsonOut:
+		out, _ = json.Marshal(results)
+	default:
+		out = newProcessLayerTableOutput(results)
+	}
+	output(out)
+}
+
+func newProcessLayerTableOutput(results []processLayer) []byte {
+	rows := [][]string{}
+	for _, r := range results {
+		container := r.ContainerID
+		if len(container) > 12 {
+			container = container[:12]
+		}
+		origin, createdBy := r.Error, ""
+		switch {
+		case r.Origin == nil:
+		case r.Origin.AddedAtRuntime:
+			origin = "added at runtime"
+		default:
+			origin = fmt.Sprintf("layer %d", r.Origin.Layer.Index)
+			createdBy = r.Origin.Layer.CreatedBy
+		}
+		rows = append(rows, []string{strconv.Itoa(r.PID), r.CommandName, r.CommandPath, container, r.Image, origin, createdBy})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"PID", "Name", "Binary", "Container", "Image", "Origin", "Created By"})
+	// instructions are long, and wrapping them makes them hard to read.
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
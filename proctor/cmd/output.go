@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	// the prefixes of the --output values whose remainder is a specification,
+	// such as custom-columns=PID:.ID,NAME:.CommandName.
+	customColumnsPrefix  = "custom-columns="
+	goTemplatePrefix     = "go-template="
+	goTemplateFilePrefix = "go-template-file="
+)
+
+// noneValue is shown by custom columns for fields that are unset, such as the
+// fields of a nil pointer, as kubectl does.
+const noneValue = "<none>"
+
+// processFormat renders processes as requested with --output
+// custom-columns=SPEC or go-template=TEMPLATE.
+type processFormat struct {
+	// the header of each column, empty for go templates.
+	headers []string
+	// the path to the field of each column, e.g. [OSSpecific State] for
+	// .OSSpecific.State.
+	fields [][]string
+	// rendered for each process with go templates.
+	tmpl *template.Template
+}
+
+// newProcessFormat parses the specification of the custom-columns or
+// go-template output type ot.
+//
+// Custom columns are a comma separated list of HEADER:FIELD, where FIELD is a
+// path to a field of [plib.Process], such as .ID or .OSSpecific.State. As
+// with kubectl, FIELD may be wrapped in braces, and fields that are unset,
+// such as those of a nil pointer, are shown as <none>. Go templates are
+// executed for each process, and followed by a newline unless they end with
+// one.
+func newProcessFormat(ot outputType, spec string) (*processFormat, error) {
+	switch ot {
+	case customColumnsOut:
+		f := &processFormat{}
+		for _, column := range strings.Split(spec, ",") {
+			header, field, ok := strings.Cut(column, ":")
+			field = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(field), "{"), "}")
+			field = strings.TrimPrefix(field, ".")
+			path := strings.Split(field, ".")
+			if !ok || header == "" || field == "" || strings.Contains(field, "..") || strings.HasSuffix(field, ".") {
+				return nil, fmt.Errorf("custom column (%s) must be in the form HEADER:FIELD, e.g. PID:.ID", column)
+			}
+			f.headers = append(f.headers, header)
+			f.fields = append(f.fields, path)
+		}
+		return f, nil
+	case goTemplateOut:
+		tmpl, err := template.New("go-template").Parse(spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing go template: %s", err)
+		}
+		return &processFormat{tmpl: tmpl}, nil
+	}
+	return nil, fmt.Errorf("output type (%d) is not a custom format", ot)
+}
+
+// render returns ps in the format, in the order given.
+func (f *processFormat) render(ps []*plib.Process) ([]byte, error) {
+	var buf bytes.Buffer
+	if f.headers == nil {
+		for _, p := range ps {
+			var out bytes.Buffer
+			if err := f.tmpl.Execute(&out, p); err != nil {
+				return nil, fmt.Errorf("failed executing template for process %d: %s", p.ID, err)
+			}
+			if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+				out.WriteByte('\n')
+			}
+			buf.Write(out.Bytes())
+		}
+		return buf.Bytes(), nil
+	}
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(f.headers, "\t"))
+	for _, p := range ps {
+		values := make([]string, len(f.fields))
+		for i, path := range f.fields {
+			v, err := fieldValue(reflect.ValueOf(p), path)
+			if err != nil {
+				return nil, fmt.Errorf("failed executing custom columns for process %d: %s", p.ID, err)
+			}
+			values[i] = v
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	w.Flush()
+	return buf.Bytes(), nil
+}
+
+// fieldValue returns the value of the field at path within v, formatted as
+// text templates do. When a nil pointer or interface is reached, or a map
+// has no value for a key, the field is unset and [noneValue] is returned. An
+// error is returned when a field doesn't exist.
+func fieldValue(v reflect.Value, path []string) (string, error) {
+	for _, name := range path {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return noneValue, nil
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			field := v.FieldByName(name)
+			if !field.IsValid() || !field.CanInterface() {
+				return "", fmt.Errorf("can't evaluate field %s in type %s", name, v.Type())
+			}
+			v = field
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return "", fmt.Errorf("can't evaluate field %s in type %s", name, v.Type())
+			}
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return noneValue, nil
+			}
+		default:
+			return "", fmt.Errorf("can't evaluate field %s in type %s", name, v.Type())
+		}
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return noneValue, nil
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// createFormattedOutput renders ps, in the order given, in the custom format
+// requested in opts.
+func createFormattedOutput(ps []*plib.Process, opts proctorOpts) ([]byte, error) {
+	f, err := newProcessFormat(opts.outType, opts.outSpec)
+	if err != nil {
+		return nil, err
+	}
+	return f.render(ps)
+}
+
+// resolveOutputSpec returns the specification following the type in the
+// --output flag, such as the columns of custom-columns=PID:.ID. A
+// go-template-file's template is read from the file.
+func resolveOutputSpec(of string) (string, error) {
+	switch {
+	case strings.HasPrefix(of, customColumnsPrefix):
+		return strings.TrimPrefix(of, customColumnsPrefix), nil
+	case strings.HasPrefix(of, goTemplatePrefix):
+		return strings.TrimPrefix(of, goTemplatePrefix), nil
+	case strings.HasPrefix(of, goTemplateFilePrefix):
+		b, err := os.ReadFile(strings.TrimPrefix(of, goTemplateFilePrefix))
+		if err != nil {
+			return "", fmt.Errorf("failed reading go template file: %s", err)
+		}
+		return string(b), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+func TestProcessFormat(t *testing.T) {
+	ps := []*plib.Process{
+		{ID: 1, CommandName: "systemd", OSSpecific: plib.ProcessStat{State: "S"}, Annotations: map[string]string{"pod": "web"}},
+		{ID: 42, CommandName: "bash"},
+	}
+	tests := []struct {
+		name     string
+		ot       outputType
+		spec     string
+		expected string
+	}{
+		{
+			name:     "custom columns",
+			ot:       customColumnsOut,
+			spec:     "PID:.ID,NAME:.CommandName",
+			expected: "PID   NAME\n1     systemd\n42    bash\n",
+		},
+		{
+			name:     "custom columns with braces and without a leading dot",
+			ot:       customColumnsOut,
+			spec:     "PID:{.ID},NAME:CommandName",
+			expected: "PID   NAME\n1     systemd\n42    bash\n",
+		},
+		{
+			name:     "custom columns of unset fields",
+			ot:       customColumnsOut,
+			spec:     "PID:.ID,STATE:.OSSpecific.State,POD:.Annotations.pod",
+			expected: "PID   STATE    POD\n1     S        web\n42    <none>   <none>\n",
+		},
+		{
+			name:     "go template",
+			ot:       goTemplateOut,
+			spec:     "{{ .ID }} {{ .CommandName }}",
+			expected: "1 systemd\n42 bash\n",
+		},
+		{
+			name:     "go template ending with a newline",
+			ot:       goTemplateOut,
+			spec:     "{{ .ID }}\n",
+			expected: "1\n42\n",
+		},
+	}
+	for _, test := range tests {
+		f, err := newProcessFormat(test.ot, test.spec)
+		if err != nil {
+			t.Fatalf("fail: %s: unexpected error parsing format: %s", test.name, err)
+		}
+		out, err := f.render(ps)
+		if err != nil {
+			t.Fatalf("fail: %s: unexpected error rendering: %s", test.name, err)
+		}
+		if string(out) != test.expected {
+			t.Logf("fail: %s: expected:\n%q\nactual:\n%q", test.name, test.expected, string(out))
+			t.Fail()
+		}
+	}
+}
+
+func TestProcessFormatErrors(t *testing.T) {
+	ps := []*plib.Process{{ID: 1, CommandName: "systemd"}}
+	tests := []struct {
+		name string
+		ot   outputType
+		spec string
+	}{
+		{name: "custom column without a field", ot: customColumnsOut, spec: "PID"},
+		{name: "custom column without a header", ot: customColumnsOut, spec: ":.ID"},
+		{name: "custom column with an empty path element", ot: customColumnsOut, spec: "PID:.OSSpecific..State"},
+		{name: "custom column of a missing field", ot: customColumnsOut, spec: "PID:.Missing"},
+		{name: "invalid go template", ot: goTemplateOut, spec: "{{ .ID "},
+		{name: "go template of a missing field", ot: goTemplateOut, spec: "{{ .Missing }}"},
+	}
+	for _, test := range tests {
+		f, err := newProcessFormat(test.ot, test.spec)
+		if err == nil {
+			_, err = f.render(ps)
+		}
+		if err == nil {
+			t.Logf("fail: %s: expected an error", test.name)
+			t.Fail()
+		}
+	}
+}
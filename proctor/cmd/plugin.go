@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/plugins"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// runPlugin defines the behavior of running:
+// `proctor plugin`
+func runPlugin(cmd *cobra.Command, args []string) {
+	// if proctor is run without a command (argument), print help.
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+}
+
+// runPluginList defines the behavior of running:
+// `proctor plugin ls ...`
+func runPluginList(cmd *cobra.Command, args []string) {
+	found := discoverPlugins(cmd)
+	var out []byte
+	switch resolveOutputType(cmd.Flags()) {
+	case jsonOut:
+		out, _ = json.Marshal(found)
+	default:
+		rows := [][]string{}
+		for _, p := range found {
+			kinds := []string{}
+			for _, k := range p.Kinds {
+				kinds = append(kinds, string(k))
+			}
+			rows = append(rows, []string{p.Name, strings.Join(kinds, ", "), p.Description, p.Path})
+		}
+		var buf bytes.Buffer
+		table := tablewriter.NewWriter(&buf)
+		table.SetHeader([]string{"Name", "Kinds", "Description", "Path"})
+		table.AppendBulk(rows)
+		table.SetAutoWrapText(false)
+		table.Render()
+		out = buf.Bytes()
+	}
+	output(out)
+}
+
+// runPluginOutput defines the behavior of running:
+// `proctor plugin output ...`
+func runPluginOutput(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+	p, err := plugins.Find(discoverPlugins(cmd, args[0]), args[0], plugins.OutputKind)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	ps := liveProcesses(cmd)
+	enrichProcesses(cmd, ps)
+	if err := p.Output(context.Background(), ps); err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	output([]byte(fmt.Sprintf("sent %d processes to %s\n", len(ps), p.Name)))
+}
+
+// enrichProcesses annotates ps with the enricher plugins named by the
+// --enrich flag, in order.
+func enrichProcesses(cmd *cobra.Command, ps plib.Processes) {
+	names, _ := cmd.Flags().GetStringSlice(enrichFlag)
+	if len(names) == 0 {
+		return
+	}
+	found := discoverPlugins(cmd, names...)
+	for _, name := range names {
+		p, err := plugins.Find(found, name, plugins.EnricherKind)
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+		if err := p.Enrich(context.Background(), ps); err != nil {
+			outputErrorAndFail(err.Error())
+		}
+	}
+}
+
+// discoverPlugins returns the plugins in the --plugin-dir directory, or the
+// default, limited to names when any are passed so no other plugin is run.
+// Plugins failing to describe themselves are logged and left out.
+func discoverPlugins(cmd *cobra.Command, names ...string) []plugins.Plugin {
+	dir, _ := cmd.Flags().GetString(pluginDirFlag)
+	found, err := plugins.Discover(context.Background(), dir, plugins.DiscoverOpts{Names: names})
+	if err != nil {
+		if found == nil {
+			outputErrorAndFail(err.Error())
+		}
+		slog.Warn("leaving out plugins that failed to describe themselves or aren't trusted", "error", err)
+	}
+	return found
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/policy"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// The exit code of `proctor policy eval` when processes violate rules of at
+// least the --fail-on severity. It differs from the catchall for errors (1),
+// so CI and compliance checks can tell violations apart from failing to
+// evaluate.
+const violationExitCode = 2
+
+// runPolicy defines what should occur when `proctor policy ...` is run.
+func runPolicy(cmd *cobra.Command, args []string) {
+	// if proctor is run without a command (argument), print help.
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+}
+
+// runPolicyEval defines the behavior of running:
+// `proctor policy eval ...`
+// It exits with violationExitCode when processes violate rules of at least
+// the --fail-on severity.
+func runPolicyEval(cmd *cobra.Command, args []string) {
+	fs := cmd.Flags()
+	file, _ := fs.GetString(fileFlag)
+	if file == "" {
+		outputErrorAndFail(fmt.Sprintf("please pass the rules to evaluate with --%s", fileFlag))
+	}
+	failOn, _ := fs.GetString(failOnFlag)
+	failOn, err := policy.ParseSeverity(failOn)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid --%s: %s", failOnFlag, err))
+	}
+	p, err := policy.Load(file)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+
+	opts := newProctorOptions(fs)
+	// rules must be evaluated against what's running now, never the cache.
+	opts.resetCache = true
+	ps, err := createInspectorAndGetProcesses(opts)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
+	}
+	ri, err := createResourceInspector(opts)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	info := host.Collect(context.Background(), newHostReader(cmd))
+	conf := policy.EvalOpts{Host: &info, Sockets: map[int][]plib.Socket{}}
+	for _, s := range plib.GetProcessesSockets(ri, ps) {
+		conf.Sockets[s.PID] = append(conf.Sockets[s.PID], s.Socket)
+	}
+
+	report, err := p.Evaluate(ps, conf)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(report)
+	default:
+		out = newPolicyReportTableOutput(report)
+	}
+	output(out)
+	if len(report.AtLeast(failOn)) > 0 {
+		os.Exit(violationExitCode)
+	}
+}
+
+// newPolicyReportTableOutput renders the findings of r as a table, followed
+// by a summary of what was evaluated.
+func newPolicyReportTableOutput(r policy.Report) []byte {
+	var buf bytes.Buffer
+	if len(r.Findings) > 0 {
+		rows := [][]string{}
+		for _, f := range r.Findings {
+			rows = append(rows, []string{f.Severity, f.Rule, strconv.Itoa(f.PID), f.CommandName, f.CommandPath})
+		}
+		table := tablewriter.NewWriter(&buf)
+		table.SetHeader([]string{"Severity", "Rule", "PID", "Name", "Path"})
+		table.SetAutoWrapText(false)
+		table.AppendBulk(rows)
+		table.Render()
+	}
+	fmt.Fprintf(&buf, "%d finding(s) evaluating %d rule(s) against %d process(es)\n", len(r.Findings), r.Rules, r.Processes)
+	return buf.Bytes()
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/arctir/proctor/source"
+)
+
+// The minimum amount of time between rendering progress updates, which
+// prevents flooding the terminal when many updates arrive at once.
+const progressRenderInterval = 100 * time.Millisecond
+
+// progressPrinter renders the progress of retrieving a repository as a single
+// line that is rewritten in place.
+type progressPrinter struct {
+	mu         sync.Mutex
+	w          io.Writer
+	url        string
+	lastRender time.Time
+	rendered   bool
+}
+
+func newProgressPrinter(w io.Writer, url string) *progressPrinter {
+	return &progressPrinter{w: w, url: url}
+}
+
+// print renders p, unless an update was rendered too recently. It satisfies
+// [source.ProgressFunc].
+func (pp *progressPrinter) print(p source.Progress) {
+	line := fmt.Sprintf("%s: %s", pp.url, p.Stage)
+	switch {
+	case p.TotalObjects > 0:
+		line += fmt.Sprintf(" %d%% (%d/%d)", p.Objects*100/p.TotalObjects, p.Objects, p.TotalObjects)
+	case p.Objects > 0:
+		line += fmt.Sprintf(" %d", p.Objects)
+	}
+	if p.BytesReceived > 0 {
+		line += ", " + formatBytes(p.BytesReceived)
+	}
+	pp.render(line)
+}
+
+// printBytes renders the progress of a download that has written bytes of
+// total, unless an update was rendered too recently. A total of 0 means the
+// size is unknown.
+func (pp *progressPrinter) printBytes(written, total int64) {
+	line := fmt.Sprintf("%s: %s", pp.url, formatBytes(written))
+	if total > 0 {
+		line = fmt.Sprintf("%s: %d%% (%s/%s)", pp.url, written*100/total, formatBytes(written), formatBytes(total))
+	}
+	pp.render(line)
+}
+
+// render replaces the progress line with line, unless an update was rendered
+// too recently.
+func (pp *progressPrinter) render(line string) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	now := time.Now()
+	if now.Sub(pp.lastRender) < progressRenderInterval {
+		return
+	}
+	pp.lastRender = now
+	pp.rendered = true
+	// \033[K clears what remains of the previous, possibly longer, line.
+	fmt.Fprintf(pp.w, "\r%s\033[K", line)
+}
+
+// clear removes the progress line, if one was rendered.
+func (pp *progressPrinter) clear() {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if pp.rendered {
+		fmt.Fprint(pp.w, "\r\033[K")
+	}
+}
+
+// formatBytes returns n as a human readable size, such as 12.3 MiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// isTerminal returns whether f is a terminal (character device) rather than,
+// for example, a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/arctir/proctor/platforms"
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/provenance"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// runProcessProvenance defines the behavior of running:
+// `proctor process provenance ...`
+func runProcessProvenance(cmd *cobra.Command, args []string) {
+	pid, err := parseID(args)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("please pass a valid pid (int); we received: %s", args))
+	}
+	opts := newProctorOptions(cmd.Flags())
+	ps, err := createInspectorAndGetProcesses(opts)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
+	}
+	if ps[pid] == nil {
+		outputErrorAndFail(fmt.Sprintf("failed to find process with id: %d", pid))
+	}
+
+	prov, err := provenance.ResolveProcess(*ps[pid])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving source of process %d: %s", pid, err))
+	}
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(prov)
+	default:
+		out = newProvenanceTableOutput(prov)
+	}
+	output(out)
+}
+
+// runProcessArtifact defines the behavior of running:
+// `proctor process artifact ...`
+func runProcessArtifact(cmd *cobra.Command, args []string) {
+	pid, err := parseID(args)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("please pass a valid pid (int); we received: %s", args))
+	}
+	opts := newProctorOptions(cmd.Flags())
+	ps, err := createInspectorAndGetProcesses(opts)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("process collection failed: %s", err))
+	}
+	if ps[pid] == nil {
+		outputErrorAndFail(fmt.Sprintf("failed to find process with id: %d", pid))
+	}
+
+	// when no repository is passed, use the one the binary was built from.
+	var repoURL string
+	if len(args) > 1 {
+		repoURL = args[1]
+	} else {
+		bi, err := provenance.ReadBuildInfo(ps[pid].CommandPath)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("please pass the repository the binary was released from, it could not be determined: %s", err))
+		}
+		repoURL, err = provenance.RepoURLFromModulePath(bi.ModulePath)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("please pass the repository the binary was released from, it could not be determined: %s", err))
+		}
+	}
+	setupPlatforms(cmd)
+	platform, repo, err := platforms.ForURL(repoURL)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+
+	match, err := provenance.MatchProcess(*ps[pid], platform, repo)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed matching process %d to a release artifact: %s", pid, err))
+	}
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(match)
+	default:
+		out = newArtifactMatchTableOutput(match)
+	}
+	output(out)
+}
+
+// fileHash is the checksum of a file hashed by `proctor process hash` and,
+// when a repository is given, the release artifact it matched.
+type fileHash struct {
+	Path   string
+	SHA256 string
+	Match  *provenance.ArtifactMatch `json:",omitempty"`
+}
+
+// runProcessHash defines the behavior of running:
+// `proctor process hash ...`
+// It exits with a non-zero code when --repo is set and a file doesn't match a
+// release artifact.
+func runProcessHash(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+	opts := newProctorOptions(cmd.Flags())
+	repoURL, _ := cmd.Flags().GetString(repoFlag)
+	// releases are indexed once and reused to match every file.
+	var index *provenance.ArtifactIndex
+	if repoURL != "" {
+		setupPlatforms(cmd)
+		platform, repo, err := platforms.ForURL(repoURL)
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+		index, err = provenance.NewArtifactIndex(platform, repo)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed retrieving release artifacts of %s: %s", repoURL, err))
+		}
+	}
+
+	hashes := []fileHash{}
+	unmatched := false
+	for _, path := range args {
+		sha, err := plib.HashFile(path)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed hashing file: %s", err))
+		}
+		h := fileHash{Path: path, SHA256: sha}
+		if index != nil {
+			h.Match = index.Match(sha)
+			unmatched = unmatched || !h.Match.Matched
+		}
+		hashes = append(hashes, h)
+	}
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(hashes)
+	default:
+		out = newFileHashTableOutput(hashes, index != nil)
+	}
+	output(out)
+	if unmatched {
+		os.Exit(1)
+	}
+}
+
+// newFileHashTableOutput renders hashed files as a table. When matched is
+// true, the release artifact each file matched is included.
+func newFileHashTableOutput(hashes []fileHash, matched bool) []byte {
+	header := []string{"Path", "SHA256"}
+	if matched {
+		header = append(header, "Release", "Artifact")
+	}
+	rows := [][]string{}
+	for _, h := range hashes {
+		row := []string{h.Path, h.SHA256}
+		if matched {
+			if h.Match.Matched {
+				row = append(row, h.Match.Release.Tag, h.Match.Artifact.Name)
+			} else {
+				row = append(row, "unknown", fmt.Sprintf("no match in the latest %d releases", h.Match.ReleasesSearched))
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader(header)
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/arctir/proctor/reputation"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// The environment variable read for the VirusTotal API key when
+// --virustotal-key isn't passed.
+const virusTotalKeyEnv = "VT_API_KEY"
+
+// runProcessReputation defines the behavior of running:
+// `proctor process reputation ...`
+// It exits with violationExitCode when a process's binary is flagged.
+func runProcessReputation(cmd *cobra.Command, args []string) {
+	sources := newReputationSources(cmd)
+	if len(sources) == 0 {
+		outputErrorAndFail(fmt.Sprintf("please pass a reputation source with --%s, --%s, --%s, or --%s (or $%s)", allowlistFlag, denylistFlag, nsrlFlag, virusTotalKeyFlag, virusTotalKeyEnv))
+	}
+	ps := liveProcesses(cmd)
+	results, err := reputation.NewChecker(sources...).CheckProcesses(ps)
+	// reputation is an enrichment, so binaries that failed to be looked up
+	// are reported as unknown rather than failing.
+	if err != nil {
+		slog.Warn("failed looking up the reputation of binaries", "error", err)
+	}
+
+	var out []byte
+	switch resolveOutputType(cmd.Flags()) {
+	case jsonOut:
+		out, _ = json.Marshal(results)
+	default:
+		out = newReputationTableOutput(results)
+	}
+	output(out)
+	for _, r := range results {
+		if r.Verdict == reputation.Flagged {
+			os.Exit(violationExitCode)
+		}
+	}
+}
+
+// newReputationSources returns the reputation sources configured by the
+// flags of `proctor process reputation`, in the order they're consulted:
+// denylists, allowlists, the NSRL, then VirusTotal.
+func newReputationSources(cmd *cobra.Command) []reputation.Source {
+	fs := cmd.Flags()
+	sources := []reputation.Source{}
+	lists := []struct {
+		flag    string
+		verdict reputation.Verdict
+	}{{denylistFlag, reputation.Flagged}, {allowlistFlag, reputation.KnownGood}}
+	for _, l := range lists {
+		paths, _ := fs.GetStringSlice(l.flag)
+		for _, path := range paths {
+			list, err := reputation.LoadHashList(l.flag, l.verdict, path)
+			if err != nil {
+				outputErrorAndFail(err.Error())
+			}
+			sources = append(sources, list)
+		}
+	}
+	if path, _ := fs.GetString(nsrlFlag); path != "" {
+		nsrl, err := reputation.LoadNSRL(path)
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+		sources = append(sources, nsrl)
+	}
+	key, _ := fs.GetString(virusTotalKeyFlag)
+	if key == "" {
+		key = os.Getenv(virusTotalKeyEnv)
+	}
+	if key != "" {
+		minDetections, _ := fs.GetInt(minDetectionsFlag)
+		sources = append(sources, reputation.NewVirusTotal(key, reputation.VirusTotalOpts{MinDetections: minDetections}))
+	}
+	return sources
+}
+
+func newReputationTableOutput(results []reputation.ProcessResult) []byte {
+	rows := [][]string{}
+	for _, r := range results {
+		rows = append(rows, []string{
+			strconv.Itoa(r.PID),
+			r.CommandName,
+			r.CommandPath,
+			r.SHA256,
+			string(r.Verdict),
+			r.Source,
+			r.Detail,
+		})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"PID", "Name", "Binary", "SHA256", "Verdict", "Source", "Detail"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arctir/proctor/plib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// runProcessEnv defines the behavior of running:
+// `proctor process env ...`
+func runProcessEnv(cmd *cobra.Command, args []string) {
+	pid, err := parseID(args)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("please pass a valid pid (int); we received: %s", args))
+	}
+	opts := newProctorOptions(cmd.Flags())
+	if redact, _ := cmd.Flags().GetBool(redactFlag); !redact {
+		opts.redactor, _ = plib.NewRedactor(plib.RedactionRules{NoDefaults: true})
+	}
+	ri, err := createResourceInspector(opts)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	env, err := ri.GetEnvironment(pid)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving environment of process %d: %s", pid, err))
+	}
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(newEnvironmentMap(env))
+	default:
+		out = newEnvironmentTableOutput(env)
+	}
+	output(out)
+}
+
+// runProcessPorts defines the behavior of running:
+// `proctor process ports ...`
+func runProcessPorts(cmd *cobra.Command, args []string) {
+	all, _ := cmd.Flags().GetBool(allFlag)
+	var pid int
+	if !all {
+		var err error
+		pid, err = parseID(args)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("please pass a valid pid (int) or --%s; we received: %s", allFlag, args))
+		}
+	}
+	opts := newProctorOptions(cmd.Flags())
+	// the same inspector lists the processes and reads their sockets.
+	insp, err := createInspector(opts)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	ri, ok := insp.(plib.ResourceInspector)
+	if !ok {
+		outputErrorAndFail("retrieving the resources of processes is not supported on this operating system")
+	}
+	if opts.resetCache {
+		insp.ClearProcessCache()
+	}
+	ps, err := insp.GetProcesses()
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("process collection failed: failed retrieving processes via Linux APIs: %s", err))
+	}
+
+	var sockets []plib.ProcessSocket
+	if all {
+		sockets = plib.GetProcessesSockets(ri, ps)
+	} else {
+		if ps[pid] == nil {
+			outputErrorAndFail(fmt.Sprintf("failed to find process with id: %d", pid))
+		}
+		// unlike with --all, failing to read the process's sockets is an error.
+		s, err := ri.GetSockets(pid)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed retrieving sockets of process %d: %s", pid, err))
+		}
+		for _, socket := range s {
+			sockets = append(sockets, plib.ProcessSocket{PID: pid, CommandName: ps[pid].CommandName, Socket: socket})
+		}
+	}
+
+	ports := []plib.ProcessSocket{}
+	for _, s := range sockets {
+		if s.IsListening() || s.IsEstablished() {
+			ports = append(ports, s)
+		}
+	}
+
+	var out []byte
+	switch opts.outType {
+	case jsonOut:
+		out, _ = json.Marshal(ports)
+	default:
+		out = newPortsTableOutput(ports)
+	}
+	output(out)
+}
+
+func newPortsTableOutput(ports []plib.ProcessSocket) []byte {
+	rows := [][]string{}
+	for _, s := range ports {
+		// udp sockets have no state, so each socket is described by whether
+		// it's listening or connected.
+		state := "ESTABLISHED"
+		if s.IsListening() {
+			state = "LISTEN"
+		}
+		rows = append(rows, []string{strconv.Itoa(s.PID), s.CommandName, s.Protocol, s.LocalAddress, s.RemoteAddress, state})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"PID", "Name", "Protocol", "Local", "Remote", "State"})
+	table.SetAutoWrapText(false)
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
+
+// createResourceInspector creates a new inspector configured by opts, failing
+// when it can't retrieve the resources processes hold on this operating
+// system.
+func createResourceInspector(opts proctorOpts) (plib.ResourceInspector, error) {
+	insp, err := createInspector(opts)
+	if err != nil {
+		return nil, err
+	}
+	ri, ok := insp.(plib.ResourceInspector)
+	if !ok {
+		return nil, fmt.Errorf("retrieving the resources of processes is not supported on this operating system")
+	}
+	return ri, nil
+}
+
+// newEnvironmentMap returns env, in KEY=value form, keyed by variable.
+func newEnvironmentMap(env []string) map[string]string {
+	result := map[string]string{}
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		result[key] = value
+	}
+	return result
+}
+
+func newEnvironmentTableOutput(env []string) []byte {
+	rows := [][]string{}
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		rows = append(rows, []string{key, value})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Key", "Value"})
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.AppendBulk(rows)
+	table.Render()
+	return buf.Bytes()
+}
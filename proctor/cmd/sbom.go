@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/sbom"
+	"github.com/spf13/cobra"
+)
+
+const (
+	spdxFormat      = "spdx"
+	cycloneDXFormat = "cyclonedx"
+)
+
+// runRuntimeSBOM defines the behavior of running:
+// `proctor sbom ...`
+func runRuntimeSBOM(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString(sbomFormatFlag)
+	if format != spdxFormat && format != cycloneDXFormat {
+		outputErrorAndFail(fmt.Sprintf("--%s must be %s or %s; we received: %s", sbomFormatFlag, spdxFormat, cycloneDXFormat, format))
+	}
+	allPackages, _ := cmd.Flags().GetBool(allPackagesFlag)
+	inv := collectInventory(cmd, allPackages)
+
+	var out []byte
+	var err error
+	switch format {
+	case cycloneDXFormat:
+		out, err = sbom.CycloneDX(inv)
+	default:
+		out, err = sbom.SPDX(inv)
+	}
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed creating SBOM: %s", err))
+	}
+	output(out)
+}
+
+// collectInventory returns the inventory of the binaries of the live
+// processes, resolving the OS packages that installed them. allPackages
+// includes every installed package in the inventory.
+func collectInventory(cmd *cobra.Command, allPackages bool) sbom.Inventory {
+	ps := liveProcesses(cmd)
+
+	lr := host.NewLinuxReader(host.LinuxReaderConfig{})
+	opts := sbom.CollectOpts{AllPackages: allPackages}
+	if id, err := lr.GetHostID(); err == nil {
+		opts.HostID = id
+	}
+	if osDetails, err := lr.GetOS(); err == nil {
+		opts.OS = *osDetails
+	}
+	// the inventory remains useful without OS packages, such as on hosts using
+	// an unsupported package manager, so they're left out rather than failing.
+	packages, err := lr.GetPackages()
+	if err != nil {
+		slog.Warn("not resolving the packages that installed binaries", "error", err)
+	}
+	opts.Packages = packages
+	return sbom.Collect(ps, opts)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arctir/proctor/baseline"
+	"github.com/arctir/proctor/platforms"
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/provenance"
+	"github.com/arctir/proctor/schema"
+	"github.com/arctir/proctor/source"
+	"github.com/spf13/cobra"
+)
+
+// schemaFunc returns the schema of the JSON cmd outputs, which can depend on
+// its flags.
+type schemaFunc func(cmd *cobra.Command) (*schema.Schema, error)
+
+// registerSchemas adds the --schema flag to the commands with JSON output.
+// When set, the command prints the JSON Schema of its output instead of
+// running, so integrations can validate proctor's output.
+func registerSchemas() {
+	schemas := map[*cobra.Command]schemaFunc{
+		listCmd: func(cmd *cobra.Command) (*schema.Schema, error) {
+			if watch, _ := cmd.Flags().GetBool(watchFlag); watch {
+				return schema.ForOutput("process-event")
+			}
+			return schema.ForOutput("processes")
+		},
+		getCmd: func(cmd *cobra.Command) (*schema.Schema, error) {
+			if id, _ := cmd.Flags().GetInt(idFlag); id != 0 {
+				return schema.ForOutput("process")
+			}
+			return schema.ForOutput("processes")
+		},
+		treeCmd: func(cmd *cobra.Command) (*schema.Schema, error) {
+			if name, _ := cmd.Flags().GetString(nameFlag); name != "" {
+				return schema.ForValue([]*plib.ProcessTree{}, "The tree of every process with a name, as output by `proctor process tree --name`."), nil
+			}
+			return schema.ForOutput("process-tree")
+		},
+		fpCmd: func(cmd *cobra.Command) (*schema.Schema, error) {
+			if all, _ := cmd.Flags().GetBool(allFlag); all {
+				return schema.ForOutput("fingerprint-report")
+			}
+			return nil, fmt.Errorf("only the output of --%s is JSON", allFlag)
+		},
+		fpVerifyCmd:        valueSchema(baselineVerifyResult{}, "The outcome of verifying a process against a baseline, as output by `proctor process fp verify`."),
+		statCmd:            outputSchema("process-stat"),
+		diffCmd:            outputSchema("processes-diff"),
+		killCmd:            valueSchema([]signalResult{}, "The outcome of signaling each matched process, as output by `proctor process kill`."),
+		envCmd:             valueSchema(map[string]string{}, "The environment of a process, as output by `proctor process env`."),
+		portsCmd:           valueSchema([]plib.ProcessSocket{}, "The listening and established sockets of processes, as output by `proctor process ports`."),
+		provenanceCmd:      valueSchema(&provenance.Provenance{}, "The source of a process's binary, as output by `proctor process provenance`."),
+		processArtifactCmd: valueSchema(&provenance.ArtifactMatch{}, "The release artifact a process's binary matched, as output by `proctor process artifact`."),
+		hashCmd:            valueSchema([]fileHash{}, "The SHA256 of files, as output by `proctor process hash`."),
+		baselineCheckCmd:   outputSchema("drift-report"),
+		baselineListCmd:    valueSchema([]baseline.Baseline{}, "The recorded role baselines, as output by `proctor baseline list`."),
+		policyEvalCmd:      outputSchema("policy-report"),
+		hostInfoCmd:        outputSchema("host"),
+		hostIDCmd:          valueSchema("", "The ID of a host, as output by `proctor host id`."),
+		hostHardwareCmd:    outputSchema("host-hardware"),
+		hostContainersCmd:  outputSchema("host-containers"),
+		bundleVerifyCmd:    outputSchema("bundle-manifest"),
+		fleetReportCmd:     outputSchema("fleet-report"),
+		contribListCmd: func(cmd *cobra.Command) (*schema.Schema, error) {
+			owners, _ := cmd.Flags().GetBool(ownersFlag)
+			remote, _ := cmd.Flags().GetBool(remoteFlag)
+			authors, _ := cmd.Flags().GetBool(authorsFlag)
+			byDomain, _ := cmd.Flags().GetBool(byDomainFlag)
+			switch {
+			case owners:
+				return schema.ForValue([]source.OwnerCoverage{}, "The code owners responsible for a repository's commits, as output by `proctor source commits list --owners`."), nil
+			case remote:
+				return schema.ForValue([]platforms.Contributor{}, "The contributors counted by a repository's platform, as output by `proctor source commits list --remote`."), nil
+			case authors:
+				return schema.ForValue([]source.Author{}, "The authors of a repository's commits, as output by `proctor source commits list --authors`."), nil
+			case byDomain:
+				return schema.ForValue([]source.Organization{}, "The authors of a repository's commits grouped by email domain, as output by `proctor source commits list --by-domain`."), nil
+			}
+			return schema.ForValue([]source.CommitRecord{}, "The commits of a repository, as output by `proctor source commits list`."), nil
+		},
+		contribStatsCmd:    valueSchema(source.ContributorStats{}, "The contributor statistics of a repository, as output by `proctor source commits stats`."),
+		contribActivityCmd: valueSchema([]source.ActivityBucket{}, "The commits of a repository per interval, as output by `proctor source commits activity`."),
+		contribGrepCmd:     valueSchema([]source.CommitRecord{}, "The commits whose message matches a pattern, as output by `proctor source commits grep`."),
+	}
+	for c, f := range schemas {
+		c.Flags().Bool(schemaFlag, false, "Print the JSON Schema of the command's JSON output, rather than running it.")
+		c.Run = withSchema(c.Run, f)
+	}
+}
+
+// outputSchema returns a schemaFunc returning the schema of the output with
+// name in [schema.Outputs].
+func outputSchema(name string) schemaFunc {
+	return func(cmd *cobra.Command) (*schema.Schema, error) {
+		return schema.ForOutput(name)
+	}
+}
+
+// valueSchema returns a schemaFunc returning the schema of v, for outputs
+// specific to a command.
+func valueSchema(v interface{}, description string) schemaFunc {
+	return func(cmd *cobra.Command) (*schema.Schema, error) {
+		return schema.ForValue(v, description), nil
+	}
+}
+
+// withSchema wraps run so that, when --schema is set, the schema returned by
+// f is printed instead of running the command.
+func withSchema(run func(cmd *cobra.Command, args []string), f schemaFunc) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if printSchema, _ := cmd.Flags().GetBool(schemaFlag); !printSchema {
+			run(cmd, args)
+			return
+		}
+		s, err := f(cmd)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("%s has no schema: %s", strings.TrimSpace(cmd.CommandPath()), err))
+		}
+		out, err := schema.Marshal(s)
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+		output(out)
+	}
+}
@@ -1,26 +1,24 @@
 package cmd
 
 import (
+	"crypto/x509"
 	"fmt"
+	"io"
 	"os"
-	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/arctir/proctor/cosign"
+	"github.com/arctir/proctor/platforms"
+	// registers GitHub as a platform for platforms.ForURL.
 	"github.com/arctir/proctor/platforms/github"
 	"github.com/arctir/proctor/source"
+	"github.com/arctir/proctor/vuln"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-// authorWrapper contains a source.Person and holds a commmit count the user
-// may wish to change over time.
-type authorWrapper struct {
-	commitCount int
-	source.Person
-}
-
-// authorWrappers exists purely to implement sort.Interface.
-type authorWrappers []authorWrapper
-
 // runSource defines what should occur when `proctor source ...` is run.
 func runSource(cmd *cobra.Command, args []string) {
 	// if proctor is run without a command (argument), print help.
@@ -30,6 +28,43 @@ func runSource(cmd *cobra.Command, args []string) {
 	}
 }
 
+// resolveRepoOpts are the options used whenever a `proctor source ...` command
+// retrieves a repository. They are set from flags by [setupSource].
+var resolveRepoOpts source.ResolveRepoOpts
+
+// setupSource runs before every `proctor source ...` command to apply the
+// flags shared by all of them.
+func setupSource(cmd *cobra.Command, args []string) {
+	timeout, _ := cmd.Flags().GetDuration(timeoutFlag)
+	resolveRepoOpts.Timeout = timeout
+	setupPlatforms(cmd)
+}
+
+// setupPlatforms applies the --no-cache flag of commands retrieving data from
+// platforms such as GitHub.
+func setupPlatforms(cmd *cobra.Command) {
+	if noCache, _ := cmd.Flags().GetBool(noCacheFlag); noCache {
+		github.Register(github.GHManagerConfig{NoCache: true})
+	}
+}
+
+// resolveRepo retrieves the repository at url using [resolveRepoOpts]. When
+// stderr is a terminal, the progress of cloning or fetching the repository is
+// rendered to it.
+func resolveRepo(url string) (*source.Repository, error) {
+	opts := resolveRepoOpts
+	var pp *progressPrinter
+	if isTerminal(os.Stderr) {
+		pp = newProgressPrinter(os.Stderr, url)
+		opts.Progress = pp.print
+	}
+	repo, err := source.ResolveRepo(url, opts)
+	if pp != nil {
+		pp.clear()
+	}
+	return repo, err
+}
+
 // runContrib defines what should occur when `proctor source contrib ...` is
 // run.
 func runContrib(cmd *cobra.Command, args []string) {
@@ -50,6 +85,33 @@ func runArtifacts(cmd *cobra.Command, args []string) {
 	}
 }
 
+// runDiffArtifacts defines what should occur when `proctor source artifacts
+// diff ...` is run.
+func runDiffArtifacts(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+	opts := newSourceOptions(cmd.Flags())
+	if opts.tagOne == "" || opts.tagTwo == "" {
+		outputErrorAndFail(fmt.Sprintf("please specify --%s and --%s when comparing artifacts", tagOneFlag, tagTwoFlag))
+	}
+
+	platform, repo, err := platforms.ForURL(args[0])
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	from, err := platform.GetArtifacts(repo, opts.tagOne)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving artifacts: %s", err))
+	}
+	to, err := platform.GetArtifacts(repo, opts.tagTwo)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving artifacts: %s", err))
+	}
+	output(newArtifactDiffTableOutput(platforms.DiffArtifacts(opts.tagOne, from, opts.tagTwo, to)))
+}
+
 // runListArtifacts defines what should occur when `proctor source
 // artifacts list ...` is run.
 func runListArtifacts(cmd *cobra.Command, args []string) {
@@ -57,17 +119,20 @@ func runListArtifacts(cmd *cobra.Command, args []string) {
 		cmd.Help()
 		os.Exit(0)
 	}
-	gh := github.NewGHManager()
-	repo := strings.Split(args[0], "https://github.com/")
-	if len(repo) < 2 {
-		outputErrorAndFail(fmt.Sprintf("repository (%s) provided was invalid. At this time we only support https://github.com/$ORG/$REPO.", args[0]))
+	opts := newSourceOptions(cmd.Flags())
+	sortBy, _ := cmd.Flags().GetString(sortFlag)
+	if sortBy != "api" && sortBy != "published" {
+		outputErrorAndFail(fmt.Sprintf("invalid --%s (%s), expected api or published", sortFlag, sortBy))
 	}
-	orgAndRepo := repo[1]
-	arts, err := gh.GetArtifacts(orgAndRepo)
+	platform, repo, err := platforms.ForURL(args[0])
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	releases, err := platform.GetReleases(repo)
 	if err != nil {
 		outputErrorAndFail(fmt.Sprintf("failed retrieving artifacts: %s", err))
 	}
-	out := newArtifactListTableOutput(arts)
+	out := newArtifactListTableOutput(platforms.FilterReleases(releases, opts.releaseFilter))
 	output(out)
 }
 
@@ -83,22 +148,14 @@ func runGetArtifacts(cmd *cobra.Command, args []string) {
 		outputErrorAndFail("please specify --tag when looking up artifacts")
 	}
 
-	gh := github.NewGHManager()
-	repo := strings.Split(args[0], "https://github.com/")
-	if len(repo) < 2 {
-		outputErrorAndFail(fmt.Sprintf("repository (%s) provided was invalid. At this time we only support https://github.com/$ORG/$REPO.", args[0]))
+	platform, repo, err := platforms.ForURL(args[0])
+	if err != nil {
+		outputErrorAndFail(err.Error())
 	}
-	orgAndRepo := repo[1]
-	releases, err := gh.GetArtifacts(orgAndRepo)
+	arts, err := platform.GetArtifacts(repo, opts.singleTag)
 	if err != nil {
 		outputErrorAndFail(fmt.Sprintf("failed retrieving artifacts: %s", err))
 	}
-	arts := []github.Artifact{}
-	for _, r := range releases {
-		if r.Tag == opts.singleTag {
-			arts = r.Artifacts
-		}
-	}
 	if len(arts) < 1 {
 		outputErrorAndFail(fmt.Sprintf("failed to find any artifacts for tag (%s)", opts.singleTag))
 	}
@@ -106,6 +163,279 @@ func runGetArtifacts(cmd *cobra.Command, args []string) {
 	output(out)
 }
 
+// The suffixes of the files cosign writes alongside a signed artifact.
+var (
+	signatureSuffixes   = []string{".sig"}
+	certificateSuffixes = []string{".pem", ".cert", ".crt"}
+	bundleSuffixes      = []string{".bundle", ".cosign.bundle"}
+)
+
+// runVerifyArtifact defines what should occur when `proctor source
+// artifacts verify ...` is run.
+func runVerifyArtifact(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+	opts := newSourceOptions(cmd.Flags())
+	name, _ := cmd.Flags().GetString(artifactFlag)
+	if opts.singleTag == "" || name == "" {
+		outputErrorAndFail("please specify --tag and --artifact when verifying an artifact")
+	}
+	conf := newCosignVerifyOpts(cmd.Flags())
+
+	platform, repo, err := platforms.ForURL(args[0])
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	arts, err := platform.GetArtifacts(repo, opts.singleTag)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving artifacts: %s", err))
+	}
+	var artifact *platforms.Artifact
+	for i := range arts {
+		if arts[i].Name == name {
+			artifact = &arts[i]
+		}
+	}
+	if artifact == nil {
+		outputErrorAndFail(fmt.Sprintf("failed to find artifact (%s) in release (%s)", name, opts.singleTag))
+	}
+
+	body, err := platform.DownloadArtifact(*artifact)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	defer body.Close()
+	result, err := verifyArtifactSignature(platform, arts, name, body, conf)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	output(newVerifyTableOutput(name, result))
+}
+
+// newCosignVerifyOpts creates the cosign.VerifyOpts set by the --key, --roots,
+// --certificate-identity, --certificate-oidc-issuer, --skip-tlog, and, when
+// defined, --rekor-url flags.
+func newCosignVerifyOpts(fs *pflag.FlagSet) cosign.VerifyOpts {
+	conf := cosign.VerifyOpts{}
+	conf.SkipTlog, _ = fs.GetBool(skipTlogFlag)
+	conf.RekorURL, _ = fs.GetString(rekorURLFlag)
+	conf.Identity, _ = fs.GetString(certIdentityFlag)
+	conf.Issuer, _ = fs.GetString(certOIDCIssuerFlag)
+	if keyFile, _ := fs.GetString(keyFlag); keyFile != "" {
+		content, err := os.ReadFile(keyFile)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed reading --%s: %s", keyFlag, err))
+		}
+		conf.PublicKey, err = cosign.ParsePublicKey(content)
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+	}
+	if rootsFile, _ := fs.GetString(rootsFlag); rootsFile != "" {
+		content, err := os.ReadFile(rootsFile)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed reading --%s: %s", rootsFlag, err))
+		}
+		conf.Roots = x509.NewCertPool()
+		if !conf.Roots.AppendCertsFromPEM(content) {
+			outputErrorAndFail(fmt.Sprintf("no certificates found in --%s", rootsFlag))
+		}
+	}
+	return conf
+}
+
+// checkSignatureTrusted returns an error unless result is of a signature made
+// by a trusted signer: with --key, or keyless with a certificate chaining to
+// --roots that was issued to the expected identity. Anyone can have a
+// certificate naming any signer logged in Rekor, so a valid keyless signature
+// alone proves nothing.
+func checkSignatureTrusted(result *cosign.Result, conf cosign.VerifyOpts) error {
+	if !result.Trusted {
+		return fmt.Errorf("the signing certificate, issued to %s, isn't trusted, pass the roots it must chain to (e.g. the Fulcio roots) with --%s", result.Signer, rootsFlag)
+	}
+	if conf.PublicKey == nil && (conf.Identity == "" || conf.Issuer == "") {
+		return fmt.Errorf("the signature was made keyless by %s (%s), pass the expected signer with --%s and --%s", result.Signer, result.Issuer, certIdentityFlag, certOIDCIssuerFlag)
+	}
+	return nil
+}
+
+// verifyArtifactSignature verifies content, the content of the artifact named
+// name, with the cosign bundle, or signature and certificate, attached
+// alongside it among arts. An error is returned unless the signature is
+// valid and its signer trusted. See checkSignatureTrusted.
+func verifyArtifactSignature(platform platforms.Platform, arts []platforms.Artifact, name string, content io.Reader, conf cosign.VerifyOpts) (*cosign.Result, error) {
+	result, err := verifyArtifactSidecars(platform, arts, name, content, conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSignatureTrusted(result, conf); err != nil {
+		return nil, fmt.Errorf("verification of (%s) failed: %s", name, err)
+	}
+	return result, nil
+}
+
+// verifyArtifactSidecars verifies content, the content of the artifact named
+// name, with the cosign bundle, or signature and certificate, attached
+// alongside it among arts.
+func verifyArtifactSidecars(platform platforms.Platform, arts []platforms.Artifact, name string, content io.Reader, conf cosign.VerifyOpts) (*cosign.Result, error) {
+	byName := map[string]platforms.Artifact{}
+	for _, a := range arts {
+		byName[a.Name] = a
+	}
+	findSidecar := func(suffixes []string) (platforms.Artifact, bool) {
+		for _, s := range suffixes {
+			if a, ok := byName[name+s]; ok {
+				return a, true
+			}
+		}
+		return platforms.Artifact{}, false
+	}
+	download := func(a platforms.Artifact) ([]byte, error) {
+		body, err := platform.DownloadArtifact(a)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		b, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed downloading artifact (%s): %s", a.Name, err)
+		}
+		return b, nil
+	}
+
+	var result *cosign.Result
+	if b, ok := findSidecar(bundleSuffixes); ok {
+		raw, err := download(b)
+		if err != nil {
+			return nil, err
+		}
+		bundle, err := cosign.ParseBundle(raw)
+		if err != nil {
+			return nil, err
+		}
+		result, err = cosign.VerifyBundle(content, bundle, conf)
+		if err != nil {
+			return nil, fmt.Errorf("verification of (%s) failed: %s", name, err)
+		}
+		return result, nil
+	}
+
+	sigArtifact, ok := findSidecar(signatureSuffixes)
+	if !ok {
+		return nil, fmt.Errorf("failed to find a signature (%s.sig) or bundle (%s.bundle) for artifact (%s)", name, name, name)
+	}
+	sig, err := download(sigArtifact)
+	if err != nil {
+		return nil, err
+	}
+	var cert []byte
+	if c, ok := findSidecar(certificateSuffixes); ok {
+		if cert, err = download(c); err != nil {
+			return nil, err
+		}
+	}
+	result, err = cosign.VerifyBlob(content, sig, cert, conf)
+	if err != nil {
+		return nil, fmt.Errorf("verification of (%s) failed: %s", name, err)
+	}
+	return result, nil
+}
+
+// isSignatureSidecar returns whether the artifact named name is a signature,
+// certificate, or bundle written by cosign alongside another artifact.
+func isSignatureSidecar(name string) bool {
+	for _, suffixes := range [][]string{signatureSuffixes, certificateSuffixes, bundleSuffixes} {
+		for _, s := range suffixes {
+			if strings.HasSuffix(name, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runDownloadArtifacts defines what should occur when `proctor source
+// artifacts download ...` is run.
+func runDownloadArtifacts(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+	opts := newSourceOptions(cmd.Flags())
+	if opts.singleTag == "" {
+		outputErrorAndFail("please specify --tag when downloading artifacts")
+	}
+	patterns, _ := cmd.Flags().GetStringSlice(artifactFlag)
+	dir, _ := cmd.Flags().GetString(dirFlag)
+	requireChecksum, _ := cmd.Flags().GetBool(requireChecksumFlag)
+	verifySignature, _ := cmd.Flags().GetBool(verifySignatureFlag)
+	var conf cosign.VerifyOpts
+	if verifySignature {
+		conf = newCosignVerifyOpts(cmd.Flags())
+	}
+
+	platform, repo, err := platforms.ForURL(args[0])
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	arts, err := platform.GetArtifacts(repo, opts.singleTag)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving artifacts: %s", err))
+	}
+	selected, err := platforms.MatchArtifacts(arts, patterns)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	if len(selected) < 1 {
+		outputErrorAndFail(fmt.Sprintf("failed to find any artifacts matching --%s in release (%s)", artifactFlag, opts.singleTag))
+	}
+	// checked before downloading anything, so a release that can't satisfy
+	// --require-checksum doesn't leave some artifacts behind.
+	if requireChecksum {
+		for _, a := range selected {
+			if a.Digest == "" && !platforms.IsChecksumsFile(a.Name) {
+				outputErrorAndFail(fmt.Sprintf("artifact (%s) isn't listed in a checksums file of release (%s)", a.Name, opts.singleTag))
+			}
+		}
+	}
+
+	results := []artifactDownload{}
+	for _, a := range selected {
+		dlOpts := platforms.DownloadOpts{}
+		var pp *progressPrinter
+		if isTerminal(os.Stderr) {
+			pp = newProgressPrinter(os.Stderr, a.Name)
+			dlOpts.Progress = func(written int64) { pp.printBytes(written, a.Size) }
+		}
+		d, err := platforms.Download(platform, a, dir, dlOpts)
+		if pp != nil {
+			pp.clear()
+		}
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+
+		result := artifactDownload{DownloadedArtifact: d}
+		if verifySignature && !isSignatureSidecar(a.Name) {
+			f, err := os.Open(d.Path)
+			if err != nil {
+				outputErrorAndFail(fmt.Sprintf("failed reading artifact (%s): %s", a.Name, err))
+			}
+			result.Signature, err = verifyArtifactSignature(platform, arts, a.Name, f, conf)
+			f.Close()
+			if err != nil {
+				// an artifact failing verification isn't left for use.
+				os.Remove(d.Path)
+				outputErrorAndFail(err.Error())
+			}
+		}
+		results = append(results, result)
+	}
+	output(newDownloadTableOutput(results))
+}
+
 // runContribSource defines the behavior of running:
 // `proctor process ls ...`
 func runContribList(cmd *cobra.Command, args []string) {
@@ -115,15 +445,56 @@ func runContribList(cmd *cobra.Command, args []string) {
 		os.Exit(0)
 	}
 
+	now := time.Now()
+	since, err := parseTimeFlag(opts.since, now)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid value for --%s: %s", sinceFlag, err))
+	}
+	until, err := parseTimeFlag(opts.until, now)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid value for --%s: %s", untilFlag, err))
+	}
+
+	// when --remote is specified, list the contributors counted by the
+	// platform hosting the repository, which avoids cloning it.
+	if opts.remote {
+		if opts.singleTag != "" || opts.branch != "" || opts.owners || opts.byDomain || opts.since != "" || opts.until != "" {
+			outputErrorAndFail(fmt.Sprintf("--%s can't be combined with --%s, --%s, --%s, --%s, --%s, or --%s, which require the repository's history", remoteFlag, tagFlag, branchFlag, ownersFlag, byDomainFlag, sinceFlag, untilFlag))
+		}
+		platform, repo, err := platforms.ForURL(args[0])
+		if err != nil {
+			outputErrorAndFail(err.Error())
+		}
+		contributors, err := platform.GetContributors(repo)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed retrieving contributors: %s", err))
+		}
+		out, err := newContributorsOutput(contributors, resolveOutputType(cmd.Flags()))
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed creating output for contributors: %s", err))
+		}
+		output(out)
+		return
+	}
+
+	// the repository is resolved once, as --owners reads it again after the
+	// commits are retrieved.
+	repo, err := resolveRepo(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving repository: %s", err))
+	}
+	defer repo.Close()
+	gm := source.NewGitManager()
+
 	commits := []source.Commit{}
-	var err error
 	if opts.singleTag != "" {
-		commits, err = getCommitsForTag(args[0], opts.singleTag)
+		commits, err = getReleaseCommits(repo, opts.singleTag)
 		if err != nil {
 			outputErrorAndFail(fmt.Sprintf("failed resolving commits, underlying error: %s", err))
 		}
+		commits = source.FilterCommitsByTime(commits, since, until)
 	} else {
-		commits, err = getCommits(args[0])
+		commits, err = gm.GetCommits(*repo, source.GetCommitsOpts{Branch: opts.branch, Since: since, Until: until})
 		if err != nil {
 			outputErrorAndFail(fmt.Sprintf("failed resolving commits, underlying error: %s", err))
 		}
@@ -132,18 +503,370 @@ func runContribList(cmd *cobra.Command, args []string) {
 	// when --authors is specified, create an output that exclusively contains
 	// authors.
 	if opts.retrieveOnlyAuthors {
-		authors := getAuthors(commits)
-		// sort by number of commits
-		sort.Sort(authors)
-		out := newAuthorTableOutput(authors)
+		authors := source.GetAuthors(commits)
+		out, err := newAuthorsOutput(authors, resolveOutputType(cmd.Flags()))
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed creating output for authors: %s", err))
+		}
+		output(out)
+		return
+	}
+
+	// when --by-domain is specified, create an output that exclusively
+	// contains authors grouped by their email domain.
+	if opts.byDomain {
+		orgs := source.GetOrganizations(source.GetAuthors(commits), opts.domainAliases)
+		out, err := newOrganizationsOutput(orgs, resolveOutputType(cmd.Flags()))
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed creating output for organizations: %s", err))
+		}
 		output(out)
 		return
 	}
 
-	out := newCommitTableOutput(commits, 30)
+	// when --owners is specified, create an output that exclusively contains
+	// the code owners responsible for the commits.
+	if opts.owners {
+		co, err := gm.GetCodeOwners(*repo, opts.singleTag)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed reading code owners, underlying error: %s", err))
+		}
+		coverage, err := gm.GetOwnerCoverage(*repo, co, commits)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed resolving code owners of commits, underlying error: %s", err))
+		}
+		out, err := newOwnerCoverageOutput(coverage, resolveOutputType(cmd.Flags()))
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed creating output for code owners: %s", err))
+		}
+		output(out)
+		return
+	}
+
+	out, err := newCommitsOutput(commits, resolveOutputType(cmd.Flags()))
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed creating output for commits: %s", err))
+	}
 	output(out)
 }
 
+// runContribStats is the equivelant to `proctor source contrib stats ...`.
+func runContribStats(cmd *cobra.Command, args []string) {
+	opts := newSourceOptions(cmd.Flags())
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+	now := time.Now()
+	since, err := parseTimeFlag(opts.since, now)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid value for --%s: %s", sinceFlag, err))
+	}
+	until, err := parseTimeFlag(opts.until, now)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid value for --%s: %s", untilFlag, err))
+	}
+
+	// the full history is retrieved so new contributors can be detected.
+	commits, err := getCommits(args[0], source.GetCommitsOpts{Branch: opts.branch})
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving commits, underlying error: %s", err))
+	}
+	stats := source.NewContributorStats(commits, source.ContributorStatsOpts{
+		Since: since,
+		Until: until,
+		TopN:  opts.topN,
+	})
+	out, err := newContribStatsOutput(stats, resolveOutputType(cmd.Flags()))
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed creating output for contributor stats: %s", err))
+	}
+	output(out)
+}
+
+// runContribActivity is the equivelant to `proctor source contrib activity ...`.
+func runContribActivity(cmd *cobra.Command, args []string) {
+	opts := newSourceOptions(cmd.Flags())
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+	interval, err := source.ParseActivityInterval(opts.interval)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	since, err := parseTimeFlag(opts.since, time.Now())
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid value for --%s: %s", sinceFlag, err))
+	}
+
+	commits, err := getCommits(args[0], source.GetCommitsOpts{Branch: opts.branch, Since: since})
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving commits, underlying error: %s", err))
+	}
+	out, err := newActivityOutput(source.GetActivity(commits, interval), resolveOutputType(cmd.Flags()))
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed creating output for activity: %s", err))
+	}
+	output(out)
+}
+
+// runContribGrep is the equivelant to `proctor source contrib grep ...`.
+func runContribGrep(cmd *cobra.Command, args []string) {
+	opts := newSourceOptions(cmd.Flags())
+	if len(args) < 2 {
+		cmd.Help()
+		os.Exit(0)
+	}
+	since, err := parseTimeFlag(opts.since, time.Now())
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("invalid value for --%s: %s", sinceFlag, err))
+	}
+	pattern := args[1]
+	if opts.ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	repo, err := resolveRepo(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving repository: %s", err))
+	}
+	defer repo.Close()
+	gm := source.NewGitManager()
+	commits, err := gm.SearchCommits(*repo, pattern, source.GetCommitsOpts{Branch: opts.branch, Since: since})
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed searching commits, underlying error: %s", err))
+	}
+	out, err := newCommitsOutput(commits, resolveOutputType(cmd.Flags()))
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed creating output for commits: %s", err))
+	}
+	output(out)
+}
+
+// runHealth is the equivelant to `proctor source health ...`.
+func runHealth(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+
+	opts := newSourceOptions(cmd.Flags())
+	repo, err := resolveRepo(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving repository: %s", err))
+	}
+	defer repo.Close()
+	healthOpts := source.GetRepoHealthOpts{}
+	if opts.issues {
+		healthOpts.Platform, healthOpts.PlatformRepo, err = platforms.ForURL(args[0])
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed retrieving issue statistics: %s", err))
+		}
+	}
+	gm := source.NewGitManager()
+	health, err := gm.GetRepoHealth(*repo, healthOpts)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed calculating repository health, underlying error: %s", err))
+	}
+	output(newHealthTableOutput(health))
+}
+
+// runSubmodules is the equivelant to `proctor source submodules ...`.
+func runSubmodules(cmd *cobra.Command, args []string) {
+	opts := newSourceOptions(cmd.Flags())
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+
+	repo, err := resolveRepo(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving repository: %s", err))
+	}
+	defer repo.Close()
+	gm := source.NewGitManager()
+	submodules, err := gm.GetSubmodules(*repo, source.GetSubmodulesOpts{Recursive: opts.recursive})
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving submodules, underlying error: %s", err))
+	}
+	output(newSubmoduleTableOutput(submodules))
+}
+
+// runDependencies is the equivelant to `proctor source dependencies ...`.
+func runDependencies(cmd *cobra.Command, args []string) {
+	opts := newSourceOptions(cmd.Flags())
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+
+	repo, err := resolveRepo(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving repository: %s", err))
+	}
+	defer repo.Close()
+	gm := source.NewGitManager()
+	deps, err := gm.GetDependencies(*repo, opts.singleTag)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving dependencies, underlying error: %s", err))
+	}
+	output(newDependencyTableOutput(deps))
+}
+
+// runDependenciesDiff is the equivelant to `proctor source dependencies diff
+// ...`.
+func runDependenciesDiff(cmd *cobra.Command, args []string) {
+	opts := newSourceOptions(cmd.Flags())
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+
+	// when tags aren't provided, default to the latest stable release (or
+	// --to) and the release preceding it.
+	if opts.fromTag == "" {
+		toTag, fromTag, err := resolveReleaseRange(args[0], opts.toTag)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed resolving tags, please provide --from and --to: %s", err))
+		}
+		opts.fromTag = fromTag
+		opts.toTag = toTag
+	}
+
+	repo, err := resolveRepo(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving repository: %s", err))
+	}
+	defer repo.Close()
+	gm := source.NewGitManager()
+	from, err := gm.GetDependencies(*repo, opts.fromTag)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving dependencies at %s, underlying error: %s", opts.fromTag, err))
+	}
+	to, err := gm.GetDependencies(*repo, opts.toTag)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving dependencies at %s, underlying error: %s", opts.toTag, err))
+	}
+	output(newDependencyDiffTableOutput(source.DiffDependencies(from, to)))
+}
+
+// runDependenciesVulns is the equivelant to `proctor source dependencies
+// vulns ...`.
+func runDependenciesVulns(cmd *cobra.Command, args []string) {
+	opts := newSourceOptions(cmd.Flags())
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+
+	repo, err := resolveRepo(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving repository: %s", err))
+	}
+	defer repo.Close()
+	gm := source.NewGitManager()
+	deps, err := gm.GetDependencies(*repo, opts.singleTag)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving dependencies, underlying error: %s", err))
+	}
+	findings, err := vuln.QueryDependencies(deps)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed looking up vulnerabilities, underlying error: %s", err))
+	}
+	output(newFindingTableOutput(findings))
+}
+
+// runAdvisories is the equivelant to `proctor source advisories ...`.
+func runAdvisories(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+
+	platform, platformRepo, err := platforms.ForURL(args[0])
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	advisorySource, ok := platform.(platforms.AdvisorySource)
+	if !ok {
+		outputErrorAndFail(fmt.Sprintf("the platform hosting (%s) does not publish security advisories", args[0]))
+	}
+	advisories, err := advisorySource.GetSecurityAdvisories(platformRepo)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving security advisories: %s", err))
+	}
+
+	repo, err := resolveRepo(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving repository: %s", err))
+	}
+	defer repo.Close()
+	gm := source.NewGitManager()
+	tags, err := gm.GetTagsFromRepository(*repo)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving tags, underlying error: %s", err))
+	}
+	affected, err := vuln.AffectedReleases(advisories, tags)
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	output(newAdvisoryTableOutput(affected))
+}
+
+// runSBOM is the equivelant to `proctor source sbom ...`.
+func runSBOM(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+
+	platform, repo, err := platforms.ForURL(args[0])
+	if err != nil {
+		outputErrorAndFail(err.Error())
+	}
+	sbomSource, ok := platform.(platforms.SBOMSource)
+	if !ok {
+		outputErrorAndFail(fmt.Sprintf("the platform hosting (%s) does not generate SBOMs", args[0]))
+	}
+	sbom, err := sbomSource.GetSBOM(repo)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed retrieving SBOM: %s", err))
+	}
+	output(append(sbom, '\n'))
+}
+
+// parseTimeFlag parses the value of a time-window flag relative to now. It
+// accepts a date (2006-01-02), an RFC 3339 timestamp, or a duration into the
+// past with a unit of d (days), w (weeks), or y (years) such as 90d. An empty
+// value returns the zero time, meaning the window is unbounded.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	units := map[byte]time.Duration{
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+		'y': 365 * 24 * time.Hour,
+	}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%s is not a date (2006-01-02), timestamp (RFC 3339), or duration (e.g. 90d)", value)
+}
+
 // runDiffSource is the equivelent to `proctor source contrib diff ...`.
 func runDiffSource(cmd *cobra.Command, args []string) {
 	opts := newSourceOptions(cmd.Flags())
@@ -151,11 +874,19 @@ func runDiffSource(cmd *cobra.Command, args []string) {
 		cmd.Help()
 		os.Exit(0)
 	}
-	if opts.tagOne == "" {
-		outputErrorAndFail("please provide value for --tag1")
-	}
-	if opts.tagTwo == "" {
-		outputErrorAndFail("please provide value for --tag2")
+	// when tags aren't provided, default to comparing the latest stable
+	// release (or --tag1) against the release that preceded it.
+	if opts.tagOne == "" || opts.tagTwo == "" {
+		tagOne, tagTwo, err := resolveReleaseRange(args[0], opts.tagOne)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed resolving tags to compare, please provide --tag1 and --tag2: %s", err))
+		}
+		if opts.tagOne == "" {
+			opts.tagOne = tagOne
+		}
+		if opts.tagTwo == "" {
+			opts.tagTwo = tagTwo
+		}
 	}
 
 	var err error
@@ -199,9 +930,7 @@ func runDiffSource(cmd *cobra.Command, args []string) {
 	// when --authors is specified, create an output that exclusively contains
 	// authors.
 	if opts.retrieveOnlyAuthors {
-		authors := getAuthors(commitsOnlyInOne)
-		// sort by number of commits
-		sort.Sort(authors)
+		authors := source.GetAuthors(commitsOnlyInOne)
 		out := newAuthorTableOutput(authors)
 		output(out)
 		return
@@ -211,54 +940,117 @@ func runDiffSource(cmd *cobra.Command, args []string) {
 	output(out)
 }
 
-func reverseCommitsOrder(commits []source.Commit) {
-	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
-		commits[i], commits[j] = commits[j], commits[i]
+// resolveReleaseRange returns the tag to analyze and the tag preceding it, by
+// semantic version, for the repository at url. When tag is empty, the latest
+// stable tag is used.
+func resolveReleaseRange(url string, tag string) (string, string, error) {
+	repo, err := resolveRepo(url)
+	if err != nil {
+		return "", "", err
+	}
+	defer repo.Close()
+	gm := source.NewGitManager()
+	tags, err := gm.GetTagsFromRepository(*repo)
+	if err != nil {
+		return "", "", err
+	}
+	if tag == "" {
+		latest, err := source.GetLatestStableTag(tags)
+		if err != nil {
+			return "", "", err
+		}
+		tag = latest.Name
+	}
+	previous, err := source.GetPreviousTag(tags, tag)
+	if err != nil {
+		return "", "", err
 	}
+	return tag, previous.Name, nil
 }
 
-// getAuthors takes a list of commits (c) and returns a slice of those authors.
-func getAuthors(c []source.Commit) authorWrappers {
-	authors := map[string]authorWrapper{}
-	for _, commit := range c {
-		if v, ok := authors[commit.Author.Email]; ok {
-			authors[commit.Author.Email] = authorWrapper{v.commitCount + 1, commit.Author}
-		} else {
-			authors[commit.Author.Email] = authorWrapper{1, commit.Author}
+// runReleaseNotes is the equivelant to `proctor source release-notes ...`.
+func runReleaseNotes(cmd *cobra.Command, args []string) {
+	opts := newSourceOptions(cmd.Flags())
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(0)
+	}
+
+	// when tags aren't provided, default to the notes of the latest stable
+	// release (or --to).
+	if opts.fromTag == "" {
+		toTag, fromTag, err := resolveReleaseRange(args[0], opts.toTag)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("failed resolving tags, please provide --from and --to: %s", err))
 		}
+		opts.fromTag = fromTag
+		opts.toTag = toTag
+	}
+	if opts.toTag == "" {
+		outputErrorAndFail("please provide value for --to")
 	}
 
-	authorList := []authorWrapper{}
-	for _, v := range authors {
-		authorList = append(authorList, v)
+	repo, err := resolveRepo(args[0])
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed resolving repository: %s", err))
+	}
+	defer repo.Close()
+	gm := source.NewGitManager()
+	rn, err := gm.GetReleaseNotes(*repo, opts.fromTag, opts.toTag)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed generating release notes, underlying error: %s", err))
 	}
+	output(rn.Markdown())
+}
 
-	return authorList
+func reverseCommitsOrder(commits []source.Commit) {
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
 }
 
 // getCommits is a healper function that returns all the commits for a
-// repostiory, passed as url.
-func getCommits(url string) ([]source.Commit, error) {
-	repo, err := source.ResolveRepo(url)
+// repostiory, passed as url, constrained by opts.
+func getCommits(url string, opts source.GetCommitsOpts) ([]source.Commit, error) {
+	repo, err := resolveRepo(url)
 	if err != nil {
 		return nil, err
 	}
+	defer repo.Close()
 
 	gm := source.NewGitManager()
-	commits, err := gm.GetCommits(*repo)
+	commits, err := gm.GetCommits(*repo, opts)
 	if err != nil {
 		return nil, err
 	}
 	return commits, nil
 }
 
+// getReleaseCommits returns the commits introduced in the release tagName,
+// meaning those since the release preceding it by semantic version. When no
+// preceding release is found (e.g. tagName is the first release or is not a
+// semantic version), every commit reachable from tagName is returned.
+func getReleaseCommits(repo *source.Repository, tagName string) ([]source.Commit, error) {
+	gm := source.NewGitManager()
+	tags, err := gm.GetTagsFromRepository(*repo)
+	if err != nil {
+		return nil, err
+	}
+	previous := ""
+	if prevTag, err := source.GetPreviousTag(tags, tagName); err == nil {
+		previous = prevTag.Name
+	}
+	return gm.GetCommitsBetween(*repo, previous, tagName)
+}
+
 // getCommits is a healper function that returns all the commits for a
 // repostiory, passed as url.
 func getCommitsForTag(url string, tagName string) ([]source.Commit, error) {
-	repo, err := source.ResolveRepo(url)
+	repo, err := resolveRepo(url)
 	if err != nil {
 		return nil, err
 	}
+	defer repo.Close()
 
 	gm := source.NewGitManager()
 	commits, err := gm.GetCommitsForTag(tagName, *repo)
@@ -267,7 +1059,3 @@ func getCommitsForTag(url string, tagName string) ([]source.Commit, error) {
 	}
 	return commits, nil
 }
-
-func (a authorWrappers) Len() int           { return len(a) }
-func (a authorWrappers) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a authorWrappers) Less(i, j int) bool { return a[j].commitCount < a[i].commitCount }
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// createTreeOutput renders t in the output type requested in opts. Tables are
+// rendered as an indented tree, similar to pstree, and JSON keeps the nesting
+// of t. Custom formats are rendered for each process, parents before their
+// children.
+func createTreeOutput(t *plib.ProcessTree, opts proctorOpts) ([]byte, error) {
+	switch opts.outType {
+	case jsonOut:
+		return json.Marshal(t)
+	case customColumnsOut, goTemplateOut:
+		ps := []*plib.Process{}
+		t.Walk(func(p *plib.Process, depth int) {
+			ps = append(ps, p)
+		})
+		return createFormattedOutput(ps, opts)
+	}
+	var buf bytes.Buffer
+	writeTree(&buf, t, "", "")
+	return buf.Bytes(), nil
+}
+
+// createTreesOutput renders trees, such as those of every process sharing a
+// name, in the output type requested in opts. JSON is an array of the trees
+// and tables are separated by a blank line.
+func createTreesOutput(trees []*plib.ProcessTree, opts proctorOpts) ([]byte, error) {
+	switch opts.outType {
+	case jsonOut:
+		return json.Marshal(trees)
+	case customColumnsOut, goTemplateOut:
+		ps := []*plib.Process{}
+		for _, t := range trees {
+			t.Walk(func(p *plib.Process, depth int) {
+				ps = append(ps, p)
+			})
+		}
+		return createFormattedOutput(ps, opts)
+	}
+	var buf bytes.Buffer
+	for i, t := range trees {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		writeTree(&buf, t, "", "")
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTree writes a line for the process of t, starting with prefix, then the
+// lines of its children, which are indented with childPrefix and connected to
+// it by branches.
+func writeTree(buf *bytes.Buffer, t *plib.ProcessTree, prefix, childPrefix string) {
+	p := t.Process
+	fmt.Fprintf(buf, "%s%d %s", prefix, p.ID, p.CommandName)
+	if p.CommandPath != "" {
+		fmt.Fprintf(buf, " (%s)", p.CommandPath)
+	}
+	buf.WriteString("\n")
+	for i, c := range t.Children {
+		if i == len(t.Children)-1 {
+			writeTree(buf, c, childPrefix+"└─ ", childPrefix+"   ")
+			continue
+		}
+		writeTree(buf, c, childPrefix+"├─ ", childPrefix+"│  ")
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/arctir/proctor/vuln"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// runProcessVulns defines the behavior of running:
+// `proctor process vulns ...`
+// It exits with violationExitCode when running components are affected by
+// vulnerabilities of at least the --severity, or whose severity is unknown.
+func runProcessVulns(cmd *cobra.Command, args []string) {
+	severity, _ := cmd.Flags().GetString(severityFlag)
+	if severity != "" {
+		var err error
+		severity, err = vuln.ParseSeverity(severity)
+		if err != nil {
+			outputErrorAndFail(fmt.Sprintf("invalid --%s: %s", severityFlag, err))
+		}
+	}
+	inv := collectInventory(cmd, false)
+	findings, err := vuln.QueryProcesses(inv)
+	if err != nil {
+		outputErrorAndFail(fmt.Sprintf("failed looking up vulnerabilities, underlying error: %s", err))
+	}
+	if severity != "" {
+		filtered := []vuln.ProcessFinding{}
+		for _, f := range findings {
+			// vulnerabilities without a published severity, such as those of
+			// the Go vulnerability database, may be severe, so they're kept.
+			if f.Vulnerability.Severity == "" || f.Vulnerability.AtLeast(severity) {
+				filtered = append(filtered, f)
+			}
+		}
+		findings = filtered
+	}
+
+	var out []byte
+	switch resolveOutputType(cmd.Flags()) {
+	case jsonOut:
+		out, _ = json.Marshal(findings)
+	default:
+		out = newProcessFindingTableOutput(findings)
+	}
+	output(out)
+	if len(findings) > 0 {
+		os.Exit(violationExitCode)
+	}
+}
+
+func newProcessFindingTableOutput(findings []vuln.ProcessFinding) []byte {
+	rows := [][]string{}
+	for _, f := range findings {
+		pids := []string{}
+		for _, pid := range f.Component.PIDs {
+			pids = append(pids, strconv.Itoa(pid))
+		}
+		rows = append(rows, []string{
+			f.Component.Name,
+			f.Component.Version,
+			f.Vulnerability.ID,
+			strings.Join(f.Vulnerability.Aliases, ", "),
+			f.Vulnerability.Severity,
+			strings.Join(f.FixedVersions, ", "),
+			strings.Join(pids, ", "),
+			strings.Join(f.Component.Binaries, ", "),
+		})
+	}
+
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+	table.SetHeader([]string{"Component", "Version", "Vulnerability", "Aliases", "Severity", "Fixed In", "PIDs", "Binaries"})
+	table.AppendBulk(rows)
+	table.SetAutoWrapText(false)
+	table.Render()
+	return buf.Bytes()
+}
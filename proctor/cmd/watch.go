@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// clearScreen moves the cursor to the top left of the terminal and clears it,
+// so the process table can be re-rendered in place.
+const clearScreen = "\033[H\033[2J"
+
+// watchProcesses lists processes, then updates the listing as processes start
+// and exit until interrupted. For JSON output, a JSON line is streamed for
+// each [plib.ProcessEvent]. Otherwise, the listing is re-rendered in place
+// after each change.
+func watchProcesses(opts proctorOpts, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	insp, err := createInspector(opts)
+	if err != nil {
+		return err
+	}
+	// held while reading the inspector's processes, which Watch replaces
+	// when it loads them.
+	var lock sync.Mutex
+	events, err := plib.Watch(ctx, insp, plib.WatchOpts{Interval: interval, Lock: &lock})
+	if err != nil {
+		return err
+	}
+	// the processes Watch starts from, which events are applied to. Applying
+	// an event is idempotent, so the listing is correct even when Watch has
+	// already loaded newer processes.
+	lock.Lock()
+	baseline, err := insp.GetProcesses()
+	ps := plib.Processes{}
+	for id, p := range baseline {
+		ps[id] = p
+	}
+	lock.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed retrieving processes via Linux APIs: %s", err)
+	}
+
+	if opts.outType == jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		for e := range events {
+			if !opts.filter.Matches(&e.Process) {
+				continue
+			}
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	render := func() error {
+		out, err := createListOutput(ps.Filter(opts.filter), opts)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%sEvery %s, last updated %s. Press Ctrl+C to stop.\n\n%s", clearScreen, interval, time.Now().Format(timeDateFormat), out)
+		return nil
+	}
+	if err := render(); err != nil {
+		return err
+	}
+	for e := range events {
+		applyProcessEvent(ps, e)
+		// events from the same lookup arrive together, so the listing is
+		// rendered once they've all been applied.
+		for drained := false; !drained; {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return nil
+				}
+				applyProcessEvent(ps, e)
+			default:
+				drained = true
+			}
+		}
+		if err := render(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyProcessEvent updates ps with the process started or exited in e.
+func applyProcessEvent(ps plib.Processes, e plib.ProcessEvent) {
+	switch e.Type {
+	case plib.ProcessStarted:
+		p := e.Process
+		ps[p.ID] = &p
+	case plib.ProcessExited:
+		delete(ps, e.Process.ID)
+	}
+}
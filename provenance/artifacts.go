@@ -0,0 +1,139 @@
+package provenance
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/arctir/proctor/platforms"
+	"github.com/arctir/proctor/plib"
+)
+
+// The number of releases, newest first, searched by [MatchBinary] and
+// [NewArtifactIndex] when not specified.
+const DefaultMaxReleases = 10
+
+// ArtifactMatch is the release artifact a binary was found to be, by
+// comparing the binary's SHA256 with the digests of release artifacts.
+type ArtifactMatch struct {
+	// The SHA256 of the binary that was searched for.
+	BinarySHA string
+	// Whether an artifact with the binary's digest was found. When false, the
+	// binary is unknown: it may have been built locally, modified, or
+	// extracted from an archive artifact, whose digest covers the archive
+	// rather than the binary.
+	Matched bool
+	// The release containing the matching artifact.
+	Release platforms.Release
+	// The matching artifact.
+	Artifact platforms.Artifact
+	// The number of releases searched.
+	ReleasesSearched int
+}
+
+// MatchOpts configures how release artifacts are searched.
+type MatchOpts struct {
+	// The number of releases, newest first, to search. Defaults to
+	// [DefaultMaxReleases].
+	MaxReleases int
+}
+
+// MatchProcess searches the releases of repo on platform for the artifact
+// the binary of p was downloaded from, using the SHA256 recorded in
+// p.BinarySHA. See [MatchBinary].
+func MatchProcess(p plib.Process, platform platforms.Platform, repo string, opts ...MatchOpts) (*ArtifactMatch, error) {
+	if len(p.BinarySHA) != 64 {
+		return nil, fmt.Errorf("process %d has no binary SHA256 to match: %s", p.ID, p.BinarySHA)
+	}
+	return MatchBinary(p.BinarySHA, platform, repo, opts...)
+}
+
+// MatchBinary searches the releases of repo on platform for an artifact whose
+// digest is binarySHA, the hex encoded SHA256 of a binary. See
+// [NewArtifactIndex]; to match several binaries against the same repository,
+// create an index once and use [ArtifactIndex.Match].
+func MatchBinary(binarySHA string, platform platforms.Platform, repo string, opts ...MatchOpts) (*ArtifactMatch, error) {
+	index, err := NewArtifactIndex(platform, repo, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return index.Match(binarySHA), nil
+}
+
+// ArtifactIndex holds the digests of the artifacts of a repository's
+// releases, so binaries can be matched to release artifacts without
+// retrieving the releases again.
+type ArtifactIndex struct {
+	// matching artifacts, keyed by digest. When artifacts of several
+	// releases share a digest, the newest release is kept.
+	artifacts map[string]indexedArtifact
+	// the number of releases indexed.
+	releases int
+}
+
+// indexedArtifact is an artifact held in an [ArtifactIndex], along with the
+// release it is attached to.
+type indexedArtifact struct {
+	release  platforms.Release
+	artifact platforms.Artifact
+}
+
+// NewArtifactIndex retrieves the releases of repo on platform, newest first,
+// and indexes the digests of their artifacts. Artifact digests are read from
+// the checksums files attached to each release, so the artifacts themselves
+// aren't downloaded. An error is returned when releases or checksums files
+// cannot be retrieved.
+func NewArtifactIndex(platform platforms.Platform, repo string, opts ...MatchOpts) (*ArtifactIndex, error) {
+	conf := MatchOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if conf.MaxReleases < 1 {
+		conf.MaxReleases = DefaultMaxReleases
+	}
+	releases, err := platform.GetReleases(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &ArtifactIndex{artifacts: map[string]indexedArtifact{}}
+	download := func(a platforms.Artifact) ([]byte, error) {
+		body, err := platform.DownloadArtifact(a)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	for _, r := range releases {
+		if index.releases >= conf.MaxReleases {
+			break
+		}
+		index.releases++
+		// releases already list their artifacts, so only the checksums files
+		// are downloaded rather than retrieving each release again.
+		artifacts := append([]platforms.Artifact{}, r.Artifacts...)
+		if err := platforms.AttachDigests(artifacts, download); err != nil {
+			return nil, fmt.Errorf("failed retrieving artifacts of release (%s): %s", r.Tag, err)
+		}
+		for _, a := range artifacts {
+			if _, ok := index.artifacts[a.Digest]; a.Digest != "" && !ok {
+				index.artifacts[a.Digest] = indexedArtifact{release: r, artifact: a}
+			}
+		}
+	}
+	return index, nil
+}
+
+// Match returns the artifact in the index whose digest is binarySHA, the hex
+// encoded SHA256 of a binary. When no artifact matches, the returned match
+// has Matched set to false.
+func (i *ArtifactIndex) Match(binarySHA string) *ArtifactMatch {
+	match := &ArtifactMatch{BinarySHA: binarySHA, ReleasesSearched: i.releases}
+	if a, ok := i.artifacts["sha256:"+strings.ToLower(binarySHA)]; ok {
+		match.Matched = true
+		match.Release = a.release
+		match.Artifact = a.artifact
+	}
+	return match
+}
@@ -0,0 +1,135 @@
+package provenance
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/platforms"
+	"github.com/arctir/proctor/plib"
+)
+
+const testBinarySHA = "6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b"
+
+// fakePlatform serves a fixed set of releases and the content of their
+// checksums files.
+type fakePlatform struct {
+	releases []platforms.Release
+	// the content of artifacts, keyed by URL.
+	content map[string]string
+	// the number of requests made, keyed by method.
+	calls map[string]int
+}
+
+func (f fakePlatform) GetReleases(repo string) ([]platforms.Release, error) {
+	if f.calls != nil {
+		f.calls["GetReleases"]++
+	}
+	return f.releases, nil
+}
+
+func (f fakePlatform) GetArtifacts(repo string, tag string) ([]platforms.Artifact, error) {
+	for _, r := range f.releases {
+		if r.Tag == tag {
+			return r.Artifacts, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f fakePlatform) GetRepoMetadata(repo string) (platforms.RepoMetadata, error) {
+	return platforms.RepoMetadata{}, nil
+}
+
+func (f fakePlatform) GetContributors(repo string) ([]platforms.Contributor, error) {
+	return nil, nil
+}
+
+func (f fakePlatform) DownloadArtifact(a platforms.Artifact) (io.ReadCloser, error) {
+	if f.calls != nil {
+		f.calls["DownloadArtifact"]++
+	}
+	content, ok := f.content[a.URL]
+	if !ok {
+		return nil, fmt.Errorf("artifact (%s) does not exist", a.Name)
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (f fakePlatform) GetIssueStats(repo string) (platforms.IssueStats, error) {
+	return platforms.IssueStats{}, nil
+}
+
+func TestMatchBinary(t *testing.T) {
+	platform := fakePlatform{releases: []platforms.Release{
+		{Tag: "v1.1.0", Artifacts: []platforms.Artifact{{Name: "proctor_linux_amd64", Digest: "sha256:00"}}},
+		{Tag: "v1.0.0", Artifacts: []platforms.Artifact{{Name: "proctor_linux_amd64", Digest: "sha256:" + testBinarySHA}}},
+	}}
+
+	match, err := MatchProcess(plib.Process{ID: 1, BinarySHA: testBinarySHA}, platform, "arctir/proctor")
+	if err != nil {
+		t.Fatalf("fail: unexpected error matching binary: %s", err)
+	}
+	if !match.Matched || match.Release.Tag != "v1.0.0" || match.Artifact.Name != "proctor_linux_amd64" {
+		t.Logf("fail: match was wrong: %+v", match)
+		t.Fail()
+	}
+
+	match, err = MatchBinary(testBinarySHA, platform, "arctir/proctor", MatchOpts{MaxReleases: 1})
+	if err != nil {
+		t.Fatalf("fail: unexpected error matching binary: %s", err)
+	}
+	if match.Matched || match.ReleasesSearched != 1 {
+		t.Logf("fail: expected no match when searching only the latest release: %+v", match)
+		t.Fail()
+	}
+
+	if _, err := MatchProcess(plib.Process{ID: 1, BinarySHA: "permission denied"}, platform, "arctir/proctor"); err == nil {
+		t.Log("fail: expected error matching a process without a binary SHA")
+		t.Fail()
+	}
+}
+
+func TestArtifactIndex(t *testing.T) {
+	otherSHA := strings.Repeat("a", 64)
+	platform := fakePlatform{
+		releases: []platforms.Release{
+			{Tag: "v1.1.0", Artifacts: []platforms.Artifact{
+				{Name: "proctor_linux_amd64"},
+				{Name: "checksums.txt", URL: "v1.1.0/checksums.txt"},
+			}},
+			{Tag: "v1.0.0", Artifacts: []platforms.Artifact{
+				{Name: "proctor_linux_amd64"},
+				{Name: "checksums.txt", URL: "v1.0.0/checksums.txt"},
+			}},
+		},
+		content: map[string]string{
+			"v1.1.0/checksums.txt": otherSHA + "  proctor_linux_amd64\n",
+			"v1.0.0/checksums.txt": testBinarySHA + "  proctor_linux_amd64\n",
+		},
+		calls: map[string]int{},
+	}
+
+	index, err := NewArtifactIndex(platform, "arctir/proctor")
+	if err != nil {
+		t.Fatalf("fail: unexpected error indexing artifacts: %s", err)
+	}
+	for sha, tag := range map[string]string{testBinarySHA: "v1.0.0", otherSHA: "v1.1.0"} {
+		match := index.Match(sha)
+		if !match.Matched || match.Release.Tag != tag || match.ReleasesSearched != 2 {
+			t.Logf("fail: expected %s to match release %s: %+v", sha, tag, match)
+			t.Fail()
+		}
+	}
+	if match := index.Match(strings.Repeat("b", 64)); match.Matched {
+		t.Logf("fail: expected no match for an unknown binary: %+v", match)
+		t.Fail()
+	}
+	// releases are retrieved once, and each checksums file downloaded once,
+	// regardless of the number of binaries matched.
+	if platform.calls["GetReleases"] != 1 || platform.calls["DownloadArtifact"] != 2 {
+		t.Logf("fail: expected 1 release listing and 2 downloads, actual: %v", platform.calls)
+		t.Fail()
+	}
+}
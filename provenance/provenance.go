@@ -0,0 +1,317 @@
+// provenance is a package that resolves running processes and binaries back to
+// the source code they were built from. It currently supports Go binaries,
+// which embed the module and version control details of their build.
+package provenance
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/source"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const (
+	// The version Go reports for the main module when it was built from a
+	// local checkout rather than a versioned module download.
+	develVersion = "(devel)"
+	// The amount of time to wait when looking up a vanity import path's
+	// repository.
+	goImportTimeout = 10 * time.Second
+)
+
+var (
+	// matches the commit hash at the end of a pseudo-version, such as
+	// v0.0.0-20221026131551-cf6655e29de4.
+	pseudoVersionRegex = regexp.MustCompile(`\d{14}-([0-9a-f]{12})(?:\+incompatible)?$`)
+	// matches a major version suffix of a module path, such as /v2.
+	majorVersionSuffixRegex = regexp.MustCompile(`/v[0-9]+$`)
+	// matches the go-import meta tag served for vanity import paths.
+	goImportRegex = regexp.MustCompile(`<meta\s+name="go-import"\s+content="([^"]+)"`)
+	// Hosts where a repository is identified by the first three elements of a
+	// module path (host/owner/repo).
+	knownHosts = map[string]bool{
+		"github.com":    true,
+		"gitlab.com":    true,
+		"bitbucket.org": true,
+	}
+)
+
+// BuildInfo contains the details a Go binary embeds about how it was built.
+type BuildInfo struct {
+	// The version of Go used to build the binary.
+	GoVersion string
+	// The path of the main module, such as github.com/arctir/proctor.
+	ModulePath string
+	// The version of the main module. Binaries built from a local checkout
+	// report (devel); those built using go install with a version report it
+	// (e.g. v1.2.3).
+	ModuleVersion string
+	// The version control system the binary was built from, such as git.
+	VCS string
+	// The commit the binary was built from. Only set when the binary was built
+	// from a local checkout with VCS stamping enabled.
+	Revision     string
+	RevisionTime time.Time
+	// Whether the checkout contained uncommitted changes at build time.
+	Modified bool
+	// The modules the binary was built with, excluding the main module.
+	Deps []Module
+}
+
+// Module is a Go module a binary was built with.
+type Module struct {
+	Path    string
+	Version string
+	// The checksum of the module, as found in go.sum (e.g. h1:...).
+	Sum string
+}
+
+// Provenance describes the source code a binary was built from.
+type Provenance struct {
+	BuildInfo
+	// The URL of the repository containing the main module.
+	RepoURL string
+	// The commit the binary was built from.
+	Commit source.Commit
+	// The tags pointing at Commit, if any.
+	Tags []string
+}
+
+// ResolveOpts configures how provenance is resolved.
+type ResolveOpts struct {
+	// The options used to retrieve the module's repository.
+	ResolveRepoOpts source.ResolveRepoOpts
+}
+
+// ResolveProcess resolves the source code the binary of p was built from. See
+// [ResolveBinary] for details.
+func ResolveProcess(p plib.Process, opts ...ResolveOpts) (*Provenance, error) {
+	if p.CommandPath == "" {
+		return nil, fmt.Errorf("process (%d) has no known binary path, which may be due to missing permissions", p.ID)
+	}
+	return ResolveBinary(p.CommandPath, opts...)
+}
+
+// ResolveBinary reads the build information of the Go binary at path,
+// retrieves the repository of its main module with [source.ResolveRepo], and
+// returns the commit and tags the binary was built from. The commit is found
+// using the VCS revision stamped into the binary or, for binaries installed at
+// a version, the tag or pseudo-version of the module. An error is returned
+// when the binary isn't a Go binary, its repository can't be retrieved, or
+// the commit can't be found in the repository.
+func ResolveBinary(path string, opts ...ResolveOpts) (*Provenance, error) {
+	conf := ResolveOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	bi, err := ReadBuildInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	if bi.ModulePath == "" {
+		return nil, fmt.Errorf("binary (%s) was not built from a module, its source can't be resolved", path)
+	}
+	repoURL, err := RepoURLFromModulePath(bi.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := source.ResolveRepo(repoURL, conf.ResolveRepoOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed resolving repository (%s) of module (%s): %s", repoURL, bi.ModulePath, err)
+	}
+	defer repo.Close()
+	return resolveInRepo(*repo, *bi, moduleSubdir(bi.ModulePath, repoURL))
+}
+
+// ReadBuildInfo returns the build information embedded in the Go binary at
+// path. An error is returned when the file can't be read or isn't a Go
+// binary.
+func ReadBuildInfo(path string) (*BuildInfo, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading go build info from binary (%s): %s", path, err)
+	}
+	bi := newBuildInfo(info)
+	return &bi, nil
+}
+
+// Self returns the build information of the running binary, such as the
+// version and commit of proctor itself.
+func Self() (*BuildInfo, error) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil, fmt.Errorf("failed reading go build info of the running binary, which wasn't built with module support")
+	}
+	bi := newBuildInfo(info)
+	return &bi, nil
+}
+
+// RepoURLFromModulePath returns the URL of the repository containing the
+// module at modulePath. For modules hosted on GitHub, GitLab, and Bitbucket,
+// the URL is derived from the path. For other modules, such as those using a
+// vanity import path, the repository is looked up using the go-import meta
+// tag served at the module's path, which requires network access.
+func RepoURLFromModulePath(modulePath string) (string, error) {
+	parts := strings.Split(modulePath, "/")
+	if knownHosts[parts[0]] {
+		if len(parts) < 3 {
+			return "", fmt.Errorf("module path (%s) does not contain a repository", modulePath)
+		}
+		return "https://" + strings.Join(parts[:3], "/"), nil
+	}
+
+	client := http.Client{Timeout: goImportTimeout}
+	resp, err := client.Get("https://" + modulePath + "?go-get=1")
+	if err != nil {
+		return "", fmt.Errorf("failed looking up repository of module (%s): %s", modulePath, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed looking up repository of module (%s): %s", modulePath, err)
+	}
+	return parseGoImport(modulePath, body)
+}
+
+// parseGoImport returns the repository URL of the go-import meta tag in body
+// whose prefix matches modulePath. Only git repositories are supported.
+func parseGoImport(modulePath string, body []byte) (string, error) {
+	for _, match := range goImportRegex.FindAllSubmatch(body, -1) {
+		fields := strings.Fields(string(match[1]))
+		if len(fields) != 3 {
+			continue
+		}
+		prefix, vcs, repoURL := fields[0], fields[1], fields[2]
+		if modulePath != prefix && !strings.HasPrefix(modulePath, prefix+"/") {
+			continue
+		}
+		if vcs != "git" {
+			return "", fmt.Errorf("module (%s) is hosted in %s, only git is supported", modulePath, vcs)
+		}
+		return repoURL, nil
+	}
+	return "", fmt.Errorf("no go-import meta tag found for module (%s)", modulePath)
+}
+
+// newBuildInfo converts the build information read from a binary into a
+// [BuildInfo].
+func newBuildInfo(info *debug.BuildInfo) BuildInfo {
+	bi := BuildInfo{
+		GoVersion:     info.GoVersion,
+		ModulePath:    info.Main.Path,
+		ModuleVersion: info.Main.Version,
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs":
+			bi.VCS = s.Value
+		case "vcs.revision":
+			bi.Revision = s.Value
+		case "vcs.time":
+			bi.RevisionTime, _ = time.Parse(time.RFC3339, s.Value)
+		case "vcs.modified":
+			bi.Modified = s.Value == "true"
+		}
+	}
+	for _, dep := range info.Deps {
+		// a replaced module is built from its replacement.
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		bi.Deps = append(bi.Deps, Module{Path: dep.Path, Version: dep.Version, Sum: dep.Sum})
+	}
+	return bi
+}
+
+// moduleSubdir returns the directory of the module within its repository,
+// which is empty when the module is at the root. For example, the module
+// github.com/org/repo/tools/v2 is in the tools directory of
+// github.com/org/repo.
+func moduleSubdir(modulePath string, repoURL string) string {
+	repoPath := strings.TrimSuffix(strings.TrimPrefix(repoURL, "https://"), ".git")
+	modulePath = majorVersionSuffixRegex.ReplaceAllString(modulePath, "")
+	// vanity import paths don't share a prefix with their repository, in
+	// which case the module is assumed to be at the root.
+	if !strings.HasPrefix(modulePath, repoPath) {
+		return ""
+	}
+	return strings.Trim(strings.TrimPrefix(modulePath, repoPath), "/")
+}
+
+// resolveInRepo finds the commit described by bi within r. subdir is the
+// directory of the module within r, which prefixes the tags of modules not at
+// the root of the repository (e.g. tools/v1.0.0).
+func resolveInRepo(r source.Repository, bi BuildInfo, subdir string) (*Provenance, error) {
+	rev := bi.Revision
+	if rev == "" {
+		rev = revisionFromVersion(bi.ModuleVersion, subdir)
+	}
+	if rev == "" {
+		return nil, fmt.Errorf("binary has no vcs revision or module version to resolve a commit from; it may have been built with -buildvcs=false")
+	}
+	hash, err := r.RepoRef.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed finding revision (%s) in repository (%s): %s", rev, r.URL, err)
+	}
+
+	gm := source.NewGitManager()
+	commit, err := gm.GetCommit(r, source.Hash(*hash))
+	if err != nil {
+		return nil, err
+	}
+	prov := &Provenance{BuildInfo: bi, RepoURL: r.URL, Commit: commit}
+	prov.Tags, err = tagsPointingAt(r, *hash)
+	if err != nil {
+		return nil, err
+	}
+	return prov, nil
+}
+
+// revisionFromVersion returns the git revision identified by a module
+// version. Pseudo-versions identify a commit by its abbreviated hash, while
+// other versions are tags. An empty string is returned for (devel) builds.
+func revisionFromVersion(version string, subdir string) string {
+	if version == "" || version == develVersion {
+		return ""
+	}
+	if match := pseudoVersionRegex.FindStringSubmatch(version); match != nil {
+		return match[1]
+	}
+	tag := strings.TrimSuffix(version, "+incompatible")
+	if subdir != "" {
+		tag = subdir + "/" + tag
+	}
+	return tag
+}
+
+// tagsPointingAt returns the names of the tags, lightweight or annotated,
+// that point at commit.
+func tagsPointingAt(r source.Repository, commit plumbing.Hash) ([]string, error) {
+	refs, err := r.RepoRef.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving tags: %s", err)
+	}
+	tags := []string{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		if tag, err := r.RepoRef.TagObject(target); err == nil {
+			target = tag.Target
+		}
+		if target == commit {
+			tags = append(tags, ref.Name().Short())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving tags: %s", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,176 @@
+package provenance
+
+import (
+	"os"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/arctir/proctor/source"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func TestReadBuildInfo(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("fail: unable to find test binary: %s", err)
+	}
+	bi, err := ReadBuildInfo(exe)
+	if err != nil {
+		t.Fatalf("fail: unexpected error reading build info of test binary: %s", err)
+	}
+	if bi.GoVersion == "" {
+		t.Log("fail: go version was not read from test binary")
+		t.Fail()
+	}
+
+	if _, err := ReadBuildInfo("/does/not/exist"); err == nil {
+		t.Log("fail: expected error reading build info of missing binary")
+		t.Fail()
+	}
+}
+
+func TestNewBuildInfo(t *testing.T) {
+	bi := newBuildInfo(&debug.BuildInfo{
+		GoVersion: "go1.19.3",
+		Main:      debug.Module{Path: "github.com/arctir/proctor", Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "b3bcf00a1d4ed5a7a1ef0a2f2a0a5a4d01dfe2a1"},
+			{Key: "vcs.time", Value: "2022-12-01T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+		Deps: []*debug.Module{
+			{Path: "github.com/spf13/cobra", Version: "v1.6.1", Sum: "h1:abc="},
+			{Path: "golang.org/x/sys", Version: "v0.3.0", Replace: &debug.Module{Path: "golang.org/x/sys", Version: "v0.4.0"}},
+		},
+	})
+	if bi.ModulePath != "github.com/arctir/proctor" || bi.VCS != "git" || !bi.Modified {
+		t.Logf("fail: build info was wrong: %+v", bi)
+		t.Fail()
+	}
+	if bi.RevisionTime != time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC) {
+		t.Logf("fail: revision time was wrong: %s", bi.RevisionTime)
+		t.Fail()
+	}
+	if len(bi.Deps) != 2 || bi.Deps[0].Sum != "h1:abc=" || bi.Deps[1].Version != "v0.4.0" {
+		t.Logf("fail: dependencies were wrong: %+v", bi.Deps)
+		t.Fail()
+	}
+}
+
+func TestRepoURLFromModulePath(t *testing.T) {
+	url, err := RepoURLFromModulePath("github.com/google/go-github/v48")
+	if err != nil {
+		t.Fatalf("fail: unexpected error resolving repo url: %s", err)
+	}
+	if url != "https://github.com/google/go-github" {
+		t.Logf("fail: repo url was wrong: %s", url)
+		t.Fail()
+	}
+	if sub := moduleSubdir("github.com/org/repo/tools/v2", "https://github.com/org/repo"); sub != "tools" {
+		t.Logf("fail: module subdir was wrong: %s", sub)
+		t.Fail()
+	}
+
+	page := []byte(`<html><head>
+<meta name="go-import" content="go.uber.org/zap git https://github.com/uber-go/zap">
+</head></html>`)
+	url, err = parseGoImport("go.uber.org/zap/zapcore", page)
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing go-import: %s", err)
+	}
+	if url != "https://github.com/uber-go/zap" {
+		t.Logf("fail: go-import repo url was wrong: %s", url)
+		t.Fail()
+	}
+	if _, err := parseGoImport("example.com/other", page); err == nil {
+		t.Log("fail: expected error parsing go-import for a different module")
+		t.Fail()
+	}
+}
+
+func TestResolveInRepo(t *testing.T) {
+	r, hashes, err := createTestRepo()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo: %s", err)
+	}
+
+	// a local build stamped with the vcs revision
+	prov, err := resolveInRepo(*r, BuildInfo{Revision: hashes[0].String(), ModuleVersion: develVersion}, "")
+	if err != nil {
+		t.Fatalf("fail: unexpected error resolving revision: %s", err)
+	}
+	if prov.Commit.Hash != source.Hash(hashes[0]) || len(prov.Tags) != 1 || prov.Tags[0] != "v1.0.0" {
+		t.Logf("fail: provenance of revision was wrong: %+v", prov)
+		t.Fail()
+	}
+
+	// go install of a module at a tagged version
+	prov, err = resolveInRepo(*r, BuildInfo{ModuleVersion: "v1.0.0"}, "")
+	if err != nil {
+		t.Fatalf("fail: unexpected error resolving version: %s", err)
+	}
+	if prov.Commit.Hash != source.Hash(hashes[0]) {
+		t.Logf("fail: provenance of version was wrong: %+v", prov)
+		t.Fail()
+	}
+
+	// go install of a module at a pseudo-version
+	pseudo := "v1.0.1-0.20230101120000-" + hashes[1].String()[:12]
+	prov, err = resolveInRepo(*r, BuildInfo{ModuleVersion: pseudo}, "")
+	if err != nil {
+		t.Fatalf("fail: unexpected error resolving pseudo-version: %s", err)
+	}
+	if prov.Commit.Hash != source.Hash(hashes[1]) || len(prov.Tags) != 0 {
+		t.Logf("fail: provenance of pseudo-version was wrong: %+v", prov)
+		t.Fail()
+	}
+
+	if _, err := resolveInRepo(*r, BuildInfo{ModuleVersion: develVersion}, ""); err == nil {
+		t.Log("fail: expected error resolving a build without a revision or version")
+		t.Fail()
+	}
+}
+
+// createTestRepo creates an in-memory repository with 2 commits, the first of
+// which is tagged v1.0.0. The hashes of the commits are returned in order.
+func createTestRepo() (*source.Repository, []plumbing.Hash, error) {
+	r, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		return nil, nil, err
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := []plumbing.Hash{}
+	for i, content := range []string{"one", "two"} {
+		f, err := wt.Filesystem.Create("main.go")
+		if err != nil {
+			return nil, nil, err
+		}
+		f.Write([]byte(content))
+		f.Close()
+		if _, err := wt.Add("main.go"); err != nil {
+			return nil, nil, err
+		}
+		sig := &object.Signature{Name: "Jane", Email: "jane@example.com", When: time.Date(2023, 1, 1+i, 12, 0, 0, 0, time.UTC)}
+		hash, err := wt.Commit(content, &git.CommitOptions{Author: sig, Committer: sig})
+		if err != nil {
+			return nil, nil, err
+		}
+		hashes = append(hashes, hash)
+		if i == 0 {
+			_, err = r.CreateTag("v1.0.0", hash, &git.CreateTagOptions{Tagger: sig, Message: "v1.0.0"})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return &source.Repository{URL: "fake-url", RepoRef: r}, hashes, nil
+}
@@ -0,0 +1,176 @@
+package reputation
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// The name of the source created by [LoadNSRL].
+	NSRLSource = "nsrl"
+	// The column of the SHA256s in NSRL CSV exports.
+	nsrlSHA256Column = "sha256"
+)
+
+// HashList is a source of the binaries in a set of SHA256s, such as an
+// allowlist, a denylist, or an NSRL hash set. Every listed binary has the
+// same verdict.
+type HashList struct {
+	name    string
+	verdict Verdict
+	// SHA256s are stored decoded, halving the memory of large hash sets such
+	// as the NSRL's.
+	hashes map[[32]byte]struct{}
+}
+
+// NewHashList returns a source, identified by name, giving the binaries with
+// the SHA256s in hashes the verdict.
+func NewHashList(name string, verdict Verdict, hashes []string) (*HashList, error) {
+	l := &HashList{name: name, verdict: verdict, hashes: map[[32]byte]struct{}{}}
+	for _, h := range hashes {
+		if err := l.add(h); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// LoadHashList reads a list of SHA256s from the file at path and returns a
+// source, identified by name, giving the listed binaries the verdict. The
+// file lists a SHA256 per line, optionally followed by whitespace and text
+// such as the output of sha256sum. Blank lines and lines starting with # are
+// ignored.
+func LoadHashList(name string, verdict Verdict, path string) (*HashList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading hash list: %s", err)
+	}
+	defer f.Close()
+	l := &HashList{name: name, verdict: verdict, hashes: map[[32]byte]struct{}{}}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := l.add(strings.Fields(line)[0]); err != nil {
+			return nil, fmt.Errorf("failed reading hash list %s, line %d: %s", path, n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading hash list %s: %s", path, err)
+	}
+	return l, nil
+}
+
+// LoadNSRL reads an NSRL hash set from the file at path and returns a source
+// giving its binaries the KnownGood verdict. The NSRL's Reference Data Set is
+// published as a SQLite database, so the SHA256s must be exported from it,
+// such as with:
+//
+//	sqlite3 -csv -header RDS.db "SELECT sha256, file_name FROM FILE" > nsrl.csv
+//
+// A CSV file whose header has a sha256 column (in any case) is read, as is a
+// file listing a SHA256 per line; see [LoadHashList].
+func LoadNSRL(path string) (*HashList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading NSRL hash set: %s", err)
+	}
+	defer f.Close()
+	header, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed reading NSRL hash set %s: %s", path, err)
+	}
+	if !strings.Contains(strings.ToLower(header), nsrlSHA256Column) {
+		return LoadHashList(NSRLSource, KnownGood, path)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed reading NSRL hash set %s: %s", path, err)
+	}
+	r := csv.NewReader(bufio.NewReader(f))
+	r.ReuseRecord = true
+	r.FieldsPerRecord = -1
+	columns, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading NSRL hash set %s: %s", path, err)
+	}
+	column := -1
+	for i, c := range columns {
+		if strings.EqualFold(strings.TrimSpace(c), nsrlSHA256Column) {
+			column = i
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("failed reading NSRL hash set %s: no %s column in header %q", path, nsrlSHA256Column, strings.TrimSpace(header))
+	}
+	l := &HashList{name: NSRLSource, verdict: KnownGood, hashes: map[[32]byte]struct{}{}}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed reading NSRL hash set %s: %s", path, err)
+		}
+		if column >= len(record) {
+			continue
+		}
+		if err := l.add(record[column]); err != nil {
+			line, _ := r.FieldPos(column)
+			return nil, fmt.Errorf("failed reading NSRL hash set %s, line %d: %s", path, line, err)
+		}
+	}
+	return l, nil
+}
+
+// Name returns the name of the list.
+func (l *HashList) Name() string {
+	return l.name
+}
+
+// Len returns the number of SHA256s in the list.
+func (l *HashList) Len() int {
+	return len(l.hashes)
+}
+
+// Lookup returns the verdict of the list when it has sha, otherwise Unknown.
+func (l *HashList) Lookup(sha string) (Verdict, string, error) {
+	key, err := decodeSHA256(sha)
+	if err != nil {
+		return Unknown, "", err
+	}
+	if _, ok := l.hashes[key]; ok {
+		return l.verdict, "", nil
+	}
+	return Unknown, "", nil
+}
+
+// add adds the hex encoded SHA256 sha to the list.
+func (l *HashList) add(sha string) error {
+	key, err := decodeSHA256(sha)
+	if err != nil {
+		return err
+	}
+	l.hashes[key] = struct{}{}
+	return nil
+}
+
+// decodeSHA256 decodes the hex encoded SHA256 sha, in either case.
+func decodeSHA256(sha string) ([32]byte, error) {
+	var key [32]byte
+	sha = strings.TrimSpace(sha)
+	if len(sha) != hex.EncodedLen(len(key)) {
+		return key, fmt.Errorf("invalid SHA256 %q: expected 64 hex characters", sha)
+	}
+	if _, err := hex.Decode(key[:], []byte(sha)); err != nil {
+		return key, fmt.Errorf("invalid SHA256 %q: %s", sha, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,134 @@
+// Package reputation looks up the reputation of process binaries, by their
+// SHA256, in sources such as local allow and deny lists, NSRL hash sets, and
+// VirusTotal, classifying each as known-good, unknown, or flagged.
+//
+// A [Checker] consults its sources in order and the first source to know a
+// binary decides its verdict. Placing local lists first lets them override
+// remote sources, such as a VirusTotal false positive, and saves the quota of
+// those sources.
+package reputation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// Verdict is the reputation of a binary.
+type Verdict string
+
+const (
+	// The binary is known to be legitimate, such as one listed in an
+	// allowlist or the NSRL.
+	KnownGood Verdict = "known-good"
+	// No source knows the binary.
+	Unknown Verdict = "unknown"
+	// The binary is known to be malicious or unwanted, such as one listed in a
+	// denylist or detected by VirusTotal's engines.
+	Flagged Verdict = "flagged"
+)
+
+// Result is the reputation of a binary.
+type Result struct {
+	SHA256  string
+	Verdict Verdict
+	// The name of the source that decided the verdict, such as nsrl. Empty
+	// when the verdict is unknown.
+	Source string
+	// Details the source reported, such as the number of VirusTotal engines
+	// detecting the binary.
+	Detail string
+}
+
+// ProcessResult is the reputation of a process's binary.
+type ProcessResult struct {
+	PID         int
+	CommandName string
+	CommandPath string
+	Result
+}
+
+// Source is a source of binary reputations.
+type Source interface {
+	// Name identifies the source in results, such as denylist.
+	Name() string
+	// Lookup returns the verdict of the binary with the SHA256 sha, along
+	// with details, or Unknown when the source doesn't know it.
+	Lookup(sha string) (Verdict, string, error)
+}
+
+// Checker checks the reputation of binaries against its sources. Results are
+// cached, so each binary is looked up once.
+type Checker struct {
+	sources []Source
+	cache   map[string]Result
+}
+
+// NewChecker returns a checker consulting sources in order.
+func NewChecker(sources ...Source) *Checker {
+	return &Checker{sources: sources, cache: map[string]Result{}}
+}
+
+// Check returns the reputation of the binary with the SHA256 sha, as decided
+// by the first source knowing it. Sources that fail to look the binary up are
+// skipped. When no source knows the binary, and a source failed, the unknown
+// result is returned along with the errors of the failed sources.
+func (c *Checker) Check(sha string) (Result, error) {
+	sha = strings.ToLower(sha)
+	if r, ok := c.cache[sha]; ok {
+		return r, nil
+	}
+	errs := []error{}
+	for _, s := range c.sources {
+		verdict, detail, err := s.Lookup(sha)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed looking up %s in %s: %s", sha, s.Name(), err))
+			continue
+		}
+		if verdict != Unknown {
+			r := Result{SHA256: sha, Verdict: verdict, Source: s.Name(), Detail: detail}
+			c.cache[sha] = r
+			return r, nil
+		}
+	}
+	r := Result{SHA256: sha, Verdict: Unknown}
+	if len(errs) > 0 {
+		// failed lookups aren't cached, so they're retried.
+		return r, errors.Join(errs...)
+	}
+	c.cache[sha] = r
+	return r, nil
+}
+
+// CheckProcesses returns the reputation of the binary of each process in ps,
+// ordered by PID. Processes whose binary wasn't hashed, such as kernel
+// threads, are left out. The errors of failed lookups are returned along
+// with every result.
+func (c *Checker) CheckProcesses(ps plib.Processes) ([]ProcessResult, error) {
+	results := []ProcessResult{}
+	errs := []error{}
+	// binaries that failed are looked up once, rather than for each process.
+	checked := map[string]Result{}
+	for _, p := range ps {
+		if p.BinarySHA == "" {
+			continue
+		}
+		r, ok := checked[p.BinarySHA]
+		if !ok {
+			var err error
+			r, err = c.Check(p.BinarySHA)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			checked[p.BinarySHA] = r
+		}
+		results = append(results, ProcessResult{PID: p.ID, CommandName: p.CommandName, CommandPath: p.CommandPath, Result: r})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].PID < results[j].PID
+	})
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,166 @@
+package reputation
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// sha returns a fake SHA256 made of c.
+func sha(c string) string {
+	return strings.Repeat(c, 64)
+}
+
+// failingSource is a source whose lookups always fail.
+type failingSource struct{}
+
+func (failingSource) Name() string {
+	return "failing"
+}
+
+func (failingSource) Lookup(sha string) (Verdict, string, error) {
+	return Unknown, "", fmt.Errorf("unavailable")
+}
+
+func TestCheckProcesses(t *testing.T) {
+	allow, _ := NewHashList("allowlist", KnownGood, []string{sha("a"), sha("b")})
+	deny, _ := NewHashList("denylist", Flagged, []string{sha("b"), sha("c")})
+	// the allowlist is first, so it overrides the denylist for b.
+	c := NewChecker(allow, deny)
+	results, err := c.CheckProcesses(plib.Processes{
+		3: {ID: 3, CommandName: "c", BinarySHA: sha("c")},
+		1: {ID: 1, CommandName: "a", BinarySHA: sha("a")},
+		2: {ID: 2, CommandName: "b", BinarySHA: sha("b")},
+		4: {ID: 4, CommandName: "d", BinarySHA: sha("d")},
+		5: {ID: 5, CommandName: "kthreadd"},
+	})
+	if err != nil {
+		t.Fatalf("fail: unexpected error checking processes: %s", err)
+	}
+	expected := []struct {
+		pid     int
+		verdict Verdict
+		source  string
+	}{{1, KnownGood, "allowlist"}, {2, KnownGood, "allowlist"}, {3, Flagged, "denylist"}, {4, Unknown, ""}}
+	if len(results) != len(expected) {
+		t.Fatalf("fail: expected %d results, actual: %+v", len(expected), results)
+	}
+	for i, e := range expected {
+		r := results[i]
+		if r.PID != e.pid || r.Verdict != e.verdict || r.Source != e.source {
+			t.Logf("fail: expected PID %d to be %s by %q, actual: %+v", e.pid, e.verdict, e.source, r)
+			t.Fail()
+		}
+	}
+
+	// a failing source is skipped, and its error returned when no other
+	// source knows the binary.
+	c = NewChecker(failingSource{}, deny)
+	if r, err := c.Check(sha("c")); err != nil || r.Verdict != Flagged {
+		t.Logf("fail: expected the failing source to be skipped, actual: %+v, %v", r, err)
+		t.Fail()
+	}
+	if r, err := c.Check(sha("d")); err == nil || r.Verdict != Unknown {
+		t.Logf("fail: expected an unknown result and error, actual: %+v, %v", r, err)
+		t.Fail()
+	}
+}
+
+func TestLoadHashList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deny.txt")
+	os.WriteFile(path, []byte("# known bad\n"+strings.ToUpper(sha("a"))+"  /tmp/miner\n\n"+sha("b")+"\n"), 0644)
+	l, err := LoadHashList("denylist", Flagged, path)
+	if err != nil {
+		t.Fatalf("fail: unexpected error loading hash list: %s", err)
+	}
+	if verdict, _, _ := l.Lookup(sha("a")); l.Len() != 2 || verdict != Flagged {
+		t.Logf("fail: expected 2 hashes with a flagged, actual: %d, %s", l.Len(), verdict)
+		t.Fail()
+	}
+	if verdict, _, _ := l.Lookup(sha("c")); verdict != Unknown {
+		t.Logf("fail: expected an unlisted hash to be unknown, actual: %s", verdict)
+		t.Fail()
+	}
+
+	os.WriteFile(path, []byte("not-a-hash\n"), 0644)
+	if _, err := LoadHashList("denylist", Flagged, path); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Logf("fail: expected an error for the invalid line, actual: %v", err)
+		t.Fail()
+	}
+}
+
+func TestLoadNSRL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nsrl.csv")
+	os.WriteFile(path, []byte("\"SHA256\",\"file_name\"\n\""+sha("a")+"\",\"bash\"\n"+sha("b")+",\"ls, v2\"\n"), 0644)
+	l, err := LoadNSRL(path)
+	if err != nil {
+		t.Fatalf("fail: unexpected error loading NSRL: %s", err)
+	}
+	if verdict, _, _ := l.Lookup(sha("b")); l.Name() != NSRLSource || l.Len() != 2 || verdict != KnownGood {
+		t.Logf("fail: expected 2 known-good hashes, actual: %s, %d, %s", l.Name(), l.Len(), verdict)
+		t.Fail()
+	}
+
+	// a plain list of hashes is read too.
+	os.WriteFile(path, []byte(sha("c")+"\n"), 0644)
+	if l, err := LoadNSRL(path); err != nil || l.Len() != 1 {
+		t.Logf("fail: expected a hash list to be read, actual: %v", err)
+		t.Fail()
+	}
+}
+
+func TestVirusTotal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-apikey") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v3/files/" + sha("a"):
+			w.Write([]byte(`{"data": {"attributes": {"meaningful_name": "xmrig", "last_analysis_stats": {"malicious": 40, "suspicious": 2, "undetected": 28}}}}`))
+		case "/api/v3/files/" + sha("b"):
+			w.Write([]byte(`{"data": {"attributes": {"last_analysis_stats": {"harmless": 1, "undetected": 70}}}}`))
+		case "/api/v3/files/" + sha("d"):
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/api/v3/files/" + sha("e"):
+			w.Write([]byte(`{"data": {"attributes": {"last_analysis_stats": {"suspicious": 1, "undetected": 70}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	vt := NewVirusTotal("key", VirusTotalOpts{URL: server.URL})
+	tests := map[string]struct {
+		sha     string
+		verdict Verdict
+		detail  string
+		err     bool
+	}{
+		"malicious": {sha("a"), Flagged, "40/70 engines detected it as malicious, 2 as suspicious (xmrig)", false},
+		"clean":     {sha("b"), KnownGood, "0/71 engines detected it as malicious", false},
+		"not found": {sha("c"), Unknown, "", false},
+		"quota":     {sha("d"), Unknown, "", true},
+		// detections short of the minimum don't make a binary known-good.
+		"suspicious": {sha("e"), Unknown, "0/71 engines detected it as malicious, 1 as suspicious", false},
+	}
+	for name, test := range tests {
+		verdict, detail, err := vt.Lookup(test.sha)
+		if verdict != test.verdict || detail != test.detail || (err != nil) != test.err {
+			t.Logf("fail: %s: expected (%s, %q, error: %t), actual: (%s, %q, %v)", name, test.verdict, test.detail, test.err, verdict, detail, err)
+			t.Fail()
+		}
+	}
+
+	vt = NewVirusTotal("key", VirusTotalOpts{URL: server.URL, MinDetections: 50})
+	if verdict, detail, _ := vt.Lookup(sha("a")); verdict != Unknown || detail == "" {
+		t.Logf("fail: expected a binary detected by fewer than the minimum engines to be unknown, actual: (%s, %q)", verdict, detail)
+		t.Fail()
+	}
+}
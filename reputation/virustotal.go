@@ -0,0 +1,133 @@
+package reputation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// The name of the source created by [NewVirusTotal].
+	VirusTotalSource = "virustotal"
+	// The public VirusTotal API.
+	DefaultVirusTotalURL = "https://www.virustotal.com"
+	// The amount of time to wait for VirusTotal to respond.
+	virusTotalTimeout = 30 * time.Second
+)
+
+// VirusTotalOpts configures how VirusTotal is queried.
+type VirusTotalOpts struct {
+	// The base URL of the VirusTotal API. Defaults to [DefaultVirusTotalURL].
+	URL string
+	// The number of engines that must detect a binary as malicious for it to
+	// be flagged. Defaults to 1.
+	MinDetections int
+	// The client used to query VirusTotal. Defaults to a client with a 30
+	// second timeout.
+	HTTPClient *http.Client
+}
+
+// VirusTotal is a source of the reputations of binaries analyzed by
+// VirusTotal's engines. Binaries detected as malicious by at least
+// MinDetections engines are flagged, and only those analyzed without any
+// malicious or suspicious detections are known-good. Binaries with fewer
+// detections are unknown, so other sources are still consulted. The public API allows 4 lookups a minute, so lookups
+// exceeding the quota of the key fail.
+type VirusTotal struct {
+	apiKey string
+	conf   VirusTotalOpts
+}
+
+// vtFile is a file report as returned by VirusTotal's API.
+type vtFile struct {
+	Data struct {
+		Attributes struct {
+			MeaningfulName    string `json:"meaningful_name"`
+			LastAnalysisStats struct {
+				Harmless   int `json:"harmless"`
+				Malicious  int `json:"malicious"`
+				Suspicious int `json:"suspicious"`
+				Undetected int `json:"undetected"`
+			} `json:"last_analysis_stats"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// NewVirusTotal returns a source looking binaries up in VirusTotal with the
+// API key apiKey.
+func NewVirusTotal(apiKey string, opts ...VirusTotalOpts) *VirusTotal {
+	conf := VirusTotalOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if conf.URL == "" {
+		conf.URL = DefaultVirusTotalURL
+	}
+	if conf.MinDetections < 1 {
+		conf.MinDetections = 1
+	}
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = &http.Client{Timeout: virusTotalTimeout}
+	}
+	return &VirusTotal{apiKey: apiKey, conf: conf}
+}
+
+// Name returns the name of the source, virustotal.
+func (vt *VirusTotal) Name() string {
+	return VirusTotalSource
+}
+
+// Lookup returns the verdict of VirusTotal's last analysis of the binary with
+// the SHA256 sha, with the number of engines detecting it as details.
+func (vt *VirusTotal) Lookup(sha string) (Verdict, string, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(vt.conf.URL, "/")+"/api/v3/files/"+url.PathEscape(sha), nil)
+	if err != nil {
+		return Unknown, "", fmt.Errorf("failed creating VirusTotal request: %s", err)
+	}
+	req.Header.Set("x-apikey", vt.apiKey)
+	req.Header.Set("Accept", "application/json")
+	res, err := vt.conf.HTTPClient.Do(req)
+	if err != nil {
+		return Unknown, "", fmt.Errorf("failed querying VirusTotal: %s", err)
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return Unknown, "", nil
+	case http.StatusTooManyRequests:
+		return Unknown, "", fmt.Errorf("failed querying VirusTotal: the quota of the API key is exceeded")
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return Unknown, "", fmt.Errorf("failed querying VirusTotal: %s %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	file := vtFile{}
+	if err := json.NewDecoder(res.Body).Decode(&file); err != nil {
+		return Unknown, "", fmt.Errorf("failed decoding VirusTotal response: %s", err)
+	}
+	stats := file.Data.Attributes.LastAnalysisStats
+	total := stats.Harmless + stats.Malicious + stats.Suspicious + stats.Undetected
+	if total == 0 {
+		return Unknown, "", nil
+	}
+	detail := fmt.Sprintf("%d/%d engines detected it as malicious", stats.Malicious, total)
+	if stats.Suspicious > 0 {
+		detail += fmt.Sprintf(", %d as suspicious", stats.Suspicious)
+	}
+	if name := file.Data.Attributes.MeaningfulName; name != "" {
+		detail += fmt.Sprintf(" (%s)", name)
+	}
+	switch {
+	case stats.Malicious >= vt.conf.MinDetections:
+		return Flagged, detail, nil
+	case stats.Malicious+stats.Suspicious > 0:
+		// detections short of MinDetections don't vouch for the binary.
+		return Unknown, detail, nil
+	}
+	return KnownGood, detail, nil
+}
@@ -0,0 +1,137 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const cycloneDXVersion = "1.5"
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	BOMRef     string         `json:"bom-ref,omitempty"`
+	Type       string         `json:"type"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version,omitempty"`
+	PURL       string         `json:"purl,omitempty"`
+	Hashes     []cdxHash      `json:"hashes,omitempty"`
+	Properties []cdxProperty  `json:"properties,omitempty"`
+	Components []cdxComponent `json:"components,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// CycloneDX encodes inv as a CycloneDX 1.5 JSON document. The host is the
+// subject of the document. Binaries are application components, nested in
+// the operating-system package that installed them, if any, and depend on
+// the Go modules, described as library components, they were built with.
+func CycloneDX(inv Inventory) ([]byte, error) {
+	doc := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXVersion,
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: inv.Created.UTC().Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: toolName}}},
+			Component: cdxComponent{
+				BOMRef:  "host",
+				Type:    "operating-system",
+				Name:    documentName(inv),
+				Version: strings.TrimSpace(inv.OS.Name + " " + inv.OS.Version),
+			},
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{},
+	}
+
+	modules, deps := inv.modules()
+	binaries := map[string][]cdxComponent{}
+	for _, b := range inv.Binaries {
+		ref := "binary:" + b.Path
+		pids := make([]string, len(b.PIDs))
+		for i, pid := range b.PIDs {
+			pids[i] = fmt.Sprint(pid)
+		}
+		binaries[b.Package] = append(binaries[b.Package], cdxComponent{
+			BOMRef:  ref,
+			Type:    "application",
+			Name:    b.Path,
+			Version: inv.version(b),
+			Hashes:  []cdxHash{{Alg: "SHA-256", Content: b.SHA256}},
+			Properties: []cdxProperty{
+				{Name: "proctor:path", Value: b.Path},
+				{Name: "proctor:pids", Value: strings.Join(pids, ",")},
+			},
+		})
+		doc.Dependencies = append(doc.Dependencies, cdxDependency{Ref: ref, DependsOn: deps[b.Path]})
+	}
+	for _, p := range inv.Packages {
+		purl := inv.packagePURL(p)
+		doc.Components = append(doc.Components, cdxComponent{
+			BOMRef:     purl,
+			Type:       "library",
+			Name:       p.Name,
+			Version:    p.Version,
+			PURL:       purl,
+			Properties: []cdxProperty{{Name: "proctor:package-manager", Value: p.Manager}},
+			Components: binaries[p.Name],
+		})
+	}
+	// binaries not installed by a package are top-level components.
+	doc.Components = append(doc.Components, binaries[""]...)
+	for _, purl := range sortedKeys(modules) {
+		m := modules[purl]
+		c := cdxComponent{BOMRef: purl, Type: "library", Name: m.Path, Version: m.Version, PURL: purl}
+		if m.Sum != "" {
+			c.Properties = []cdxProperty{{Name: "proctor:go-sum", Value: m.Sum}}
+		}
+		doc.Components = append(doc.Components, c)
+	}
+	return json.Marshal(doc)
+}
+
+// sortedKeys returns the keys of m, sorted.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,222 @@
+// Package sbom generates a runtime software bill of materials (SBOM) of a
+// host. Unlike an SBOM produced at build time, which describes what a build
+// could ship, a runtime SBOM describes what is actually executing: the
+// binaries of running processes, with their hashes, the Go modules they were
+// built with, and the operating-system packages that installed them.
+//
+// An [Inventory] is collected from processes and packages, then encoded as an
+// SPDX or CycloneDX JSON document with [SPDX] or [CycloneDX].
+package sbom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/provenance"
+)
+
+const (
+	// The name of the tool reported as the creator of documents.
+	toolName = "proctor"
+	// The version reported for the main module of binaries built from a local
+	// checkout.
+	develVersion = "(devel)"
+)
+
+// Inventory is the software executing on a host.
+type Inventory struct {
+	// The unique identifier of the host, such as its machine-id.
+	HostID  string
+	OS      host.OS
+	Created time.Time
+	// The binaries of running processes, sorted by path.
+	Binaries []Binary
+	// The operating-system packages described by the inventory, sorted by
+	// name. By default, these are the packages that installed Binaries.
+	Packages []host.Package
+}
+
+// Binary is a binary being run by one or more processes.
+type Binary struct {
+	Path   string
+	SHA256 string
+	// The IDs of the processes running the binary, sorted.
+	PIDs []int
+	// The name of the operating-system package that installed the binary, if
+	// any.
+	Package string
+	// The build information of the binary, when it's a Go binary.
+	BuildInfo *provenance.BuildInfo
+}
+
+// CollectOpts configures how an [Inventory] is collected.
+type CollectOpts struct {
+	HostID string
+	OS     host.OS
+	// The operating-system packages installed on the host, used to resolve
+	// the package that installed each binary.
+	Packages []host.Package
+	// Whether every package in Packages is included in the inventory, rather
+	// than only those that installed a running binary.
+	AllPackages bool
+	// Reads the build information of the binary at a path. By default,
+	// [provenance.ReadBuildInfo] is used.
+	ReadBuildInfo func(path string) (*provenance.BuildInfo, error)
+}
+
+// Collect returns the inventory of the binaries run by ps. Processes whose
+// binary path or hash is unknown, such as kernel threads, are left out.
+func Collect(ps plib.Processes, opts ...CollectOpts) Inventory {
+	var opt CollectOpts
+	if len(opts) > 0 {
+		opt = opts[len(opts)-1]
+	}
+	if opt.ReadBuildInfo == nil {
+		opt.ReadBuildInfo = provenance.ReadBuildInfo
+	}
+	owners := map[string]int{}
+	for i, p := range opt.Packages {
+		for _, f := range p.Files {
+			owners[f] = i
+		}
+	}
+
+	inv := Inventory{
+		HostID:   opt.HostID,
+		OS:       opt.OS,
+		Created:  time.Now().UTC(),
+		Binaries: []Binary{},
+		Packages: []host.Package{},
+	}
+	binaries := map[string]*Binary{}
+	for _, p := range ps {
+		if p.CommandPath == "" || len(p.BinarySHA) != 64 {
+			continue
+		}
+		b, ok := binaries[p.CommandPath]
+		if !ok {
+			b = &Binary{Path: p.CommandPath, SHA256: p.BinarySHA}
+			if bi, err := opt.ReadBuildInfo(p.CommandPath); err == nil {
+				b.BuildInfo = bi
+			}
+			binaries[p.CommandPath] = b
+		}
+		b.PIDs = append(b.PIDs, p.ID)
+	}
+
+	used := map[int]bool{}
+	for _, b := range binaries {
+		sort.Ints(b.PIDs)
+		if i, ok := ownerOf(owners, b.Path); ok {
+			b.Package = opt.Packages[i].Name
+			used[i] = true
+		}
+		inv.Binaries = append(inv.Binaries, *b)
+	}
+	sort.Slice(inv.Binaries, func(i, j int) bool { return inv.Binaries[i].Path < inv.Binaries[j].Path })
+	for i, p := range opt.Packages {
+		if opt.AllPackages || used[i] {
+			inv.Packages = append(inv.Packages, p)
+		}
+	}
+	sort.SliceStable(inv.Packages, func(i, j int) bool { return inv.Packages[i].Name < inv.Packages[j].Name })
+	return inv
+}
+
+// ownerOf returns the index of the package that installed the binary at path.
+// On merged-/usr systems, a binary run from /usr/bin may have been installed
+// to /bin, and vice versa, so both locations are checked.
+func ownerOf(owners map[string]int, path string) (int, bool) {
+	if i, ok := owners[path]; ok {
+		return i, true
+	}
+	if rel, err := filepath.Rel("/usr", path); err == nil && filepath.IsLocal(rel) {
+		i, ok := owners["/"+rel]
+		return i, ok
+	}
+	i, ok := owners[filepath.Join("/usr", path)]
+	return i, ok
+}
+
+// modules returns the Go modules the binaries of inv were built with, keyed
+// by their package URL, along with the package URLs of the modules each
+// binary depends on, keyed by the binary's path.
+func (inv Inventory) modules() (map[string]provenance.Module, map[string][]string) {
+	modules := map[string]provenance.Module{}
+	deps := map[string][]string{}
+	for _, b := range inv.Binaries {
+		if b.BuildInfo == nil {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, m := range b.BuildInfo.Deps {
+			purl := goPURL(m.Path, m.Version)
+			modules[purl] = m
+			if !seen[purl] {
+				seen[purl] = true
+				deps[b.Path] = append(deps[b.Path], purl)
+			}
+		}
+		sort.Strings(deps[b.Path])
+	}
+	return modules, deps
+}
+
+// version returns the version of b, which is the version of the package that
+// installed it or, for Go binaries, the version of their main module.
+func (inv Inventory) version(b Binary) string {
+	for _, p := range inv.Packages {
+		if p.Name == b.Package {
+			return p.Version
+		}
+	}
+	if b.BuildInfo != nil && b.BuildInfo.ModuleVersion != develVersion {
+		return b.BuildInfo.ModuleVersion
+	}
+	return ""
+}
+
+// goPURL returns the package URL of a Go module.
+func goPURL(path string, version string) string {
+	return fmt.Sprintf("pkg:golang/%s@%s", path, purlEscape(version))
+}
+
+// packagePURL returns the package URL of an operating-system package, such as
+// pkg:deb/debian/bash@5.2.15-2?arch=amd64.
+func (inv Inventory) packagePURL(p host.Package) string {
+	purlType := "deb"
+	if p.Manager == host.APKManager {
+		purlType = "apk"
+	}
+	namespace := inv.OS.Name
+	if namespace == "" {
+		namespace = map[string]string{"deb": "debian", "apk": "alpine"}[purlType]
+	}
+	purl := fmt.Sprintf("pkg:%s/%s/%s@%s", purlType, namespace, p.Name, purlEscape(p.Version))
+	if p.Arch != "" {
+		purl += "?arch=" + url.QueryEscape(p.Arch)
+	}
+	return purl
+}
+
+// purlEscape percent-encodes s for use in a package URL, which, unlike a URL
+// path, requires + to be encoded.
+func purlEscape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "+", "%2B")
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
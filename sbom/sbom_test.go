@@ -0,0 +1,136 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/provenance"
+)
+
+// sha returns a fake SHA256 made of c.
+func sha(c string) string {
+	return strings.Repeat(c, 64)
+}
+
+// readBuildInfo returns build information for the Go binary /usr/bin/agent
+// and fails for every other binary.
+func readBuildInfo(path string) (*provenance.BuildInfo, error) {
+	if path != "/usr/bin/agent" {
+		return nil, fmt.Errorf("not a go binary")
+	}
+	return &provenance.BuildInfo{
+		ModulePath:    "github.com/example/agent",
+		ModuleVersion: "v1.2.0",
+		Deps:          []provenance.Module{{Path: "github.com/spf13/cobra", Version: "v1.6.1", Sum: "h1:abc="}},
+	}, nil
+}
+
+func testInventory() Inventory {
+	ps := plib.Processes{
+		1:   {ID: 1, CommandName: "bash", CommandPath: "/usr/bin/bash", BinarySHA: sha("a")},
+		2:   {ID: 2, CommandName: "kthreadd"},
+		3:   {ID: 3, CommandName: "bash", CommandPath: "/usr/bin/bash", BinarySHA: sha("a")},
+		10:  {ID: 10, CommandName: "agent", CommandPath: "/usr/bin/agent", BinarySHA: sha("b")},
+		700: {ID: 700, CommandName: "miner", CommandPath: "/tmp/miner", BinarySHA: sha("c")},
+	}
+	return Collect(ps, CollectOpts{
+		HostID: "abc123",
+		OS:     host.OS{Name: "debian", Version: "12 (bookworm)"},
+		Packages: []host.Package{
+			{Manager: host.DpkgManager, Name: "bash", Version: "5.2.15-2", Arch: "amd64", Files: []string{"/bin/bash"}},
+			{Manager: host.DpkgManager, Name: "vim", Version: "9.0", Arch: "amd64", Files: []string{"/usr/bin/vim"}},
+		},
+		ReadBuildInfo: readBuildInfo,
+	})
+}
+
+func TestCollect(t *testing.T) {
+	inv := testInventory()
+	paths := []string{}
+	for _, b := range inv.Binaries {
+		paths = append(paths, b.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"/tmp/miner", "/usr/bin/agent", "/usr/bin/bash"}) {
+		t.Fatalf("fail: binaries were wrong: %v", paths)
+	}
+	// bash is run from /usr/bin, but was installed to /bin (merged /usr).
+	bash := inv.Binaries[2]
+	if bash.Package != "bash" || !reflect.DeepEqual(bash.PIDs, []int{1, 3}) {
+		t.Logf("fail: bash was wrong: %+v", bash)
+		t.Fail()
+	}
+	if inv.Binaries[1].BuildInfo == nil || inv.Binaries[0].BuildInfo != nil {
+		t.Logf("fail: expected build info for only the go binary: %+v", inv.Binaries)
+		t.Fail()
+	}
+	// vim isn't running, so shouldn't be described.
+	if len(inv.Packages) != 1 || inv.Packages[0].Name != "bash" {
+		t.Logf("fail: packages were wrong: %+v", inv.Packages)
+		t.Fail()
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	out, err := SPDX(testInventory())
+	if err != nil {
+		t.Fatalf("fail: unexpected error encoding spdx: %s", err)
+	}
+	var doc spdxDocument
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("fail: unexpected error decoding spdx: %s", err)
+	}
+	if doc.SPDXVersion != spdxVersion || len(doc.Packages) != 5 {
+		t.Logf("fail: expected 5 packages (1 os package, 3 binaries, 1 module), actual: %+v", doc.Packages)
+		t.Fail()
+	}
+	want := []spdxRelationship{
+		{"SPDXRef-Package-dpkg-bash-amd64", "CONTAINS", "SPDXRef-Binary-usr-bin-bash"},
+		{"SPDXRef-Binary-usr-bin-agent", "DEPENDS_ON", "SPDXRef-Module-pkg-golang-github.com-spf13-cobra-v1.6.1"},
+	}
+	for _, w := range want {
+		found := false
+		for _, r := range doc.Relationships {
+			found = found || r == w
+		}
+		if !found {
+			t.Logf("fail: expected relationship %+v, actual: %+v", w, doc.Relationships)
+			t.Fail()
+		}
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	out, err := CycloneDX(testInventory())
+	if err != nil {
+		t.Fatalf("fail: unexpected error encoding cyclonedx: %s", err)
+	}
+	var doc cdxDocument
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("fail: unexpected error decoding cyclonedx: %s", err)
+	}
+	// the bash package (nesting its binary), the 2 binaries without a package,
+	// and the cobra module.
+	if len(doc.Components) != 4 {
+		t.Fatalf("fail: expected 4 components, actual: %+v", doc.Components)
+	}
+	bash := doc.Components[0]
+	if bash.PURL != "pkg:deb/debian/bash@5.2.15-2?arch=amd64" || len(bash.Components) != 1 || bash.Components[0].Version != "5.2.15-2" {
+		t.Logf("fail: bash package was wrong: %+v", bash)
+		t.Fail()
+	}
+	if agent := doc.Components[2]; agent.Version != "v1.2.0" || agent.Hashes[0].Content != sha("b") {
+		t.Logf("fail: agent binary was wrong: %+v", agent)
+		t.Fail()
+	}
+	for _, d := range doc.Dependencies {
+		if d.Ref == "binary:/usr/bin/agent" && !reflect.DeepEqual(d.DependsOn, []string{"pkg:golang/github.com/spf13/cobra@v1.6.1"}) {
+			t.Logf("fail: agent dependencies were wrong: %+v", d)
+			t.Fail()
+		}
+	}
+}
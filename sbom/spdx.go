@@ -0,0 +1,158 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	spdxVersion     = "SPDX-2.3"
+	spdxDocumentID  = "SPDXRef-DOCUMENT"
+	spdxNoAssertion = "NOASSERTION"
+	// The namespace documents are created under, made unique per document.
+	spdxNamespace = "https://github.com/arctir/proctor/spdx"
+)
+
+// matches characters that aren't allowed in an SPDX identifier.
+var spdxIDRegex = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDX encodes inv as an SPDX 2.3 JSON document. Binaries, operating-system
+// packages, and Go modules are each described as an SPDX package. The
+// document describes every binary, which depends on the Go modules it was
+// built with and is contained by the package that installed it.
+func SPDX(inv Inventory) ([]byte, error) {
+	doc := spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              documentName(inv),
+		DocumentNamespace: fmt.Sprintf("%s/%s-%s", spdxNamespace, spdxIDRegex.ReplaceAllString(documentName(inv), "-"), newUUID()),
+		CreationInfo: spdxCreationInfo{
+			Created:  inv.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	for _, p := range inv.Packages {
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:                  p.Name,
+			SPDXID:                spdxID("Package", p.Manager, p.Name, p.Arch),
+			VersionInfo:           p.Version,
+			DownloadLocation:      spdxNoAssertion,
+			ExternalRefs:          []spdxExternalRef{purlRef(inv.packagePURL(p))},
+			PrimaryPackagePurpose: "OPERATING-SYSTEM",
+		})
+	}
+	modules, deps := inv.modules()
+	for _, b := range inv.Binaries {
+		id := spdxID("Binary", b.Path)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:                  b.Path,
+			SPDXID:                id,
+			VersionInfo:           inv.version(b),
+			DownloadLocation:      spdxNoAssertion,
+			Checksums:             []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: b.SHA256}},
+			PrimaryPackagePurpose: "APPLICATION",
+			Comment:               fmt.Sprintf("Running as process(es) %v.", b.PIDs),
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{spdxDocumentID, "DESCRIBES", id})
+		for _, p := range inv.Packages {
+			if p.Name == b.Package {
+				doc.Relationships = append(doc.Relationships, spdxRelationship{spdxID("Package", p.Manager, p.Name, p.Arch), "CONTAINS", id})
+			}
+		}
+		for _, purl := range deps[b.Path] {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{id, "DEPENDS_ON", spdxID("Module", purl)})
+		}
+	}
+	for _, purl := range sortedKeys(modules) {
+		m := modules[purl]
+		pkg := spdxPackage{
+			Name:                  m.Path,
+			SPDXID:                spdxID("Module", purl),
+			VersionInfo:           m.Version,
+			DownloadLocation:      spdxNoAssertion,
+			ExternalRefs:          []spdxExternalRef{purlRef(purl)},
+			PrimaryPackagePurpose: "LIBRARY",
+		}
+		if m.Sum != "" {
+			pkg.Comment = "go.sum checksum: " + m.Sum
+		}
+		doc.Packages = append(doc.Packages, pkg)
+	}
+	return json.Marshal(doc)
+}
+
+// spdxID returns the SPDX identifier of the element identified by parts.
+func spdxID(kind string, parts ...string) string {
+	id := "SPDXRef-" + kind
+	for _, p := range parts {
+		if p != "" {
+			id += "-" + strings.Trim(spdxIDRegex.ReplaceAllString(p, "-"), "-")
+		}
+	}
+	return id
+}
+
+// purlRef returns an SPDX external reference to the package URL purl.
+func purlRef(purl string) spdxExternalRef {
+	return spdxExternalRef{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}
+}
+
+// documentName returns the name of the document describing inv.
+func documentName(inv Inventory) string {
+	if inv.HostID == "" {
+		return toolName + "-runtime"
+	}
+	return toolName + "-runtime-" + inv.HostID
+}
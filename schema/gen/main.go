@@ -0,0 +1,35 @@
+// Command gen writes the schema of every output in [schema.Outputs] to a
+// directory, as <name>.json. It's run with go generate to publish the schemas
+// in docs/schemas.
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/arctir/proctor/schema"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: gen <dir>")
+	}
+	dir := os.Args[1]
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Fatalf("failed creating %s: %s", dir, err)
+	}
+	for _, o := range schema.Outputs {
+		s, err := schema.ForOutput(o.Name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out, err := schema.Marshal(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, o.Name+".json"), out, 0o644); err != nil {
+			log.Fatalf("failed writing schema %s: %s", o.Name, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/arctir/proctor/agent"
+	"github.com/arctir/proctor/baseline"
+	"github.com/arctir/proctor/bundle"
+	"github.com/arctir/proctor/fleet"
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/plib"
+	"github.com/arctir/proctor/policy"
+)
+
+// Output is a JSON output of proctor whose schema is published.
+type Output struct {
+	// The name the schema is published under, such as process.
+	Name        string
+	Description string
+	// A value of the type that is encoded.
+	Value interface{}
+}
+
+// Outputs are the JSON outputs of proctor whose schemas are published in
+// docs/schemas. Commands whose output is one of these print its schema with
+// --schema.
+var Outputs = []Output{
+	{Name: "process", Description: "A process, as output by `proctor process get --id`.", Value: plib.Process{}},
+	{Name: "processes", Description: "Processes keyed by ID, as output by `proctor process ls` and `proctor process get --name`.", Value: plib.Processes{}},
+	{Name: "process-tree", Description: "A process and its children, as output by `proctor process tree`.", Value: plib.ProcessTree{}},
+	{Name: "process-event", Description: "A process starting or exiting, as output by `proctor process ls --watch`, one per line.", Value: plib.ProcessEvent{}},
+	{Name: "process-stat", Description: "The fields of a process's stat file, as output by `proctor process stat`.", Value: []plib.StatField{}},
+	{Name: "processes-diff", Description: "How processes differ from a snapshot, as output by `proctor process diff`.", Value: plib.ProcessesDiff{}},
+	{Name: "snapshot", Description: "The processes of a host, as served by `proctor agent` on /api/processes.", Value: agent.Snapshot{}},
+	{Name: "fingerprint", Description: "A process's fingerprint and the lineage it was created from.", Value: plib.Fingerprint{}},
+	{Name: "fingerprint-report", Description: "The fingerprint of every process, as output by `proctor process fp --all`.", Value: plib.FingerprintReport{}},
+	{Name: "baseline", Description: "A fingerprint saved by `proctor process fp save`.", Value: plib.Baseline{}},
+	{Name: "role-baseline", Description: "The binaries approved for and expected on hosts of a role, as recorded by `proctor baseline record`.", Value: baseline.Baseline{}},
+	{Name: "drift-report", Description: "How a host's processes drift from a role baseline, as output by `proctor baseline check` and served by the agent on /api/drift.", Value: baseline.Report{}},
+	{Name: "policy-report", Description: "The processes violating rules, as output by `proctor policy eval`.", Value: policy.Report{}},
+	{Name: "host", Description: "The details of a host, as output by `proctor host info`.", Value: host.HostInfo{}},
+	{Name: "host-hardware", Description: "The hardware of a host, as output by `proctor host hardware`.", Value: host.Hardware{}},
+	{Name: "bundle-manifest", Description: "The contents of a bundle created by `proctor bundle`, as written to its manifest.json and output by `proctor bundle verify`.", Value: bundle.Manifest{}},
+	{Name: "fleet-report", Description: "The fleet-wide views of the snapshots of many hosts, as output by `proctor fleet report`.", Value: fleet.Report{}},
+	{Name: "host-containers", Description: "The containers running on a host, as output by `proctor host containers`.", Value: []host.Container{}},
+}
+
+// outputOpts resolves the types of the fields of interface type found in
+// [Outputs]. Processes are described as they are on Linux, the only
+// operating system proctor inspects today.
+var outputOpts = Opts{
+	Interfaces: map[string]interface{}{
+		"Process.OSSpecific": plib.ProcessStat{},
+	},
+}
+
+// ForOutput returns the schema of the output with name, as listed in
+// [Outputs].
+func ForOutput(name string) (*Schema, error) {
+	for _, o := range Outputs {
+		if o.Name == name {
+			return ForValue(o.Value, o.Description), nil
+		}
+	}
+	return nil, fmt.Errorf("failed to find schema %q; available schemas: %s", name, OutputNames())
+}
+
+// ForValue returns the schema of v, which is an output of proctor, described
+// by description. It's used for outputs not listed in [Outputs], such as
+// those specific to a command.
+func ForValue(v interface{}, description string) *Schema {
+	s := Generate(v, outputOpts)
+	s.Description = description
+	return s
+}
+
+// OutputNames returns the name of every output in [Outputs], sorted.
+func OutputNames() []string {
+	names := make([]string, 0, len(Outputs))
+	for _, o := range Outputs {
+		names = append(names, o.Name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,265 @@
+// Package schema generates [JSON Schema] definitions of the JSON proctor
+// outputs, from the Go types that are encoded. Integrations consuming
+// proctor's output can use the schemas to validate it.
+//
+// [JSON Schema]: https://json-schema.org
+package schema
+
+//go:generate go run ./gen ../docs/schemas
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The version of JSON Schema generated schemas conform to.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. Only the keywords needed to describe Go types are
+// supported.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Opts configures how schemas are generated. Every field is optional.
+type Opts struct {
+	// The concrete type held by fields of interface type, keyed by the field
+	// as <type>.<field> (e.g. Process.OSSpecific). Fields of interface type
+	// that aren't listed accept any value.
+	Interfaces map[string]interface{}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	// types encoding themselves as JSON, whose form can't be determined from
+	// their fields.
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	// types encoding themselves as JSON strings.
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate returns the schema of v, as encoded by encoding/json. Named struct
+// types are described once in $defs and referenced wherever they're used,
+// which allows recursive types such as trees.
+func Generate(v interface{}, opts ...Opts) *Schema {
+	conf := Opts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	g := &generator{conf: conf, defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+	t := reflect.TypeOf(v)
+	s := g.schema(t)
+	s.Schema = Draft
+	s.Title = typeName(t)
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+// generator holds the definitions of the named types found while generating
+// a schema.
+type generator struct {
+	conf Opts
+	defs map[string]*Schema
+	// the name of each type in defs, which is qualified by its package when
+	// types in different packages share a name.
+	names map[reflect.Type]string
+}
+
+// schema returns the schema of values of type t.
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Description: "A duration in nanoseconds."}
+	case t.Implements(marshalerType):
+		return &Schema{}
+	case t.Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: []string{"string", "null"}, ContentEncoding: "base64"}
+		}
+		return &Schema{Type: []string{"array", "null"}, Items: g.schema(t.Elem())}
+	case reflect.Array:
+		n := t.Len()
+		return &Schema{Type: "array", Items: g.schema(t.Elem()), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		s := &Schema{Type: []string{"object", "null"}, AdditionalProperties: g.schema(t.Elem())}
+		switch t.Key().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s.PropertyNames = &Schema{Pattern: "^-?[0-9]+$"}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			s.PropertyNames = &Schema{Pattern: "^[0-9]+$"}
+		}
+		return s
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := g.define(t)
+		return &Schema{Ref: "#/$defs/" + name}
+	}
+	// interfaces, and kinds encoding/json can't encode, accept any value.
+	return &Schema{}
+}
+
+// define adds the schema of the named struct type t to the definitions, unless
+// it's already defined, and returns its name within them.
+func (g *generator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.defs[name]; taken {
+		name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + name
+	}
+	g.names[t] = name
+	// reserve the name before generating, so recursive references resolve.
+	g.defs[name] = &Schema{}
+	*g.defs[name] = *g.structSchema(t)
+	return name
+}
+
+// structSchema returns the schema of the struct type t, whose exported fields
+// are encoded as properties named by their json tag, or their name.
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds the properties of the fields of struct type t to s. The
+// fields of embedded structs are promoted, as encoding/json does.
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitEmpty, skip := parseTag(f)
+		if skip {
+			continue
+		}
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !ft.Implements(marshalerType) {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		var fs *Schema
+		if concrete, ok := g.conf.Interfaces[t.Name()+"."+f.Name]; ok && ft.Kind() == reflect.Interface {
+			fs = nullable(g.schema(reflect.TypeOf(concrete)))
+		} else {
+			fs = g.schema(ft)
+		}
+		s.Properties[name] = fs
+		if !omitEmpty {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// parseTag returns the name and omitempty option of f's json tag, and whether
+// the field is skipped when encoding.
+func parseTag(f reflect.StructField) (string, bool, bool) {
+	tag, ok := f.Tag.Lookup("json")
+	if !ok {
+		return "", false, false
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	omitEmpty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, false
+}
+
+// nullable returns a schema accepting null as well as the values s accepts.
+func nullable(s *Schema) *Schema {
+	if s.Type == nil && s.Ref == "" && s.AnyOf == nil {
+		// s already accepts any value.
+		return s
+	}
+	if types, ok := s.Type.([]string); ok {
+		for _, t := range types {
+			if t == "null" {
+				return s
+			}
+		}
+	}
+	return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+}
+
+// typeName returns the name of t, such as Process or []Process.
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	}
+	if t.Name() == "" {
+		return t.String()
+	}
+	return t.Name()
+}
+
+// Marshal returns s as indented JSON, ending in a newline.
+func Marshal(s *Schema) ([]byte, error) {
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding schema: %s", err)
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testEmbedded struct {
+	Created time.Time
+}
+
+type testNode struct {
+	testEmbedded
+	Name     string `json:"name"`
+	Comment  string `json:",omitempty"`
+	Ignored  string `json:"-"`
+	hidden   string
+	Parent   *testNode
+	Children []testNode
+	Details  interface{}
+	Data     []byte
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(testNode{}, Opts{Interfaces: map[string]interface{}{"testNode.Details": 0}})
+	if s.Ref != "#/$defs/testNode" || s.Schema != Draft {
+		t.Fatalf("fail: expected the root to reference testNode in %s, actual: %+v", Draft, s)
+	}
+	node := s.Defs["testNode"]
+	if node == nil {
+		t.Fatalf("fail: expected testNode to be defined, actual: %v", s.Defs)
+	}
+
+	names := []string{}
+	for name := range node.Properties {
+		names = append(names, name)
+	}
+	expectedNames := []string{"Created", "name", "Comment", "Parent", "Children", "Details", "Data"}
+	if len(names) != len(expectedNames) {
+		t.Logf("fail: expected properties %v, actual: %v", expectedNames, names)
+		t.Fail()
+	}
+	expectedRequired := []string{"Created", "name", "Parent", "Children", "Details", "Data"}
+	if !reflect.DeepEqual(node.Required, expectedRequired) {
+		t.Logf("fail: expected required %v, actual: %v", expectedRequired, node.Required)
+		t.Fail()
+	}
+
+	tests := []struct {
+		property string
+		expected *Schema
+	}{
+		{"Created", &Schema{Type: "string", Format: "date-time"}},
+		{"Parent", &Schema{AnyOf: []*Schema{{Ref: "#/$defs/testNode"}, {Type: "null"}}}},
+		{"Children", &Schema{Type: []string{"array", "null"}, Items: &Schema{Ref: "#/$defs/testNode"}}},
+		{"Details", &Schema{AnyOf: []*Schema{{Type: "integer"}, {Type: "null"}}}},
+		{"Data", &Schema{Type: []string{"string", "null"}, ContentEncoding: "base64"}},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(node.Properties[test.property], test.expected) {
+			t.Logf("fail: property %s expected %+v, actual: %+v", test.property, test.expected, node.Properties[test.property])
+			t.Fail()
+		}
+	}
+}
+
+// TestPublishedSchemas ensures the schemas in docs/schemas match the types
+// they're generated from. When it fails, run go generate ./schema.
+func TestPublishedSchemas(t *testing.T) {
+	for _, o := range Outputs {
+		s, err := ForOutput(o.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := os.ReadFile(filepath.Join("..", "docs", "schemas", o.Name+".json"))
+		if err != nil {
+			t.Logf("fail: schema %s isn't published: %s", o.Name, err)
+			t.Fail()
+			continue
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Logf("fail: published schema %s is out of date; run go generate ./schema", o.Name)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ActivityInterval is the size of each bucket in an activity time series.
+type ActivityInterval int
+
+const (
+	WeeklyActivity ActivityInterval = iota
+	MonthlyActivity
+)
+
+// ActivityBucket holds the commit activity within a single interval.
+type ActivityBucket struct {
+	// The start of the interval (inclusive), in UTC.
+	Start time.Time
+	// The end of the interval (exclusive), in UTC.
+	End           time.Time
+	Commits       int
+	UniqueAuthors int
+}
+
+// ParseActivityInterval returns the [ActivityInterval] represented by name,
+// which can be week(ly) or month(ly).
+func ParseActivityInterval(name string) (ActivityInterval, error) {
+	switch strings.ToLower(name) {
+	case "week", "weekly":
+		return WeeklyActivity, nil
+	case "month", "monthly":
+		return MonthlyActivity, nil
+	}
+	return 0, fmt.Errorf("activity interval (%s) is invalid, valid intervals are week or month", name)
+}
+
+// GetActivity buckets commits into a time series where each bucket covers a
+// single interval. Weeks start on Monday and months on their first day, both
+// in UTC. The series covers every interval from the oldest to the newest
+// commit, including intervals without commits, so it can be charted directly.
+// The returned buckets are ordered oldest first.
+func GetActivity(commits []Commit, interval ActivityInterval) []ActivityBucket {
+	if len(commits) == 0 {
+		return []ActivityBucket{}
+	}
+	oldest, newest := commits[0].Date, commits[0].Date
+	for _, c := range commits {
+		if c.Date.Before(oldest) {
+			oldest = c.Date
+		}
+		if c.Date.After(newest) {
+			newest = c.Date
+		}
+	}
+
+	buckets := []ActivityBucket{}
+	index := map[time.Time]int{}
+	for start := intervalStart(oldest, interval); !start.After(newest); start = nextInterval(start, interval) {
+		index[start] = len(buckets)
+		buckets = append(buckets, ActivityBucket{Start: start, End: nextInterval(start, interval)})
+	}
+
+	authors := make([]map[string]bool, len(buckets))
+	for _, c := range commits {
+		i := index[intervalStart(c.Date, interval)]
+		buckets[i].Commits++
+		if authors[i] == nil {
+			authors[i] = map[string]bool{}
+		}
+		authors[i][strings.ToLower(c.Author.Email)] = true
+	}
+	for i := range buckets {
+		buckets[i].UniqueAuthors = len(authors[i])
+	}
+	return buckets
+}
+
+// intervalStart returns the start of the interval t falls within.
+func intervalStart(t time.Time, interval ActivityInterval) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if interval == MonthlyActivity {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	// time.Weekday starts on Sunday (0); shift so Monday starts the week.
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// nextInterval returns the start of the interval following start.
+func nextInterval(start time.Time, interval ActivityInterval) time.Time {
+	if interval == MonthlyActivity {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 7)
+}
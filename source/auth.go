@@ -0,0 +1,87 @@
+package source
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+const (
+	// The username sent alongside a token when authenticating over HTTPS.
+	// GitHub and GitLab accept any non-empty username with a token.
+	defaultTokenUsername = "x-access-token"
+	// The user to authenticate as over SSH when the URL doesn't specify one.
+	defaultSSHUser = "git"
+)
+
+// RepoAuth provides the credentials used to clone and fetch private
+// repositories. Which credentials are used depends on the URL of the
+// repository. For HTTP(S) URLs, Username and Password take precedence over
+// Token. For SSH URLs (e.g. git@github.com:arctir/proctor.git), SSHKeyPath
+// takes precedence over SSHAgent. When no credentials apply to the URL, the
+// repository is accessed anonymously.
+type RepoAuth struct {
+	// A personal access token, sent as the password of HTTP basic auth.
+	Token string
+	// The username and password used for HTTP basic auth.
+	Username string
+	Password string
+	// The path to a PEM encoded private key used for SSH auth.
+	SSHKeyPath string
+	// The passphrase of the key at SSHKeyPath, if it is encrypted.
+	SSHKeyPassphrase string
+	// Authenticate over SSH using the keys held by the running SSH agent
+	// (found using SSH_AUTH_SOCK).
+	SSHAgent bool
+}
+
+// IsZero returns whether no credentials are set.
+func (a RepoAuth) IsZero() bool {
+	return a == RepoAuth{}
+}
+
+// newAuthMethod returns the go-git auth method for accessing url with the
+// credentials in auth. A nil method is returned when no credentials apply to
+// url. An error is returned when the url cannot be parsed or an SSH key
+// cannot be loaded.
+func newAuthMethod(url string, auth RepoAuth) (transport.AuthMethod, error) {
+	if auth.IsZero() {
+		return nil, nil
+	}
+	ep, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing repository url (%s): %s", url, err)
+	}
+
+	switch ep.Protocol {
+	case "http", "https":
+		if auth.Username != "" || auth.Password != "" {
+			return &http.BasicAuth{Username: auth.Username, Password: auth.Password}, nil
+		}
+		if auth.Token != "" {
+			return &http.BasicAuth{Username: defaultTokenUsername, Password: auth.Token}, nil
+		}
+	case "ssh":
+		user := ep.User
+		if user == "" {
+			user = defaultSSHUser
+		}
+		if auth.SSHKeyPath != "" {
+			keys, err := ssh.NewPublicKeysFromFile(user, auth.SSHKeyPath, auth.SSHKeyPassphrase)
+			if err != nil {
+				return nil, fmt.Errorf("failed loading ssh key (%s): %s", auth.SSHKeyPath, err)
+			}
+			return keys, nil
+		}
+		if auth.SSHAgent {
+			agent, err := ssh.NewSSHAgentAuth(user)
+			if err != nil {
+				return nil, fmt.Errorf("failed connecting to ssh agent: %s", err)
+			}
+			return agent, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,76 @@
+package source
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+func TestNewAuthMethod(t *testing.T) {
+	auth, err := newAuthMethod("https://github.com/arctir/proctor", RepoAuth{Token: "abc123"})
+	if err != nil {
+		t.Fatalf("fail: unexpected error creating token auth: %s", err)
+	}
+	basic, ok := auth.(*http.BasicAuth)
+	if !ok || basic.Password != "abc123" || basic.Username == "" {
+		t.Logf("fail: token auth was wrong: %#v", auth)
+		t.Fail()
+	}
+
+	auth, err = newAuthMethod("https://gitlab.com/arctir/proctor", RepoAuth{Token: "abc123", Username: "jane", Password: "secret"})
+	if err != nil {
+		t.Fatalf("fail: unexpected error creating basic auth: %s", err)
+	}
+	basic, ok = auth.(*http.BasicAuth)
+	if !ok || basic.Username != "jane" || basic.Password != "secret" {
+		t.Logf("fail: basic auth should take precedence over token: %#v", auth)
+		t.Fail()
+	}
+
+	// a token does not apply to ssh urls
+	auth, err = newAuthMethod("git@github.com:arctir/proctor.git", RepoAuth{Token: "abc123"})
+	if err != nil || auth != nil {
+		t.Logf("fail: expected no auth method for ssh url with a token, got: %#v, err: %v", auth, err)
+		t.Fail()
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "id_ecdsa")
+	if err := writeTestSSHKey(keyPath); err != nil {
+		t.Fatalf("fail: error writing test ssh key: %s", err)
+	}
+	auth, err = newAuthMethod("git@github.com:arctir/proctor.git", RepoAuth{SSHKeyPath: keyPath})
+	if err != nil {
+		t.Fatalf("fail: unexpected error creating ssh key auth: %s", err)
+	}
+	keys, ok := auth.(*ssh.PublicKeys)
+	if !ok || keys.User != "git" {
+		t.Logf("fail: ssh key auth was wrong: %#v", auth)
+		t.Fail()
+	}
+
+	_, err = newAuthMethod("ssh://git@github.com/arctir/proctor.git", RepoAuth{SSHKeyPath: keyPath + "-missing"})
+	if err == nil {
+		t.Log("fail: expected error loading a missing ssh key")
+		t.Fail()
+	}
+}
+
+func writeTestSSHKey(path string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600)
+}
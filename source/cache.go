@@ -0,0 +1,171 @@
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// The host directory used for repositories that are resolved from a local
+// path rather than a remote.
+const localCacheHost = "local"
+
+var (
+	// matches a scp-like ssh URL, such as git@github.com:org/repo.git.
+	scpURLRegex = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+	// matches the characters not allowed in a cache directory name.
+	unsafeCacheCharsRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// getCacheName returns the directory, relative to the cache location, that
+// the repository at url is persisted to. It is laid out as host/org/repo, so
+// the cache can be browsed, with a short hash of the url appended to the
+// repository's name to keep URLs that sanitize to the same path (e.g. http
+// and https) apart. For example, https://github.com/spf13/cobra.git is cached
+// in github.com/spf13/cobra-<hash>.
+func getCacheName(repoURL string) string {
+	host, p := splitRepoURL(repoURL)
+	segments := []string{sanitizeCacheSegment(host)}
+	for _, s := range strings.Split(p, "/") {
+		if s == "" || s == "." || s == ".." {
+			continue
+		}
+		segments = append(segments, sanitizeCacheSegment(s))
+	}
+	sum := sha256.Sum256([]byte(repoURL))
+	suffix := hex.EncodeToString(sum[:])[:12]
+	if len(segments) == 1 {
+		segments = append(segments, suffix)
+	} else {
+		last := len(segments) - 1
+		segments[last] = strings.TrimSuffix(segments[last], ".git") + "-" + suffix
+	}
+	return filepath.Join(segments...)
+}
+
+// splitRepoURL returns the host and path of a repository's URL. URLs without
+// a host, such as local paths, are given the host [localCacheHost].
+func splitRepoURL(repoURL string) (string, string) {
+	if u, err := url.Parse(repoURL); err == nil && u.Scheme != "" && u.Host != "" {
+		return u.Hostname(), u.Path
+	}
+	// single letter hosts are windows drive letters (e.g. C:\repo).
+	if m := scpURLRegex.FindStringSubmatch(repoURL); m != nil && len(m[1]) > 1 {
+		return m[1], m[2]
+	}
+	return localCacheHost, filepath.ToSlash(repoURL)
+}
+
+// sanitizeCacheSegment replaces the characters of s that are unsafe in a
+// directory name.
+func sanitizeCacheSegment(s string) string {
+	s = unsafeCacheCharsRegex.ReplaceAllString(s, "_")
+	if s == "" {
+		return "_"
+	}
+	return s
+}
+
+// getLegacyCacheName returns the base64 encoded representation of a repo's
+// URL, which was previously used as its directory within the cache.
+func getLegacyCacheName(repoURL string) string {
+	return base64.StdEncoding.EncodeToString([]byte(repoURL))
+}
+
+// resolveCachePath returns where the repository at url is cached within
+// cacheDir. When the repository is only found at its legacy (base64 encoded)
+// location, it is moved to its current location. If moving it fails, the
+// legacy location is returned so the existing clone is still used.
+func resolveCachePath(cacheDir string, repoURL string) string {
+	fp := filepath.Join(cacheDir, getCacheName(repoURL))
+	if _, err := os.Stat(fp); err == nil {
+		return fp
+	}
+	legacy := filepath.Join(cacheDir, getLegacyCacheName(repoURL))
+	if _, err := os.Stat(legacy); err != nil {
+		return fp
+	}
+	if err := os.MkdirAll(filepath.Dir(fp), 0777); err != nil {
+		return legacy
+	}
+	if err := os.Rename(legacy, fp); err != nil {
+		return legacy
+	}
+	return fp
+}
+
+// GetCachedRepoURLs returns the URLs of the repositories cached to the
+// filesystem, sorted. Nothing is fetched, so it's quick enough for uses such as
+// shell completion.
+func GetCachedRepoURLs() ([]string, error) {
+	return getCachedRepoURLs(getDefaultCacheLocation())
+}
+
+// getCachedRepoURLs returns the URLs of the repositories cached in cacheDir.
+// Each repository's URL is read from the remote it was cloned from.
+func getCachedRepoURLs(cacheDir string) ([]string, error) {
+	urls := []string{}
+	err := filepath.WalkDir(cacheDir, func(fp string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if fp == cacheDir && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() || fp == cacheDir {
+			return nil
+		}
+		// repositories are cloned bare, so a directory with a HEAD is a
+		// repository.
+		if _, err := os.Stat(filepath.Join(fp, "HEAD")); err != nil {
+			return nil
+		}
+		if r, err := git.PlainOpen(fp); err == nil {
+			if remote, err := r.Remote(git.DefaultRemoteName); err == nil && len(remote.Config().URLs) > 0 {
+				urls = append(urls, remote.Config().URLs[0])
+			}
+		}
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed reading cached repositories: %s", err)
+	}
+	sort.Strings(urls)
+	return urls, nil
+}
+
+// GetCachedTagNames returns the names of the tags of the repository at url as
+// of when it was last fetched into the cache, sorted. Nothing is fetched, so
+// an error is returned if the repository isn't cached.
+func GetCachedTagNames(url string) ([]string, error) {
+	return getCachedTagNames(getDefaultCacheLocation(), url)
+}
+
+func getCachedTagNames(cacheDir string, url string) ([]string, error) {
+	r, err := git.PlainOpen(resolveCachePath(cacheDir, url))
+	if err != nil {
+		return nil, fmt.Errorf("failed opening cached repo %s: %s", url, err)
+	}
+	tags, err := r.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading tags of cached repo %s: %s", url, err)
+	}
+	names := []string{}
+	tags.ForEach(func(ref *plumbing.Reference) error {
+		names = append(names, ref.Name().Short())
+		return nil
+	})
+	sort.Strings(names)
+	return names, nil
+}
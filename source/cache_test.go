@@ -0,0 +1,91 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestGetCacheName(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/spf13/cobra.git":   filepath.Join("github.com", "spf13", "cobra-"),
+		"git@gitlab.com:group/sub/project.git": filepath.Join("gitlab.com", "group", "sub", "project-"),
+		"/tmp/my repo":                         filepath.Join("local", "tmp", "my_repo-"),
+	}
+	for url, prefix := range tests {
+		name := getCacheName(url)
+		if !strings.HasPrefix(name, prefix) {
+			t.Logf("fail: cache name of %s was wrong, expected prefix: %s, actual: %s", url, prefix, name)
+			t.Fail()
+		}
+	}
+	if getCacheName("http://github.com/spf13/cobra") == getCacheName("https://github.com/spf13/cobra") {
+		t.Log("fail: expected different cache names for different urls")
+		t.Fail()
+	}
+}
+
+func TestResolveCachePath(t *testing.T) {
+	cacheDir := t.TempDir()
+	url := "https://github.com/spf13/cobra"
+	legacy := filepath.Join(cacheDir, getLegacyCacheName(url))
+	if err := os.MkdirAll(legacy, 0777); err != nil {
+		t.Fatalf("fail: error creating legacy cache dir: %s", err)
+	}
+
+	fp := resolveCachePath(cacheDir, url)
+	if fp != filepath.Join(cacheDir, getCacheName(url)) {
+		t.Logf("fail: cache path was wrong: %s", fp)
+		t.Fail()
+	}
+	if _, err := os.Stat(fp); err != nil {
+		t.Logf("fail: legacy cache dir was not migrated: %s", err)
+		t.Fail()
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Log("fail: legacy cache dir still exists after migration")
+		t.Fail()
+	}
+}
+
+func TestGetCachedRepos(t *testing.T) {
+	_, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	url := filepath.Join(getTestRepoDir(), "repo2")
+	cacheDir := t.TempDir()
+	if _, err := git.PlainClone(filepath.Join(cacheDir, getCacheName(url)), true, &git.CloneOptions{URL: url}); err != nil {
+		t.Fatalf("fail: error caching test repo: %s", err)
+	}
+
+	urls, err := getCachedRepoURLs(cacheDir)
+	if err != nil {
+		t.Fatalf("fail: error listing cached repos: %s", err)
+	}
+	if len(urls) != 1 || urls[0] != url {
+		t.Logf("fail: expected the cached repo %s, actual: %v", url, urls)
+		t.Fail()
+	}
+	if urls, err := getCachedRepoURLs(filepath.Join(cacheDir, "missing")); err != nil || len(urls) != 0 {
+		t.Logf("fail: expected no repos without a cache, actual: %v, %v", urls, err)
+		t.Fail()
+	}
+
+	tags, err := getCachedTagNames(cacheDir, url)
+	if err != nil {
+		t.Fatalf("fail: error listing cached tags: %s", err)
+	}
+	if strings.Join(tags, ",") != "v0.1.0,v0.2.0" {
+		t.Logf("fail: expected tags v0.1.0 and v0.2.0, actual: %v", tags)
+		t.Fail()
+	}
+	if _, err := getCachedTagNames(cacheDir, "https://github.com/not/cached"); err == nil {
+		t.Log("fail: expected an error listing the tags of a repo that isn't cached")
+		t.Fail()
+	}
+}
@@ -0,0 +1,255 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// The locations a CODEOWNERS file is searched for, in order of precedence.
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners is a parsed CODEOWNERS file, which assigns owners (users, teams,
+// or email addresses) to the files of a repository.
+type CodeOwners struct {
+	// The path of the CODEOWNERS file, relative to the root of the
+	// repository.
+	Path  string
+	Rules []CodeOwnersRule
+}
+
+// CodeOwnersRule assigns Owners to the files matching Pattern. A rule without
+// owners leaves the matching files unowned.
+type CodeOwnersRule struct {
+	Pattern string
+	Owners  []string
+	// The line of the CODEOWNERS file the rule is on.
+	Line  int
+	regex *regexp.Regexp
+}
+
+// OwnerCoverage describes the commits and files an owner is responsible for
+// within a set of commits. An empty Owner represents files that have no
+// owner.
+type OwnerCoverage struct {
+	Owner string
+	// The number of commits changing at least one file the owner owns.
+	Commits int
+	// The number of unique files changed that the owner owns.
+	Files int
+}
+
+// GetCodeOwners returns the CODEOWNERS file of the repository as of ref,
+// which can be a tag, branch, or commit hash; when empty, the repository's
+// PinnedCommit or, if unset, HEAD is used. The file is searched for in
+// .github/, the root of the repository, and docs/, in that order. An error is
+// returned when no CODEOWNERS file exists.
+func (gm *GitManager) GetCodeOwners(r Repository, ref string) (*CodeOwners, error) {
+	if r.RepoRef == nil {
+		return nil, fmt.Errorf("failed to find reference to valid repo when looking up code owners.")
+	}
+	tree, err := treeAtRef(r, ref)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range codeOwnersPaths {
+		f, err := tree.File(p)
+		if err == object.ErrFileNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed reading %s: %s", p, err)
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed reading %s: %s", p, err)
+		}
+		return ParseCodeOwners(p, []byte(content))
+	}
+	return nil, fmt.Errorf("no CODEOWNERS file found in %s", strings.Join(codeOwnersPaths, ", "))
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file found at path. Each
+// non-empty line that isn't a comment is a pattern, using the same syntax as
+// .gitignore, followed by zero or more owners. An error is returned when a
+// pattern cannot be parsed.
+func ParseCodeOwners(path string, content []byte) (*CodeOwners, error) {
+	co := &CodeOwners{Path: path, Rules: []CodeOwnersRule{}}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		regex, err := codeOwnersPatternRegex(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing pattern (%s) on line %d of %s: %s", fields[0], line, path, err)
+		}
+		co.Rules = append(co.Rules, CodeOwnersRule{
+			Pattern: fields[0],
+			Owners:  fields[1:],
+			Line:    line,
+			regex:   regex,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading %s: %s", path, err)
+	}
+	return co, nil
+}
+
+// Owners returns the owners of the file at filePath, relative to the root of
+// the repository. As with GitHub, the last rule matching the file takes
+// precedence. When no rule matches, or the matching rule has no owners, an
+// empty slice is returned.
+func (co *CodeOwners) Owners(filePath string) []string {
+	filePath = strings.TrimPrefix(filePath, "/")
+	for i := len(co.Rules) - 1; i >= 0; i-- {
+		if co.Rules[i].regex.MatchString(filePath) {
+			return co.Rules[i].Owners
+		}
+	}
+	return []string{}
+}
+
+// GetOwnerCoverage returns, for every owner of the files changed by commits,
+// the number of commits and files they are responsible for, ordered by
+// commit count. Changed files without an owner are reported under an empty
+// Owner.
+func (gm *GitManager) GetOwnerCoverage(r Repository, co *CodeOwners, commits []Commit) ([]OwnerCoverage, error) {
+	commitCounts := map[string]int{}
+	files := map[string]map[string]bool{}
+	for _, c := range commits {
+		changed, err := gm.GetChangedFiles(r, c.Hash)
+		if err != nil {
+			return nil, err
+		}
+		owners := map[string]bool{}
+		for _, f := range changed {
+			fileOwners := co.Owners(f)
+			if len(fileOwners) == 0 {
+				fileOwners = []string{""}
+			}
+			for _, o := range fileOwners {
+				owners[o] = true
+				if files[o] == nil {
+					files[o] = map[string]bool{}
+				}
+				files[o][f] = true
+			}
+		}
+		for o := range owners {
+			commitCounts[o]++
+		}
+	}
+
+	coverage := []OwnerCoverage{}
+	for o, count := range commitCounts {
+		coverage = append(coverage, OwnerCoverage{Owner: o, Commits: count, Files: len(files[o])})
+	}
+	sort.Slice(coverage, func(i, j int) bool {
+		if coverage[i].Commits != coverage[j].Commits {
+			return coverage[i].Commits > coverage[j].Commits
+		}
+		return coverage[i].Owner < coverage[j].Owner
+	})
+	return coverage, nil
+}
+
+// GetChangedFiles returns the paths of the files added, modified, or deleted
+// by the commit with hash, compared to its first parent. Every file of a
+// commit without parents is considered added.
+func (gm *GitManager) GetChangedFiles(r Repository, hash Hash) ([]string, error) {
+	if r.RepoRef == nil {
+		return nil, fmt.Errorf("failed to find reference to valid repo when looking up changed files.")
+	}
+	c, err := r.RepoRef.CommitObject(plumbing.Hash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving commit (%s): %s", hash, err)
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving tree of commit (%s): %s", hash, err)
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving parent of commit (%s): %s", hash, err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving tree of commit (%s): %s", parent.Hash, err)
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed comparing commit (%s) to its parent: %s", hash, err)
+	}
+	paths := []string{}
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		paths = append(paths, name)
+	}
+	return paths, nil
+}
+
+// codeOwnersPatternRegex converts a CODEOWNERS pattern into a regular
+// expression matching the paths it applies to. Patterns starting with or
+// containing a / are relative to the root of the repository; otherwise they
+// match at any depth. A pattern matching a directory applies to every file
+// within it, except that a trailing /* only matches the directory's direct
+// children.
+func codeOwnersPatternRegex(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case p[i] == '*':
+			expr.WriteString("[^/]*")
+		case p[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		expr.WriteString("/.*$")
+	case strings.HasSuffix(p, "/*") && !strings.HasSuffix(p, "**"):
+		expr.WriteString("$")
+	default:
+		expr.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(expr.String())
+}
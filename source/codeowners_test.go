@@ -0,0 +1,81 @@
+package source
+
+import (
+	"testing"
+)
+
+func TestCodeOwners(t *testing.T) {
+	co, err := ParseCodeOwners("CODEOWNERS", []byte(`# default owners
+*           @org/maintainers
+*.go        @org/go-team   # go code
+/docs/      @org/docs jane@example.com
+apps/**/cfg @org/apps
+docs/*.md
+`))
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing CODEOWNERS: %s", err)
+	}
+	if len(co.Rules) != 5 || co.Rules[1].Line != 3 {
+		t.Fatalf("fail: rules were wrong: %+v", co.Rules)
+	}
+
+	tests := map[string][]string{
+		"README.md":            {"@org/maintainers"},
+		"cmd/main.go":          {"@org/go-team"},
+		"docs/guide/intro.txt": {"@org/docs", "jane@example.com"},
+		"apps/web/cfg/app.yml": {"@org/apps"},
+		"apps/cfg/app.yml":     {"@org/apps"},
+		"docs/README.md":       {},
+	}
+	for path, expected := range tests {
+		owners := co.Owners(path)
+		if len(owners) != len(expected) {
+			t.Logf("fail: owners of %s were wrong, expected: %v, actual: %v", path, expected, owners)
+			t.Fail()
+			continue
+		}
+		for i := range owners {
+			if owners[i] != expected[i] {
+				t.Logf("fail: owners of %s were wrong, expected: %v, actual: %v", path, expected, owners)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestGetOwnerCoverage(t *testing.T) {
+	gm := NewGitManager()
+	r, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	if _, err := gm.GetCodeOwners(*r, ""); err == nil {
+		t.Log("fail: expected error retrieving CODEOWNERS from a repo without one")
+		t.Fail()
+	}
+
+	commits, err := gm.GetCommits(*r)
+	if err != nil {
+		t.Fatalf("fail: error retrieving commits: %s", err)
+	}
+	co, err := ParseCodeOwners("CODEOWNERS", []byte("*.go @org/go-team\n"))
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing CODEOWNERS: %s", err)
+	}
+	coverage, err := gm.GetOwnerCoverage(*r, co, commits)
+	if err != nil {
+		t.Fatalf("fail: error retrieving owner coverage: %s", err)
+	}
+	if len(coverage) != 2 {
+		t.Fatalf("fail: owner coverage was wrong: %+v", coverage)
+	}
+	if coverage[0].Owner != "@org/go-team" || coverage[0].Commits != 3 || coverage[0].Files != 1 {
+		t.Logf("fail: go-team coverage was wrong: %+v", coverage[0])
+		t.Fail()
+	}
+	if coverage[1].Owner != "" || coverage[1].Commits != 1 {
+		t.Logf("fail: unowned coverage was wrong: %+v", coverage[1])
+		t.Fail()
+	}
+}
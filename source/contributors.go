@@ -0,0 +1,185 @@
+package source
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// The number of top authors used to calculate ContributorStats.TopAuthorsShare
+	// when not specified.
+	DefaultTopAuthors = 3
+	// The share of commits the fewest number of authors must account for to
+	// determine the bus factor.
+	busFactorThreshold = 0.5
+)
+
+// Author is a commit author and details about their contributions.
+type Author struct {
+	Person
+	CommitCount int
+	// The dates of the author's oldest and newest commits.
+	FirstCommit time.Time
+	LastCommit  time.Time
+}
+
+// ContributorStatsOpts constrains the commits considered when creating
+// [ContributorStats]. Every field is optional.
+type ContributorStatsOpts struct {
+	// Only consider commits made at or after this time. Authors whose first
+	// commit falls within the window are considered new contributors.
+	Since time.Time
+	// Only consider commits made at or before this time.
+	Until time.Time
+	// The number of top authors used to calculate TopAuthorsShare. Defaults
+	// to [DefaultTopAuthors].
+	TopN int
+}
+
+// ContributorStats summarizes the authors contributing to a repository over a
+// window of time.
+type ContributorStats struct {
+	Since time.Time
+	Until time.Time
+	// The number of commits within the window.
+	TotalCommits int
+	// The number of authors with at least one commit within the window.
+	ActiveContributors int
+	// The authors with commits within the window, ordered by commit count.
+	Authors []Author
+	// The number of top authors TopAuthorsShare was calculated with.
+	TopN int
+	// The percentage (0-100) of commits within the window made by the TopN
+	// authors.
+	TopAuthorsShare float64
+	// The fewest number of authors that account for at least half of the
+	// commits within the window. A low bus factor means the project relies on
+	// few people.
+	BusFactor int
+	// Authors whose first commit in the entire history falls within the
+	// window. Only calculated when Since is set.
+	NewContributors []Author
+}
+
+// GetAuthors takes a list of commits and returns each unique author (by email
+// address) ordered by their number of commits, highest first.
+func GetAuthors(commits []Commit) []Author {
+	authors := map[string]*Author{}
+	for _, c := range commits {
+		key := strings.ToLower(c.Author.Email)
+		a, ok := authors[key]
+		if !ok {
+			a = &Author{Person: c.Author, FirstCommit: c.Date, LastCommit: c.Date}
+			authors[key] = a
+		}
+		a.CommitCount++
+		if c.Date.Before(a.FirstCommit) {
+			a.FirstCommit = c.Date
+		}
+		if c.Date.After(a.LastCommit) {
+			a.LastCommit = c.Date
+		}
+	}
+
+	authorList := []Author{}
+	for _, a := range authors {
+		authorList = append(authorList, *a)
+	}
+	sortAuthors(authorList)
+	return authorList
+}
+
+// NewContributorStats calculates [ContributorStats] from commits, which
+// should contain the full history of the repository so new contributors can be
+// detected.
+func NewContributorStats(commits []Commit, opts ContributorStatsOpts) ContributorStats {
+	if opts.TopN < 1 {
+		opts.TopN = DefaultTopAuthors
+	}
+	windowed := []Commit{}
+	for _, c := range commits {
+		if inWindow(c.Date, opts.Since, opts.Until) {
+			windowed = append(windowed, c)
+		}
+	}
+
+	authors := GetAuthors(windowed)
+	stats := ContributorStats{
+		Since:              opts.Since,
+		Until:              opts.Until,
+		TotalCommits:       len(windowed),
+		ActiveContributors: len(authors),
+		Authors:            authors,
+		TopN:               opts.TopN,
+	}
+	if len(windowed) == 0 {
+		return stats
+	}
+
+	topCommits := 0
+	for i := 0; i < len(authors) && i < opts.TopN; i++ {
+		topCommits += authors[i].CommitCount
+	}
+	stats.TopAuthorsShare = float64(topCommits) / float64(len(windowed)) * 100
+
+	covered := 0
+	for _, a := range authors {
+		covered += a.CommitCount
+		stats.BusFactor++
+		if float64(covered) >= float64(len(windowed))*busFactorThreshold {
+			break
+		}
+	}
+
+	if !opts.Since.IsZero() {
+		// an author is new when their first commit across all history is
+		// within the window.
+		firstCommits := map[string]time.Time{}
+		for _, a := range GetAuthors(commits) {
+			firstCommits[strings.ToLower(a.Email)] = a.FirstCommit
+		}
+		for _, a := range authors {
+			if !firstCommits[strings.ToLower(a.Email)].Before(opts.Since) {
+				stats.NewContributors = append(stats.NewContributors, a)
+			}
+		}
+	}
+	return stats
+}
+
+// FilterCommitsByTime returns the commits dated within since and until. A zero
+// since or until leaves that side of the window unbounded. It's useful for
+// commits retrieved without [GetCommitsOpts], such as those of a tag.
+func FilterCommitsByTime(commits []Commit, since, until time.Time) []Commit {
+	result := []Commit{}
+	for _, c := range commits {
+		if inWindow(c.Date, since, until) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// inWindow returns whether t is within since and until. A zero since or until
+// leaves that side of the window unbounded.
+func inWindow(t, since, until time.Time) bool {
+	if !since.IsZero() && t.Before(since) {
+		return false
+	}
+	if !until.IsZero() && t.After(until) {
+		return false
+	}
+	return true
+}
+
+// sortAuthors orders authors by commit count, highest first. Ties are ordered
+// by email so output is stable.
+func sortAuthors(authors []Author) {
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].CommitCount != authors[j].CommitCount {
+			return authors[i].CommitCount > authors[j].CommitCount
+		}
+		return authors[i].Email < authors[j].Email
+	})
+}
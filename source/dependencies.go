@@ -0,0 +1,310 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Ecosystems of the dependencies found in manifests. The names match those
+// used by the [OSV] schema so dependencies can be looked up in vulnerability
+// databases.
+//
+// [OSV]: https://ossf.github.io/osv-schema/#affectedpackage-field
+const (
+	GoEcosystem   = "Go"
+	NPMEcosystem  = "npm"
+	PyPIEcosystem = "PyPI"
+)
+
+// manifestEcosystems maps the file names of supported dependency manifests to
+// their ecosystem.
+var manifestEcosystems = map[string]string{
+	"go.mod":           GoEcosystem,
+	"package.json":     NPMEcosystem,
+	"requirements.txt": PyPIEcosystem,
+}
+
+// Directories containing third-party code, whose manifests are not part of the
+// repository's own dependencies.
+var skippedManifestDirs = map[string]bool{
+	"vendor":       true,
+	"node_modules": true,
+	"testdata":     true,
+}
+
+var (
+	// matches a requirement such as requests[security]>=2.8.1 ; python_version < "3"
+	requirementRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(?:\[[^\]]*\])?\s*([^;]*)`)
+	// matches runs of characters that are equivalent in python package names.
+	pypiNameSeparatorRegex = regexp.MustCompile(`[-_.]+`)
+)
+
+// Dependency is a single dependency declared in a manifest.
+type Dependency struct {
+	Name string
+	// The version, or version constraint, of the dependency as declared in the
+	// manifest (e.g. v1.2.3, ^4.0.0, or >=2.8). Empty when unconstrained.
+	Version   string
+	Ecosystem string
+	// The path of the manifest declaring the dependency, relative to the root
+	// of the repository.
+	Manifest string
+	// Whether the dependency is only required indirectly, such as go.mod
+	// requirements marked with // indirect.
+	Indirect bool
+	// Whether the dependency is only required for development, such as
+	// devDependencies in package.json.
+	Dev bool
+}
+
+// DependencyChange is a dependency whose version differs between two sets of
+// dependencies.
+type DependencyChange struct {
+	Dependency
+	PreviousVersion string
+}
+
+// DependencyDiff describes how dependencies changed between two refs.
+type DependencyDiff struct {
+	Added   []Dependency
+	Removed []Dependency
+	Changed []DependencyChange
+}
+
+// GetDependencies finds every supported dependency manifest (go.mod,
+// package.json, and requirements.txt) in the repository at ref and returns the
+// dependencies they declare. ref can be a tag, branch, or commit hash; when
+// empty, the repository's PinnedCommit or, if unset, HEAD is used. Manifests
+// within vendor, node_modules, and testdata directories are skipped.
+// Dependencies are ordered by manifest and name. An error is returned when the
+// tree cannot be walked or a manifest cannot be read or parsed.
+func (gm *GitManager) GetDependencies(r Repository, ref string) ([]Dependency, error) {
+	tree, err := treeAtRef(r, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []Dependency{}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed walking the tree at ref (%s): %s", ref, err)
+		}
+		if entry.Mode == filemode.Dir {
+			continue
+		}
+		if _, ok := manifestEcosystems[entry.Name]; !ok || inSkippedDir(name) {
+			continue
+		}
+		f, err := tree.File(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading manifest (%s): %s", name, err)
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed reading manifest (%s): %s", name, err)
+		}
+		manifestDeps, err := ParseManifest(name, []byte(content))
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, manifestDeps...)
+	}
+
+	sort.SliceStable(deps, func(i, j int) bool {
+		if deps[i].Manifest != deps[j].Manifest {
+			return deps[i].Manifest < deps[j].Manifest
+		}
+		return deps[i].Name < deps[j].Name
+	})
+	return deps, nil
+}
+
+// ParseManifest parses the dependency manifest at manifestPath, which must be
+// named go.mod, package.json, or requirements.txt. An error is returned when
+// the manifest type is unsupported or its content cannot be parsed.
+func ParseManifest(manifestPath string, content []byte) ([]Dependency, error) {
+	switch path.Base(manifestPath) {
+	case "go.mod":
+		return parseGoMod(manifestPath, content)
+	case "package.json":
+		return parsePackageJSON(manifestPath, content)
+	case "requirements.txt":
+		return parseRequirements(manifestPath, content), nil
+	}
+	return nil, fmt.Errorf("manifest (%s) is not a supported type", manifestPath)
+}
+
+// DiffDependencies compares the dependencies found at two refs, from and to.
+// Dependencies are matched by ecosystem, manifest, and name.
+func DiffDependencies(from []Dependency, to []Dependency) DependencyDiff {
+	key := func(d Dependency) string {
+		return d.Ecosystem + "|" + d.Manifest + "|" + d.Name
+	}
+	fromDeps := map[string]Dependency{}
+	for _, d := range from {
+		fromDeps[key(d)] = d
+	}
+
+	diff := DependencyDiff{}
+	seen := map[string]bool{}
+	for _, d := range to {
+		k := key(d)
+		seen[k] = true
+		prev, ok := fromDeps[k]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, d)
+		case prev.Version != d.Version:
+			diff.Changed = append(diff.Changed, DependencyChange{Dependency: d, PreviousVersion: prev.Version})
+		}
+	}
+	for _, d := range from {
+		if !seen[key(d)] {
+			diff.Removed = append(diff.Removed, d)
+		}
+	}
+	return diff
+}
+
+// parseGoMod returns the requirements of a go.mod file.
+func parseGoMod(manifestPath string, content []byte) ([]Dependency, error) {
+	deps := []Dependency{}
+	inRequireBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		var requirement string
+		switch {
+		case inRequireBlock && line == ")":
+			inRequireBlock = false
+			continue
+		case inRequireBlock:
+			requirement = line
+		case strings.HasPrefix(line, "require ("), line == "require(":
+			inRequireBlock = true
+			continue
+		case strings.HasPrefix(line, "require "):
+			requirement = strings.TrimPrefix(line, "require ")
+		default:
+			continue
+		}
+
+		indirect := false
+		if i := strings.Index(requirement, "//"); i >= 0 {
+			indirect = strings.TrimSpace(requirement[i+2:]) == "indirect"
+			requirement = requirement[:i]
+		}
+		fields := strings.Fields(requirement)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("failed parsing %s: invalid requirement on line %d", manifestPath, lineNum)
+		}
+		deps = append(deps, Dependency{
+			Name:      strings.Trim(fields[0], `"`),
+			Version:   fields[1],
+			Ecosystem: GoEcosystem,
+			Manifest:  manifestPath,
+			Indirect:  indirect,
+		})
+	}
+	return deps, nil
+}
+
+// parsePackageJSON returns the dependencies, devDependencies, and
+// optionalDependencies of a package.json file.
+func parsePackageJSON(manifestPath string, content []byte) ([]Dependency, error) {
+	pkg := struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}{}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, fmt.Errorf("failed parsing %s: %s", manifestPath, err)
+	}
+
+	deps := []Dependency{}
+	add := func(m map[string]string, dev bool) {
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			deps = append(deps, Dependency{
+				Name:      name,
+				Version:   m[name],
+				Ecosystem: NPMEcosystem,
+				Manifest:  manifestPath,
+				Dev:       dev,
+			})
+		}
+	}
+	add(pkg.Dependencies, false)
+	add(pkg.OptionalDependencies, false)
+	add(pkg.DevDependencies, true)
+	return deps, nil
+}
+
+// parseRequirements returns the requirements of a pip requirements file.
+// Options (e.g. -r other.txt) and requirements that aren't package names,
+// such as URLs, are skipped. Package names are normalized as described in
+// PEP 503. Versions pinned with == are returned without the operator; other
+// constraints are returned as written.
+func parseRequirements(manifestPath string, content []byte) []Dependency {
+	deps := []Dependency{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		match := requirementRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		version := strings.ReplaceAll(strings.TrimSpace(match[2]), " ", "")
+		if strings.HasPrefix(version, "==") && !strings.Contains(version, ",") {
+			version = strings.TrimPrefix(version, "==")
+		}
+		deps = append(deps, Dependency{
+			Name:      strings.ToLower(pypiNameSeparatorRegex.ReplaceAllString(match[1], "-")),
+			Version:   version,
+			Ecosystem: PyPIEcosystem,
+			Manifest:  manifestPath,
+		})
+	}
+	return deps
+}
+
+// inSkippedDir returns whether p is within a directory whose manifests are
+// skipped.
+func inSkippedDir(p string) bool {
+	for _, dir := range strings.Split(path.Dir(p), "/") {
+		if skippedManifestDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseManifest(t *testing.T) {
+	goMod := []byte(`module github.com/arctir/proctor
+
+go 1.18
+
+require github.com/spf13/cobra v1.5.0
+
+require (
+	github.com/adrg/xdg v0.4.0
+	golang.org/x/sys v0.2.0 // indirect
+)
+`)
+	deps, err := ParseManifest("go.mod", goMod)
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing go.mod: %s", err)
+	}
+	if len(deps) != 3 {
+		t.Fatalf("fail: go.mod had wrong number of dependencies, expected: %d, actual: %d", 3, len(deps))
+	}
+	if deps[0].Name != "github.com/spf13/cobra" || deps[0].Version != "v1.5.0" || deps[0].Ecosystem != GoEcosystem {
+		t.Logf("fail: go.mod dependency was wrong: %+v", deps[0])
+		t.Fail()
+	}
+	if deps[1].Indirect || !deps[2].Indirect {
+		t.Logf("fail: indirect dependencies were not detected: %+v", deps[1:])
+		t.Fail()
+	}
+
+	pkgJSON := []byte(`{"name": "ui", "dependencies": {"react": "^18.2.0"}, "devDependencies": {"jest": "29.0.0"}}`)
+	deps, err = ParseManifest("web/package.json", pkgJSON)
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing package.json: %s", err)
+	}
+	if len(deps) != 2 || deps[0].Name != "react" || deps[0].Dev || !deps[1].Dev || deps[1].Manifest != "web/package.json" {
+		t.Logf("fail: package.json dependencies were wrong: %+v", deps)
+		t.Fail()
+	}
+
+	requirements := []byte(`# comment
+-r base.txt
+Django==4.1.3
+requests[security] >= 2.8.1 ; python_version > "3"
+zope.interface
+`)
+	deps, err = ParseManifest("requirements.txt", requirements)
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing requirements.txt: %s", err)
+	}
+	expected := []Dependency{
+		{Name: "django", Version: "4.1.3"},
+		{Name: "requests", Version: ">=2.8.1"},
+		{Name: "zope-interface", Version: ""},
+	}
+	if len(deps) != len(expected) {
+		t.Fatalf("fail: requirements.txt had wrong number of dependencies, expected: %d, actual: %d", len(expected), len(deps))
+	}
+	for i, e := range expected {
+		if deps[i].Name != e.Name || deps[i].Version != e.Version {
+			t.Logf("fail: requirement %d was wrong, expected: %s %s, actual: %s %s", i, e.Name, e.Version, deps[i].Name, deps[i].Version)
+			t.Fail()
+		}
+	}
+
+	if _, err := ParseManifest("Cargo.toml", nil); err == nil {
+		t.Log("fail: expected error parsing an unsupported manifest")
+		t.Fail()
+	}
+}
+
+func TestGetDependencies(t *testing.T) {
+	gm := NewGitManager()
+	jane := object.Signature{Name: "Jane", Email: "jane@example.com"}
+	r, err := createTestRepoWithCommits("deps", testRepo2Start, []testCommit{
+		{file: "go.mod", message: "module example.com/app\n\nrequire (\n\tgithub.com/a/b v1.0.0\n\tgithub.com/c/d v0.1.0\n)\n", author: jane, tag: "v0.1.0"},
+		{file: "vendor/github.com/a/b/go.mod", message: "module github.com/a/b\n\nrequire github.com/e/f v1.0.0\n", author: jane},
+		{file: "go.mod", message: "module example.com/app\n\nrequire (\n\tgithub.com/a/b v1.1.0\n\tgithub.com/g/h v2.0.0\n)\n", author: jane, tag: "v0.2.0"},
+	})
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+
+	from, err := gm.GetDependencies(*r, "v0.1.0")
+	if err != nil {
+		t.Fatalf("fail: error retrieving dependencies: %s", err)
+	}
+	to, err := gm.GetDependencies(*r, "")
+	if err != nil {
+		t.Fatalf("fail: error retrieving dependencies: %s", err)
+	}
+	if len(to) != 2 {
+		t.Fatalf("fail: vendored manifests should be skipped, got dependencies: %+v", to)
+	}
+
+	diff := DiffDependencies(from, to)
+	if len(diff.Added) != 1 || diff.Added[0].Name != "github.com/g/h" {
+		t.Logf("fail: added dependencies were wrong: %+v", diff.Added)
+		t.Fail()
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "github.com/c/d" {
+		t.Logf("fail: removed dependencies were wrong: %+v", diff.Removed)
+		t.Fail()
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].PreviousVersion != "v1.0.0" || diff.Changed[0].Version != "v1.1.0" {
+		t.Logf("fail: changed dependencies were wrong: %+v", diff.Changed)
+		t.Fail()
+	}
+
+	if _, err := gm.GetDependencies(*r, "v9.9.9"); err == nil {
+		t.Log("fail: expected error retrieving dependencies of a ref that doesn't exist")
+		t.Fail()
+	}
+}
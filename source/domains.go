@@ -0,0 +1,91 @@
+package source
+
+import (
+	"sort"
+	"strings"
+)
+
+// UnknownDomain is the domain of authors whose email address has none.
+const UnknownDomain = "unknown"
+
+// Organization is a group of commit authors sharing an email domain, or a set
+// of domains aliased to the same name.
+type Organization struct {
+	// The alias the domains are grouped under, or the domain when it isn't
+	// aliased.
+	Name string
+	// The email domains of the organization's authors, sorted.
+	Domains     []string
+	AuthorCount int
+	CommitCount int
+}
+
+// EmailDomain returns the lowercased domain of an email address, or
+// [UnknownDomain] when it has none.
+func EmailDomain(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 0 || i == len(email)-1 {
+		return UnknownDomain
+	}
+	return strings.ToLower(email[i+1:])
+}
+
+// GetOrganizations groups authors by their email domain and returns the groups
+// ordered by their number of commits, highest first. aliases maps a domain to
+// the organization it belongs to, such that corporate domains (e.g. vmware.com
+// and broadcom.com) can be counted together. An alias also applies to the
+// domain's subdomains. aliases may be nil.
+func GetOrganizations(authors []Author, aliases map[string]string) []Organization {
+	normalized := map[string]string{}
+	for d, name := range aliases {
+		normalized[strings.ToLower(strings.TrimPrefix(d, "@"))] = name
+	}
+
+	orgs := map[string]*Organization{}
+	domains := map[string]map[string]bool{}
+	for _, a := range authors {
+		domain := EmailDomain(a.Email)
+		name := resolveAlias(domain, normalized)
+		o, ok := orgs[name]
+		if !ok {
+			o = &Organization{Name: name}
+			orgs[name] = o
+			domains[name] = map[string]bool{}
+		}
+		o.AuthorCount++
+		o.CommitCount += a.CommitCount
+		domains[name][domain] = true
+	}
+
+	orgList := []Organization{}
+	for name, o := range orgs {
+		for d := range domains[name] {
+			o.Domains = append(o.Domains, d)
+		}
+		sort.Strings(o.Domains)
+		orgList = append(orgList, *o)
+	}
+	sort.Slice(orgList, func(i, j int) bool {
+		if orgList[i].CommitCount != orgList[j].CommitCount {
+			return orgList[i].CommitCount > orgList[j].CommitCount
+		}
+		return orgList[i].Name < orgList[j].Name
+	})
+	return orgList
+}
+
+// resolveAlias returns the alias of domain or its closest parent domain, or
+// domain itself when there is none.
+func resolveAlias(domain string, aliases map[string]string) string {
+	for d := domain; d != ""; {
+		if name, ok := aliases[d]; ok {
+			return name
+		}
+		i := strings.Index(d, ".")
+		if i < 0 {
+			break
+		}
+		d = d[i+1:]
+	}
+	return domain
+}
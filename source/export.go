@@ -0,0 +1,184 @@
+package source
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitRecord is the serialized form of a [Commit], written by
+// [WriteCommitsJSON] and [WriteCommitsCSV]. Unlike Commit, its hash and
+// message are strings, so it is readable by other tools.
+type CommitRecord struct {
+	Hash           string
+	Title          string
+	Date           time.Time
+	AuthorName     string
+	AuthorEmail    string
+	CommitterName  string
+	CommitterEmail string
+	Message        string
+}
+
+// The header row of commits written as CSV.
+var commitCSVHeader = []string{"hash", "title", "date", "author_name", "author_email", "committer_name", "committer_email", "message"}
+
+// The header row of authors written as CSV.
+var authorCSVHeader = []string{"name", "email", "commits", "first_commit", "last_commit"}
+
+// The header row of organizations written as CSV.
+var organizationCSVHeader = []string{"organization", "domains", "authors", "commits"}
+
+// The header row of code owner coverage written as CSV.
+var ownerCoverageCSVHeader = []string{"owner", "commits", "files"}
+
+// The header row of activity buckets written as CSV.
+var activityCSVHeader = []string{"start", "end", "commits", "unique_authors"}
+
+// NewCommitRecords converts commits into their serialized form.
+func NewCommitRecords(commits []Commit) []CommitRecord {
+	records := make([]CommitRecord, 0, len(commits))
+	for _, c := range commits {
+		records = append(records, CommitRecord{
+			Hash:           c.Hash.String(),
+			Title:          c.Title,
+			Date:           c.Date,
+			AuthorName:     c.Author.Name,
+			AuthorEmail:    c.Author.Email,
+			CommitterName:  c.Committer.Name,
+			CommitterEmail: c.Committer.Email,
+			Message:        string(c.Message),
+		})
+	}
+	return records
+}
+
+// WriteCommitsJSON writes commits to w as a JSON array of [CommitRecord].
+func WriteCommitsJSON(w io.Writer, commits []Commit) error {
+	return json.NewEncoder(w).Encode(NewCommitRecords(commits))
+}
+
+// WriteCommitsCSV writes commits to w as CSV, with a header row followed by a
+// row per commit. Dates are formatted as RFC 3339.
+func WriteCommitsCSV(w io.Writer, commits []Commit) error {
+	cw := csv.NewWriter(w)
+	cw.Write(commitCSVHeader)
+	for _, r := range NewCommitRecords(commits) {
+		cw.Write([]string{
+			r.Hash,
+			r.Title,
+			r.Date.Format(time.RFC3339),
+			r.AuthorName,
+			r.AuthorEmail,
+			r.CommitterName,
+			r.CommitterEmail,
+			r.Message,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteAuthorsJSON writes authors to w as a JSON array.
+func WriteAuthorsJSON(w io.Writer, authors []Author) error {
+	return json.NewEncoder(w).Encode(authors)
+}
+
+// WriteAuthorsCSV writes authors to w as CSV, with a header row followed by a
+// row per author. Dates are formatted as RFC 3339.
+func WriteAuthorsCSV(w io.Writer, authors []Author) error {
+	cw := csv.NewWriter(w)
+	cw.Write(authorCSVHeader)
+	for _, a := range authors {
+		cw.Write([]string{
+			a.Name,
+			a.Email,
+			strconv.Itoa(a.CommitCount),
+			a.FirstCommit.Format(time.RFC3339),
+			a.LastCommit.Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteOrganizationsJSON writes orgs to w as a JSON array.
+func WriteOrganizationsJSON(w io.Writer, orgs []Organization) error {
+	return json.NewEncoder(w).Encode(orgs)
+}
+
+// WriteOrganizationsCSV writes orgs to w as CSV, with a header row followed by
+// a row per organization. Domains are separated by spaces.
+func WriteOrganizationsCSV(w io.Writer, orgs []Organization) error {
+	cw := csv.NewWriter(w)
+	cw.Write(organizationCSVHeader)
+	for _, o := range orgs {
+		cw.Write([]string{
+			o.Name,
+			strings.Join(o.Domains, " "),
+			strconv.Itoa(o.AuthorCount),
+			strconv.Itoa(o.CommitCount),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteContributorStatsJSON writes stats to w as a JSON object.
+func WriteContributorStatsJSON(w io.Writer, stats ContributorStats) error {
+	return json.NewEncoder(w).Encode(stats)
+}
+
+// WriteContributorStatsCSV writes stats to w as CSV. As CSV is a single table,
+// only the authors within the window are written, the same as
+// [WriteAuthorsCSV]; use [WriteContributorStatsJSON] to include the summary.
+func WriteContributorStatsCSV(w io.Writer, stats ContributorStats) error {
+	return WriteAuthorsCSV(w, stats.Authors)
+}
+
+// WriteActivityJSON writes buckets to w as a JSON array.
+func WriteActivityJSON(w io.Writer, buckets []ActivityBucket) error {
+	return json.NewEncoder(w).Encode(buckets)
+}
+
+// WriteActivityCSV writes buckets to w as CSV, with a header row followed by a
+// row per bucket. Dates are formatted as RFC 3339.
+func WriteActivityCSV(w io.Writer, buckets []ActivityBucket) error {
+	cw := csv.NewWriter(w)
+	cw.Write(activityCSVHeader)
+	for _, b := range buckets {
+		cw.Write([]string{
+			b.Start.Format(time.RFC3339),
+			b.End.Format(time.RFC3339),
+			strconv.Itoa(b.Commits),
+			strconv.Itoa(b.UniqueAuthors),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteOwnerCoverageJSON writes coverage to w as a JSON array.
+func WriteOwnerCoverageJSON(w io.Writer, coverage []OwnerCoverage) error {
+	return json.NewEncoder(w).Encode(coverage)
+}
+
+// WriteOwnerCoverageCSV writes coverage to w as CSV, with a header row
+// followed by a row per owner. Files without an owner are counted in a row
+// with an empty owner.
+func WriteOwnerCoverageCSV(w io.Writer, coverage []OwnerCoverage) error {
+	cw := csv.NewWriter(w)
+	cw.Write(ownerCoverageCSVHeader)
+	for _, c := range coverage {
+		cw.Write([]string{
+			c.Owner,
+			strconv.Itoa(c.Commits),
+			strconv.Itoa(c.Files),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
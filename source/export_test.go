@@ -0,0 +1,77 @@
+package source
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWriteCommits(t *testing.T) {
+	commits := []Commit{{
+		Hash:    Hash{0xab},
+		Title:   "fix: handle empty input",
+		Date:    time.Date(2022, 1, 4, 12, 0, 0, 0, time.UTC),
+		Author:  Person{Name: "Jane", Email: "jane@example.com"},
+		Message: []byte("fix: handle empty input\n\nCloses #12"),
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteCommitsJSON(&buf, commits); err != nil {
+		t.Fatalf("fail: unexpected error writing json: %s", err)
+	}
+	records := []CommitRecord{}
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("fail: written json was invalid: %s", err)
+	}
+	if len(records) != 1 || records[0].Hash != commits[0].Hash.String() || records[0].Message != string(commits[0].Message) {
+		t.Logf("fail: json records were wrong: %+v", records)
+		t.Fail()
+	}
+
+	buf.Reset()
+	if err := WriteCommitsCSV(&buf, commits); err != nil {
+		t.Fatalf("fail: unexpected error writing csv: %s", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("fail: written csv was invalid: %s", err)
+	}
+	if len(rows) != 2 || rows[0][0] != "hash" || rows[1][2] != "2022-01-04T12:00:00Z" || rows[1][7] != string(commits[0].Message) {
+		t.Logf("fail: csv rows were wrong: %q", rows)
+		t.Fail()
+	}
+}
+
+func TestWriteAuthorsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteAuthorsCSV(&buf, []Author{{Person: Person{Name: "Doe, Jane", Email: "jane@example.com"}, CommitCount: 3}})
+	if err != nil {
+		t.Fatalf("fail: unexpected error writing csv: %s", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("fail: written csv was invalid: %s", err)
+	}
+	if len(rows) != 2 || rows[1][0] != "Doe, Jane" || rows[1][2] != "3" {
+		t.Logf("fail: csv rows were wrong: %q", rows)
+		t.Fail()
+	}
+}
+
+func TestWriteOwnerCoverageCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteOwnerCoverageCSV(&buf, []OwnerCoverage{{Owner: "@org/team", Commits: 4, Files: 2}, {Commits: 1, Files: 1}})
+	if err != nil {
+		t.Fatalf("fail: unexpected error writing csv: %s", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("fail: written csv was invalid: %s", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "owner" || rows[1][0] != "@org/team" || rows[1][1] != "4" || rows[2][0] != "" || rows[2][2] != "1" {
+		t.Logf("fail: csv rows were wrong: %q", rows)
+		t.Fail()
+	}
+}
@@ -0,0 +1,158 @@
+package source
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/arctir/proctor/platforms"
+)
+
+// The number of hours in a day, used to express durations in days.
+const hoursPerDay = 24
+
+// RepoHealth describes how actively a repository is maintained. It is meant to
+// be used as input to higher-level scoring, so it reports raw measurements
+// rather than a judgement. Averages are 0 when there are too few commits or
+// tags to calculate them.
+type RepoHealth struct {
+	// The time health was measured at.
+	MeasuredAt time.Time
+	// The date of the most recent commit and the number of days since it.
+	LastCommit          time.Time
+	DaysSinceLastCommit float64
+	TotalCommits        int
+	// The number of commits with more than one parent.
+	MergeCommits int
+	// The share (0-1) of commits that are merge commits.
+	MergeCommitRatio float64
+	// The number of tags and the average number of days between consecutive
+	// tags, including pre-releases and tags that are not semantic versions.
+	Tags                   int
+	AverageDaysBetweenTags float64
+	// The days since the most recent tag. The cadence is open-ended: when it
+	// exceeds AverageDaysBetweenTags, the next tag is overdue.
+	DaysSinceLastTag float64
+	// The number of stable (non pre-release) semantic version tags.
+	Releases int
+	// The most recent stable release, by semantic version, and its date.
+	LatestRelease     string
+	LatestReleaseDate time.Time
+	// The average number of days between consecutive stable releases.
+	AverageDaysBetweenReleases float64
+	DaysSinceLastRelease       float64
+	// The issue and pull request activity of the repository, as reported by
+	// the platform hosting it. Only set when retrieved with
+	// GetRepoHealthOpts.Platform.
+	Issues *platforms.IssueStats
+}
+
+// GetRepoHealthOpts configures how repository health is measured.
+type GetRepoHealthOpts struct {
+	// The platform hosting the repository, used to retrieve its issue and
+	// pull request statistics. When nil, only the git history is measured.
+	Platform platforms.Platform
+	// The repository's path on Platform, e.g. arctir/proctor.
+	PlatformRepo string
+}
+
+// GetRepoHealth calculates the [RepoHealth] of a repository, as of now, from
+// the full history of its default branch and its tags. When
+// GetRepoHealthOpts.Platform is set, the repository's issue and pull request
+// statistics are included.
+func (gm *GitManager) GetRepoHealth(r Repository, opts ...GetRepoHealthOpts) (RepoHealth, error) {
+	conf := GetRepoHealthOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	commits, err := gm.GetCommits(r)
+	if err != nil {
+		return RepoHealth{}, fmt.Errorf("failed retrieving commits: %s", err)
+	}
+	tags, err := gm.GetTagsFromRepository(r)
+	if err != nil {
+		return RepoHealth{}, err
+	}
+	health := NewRepoHealth(commits, tags, time.Now())
+	if conf.Platform != nil {
+		issues, err := conf.Platform.GetIssueStats(conf.PlatformRepo)
+		if err != nil {
+			return RepoHealth{}, fmt.Errorf("failed retrieving issue statistics: %s", err)
+		}
+		health.Issues = &issues
+	}
+	return health, nil
+}
+
+// NewRepoHealth calculates [RepoHealth] from commits and tags, as of now. The
+// date of a tag is its Date when set, otherwise the date of its LastCommit, as
+// found in commits. Tags without a known date are ignored.
+func NewRepoHealth(commits []Commit, tags []Tag, now time.Time) RepoHealth {
+	health := RepoHealth{
+		MeasuredAt:   now,
+		TotalCommits: len(commits),
+	}
+	commitDates := map[Hash]time.Time{}
+	for _, c := range commits {
+		commitDates[c.Hash] = c.Date
+		if c.Date.After(health.LastCommit) {
+			health.LastCommit = c.Date
+		}
+		if len(c.Parents) > 1 {
+			health.MergeCommits++
+		}
+	}
+	if len(commits) > 0 {
+		health.DaysSinceLastCommit = daysBetween(health.LastCommit, now)
+		health.MergeCommitRatio = float64(health.MergeCommits) / float64(len(commits))
+	}
+
+	tagDates := []time.Time{}
+	releaseDates := []time.Time{}
+	releases := []Tag{}
+	for _, t := range tags {
+		date := t.Date
+		if date.IsZero() {
+			date = commitDates[t.LastCommit]
+		}
+		if date.IsZero() {
+			continue
+		}
+		tagDates = append(tagDates, date)
+		if v, err := ParseSemver(t.Name); err == nil && !v.IsPrerelease() {
+			t.Date = date
+			releases = append(releases, t)
+			releaseDates = append(releaseDates, date)
+		}
+	}
+	health.Tags = len(tagDates)
+	health.AverageDaysBetweenTags, health.DaysSinceLastTag = cadence(tagDates, now)
+	health.Releases = len(releases)
+	health.AverageDaysBetweenReleases, health.DaysSinceLastRelease = cadence(releaseDates, now)
+	if latest, err := GetLatestStableTag(releases); err == nil {
+		health.LatestRelease = latest.Name
+		health.LatestReleaseDate = latest.Date
+	}
+	return health
+}
+
+// cadence returns the average number of days between consecutive dates and
+// the number of days from the most recent date until now. Both are 0 when
+// there are too few dates.
+func cadence(dates []time.Time, now time.Time) (float64, float64) {
+	if len(dates) == 0 {
+		return 0, 0
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	since := daysBetween(dates[len(dates)-1], now)
+	if len(dates) < 2 {
+		return 0, since
+	}
+	return daysBetween(dates[0], dates[len(dates)-1]) / float64(len(dates)-1), since
+}
+
+// daysBetween returns the number of days, including fractions, from start to
+// end.
+func daysBetween(start, end time.Time) float64 {
+	return end.Sub(start).Hours() / hoursPerDay
+}
@@ -0,0 +1,98 @@
+package source
+
+import (
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/arctir/proctor/platforms"
+)
+
+func TestNewRepoHealth(t *testing.T) {
+	day := func(n int) time.Time { return testRepo2Start.AddDate(0, 0, n) }
+	commits := []Commit{
+		{Hash: Hash{4}, Date: day(30), Parents: []Hash{{3}, {9}}},
+		{Hash: Hash{3}, Date: day(20), Parents: []Hash{{2}}},
+		{Hash: Hash{2}, Date: day(10), Parents: []Hash{{1}}},
+		{Hash: Hash{1}, Date: day(0)},
+	}
+	tags := []Tag{
+		{Name: "v0.1.0", LastCommit: Hash{1}},
+		{Name: "v0.2.0-rc.1", LastCommit: Hash{2}},
+		{Name: "v0.2.0", Date: day(20), LastCommit: Hash{3}},
+		// not found in commits and without a date, so ignored
+		{Name: "v0.3.0", LastCommit: Hash{8}},
+	}
+
+	health := NewRepoHealth(commits, tags, day(40))
+	if health.DaysSinceLastCommit != 10 || health.MergeCommits != 1 || health.MergeCommitRatio != 0.25 {
+		t.Logf("fail: commit health was wrong: %+v", health)
+		t.Fail()
+	}
+	if health.Tags != 3 || health.AverageDaysBetweenTags != 10 || health.DaysSinceLastTag != 20 {
+		t.Logf("fail: tag cadence was wrong: %+v", health)
+		t.Fail()
+	}
+	if health.Releases != 2 || health.LatestRelease != "v0.2.0" || health.AverageDaysBetweenReleases != 20 {
+		t.Logf("fail: release cadence was wrong: %+v", health)
+		t.Fail()
+	}
+
+	empty := NewRepoHealth(nil, nil, day(40))
+	if empty.MergeCommitRatio != 0 || math.IsNaN(empty.AverageDaysBetweenReleases) || empty.LatestRelease != "" {
+		t.Logf("fail: health of empty repo was wrong: %+v", empty)
+		t.Fail()
+	}
+}
+
+func TestGetRepoHealth(t *testing.T) {
+	gm := NewGitManager()
+	r, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+
+	health, err := gm.GetRepoHealth(*r)
+	if err != nil {
+		t.Fatalf("fail: error retrieving repo health: %s", err)
+	}
+	if health.TotalCommits != 4 || health.Releases != 2 || health.LatestRelease != "v0.2.0" || health.AverageDaysBetweenReleases != 2 {
+		t.Logf("fail: repo health was wrong: %+v", health)
+		t.Fail()
+	}
+	if health.Issues != nil {
+		t.Logf("fail: issue stats were set without a platform: %+v", health.Issues)
+		t.Fail()
+	}
+
+	health, err = gm.GetRepoHealth(*r, GetRepoHealthOpts{Platform: issuesPlatform{}, PlatformRepo: "arctir/proctor"})
+	if err != nil {
+		t.Fatalf("fail: error retrieving repo health with issues: %s", err)
+	}
+	if health.Issues == nil || health.Issues.OpenIssues != 5 {
+		t.Logf("fail: issue stats were wrong: %+v", health.Issues)
+		t.Fail()
+	}
+}
+
+// issuesPlatform is a [platforms.Platform] that only reports issue statistics.
+type issuesPlatform struct{}
+
+func (p issuesPlatform) GetReleases(repo string) ([]platforms.Release, error) { return nil, nil }
+func (p issuesPlatform) GetArtifacts(repo string, tag string) ([]platforms.Artifact, error) {
+	return nil, nil
+}
+func (p issuesPlatform) GetRepoMetadata(repo string) (platforms.RepoMetadata, error) {
+	return platforms.RepoMetadata{}, nil
+}
+func (p issuesPlatform) GetContributors(repo string) ([]platforms.Contributor, error) {
+	return nil, nil
+}
+func (p issuesPlatform) DownloadArtifact(a platforms.Artifact) (io.ReadCloser, error) {
+	return nil, io.EOF
+}
+func (p issuesPlatform) GetIssueStats(repo string) (platforms.IssueStats, error) {
+	return platforms.IssueStats{OpenIssues: 5}, nil
+}
@@ -0,0 +1,177 @@
+package source
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/sideband"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+// The stage reported while the repository's objects are being downloaded,
+// once the remote has stopped reporting its own progress.
+const ReceivingStage = "Receiving objects"
+
+var (
+	// matches a progress message sent by a git server, such as
+	// "Counting objects:  45% (45/100)" or "Enumerating objects: 120, done.".
+	progressMessageRegex = regexp.MustCompile(`^([A-Za-z][A-Za-z ]*):\s+(?:\d+%\s+\()?(\d+)(?:/(\d+))?`)
+)
+
+// Progress describes how far along retrieving a repository is.
+type Progress struct {
+	// The current stage, as reported by the remote (e.g. Counting objects or
+	// Compressing objects). Once the remote is done reporting, the stage is
+	// [ReceivingStage].
+	Stage string
+	// The number of objects processed in the current stage.
+	Objects int
+	// The total number of objects to process in the current stage, or 0 when
+	// unknown.
+	TotalObjects int
+	// The number of bytes received from the remote. For repositories
+	// retrieved into memory, this is the size of the objects once unpacked.
+	BytesReceived int64
+}
+
+// ProgressFunc is called each time progress is made retrieving a repository.
+// It may be called from multiple goroutines, but never concurrently for the
+// same repository.
+type ProgressFunc func(Progress)
+
+// progressReporter tracks the progress of a single repository retrieval and
+// reports it to a [ProgressFunc]. It is written to by go-git as the remote
+// sends progress messages and by [countingStorer] as objects are stored.
+type progressReporter struct {
+	mu       sync.Mutex
+	fn       ProgressFunc
+	progress Progress
+	// holds a partial message until its end (\r or \n) is written.
+	buf string
+}
+
+func newProgressReporter(fn ProgressFunc) *progressReporter {
+	return &progressReporter{fn: fn}
+}
+
+// Write parses the progress messages sent by the remote. Messages are
+// separated by carriage returns or newlines and may span multiple writes.
+func (p *progressReporter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf += string(b)
+	for {
+		i := strings.IndexAny(p.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		msg := strings.TrimSpace(p.buf[:i])
+		p.buf = p.buf[i+1:]
+		match := progressMessageRegex.FindStringSubmatch(msg)
+		if match == nil {
+			continue
+		}
+		p.progress.Stage = match[1]
+		p.progress.Objects, _ = strconv.Atoi(match[2])
+		p.progress.TotalObjects, _ = strconv.Atoi(match[3])
+		p.fn(p.progress)
+	}
+	return len(b), nil
+}
+
+// addBytes records n more bytes received from the remote.
+func (p *progressReporter) addBytes(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.BytesReceived += int64(n)
+	if p.progress.Stage == "" || strings.HasSuffix(p.buf, "done.") {
+		p.progress.Stage = ReceivingStage
+	}
+	p.fn(p.progress)
+}
+
+// countingStorer reports the size of each object stored while retrieving a
+// repository to a [progressReporter]. Counting at the storer, rather than the
+// transport, tracks the progress of each retrieval on its own whichever
+// protocol the remote is reached over.
+type countingStorer struct {
+	storage.Storer
+	reporter *progressReporter
+}
+
+func (s *countingStorer) SetEncodedObject(o plumbing.EncodedObject) (plumbing.Hash, error) {
+	h, err := s.Storer.SetEncodedObject(o)
+	if err == nil {
+		s.reporter.addBytes(int(o.Size()))
+	}
+	return h, err
+}
+
+// Init initializes the underlying storer, when it needs to be, so wrapping it
+// does not stop go-git from creating the repository's layout.
+func (s *countingStorer) Init() error {
+	if i, ok := s.Storer.(storer.Initializer); ok {
+		return i.Init()
+	}
+	return nil
+}
+
+// countingPackfileStorer is a [countingStorer] for storers that write the
+// packfile sent by the remote as is, such as the filesystem storer, in which
+// case the bytes of the packfile are counted instead of its objects.
+type countingPackfileStorer struct {
+	*countingStorer
+}
+
+func (s *countingPackfileStorer) PackfileWriter() (io.WriteCloser, error) {
+	w, err := s.Storer.(storer.PackfileWriter).PackfileWriter()
+	if err != nil {
+		return nil, err
+	}
+	return &countingWriter{WriteCloser: w, reporter: s.reporter}, nil
+}
+
+// countingWriter reports the number of bytes written to a packfile.
+type countingWriter struct {
+	io.WriteCloser
+	reporter *progressReporter
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	if n > 0 {
+		w.reporter.addBytes(n)
+	}
+	return n, err
+}
+
+// withProgress returns s wrapped to report the bytes it receives to progress
+// when progress is a [progressReporter]. Otherwise, s is returned as is.
+func withProgress(s storage.Storer, progress sideband.Progress) storage.Storer {
+	reporter, ok := progress.(*progressReporter)
+	if !ok {
+		return s
+	}
+	c := &countingStorer{Storer: s, reporter: reporter}
+	if _, ok := s.(storer.PackfileWriter); ok {
+		return &countingPackfileStorer{c}
+	}
+	return c
+}
+
+// cloneWithProgress clones the repository described by opts into s, reporting
+// the bytes received to opts.Progress. The returned repository is backed by s
+// itself, so nothing more is reported once the clone is done.
+func cloneWithProgress(ctx context.Context, s storage.Storer, opts *git.CloneOptions) (*git.Repository, error) {
+	if _, err := git.CloneContext(ctx, withProgress(s, opts.Progress), nil, opts); err != nil {
+		return nil, err
+	}
+	return git.Open(s, nil)
+}
@@ -0,0 +1,69 @@
+package source
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestProgressReporter(t *testing.T) {
+	updates := []Progress{}
+	reporter := newProgressReporter(func(p Progress) {
+		updates = append(updates, p)
+	})
+
+	// messages can be split across writes and separated by \r or \n
+	reporter.Write([]byte("Enumerating objects: 120, done.\nCounting objects:  45% (45/1"))
+	reporter.Write([]byte("00)\rCounting objects: 100% (100/100), done.\n"))
+	reporter.Write([]byte("Total 120 (delta 3), reused 0\n"))
+
+	if len(updates) != 3 {
+		t.Fatalf("fail: wrong number of progress updates, expected: %d, actual: %d: %+v", 3, len(updates), updates)
+	}
+	if updates[0].Stage != "Enumerating objects" || updates[0].Objects != 120 || updates[0].TotalObjects != 0 {
+		t.Logf("fail: first update was wrong: %+v", updates[0])
+		t.Fail()
+	}
+	if updates[1].Stage != "Counting objects" || updates[1].Objects != 45 || updates[1].TotalObjects != 100 {
+		t.Logf("fail: second update was wrong: %+v", updates[1])
+		t.Fail()
+	}
+
+	reporter.addBytes(512)
+	last := updates[len(updates)-1]
+	if last.BytesReceived != 512 {
+		t.Logf("fail: bytes received was wrong, expected: %d, actual: %d", 512, last.BytesReceived)
+		t.Fail()
+	}
+}
+
+func TestResolveRepoContext(t *testing.T) {
+	_, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	url := filepath.Join(getTestRepoDir(), "repo2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ResolveRepoContext(ctx, url, ResolveRepoOpts{InMemory: true}); err == nil {
+		t.Log("fail: expected error resolving a repo with a cancelled context")
+		t.Fail()
+	}
+
+	var received int64
+	_, err = ResolveRepoContext(context.Background(), url, ResolveRepoOpts{
+		InMemory: true,
+		Progress: func(p Progress) { received = p.BytesReceived },
+	})
+	if err != nil {
+		t.Fatalf("fail: unexpected error resolving repo with progress: %s", err)
+	}
+	// bytes are counted for remotes reached over any protocol, including
+	// local file paths.
+	if received == 0 {
+		t.Log("fail: expected bytes received to be reported")
+		t.Fail()
+	}
+}
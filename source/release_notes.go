@@ -0,0 +1,181 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Types of [conventional commits] recognized when generating release notes.
+// Commits that don't follow the convention are grouped as OtherCommitType.
+//
+// [conventional commits]: https://www.conventionalcommits.org
+const (
+	FeatCommitType     = "feat"
+	FixCommitType      = "fix"
+	PerfCommitType     = "perf"
+	RefactorCommitType = "refactor"
+	DocsCommitType     = "docs"
+	TestCommitType     = "test"
+	BuildCommitType    = "build"
+	CICommitType       = "ci"
+	ChoreCommitType    = "chore"
+	RevertCommitType   = "revert"
+	OtherCommitType    = "other"
+)
+
+// commitTypeTitles maps each commit type to the heading it is rendered under.
+// The order of commitTypeOrder determines the order sections are rendered in.
+var (
+	commitTypeOrder = []string{
+		FeatCommitType, FixCommitType, PerfCommitType, RefactorCommitType, DocsCommitType,
+		TestCommitType, BuildCommitType, CICommitType, ChoreCommitType, RevertCommitType, OtherCommitType,
+	}
+	commitTypeTitles = map[string]string{
+		FeatCommitType:     "Features",
+		FixCommitType:      "Bug Fixes",
+		PerfCommitType:     "Performance Improvements",
+		RefactorCommitType: "Refactors",
+		DocsCommitType:     "Documentation",
+		TestCommitType:     "Tests",
+		BuildCommitType:    "Build System",
+		CICommitType:       "Continuous Integration",
+		ChoreCommitType:    "Chores",
+		RevertCommitType:   "Reverts",
+		OtherCommitType:    "Other Changes",
+	}
+	// matches a conventional commit title such as feat(api)!: add endpoint
+	conventionalCommitRegex = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+)
+
+// ReleaseNotes contains the commits made between two tags, grouped by their
+// conventional commit type.
+type ReleaseNotes struct {
+	// The tag the notes start from (exclusive).
+	From string
+	// The tag the notes end at (inclusive).
+	To string
+	// Commits flagged as breaking changes, either with a "!" after the type
+	// or a BREAKING CHANGE footer. These commits are also listed in their
+	// type's section.
+	BreakingChanges []ReleaseNote
+	// The non-empty sections, ordered by significance (features first).
+	Sections []ReleaseNotesSection
+}
+
+// ReleaseNotesSection groups the notes of a single commit type.
+type ReleaseNotesSection struct {
+	Type  string
+	Title string
+	Notes []ReleaseNote
+}
+
+// ReleaseNote is a single entry in the release notes.
+type ReleaseNote struct {
+	// The scope of the change, if specified (e.g. api in feat(api): ...).
+	Scope string
+	// The description of the change, without the type and scope prefix.
+	Description string
+	Commit      Commit
+}
+
+// GetReleaseNotes returns the release notes for the commits reachable from
+// toTag that are not reachable from fromTag. An error is returned when either
+// tag cannot be resolved.
+func (gm *GitManager) GetReleaseNotes(r Repository, fromTag, toTag string) (*ReleaseNotes, error) {
+	commits, err := gm.GetCommitsBetween(r, fromTag, toTag)
+	if err != nil {
+		return nil, err
+	}
+
+	rn := NewReleaseNotes(fromTag, toTag, commits)
+	return &rn, nil
+}
+
+// NewReleaseNotes groups commits into [ReleaseNotes] based on their
+// conventional commit type. Merge commits are skipped as the commits they
+// merge are already included.
+func NewReleaseNotes(from, to string, commits []Commit) ReleaseNotes {
+	rn := ReleaseNotes{From: from, To: to}
+	byType := map[string][]ReleaseNote{}
+	for _, c := range commits {
+		title := c.Title
+		if title == "" {
+			title = strings.SplitN(string(c.Message), "\n", 2)[0]
+		}
+		if strings.HasPrefix(title, "Merge ") {
+			continue
+		}
+		commitType, note, breaking := parseConventionalCommit(title, string(c.Message))
+		note.Commit = c
+		byType[commitType] = append(byType[commitType], note)
+		if breaking {
+			rn.BreakingChanges = append(rn.BreakingChanges, note)
+		}
+	}
+
+	for _, t := range commitTypeOrder {
+		if len(byType[t]) == 0 {
+			continue
+		}
+		rn.Sections = append(rn.Sections, ReleaseNotesSection{
+			Type:  t,
+			Title: commitTypeTitles[t],
+			Notes: byType[t],
+		})
+	}
+	return rn
+}
+
+// Markdown renders the release notes as a Markdown document.
+func (rn ReleaseNotes) Markdown() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "## Changes from %s to %s\n", rn.From, rn.To)
+	if len(rn.BreakingChanges) > 0 {
+		buf.WriteString("\n### Breaking Changes\n\n")
+		for _, n := range rn.BreakingChanges {
+			writeMarkdownNote(&buf, n)
+		}
+	}
+	for _, s := range rn.Sections {
+		fmt.Fprintf(&buf, "\n### %s\n\n", s.Title)
+		for _, n := range s.Notes {
+			writeMarkdownNote(&buf, n)
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeMarkdownNote writes a single note as a Markdown list item, for
+// example:
+//
+//   - **api:** add endpoint (abc1234)
+func writeMarkdownNote(buf *bytes.Buffer, n ReleaseNote) {
+	buf.WriteString("- ")
+	if n.Scope != "" {
+		fmt.Fprintf(buf, "**%s:** ", n.Scope)
+	}
+	fmt.Fprintf(buf, "%s (%s)\n", n.Description, n.Commit.Hash.String()[:7])
+}
+
+// parseConventionalCommit returns the commit type of a commit's title along
+// with its note and whether it is a breaking change. Titles that don't follow
+// the conventional commit format, or use an unknown type, are returned as
+// OtherCommitType with the full title as the description.
+func parseConventionalCommit(title string, message string) (string, ReleaseNote, bool) {
+	breaking := strings.Contains(message, "BREAKING CHANGE:") || strings.Contains(message, "BREAKING-CHANGE:")
+	match := conventionalCommitRegex.FindStringSubmatch(title)
+	if match == nil {
+		return OtherCommitType, ReleaseNote{Description: title}, breaking
+	}
+	commitType := strings.ToLower(match[1])
+	if _, ok := commitTypeTitles[commitType]; !ok {
+		return OtherCommitType, ReleaseNote{Description: title}, breaking
+	}
+	note := ReleaseNote{
+		Scope:       match[2],
+		Description: match[4],
+	}
+	return commitType, note, breaking || match[3] == "!"
+}
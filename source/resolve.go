@@ -0,0 +1,84 @@
+package source
+
+import (
+	"context"
+	"sync"
+)
+
+// The number of repositories resolved at once by [ResolveRepos] when not
+// specified.
+const DefaultResolveConcurrency = 4
+
+// ResolveReposOpts provides instructions for how many repositories should be
+// retrieved.
+type ResolveReposOpts struct {
+	// The options used to retrieve each repository.
+	ResolveRepoOpts
+	// The maximum number of repositories retrieved at once. Defaults to
+	// [DefaultResolveConcurrency].
+	Concurrency int
+}
+
+// RepoResult is the outcome of resolving a single repository with
+// [ResolveRepos]. Exactly one of Repo or Err is set.
+type RepoResult struct {
+	URL  string
+	Repo *Repository
+	Err  error
+}
+
+// ResolveRepos resolves every repository in urls, as done by [ResolveRepo],
+// retrieving up to opts.Concurrency repositories at once. A result is returned
+// for every url, in the same order as urls, so a failure to retrieve one
+// repository does not prevent others from being returned. Duplicate urls are
+// only retrieved once.
+//
+// When ctx is cancelled, retrievals in progress are stopped and every
+// repository not yet retrieved is returned with an error.
+func ResolveRepos(ctx context.Context, urls []string, opts ...ResolveReposOpts) []RepoResult {
+	conf := ResolveReposOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if conf.Concurrency < 1 {
+		conf.Concurrency = DefaultResolveConcurrency
+	}
+
+	unique := map[string]*RepoResult{}
+	for _, u := range urls {
+		if _, ok := unique[u]; !ok {
+			unique[u] = &RepoResult{URL: u}
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, conf.Concurrency)
+	for _, result := range unique {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			result.Err = ctx.Err()
+			continue
+		}
+		// check for cancellation again as select picks randomly when both
+		// cases are ready.
+		if ctx.Err() != nil {
+			result.Err = ctx.Err()
+			<-sem
+			continue
+		}
+		wg.Add(1)
+		go func(result *RepoResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result.Repo, result.Err = ResolveRepoContext(ctx, result.URL, conf.ResolveRepoOpts)
+		}(result)
+	}
+	wg.Wait()
+
+	results := make([]RepoResult, len(urls))
+	for i, u := range urls {
+		results[i] = *unique[u]
+	}
+	return results
+}
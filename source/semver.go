@@ -0,0 +1,211 @@
+package source
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Semver represents a tag parsed as a [semantic version].
+//
+// [semantic version]: https://semver.org
+type Semver struct {
+	Major int
+	Minor int
+	Patch int
+	// The pre-release identifiers, without the leading hyphen (e.g. rc.1).
+	Prerelease string
+	// The build metadata, without the leading plus sign. Build metadata is
+	// ignored when comparing versions.
+	Build string
+}
+
+// ParseSemver parses a tag name as a semantic version. Since tags often don't
+// strictly follow the specification, parsing is lenient: a leading "v" is
+// allowed and the minor and patch versions may be omitted (e.g. v1 or 1.2).
+// An error is returned when tag is not a version.
+func ParseSemver(tag string) (Semver, error) {
+	v := Semver{}
+	rest := strings.TrimPrefix(tag, "v")
+	if i := strings.Index(rest, "+"); i >= 0 {
+		v.Build = rest[i+1:]
+		rest = rest[:i]
+	}
+	if i := strings.Index(rest, "-"); i >= 0 {
+		v.Prerelease = rest[i+1:]
+		rest = rest[:i]
+		if v.Prerelease == "" {
+			return Semver{}, fmt.Errorf("tag (%s) has an empty pre-release", tag)
+		}
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return Semver{}, fmt.Errorf("tag (%s) has more than 3 version numbers", tag)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Semver{}, fmt.Errorf("tag (%s) is not a semantic version", tag)
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+// IsPrerelease returns whether the version is a pre-release (e.g. 1.0.0-rc.1).
+func (v Semver) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+// Compare returns -1 when v is lower than o, 1 when v is higher than o, and 0
+// when they have the same precedence. Precedence follows the semantic
+// versioning specification, meaning a pre-release is lower than its
+// associated release and build metadata is ignored.
+func (v Semver) Compare(o Semver) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func (v Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// SortTagsBySemver returns the tags that are semantic versions, sorted from
+// lowest to highest version. Tags that cannot be parsed by [ParseSemver] are
+// not included.
+func SortTagsBySemver(tags []Tag) []Tag {
+	type versionedTag struct {
+		tag     Tag
+		version Semver
+	}
+	versioned := []versionedTag{}
+	for _, t := range tags {
+		v, err := ParseSemver(t.Name)
+		if err != nil {
+			continue
+		}
+		versioned = append(versioned, versionedTag{t, v})
+	}
+	sort.SliceStable(versioned, func(i, j int) bool {
+		return versioned[i].version.Compare(versioned[j].version) < 0
+	})
+
+	sorted := make([]Tag, len(versioned))
+	for i, vt := range versioned {
+		sorted[i] = vt.tag
+	}
+	return sorted
+}
+
+// GetLatestStableTag returns the tag with the highest semantic version that
+// is not a pre-release. An error is returned when no such tag exists.
+func GetLatestStableTag(tags []Tag) (Tag, error) {
+	sorted := SortTagsBySemver(tags)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		v, _ := ParseSemver(sorted[i].Name)
+		if !v.IsPrerelease() {
+			return sorted[i], nil
+		}
+	}
+	return Tag{}, fmt.Errorf("no stable semantic version tags found")
+}
+
+// GetPreviousTag returns the tag that precedes tagName by semantic version.
+// When tagName is a stable release, the previous stable release is returned,
+// meaning pre-releases in between are skipped. When tagName is a pre-release,
+// the immediately preceding version is returned. An error is returned when
+// tagName is not a semantic version tag in tags or when it is the lowest
+// version.
+func GetPreviousTag(tags []Tag, tagName string) (Tag, error) {
+	current, err := ParseSemver(tagName)
+	if err != nil {
+		return Tag{}, err
+	}
+	sorted := SortTagsBySemver(tags)
+	idx := -1
+	for i, t := range sorted {
+		if t.Name == tagName {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return Tag{}, fmt.Errorf("tag (%s) not found", tagName)
+	}
+	for i := idx - 1; i >= 0; i-- {
+		v, _ := ParseSemver(sorted[i].Name)
+		if v.Compare(current) == 0 {
+			continue
+		}
+		if !current.IsPrerelease() && v.IsPrerelease() {
+			continue
+		}
+		return sorted[i], nil
+	}
+	return Tag{}, fmt.Errorf("no tag precedes (%s)", tagName)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease compares pre-release identifiers as described in the
+// semantic versioning specification. An empty pre-release (a release) has
+// higher precedence than any pre-release.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInt(aNum, bNum); c != 0 {
+				return c
+			}
+		// numeric identifiers always have lower precedence than alphanumeric
+		// identifiers.
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aIDs[i], bIDs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(aIDs), len(bIDs))
+}
@@ -0,0 +1,92 @@
+package source
+
+import (
+	"testing"
+)
+
+func TestParseSemver(t *testing.T) {
+	v, err := ParseSemver("v1.22.3-rc.1+build.5")
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing version: %s", err)
+	}
+	expected := Semver{Major: 1, Minor: 22, Patch: 3, Prerelease: "rc.1", Build: "build.5"}
+	if v != expected {
+		t.Logf("fail: parsed version was wrong, expected: %+v, actual: %+v", expected, v)
+		t.Fail()
+	}
+
+	v, err = ParseSemver("2.1")
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing version: %s", err)
+	}
+	if v.String() != "2.1.0" {
+		t.Logf("fail: parsed version was wrong, expected: %s, actual: %s", "2.1.0", v)
+		t.Fail()
+	}
+
+	for _, bad := range []string{"latest", "v1.2.3.4", "v1.x", "1.0.0-"} {
+		if _, err := ParseSemver(bad); err == nil {
+			t.Logf("fail: expected error parsing %s, but did not receive one", bad)
+			t.Fail()
+		}
+	}
+}
+
+func TestSortTagsBySemver(t *testing.T) {
+	tags := newTagsFromNames("v1.10.0", "v1.2.0", "nightly", "v1.10.0-rc.2", "v1.10.0-rc.10", "v1.10.0-beta", "v1.9.1")
+	sorted := SortTagsBySemver(tags)
+	expected := []string{"v1.2.0", "v1.9.1", "v1.10.0-beta", "v1.10.0-rc.2", "v1.10.0-rc.10", "v1.10.0"}
+	if len(sorted) != len(expected) {
+		t.Fatalf("fail: sorted tags had wrong length, expected: %d, actual: %d", len(expected), len(sorted))
+	}
+	for i := range expected {
+		if sorted[i].Name != expected[i] {
+			t.Logf("fail: sorted tag %d was wrong, expected: %s, actual: %s", i, expected[i], sorted[i].Name)
+			t.Fail()
+		}
+	}
+
+	latest, err := GetLatestStableTag(newTagsFromNames("v1.2.0", "v1.3.0-rc.1", "v1.1.0"))
+	if err != nil {
+		t.Fatalf("fail: unexpected error resolving latest tag: %s", err)
+	}
+	if latest.Name != "v1.2.0" {
+		t.Logf("fail: latest stable tag was wrong, expected: %s, actual: %s", "v1.2.0", latest.Name)
+		t.Fail()
+	}
+}
+
+func TestGetPreviousTag(t *testing.T) {
+	tags := newTagsFromNames("v1.0.0", "v1.1.0-rc.1", "v1.1.0", "v1.1.1", "v1.2.0-rc.1", "v1.2.0-rc.2")
+	cases := map[string]string{
+		"v1.1.0":      "v1.0.0",
+		"v1.1.1":      "v1.1.0",
+		"v1.2.0-rc.2": "v1.2.0-rc.1",
+		"v1.2.0-rc.1": "v1.1.1",
+	}
+	for tag, expected := range cases {
+		prev, err := GetPreviousTag(tags, tag)
+		if err != nil {
+			t.Logf("fail: unexpected error resolving previous tag of %s: %s", tag, err)
+			t.Fail()
+			continue
+		}
+		if prev.Name != expected {
+			t.Logf("fail: previous tag of %s was wrong, expected: %s, actual: %s", tag, expected, prev.Name)
+			t.Fail()
+		}
+	}
+
+	if _, err := GetPreviousTag(tags, "v1.0.0"); err == nil {
+		t.Log("fail: expected error resolving the previous tag of the lowest version")
+		t.Fail()
+	}
+}
+
+func newTagsFromNames(names ...string) []Tag {
+	tags := []Tag{}
+	for _, n := range names {
+		tags = append(tags, Tag{Name: n})
+	}
+	return tags
+}
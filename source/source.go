@@ -3,30 +3,85 @@
 package source
 
 import (
-	"encoding/base64"
+	"context"
 	"encoding/hex"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/adrg/xdg"
+	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 const (
 	CacheDirName     = "proctor"
 	CacheRepoDirName = "repos"
+	// The prefix of branches fetched from the origin remote.
+	remoteBranchPrefix = "refs/remotes/origin/"
 )
 
 // ResolveRepoOpts provides instructions for how a repository should be retrieved.
 type ResolveRepoOpts struct {
 	// instructs doing all retrieval in memory. Note that for medium to large
-	// size repos, this can cause significant memory consumption.
+	// size repos, this can cause significant memory consumption, so
+	// repositories expected to exceed MemoryLimit are instead stored in a
+	// temporary directory. Call [Repository.Close] once done with the
+	// repository to remove it.
 	InMemory bool
+	// The maximum size, in bytes, of a repository held in memory when
+	// InMemory is set. Defaults to [DefaultMemoryLimit]; a negative value
+	// removes the limit. When SizeHint exceeds the limit, the repository is
+	// cloned into a temporary directory. Otherwise, once the objects of the
+	// in-memory clone exceed MemoryLimit bytes, they are moved to a temporary
+	// directory, where the clone continues.
+	MemoryLimit int64
+	// The expected size of the repository in bytes, such as the size reported
+	// by its hosting platform's API. Used to choose where an InMemory
+	// repository is stored before cloning it; 0 when unknown.
+	SizeHint int64
+	// Limits the clone, and subsequent fetches, to this many commits from the
+	// tip of each branch. A value of 0 retrieves the full history. Commits
+	// retrieved from a shallow repository end at the depth boundary.
+	//
+	// Partial clones, such as git clone --filter=blob:none, are not supported:
+	// go-git can't clone without blobs, and fetches every object reachable
+	// within Depth. Depth and SingleBranch are the only means of reducing what
+	// is transferred.
+	Depth int
+	// Only clone the branch specified by Branch or, when Branch is empty, the
+	// remote's default branch. Tags pointing at commits outside the branch are
+	// not retrieved.
+	SingleBranch bool
+	// The branch to clone. Defaults to the remote's default branch. Only
+	// applies to new clones.
+	Branch string
+	// The credentials used to clone and fetch the repository. By default, the
+	// repository is accessed anonymously.
+	Auth RepoAuth
+	// Pins the returned repository to a branch, tag, or commit hash, which is
+	// then used in place of HEAD. An error is returned when the ref cannot be
+	// found, which may occur when combined with Depth or SingleBranch.
+	Ref string
+	// The maximum amount of time retrieving the repository may take. By
+	// default, there is no limit beyond the context passed to
+	// [ResolveRepoContext].
+	Timeout time.Duration
+	// Called as the clone or fetch progresses, which is useful for reporting
+	// progress of large repositories.
+	Progress ProgressFunc
 }
 
 // Tag represents a git tag.
@@ -40,6 +95,15 @@ type Tag struct {
 	AssociatedCommits []Commit
 }
 
+// Branch represents a git branch.
+type Branch struct {
+	// The short name of the branch (e.g. main), without the refs/heads/ or
+	// remote prefix.
+	Name string
+	// The commit the branch currently points to.
+	LastCommit Hash
+}
+
 type Hash [20]byte
 
 type Person struct {
@@ -54,6 +118,9 @@ type Commit struct {
 	Committer Person
 	Author    Person
 	Message   []byte
+	// The commits this commit was made on top of. Merge commits have more
+	// than one parent.
+	Parents []Hash
 }
 
 // GitManager operates on [git] repositories in order to facilitate the
@@ -87,11 +154,41 @@ type CommitReader interface {
 type Repository struct {
 	URL     string
 	RepoRef *git.Repository
+	// The commit the repository is pinned to, set when resolved with
+	// ResolveRepoOpts.Ref. When set, it is used in place of HEAD when
+	// retrieving commits and reading files.
+	PinnedCommit Hash
+	// The temporary directory the repository is stored in, when an in-memory
+	// repository exceeded ResolveRepoOpts.MemoryLimit.
+	tempDir string
 }
 
 // GetCommitsOpts enables putting constraints on the commit data you'd like to
-// retrieve.
+// retrieve. Every field is optional; when left to its zero value, the
+// constraint is not applied.
 type GetCommitsOpts struct {
+	// The name of the branch to retrieve commits from, such as main. By
+	// default, commits are retrieved from HEAD. Remote branches (e.g. those
+	// only fetched into refs/remotes/origin by a clone) are also resolved.
+	// This option is ignored when retrieving commits for a tag.
+	Branch string
+	// Only include commits committed at or after this time.
+	Since time.Time
+	// Only include commits committed at or before this time.
+	Until time.Time
+	// Only include commits authored by this email address. The comparison is
+	// case-insensitive.
+	AuthorEmail string
+	// Only include commits that modified this file or, if it is a directory,
+	// any file within it. The path is relative to the root of the repository.
+	Path string
+	// The maximum number of commits to return. Once reached, the history is no
+	// longer walked, which avoids reading the full history of large
+	// repositories.
+	MaxCount int
+	// Only include commits whose message matches this pattern. Set by
+	// [GitManager.SearchCommits].
+	messagePattern *regexp.Regexp
 }
 
 // NewGitManager returns and instance of a [GitManager] based on the specified
@@ -103,7 +200,25 @@ type GetCommitsOpts struct {
 // If more than one is passed, the last config in the argument's slice will be
 // used.
 func NewGitManager(config ...GitManagerConfig) GitManager {
-	return GitManager{}
+	conf := GitManagerConfig{}
+	if len(config) > 0 {
+		conf = config[len(config)-1]
+	}
+	return GitManager{conf}
+}
+
+// ResolveRepo is the equivalent of the package-level [ResolveRepo], except
+// that when opts doesn't specify credentials, the GitManager's AccessToken is
+// used to authenticate over HTTPS.
+func (gm *GitManager) ResolveRepo(url string, opts ...ResolveRepoOpts) (*Repository, error) {
+	conf := ResolveRepoOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if conf.Auth.IsZero() {
+		conf.Auth.Token = gm.AccessToken
+	}
+	return ResolveRepo(url, conf)
 }
 
 // GetCommits takes a [Repository], which should be generated using
@@ -119,32 +234,51 @@ func (gm *GitManager) GetCommits(r Repository, opts ...GetCommitsOpts) ([]Commit
 	if r.RepoRef == nil {
 		return nil, fmt.Errorf("failed to find reference to valid repo when looking up commits.")
 	}
-	commits := []Commit{}
-	commitObjs, err := r.RepoRef.Log(&git.LogOptions{Order: git.LogOrderCommitterTime})
+	conf := resolveCommitsOpts(opts)
+	from := plumbing.Hash(r.PinnedCommit)
+	if conf.Branch != "" {
+		branchHash, err := resolveBranch(r, conf.Branch)
+		if err != nil {
+			return nil, err
+		}
+		from = branchHash
+	}
+	shallow := isShallow(r)
+	commitObjs, err := r.RepoRef.Log(newLogOptions(from, conf, shallow))
 	if err != nil {
 		return nil, fmt.Errorf("failed getting all commits from repo. Error from git: %s", err)
 	}
 
-	// add each commit object to commits for return
-	commitObjs.ForEach(func(obj *object.Commit) error {
-		commit := Commit{
-			Hash: Hash(obj.Hash),
-			Date: obj.Committer.When,
-			Committer: Person{
-				Name:  obj.Committer.Name,
-				Email: obj.Committer.Email,
-			},
-			Author: Person{
-				Name:  obj.Author.Name,
-				Email: obj.Author.Email,
-			},
-			Message: []byte(obj.Message),
-		}
-		commits = append(commits, commit)
-		return nil
-	})
+	return collectCommits(commitObjs, conf, shallow)
+}
 
-	return commits, nil
+// GetCommit returns the commit identified by hash. An error is returned when
+// the commit does not exist in the repository.
+func (gm *GitManager) GetCommit(r Repository, hash Hash) (Commit, error) {
+	if r.RepoRef == nil {
+		return Commit{}, fmt.Errorf("failed to find reference to valid repo when looking up commit.")
+	}
+	obj, err := r.RepoRef.CommitObject(plumbing.Hash(hash))
+	if err != nil {
+		return Commit{}, fmt.Errorf("failed retrieving commit (%s): %s", hash, err)
+	}
+	return newCommit(obj), nil
+}
+
+// SearchCommits returns the commits whose message matches pattern, a regular
+// expression as accepted by [regexp.Compile], such as `CVE-\d{4}-\d+`. To
+// match case-insensitively, prefix the pattern with (?i). The optional opts
+// argument constrains the commits searched the same as [GitManager.GetCommits];
+// MaxCount limits the number of matching commits returned. An error is
+// returned when pattern is invalid or commits cannot be retrieved.
+func (gm *GitManager) SearchCommits(r Repository, pattern string, opts ...GetCommitsOpts) ([]Commit, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern (%s): %s", pattern, err)
+	}
+	conf := resolveCommitsOpts(opts)
+	conf.messagePattern = re
+	return gm.GetCommits(r, conf)
 }
 
 // GetCommitsForTag takes a tagName and its associated repository and returns a
@@ -178,28 +312,46 @@ func (gm *GitManager) GetCommitsForTag(tagName string, r Repository, opts ...Get
 		return nil, fmt.Errorf("no lastcommit hash was specified with tag.")
 	}
 
-	commits, err := r.RepoRef.Log(&git.LogOptions{
-		From:  plumbing.Hash(tag.LastCommit),
-		Order: git.LogOrderCommitterTime,
-	})
+	conf := resolveCommitsOpts(opts)
+	shallow := isShallow(r)
+	commits, err := r.RepoRef.Log(newLogOptions(plumbing.Hash(tag.LastCommit), conf, shallow))
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve commits from tag \"%s\". Error from go-git was: %s", tag.Name, err)
 	}
-	CollectedCommits := []Commit{}
-	commits.ForEach(func(o *object.Commit) error {
-		CollectedCommits = append(CollectedCommits, Commit{
-			Hash: Hash(o.Hash),
-			Date: o.Committer.When,
-			Author: Person{
-				Name:  o.Author.Name,
-				Email: o.Author.Email,
-			},
-			Message: []byte(o.Message),
-		})
-		return nil
-	})
 
-	return CollectedCommits, nil
+	return collectCommits(commits, conf, shallow)
+}
+
+// GetCommitsBetween returns the commits reachable from toTag that are not
+// reachable from fromTag, which are the commits introduced by the release
+// toTag when fromTag is the release preceding it. When fromTag is empty, every
+// commit reachable from toTag is returned. Commits are ordered the same as
+// [GitManager.GetCommitsForTag]. An error is returned when either tag cannot
+// be resolved.
+func (gm *GitManager) GetCommitsBetween(r Repository, fromTag, toTag string) ([]Commit, error) {
+	toCommits, err := gm.GetCommitsForTag(toTag, r)
+	if err != nil {
+		return nil, err
+	}
+	if fromTag == "" {
+		return toCommits, nil
+	}
+	fromCommits, err := gm.GetCommitsForTag(fromTag, r)
+	if err != nil {
+		return nil, err
+	}
+
+	inFrom := map[Hash]bool{}
+	for _, c := range fromCommits {
+		inFrom[c.Hash] = true
+	}
+	commits := []Commit{}
+	for _, c := range toCommits {
+		if !inFrom[c.Hash] {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
 }
 
 // GetTagsFromRepository accepts a repository returns all tags that are
@@ -228,6 +380,7 @@ func (gm *GitManager) GetTagsFromRepository(r Repository) ([]Tag, error) {
 
 		CollectedTags = append(CollectedTags, Tag{
 			Name:       o.Name().Short(),
+			Date:       tagRef.Tagger.When,
 			LastCommit: Hash(commitRef.Hash),
 		})
 		return nil
@@ -236,6 +389,241 @@ func (gm *GitManager) GetTagsFromRepository(r Repository) ([]Tag, error) {
 	return CollectedTags, nil
 }
 
+// resolveCommitsOpts returns the last opts argument or, when none was passed,
+// an empty GetCommitsOpts.
+func resolveCommitsOpts(opts []GetCommitsOpts) GetCommitsOpts {
+	if len(opts) > 0 {
+		return opts[len(opts)-1]
+	}
+	return GetCommitsOpts{}
+}
+
+// newLogOptions translates opts into the equivalent go-git log options. The
+// log starts at from; when from is the zero hash, it starts at HEAD. Filters
+// go-git is unable to apply (e.g. author) are applied in [collectCommits].
+//
+// Ordering by committer time loads a commit's parents before returning it,
+// which fails at the boundary of a shallow repository. When shallow is true,
+// the history is walked depth-first instead and [collectCommits] orders the
+// result.
+func newLogOptions(from plumbing.Hash, opts GetCommitsOpts, shallow bool) *git.LogOptions {
+	logOpts := &git.LogOptions{
+		From:  from,
+		Order: git.LogOrderCommitterTime,
+	}
+	if shallow {
+		logOpts.Order = git.LogOrderDFS
+	}
+	if !opts.Since.IsZero() {
+		logOpts.Since = &opts.Since
+	}
+	if !opts.Until.IsZero() {
+		logOpts.Until = &opts.Until
+	}
+	if opts.Path != "" {
+		dir := strings.TrimSuffix(opts.Path, "/")
+		logOpts.PathFilter = func(p string) bool {
+			return p == dir || strings.HasPrefix(p, dir+"/")
+		}
+	}
+	return logOpts
+}
+
+// collectCommits walks every commit object in iter and returns them as a
+// slice of [Commit], applying the filters in opts that aren't supported by
+// go-git's log. Walking stops once opts.MaxCount commits are collected. When
+// shallow is true, parents missing beyond the shallow boundary end the walk
+// rather than causing an error, and the newest opts.MaxCount commits are kept
+// once the whole history is walked.
+func collectCommits(iter object.CommitIter, opts GetCommitsOpts, shallow bool) ([]Commit, error) {
+	commits := []Commit{}
+	err := iter.ForEach(func(obj *object.Commit) error {
+		if opts.AuthorEmail != "" && !strings.EqualFold(obj.Author.Email, opts.AuthorEmail) {
+			return nil
+		}
+		if opts.messagePattern != nil && !opts.messagePattern.MatchString(obj.Message) {
+			return nil
+		}
+		commits = append(commits, newCommit(obj))
+		// a depth-first walk doesn't visit the newest commits first, so a
+		// shallow history, which is short, is walked in full and limited once
+		// ordered.
+		if !shallow && opts.MaxCount > 0 && len(commits) >= opts.MaxCount {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		if !shallow || err != plumbing.ErrObjectNotFound {
+			return nil, fmt.Errorf("failed walking commits. Error from go-git: %s", err)
+		}
+	}
+	if shallow {
+		sort.SliceStable(commits, func(i, j int) bool {
+			return commits[i].Date.After(commits[j].Date)
+		})
+		if opts.MaxCount > 0 && len(commits) > opts.MaxCount {
+			commits = commits[:opts.MaxCount]
+		}
+	}
+	return commits, nil
+}
+
+// isShallow returns whether r was retrieved with a limited depth, meaning
+// part of its history is missing.
+func isShallow(r Repository) bool {
+	shallows, err := r.RepoRef.Storer.Shallow()
+	return err == nil && len(shallows) > 0
+}
+
+// newCommit converts a go-git commit object into a [Commit].
+func newCommit(obj *object.Commit) Commit {
+	parents := make([]Hash, len(obj.ParentHashes))
+	for i, p := range obj.ParentHashes {
+		parents[i] = Hash(p)
+	}
+	return Commit{
+		Hash:  Hash(obj.Hash),
+		Title: strings.SplitN(obj.Message, "\n", 2)[0],
+		Date:  obj.Committer.When,
+		Committer: Person{
+			Name:  obj.Committer.Name,
+			Email: obj.Committer.Email,
+		},
+		Author: Person{
+			Name:  obj.Author.Name,
+			Email: obj.Author.Email,
+		},
+		Message: []byte(obj.Message),
+		Parents: parents,
+	}
+}
+
+// GetBranches accepts a repository and returns all branches in it. This
+// includes local branches and branches of the origin remote, which is where
+// a clone stores every branch other than the default one. When a branch exists
+// both locally and on the remote, the local branch is returned.
+func (gm *GitManager) GetBranches(r Repository) ([]Branch, error) {
+	if r.RepoRef == nil {
+		return nil, fmt.Errorf("request to retrieve branches was requested but their was no repo associated with the passed argument")
+	}
+	refs, err := r.RepoRef.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve references for repository %s. Error from go-git: %s", r.URL, err)
+	}
+
+	local := map[string]Hash{}
+	remote := map[string]Hash{}
+	refs.ForEach(func(ref *plumbing.Reference) error {
+		// symbolic references (e.g. refs/remotes/origin/HEAD) point to another
+		// branch, which is already included.
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		switch {
+		case ref.Name().IsBranch():
+			local[ref.Name().Short()] = Hash(ref.Hash())
+		case ref.Name().IsRemote():
+			name := strings.TrimPrefix(ref.Name().String(), remoteBranchPrefix)
+			if name != ref.Name().String() {
+				remote[name] = Hash(ref.Hash())
+			}
+		}
+		return nil
+	})
+
+	branches := []Branch{}
+	for name, hash := range local {
+		branches = append(branches, Branch{Name: name, LastCommit: hash})
+	}
+	for name, hash := range remote {
+		if _, ok := local[name]; ok {
+			continue
+		}
+		branches = append(branches, Branch{Name: name, LastCommit: hash})
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
+
+// resolveBranch returns the commit hash a branch named name points to. Local
+// branches are preferred over the origin remote's branches. An error is
+// returned when no branch named name exists.
+func resolveBranch(r Repository, name string) (plumbing.Hash, error) {
+	candidates := []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(name),
+		plumbing.ReferenceName(remoteBranchPrefix + name),
+	}
+	for _, c := range candidates {
+		ref, err := r.RepoRef.Reference(c, true)
+		if err == nil {
+			return ref.Hash(), nil
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf("branch (%s) not found in repo (%s)", name, r.URL)
+}
+
+// ReadFileAtRef returns the contents of the file at path, relative to the root
+// of the repository, as of ref. ref can be a tag, branch, or commit hash; when
+// empty, the repository's PinnedCommit or, if unset, HEAD is used. An error
+// is returned when the ref or file cannot be found.
+func ReadFileAtRef(r Repository, ref string, path string) ([]byte, error) {
+	tree, err := treeAtRef(r, ref)
+	if err != nil {
+		return nil, err
+	}
+	f, err := tree.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed finding file (%s) at ref (%s): %s", path, ref, err)
+	}
+	content, err := f.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading file (%s) at ref (%s): %s", path, ref, err)
+	}
+	return []byte(content), nil
+}
+
+// resolveRef returns the commit ref points to. ref can be a tag, branch
+// (including those only fetched from the origin remote), or commit hash; when
+// empty, the repository's PinnedCommit or, if unset, HEAD is used.
+func resolveRef(r Repository, ref string) (plumbing.Hash, error) {
+	if r.RepoRef == nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to find reference to valid repo when resolving ref.")
+	}
+	if ref == "" {
+		if r.PinnedCommit != (Hash{}) {
+			return plumbing.Hash(r.PinnedCommit), nil
+		}
+		ref = string(plumbing.HEAD)
+	}
+	hash, err := r.RepoRef.ResolveRevision(plumbing.Revision(ref))
+	if err == nil {
+		return *hash, nil
+	}
+	if branchHash, branchErr := resolveBranch(r, ref); branchErr == nil {
+		return branchHash, nil
+	}
+	return plumbing.ZeroHash, fmt.Errorf("failed resolving ref (%s): %s", ref, err)
+}
+
+// treeAtRef returns the tree of the commit ref points to, as resolved by
+// [resolveRef].
+func treeAtRef(r Repository, ref string) (*object.Tree, error) {
+	hash, err := resolveRef(r, ref)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := r.RepoRef.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving commit (%s) of ref (%s): %s", hash, ref, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving tree of ref (%s): %s", ref, err)
+	}
+	return tree, nil
+}
+
 // NewMapOfTags returns a map representation of a list of tags where the key is
 // set to the tag name.
 func NewMapOfTags(t []Tag) map[string]Tag {
@@ -252,40 +640,94 @@ func NewMapOfTags(t []Tag) map[string]Tag {
 // is, it will do a git fetch to grab any new changes and return a reference to
 // the repository. If the repo does not exist on the filesystem (cache), it
 // will perform a clone that persists it to [getDefaultCacheLocation]. The
-// directory name within the cache will be a base64 encoded representation of
-// the url.
+// directory within the cache is named after the url's host, organization, and
+// repository, see [getCacheName].
 //
 // If you wish to get a repository reference for a repo held entirely in
 // memeory, you can set InMemory to true within the [ResolveRepoOpts] argument.
 // Note that doing an in-memory clone can consume substatial system resouces
 // (heap space) when the repository is large.
 func ResolveRepo(url string, opts ...ResolveRepoOpts) (*Repository, error) {
+	return ResolveRepoContext(context.Background(), url, opts...)
+}
+
+// ResolveRepoContext is the equivalent of [ResolveRepo], except that
+// retrieving the repository is stopped when ctx is done or, if set,
+// opts.Timeout elapses. A partially cloned repository is not kept in the
+// cache.
+func ResolveRepoContext(ctx context.Context, url string, opts ...ResolveRepoOpts) (*Repository, error) {
 	conf := ResolveRepoOpts{}
 	if len(opts) > 0 {
 		conf = opts[len(opts)-1]
 	}
+	if conf.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, conf.Timeout)
+		defer cancel()
+	}
+	repo, err := retrieveRepo(ctx, url, conf)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out retrieving repository (%s): %s", url, err)
+		}
+		return nil, err
+	}
+	if conf.Ref != "" {
+		hash, err := resolveRef(*repo, conf.Ref)
+		if err != nil {
+			return nil, err
+		}
+		repo.PinnedCommit = Hash(hash)
+	}
+	return repo, nil
+}
+
+// retrieveRepo clones the repository at url or, when it is already cached,
+// fetches its latest changes. See [ResolveRepo] for details.
+func retrieveRepo(ctx context.Context, url string, conf ResolveRepoOpts) (*Repository, error) {
+	auth, err := newAuthMethod(url, conf.Auth)
+	if err != nil {
+		return nil, err
+	}
+	cloneOpts := newCloneOptions(url, conf, auth)
+	fetchOpts := &git.FetchOptions{
+		RemoteURL: url,
+		Depth:     conf.Depth,
+		Auth:      auth,
+	}
+	if conf.Progress != nil {
+		reporter := newProgressReporter(conf.Progress)
+		cloneOpts.Progress = reporter
+		fetchOpts.Progress = reporter
+	}
 	if conf.InMemory {
-		return newInMemRepo(url)
+		slog.Debug("cloning repository in memory", "url", url, "depth", conf.Depth)
+		return newSpillingRepo(ctx, url, conf, cloneOpts)
 	}
 	// Check for existence of repo in filesystem, if it doesn't exist, clone it;
 	// if it does, open and return a ref.
-	fp := filepath.Join(getDefaultCacheLocation(), getEncodedCacheName(url))
+	fp := resolveCachePath(getDefaultCacheLocation(), url)
 	if _, err := os.Stat(fp); err != nil {
-		return newFSRepo(url)
+		slog.Debug("cloning repository", "url", url, "path", fp, "depth", conf.Depth)
+		return newFSRepo(ctx, url, cloneOpts)
 	}
+	slog.Debug("fetching cached repository", "url", url, "path", fp)
 
-	ref, err := git.PlainOpen(fp)
+	fsStore := newFSStorage(fp)
+	fetchRef, err := git.Open(withProgress(fsStore, fetchOpts.Progress), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed opening repo in cache: %s", err)
 	}
-	err = ref.Fetch(&git.FetchOptions{
-		RemoteURL: url,
-	})
+	err = fetchRef.FetchContext(ctx, fetchOpts)
 	if err != nil {
 		if err != git.NoErrAlreadyUpToDate {
 			return nil, fmt.Errorf("failed checking if repo was up to date: %s", err)
 		}
 	}
+	ref, err := git.Open(fsStore, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening repo in cache: %s", err)
+	}
 	repo := &Repository{
 		URL:     url,
 		RepoRef: ref,
@@ -296,17 +738,17 @@ func ResolveRepo(url string, opts ...ResolveRepoOpts) (*Repository, error) {
 // newFSRepo attempts to clone the repository to the filesystem and return a
 // reference. If the repo already exists or there is an issue retrieving it
 // over the network, an error is returned.
-func newFSRepo(url string) (*Repository, error) {
+func newFSRepo(ctx context.Context, url string, cloneOpts *git.CloneOptions) (*Repository, error) {
 	err := ensureCacheDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed ensuring cache location exists or creating it: %s", err)
 	}
-	fp := filepath.Join(getDefaultCacheLocation(), getEncodedCacheName(url))
-	ref, err := git.PlainClone(fp, true, &git.CloneOptions{
-		URL:        url,
-		NoCheckout: true,
-	})
+	fp := filepath.Join(getDefaultCacheLocation(), getCacheName(url))
+	ref, err := cloneWithProgress(ctx, newFSStorage(fp), cloneOpts)
 	if err != nil {
+		// remove what was partially cloned, otherwise it would be opened as
+		// though it were complete the next time the repo is resolved.
+		os.RemoveAll(fp)
 		return nil, err
 	}
 	repo := &Repository{
@@ -320,12 +762,9 @@ func newFSRepo(url string) (*Repository, error) {
 // github.com/spf13/cobra, and constructs an in-memory representation of the
 // git-related data. If there is an issue creating this representation, an
 // error is returned.
-func newInMemRepo(url string) (*Repository, error) {
+func newInMemRepo(ctx context.Context, url string, cloneOpts *git.CloneOptions) (*Repository, error) {
 	mStore := memory.NewStorage()
-	r, err := git.Clone(mStore, nil, &git.CloneOptions{
-		URL:        url,
-		NoCheckout: true,
-	})
+	r, err := cloneWithProgress(ctx, mStore, cloneOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -346,10 +785,32 @@ func newInMemRepo(url string) (*Repository, error) {
 	return repo, nil
 }
 
+// newCloneOptions translates conf into the go-git options used to clone url,
+// authenticating with auth.
+func newCloneOptions(url string, conf ResolveRepoOpts, auth transport.AuthMethod) *git.CloneOptions {
+	cloneOpts := &git.CloneOptions{
+		URL:          url,
+		Auth:         auth,
+		NoCheckout:   true,
+		Depth:        conf.Depth,
+		SingleBranch: conf.SingleBranch,
+	}
+	if conf.Branch != "" {
+		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(conf.Branch)
+	}
+	return cloneOpts
+}
+
 func (h Hash) String() string {
 	return hex.EncodeToString(h[:])
 }
 
+// newFSStorage returns the storage of a bare repository at path, as created by
+// [git.PlainInit].
+func newFSStorage(path string) *filesystem.Storage {
+	return filesystem.NewStorage(osfs.New(path), cache.NewObjectLRUDefault())
+}
+
 // ensureCacheDir will verify that proctor's cache dir already exists and if it
 // doesn't, create it.
 func ensureCacheDir() error {
@@ -373,10 +834,3 @@ func ensureCacheDir() error {
 func getDefaultCacheLocation() string {
 	return filepath.Join(xdg.DataHome, CacheDirName, CacheRepoDirName)
 }
-
-// getEncodedCacheName takes a repo's URL and returns its representation in
-// base64 encoding. This is used for creating unique cache directories when
-// persisting cloned repos onto the filesystem.
-func getEncodedCacheName(url string) string {
-	return base64.StdEncoding.EncodeToString([]byte(url))
-}
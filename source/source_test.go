@@ -1,13 +1,21 @@
 package source
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 const (
@@ -82,6 +90,662 @@ func TestGetCommits(t *testing.T) {
 	}
 }
 
+func TestGetCommitsOpts(t *testing.T) {
+	gm := NewGitManager()
+	r, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+
+	commits, err := gm.GetCommits(*r, GetCommitsOpts{AuthorEmail: "JANE@example.com"})
+	if err != nil {
+		t.Fatalf("fail: error retrieving list of commits from repo: %s", err)
+	}
+	if len(commits) != 2 {
+		t.Logf("fail: author filter returned wrong count, expected: %d, actual: %d", 2, len(commits))
+		t.Fail()
+	}
+
+	commits, err = gm.GetCommits(*r, GetCommitsOpts{Since: testRepo2Start.Add(36 * time.Hour)})
+	if err != nil {
+		t.Fatalf("fail: error retrieving list of commits from repo: %s", err)
+	}
+	if len(commits) != 2 {
+		t.Logf("fail: since filter returned wrong count, expected: %d, actual: %d", 2, len(commits))
+		t.Fail()
+	}
+
+	commits, err = gm.GetCommits(*r, GetCommitsOpts{Until: testRepo2Start.Add(36 * time.Hour)})
+	if err != nil {
+		t.Fatalf("fail: error retrieving list of commits from repo: %s", err)
+	}
+	if len(commits) != 2 {
+		t.Logf("fail: until filter returned wrong count, expected: %d, actual: %d", 2, len(commits))
+		t.Fail()
+	}
+
+	commits, err = gm.GetCommits(*r, GetCommitsOpts{Path: "docs"})
+	if err != nil {
+		t.Fatalf("fail: error retrieving list of commits from repo: %s", err)
+	}
+	if len(commits) != 1 || commits[0].Title != "docs: add readme" {
+		t.Logf("fail: path filter returned wrong commits: %+v", commits)
+		t.Fail()
+	}
+
+	commits, err = gm.GetCommits(*r, GetCommitsOpts{MaxCount: 3})
+	if err != nil {
+		t.Fatalf("fail: error retrieving list of commits from repo: %s", err)
+	}
+	if len(commits) != 3 {
+		t.Logf("fail: max count returned wrong count, expected: %d, actual: %d", 3, len(commits))
+		t.Fail()
+	}
+	// commits are ordered newest first
+	if commits[0].Title != "fix: handle empty input" {
+		t.Logf("fail: commits were not ordered newest first; first commit: %s", commits[0].Title)
+		t.Fail()
+	}
+}
+
+func TestResolveRepoShallow(t *testing.T) {
+	gm := NewGitManager()
+	_, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	url := filepath.Join(getTestRepoDir(), "repo2")
+
+	cloneOpts := newCloneOptions(url, ResolveRepoOpts{Depth: 2, SingleBranch: true, Branch: "master"}, nil)
+	if cloneOpts.Depth != 2 || !cloneOpts.SingleBranch || cloneOpts.ReferenceName != "refs/heads/master" {
+		t.Logf("fail: clone options were not set from resolve options: %+v", cloneOpts)
+		t.Fail()
+	}
+
+	// go-git's local transport ignores depth, so simulate a depth of 2 by
+	// marking the shallow boundary and removing the commits beyond it.
+	r, err := ResolveRepo(url, ResolveRepoOpts{InMemory: true})
+	if err != nil {
+		t.Fatalf("fail: error cloning test repo. error was: %s", err)
+	}
+	full, err := gm.GetCommits(*r)
+	if err != nil {
+		t.Fatalf("fail: error retrieving commits: %s", err)
+	}
+	mStore := r.RepoRef.Storer.(*memory.Storage)
+	err = mStore.SetShallow([]plumbing.Hash{plumbing.Hash(full[1].Hash)})
+	if err != nil {
+		t.Fatalf("fail: error setting shallow boundary: %s", err)
+	}
+	for _, c := range full[2:] {
+		delete(mStore.Objects, plumbing.Hash(c.Hash))
+		delete(mStore.Commits, plumbing.Hash(c.Hash))
+	}
+
+	commits, err := gm.GetCommits(*r)
+	if err != nil {
+		t.Fatalf("fail: error retrieving commits from shallow clone: %s", err)
+	}
+	if len(commits) != 2 {
+		t.Logf("fail: shallow clone had wrong number of commits, expected: %d, actual: %d", 2, len(commits))
+		t.Fail()
+	}
+
+	// the newest commits are kept when limiting a shallow history.
+	commits, err = gm.GetCommits(*r, GetCommitsOpts{MaxCount: 1})
+	if err != nil {
+		t.Fatalf("fail: error retrieving commits from shallow clone: %s", err)
+	}
+	if len(commits) != 1 || commits[0].Hash != full[0].Hash {
+		t.Logf("fail: expected only the newest commit, actual: %+v", commits)
+		t.Fail()
+	}
+}
+
+func TestResolveRepoRef(t *testing.T) {
+	gm := NewGitManager()
+	_, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	url := filepath.Join(getTestRepoDir(), "repo2")
+
+	r, err := ResolveRepo(url, ResolveRepoOpts{InMemory: true, Ref: "v0.1.0"})
+	if err != nil {
+		t.Fatalf("fail: error cloning test repo. error was: %s", err)
+	}
+	commits, err := gm.GetCommits(*r)
+	if err != nil {
+		t.Fatalf("fail: error retrieving commits: %s", err)
+	}
+	if len(commits) != 2 || commits[0].Hash != r.PinnedCommit {
+		t.Logf("fail: commits should start at the pinned ref, got: %+v", commits)
+		t.Fail()
+	}
+
+	content, err := ReadFileAtRef(*r, "", "main.go")
+	if err != nil {
+		t.Fatalf("fail: error reading file at pinned ref: %s", err)
+	}
+	if string(content) != "chore: bump deps" {
+		t.Logf("fail: file contents at pinned ref were wrong: %s", content)
+		t.Fail()
+	}
+	content, err = ReadFileAtRef(*r, "v0.2.0", "main.go")
+	if err != nil {
+		t.Fatalf("fail: error reading file at tag: %s", err)
+	}
+	if !strings.HasPrefix(string(content), "fix: handle empty input") {
+		t.Logf("fail: file contents at tag were wrong: %s", content)
+		t.Fail()
+	}
+	if _, err := ReadFileAtRef(*r, "v0.1.0", "docs/README.md"); err == nil {
+		t.Log("fail: expected error reading a file that doesn't exist at the ref")
+		t.Fail()
+	}
+
+	if _, err := ResolveRepo(url, ResolveRepoOpts{InMemory: true, Ref: "does-not-exist"}); err == nil {
+		t.Log("fail: expected error pinning a ref that doesn't exist")
+		t.Fail()
+	}
+}
+
+func TestResolveRepos(t *testing.T) {
+	_, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	url := filepath.Join(getTestRepoDir(), "repo2")
+	missing := filepath.Join(getTestRepoDir(), "does-not-exist")
+	opts := ResolveReposOpts{ResolveRepoOpts: ResolveRepoOpts{InMemory: true}, Concurrency: 2}
+
+	results := ResolveRepos(context.Background(), []string{url, missing, url}, opts)
+	if len(results) != 3 {
+		t.Fatalf("fail: wrong number of results, expected: %d, actual: %d", 3, len(results))
+	}
+	if results[0].Err != nil || results[0].Repo == nil || results[0].URL != url {
+		t.Logf("fail: expected repo to be resolved, got: %+v", results[0])
+		t.Fail()
+	}
+	if results[1].Err == nil || results[1].URL != missing {
+		t.Logf("fail: expected error resolving missing repo, got: %+v", results[1])
+		t.Fail()
+	}
+	if results[2].Repo != results[0].Repo {
+		t.Log("fail: duplicate urls should only be resolved once")
+		t.Fail()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = ResolveRepos(ctx, []string{url}, opts)
+	if results[0].Err != context.Canceled {
+		t.Logf("fail: expected context error resolving with a cancelled context, got: %v", results[0].Err)
+		t.Fail()
+	}
+}
+
+func TestGetBranches(t *testing.T) {
+	gm := NewGitManager()
+	r, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	// create a remote-only branch pointing to the 2nd commit (v0.1.0)
+	tags, err := gm.GetTagsFromRepository(*r)
+	if err != nil {
+		t.Fatalf("fail: error retrieving tags: %s", err)
+	}
+	v010 := NewMapOfTags(tags)["v0.1.0"].LastCommit
+	err = r.RepoRef.Storer.SetReference(plumbing.NewHashReference("refs/remotes/origin/release-0.1", plumbing.Hash(v010)))
+	if err != nil {
+		t.Fatalf("fail: error creating branch: %s", err)
+	}
+
+	branches, err := gm.GetBranches(*r)
+	if err != nil {
+		t.Fatalf("fail: error retrieving branches: %s", err)
+	}
+	if len(branches) != 2 || branches[0].Name != "master" || branches[1].Name != "release-0.1" {
+		t.Fatalf("fail: unexpected branches returned: %+v", branches)
+	}
+
+	commits, err := gm.GetCommits(*r, GetCommitsOpts{Branch: "release-0.1"})
+	if err != nil {
+		t.Fatalf("fail: error retrieving commits for branch: %s", err)
+	}
+	if len(commits) != 2 {
+		t.Logf("fail: branch commits had wrong count, expected: %d, actual: %d", 2, len(commits))
+		t.Fail()
+	}
+
+	_, err = gm.GetCommits(*r, GetCommitsOpts{Branch: "does-not-exist"})
+	if err == nil {
+		t.Log("fail: expected error retrieving commits from a branch that doesn't exist")
+		t.Fail()
+	}
+}
+
+func TestGetSubmodules(t *testing.T) {
+	gm := NewGitManager()
+	lib, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	tags, err := gm.GetTagsFromRepository(*lib)
+	if err != nil {
+		t.Fatalf("fail: error retrieving tags: %s", err)
+	}
+	pinned := NewMapOfTags(tags)["v0.1.0"].LastCommit
+
+	// the parent sits alongside repo2 so the relative submodule url resolves
+	// to it.
+	parent, err := createRepoWithSubmodule(filepath.Join(getTestRepoDir(), "parent"), "lib", "../repo2", plumbing.Hash(pinned))
+	if err != nil {
+		t.Fatalf("fail: error setting up parent repo. error was: %s", err)
+	}
+
+	submodules, err := gm.GetSubmodules(*parent)
+	if err != nil {
+		t.Fatalf("fail: error retrieving submodules: %s", err)
+	}
+	if len(submodules) != 1 {
+		t.Fatalf("fail: wrong number of submodules, expected: %d, actual: %d", 1, len(submodules))
+	}
+	if submodules[0].Path != "lib" || submodules[0].URL != "../repo2" || submodules[0].Commit != pinned {
+		t.Logf("fail: submodule was wrong: %+v", submodules[0])
+		t.Fail()
+	}
+	if submodules[0].Repo != nil {
+		t.Log("fail: submodule repo should only be resolved when recursive")
+		t.Fail()
+	}
+
+	submodules, err = gm.GetSubmodules(*parent, GetSubmodulesOpts{Recursive: true, ResolveOpts: ResolveRepoOpts{InMemory: true}})
+	if err != nil {
+		t.Fatalf("fail: error recursively retrieving submodules: %s", err)
+	}
+	if submodules[0].Repo == nil {
+		t.Fatal("fail: submodule repo was not resolved")
+	}
+	if _, err := submodules[0].Repo.RepoRef.CommitObject(plumbing.Hash(pinned)); err != nil {
+		t.Logf("fail: pinned commit was not found in resolved submodule: %s", err)
+		t.Fail()
+	}
+
+	// a submodule already searched at the same commit is still listed, but
+	// not resolved again.
+	head, err := resolveRef(*parent, "")
+	if err != nil {
+		t.Fatalf("fail: error resolving parent head: %s", err)
+	}
+	visited := map[string]bool{resolveSubmoduleURL(parent.URL, "../repo2") + "@" + plumbing.Hash(pinned).String(): true}
+	submodules, err = getSubmodulesAt(*parent, head, GetSubmodulesOpts{Recursive: true, ResolveOpts: ResolveRepoOpts{InMemory: true}}, visited)
+	if err != nil {
+		t.Fatalf("fail: error retrieving submodules: %s", err)
+	}
+	if len(submodules) != 1 || submodules[0].Repo != nil {
+		t.Logf("fail: visited submodule should be listed without its repo: %+v", submodules)
+		t.Fail()
+	}
+
+	if u := resolveSubmoduleURL("https://github.com/arctir/proctor.git", "../other.git"); u != "https://github.com/arctir/other.git" {
+		t.Logf("fail: relative submodule url was resolved wrong: %s", u)
+		t.Fail()
+	}
+}
+
+func TestGetCommitsBetween(t *testing.T) {
+	gm := NewGitManager()
+	r, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+
+	commits, err := gm.GetCommitsBetween(*r, "v0.1.0", "v0.2.0")
+	if err != nil {
+		t.Fatalf("fail: error retrieving commits between tags: %s", err)
+	}
+	if len(commits) != 2 || commits[0].Title != "fix: handle empty input" || commits[1].Title != "docs: add readme" {
+		t.Logf("fail: commits between tags were wrong: %+v", commits)
+		t.Fail()
+	}
+
+	commits, err = gm.GetCommitsBetween(*r, "", "v0.1.0")
+	if err != nil {
+		t.Fatalf("fail: error retrieving commits of first tag: %s", err)
+	}
+	if len(commits) != 2 {
+		t.Logf("fail: commits of first tag had wrong count, expected: %d, actual: %d", 2, len(commits))
+		t.Fail()
+	}
+
+	if _, err := gm.GetCommitsBetween(*r, "v0.0.1", "v0.2.0"); err == nil {
+		t.Log("fail: expected error retrieving commits from a tag that doesn't exist")
+		t.Fail()
+	}
+}
+
+func TestSearchCommits(t *testing.T) {
+	gm := NewGitManager()
+	r, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+
+	// matches the body of the commit, not only the title
+	commits, err := gm.SearchCommits(*r, `(?i)closes #\d+`)
+	if err != nil {
+		t.Fatalf("fail: error searching commits: %s", err)
+	}
+	if len(commits) != 1 || commits[0].Title != "fix: handle empty input" {
+		t.Logf("fail: searched commits were wrong: %+v", commits)
+		t.Fail()
+	}
+
+	// max count applies to the matching commits
+	commits, err = gm.SearchCommits(*r, `^(feat|fix|docs):`, GetCommitsOpts{MaxCount: 2})
+	if err != nil {
+		t.Fatalf("fail: error searching commits with max count: %s", err)
+	}
+	if len(commits) != 2 || commits[1].Title != "docs: add readme" {
+		t.Logf("fail: searched commits with max count were wrong: %+v", commits)
+		t.Fail()
+	}
+
+	if _, err := gm.SearchCommits(*r, "CVE-("); err == nil {
+		t.Log("fail: expected error searching with an invalid pattern")
+		t.Fail()
+	}
+}
+
+func TestGetReleaseNotes(t *testing.T) {
+	gm := NewGitManager()
+	r, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+
+	rn, err := gm.GetReleaseNotes(*r, "v0.1.0", "v0.2.0")
+	if err != nil {
+		t.Fatalf("fail: error generating release notes: %s", err)
+	}
+	if len(rn.Sections) != 2 {
+		t.Fatalf("fail: release notes had wrong section count, expected: %d, actual: %d", 2, len(rn.Sections))
+	}
+	if rn.Sections[0].Type != FixCommitType || rn.Sections[0].Notes[0].Description != "handle empty input" {
+		t.Logf("fail: first section was wrong: %+v", rn.Sections[0])
+		t.Fail()
+	}
+	if rn.Sections[1].Type != DocsCommitType {
+		t.Logf("fail: second section was wrong: %+v", rn.Sections[1])
+		t.Fail()
+	}
+	md := string(rn.Markdown())
+	if !strings.Contains(md, "### Bug Fixes") || strings.Contains(md, "bump deps") {
+		t.Logf("fail: markdown did not contain the expected content:\n%s", md)
+		t.Fail()
+	}
+
+	notes := NewReleaseNotes("a", "b", []Commit{
+		{Title: "feat(api)!: drop v1", Message: []byte("feat(api)!: drop v1")},
+		{Title: "update readme", Message: []byte("update readme")},
+	})
+	if len(notes.BreakingChanges) != 1 || notes.BreakingChanges[0].Scope != "api" {
+		t.Logf("fail: breaking change was not detected: %+v", notes.BreakingChanges)
+		t.Fail()
+	}
+	if notes.Sections[len(notes.Sections)-1].Type != OtherCommitType {
+		t.Logf("fail: non-conventional commit was not grouped as other: %+v", notes.Sections)
+		t.Fail()
+	}
+}
+
+func TestNewContributorStats(t *testing.T) {
+	gm := NewGitManager()
+	r, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	commits, err := gm.GetCommits(*r)
+	if err != nil {
+		t.Fatalf("fail: error retrieving list of commits from repo: %s", err)
+	}
+
+	authors := GetAuthors(commits)
+	if len(authors) != 2 || authors[0].CommitCount != 2 || authors[1].CommitCount != 2 {
+		t.Logf("fail: unexpected authors: %+v", authors)
+		t.Fail()
+	}
+
+	// window starts after the first commit, leaving joe with 2 commits and
+	// jane with 1.
+	stats := NewContributorStats(commits, ContributorStatsOpts{Since: testRepo2Start.Add(12 * time.Hour), TopN: 1})
+	if stats.TotalCommits != 3 || stats.ActiveContributors != 2 {
+		t.Logf("fail: unexpected totals, commits: %d, contributors: %d", stats.TotalCommits, stats.ActiveContributors)
+		t.Fail()
+	}
+	if stats.Authors[0].Email != "joe@corp.example.org" {
+		t.Logf("fail: expected joe to be the top author, actual: %s", stats.Authors[0].Email)
+		t.Fail()
+	}
+	if stats.BusFactor != 1 {
+		t.Logf("fail: bus factor was wrong, expected: %d, actual: %d", 1, stats.BusFactor)
+		t.Fail()
+	}
+	if int(stats.TopAuthorsShare) != 66 {
+		t.Logf("fail: top author share was wrong, expected: %d, actual: %f", 66, stats.TopAuthorsShare)
+		t.Fail()
+	}
+	if len(stats.NewContributors) != 1 || stats.NewContributors[0].Email != "joe@corp.example.org" {
+		t.Logf("fail: expected joe to be the only new contributor, actual: %+v", stats.NewContributors)
+		t.Fail()
+	}
+}
+
+func TestFilterCommitsByTime(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	commits := []Commit{
+		{Title: "a", Date: start},
+		{Title: "b", Date: start.Add(24 * time.Hour)},
+		{Title: "c", Date: start.Add(48 * time.Hour)},
+	}
+	tests := []struct {
+		since, until time.Time
+		expected     string
+	}{
+		{time.Time{}, time.Time{}, "abc"},
+		{start.Add(time.Hour), time.Time{}, "bc"},
+		{time.Time{}, start.Add(24 * time.Hour), "ab"},
+		{start.Add(time.Hour), start.Add(25 * time.Hour), "b"},
+	}
+	for _, test := range tests {
+		actual := ""
+		for _, c := range FilterCommitsByTime(commits, test.since, test.until) {
+			actual += c.Title
+		}
+		if actual != test.expected {
+			t.Logf("fail: expected commits %s between %s and %s, actual: %s", test.expected, test.since, test.until, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestGetActivity(t *testing.T) {
+	jane := Person{Name: "Jane", Email: "jane@example.com"}
+	joe := Person{Name: "Joe", Email: "joe@example.com"}
+	commits := []Commit{
+		// Monday and Wednesday of the same week
+		{Date: time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC), Author: jane},
+		{Date: time.Date(2023, 1, 4, 10, 0, 0, 0, time.UTC), Author: joe},
+		// two weeks later, leaving an empty week in between
+		{Date: time.Date(2023, 1, 20, 10, 0, 0, 0, time.UTC), Author: jane},
+	}
+
+	weekly := GetActivity(commits, WeeklyActivity)
+	if len(weekly) != 3 {
+		t.Fatalf("fail: weekly series had wrong length, expected: %d, actual: %d", 3, len(weekly))
+	}
+	if weekly[0].Commits != 2 || weekly[0].UniqueAuthors != 2 {
+		t.Logf("fail: first week was wrong: %+v", weekly[0])
+		t.Fail()
+	}
+	if weekly[1].Commits != 0 || weekly[2].Commits != 1 {
+		t.Logf("fail: later weeks were wrong: %+v", weekly[1:])
+		t.Fail()
+	}
+	if weekly[0].Start.Weekday() != time.Monday {
+		t.Logf("fail: weeks should start on monday, actual: %s", weekly[0].Start.Weekday())
+		t.Fail()
+	}
+
+	monthly := GetActivity(commits, MonthlyActivity)
+	if len(monthly) != 1 || monthly[0].Commits != 3 || monthly[0].UniqueAuthors != 2 {
+		t.Logf("fail: monthly series was wrong: %+v", monthly)
+		t.Fail()
+	}
+}
+
+// testCommit describes a commit to create in a test repository. When tag is
+// set, an annotated tag with that name is created on the commit.
+type testCommit struct {
+	file    string
+	message string
+	author  object.Signature
+	tag     string
+}
+
+// testRepo2Start is the time of the first commit in test repo 2. Each
+// following commit is made one day later.
+var testRepo2Start = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// createTestRepo2 creates a repository containing 4 commits from 2 authors,
+// with v0.1.0 tagging the 2nd commit and v0.2.0 tagging the 4th.
+func createTestRepo2() (*Repository, error) {
+	jane := object.Signature{Name: "Jane", Email: "jane@example.com"}
+	joe := object.Signature{Name: "Joe", Email: "joe@corp.example.org"}
+	return createTestRepoWithCommits("repo2", testRepo2Start, []testCommit{
+		{file: "main.go", message: "feat: initial implementation", author: jane},
+		{file: "main.go", message: "chore: bump deps", author: joe, tag: "v0.1.0"},
+		{file: "docs/README.md", message: "docs: add readme", author: joe},
+		{file: "main.go", message: "fix: handle empty input\n\nCloses #12", author: jane, tag: "v0.2.0"},
+	})
+}
+
+// createTestRepoWithCommits creates a repository named name and makes each
+// commit in order. The first commit is made at start and each following
+// commit is made one day later.
+func createTestRepoWithCommits(name string, start time.Time, commits []testCommit) (*Repository, error) {
+	fp, err := createMockRepoDir(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := git.PlainInit(fp, false)
+	if err != nil {
+		return nil, err
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range commits {
+		err = os.MkdirAll(filepath.Join(fp, filepath.Dir(c.file)), DefaultFilePerms)
+		if err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(filepath.Join(fp, c.file), []byte(c.message), DefaultFilePerms)
+		if err != nil {
+			return nil, err
+		}
+		_, err = wt.Add(c.file)
+		if err != nil {
+			return nil, err
+		}
+		sig := c.author
+		sig.When = start.Add(time.Duration(i) * 24 * time.Hour)
+		hash, err := wt.Commit(c.message, &git.CommitOptions{Author: &sig, Committer: &sig})
+		if err != nil {
+			return nil, err
+		}
+		if c.tag != "" {
+			_, err = r.CreateTag(c.tag, hash, &git.CreateTagOptions{Tagger: &sig, Message: c.tag})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &Repository{
+		URL:     "fake-url",
+		RepoRef: r,
+	}, nil
+}
+
+// createRepoWithSubmodule creates an in-memory repository, identified by url,
+// with a single commit containing a submodule at smPath that pins smCommit of
+// the repository at smURL.
+func createRepoWithSubmodule(url string, smPath string, smURL string, smCommit plumbing.Hash) (*Repository, error) {
+	r, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, err
+	}
+	modules := fmt.Sprintf("[submodule \"%s\"]\n\tpath = %s\n\turl = %s\n", smPath, smPath, smURL)
+	blob := r.Storer.NewEncodedObject()
+	blob.SetType(plumbing.BlobObject)
+	w, err := blob.Writer()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte(modules)); err != nil {
+		return nil, err
+	}
+	w.Close()
+	blobHash, err := r.Storer.SetEncodedObject(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &object.Tree{Entries: []object.TreeEntry{
+		{Name: ".gitmodules", Mode: filemode.Regular, Hash: blobHash},
+		{Name: smPath, Mode: filemode.Submodule, Hash: smCommit},
+	}}
+	treeObj := r.Storer.NewEncodedObject()
+	if err := tree.Encode(treeObj); err != nil {
+		return nil, err
+	}
+	treeHash, err := r.Storer.SetEncodedObject(treeObj)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := object.Signature{Name: "Jane", Email: "jane@example.com", When: testRepo2Start}
+	commit := &object.Commit{Author: sig, Committer: sig, Message: "add submodule", TreeHash: treeHash}
+	commitObj := r.Storer.NewEncodedObject()
+	if err := commit.Encode(commitObj); err != nil {
+		return nil, err
+	}
+	commitHash, err := r.Storer.SetEncodedObject(commitObj)
+	if err != nil {
+		return nil, err
+	}
+	err = r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), commitHash))
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{URL: url, RepoRef: r}, nil
+}
+
 func createTestRepo1() (*Repository, error) {
 	fp, err := createMockRepoDir("repo1")
 	if err != nil {
@@ -176,3 +840,30 @@ func cleanTestData() {
 		}
 	}
 }
+
+func TestGetOrganizations(t *testing.T) {
+	authors := []Author{
+		{Person: Person{Email: "jane@VMware.com"}, CommitCount: 5},
+		{Person: Person{Email: "joe@broadcom.com"}, CommitCount: 2},
+		{Person: Person{Email: "sam@corp.google.com"}, CommitCount: 3},
+		{Person: Person{Email: "kim@google.com"}, CommitCount: 1},
+		{Person: Person{Email: "bot"}, CommitCount: 9},
+	}
+	aliases := map[string]string{"vmware.com": "Broadcom", "broadcom.com": "Broadcom", "google.com": "Google"}
+	orgs := GetOrganizations(authors, aliases)
+	expected := []Organization{
+		{Name: UnknownDomain, Domains: []string{UnknownDomain}, AuthorCount: 1, CommitCount: 9},
+		{Name: "Broadcom", Domains: []string{"broadcom.com", "vmware.com"}, AuthorCount: 2, CommitCount: 7},
+		{Name: "Google", Domains: []string{"corp.google.com", "google.com"}, AuthorCount: 2, CommitCount: 4},
+	}
+	if !reflect.DeepEqual(orgs, expected) {
+		t.Logf("fail: unexpected organizations\nexpected: %+v\nactual: %+v", expected, orgs)
+		t.Fail()
+	}
+
+	orgs = GetOrganizations(authors, nil)
+	if len(orgs) != 5 || orgs[1].Name != "vmware.com" {
+		t.Logf("fail: expected one organization per domain without aliases, actual: %+v", orgs)
+		t.Fail()
+	}
+}
@@ -0,0 +1,262 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// The size, in bytes, an in-memory repository may reach before it is stored
+// in a temporary directory instead, when ResolveRepoOpts.MemoryLimit is not
+// set.
+const DefaultMemoryLimit = 512 << 20
+
+// The prefix of temporary directories that repositories exceeding the memory
+// limit are stored in.
+const tempRepoDirPrefix = "proctor-repo-"
+
+// Close releases the storage used by the repository. Repositories that were
+// stored in a temporary directory, because they exceeded
+// ResolveRepoOpts.MemoryLimit, have the directory removed. For every other
+// repository, Close does nothing. The repository must not be used after it
+// is closed.
+func (r Repository) Close() error {
+	if r.tempDir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(r.tempDir); err != nil {
+		return fmt.Errorf("failed removing temporary repo dir (%s): %s", r.tempDir, err)
+	}
+	return nil
+}
+
+// newSpillingRepo clones the repository at url into memory unless it is
+// expected to exceed the memory limit of conf, in which case it is cloned
+// into a temporary directory. When the objects of the in-memory clone exceed
+// the limit, they are moved to a temporary directory, where the clone
+// continues.
+func newSpillingRepo(ctx context.Context, url string, conf ResolveRepoOpts, cloneOpts *git.CloneOptions) (*Repository, error) {
+	limit := conf.MemoryLimit
+	if limit == 0 {
+		limit = DefaultMemoryLimit
+	}
+	if limit < 0 {
+		return newInMemRepo(ctx, url, cloneOpts)
+	}
+	if conf.SizeHint > limit {
+		return newTempDirRepo(ctx, url, cloneOpts)
+	}
+
+	s := &spillingStorer{current: memory.NewStorage(), limit: limit}
+	if _, err := cloneWithProgress(ctx, s, cloneOpts); err != nil {
+		if s.dir != "" {
+			os.RemoveAll(s.dir)
+		}
+		return nil, err
+	}
+	// the repository is opened on the storer the clone ended up in, so it's
+	// used directly from then on.
+	ref, err := git.Open(s.current, nil)
+	if err != nil {
+		if s.dir != "" {
+			os.RemoveAll(s.dir)
+		}
+		return nil, err
+	}
+	return &Repository{
+		URL:     url,
+		RepoRef: ref,
+		tempDir: s.dir,
+	}, nil
+}
+
+// newTempDirRepo clones the repository at url into a new temporary
+// directory, which is removed by [Repository.Close]. The directory is removed
+// right away when the clone fails.
+func newTempDirRepo(ctx context.Context, url string, cloneOpts *git.CloneOptions) (*Repository, error) {
+	dir, err := os.MkdirTemp("", tempRepoDirPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating temporary repo dir: %s", err)
+	}
+	ref, err := cloneWithProgress(ctx, newFSStorage(dir), cloneOpts)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &Repository{
+		URL:     url,
+		RepoRef: ref,
+		tempDir: dir,
+	}, nil
+}
+
+// spillingStorer stores a repository in memory until the size of its objects
+// exceeds limit, at which point everything stored so far is moved to a
+// temporary directory, which stores the repository from then on.
+type spillingStorer struct {
+	mu      sync.Mutex
+	current storage.Storer
+	limit   int64
+	size    int64
+	// the temporary directory the repository was moved to, if it was.
+	dir string
+}
+
+// storer returns the storer the repository is currently stored in.
+func (s *spillingStorer) storer() storage.Storer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+func (s *spillingStorer) SetEncodedObject(o plumbing.EncodedObject) (plumbing.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, err := s.current.SetEncodedObject(o)
+	if err != nil || s.dir != "" {
+		return h, err
+	}
+	s.size += o.Size()
+	if s.size > s.limit {
+		if err := s.spill(); err != nil {
+			return h, err
+		}
+	}
+	return h, nil
+}
+
+// spill moves the objects, references, configuration, and shallow commits
+// stored in memory to a new temporary directory and stores the repository
+// there from then on.
+func (s *spillingStorer) spill() error {
+	dir, err := os.MkdirTemp("", tempRepoDirPrefix)
+	if err != nil {
+		return fmt.Errorf("failed creating temporary repo dir: %s", err)
+	}
+	s.dir = dir
+	slog.Debug("moving repository exceeding the memory limit to a temporary directory", "path", dir, "limit", s.limit)
+	fs := newFSStorage(dir)
+	if err := fs.Init(); err != nil {
+		return fmt.Errorf("failed initializing temporary repo dir: %s", err)
+	}
+	objects, err := s.current.IterEncodedObjects(plumbing.AnyObject)
+	if err != nil {
+		return err
+	}
+	err = objects.ForEach(func(o plumbing.EncodedObject) error {
+		_, err := fs.SetEncodedObject(o)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed moving objects to temporary repo dir: %s", err)
+	}
+	refs, err := s.current.IterReferences()
+	if err != nil {
+		return err
+	}
+	if err := refs.ForEach(fs.SetReference); err != nil {
+		return fmt.Errorf("failed moving references to temporary repo dir: %s", err)
+	}
+	cfg, err := s.current.Config()
+	if err != nil {
+		return err
+	}
+	if err := fs.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed moving config to temporary repo dir: %s", err)
+	}
+	shallow, err := s.current.Shallow()
+	if err != nil {
+		return err
+	}
+	if err := fs.SetShallow(shallow); err != nil {
+		return fmt.Errorf("failed moving shallow commits to temporary repo dir: %s", err)
+	}
+	s.current = fs
+	return nil
+}
+
+func (s *spillingStorer) NewEncodedObject() plumbing.EncodedObject {
+	return s.storer().NewEncodedObject()
+}
+
+func (s *spillingStorer) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	return s.storer().EncodedObject(t, h)
+}
+
+func (s *spillingStorer) IterEncodedObjects(t plumbing.ObjectType) (storer.EncodedObjectIter, error) {
+	return s.storer().IterEncodedObjects(t)
+}
+
+func (s *spillingStorer) HasEncodedObject(h plumbing.Hash) error {
+	return s.storer().HasEncodedObject(h)
+}
+
+func (s *spillingStorer) EncodedObjectSize(h plumbing.Hash) (int64, error) {
+	return s.storer().EncodedObjectSize(h)
+}
+
+func (s *spillingStorer) SetReference(ref *plumbing.Reference) error {
+	return s.storer().SetReference(ref)
+}
+
+func (s *spillingStorer) CheckAndSetReference(new, old *plumbing.Reference) error {
+	return s.storer().CheckAndSetReference(new, old)
+}
+
+func (s *spillingStorer) Reference(n plumbing.ReferenceName) (*plumbing.Reference, error) {
+	return s.storer().Reference(n)
+}
+
+func (s *spillingStorer) IterReferences() (storer.ReferenceIter, error) {
+	return s.storer().IterReferences()
+}
+
+func (s *spillingStorer) RemoveReference(n plumbing.ReferenceName) error {
+	return s.storer().RemoveReference(n)
+}
+
+func (s *spillingStorer) CountLooseRefs() (int, error) {
+	return s.storer().CountLooseRefs()
+}
+
+func (s *spillingStorer) PackRefs() error {
+	return s.storer().PackRefs()
+}
+
+func (s *spillingStorer) SetShallow(commits []plumbing.Hash) error {
+	return s.storer().SetShallow(commits)
+}
+
+func (s *spillingStorer) Shallow() ([]plumbing.Hash, error) {
+	return s.storer().Shallow()
+}
+
+func (s *spillingStorer) SetIndex(idx *index.Index) error {
+	return s.storer().SetIndex(idx)
+}
+
+func (s *spillingStorer) Index() (*index.Index, error) {
+	return s.storer().Index()
+}
+
+func (s *spillingStorer) Config() (*config.Config, error) {
+	return s.storer().Config()
+}
+
+func (s *spillingStorer) SetConfig(c *config.Config) error {
+	return s.storer().SetConfig(c)
+}
+
+func (s *spillingStorer) Module(name string) (storage.Storer, error) {
+	return s.storer().Module(name)
+}
@@ -0,0 +1,67 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveRepoMemoryLimit(t *testing.T) {
+	gm := NewGitManager()
+	_, err := createTestRepo2()
+	defer cleanTestData()
+	if err != nil {
+		t.Fatalf("fail: error setting up test repo. error was: %s", err)
+	}
+	url := filepath.Join(getTestRepoDir(), "repo2")
+
+	// a repo expected to exceed the limit is stored in a temporary directory
+	repo, err := ResolveRepo(url, ResolveRepoOpts{InMemory: true, MemoryLimit: 1024, SizeHint: 2048})
+	if err != nil {
+		t.Fatalf("fail: unexpected error resolving repo: %s", err)
+	}
+	if repo.tempDir == "" {
+		t.Fatal("fail: expected repo exceeding memory limit to be stored in a temporary directory")
+	}
+	commits, err := gm.GetCommits(*repo)
+	if err != nil || len(commits) != 4 {
+		t.Logf("fail: commits of temporary repo were wrong: %d, error: %v", len(commits), err)
+		t.Fail()
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("fail: unexpected error closing repo: %s", err)
+	}
+	if _, err := os.Stat(repo.tempDir); !os.IsNotExist(err) {
+		t.Log("fail: expected temporary directory to be removed on close")
+		t.Fail()
+	}
+
+	// a repo exceeding the limit while it's cloned is moved to a temporary
+	// directory, keeping what was already received.
+	repo, err = ResolveRepo(url, ResolveRepoOpts{InMemory: true, MemoryLimit: 100})
+	if err != nil {
+		t.Fatalf("fail: unexpected error resolving repo: %s", err)
+	}
+	defer repo.Close()
+	if repo.tempDir == "" {
+		t.Fatal("fail: expected repo exceeding memory limit while cloning to be moved to a temporary directory")
+	}
+	commits, err = gm.GetCommits(*repo)
+	if err != nil || len(commits) != 4 {
+		t.Logf("fail: commits of moved repo were wrong: %d, error: %v", len(commits), err)
+		t.Fail()
+	}
+	if _, err := gm.GetTagsFromRepository(*repo); err != nil {
+		t.Logf("fail: unexpected error reading tags of moved repo: %s", err)
+		t.Fail()
+	}
+
+	repo, err = ResolveRepo(url, ResolveRepoOpts{InMemory: true, SizeHint: 2048})
+	if err != nil {
+		t.Fatalf("fail: unexpected error resolving repo: %s", err)
+	}
+	if repo.tempDir != "" {
+		t.Log("fail: expected repo within memory limit to be held in memory")
+		t.Fail()
+	}
+}
@@ -0,0 +1,164 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// The file, at the root of a repository, that describes its submodules.
+const gitModulesFile = ".gitmodules"
+
+// Submodule is a repository embedded within another repository at a pinned
+// commit.
+type Submodule struct {
+	Name string
+	// The path of the submodule, relative to the root of the repository
+	// containing it.
+	Path string
+	// The URL of the submodule's repository, as written in .gitmodules.
+	// Relative URLs (e.g. ../other.git) are relative to the URL of the
+	// repository containing the submodule.
+	URL string
+	// The branch the submodule tracks, if set.
+	Branch string
+	// The commit of the submodule's repository that is pinned.
+	Commit Hash
+	// The submodule's repository. Only set when retrieved with
+	// GetSubmodulesOpts.Recursive, and not for a submodule whose repository
+	// was already searched at the same commit elsewhere in the tree.
+	Repo *Repository
+	// The submodules of this submodule, at its pinned commit. Only set when
+	// Repo is.
+	Submodules []Submodule
+}
+
+// GetSubmodulesOpts configures how submodules are retrieved.
+type GetSubmodulesOpts struct {
+	// Resolve each submodule's repository, using [ResolveRepo], and retrieve
+	// its submodules at the pinned commit. This continues until no more
+	// submodules are found.
+	Recursive bool
+	// The options used to resolve submodule repositories when Recursive is
+	// set.
+	ResolveOpts ResolveRepoOpts
+}
+
+// GetSubmodules returns the submodules of the repository at its PinnedCommit
+// or, if unset, HEAD. Submodules listed in .gitmodules that have no pinned
+// commit in the tree are not returned. When the repository has no submodules,
+// an empty slice is returned.
+func (gm *GitManager) GetSubmodules(r Repository, opts ...GetSubmodulesOpts) ([]Submodule, error) {
+	conf := GetSubmodulesOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if r.RepoRef == nil {
+		return nil, fmt.Errorf("failed to find reference to valid repo when looking up submodules.")
+	}
+	commit, err := resolveRef(r, "")
+	if err != nil {
+		return nil, err
+	}
+	return getSubmodulesAt(r, commit, conf, map[string]bool{})
+}
+
+// getSubmodulesAt returns the submodules of r at commit. visited contains the
+// URL and commit of every repository already searched, which guards against
+// the same submodule being resolved more than once when recursing.
+func getSubmodulesAt(r Repository, commit plumbing.Hash, conf GetSubmodulesOpts, visited map[string]bool) ([]Submodule, error) {
+	visited[r.URL+"@"+commit.String()] = true
+	c, err := r.RepoRef.CommitObject(commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving commit (%s): %s", commit, err)
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving tree of commit (%s): %s", commit, err)
+	}
+
+	modulesFile, err := tree.File(gitModulesFile)
+	if err == object.ErrFileNotFound {
+		return []Submodule{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed reading %s: %s", gitModulesFile, err)
+	}
+	modules, err := parseGitModules(modulesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	submodules := []Submodule{}
+	for _, m := range modules.Submodules {
+		entry, err := tree.FindEntry(m.Path)
+		if err != nil || entry.Mode != filemode.Submodule {
+			continue
+		}
+		sm := Submodule{
+			Name:   m.Name,
+			Path:   m.Path,
+			URL:    m.URL,
+			Branch: m.Branch,
+			Commit: Hash(entry.Hash),
+		}
+		smURL := resolveSubmoduleURL(r.URL, m.URL)
+		// a submodule already searched is still listed, but not resolved
+		// again, which would never finish for submodules that include each
+		// other.
+		if conf.Recursive && !visited[smURL+"@"+entry.Hash.String()] {
+			smRepo, err := ResolveRepo(smURL, conf.ResolveOpts)
+			if err != nil {
+				return nil, fmt.Errorf("failed resolving submodule (%s) at %s: %s", m.Name, smURL, err)
+			}
+			sm.Repo = smRepo
+			sm.Submodules, err = getSubmodulesAt(*smRepo, entry.Hash, conf, visited)
+			if err != nil {
+				return nil, err
+			}
+		}
+		submodules = append(submodules, sm)
+	}
+	return submodules, nil
+}
+
+// parseGitModules reads and parses the contents of a .gitmodules file.
+func parseGitModules(f *object.File) (*config.Modules, error) {
+	reader, err := f.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading %s: %s", gitModulesFile, err)
+	}
+	defer reader.Close()
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading %s: %s", gitModulesFile, err)
+	}
+	modules := config.NewModules()
+	if err := modules.Unmarshal(b); err != nil {
+		return nil, fmt.Errorf("failed parsing %s: %s", gitModulesFile, err)
+	}
+	return modules, nil
+}
+
+// resolveSubmoduleURL returns the URL of a submodule. Relative submodule URLs
+// are resolved against parentURL, the URL of the repository containing the
+// submodule; absolute URLs are returned as is.
+func resolveSubmoduleURL(parentURL string, submoduleURL string) string {
+	if !strings.HasPrefix(submoduleURL, "./") && !strings.HasPrefix(submoduleURL, "../") {
+		return submoduleURL
+	}
+	// relative urls are relative to the parent repository itself, not the
+	// directory containing it, which path.Join treats the same as a directory.
+	if u, err := url.Parse(parentURL); err == nil && u.Scheme != "" && u.Host != "" {
+		u.Path = path.Join(u.Path, submoduleURL)
+		return u.String()
+	}
+	return path.Join(parentURL, submoduleURL)
+}
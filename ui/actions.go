@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	signalPath = "/signal"
+	// the form fields of a request to signal a process.
+	pidField    = "pid"
+	shaField    = "sha"
+	signalField = "signal"
+	tokenField  = "token"
+)
+
+const (
+	// the number of invalid action tokens accepted before actions are locked
+	// out for actionLockout.
+	maxFailedActions = 5
+	actionLockout    = time.Minute
+)
+
+var (
+	// the signals offered on the process details page.
+	actionSignals = []string{"SIGTERM", "SIGKILL"}
+	// the audit trail of process actions when [UIConfig] doesn't configure
+	// one.
+	defaultAuditLog = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+)
+
+// actionThrottle locks out process actions after repeated invalid tokens, so
+// the token can't be guessed. Attempts are counted across every client, so
+// guesses can't be spread over many addresses.
+type actionThrottle struct {
+	lock     sync.Mutex
+	failures int
+	until    time.Time
+}
+
+// lockedOut returns how long actions remain locked out, 0 when they aren't.
+func (t *actionThrottle) lockedOut() time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if remaining := time.Until(t.until); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// record counts an attempt, locking out actions after maxFailedActions
+// consecutive invalid tokens.
+func (t *actionThrottle) record(valid bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if valid {
+		t.failures = 0
+		return
+	}
+	t.failures++
+	if t.failures >= maxFailedActions {
+		t.failures = 0
+		t.until = time.Now().Add(actionLockout)
+	}
+}
+
+// ActionData is rendered after a process is signaled.
+type ActionData struct {
+	PID    int
+	Signal string
+	Error  string
+}
+
+// auditLog returns the logger process actions are recorded to. Unless one is
+// configured, records of every level are written to stderr, regardless of the
+// level of the default logger.
+func (ui *UI) auditLog() *slog.Logger {
+	if ui.audit == nil {
+		return defaultAuditLog
+	}
+	return ui.audit
+}
+
+// handleSignal sends the signal in the request's form to a process, when the
+// form's token matches the configured action token. The form carries the
+// binary SHA of the process as it was shown, and the signal is only sent while
+// the process still runs that binary, so a pid reused since the page was
+// rendered isn't signaled. Every attempt is logged, successful or not, as an
+// audit trail.
+func (ui *UI) handleSignal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "signals must be sent with POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if ui.actionToken == "" {
+		http.Error(w, "process actions are disabled, as no action token is configured", http.StatusForbidden)
+		return
+	}
+	data := ActionData{Signal: r.PostFormValue(signalField)}
+	pid, err := strconv.Atoi(r.PostFormValue(pidField))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("process %v was not valid pid (needs to be int)", r.PostFormValue(pidField)), http.StatusBadRequest)
+		return
+	}
+	data.PID = pid
+	sha := r.PostFormValue(shaField)
+	if sha == "" {
+		http.Error(w, "the binary SHA of the process to signal is required", http.StatusBadRequest)
+		return
+	}
+	if remaining := ui.actionThrottle.lockedOut(); remaining > 0 {
+		ui.auditLog().Warn("audit: rejected signal, actions are locked out", "signal", data.Signal, "pid", pid, "remote", r.RemoteAddr)
+		w.Header().Set("Retry-After", strconv.Itoa(int(remaining.Seconds())+1))
+		http.Error(w, "too many invalid action tokens were entered, try again later", http.StatusTooManyRequests)
+		return
+	}
+	valid := subtle.ConstantTimeCompare([]byte(r.PostFormValue(tokenField)), []byte(ui.actionToken)) == 1
+	ui.actionThrottle.record(valid)
+	if !valid {
+		ui.auditLog().Warn("audit: rejected signal, invalid token", "signal", data.Signal, "pid", pid, "remote", r.RemoteAddr)
+		http.Error(w, "the action token was invalid", http.StatusUnauthorized)
+		return
+	}
+
+	sig, err := plib.ParseSignal(data.Signal)
+	if err == nil {
+		err = plib.SignalVerifiedProcess(pid, sig, sha)
+	}
+	if err != nil {
+		ui.auditLog().Error("audit: failed sending signal", "signal", data.Signal, "pid", pid, "sha", sha, "remote", r.RemoteAddr, "error", err)
+		data.Error = err.Error()
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		ui.auditLog().Info("audit: sent signal", "signal", data.Signal, "pid", pid, "sha", sha, "remote", r.RemoteAddr)
+	}
+	ui.renderTemplate(w, viewAction, data)
+}
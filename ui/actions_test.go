@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+func TestHandleSignal(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("unable to start a process to signal: %s", err)
+	}
+	defer cmd.Process.Kill()
+	pid := strconv.Itoa(cmd.Process.Pid)
+	sha, err := plib.HashFile(filepath.Join("/proc", pid, "exe"))
+	if err != nil {
+		t.Skipf("unable to hash the binary of the process to signal: %s", err)
+	}
+
+	post := func(ui *UI, form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", signalPath, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		ui.handleSignal(w, r)
+		return w
+	}
+
+	if w := post(&UI{}, url.Values{"pid": {pid}, "signal": {"SIGTERM"}}); w.Code != http.StatusForbidden {
+		t.Logf("fail: expected actions to be disabled without a token, actual status: %d", w.Code)
+		t.Fail()
+	}
+	// the audit trail is written to its own logger, not the default one.
+	var audit bytes.Buffer
+	ui := &UI{actionToken: "s3cret", audit: slog.New(slog.NewTextHandler(&audit, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+	w := httptest.NewRecorder()
+	ui.handleSignal(w, httptest.NewRequest("GET", signalPath, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Logf("fail: expected GET to be rejected, actual status: %d", w.Code)
+		t.Fail()
+	}
+	if w := post(ui, url.Values{"pid": {pid}, "sha": {sha}, "signal": {"SIGTERM"}, "token": {"wrong"}}); w.Code != http.StatusUnauthorized {
+		t.Logf("fail: expected an invalid token to be rejected, actual status: %d", w.Code)
+		t.Fail()
+	}
+	if w := post(ui, url.Values{"pid": {pid}, "sha": {sha}, "signal": {"SIGSEGV"}, "token": {"s3cret"}}); w.Code != http.StatusInternalServerError {
+		t.Logf("fail: expected an unsupported signal to fail, actual status: %d", w.Code)
+		t.Fail()
+	}
+
+	if w := post(ui, url.Values{"pid": {pid}, "signal": {"SIGTERM"}, "token": {"s3cret"}}); w.Code != http.StatusBadRequest {
+		t.Logf("fail: expected a signal without the process's binary SHA to be rejected, actual status: %d", w.Code)
+		t.Fail()
+	}
+	if w := post(ui, url.Values{"pid": {pid}, "sha": {strings.Repeat("0", 64)}, "signal": {"SIGTERM"}, "token": {"s3cret"}}); w.Code != http.StatusInternalServerError {
+		t.Logf("fail: expected a signal to a process running another binary to fail, actual status: %d", w.Code)
+		t.Fail()
+	}
+
+	w = post(ui, url.Values{"pid": {pid}, "sha": {sha}, "signal": {"SIGTERM"}, "token": {"s3cret"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Sent SIGTERM to process "+pid) {
+		t.Fatalf("fail: expected the signal to be sent, actual status: %d", w.Code)
+	}
+	if err := cmd.Wait(); err == nil || cmd.ProcessState.String() != "signal: terminated" {
+		t.Logf("fail: expected the process to be terminated, actual: %s", cmd.ProcessState)
+		t.Fail()
+	}
+	for _, record := range []string{"audit: rejected signal, invalid token", "audit: failed sending signal", "audit: sent signal"} {
+		if !strings.Contains(audit.String(), record) {
+			t.Logf("fail: expected the audit trail to record %q, actual:\n%s", record, audit.String())
+			t.Fail()
+		}
+	}
+}
+
+func TestHandleSignalLockout(t *testing.T) {
+	ui := &UI{actionToken: "s3cret"}
+	post := func(token string) int {
+		form := url.Values{"pid": {"1"}, "sha": {strings.Repeat("0", 64)}, "signal": {"SIGTERM"}, "token": {token}}
+		r := httptest.NewRequest("POST", signalPath, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		ui.handleSignal(w, r)
+		return w.Code
+	}
+	for i := 0; i < maxFailedActions; i++ {
+		if code := post("wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("fail: expected invalid token %d to be rejected, actual status: %d", i+1, code)
+		}
+	}
+	// the valid token is rejected too, until the lockout ends.
+	if code := post("s3cret"); code != http.StatusTooManyRequests {
+		t.Logf("fail: expected actions to be locked out, actual status: %d", code)
+		t.Fail()
+	}
+}
@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	comparePath = "/compare"
+	// the query parameters holding the pids of the processes compared.
+	compareAParam = "a"
+	compareBParam = "b"
+)
+
+// CompareData is rendered by the page comparing two processes side by side.
+type CompareData struct {
+	// The pids entered, which may not be valid.
+	A string
+	B string
+	// The processes compared, nil until both are entered.
+	ProcessA *plib.Process
+	ProcessB *plib.Process
+	Sections []CompareSection
+}
+
+// CompareSection is a group of attributes compared, such as the environment.
+type CompareSection struct {
+	Title string
+	Rows  []CompareRow
+	// Set when the section's attributes couldn't be retrieved for either
+	// process, such as when it belongs to another user.
+	Error string
+}
+
+// CompareRow is an attribute of both processes. A value is empty when the
+// process doesn't have the attribute.
+type CompareRow struct {
+	Name    string
+	A       string
+	B       string
+	Differs bool
+}
+
+// handleCompare renders a form to enter two pids and, once entered, their
+// attributes side by side with the differences highlighted.
+func (ui *UI) handleCompare(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := CompareData{A: strings.TrimSpace(q.Get(compareAParam)), B: strings.TrimSpace(q.Get(compareBParam))}
+	if data.A == "" || data.B == "" {
+		ui.renderTemplate(w, viewCompare, data)
+		return
+	}
+	ps, err := ui.loadedProcesses()
+	if err != nil {
+		ui.writeFailure(w, err)
+		return
+	}
+	if data.ProcessA, err = lookupProcess(ps, data.A); err != nil {
+		ui.writeFailure(w, err)
+		return
+	}
+	if data.ProcessB, err = lookupProcess(ps, data.B); err != nil {
+		ui.writeFailure(w, err)
+		return
+	}
+	data.Sections = compareProcesses(ui.inspector, data.ProcessA, data.ProcessB)
+	ui.renderTemplate(w, viewCompare, data)
+}
+
+// lookupProcess returns the process in ps with the pid pidString, with an
+// error rendered as a 400 when the pid isn't valid and a 404 when the process
+// doesn't exist.
+func lookupProcess(ps plib.Processes, pidString string) (*plib.Process, error) {
+	pid, err := strconv.Atoi(pidString)
+	if err != nil {
+		return nil, newHTTPError(http.StatusBadRequest, "process %v was not valid pid (needs to be int)", pidString)
+	}
+	if ps[pid] == nil {
+		return nil, newHTTPError(http.StatusNotFound, "process %d does not exist.", pid)
+	}
+	return ps[pid], nil
+}
+
+// compareProcesses returns the attributes of a and b, grouped into sections.
+// The environment, limits, and namespaces are only compared when the inspector
+// is a [plib.ResourceInspector].
+func compareProcesses(inspector plib.Inspector, a, b *plib.Process) []CompareSection {
+	sections := []CompareSection{{Title: "Process", Rows: compareRows(detailValues(*a), detailValues(*b))}}
+	ri, ok := inspector.(plib.ResourceInspector)
+	if !ok {
+		return sections
+	}
+
+	envValues := func(pid int) (orderedValues, error) {
+		env, err := ri.GetEnvironment(pid)
+		values := orderedValues{}
+		for _, kv := range plib.RedactEnvironment(env) {
+			k, v, _ := strings.Cut(kv, "=")
+			values.add(k, v)
+		}
+		return values, err
+	}
+	limitValues := func(pid int) (orderedValues, error) {
+		limits, err := ri.GetLimits(pid)
+		values := orderedValues{}
+		for _, l := range limits {
+			values.add(l.Name, strings.TrimSpace(fmt.Sprintf("%s / %s %s", l.Soft, l.Hard, l.Units)))
+		}
+		return values, err
+	}
+	namespaceValues := func(pid int) (orderedValues, error) {
+		namespaces, err := ri.GetNamespaces(pid)
+		values := orderedValues{}
+		for _, n := range namespaces {
+			values.add(n.Type, strconv.FormatUint(n.Inode, 10))
+		}
+		return values, err
+	}
+	for _, s := range []struct {
+		title  string
+		values func(pid int) (orderedValues, error)
+	}{
+		{"Environment", envValues},
+		{"Limits", limitValues},
+		{"Namespaces", namespaceValues},
+	} {
+		section := CompareSection{Title: s.title}
+		va, errA := s.values(a.ID)
+		vb, errB := s.values(b.ID)
+		switch {
+		case errA != nil:
+			section.Error = fmt.Sprintf("process %d: %s", a.ID, errA)
+		case errB != nil:
+			section.Error = fmt.Sprintf("process %d: %s", b.ID, errB)
+		default:
+			section.Rows = compareRows(va, vb)
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// orderedValues are named values in the order they were added.
+type orderedValues struct {
+	names  []string
+	values map[string]string
+}
+
+func (o *orderedValues) add(name, value string) {
+	if o.values == nil {
+		o.values = map[string]string{}
+	}
+	if _, ok := o.values[name]; !ok {
+		o.names = append(o.names, name)
+	}
+	o.values[name] = value
+}
+
+// detailValues returns the details of p, as listed on the process details
+// page.
+func detailValues(p plib.Process) orderedValues {
+	values := orderedValues{}
+	for _, kv := range getProcessDetails(p) {
+		values.add(kv.Field, kv.Value)
+	}
+	return values
+}
+
+// compareRows returns a row for every name in a or b, in the order of a
+// followed by the names only in b.
+func compareRows(a, b orderedValues) []CompareRow {
+	rows := []CompareRow{}
+	names := append([]string{}, a.names...)
+	for _, name := range b.names {
+		if _, ok := a.values[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		va, okA := a.values[name]
+		vb, okB := b.values[name]
+		rows = append(rows, CompareRow{Name: name, A: va, B: vb, Differs: okA != okB || va != vb})
+	}
+	return rows
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// stubResourceInspector returns fixed resources for each process.
+type stubResourceInspector struct {
+	stubInspector
+	env        map[int][]string
+	limits     map[int][]plib.Limit
+	namespaces map[int][]plib.Namespace
+}
+
+func (s *stubResourceInspector) GetEnvironment(pid int) ([]string, error)      { return s.env[pid], nil }
+func (s *stubResourceInspector) GetOpenFiles(pid int) ([]plib.OpenFile, error) { return nil, nil }
+func (s *stubResourceInspector) GetSockets(pid int) ([]plib.Socket, error)     { return nil, nil }
+func (s *stubResourceInspector) GetLimits(pid int) ([]plib.Limit, error)       { return s.limits[pid], nil }
+func (s *stubResourceInspector) GetNamespaces(pid int) ([]plib.Namespace, error) {
+	return s.namespaces[pid], nil
+}
+
+func TestHandleCompare(t *testing.T) {
+	inspector := &stubResourceInspector{
+		stubInspector: stubInspector{ps: plib.Processes{
+			10: {ID: 10, CommandName: "nginx", BinarySHA: "aa11", OSSpecific: plib.ProcessStat{}},
+			20: {ID: 20, CommandName: "nginx", BinarySHA: "bb22", OSSpecific: plib.ProcessStat{}},
+		}},
+		env: map[int][]string{
+			10: {"PATH=/usr/bin", "WORKERS=4"},
+			20: {"PATH=/usr/bin", "DEBUG=1"},
+		},
+		limits: map[int][]plib.Limit{
+			10: {{Name: "Max open files", Soft: "1024", Hard: "4096", Units: "files"}},
+			20: {{Name: "Max open files", Soft: "65536", Hard: "65536", Units: "files"}},
+		},
+		namespaces: map[int][]plib.Namespace{
+			10: {{Type: "net", Inode: 1}},
+			20: {{Type: "net", Inode: 1}},
+		},
+	}
+	ui := &UI{inspector: inspector}
+
+	w := httptest.NewRecorder()
+	ui.handleCompare(w, httptest.NewRequest("GET", comparePath+"?a=10&b=20", nil))
+	body := w.Body.String()
+	for _, expected := range []string{
+		`<tr class="differs">
+                <td>BinarySHA</td>
+                <td>aa11</td>
+                <td>bb22</td>`,
+		`<tr class="differs">
+                <td>WORKERS</td>
+                <td>4</td>
+                <td></td>`,
+		`<tr class="differs">
+                <td>DEBUG</td>
+                <td></td>
+                <td>1</td>`,
+		`<tr>
+                <td>PATH</td>`,
+		"<td>1024 / 4096 files</td>",
+		`<tr>
+                <td>net</td>`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Logf("fail: expected the comparison to contain %q", expected)
+			t.Fail()
+		}
+	}
+
+	tests := map[string]int{
+		"":             http.StatusOK,
+		"?a=10":        http.StatusOK,
+		"?a=10&b=abc":  http.StatusBadRequest,
+		"?a=10&b=30":   http.StatusNotFound,
+		"?a=20&b=10":   http.StatusOK,
+		"?a=+10&b=20+": http.StatusOK,
+	}
+	for query, code := range tests {
+		w := httptest.NewRecorder()
+		ui.handleCompare(w, httptest.NewRequest("GET", comparePath+query, nil))
+		if w.Code != code {
+			t.Logf("fail: expected %s to respond %d, got %d", query, code, w.Code)
+			t.Fail()
+		}
+	}
+}
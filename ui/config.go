@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arctir/proctor/baseline"
+)
+
+const (
+	// DefaultPort is the port the UI is served on when [UIConfig] doesn't
+	// specify one.
+	DefaultPort = 8080
+	// DefaultShutdownTimeout is how long in-flight requests are given to
+	// complete when the UI is shut down, when [UIConfig] doesn't specify it.
+	DefaultShutdownTimeout = 10 * time.Second
+	// DefaultSourceTimeout is how long the source pages spend cloning or
+	// fetching a repository, when [UIConfig] doesn't specify it.
+	DefaultSourceTimeout = 2 * time.Minute
+	// how long generated self-signed certificates are valid for.
+	selfSignedValidity = 365 * 24 * time.Hour
+)
+
+// UIConfig configures how the UI is served.
+type UIConfig struct {
+	// The address to bind to. Empty binds to all interfaces.
+	Address string
+	// The port to listen on. Defaults to [DefaultPort].
+	Port int
+	// The paths to a PEM encoded certificate and key to serve TLS with.
+	TLSCert string
+	TLSKey  string
+	// When true, and TLSCert isn't set, serve TLS with a self-signed
+	// certificate generated at startup.
+	SelfSigned bool
+	// The time between scans of the host's processes, which update the
+	// live process table and the CPU utilization. Defaults to
+	// [plib.DefaultWatchInterval].
+	ScanInterval time.Duration
+	// How long in-flight requests are given to complete when shutting down.
+	// Defaults to [DefaultShutdownTimeout].
+	ShutdownTimeout time.Duration
+	// The URLs of other proctor UIs, such as http://db-1:8080, whose
+	// processes are shown alongside this host's on the hosts page.
+	Agents []string
+	// The client the processes of Agents are retrieved with, such as one
+	// verifying agents served over TLS with a private CA and presenting a
+	// client certificate. Defaults to a client timing out after 10 seconds.
+	AgentClient *http.Client
+	// The token that must be entered to signal processes from the process
+	// details page. Actions are disabled when it isn't set. Unless the UI is
+	// bound to a loopback address, actions require TLS.
+	ActionToken string
+	// Where a JSON line is written for every request served. Defaults to
+	// stderr.
+	AccessLog io.Writer
+	// The logger every attempt to signal a process is recorded to, as an
+	// audit trail. Records are written at every level, including info, so it
+	// shouldn't filter by level, or the trail is lost when other logging is
+	// quieted. Defaults to a logger writing every record to stderr as text.
+	AuditLog *slog.Logger
+	// When true, no access log is written.
+	DisableAccessLog bool
+	// A directory of templates and static files used in place of the
+	// embedded ones, laid out the same (e.g. templates/header.html or
+	// static/style.css). Only the files being customized need to exist.
+	TemplateDir string
+	// The baseline the host's processes are compared against on the drift
+	// page. The page explains how to configure one when it isn't set.
+	Baseline *baseline.Baseline
+	// The hosts, such as github.com, whose repositories may be browsed on the
+	// source pages, which are only retrieved over HTTPS. Since the UI clones
+	// any repository entered, and retrieves releases with the platform's
+	// credentials, the source pages are disabled when empty.
+	SourceHosts []string
+	// The maximum amount of time the source pages spend retrieving a
+	// repository. Defaults to [DefaultSourceTimeout].
+	SourceTimeout time.Duration
+}
+
+// addr returns the address the UI should listen on, in the form accepted by
+// [net.Listen].
+func (c UIConfig) addr() string {
+	port := c.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	return net.JoinHostPort(c.Address, strconv.Itoa(port))
+}
+
+// isLoopback returns whether the UI is bound to a loopback address, and so is
+// only reachable from the host itself.
+func (c UIConfig) isLoopback() bool {
+	if c.Address == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(c.Address)
+	return ip != nil && ip.IsLoopback()
+}
+
+// tlsConfig returns the TLS configuration the UI should be served with, or nil
+// when it should be served over plain HTTP.
+func (c UIConfig) tlsConfig() (*tls.Config, error) {
+	if c.TLSCert == "" && c.TLSKey != "" || c.TLSCert != "" && c.TLSKey == "" {
+		return nil, fmt.Errorf("both a TLS certificate and key must be specified")
+	}
+	if c.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading TLS certificate: %s", err)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	}
+	if c.SelfSigned {
+		cert, err := generateSelfSignedCert(c.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	}
+	return nil, nil
+}
+
+// generateSelfSignedCert returns a certificate, signed by its own key, valid
+// for localhost and the host argument when it's set.
+func generateSelfSignedCert(host string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed generating key: %s", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed generating serial number: %s", err)
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"proctor"}},
+		NotBefore:             now,
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else if host != "" {
+		template.DNSNames = append(template.DNSNames, host)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed creating certificate: %s", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"crypto/x509"
+	"testing"
+)
+
+func TestUIConfigAddr(t *testing.T) {
+	tests := map[string]UIConfig{
+		":8080":          {},
+		"127.0.0.1:9000": {Address: "127.0.0.1", Port: 9000},
+		"[::1]:8080":     {Address: "::1"},
+	}
+	for expected, conf := range tests {
+		if actual := conf.addr(); actual != expected {
+			t.Logf("fail: expected address: %s, actual: %s", expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestUIConfigTLS(t *testing.T) {
+	tlsConfig, err := UIConfig{}.tlsConfig()
+	if err != nil || tlsConfig != nil {
+		t.Fatalf("fail: expected plain HTTP by default, got: %v, %v", tlsConfig, err)
+	}
+	if _, err := (UIConfig{TLSCert: "cert.pem"}).tlsConfig(); err == nil {
+		t.Logf("fail: expected an error when the TLS key is missing")
+		t.Fail()
+	}
+
+	tlsConfig, err = UIConfig{Address: "proctor.local", SelfSigned: true}.tlsConfig()
+	if err != nil {
+		t.Fatalf("fail: failed generating self-signed certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatalf("fail: failed parsing self-signed certificate: %s", err)
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "proctor.local"} {
+		if err := cert.VerifyHostname(host); err != nil {
+			t.Logf("fail: self-signed certificate isn't valid for %s: %s", host, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestUIConfigIsLoopback(t *testing.T) {
+	tests := map[string]bool{
+		"":            false,
+		"0.0.0.0":     false,
+		"10.0.0.5":    false,
+		"proctor.lan": false,
+		"localhost":   true,
+		"127.0.0.1":   true,
+		"::1":         true,
+	}
+	for address, expected := range tests {
+		if actual := (UIConfig{Address: address}).isLoopback(); actual != expected {
+			t.Logf("fail: expected loopback of %q: %t, actual: %t", address, expected, actual)
+			t.Fail()
+		}
+	}
+}
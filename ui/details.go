@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"net/url"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	// the query parameter selecting the tab of the process details page.
+	tabParam = "tab"
+	// the tabs of the process details page.
+	statTab    = "stat"
+	envTab     = "env"
+	filesTab   = "files"
+	socketsTab = "sockets"
+)
+
+// DetailsData is rendered by the process details page.
+type DetailsData struct {
+	Process *plib.Process
+	// The selected tab and the tabs available.
+	Tab  string
+	Tabs []Option
+	// The content of the selected tab, when it isn't the stat tab.
+	Environment []string
+	Files       []plib.OpenFile
+	Sockets     []plib.Socket
+	// Set when the selected tab's content couldn't be retrieved, such as when
+	// the process belongs to another user.
+	Error string
+	// The signals that can be sent to the process, empty when process
+	// actions are disabled or the process's binary SHA is unknown.
+	Signals []string
+}
+
+// newDetailsData returns the details of the process p, with the content of
+// the tab selected in query. The environment, files, and sockets tabs are only
+// available when the inspector is a [plib.ResourceInspector].
+func newDetailsData(inspector plib.Inspector, p *plib.Process, query url.Values) DetailsData {
+	data := DetailsData{Process: p, Tab: statTab}
+	tabs := []Option{{Value: statTab, Label: "Stat"}}
+	ri, ok := inspector.(plib.ResourceInspector)
+	if ok {
+		tabs = append(tabs,
+			Option{Value: envTab, Label: "Environment"},
+			Option{Value: filesTab, Label: "Files"},
+			Option{Value: socketsTab, Label: "Sockets"},
+		)
+	}
+	for i := range tabs {
+		if tabs[i].Value == query.Get(tabParam) {
+			data.Tab = tabs[i].Value
+		}
+	}
+	for i := range tabs {
+		tabs[i].Selected = tabs[i].Value == data.Tab
+	}
+	data.Tabs = tabs
+
+	var err error
+	switch data.Tab {
+	case envTab:
+		// secrets are masked even when the inspector wasn't configured to,
+		// since anyone who can reach the UI can read them.
+		data.Environment, err = ri.GetEnvironment(p.ID)
+		data.Environment = plib.RedactEnvironment(data.Environment)
+	case filesTab:
+		data.Files, err = ri.GetOpenFiles(p.ID)
+	case socketsTab:
+		data.Sockets, err = ri.GetSockets(p.ID)
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	return data
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/arctir/proctor/baseline"
+)
+
+const driftPath = "/drift"
+
+// DriftData is rendered by the page showing how the host's processes drift
+// from the configured baseline.
+type DriftData struct {
+	// The baseline evaluated against, nil when none is configured.
+	Baseline *baseline.Baseline
+	Report   baseline.Report
+}
+
+// handleDrift renders how the host's processes drift from the baseline set
+// in [UIConfig].
+func (ui *UI) handleDrift(w http.ResponseWriter, r *http.Request) {
+	data := DriftData{Baseline: ui.baseline}
+	if ui.baseline == nil {
+		ui.renderTemplate(w, viewDrift, data)
+		return
+	}
+	ui.refreshLock.Lock()
+	ps, err := ui.inspector.GetProcesses()
+	ui.refreshLock.Unlock()
+	if err != nil {
+		ui.writeFailure(w, err)
+		return
+	}
+	data.Report = ui.baseline.Evaluate(ps, time.Now())
+	ui.renderTemplate(w, viewDrift, data)
+}
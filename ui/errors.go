@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// httpError is an error rendered with a status code other than 500.
+type httpError struct {
+	code int
+	err  error
+}
+
+func (e httpError) Error() string {
+	return e.err.Error()
+}
+
+func (e httpError) Unwrap() error {
+	return e.err
+}
+
+// newHTTPError returns an error, formatted as with [fmt.Errorf], that
+// [UI.writeFailure] renders with the status code.
+func newHTTPError(code int, format string, a ...any) error {
+	return httpError{code: code, err: fmt.Errorf(format, a...)}
+}
+
+// errorStatus returns the status code err should be rendered with, which is
+// 500 unless err is, or wraps, an [httpError].
+func errorStatus(err error) int {
+	var he httpError
+	if errors.As(err, &he) {
+		return he.code
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorData is rendered by the error page.
+type ErrorData struct {
+	Code   int
+	Status string
+	Error  string
+}
+
+// writeFailure renders the error page for err with the status code from
+// [errorStatus].
+func (ui *UI) writeFailure(w http.ResponseWriter, err error) {
+	code := errorStatus(err)
+	t, terr := ui.createTemplate(errorView)
+	if terr != nil {
+		// the error template may have been overridden with a broken one.
+		http.Error(w, err.Error(), code)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	t.Execute(w, ErrorData{Code: code, Status: http.StatusText(code), Error: err.Error()})
+}
+
+// recoverPanics returns h, rendering the error page rather than dropping the
+// connection when a request panics. The panic and its stack are logged.
+func (ui *UI) recoverPanics(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			// http.ErrAbortHandler is how handlers abort a response on
+			// purpose, so it's left to the server.
+			if v == nil || v == http.ErrAbortHandler {
+				if v != nil {
+					panic(v)
+				}
+				return
+			}
+			slog.Error("panic serving request", "method", r.Method, "path", r.URL.Path, "panic", v, "stack", string(debug.Stack()))
+			ui.writeFailure(w, fmt.Errorf("an unexpected error occurred serving %s", r.URL.Path))
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+func TestHandlerErrorStatus(t *testing.T) {
+	ui := &UI{inspector: &stubInspector{ps: plib.Processes{
+		1: {ID: 1, ParentProcess: 0, CommandName: "init"},
+	}}}
+	tests := []struct {
+		path    string
+		handler http.HandlerFunc
+		code    int
+	}{
+		{"/", ui.handleAllProcesses, http.StatusOK},
+		{"/missing", ui.handleAllProcesses, http.StatusNotFound},
+		{processesPath + "1", ui.handleProcessDetails, http.StatusOK},
+		{processesPath + "abc", ui.handleProcessDetails, http.StatusBadRequest},
+		{processesPath + "999", ui.handleProcessDetails, http.StatusNotFound},
+		{processesTreePath + "999", ui.handleProcessTree, http.StatusNotFound},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		test.handler(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.code {
+			t.Logf("fail: expected %s to respond %d, got %d", test.path, test.code, w.Code)
+			t.Fail()
+		}
+		if w.Code != http.StatusOK && !strings.Contains(w.Body.String(), http.StatusText(w.Code)) {
+			t.Logf("fail: expected the error page for %s, got %s", test.path, w.Body.String())
+			t.Fail()
+		}
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	ui := &UI{}
+	h := ui.recoverPanics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ps plib.Processes
+		_ = *ps[1]
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", processesPath+"1", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("fail: expected a panic to respond 500, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "unexpected error") {
+		t.Logf("fail: expected the error page, got %s", w.Body.String())
+		t.Fail()
+	}
+}
+
+func TestGetProcessHierarchyMissing(t *testing.T) {
+	// a process that exited between listing and rendering isn't found.
+	ps := plib.Processes{1: {ID: 1}}
+	if h := getProcessHierarchy(ps, 2); len(h) != 0 {
+		t.Logf("fail: expected no hierarchy, got %v", h)
+		t.Fail()
+	}
+	if d := getProcessDescendants(ps, 2); len(d.Children) != 0 {
+		t.Logf("fail: expected no descendants, got %v", d)
+		t.Fail()
+	}
+}
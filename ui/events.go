@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	eventsPath = "/events"
+	// the number of events buffered per client before further events are
+	// dropped for it.
+	eventBufferSize = 64
+)
+
+// processEventData is the data sent to clients for a [plib.ProcessEvent].
+type processEventData struct {
+	ID          int
+	CommandName string
+	CommandPath string
+	BinarySHA   string
+}
+
+// eventHub fans out process events to every connected client.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan plib.ProcessEvent]struct{}
+	// closed when the server is shutting down, ending every stream.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		subscribers: map[chan plib.ProcessEvent]struct{}{},
+		done:        make(chan struct{}),
+	}
+}
+
+// close ends the streams of every client. Streams would otherwise keep the
+// server from shutting down until they time out.
+func (h *eventHub) close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+func (h *eventHub) subscribe() chan plib.ProcessEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan plib.ProcessEvent, eventBufferSize)
+	h.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch chan plib.ProcessEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, ch)
+}
+
+// publish sends e to every subscriber. Slow subscribers with full buffers
+// miss the event rather than holding up others.
+func (h *eventHub) publish(e plib.ProcessEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// run publishes events until the channel is closed.
+func (h *eventHub) run(events <-chan plib.ProcessEvent) {
+	for e := range events {
+		h.publish(e)
+	}
+}
+
+// handleEvents streams process events to the client as [server-sent events],
+// named after the event type (started or exited) with the process as JSON
+// data.
+//
+// [server-sent events]: https://html.spec.whatwg.org/multipage/server-sent-events.html
+func (ui *UI) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	ch := ui.events.subscribe()
+	defer ui.events.unsubscribe(ch)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ui.events.done:
+			return
+		case e := <-ch:
+			data, err := json.Marshal(processEventData{
+				ID:          e.Process.ID,
+				CommandName: e.Process.CommandName,
+				CommandPath: e.Process.CommandPath,
+				BinarySHA:   e.Process.BinarySHA,
+			})
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	exportPath = "/export"
+	// the query parameter setting the format processes are exported in, json
+	// or csv.
+	formatParam = "format"
+)
+
+// handleExport downloads the processes, filtered by the search query as they
+// are on the all processes page, in the format requested. The formats match
+// the output of `proctor process ls -o json|csv`.
+func (ui *UI) handleExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var write func(w io.Writer, ps plib.Processes) error
+	var contentType string
+	format := q.Get(formatParam)
+	switch format {
+	case "json":
+		write, contentType = plib.WriteProcessesJSON, "application/json"
+	case "csv":
+		write, contentType = plib.WriteProcessesCSV, "text/csv"
+	default:
+		http.Error(w, fmt.Sprintf("format (%s) is not supported, expected json or csv", format), http.StatusBadRequest)
+		return
+	}
+
+	ui.refreshLock.Lock()
+	ps, err := ui.inspector.GetProcesses()
+	ui.refreshLock.Unlock()
+	if err != nil {
+		ui.writeFailure(w, err)
+		return
+	}
+	// the processes are written to a buffer first, so a failure can still be
+	// reported with a failure page.
+	var out bytes.Buffer
+	if err := write(&out, filterProcesses(ps, strings.TrimSpace(q.Get(searchParam)))); err != nil {
+		ui.writeFailure(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"processes.%s\"", format))
+	w.Write(out.Bytes())
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+func TestHandleExport(t *testing.T) {
+	ui := &UI{inspector: &stubInspector{ps: plib.Processes{
+		1:  {ID: 1, CommandName: "systemd", BinarySHA: "aa11"},
+		42: {ID: 42, ParentProcess: 1, CommandName: "containerd", BinarySHA: "bb22"},
+	}}}
+	tests := map[string]struct {
+		code        int
+		contentType string
+		body        string
+	}{
+		"/export?format=csv&q=containerd": {http.StatusOK, "text/csv", "pid,ppid,name,path,args,sha,kernel,has_permission\n42,1,containerd,,,bb22,false,false\n"},
+		"/export?format=xml":              {http.StatusBadRequest, "", ""},
+	}
+	for target, expected := range tests {
+		w := httptest.NewRecorder()
+		ui.handleExport(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != expected.code {
+			t.Logf("fail: %s expected status %d, actual: %d", target, expected.code, w.Code)
+			t.Fail()
+			continue
+		}
+		if expected.body != "" && (w.Header().Get("Content-Type") != expected.contentType || w.Body.String() != expected.body) {
+			t.Logf("fail: %s expected %s:\n%s\nactual %s:\n%s", target, expected.contentType, expected.body, w.Header().Get("Content-Type"), w.Body.String())
+			t.Fail()
+		}
+	}
+
+	w := httptest.NewRecorder()
+	ui.handleExport(w, httptest.NewRequest("GET", "/export?format=json", nil))
+	if w.Header().Get("Content-Disposition") != `attachment; filename="processes.json"` {
+		t.Logf("fail: expected the JSON to be downloaded, actual disposition: %s", w.Header().Get("Content-Disposition"))
+		t.Fail()
+	}
+}
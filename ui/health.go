@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	healthPath    = "/healthz"
+	readinessPath = "/readyz"
+)
+
+// handleHealth reports that the server is running.
+func (ui *UI) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok\n"))
+}
+
+// handleReadiness reports whether the server is ready to serve requests. It
+// becomes ready once processes have been loaded and stops being ready when
+// the server begins shutting down, so load balancers stop sending it traffic
+// while connections drain.
+func (ui *UI) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	if atomic.LoadInt32(&ui.ready) == 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("not ready\n"))
+		return
+	}
+	w.Write([]byte("ok\n"))
+}
+
+func (ui *UI) setReady(ready bool) {
+	var v int32
+	if ready {
+		v = 1
+	}
+	atomic.StoreInt32(&ui.ready, v)
+}
+
+// accessLogEntry is a line of the access log, written as JSON.
+type accessLogEntry struct {
+	Time     time.Time `json:"time"`
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	Query    string    `json:"query,omitempty"`
+	Status   int       `json:"status"`
+	Bytes    int       `json:"bytes"`
+	Duration float64   `json:"duration_ms"`
+	Remote   string    `json:"remote"`
+	Agent    string    `json:"user_agent,omitempty"`
+}
+
+// accessLog returns h, writing a JSON line describing every request it serves
+// to w.
+func accessLog(w io.Writer, h http.Handler) http.Handler {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: rw, code: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(accessLogEntry{
+			Time:     start.UTC(),
+			Method:   r.Method,
+			Path:     r.URL.Path,
+			Query:    r.URL.RawQuery,
+			Status:   rec.code,
+			Bytes:    rec.bytes,
+			Duration: float64(time.Since(start).Microseconds()) / 1000,
+			Remote:   r.RemoteAddr,
+			Agent:    r.UserAgent(),
+		})
+	})
+}
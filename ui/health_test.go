@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleReadiness(t *testing.T) {
+	ui := &UI{}
+	w := httptest.NewRecorder()
+	ui.handleReadiness(w, httptest.NewRequest("GET", readinessPath, nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Logf("fail: expected not ready before serving, actual status: %d", w.Code)
+		t.Fail()
+	}
+	ui.setReady(true)
+	w = httptest.NewRecorder()
+	ui.handleReadiness(w, httptest.NewRequest("GET", readinessPath, nil))
+	if w.Code != http.StatusOK {
+		t.Logf("fail: expected ready, actual status: %d", w.Code)
+		t.Fail()
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	h := accessLog(&buf, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	r := httptest.NewRequest("GET", "/process/9?tab=env", nil)
+	r.Header.Set("User-Agent", "kube-probe/1.27")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entry accessLogEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("fail: access log wasn't a JSON line: %q", buf.String())
+	}
+	if entry.Method != "GET" || entry.Path != "/process/9" || entry.Query != "tab=env" || entry.Status != http.StatusNotFound || entry.Bytes == 0 || entry.Agent != "kube-probe/1.27" {
+		t.Logf("fail: access log entry was wrong: %+v", entry)
+		t.Fail()
+	}
+}
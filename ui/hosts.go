@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	// apiProcessesPath serves the host's processes as JSON, which is how the
+	// UI retrieves the processes of its agents.
+	apiProcessesPath = "/api/processes"
+	hostsPath        = "/hosts"
+	// the query parameter selecting the host shown on the hosts page.
+	hostParam = "host"
+	// the name of the host serving the UI on the hosts page.
+	localHost = "local"
+	// how long an agent is given to respond.
+	agentTimeout = 10 * time.Second
+)
+
+// Snapshot is the processes of a host at a point in time, as served on
+// [apiProcessesPath].
+type Snapshot struct {
+	Host        string
+	LastRefresh time.Time
+	Processes   []*plib.Process
+}
+
+// HostSummary describes a host on the hosts page.
+type HostSummary struct {
+	Name string
+	// The URL of the host's UI, empty for the host serving the page.
+	URL         string
+	Processes   int
+	LastRefresh time.Time
+	// Set when the host's processes couldn't be retrieved.
+	Error string
+}
+
+// HostProcess is a process of a host on the hosts page.
+type HostProcess struct {
+	Host    HostSummary
+	Process *plib.Process
+}
+
+// HostsData is rendered by the hosts page.
+type HostsData struct {
+	Hosts []HostSummary
+	// The host selected, or empty when the processes of every host are
+	// combined.
+	Host        string
+	HostOptions []Option
+	Query       string
+	Processes   []HostProcess
+}
+
+// handleAPIProcesses serves the processes of the host as a [Snapshot].
+func (ui *UI) handleAPIProcesses(w http.ResponseWriter, r *http.Request) {
+	ui.refreshLock.Lock()
+	ps, err := ui.inspector.GetProcesses()
+	lastRefresh := ui.inspector.GetLastLoadTime()
+	ui.refreshLock.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	host, _ := os.Hostname()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Snapshot{
+		Host:        host,
+		LastRefresh: lastRefresh,
+		Processes:   sortProcesses(ps, defaultSort, false, nil),
+	})
+}
+
+// handleHosts renders a summary of this host and every agent, along with the
+// processes of the selected host or, when none is selected, of every host.
+func (ui *UI) handleHosts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := HostsData{Host: q.Get(hostParam), Query: strings.TrimSpace(q.Get(searchParam))}
+	data.HostOptions = []Option{{Value: "", Label: "All hosts", Selected: data.Host == ""}}
+
+	snapshots, errs := ui.getSnapshots()
+	for i, s := range snapshots {
+		summary := HostSummary{Name: s.Host, Processes: len(s.Processes), LastRefresh: s.LastRefresh}
+		if i > 0 {
+			summary.URL = ui.agents[i-1]
+		}
+		if errs[i] != nil {
+			summary.Error = errs[i].Error()
+		}
+		data.Hosts = append(data.Hosts, summary)
+		data.HostOptions = append(data.HostOptions, Option{Value: summary.Name, Label: summary.Name, Selected: summary.Name == data.Host})
+		if data.Host != "" && data.Host != summary.Name {
+			continue
+		}
+		ps := plib.Processes{}
+		for _, p := range s.Processes {
+			ps[p.ID] = p
+		}
+		for _, p := range sortProcesses(filterProcesses(ps, data.Query), defaultSort, false, nil) {
+			data.Processes = append(data.Processes, HostProcess{Host: summary, Process: p})
+		}
+	}
+	ui.renderTemplate(w, viewHosts, data)
+}
+
+// getSnapshots returns the processes of this host, named [localHost], followed
+// by those of each agent in the order they're configured. Agents are queried
+// concurrently. The error of each snapshot is at the same index.
+func (ui *UI) getSnapshots() ([]Snapshot, []error) {
+	snapshots := make([]Snapshot, len(ui.agents)+1)
+	errs := make([]error, len(ui.agents)+1)
+
+	ui.refreshLock.Lock()
+	ps, err := ui.inspector.GetProcesses()
+	snapshots[0] = Snapshot{Host: localHost, LastRefresh: ui.inspector.GetLastLoadTime()}
+	ui.refreshLock.Unlock()
+	errs[0] = err
+	for _, p := range ps {
+		snapshots[0].Processes = append(snapshots[0].Processes, p)
+	}
+
+	client := ui.agentClient
+	if client == nil {
+		client = &http.Client{Timeout: agentTimeout}
+	}
+	var wg sync.WaitGroup
+	for i, agent := range ui.agents {
+		wg.Add(1)
+		go func(i int, agent string) {
+			defer wg.Done()
+			snapshots[i+1], errs[i+1] = getAgentSnapshot(client, agent)
+			if snapshots[i+1].Host == "" {
+				snapshots[i+1].Host = agent
+			}
+		}(i, agent)
+	}
+	wg.Wait()
+
+	// agents reporting the same hostname are told apart by their URL.
+	seen := map[string]bool{}
+	for i := range snapshots {
+		if seen[snapshots[i].Host] && i > 0 {
+			snapshots[i].Host = ui.agents[i-1]
+		}
+		seen[snapshots[i].Host] = true
+	}
+	return snapshots, errs
+}
+
+// getAgentSnapshot retrieves the processes of the proctor UI served at
+// agentURL.
+func getAgentSnapshot(client *http.Client, agentURL string) (Snapshot, error) {
+	res, err := client.Get(agentURL + apiProcessesPath)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed retrieving processes from agent: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Snapshot{}, fmt.Errorf("failed retrieving processes from agent: %s", res.Status)
+	}
+	var s Snapshot
+	if err := json.NewDecoder(res.Body).Decode(&s); err != nil {
+		return Snapshot{}, fmt.Errorf("failed decoding processes from agent: %s", err)
+	}
+	sort.Slice(s.Processes, func(i, j int) bool { return s.Processes[i].ID < s.Processes[j].ID })
+	return s, nil
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/plib"
+)
+
+func TestHandleHosts(t *testing.T) {
+	agent := &UI{inspector: &stubInspector{ps: plib.Processes{
+		7: {ID: 7, CommandName: "postgres", Type: "linux", OSSpecific: plib.ProcessStat{State: "S"}},
+	}}}
+	agentServer := httptest.NewServer(http.HandlerFunc(agent.handleAPIProcesses))
+	defer agentServer.Close()
+
+	ui := &UI{
+		inspector: &stubInspector{ps: plib.Processes{1: {ID: 1, CommandName: "systemd"}}},
+		agents:    []string{agentServer.URL, "http://127.0.0.1:1"},
+	}
+	snapshots, errs := ui.getSnapshots()
+	if len(snapshots) != 3 || errs[0] != nil || errs[1] != nil || errs[2] == nil {
+		t.Fatalf("fail: expected the local and first agent's snapshots to succeed, errors: %v", errs)
+	}
+	if p := snapshots[1].Processes; len(p) != 1 || p[0].CommandName != "postgres" {
+		t.Fatalf("fail: agent's processes were wrong: %v", p)
+	}
+	if _, ok := snapshots[1].Processes[0].OSSpecific.(plib.ProcessStat); !ok {
+		t.Logf("fail: expected the agent's process details to be decoded as a ProcessStat")
+		t.Fail()
+	}
+
+	w := httptest.NewRecorder()
+	ui.handleHosts(w, httptest.NewRequest("GET", hostsPath, nil))
+	body := w.Body.String()
+	for _, expected := range []string{">systemd</a>", ">postgres</a>", agentServer.URL + "/process/7", "failed retrieving processes from agent"} {
+		if !strings.Contains(body, expected) {
+			t.Logf("fail: expected the combined view to contain %q", expected)
+			t.Fail()
+		}
+	}
+
+	// selecting a host shows only its processes.
+	w = httptest.NewRecorder()
+	ui.handleHosts(w, httptest.NewRequest("GET", hostsPath+"?host="+localHost, nil))
+	if body := w.Body.String(); !strings.Contains(body, ">systemd</a>") || strings.Contains(body, ">postgres</a>") {
+		t.Log("fail: expected only the local host's processes")
+		t.Fail()
+	}
+}
+
+func TestGetSnapshotsAgentClient(t *testing.T) {
+	agent := &UI{inspector: &stubInspector{ps: plib.Processes{7: {ID: 7, CommandName: "postgres"}}}}
+	agentServer := httptest.NewTLSServer(http.HandlerFunc(agent.handleAPIProcesses))
+	defer agentServer.Close()
+
+	ui := &UI{
+		inspector: &stubInspector{ps: plib.Processes{1: {ID: 1, CommandName: "systemd"}}},
+		agents:    []string{agentServer.URL},
+	}
+	if _, errs := ui.getSnapshots(); errs[1] == nil {
+		t.Log("fail: expected an error retrieving from an agent whose certificate isn't trusted")
+		t.Fail()
+	}
+	// the configured client trusts the agent's certificate.
+	ui.agentClient = agentServer.Client()
+	if snapshots, errs := ui.getSnapshots(); errs[1] != nil || len(snapshots[1].Processes) != 1 {
+		t.Logf("fail: unexpected error retrieving from an agent with the configured client: %v", errs[1])
+		t.Fail()
+	}
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const metricsPath = "/metrics"
+
+// metrics records the measurements exposed on [metricsPath] in the
+// [Prometheus text format].
+//
+// [Prometheus text format]: https://prometheus.io/docs/instrumenting/exposition_formats/#text-based-format
+type metrics struct {
+	mu sync.Mutex
+	// the number and total duration of process scans, and the number that
+	// failed.
+	scans        int
+	scanSeconds  float64
+	scanFailures int
+	// requests served, keyed by handler then status code.
+	requests map[string]map[int]int
+	// the number and total duration of requests served, keyed by handler.
+	requestCount   map[string]int
+	requestSeconds map[string]float64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:       map[string]map[int]int{},
+		requestCount:   map[string]int{},
+		requestSeconds: map[string]float64{},
+	}
+}
+
+func (m *metrics) observeScan(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scans++
+	m.scanSeconds += d.Seconds()
+	if err != nil {
+		m.scanFailures++
+	}
+}
+
+func (m *metrics) observeRequest(handler string, code int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.requests[handler] == nil {
+		m.requests[handler] = map[int]int{}
+	}
+	m.requests[handler][code]++
+	m.requestCount[handler]++
+	m.requestSeconds[handler] += d.Seconds()
+}
+
+// instrument returns h, recording the status and duration of every request it
+// serves under the handler label.
+func (m *metrics) instrument(handler string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		h(rec, r)
+		m.observeRequest(handler, rec.code, time.Since(start))
+	}
+}
+
+// write writes the metrics, along with the count of processes by state, in the
+// Prometheus text format.
+func (m *metrics) write(w io.Writer, ps plib.Processes) {
+	states := map[string]int{}
+	for _, p := range ps {
+		if s, ok := p.OSSpecific.(plib.ProcessStat); ok && s.State != "" {
+			states[s.State]++
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fmt.Fprintln(w, "# HELP proctor_processes The number of processes on the host.")
+	fmt.Fprintln(w, "# TYPE proctor_processes gauge")
+	fmt.Fprintf(w, "proctor_processes %d\n", len(ps))
+	fmt.Fprintln(w, "# HELP proctor_processes_by_state The number of processes in each state.")
+	fmt.Fprintln(w, "# TYPE proctor_processes_by_state gauge")
+	for _, state := range sortedKeys(states) {
+		fmt.Fprintf(w, "proctor_processes_by_state{state=%q} %d\n", state, states[state])
+	}
+	fmt.Fprintln(w, "# HELP proctor_scan_duration_seconds The time spent scanning the host's processes.")
+	fmt.Fprintln(w, "# TYPE proctor_scan_duration_seconds summary")
+	fmt.Fprintf(w, "proctor_scan_duration_seconds_sum %s\n", formatFloat(m.scanSeconds))
+	fmt.Fprintf(w, "proctor_scan_duration_seconds_count %d\n", m.scans)
+	fmt.Fprintln(w, "# HELP proctor_scan_failures_total The number of process scans that failed.")
+	fmt.Fprintln(w, "# TYPE proctor_scan_failures_total counter")
+	fmt.Fprintf(w, "proctor_scan_failures_total %d\n", m.scanFailures)
+	fmt.Fprintln(w, "# HELP proctor_http_requests_total The number of HTTP requests served by handler and status code.")
+	fmt.Fprintln(w, "# TYPE proctor_http_requests_total counter")
+	for _, handler := range sortedKeys(m.requests) {
+		codes := []int{}
+		for code := range m.requests[handler] {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "proctor_http_requests_total{handler=%q,code=\"%d\"} %d\n", handler, code, m.requests[handler][code])
+		}
+	}
+	fmt.Fprintln(w, "# HELP proctor_http_request_duration_seconds The time spent serving HTTP requests by handler.")
+	fmt.Fprintln(w, "# TYPE proctor_http_request_duration_seconds summary")
+	for _, handler := range sortedKeys(m.requestCount) {
+		fmt.Fprintf(w, "proctor_http_request_duration_seconds_sum{handler=%q} %s\n", handler, formatFloat(m.requestSeconds[handler]))
+		fmt.Fprintf(w, "proctor_http_request_duration_seconds_count{handler=%q} %d\n", handler, m.requestCount[handler])
+	}
+}
+
+// handleMetrics serves the metrics in the Prometheus text format.
+func (ui *UI) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	ui.refreshLock.Lock()
+	ps, err := ui.inspector.GetProcesses()
+	ui.refreshLock.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	ui.metrics.write(w, ps)
+}
+
+// statusRecorder records the status code and number of bytes written to a
+// response.
+type statusRecorder struct {
+	http.ResponseWriter
+	code  int
+	bytes int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Flush is passed through so event streams can be served.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// timedInspector records the duration of every process scan, made with
+// LoadProcesses, of the Inspector it embeds.
+type timedInspector struct {
+	plib.Inspector
+	metrics *metrics
+}
+
+func (t timedInspector) LoadProcesses() error {
+	start := time.Now()
+	err := t.Inspector.LoadProcesses()
+	t.metrics.observeScan(time.Since(start), err)
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
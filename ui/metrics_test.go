@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arctir/proctor/plib"
+)
+
+func TestHandleMetrics(t *testing.T) {
+	inspector := &stubInspector{ps: plib.Processes{
+		1:  {ID: 1, OSSpecific: plib.ProcessStat{State: "S"}},
+		42: {ID: 42, OSSpecific: plib.ProcessStat{State: "S"}},
+		50: {ID: 50, OSSpecific: plib.ProcessStat{State: "R"}},
+	}}
+	ui := &UI{inspector: inspector, metrics: newMetrics()}
+
+	scanner := timedInspector{Inspector: inspector, metrics: ui.metrics}
+	scanner.LoadProcesses()
+	ui.metrics.observeScan(time.Second, fmt.Errorf("failed"))
+	missing := ui.metrics.instrument("/process/", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	missing(httptest.NewRecorder(), httptest.NewRequest("GET", "/process/9", nil))
+
+	w := httptest.NewRecorder()
+	ui.handleMetrics(w, httptest.NewRequest("GET", metricsPath, nil))
+	body := w.Body.String()
+	for _, expected := range []string{
+		"proctor_processes 3\n",
+		`proctor_processes_by_state{state="R"} 1` + "\n",
+		`proctor_processes_by_state{state="S"} 2` + "\n",
+		"proctor_scan_duration_seconds_count 2\n",
+		"proctor_scan_failures_total 1\n",
+		`proctor_http_requests_total{handler="/process/",code="404"} 1` + "\n",
+		`proctor_http_request_duration_seconds_count{handler="/process/"} 1` + "\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Logf("fail: expected metrics to contain %q, actual:\n%s", expected, body)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/arctir/proctor/platforms"
+	"github.com/arctir/proctor/source"
+)
+
+const (
+	sourcePath          = "/source"
+	sourceCommitsPath   = "/source/commits"
+	sourceArtifactsPath = "/source/artifacts"
+	// the query parameters of the source pages: the repository's URL, the tag
+	// a release or range of commits ends at, and the tag a range of commits
+	// starts from.
+	repoParam = "repo"
+	toParam   = "to"
+	fromParam = "from"
+)
+
+// SourceData is rendered by the source page, listing a repository's tags and
+// releases.
+type SourceData struct {
+	Repo string
+	// The repository's tags, most recent first.
+	Tags     []source.Tag
+	Releases []platforms.Release
+	// Set when the repository couldn't be retrieved.
+	Error string
+	// Set when the releases couldn't be retrieved, such as when the platform
+	// hosting the repository isn't supported.
+	ReleasesError string
+}
+
+// CommitsData is rendered by the page listing the commits between two tags.
+type CommitsData struct {
+	Repo    string
+	From    string
+	To      string
+	Commits []source.Commit
+	Error   string
+}
+
+// ArtifactsData is rendered by the page listing the artifacts of a release.
+type ArtifactsData struct {
+	Repo      string
+	Tag       string
+	Artifacts []platforms.Artifact
+	Error     string
+}
+
+// handleSource renders a form to enter a repository's URL and, once entered,
+// the repository's tags and releases.
+func (ui *UI) handleSource(w http.ResponseWriter, r *http.Request) {
+	data := SourceData{Repo: strings.TrimSpace(r.URL.Query().Get(repoParam))}
+	if err := ui.checkRepoURL(data.Repo); err != nil {
+		data.Error = err.Error()
+	} else if data.Repo != "" {
+		tags, err := ui.getTags(r.Context(), data.Repo)
+		if err != nil {
+			data.Error = err.Error()
+		}
+		data.Tags = tags
+		data.Releases, err = getReleases(data.Repo)
+		if err != nil {
+			data.ReleasesError = err.Error()
+		}
+	}
+	ui.renderTemplate(w, viewSource, data)
+}
+
+// handleSourceCommits renders the commits introduced by the tag in the to
+// query parameter, since the tag in from. When from isn't set, the tag
+// preceding to by semantic version is used.
+func (ui *UI) handleSourceCommits(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := CommitsData{Repo: q.Get(repoParam), From: q.Get(fromParam), To: q.Get(toParam)}
+	err := ui.checkRepoURL(data.Repo)
+	if err == nil {
+		data.Commits, data.From, err = ui.getCommitsBetween(r.Context(), data.Repo, data.From, data.To)
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	ui.renderTemplate(w, viewSourceCommits, data)
+}
+
+// handleSourceArtifacts renders the artifacts of the release tagged with the
+// to query parameter.
+func (ui *UI) handleSourceArtifacts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data := ArtifactsData{Repo: q.Get(repoParam), Tag: q.Get(toParam)}
+	err := ui.checkRepoURL(data.Repo)
+	if err == nil {
+		var platform platforms.Platform
+		var repo string
+		platform, repo, err = platforms.ForURL(data.Repo)
+		if err == nil {
+			data.Artifacts, err = platform.GetArtifacts(repo, data.Tag)
+		}
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	ui.renderTemplate(w, viewSourceArtifacts, data)
+}
+
+// checkRepoURL returns an error unless the source pages are enabled and
+// rawURL, when set, is an HTTPS URL of a repository on one of the hosts they
+// may browse. Otherwise, visitors could have the UI clone local paths or
+// repositories on internal hosts.
+func (ui *UI) checkRepoURL(rawURL string) error {
+	if len(ui.sourceHosts) == 0 {
+		return fmt.Errorf("browsing repositories is disabled; start the UI with --source-host to enable it")
+	}
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Host == "" {
+		return fmt.Errorf("repository (%s) must be an https URL, such as https://github.com/arctir/proctor", rawURL)
+	}
+	for _, h := range ui.sourceHosts {
+		if strings.EqualFold(u.Host, h) {
+			return nil
+		}
+	}
+	return fmt.Errorf("repositories on %s may not be browsed; the allowed hosts are: %s", u.Host, strings.Join(ui.sourceHosts, ", "))
+}
+
+// resolveRepo clones, or fetches the latest changes of, the repository at
+// url, stopping when ctx is done or the UI's source timeout elapses. Each
+// repository is resolved one at a time, as concurrent fetches of the same
+// cached repository would conflict, and the returned function must be called
+// to close and release it once done with it.
+func (ui *UI) resolveRepo(ctx context.Context, url string) (*source.Repository, func(), error) {
+	ui.sourceLock.Lock()
+	if ui.repoLocks == nil {
+		ui.repoLocks = map[string]*sync.Mutex{}
+	}
+	lock, ok := ui.repoLocks[url]
+	if !ok {
+		lock = &sync.Mutex{}
+		ui.repoLocks[url] = lock
+	}
+	ui.sourceLock.Unlock()
+
+	lock.Lock()
+	repo, err := source.ResolveRepoContext(ctx, url, source.ResolveRepoOpts{Timeout: ui.sourceTimeout})
+	if err != nil {
+		lock.Unlock()
+		return nil, nil, err
+	}
+	release := func() {
+		repo.Close()
+		lock.Unlock()
+	}
+	return repo, release, nil
+}
+
+// getTags returns the tags of the repository at url, most recent first.
+func (ui *UI) getTags(ctx context.Context, url string) ([]source.Tag, error) {
+	repo, release, err := ui.resolveRepo(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	gm := source.NewGitManager()
+	tags, err := gm.GetTagsFromRepository(*repo)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Date.After(tags[j].Date) })
+	return tags, nil
+}
+
+// getCommitsBetween returns the commits reachable from the tag to that aren't
+// reachable from the tag from, along with the tag used as from, which is the
+// tag preceding to when from is empty.
+func (ui *UI) getCommitsBetween(ctx context.Context, url, from, to string) ([]source.Commit, string, error) {
+	repo, release, err := ui.resolveRepo(ctx, url)
+	if err != nil {
+		return nil, from, err
+	}
+	defer release()
+	gm := source.NewGitManager()
+	if from == "" {
+		tags, err := gm.GetTagsFromRepository(*repo)
+		if err != nil {
+			return nil, from, err
+		}
+		// when to is the first release, every commit reachable from it is
+		// listed.
+		if prev, err := source.GetPreviousTag(tags, to); err == nil {
+			from = prev.Name
+		}
+	}
+	commits, err := gm.GetCommitsBetween(*repo, from, to)
+	return commits, from, err
+}
+
+// getReleases returns the releases of the repository at url, as reported by
+// the platform hosting it.
+func getReleases(url string) ([]platforms.Release, error) {
+	platform, repo, err := platforms.ForURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return platform.GetReleases(repo)
+}
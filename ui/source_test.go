@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arctir/proctor/platforms"
+)
+
+// fakePlatform serves a single release of any repository.
+type fakePlatform struct{}
+
+func (fakePlatform) GetReleases(repo string) ([]platforms.Release, error) {
+	return []platforms.Release{{Name: "First", Tag: "v1.0.0"}}, nil
+}
+
+func (fakePlatform) GetArtifacts(repo string, tag string) ([]platforms.Artifact, error) {
+	if tag != "v1.0.0" {
+		return nil, fmt.Errorf("failed to find release with tag %s", tag)
+	}
+	return []platforms.Artifact{{Name: "proctor_linux_amd64.tar.gz", URL: "https://ui.example.com/a", Size: 1024, Downloads: 7}}, nil
+}
+
+func (fakePlatform) GetRepoMetadata(repo string) (platforms.RepoMetadata, error) {
+	return platforms.RepoMetadata{}, nil
+}
+
+func (fakePlatform) GetContributors(repo string) ([]platforms.Contributor, error) {
+	return nil, nil
+}
+
+func (fakePlatform) DownloadArtifact(a platforms.Artifact) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (fakePlatform) GetIssueStats(repo string) (platforms.IssueStats, error) {
+	return platforms.IssueStats{}, nil
+}
+
+func TestHandleSourceArtifacts(t *testing.T) {
+	platforms.Register("ui.example.com", func() platforms.Platform { return fakePlatform{} })
+	ui := &UI{sourceHosts: []string{"ui.example.com", "unknown.example.com"}}
+
+	tests := map[string]string{
+		"/source/artifacts?repo=https://ui.example.com/arctir/proctor&to=v1.0.0": ">proctor_linux_amd64.tar.gz</a></td>",
+		"/source/artifacts?repo=https://ui.example.com/arctir/proctor&to=v9.9.9": "failed to find release with tag v9.9.9",
+		"/source/artifacts?repo=https://unknown.example.com/arctir/proctor":      "is not supported",
+		"/source/artifacts?repo=https://internal.example.com/arctir/proctor":     "may not be browsed",
+		"/source/artifacts?repo=http://ui.example.com/arctir/proctor":            "must be an https URL",
+		"/source/artifacts?repo=/etc":                                            "must be an https URL",
+	}
+	for target, expected := range tests {
+		w := httptest.NewRecorder()
+		ui.handleSourceArtifacts(w, httptest.NewRequest("GET", target, nil))
+		if !strings.Contains(w.Body.String(), expected) {
+			t.Logf("fail: expected %s to render %q", target, expected)
+			t.Fail()
+		}
+	}
+
+	// without a repository, only the form is rendered.
+	w := httptest.NewRecorder()
+	ui.handleSource(w, httptest.NewRequest("GET", "/source", nil))
+	if body := w.Body.String(); !strings.Contains(body, `name="repo"`) || strings.Contains(body, "<h2>Tags</h2>") {
+		t.Log("fail: expected only the repository form to be rendered")
+		t.Fail()
+	}
+
+	// without allowed hosts, no repository is retrieved.
+	w = httptest.NewRecorder()
+	(&UI{}).handleSource(w, httptest.NewRequest("GET", "/source?repo=https://ui.example.com/arctir/proctor", nil))
+	if body := w.Body.String(); !strings.Contains(body, "browsing repositories is disabled") {
+		t.Log("fail: expected the source pages to be disabled without allowed hosts")
+		t.Fail()
+	}
+}
@@ -1,170 +1,96 @@
 package ui
 
-const uiHeader = `
-<html>
-	<head>
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+)
 
-	<style>
-		.buttons {
-			margin-bottom: 1rem;
-		}
-		button {
-			background-color: black;
-			color: white;
-			border: 1px solid black;
-			padding: 8px;
-			font-size: 16px;
-			cursor: pointer;
-		}
-		table {
-			border-collapse: collapse;
-			width: 100%;
-		}
-		th, td {
-			border: 1px solid black;
-			padding: 8px;
-			text-align: left;
-		}
-		th {
-			background-color: black;
-			color: white;
-		}
-		.tree-wrapper {
-			padding-top: 10px;
-		  }
-		  
-		  .tree-list {
-			list-style: none;
-			padding: 0;
-			margin: 0;
-		  }
-		  .tree-list .tree-item {
-			position: relative;
-			display: block;
-			min-height: 2em;
-			line-height: 2em;
-			margin-bottom: 10px;
-			padding-left: 21px;
-		  }
-		  .tree-list .tree-item:before, .tree-list .tree-item:after {
-			content: "";
-			position: absolute;
-			display: block;
-			background-color: #333;
-		  }
-		  .tree-list .tree-item:before {
-			top: 0;
-			left: 10px;
-			width: 1px;
-			height: calc(100% + 10px);
-		  }
-		  .tree-list .tree-item:after {
-			top: 1em;
-			left: 10px;
-			width: 11px;
-			height: 1px;
-		  }
-		  .tree-list .tree-item:last-child {
-			margin-bottom: 0;
-		  }
-		  .tree-list .tree-item:last-child:before {
-			height: 1em;
-		  }
-		  .tree-list .tree-item:first-child:before {
-			top: -10px;
-			height: calc(100% + 20px);
-		  }
-		  .tree-list .tree-item > span {
-			display: inline-block;
-			padding: 0 5px;
-			border: 1px solid #333;
-		  }
-		  .tree-list .tree-item > .tree-list {
-			padding-top: 10px;
-		  }
-		
-	</style>
-		<title>Procotor display</title>
-	</head>
-	<body>
-`
+// assets holds the page templates, in templates, and the files served under
+// [staticPath], such as the stylesheet, in static.
+//
+//go:embed templates static
+var assets embed.FS
+
+const (
+	staticPath = "/static/"
+	// the templates every page is wrapped with.
+	headerTemplate = "header.html"
+	footerTemplate = "footer.html"
+	// the templates of each page, found in the templates directory.
+	viewProcessDetails  = "details.html"
+	viewTreeDetails     = "tree.html"
+	allProcessesView    = "processes.html"
+	errorView           = "error.html"
+	viewSource          = "source.html"
+	viewSourceCommits   = "source_commits.html"
+	viewSourceArtifacts = "source_artifacts.html"
+	viewDiff            = "diff.html"
+	viewHosts           = "hosts.html"
+	viewAction          = "action.html"
+	viewCompare         = "compare.html"
+	viewDrift           = "drift.html"
+)
 
-const uiFooter = `
-	</body>
-</html>
-`
+// templateLoader creates page templates and serves static files from its
+// filesystem, which is laid out like [assets]. The zero value uses assets.
+type templateLoader struct {
+	fsys fs.FS
+}
 
-const viewProcessDetails = `
-		<div class="container">
-		<div class="buttons">
-			<a href="/"><button>All Processes</button></a>
-			<a href="/tree/{{ .ID }}"><button>Process Hierarchy</button></a>
-		</div>
-		<table>
-            <tr>
-                <th>Field</th>
-                <th>Value</th>
-            </tr>
-			{{range $idx, $value := . | pDeets }}
-            <tr>
-                <td>{{ $value.Field }}</td>
-                <td>{{ $value.Value }}</td>
-            </tr>
-			{{ end }}
-			</table>
-		</div>
-`
+// newTemplateLoader returns a templateLoader preferring the files in
+// overrideDir over the embedded assets. The directory is laid out the same,
+// such as templates/header.html or static/style.css, so only the files being
+// customized need to exist. When overrideDir is empty, only the embedded
+// assets are used.
+func newTemplateLoader(overrideDir string) templateLoader {
+	if overrideDir == "" {
+		return templateLoader{}
+	}
+	return templateLoader{fsys: overlayFS{override: os.DirFS(overrideDir), base: assets}}
+}
 
-const viewTreeDetails = `
-		<div class="container">
-		<div class="buttons">
-			<a href="/"><button>All Processes</button></a>
-		</div>
-			<div class="tree-wrapper">
+func (l templateLoader) files() fs.FS {
+	if l.fsys == nil {
+		return assets
+	}
+	return l.fsys
+}
+
+// create returns the template named name, wrapped with the header and footer
+// templates.
+func (l templateLoader) create(name string) (*template.Template, error) {
+	content := []byte{}
+	for _, n := range []string{headerTemplate, name, footerTemplate} {
+		b, err := fs.ReadFile(l.files(), "templates/"+n)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, b...)
+	}
+	return template.New(name).
+		Funcs(template.FuncMap{"pDeets": getProcessDetails}).
+		Parse(string(content))
+}
 
-		  	    {{ range $value := . }}
-				<ul class="tree-list">
-					<li class="tree-item has-sub">
-						<span><a href="/process/{{ .ID }}">{{ .CommandName }} ({{ .ID }})</a></span>
-				{{ end }}
-		  	    {{ range . }}
-					</ul>
-				</li>
-				{{ end }}
-			</div>
-		</div>
-`
+// static returns a handler serving the files in the static directory.
+func (l templateLoader) static() http.Handler {
+	sub, _ := fs.Sub(l.files(), "static")
+	return http.StripPrefix(staticPath, http.FileServer(http.FS(sub)))
+}
 
-const allProcessesView = `
-		<div class="container">
-		<div class="status">
-		 <p>Last Refreshed: {{ .LastRefresh }}</p>
-		</div>
-		<div class="buttons">
-			<a href="/refresh"><button>Refresh</button></a>
-		</div>
-		<table>
-            <tr>
-                <th>PID</th>
-                <th>Name</th>
-                <th>SHA</th>
-            </tr>
-			{{range $key, $value := .PS}}
-            <tr>
-                <td>{{$key}}</td>
-				<td><a href="process/{{$key}}">{{.CommandName}}</a></td>
-                <td>{{.BinarySHA}}</td>
-            </tr>
-            {{end}}
-			</table>
-		</div>
-`
+// overlayFS opens files from override when they exist there, otherwise from
+// base.
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
 
-const errorView = `
-		<div class="container">
-			<div class="status">
-			<h1>Failed creating requested page.</h1>
-			<p>Error details {{ . }}</p>
-			</div>
-		</div>
-`
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if f, err := o.override.Open(name); err == nil {
+		return f, nil
+	}
+	return o.base.Open(name)
+}
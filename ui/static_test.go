@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTemplateLoaderCreate(t *testing.T) {
+	l := templateLoader{fsys: fstest.MapFS{
+		"templates/header.html": {Data: []byte("<header>{{ .Title }}</header>")},
+		"templates/page.html":   {Data: []byte("<main>{{ .Body }}</main>")},
+		"templates/footer.html": {Data: []byte("<footer></footer>")},
+	}}
+	tmpl, err := l.create("page.html")
+	if err != nil {
+		t.Fatalf("failed creating template: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]string{"Title": "t", "Body": "b"}); err != nil {
+		t.Fatalf("failed executing template: %s", err)
+	}
+	expected := "<header>t</header><main>b</main><footer></footer>"
+	if buf.String() != expected {
+		t.Logf("expected %q, got %q", expected, buf.String())
+		t.Fail()
+	}
+
+	if _, err := l.create("missing.html"); err == nil {
+		t.Logf("expected an error creating a template that doesn't exist")
+		t.Fail()
+	}
+}
+
+func TestTemplateLoaderOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "templates"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "static"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	footer := "<footer>custom</footer></body></html>"
+	if err := os.WriteFile(filepath.Join(dir, "templates", footerTemplate), []byte(footer), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	style := "body { color: red; }"
+	if err := os.WriteFile(filepath.Join(dir, "static", "style.css"), []byte(style), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l := newTemplateLoader(dir)
+
+	// the overridden footer is used alongside the embedded header and page.
+	tmpl, err := l.create(errorView)
+	if err != nil {
+		t.Fatalf("failed creating template: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ErrorData{Error: "broken"}); err != nil {
+		t.Fatalf("failed executing template: %s", err)
+	}
+	out := buf.String()
+	if !strings.HasSuffix(out, footer) {
+		t.Logf("expected the overridden footer, got %s", out)
+		t.Fail()
+	}
+	if !strings.Contains(out, "/static/style.css") || !strings.Contains(out, "broken") {
+		t.Logf("expected the embedded header and error page, got %s", out)
+		t.Fail()
+	}
+
+	for file, expected := range map[string]string{"style.css": style} {
+		rec := httptest.NewRecorder()
+		l.static().ServeHTTP(rec, httptest.NewRequest("GET", staticPath+file, nil))
+		body, _ := io.ReadAll(rec.Body)
+		if rec.Code != 200 || string(body) != expected {
+			t.Logf("expected %s to be served as %q, got %d %q", file, expected, rec.Code, body)
+			t.Fail()
+		}
+	}
+}
+
+func TestTemplateLoaderStatic(t *testing.T) {
+	rec := httptest.NewRecorder()
+	templateLoader{}.static().ServeHTTP(rec, httptest.NewRequest("GET", staticPath+"style.css", nil))
+	if rec.Code != 200 {
+		t.Fatalf("expected the embedded stylesheet to be served, got %d", rec.Code)
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/css") {
+		t.Logf("expected a text/css content type, got %s", rec.Header().Get("Content-Type"))
+		t.Fail()
+	}
+}
@@ -0,0 +1,258 @@
+package ui
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/arctir/proctor/plib"
+)
+
+const (
+	// the query parameter setting what processes are sorted by, one of the
+	// keys of [processSorts].
+	sortParam = "sort"
+	// the query parameter setting the sort order, asc or desc.
+	orderParam = "order"
+	// the query parameter, repeated, enabling an optional column by key.
+	columnParam = "col"
+	// the query parameter setting the page of processes listed, starting at 1.
+	pageParam = "page"
+	// the query parameter setting how many processes are listed per page.
+	limitParam = "limit"
+	// the query parameter setting how often, in seconds, the page reloads
+	// itself. It isn't reloaded when unset.
+	intervalParam = "interval"
+	// the sort used when none is requested.
+	defaultSort = "pid"
+	// the number of processes listed per page when no limit is requested.
+	// Hosts with tens of thousands of processes would otherwise render
+	// multi-megabyte pages.
+	defaultPageSize = 500
+)
+
+// Option is a choice presented in a form, such as what to sort by.
+type Option struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+// Pagination describes the page of processes listed and links to its
+// neighbours, which are empty when there is no previous or next page.
+type Pagination struct {
+	Page    int
+	Pages   int
+	PrevURL string
+	NextURL string
+}
+
+// Column is an optional column of the process table.
+type Column struct {
+	Key   string
+	Title string
+	// Value returns the content of the column's cell for a process.
+	Value func(p *plib.Process) string
+	// When set, Usage returns the content of the column's cell from the
+	// process's sampled utilization, in place of Value.
+	Usage func(u plib.Utilization) string
+}
+
+// processSort describes how processes are ordered when sorting by a key.
+type processSort struct {
+	label string
+	less  plib.ProcessLess
+	// When set, processes are ordered by the value usage returns for their
+	// sampled utilization, in place of less.
+	usage func(u plib.Utilization) float64
+}
+
+var (
+	// the orders processes can be listed in, keyed by the value of
+	// [sortParam]. Sorts on values only known on Linux (e.g. rss) treat
+	// other processes as 0.
+	processSorts = map[string]processSort{
+		"pid":    {"PID", plib.ProcessSorts["pid"], nil},
+		"name":   {"Name", plib.ProcessSorts["name"], nil},
+		"rss":    {"Memory (RSS)", plib.ProcessSorts["rss"], nil},
+		"cpu":    {"CPU Time", plib.ProcessSorts["cpu"], nil},
+		"start":  {"Start Time", plib.ProcessSorts["start"], nil},
+		"cpupct": {"CPU %", nil, func(u plib.Utilization) float64 { return u.CPUPercent }},
+	}
+	// the order sorts are presented in.
+	processSortKeys = []string{"pid", "name", "rss", "cpu", "cpupct", "start"}
+
+	// the intervals, in seconds, the page can reload itself at.
+	refreshIntervals = []int{2, 5, 10, 30, 60}
+
+	// the columns that can be added to the process table, in the order they
+	// are displayed.
+	optionalColumns = []Column{
+		{"ppid", "Parent PID", func(p *plib.Process) string { return strconv.Itoa(p.ParentProcess) }, nil},
+		{"path", "Path", func(p *plib.Process) string { return p.CommandPath }, nil},
+		{"state", "State", func(p *plib.Process) string { return stat(p).State }, nil},
+		{"threads", "Threads", func(p *plib.Process) string { return strconv.Itoa(stat(p).ThreadQuantity) }, nil},
+		{"cpupct", "CPU %", nil, func(u plib.Utilization) string { return strconv.FormatFloat(u.CPUPercent, 'f', 1, 64) }},
+		{"mem", "Memory (RSS)", nil, func(u plib.Utilization) string { return formatBytes(u.RSSBytes) }},
+	}
+)
+
+// stat returns the Linux specific details of p, which are empty for processes
+// from other operating systems.
+func stat(p *plib.Process) plib.ProcessStat {
+	s, _ := p.OSSpecific.(plib.ProcessStat)
+	return s
+}
+
+// formatBytes returns n bytes in the largest binary unit it's at least one of,
+// such as 1.5 MiB.
+func formatBytes(n int) string {
+	const unit = 1024
+	if n < unit {
+		return strconv.Itoa(n) + " B"
+	}
+	div, exp := unit, 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+	return strconv.FormatFloat(float64(n)/float64(div), 'f', 1, 64) + " " + string("KMGTP"[exp]) + "iB"
+}
+
+// sortProcesses returns processes ordered by the sort with key, or by PID when
+// key is unknown. Processes that are equal by the sort are ordered by PID.
+// Sorts on utilization use the utilization sampled for each process, treating
+// processes without a sample as 0.
+func sortProcesses(processes plib.Processes, key string, desc bool, utilization map[int]plib.Utilization) []*plib.Process {
+	s, ok := processSorts[key]
+	if !ok {
+		s = processSorts[defaultSort]
+	}
+	if s.usage != nil {
+		s.less = func(a, b *plib.Process) bool {
+			return s.usage(utilization[a.ID]) < s.usage(utilization[b.ID])
+		}
+	}
+	return plib.SortProcesses(processes, s.less, desc)
+}
+
+// listingOptions holds how the process table is sorted, which optional
+// columns it shows, and which page it lists, as requested by the query
+// parameters in q.
+type listingOptions struct {
+	Sort    string
+	Desc    bool
+	Columns []Column
+	Page    int
+	Limit   int
+	// The seconds between reloads of the page, 0 when it isn't reloaded.
+	Interval int
+	query    url.Values
+}
+
+func newListingOptions(q url.Values) listingOptions {
+	opts := listingOptions{
+		Sort:  q.Get(sortParam),
+		Desc:  q.Get(orderParam) == "desc",
+		Page:  1,
+		Limit: defaultPageSize,
+		query: q,
+	}
+	if page, err := strconv.Atoi(q.Get(pageParam)); err == nil && page > 0 {
+		opts.Page = page
+	}
+	if limit, err := strconv.Atoi(q.Get(limitParam)); err == nil && limit > 0 {
+		opts.Limit = limit
+	}
+	if interval, err := strconv.Atoi(q.Get(intervalParam)); err == nil && interval > 0 {
+		opts.Interval = interval
+	}
+	if _, ok := processSorts[opts.Sort]; !ok {
+		opts.Sort = defaultSort
+	}
+	enabled := map[string]bool{}
+	for _, key := range q[columnParam] {
+		enabled[key] = true
+	}
+	for _, c := range optionalColumns {
+		if enabled[c.Key] {
+			opts.Columns = append(opts.Columns, c)
+		}
+	}
+	return opts
+}
+
+// sortOptions returns the sorts to present, with the current sort selected.
+func (o listingOptions) sortOptions() []Option {
+	options := []Option{}
+	for _, key := range processSortKeys {
+		options = append(options, Option{Value: key, Label: processSorts[key].label, Selected: key == o.Sort})
+	}
+	return options
+}
+
+// columnOptions returns the optional columns to present, with those shown
+// selected.
+func (o listingOptions) columnOptions() []Option {
+	shown := map[string]bool{}
+	for _, c := range o.Columns {
+		shown[c.Key] = true
+	}
+	options := []Option{}
+	for _, c := range optionalColumns {
+		options = append(options, Option{Value: c.Key, Label: c.Title, Selected: shown[c.Key]})
+	}
+	return options
+}
+
+// intervalOptions returns the reload intervals to present, with the current
+// interval selected.
+func (o listingOptions) intervalOptions() []Option {
+	options := []Option{{Value: "", Label: "Off", Selected: o.Interval == 0}}
+	for _, i := range refreshIntervals {
+		options = append(options, Option{Value: strconv.Itoa(i), Label: strconv.Itoa(i) + "s", Selected: i == o.Interval})
+	}
+	return options
+}
+
+// paginate returns the processes on the requested page, which is clamped to
+// the last page, and the pagination to render with them.
+func (o listingOptions) paginate(processes []*plib.Process) ([]*plib.Process, Pagination) {
+	pages := (len(processes) + o.Limit - 1) / o.Limit
+	if pages == 0 {
+		pages = 1
+	}
+	page := o.Page
+	if page > pages {
+		page = pages
+	}
+	start := (page - 1) * o.Limit
+	end := start + o.Limit
+	if end > len(processes) {
+		end = len(processes)
+	}
+
+	p := Pagination{Page: page, Pages: pages}
+	if page > 1 {
+		p.PrevURL = o.pageURL(page - 1)
+	}
+	if page < pages {
+		p.NextURL = o.pageURL(page + 1)
+	}
+	return processes[start:end], p
+}
+
+// pageURL returns a link to page, keeping the other query parameters.
+func (o listingOptions) pageURL(page int) string {
+	q := cloneValues(o.query)
+	q.Set(pageParam, strconv.Itoa(page))
+	return "/?" + q.Encode()
+}
+
+// cloneValues returns a copy of q that can be modified without modifying q.
+func cloneValues(q url.Values) url.Values {
+	result := url.Values{}
+	for k, v := range q {
+		result[k] = append([]string{}, v...)
+	}
+	return result
+}
@@ -1,35 +1,137 @@
 package ui
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/arctir/proctor/baseline"
 	"github.com/arctir/proctor/plib"
 )
 
 const (
-	port              = ":8080"
 	refreshPath       = "/refresh"
+	diffPath          = "/diff"
 	processesPath     = "/process/"
 	processesTreePath = "/tree/"
+	// the query parameter used to filter the processes listed.
+	searchParam = "q"
+	// the query parameter requesting only a fragment of a page be rendered,
+	// such as the listing fragment when the listing is updated in place.
+	fragmentParam   = "fragment"
+	listingFragment = "listing"
 )
 
 type UI struct {
 	inspector   plib.Inspector
 	data        Data
 	refreshLock sync.Mutex
+	events      *eventHub
+	metrics     *metrics
+	// the URLs of the agents shown on the hosts page, and the client they're
+	// retrieved with.
+	agents      []string
+	agentClient *http.Client
+	// the token authenticating process actions, which are disabled when empty,
+	// and the lockout after repeated invalid tokens.
+	actionToken    string
+	actionThrottle actionThrottle
+	// the audit trail of process actions, which isn't filtered by level.
+	audit *slog.Logger
+	// set to 1 while the server is ready to serve requests.
+	ready int32
+	// creates the page templates and serves static files.
+	templates templateLoader
+	// the utilization of each process as of the last scan, keyed by pid.
+	utilization map[int]plib.Utilization
+	// the processes as they were before the last refresh, compared against
+	// on the diff page.
+	previous DiffData
+	// the hosts whose repositories may be browsed on the source pages, which
+	// are disabled when empty, and how long retrieving one may take.
+	sourceHosts   []string
+	sourceTimeout time.Duration
+	// guards repoLocks, which are held while resolving each repository for
+	// the source pages.
+	sourceLock sync.Mutex
+	repoLocks  map[string]*sync.Mutex
+	// the baseline shown on the drift page, nil when none is configured.
+	baseline *baseline.Baseline
 }
 
 type Data struct {
 	LastRefresh time.Time
 	PS          plib.Processes
+	// The search the processes in PS were filtered by, if any.
+	Query string
+	// The number of processes before filtering.
+	Total int
+	// The processes of PS on the page listed, in the order they are listed.
+	Processes []*plib.Process
+	Pagination
+	// The optional columns shown and the choices of sorts and columns.
+	Columns       []Column
+	SortOptions   []Option
+	Descending    bool
+	ColumnOptions []Option
+	// The utilization of the processes, keyed by pid, shown in the CPU and
+	// memory columns.
+	Utilization map[int]plib.Utilization
+	// The seconds between reloads of the page, 0 when it isn't reloaded, and
+	// the choices of interval.
+	Interval        int
+	IntervalOptions []Option
+}
+
+// TreeData is rendered by the process tree page.
+type TreeData struct {
+	// The ancestors of the process, starting with the most parent.
+	Ancestors []plib.Process
+	// The process and its descendants.
+	Descendants ProcessNode
+	// The ancestors and descendants as a single tree, rendered as a graph.
+	Graph GraphNode
+}
+
+// GraphNode is a process in the graph of the process tree page, which is
+// encoded as JSON for the script rendering it.
+type GraphNode struct {
+	ID   int
+	Name string
+	// Whether this is the process the page is for.
+	Selected bool
+	Children []GraphNode
+}
+
+// ProcessNode is a process and its children, forming a tree.
+type ProcessNode struct {
+	Process  plib.Process
+	Children []ProcessNode
+}
+
+// DiffData is rendered by the diff page.
+type DiffData struct {
+	// When the previous snapshot was taken and the processes in it. PS is nil
+	// until the processes have been refreshed.
+	LastRefresh time.Time
+	PS          plib.Processes
+	// When the current snapshot was taken and how it differs from the
+	// previous one.
+	CurrentRefresh time.Time
+	Diff           plib.ProcessesDiff
 }
 
 type DetailKV struct {
@@ -37,13 +139,17 @@ type DetailKV struct {
 	Value string
 }
 
-func New() *UI {
+// New returns a UI serving the processes found by an inspector created with
+// conf. See [plib.NewInspector].
+func New(conf ...plib.InspectorConfig) *UI {
 	var err error
-	newInspector, err := plib.NewInspector()
+	newInspector, err := plib.NewInspector(conf...)
 	newUI := UI{
 		inspector:   newInspector,
 		data:        Data{},
 		refreshLock: sync.Mutex{},
+		events:      newEventHub(),
+		metrics:     newMetrics(),
 	}
 	if err != nil {
 		panic(err)
@@ -51,95 +157,311 @@ func New() *UI {
 	return &newUI
 }
 
-func (ui *UI) RunUI() {
-	http.HandleFunc("/", ui.handleAllProcesses)
-	http.HandleFunc(refreshPath, ui.handleRefresh)
-	http.HandleFunc(processesPath, ui.handleProcessDetails)
-	http.HandleFunc(processesTreePath, ui.handleProcessTree)
+// RunUI serves the UI until it receives SIGINT or SIGTERM, at which point it
+// shuts down gracefully. It's served on [DefaultPort] over plain HTTP unless a
+// [UIConfig] specifies otherwise. See [UI.Serve].
+func (ui *UI) RunUI(conf ...UIConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return ui.Serve(ctx, conf...)
+}
 
-	log.Printf("serving at %s", port)
-	panic(http.ListenAndServe(port, nil))
+// Serve serves the UI until ctx is cancelled, then stops accepting
+// connections and gives in-flight requests until the configured shutdown
+// timeout to complete. Open event streams are ended so they don't hold up
+// shutdown. A nil error is returned when the server shut down gracefully.
+func (ui *UI) Serve(ctx context.Context, conf ...UIConfig) error {
+	config := UIConfig{}
+	if len(conf) > 0 {
+		config = conf[len(conf)-1]
+	}
+	if config.ShutdownTimeout == 0 {
+		config.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return err
+	}
+	// the action token would be sent in cleartext to other hosts.
+	if config.ActionToken != "" && tlsConfig == nil && !config.isLoopback() {
+		return fmt.Errorf("process actions can only be enabled over plain HTTP when bound to a loopback address, serve TLS or bind to 127.0.0.1")
+	}
+
+	if ui.metrics == nil {
+		ui.metrics = newMetrics()
+	}
+	ui.agents = []string{}
+	for _, agent := range config.Agents {
+		ui.agents = append(ui.agents, strings.TrimSuffix(agent, "/"))
+	}
+	ui.agentClient = config.AgentClient
+	ui.actionToken = config.ActionToken
+	ui.audit = config.AuditLog
+	ui.baseline = config.Baseline
+	ui.sourceHosts = config.SourceHosts
+	ui.sourceTimeout = config.SourceTimeout
+	if ui.sourceTimeout == 0 {
+		ui.sourceTimeout = DefaultSourceTimeout
+	}
+	ui.templates = newTemplateLoader(config.TemplateDir)
+	handlers := map[string]http.HandlerFunc{
+		"/":                 ui.handleAllProcesses,
+		refreshPath:         ui.handleRefresh,
+		diffPath:            ui.handleDiff,
+		processesPath:       ui.handleProcessDetails,
+		processesTreePath:   ui.handleProcessTree,
+		eventsPath:          ui.handleEvents,
+		sourcePath:          ui.handleSource,
+		sourceCommitsPath:   ui.handleSourceCommits,
+		sourceArtifactsPath: ui.handleSourceArtifacts,
+		metricsPath:         ui.handleMetrics,
+		apiProcessesPath:    ui.handleAPIProcesses,
+		hostsPath:           ui.handleHosts,
+		signalPath:          ui.handleSignal,
+		exportPath:          ui.handleExport,
+		healthPath:          ui.handleHealth,
+		readinessPath:       ui.handleReadiness,
+		comparePath:         ui.handleCompare,
+		driftPath:           ui.handleDrift,
+	}
+	handlers[staticPath] = ui.templates.static().ServeHTTP
+	mux := http.NewServeMux()
+	for path, h := range handlers {
+		mux.HandleFunc(path, ui.metrics.instrument(path, h))
+	}
+
+	// watch processes so pages can be updated as they start and exit. The
+	// inspector is shared with the handlers, so it's loaded under the same
+	// lock they use. The scans are timed for the metrics and sampled for the
+	// utilization columns.
+	var scanner plib.Inspector = timedInspector{Inspector: ui.inspector, metrics: ui.metrics}
+	scanner = sampledInspector{Inspector: scanner, ui: ui, sampler: plib.NewUtilizationSampler()}
+	events, err := plib.Watch(ctx, scanner, plib.WatchOpts{Interval: config.ScanInterval, Lock: &ui.refreshLock})
+	if err != nil {
+		slog.Warn("not watching processes for live updates", "error", err)
+	} else {
+		go ui.events.run(events)
+	}
+
+	var handler http.Handler = ui.recoverPanics(mux)
+	if !config.DisableAccessLog {
+		if config.AccessLog == nil {
+			config.AccessLog = os.Stderr
+		}
+		handler = accessLog(config.AccessLog, handler)
+	}
+	server := &http.Server{Addr: config.addr(), Handler: handler, TLSConfig: tlsConfig}
+	server.RegisterOnShutdown(ui.events.close)
+	// processes were loaded when the watch started.
+	ui.setReady(true)
+	serveErr := make(chan error, 1)
+	go func() {
+		if tlsConfig != nil {
+			slog.Info("serving", "url", "https://"+server.Addr)
+			// the certificate is already loaded into the TLS config.
+			serveErr <- server.ListenAndServeTLS("", "")
+			return
+		}
+		slog.Info("serving", "url", "http://"+server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	ui.setReady(false)
+	slog.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed shutting down gracefully: %s", err)
+	}
+	return nil
 }
 
 func (ui *UI) handleAllProcesses(w http.ResponseWriter, r *http.Request) {
+	// every path not otherwise handled is routed here.
+	if r.URL.Path != "/" {
+		ui.writeFailure(w, newHTTPError(http.StatusNotFound, "page %s does not exist", r.URL.Path))
+		return
+	}
 	ui.refreshLock.Lock()
 	defer ui.refreshLock.Unlock()
-	var err error
-	ui.data.PS, err = ui.inspector.GetProcesses()
-	ui.data.LastRefresh = ui.inspector.GetLastLoadTime()
-	t, err := createTemplate(allProcessesView)
+	ui.renderListing(w, r.URL.Query())
+}
+
+// renderListing renders the process table for the query q, as the full page
+// or, when q requests it with [fragmentParam], only the listing fragment. It's
+// called with refreshLock held.
+func (ui *UI) renderListing(w http.ResponseWriter, q url.Values) {
+	ps, err := ui.inspector.GetProcesses()
 	if err != nil {
-		// TODO(joshross): do error response
+		ui.writeFailure(w, err)
+		return
 	}
-	// Render the template with the data
-	err = t.Execute(w, ui.data)
-	if err != nil {
-		writeFailure(w, err)
+	ui.data.PS = ps
+	ui.data.LastRefresh = ui.inspector.GetLastLoadTime()
+	fragment := q.Get(fragmentParam)
+	// the fragment isn't kept in the links of the page.
+	q = cloneValues(q)
+	q.Del(fragmentParam)
+	// the full set of processes is kept in ui.data for the detail and tree
+	// pages, so only the rendered copy is filtered.
+	query := strings.TrimSpace(q.Get(searchParam))
+	opts := newListingOptions(q)
+	filtered := filterProcesses(ui.data.PS, query)
+	processes, pagination := opts.paginate(sortProcesses(filtered, opts.Sort, opts.Desc, ui.utilization))
+	data := Data{
+		LastRefresh:     ui.data.LastRefresh,
+		PS:              filtered,
+		Query:           query,
+		Total:           len(ui.data.PS),
+		Processes:       processes,
+		Pagination:      pagination,
+		Columns:         opts.Columns,
+		SortOptions:     opts.sortOptions(),
+		Descending:      opts.Desc,
+		ColumnOptions:   opts.columnOptions(),
+		Utilization:     ui.utilization,
+		Interval:        opts.Interval,
+		IntervalOptions: opts.intervalOptions(),
+	}
+	if fragment == listingFragment {
+		ui.renderFragment(w, allProcessesView, listingFragment, data)
+		return
 	}
+	ui.renderTemplate(w, allProcessesView, data)
 }
 
 func (ui *UI) handleRefresh(w http.ResponseWriter, r *http.Request) {
 	ui.refreshLock.Lock()
 	defer ui.refreshLock.Unlock()
-	err := ui.inspector.ClearProcessCache()
-	if err != nil {
-		panic(err)
+	// keep the snapshot being replaced for the diff page.
+	if ps, err := ui.inspector.GetProcesses(); err == nil {
+		ui.previous = DiffData{LastRefresh: ui.inspector.GetLastLoadTime(), PS: ps}
+	}
+	if err := ui.inspector.ClearProcessCache(); err != nil {
+		ui.writeFailure(w, err)
+		return
+	}
+	slog.Debug("refreshed process cache")
+	// the listing is updated in place when only its fragment is requested.
+	q := r.URL.Query()
+	if q.Get(fragmentParam) != "" {
+		ui.renderListing(w, q)
+		return
 	}
-	log.Println("refreshed process cache")
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	http.Redirect(w, r, "/?"+r.URL.RawQuery, http.StatusSeeOther)
 }
 
-func (ui *UI) handleProcessDetails(w http.ResponseWriter, r *http.Request) {
-	pid, err := getProcessFromPath(r, processesPath, ui)
+// handleDiff renders the processes that appeared, disappeared, or changed
+// binary hash since the snapshot before the last refresh.
+func (ui *UI) handleDiff(w http.ResponseWriter, r *http.Request) {
+	ui.refreshLock.Lock()
+	defer ui.refreshLock.Unlock()
+	current, err := ui.inspector.GetProcesses()
 	if err != nil {
-		writeFailure(w, err)
+		ui.writeFailure(w, err)
 		return
 	}
+	data := ui.previous
+	data.CurrentRefresh = ui.inspector.GetLastLoadTime()
+	if data.PS != nil {
+		data.Diff = data.PS.Diff(current)
+	}
+	ui.renderTemplate(w, viewDiff, data)
+}
 
-	t, err := createTemplate(viewProcessDetails)
+func (ui *UI) handleProcessDetails(w http.ResponseWriter, r *http.Request) {
+	ps, pid, err := ui.getProcessFromPath(r, processesPath)
 	if err != nil {
-		writeFailure(w, err)
+		ui.writeFailure(w, err)
 		return
 	}
-	err = t.Execute(w, ui.data.PS[pid])
-	if err != nil {
-		writeFailure(w, err)
-		return
+
+	data := newDetailsData(ui.inspector, ps[pid], r.URL.Query())
+	// processes are only signaled after verifying their binary, so those
+	// whose binary couldn't be hashed can't be.
+	if ui.actionToken != "" && ps[pid].BinarySHA != "" {
+		data.Signals = actionSignals
 	}
+	ui.renderTemplate(w, viewProcessDetails, data)
 }
+
 func (ui *UI) handleProcessTree(w http.ResponseWriter, r *http.Request) {
-	pid, err := getProcessFromPath(r, processesTreePath, ui)
+	ps, pid, err := ui.getProcessFromPath(r, processesTreePath)
 	if err != nil {
-		writeFailure(w, err)
+		ui.writeFailure(w, err)
 		return
 	}
 
-	hierarchy := getProcessHierarchy(ui.data.PS, pid)
-	t, err := createTemplate(viewTreeDetails)
-	if err != nil {
-		writeFailure(w, err)
-		return
+	// the hierarchy starts with the process itself, which is rendered with
+	// its descendants.
+	hierarchy := getProcessHierarchy(ps, pid)
+	ancestors := []plib.Process{}
+	for i := len(hierarchy) - 1; i > 0; i-- {
+		ancestors = append(ancestors, hierarchy[i])
 	}
-	err = t.Execute(w, hierarchy)
-	if err != nil {
-		writeFailure(w, err)
-		return
+	data := TreeData{
+		Ancestors:   ancestors,
+		Descendants: getProcessDescendants(ps, pid),
 	}
-
+	data.Graph = newProcessGraph(data.Ancestors, data.Descendants)
+	ui.renderTemplate(w, viewTreeDetails, data)
 }
 
-func getProcessFromPath(r *http.Request, pathPrefix string, ui *UI) (int, error) {
-	pidString := strings.TrimPrefix(r.URL.Path, pathPrefix)
-	pid, err := strconv.Atoi(pidString)
+// getProcessFromPath returns the pid following pathPrefix in the request's
+// path, along with the processes it was found in, which are those from
+// [UI.loadedProcesses]. See [lookupProcess] for the errors returned, such as
+// when the process exited before a refresh.
+func (ui *UI) getProcessFromPath(r *http.Request, pathPrefix string) (plib.Processes, int, error) {
+	ps, err := ui.loadedProcesses()
+	if err != nil {
+		return nil, -1, err
+	}
+	p, err := lookupProcess(ps, strings.TrimPrefix(r.URL.Path, pathPrefix))
 	if err != nil {
-		return -1, fmt.Errorf("process %v was not valid pid (needs to be int)", pidString)
+		return nil, -1, err
 	}
+	return ps, p.ID, nil
+}
 
-	if ui.data.PS[pid] == nil {
-		return -1, fmt.Errorf("process %d does not exist.", pid)
+// loadedProcesses returns the processes last listed or, when none have been,
+// the inspector's.
+func (ui *UI) loadedProcesses() (plib.Processes, error) {
+	ui.refreshLock.Lock()
+	defer ui.refreshLock.Unlock()
+	if ui.data.PS == nil && ui.inspector != nil {
+		ps, err := ui.inspector.GetProcesses()
+		if err != nil {
+			return nil, err
+		}
+		ui.data.PS = ps
+		ui.data.LastRefresh = ui.inspector.GetLastLoadTime()
 	}
+	return ui.data.PS, nil
+}
 
-	return pid, nil
+// filterProcesses returns the processes whose PID is query or whose name,
+// path, or SHA contains query, ignoring case. When query is empty, every
+// process is returned.
+func filterProcesses(processes plib.Processes, query string) plib.Processes {
+	if query == "" {
+		return processes
+	}
+	query = strings.ToLower(query)
+	result := plib.Processes{}
+	for pid, p := range processes {
+		if strconv.Itoa(pid) == query ||
+			strings.Contains(strings.ToLower(p.CommandName), query) ||
+			strings.Contains(strings.ToLower(p.CommandPath), query) ||
+			strings.Contains(strings.ToLower(p.BinarySHA), query) {
+			result[pid] = p
+		}
+	}
+	return result
 }
 
 // getProcessDetails returns a slice containing the key and value for each value
@@ -156,6 +478,10 @@ func getProcessDetails(process plib.Process) []DetailKV {
 		}
 		result = append(result, DetailKV{field.Name, fmt.Sprintf("%v", v.Field(i).Interface())})
 	}
+	// processes whose OS specific details couldn't be read have none.
+	if process.OSSpecific == nil {
+		return result
+	}
 	t = reflect.TypeOf(process.OSSpecific)
 	v = reflect.ValueOf(process.OSSpecific)
 	for i := 0; i < t.NumField(); i++ {
@@ -171,6 +497,9 @@ func getProcessDetails(process plib.Process) []DetailKV {
 // pid argument.
 func getProcessHierarchy(processes plib.Processes, pid int) []plib.Process {
 	result := []plib.Process{}
+	if processes[pid] == nil {
+		return result
+	}
 
 	currentProcess := *processes[pid]
 	for {
@@ -185,20 +514,84 @@ func getProcessHierarchy(processes plib.Processes, pid int) []plib.Process {
 	return result
 }
 
-// createTemplate returns a final template with your template (temp) specified
-// and wrapped with [UIHeader] and [UIFooter].
-func createTemplate(temp string) (*template.Template, error) {
-	t, err := template.New("response").
-		Funcs(template.FuncMap{"pDeets": getProcessDetails}).
-		Parse(uiHeader + temp + uiFooter)
-	if err != nil {
-		return nil, err
+// getProcessDescendants returns the process with the pid argument as the root
+// of a tree containing all its descendants. Children are ordered by pid.
+func getProcessDescendants(processes plib.Processes, pid int) ProcessNode {
+	children := map[int][]int{}
+	for id, p := range processes {
+		// some processes, such as pid 0 on Linux, are their own parent.
+		if id != p.ParentProcess {
+			children[p.ParentProcess] = append(children[p.ParentProcess], id)
+		}
+	}
+	for _, ids := range children {
+		sort.Ints(ids)
+	}
+
+	if processes[pid] == nil {
+		return ProcessNode{Children: []ProcessNode{}}
+	}
+	var build func(pid int, visited map[int]bool) ProcessNode
+	build = func(pid int, visited map[int]bool) ProcessNode {
+		visited[pid] = true
+		node := ProcessNode{Process: *processes[pid], Children: []ProcessNode{}}
+		for _, child := range children[pid] {
+			if !visited[child] {
+				node.Children = append(node.Children, build(child, visited))
+			}
+		}
+		return node
+	}
+	return build(pid, map[int]bool{})
+}
+
+// newProcessGraph returns the tree of ancestors, starting with the most
+// parent, each the only child of the one before it, with the last ancestor's
+// child being descendants. The root of descendants is marked selected.
+func newProcessGraph(ancestors []plib.Process, descendants ProcessNode) GraphNode {
+	var toGraph func(n ProcessNode) GraphNode
+	toGraph = func(n ProcessNode) GraphNode {
+		g := GraphNode{ID: n.Process.ID, Name: n.Process.CommandName, Children: []GraphNode{}}
+		for _, c := range n.Children {
+			g.Children = append(g.Children, toGraph(c))
+		}
+		return g
+	}
+	node := toGraph(descendants)
+	node.Selected = true
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		node = GraphNode{ID: ancestors[i].ID, Name: ancestors[i].CommandName, Children: []GraphNode{node}}
 	}
-	return t, nil
+	return node
+}
+
+// createTemplate returns the template named name, such as
+// [viewProcessDetails], wrapped with the header and footer templates. See
+// [templateLoader].
+func (ui *UI) createTemplate(name string) (*template.Template, error) {
+	return ui.templates.create(name)
+}
+
+// renderTemplate renders the template named name, created with
+// [UI.createTemplate], with data. The page is rendered in full before being
+// written, so a failure page is written in its place when it can't be.
+func (ui *UI) renderTemplate(w http.ResponseWriter, name string, data any) {
+	ui.renderFragment(w, name, name, data)
 }
 
-func writeFailure(w http.ResponseWriter, err error) {
-	w.WriteHeader(http.StatusInternalServerError)
-	t, _ := createTemplate(errorView)
-	t.Execute(w, err.Error())
+// renderFragment renders the template fragment, defined in the template named
+// name, with data. When fragment is name, the whole page is rendered.
+func (ui *UI) renderFragment(w http.ResponseWriter, name, fragment string, data any) {
+	t, err := ui.createTemplate(name)
+	if err != nil {
+		ui.writeFailure(w, err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, fragment, data); err != nil {
+		ui.writeFailure(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
 }
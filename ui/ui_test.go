@@ -0,0 +1,463 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arctir/proctor/baseline"
+	"github.com/arctir/proctor/plib"
+)
+
+func TestFilterProcesses(t *testing.T) {
+	processes := plib.Processes{
+		1:    {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", BinarySHA: "aa11"},
+		42:   {ID: 42, CommandName: "containerd", CommandPath: "/usr/bin/containerd", BinarySHA: "bb22"},
+		420:  {ID: 420, CommandName: "bash", CommandPath: "/usr/bin/bash", BinarySHA: "cc33"},
+		1042: {ID: 1042, CommandName: "Xorg", CommandPath: "/usr/lib/xorg/Xorg", BinarySHA: "dd44"},
+	}
+	tests := map[string][]int{
+		"":         {1, 42, 420, 1042},
+		"42":       {42},
+		"SYSTEMD":  {1},
+		"/usr/bin": {42, 420},
+		"BB22":     {42},
+		"xorg":     {1042},
+		"missing":  {},
+	}
+	for query, expected := range tests {
+		result := filterProcesses(processes, query)
+		if len(result) != len(expected) {
+			t.Logf("fail: query (%s) returned wrong processes, expected: %v, actual: %v", query, expected, result)
+			t.Fail()
+			continue
+		}
+		for _, pid := range expected {
+			if _, ok := result[pid]; !ok {
+				t.Logf("fail: query (%s) didn't return process %d", query, pid)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestSortProcesses(t *testing.T) {
+	processes := plib.Processes{
+		1:  {ID: 1, CommandName: "systemd", OSSpecific: plib.ProcessStat{ResidentSetMemSize: 300, UserModeTime: 5, StartTime: 1}},
+		42: {ID: 42, CommandName: "bash", OSSpecific: plib.ProcessStat{ResidentSetMemSize: 100, UserModeTime: 50, StartTime: 20}},
+		7:  {ID: 7, CommandName: "Xorg", OSSpecific: plib.ProcessStat{ResidentSetMemSize: 900, KernalTime: 9, StartTime: 10}},
+	}
+	tests := []struct {
+		key      string
+		desc     bool
+		expected []int
+	}{
+		{"pid", false, []int{1, 7, 42}},
+		{"name", false, []int{42, 1, 7}},
+		{"rss", true, []int{7, 1, 42}},
+		{"cpu", true, []int{42, 7, 1}},
+		{"cpupct", true, []int{7, 1, 42}},
+		{"start", false, []int{1, 7, 42}},
+		{"unknown", false, []int{1, 7, 42}},
+	}
+	// process 42 has no sample, so sorts as 0.
+	utilization := map[int]plib.Utilization{1: {CPUPercent: 2.5}, 7: {CPUPercent: 80}}
+	for _, test := range tests {
+		sorted := sortProcesses(processes, test.key, test.desc, utilization)
+		for i, pid := range test.expected {
+			if sorted[i].ID != pid {
+				t.Logf("fail: sort by %s (desc: %t) was wrong at %d, expected: %d, actual: %d", test.key, test.desc, i, pid, sorted[i].ID)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestRenderAllProcesses(t *testing.T) {
+	processes := plib.Processes{1: {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", OSSpecific: plib.ProcessStat{}}}
+	opts := newListingOptions(url.Values{"sort": {"rss"}, "order": {"desc"}, "col": {"path", "unknown"}})
+	if opts.Sort != "rss" || !opts.Desc || len(opts.Columns) != 1 || opts.Columns[0].Key != "path" {
+		t.Fatalf("fail: listing options were wrong: %+v", opts)
+	}
+
+	tmpl, err := templateLoader{}.create(allProcessesView)
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing template: %s", err)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, Data{
+		PS:            processes,
+		Processes:     sortProcesses(processes, opts.Sort, opts.Desc, nil),
+		Columns:       opts.Columns,
+		SortOptions:   opts.sortOptions(),
+		ColumnOptions: opts.columnOptions(),
+	})
+	if err != nil {
+		t.Fatalf("fail: unexpected error rendering template: %s", err)
+	}
+	if !strings.Contains(buf.String(), "<td>/usr/lib/systemd/systemd</td>") {
+		t.Log("fail: expected the path column to be rendered")
+		t.Fail()
+	}
+}
+
+func TestRenderUtilizationColumns(t *testing.T) {
+	processes := plib.Processes{1: {ID: 1, CommandName: "systemd", OSSpecific: plib.ProcessStat{}}}
+	ui := &UI{
+		inspector:   &stubInspector{ps: processes},
+		utilization: map[int]plib.Utilization{1: {CPUPercent: 12.345, RSSBytes: 3 * 1024 * 1024}},
+	}
+	w := httptest.NewRecorder()
+	ui.handleAllProcesses(w, httptest.NewRequest("GET", "/?col=cpupct&col=mem&interval=5", nil))
+	body := w.Body.String()
+	for _, expected := range []string{"<td>12.3</td>", "<td>3.0 MiB</td>", `<option value="5" selected>5s</option>`, `updateListing("/")`} {
+		if !strings.Contains(body, expected) {
+			t.Logf("fail: expected %q in the page", expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int]string{
+		512:                    "512 B",
+		1536:                   "1.5 KiB",
+		5 * 1024 * 1024:        "5.0 MiB",
+		2 * 1024 * 1024 * 1024: "2.0 GiB",
+	}
+	for n, expected := range tests {
+		if actual := formatBytes(n); actual != expected {
+			t.Logf("fail: expected %d bytes to format as %s, actual: %s", n, expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestHandleEvents(t *testing.T) {
+	ui := &UI{events: newEventHub()}
+	server := httptest.NewServer(http.HandlerFunc(ui.handleEvents))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("fail: unexpected error connecting to events: %s", err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Logf("fail: content type was wrong: %s", ct)
+		t.Fail()
+	}
+
+	// the subscription is made once the handler starts, after headers are
+	// flushed, so publish until the event is received.
+	go func() {
+		for i := 0; i < 100; i++ {
+			ui.events.publish(plib.ProcessEvent{Type: plib.ProcessStarted, Process: plib.Process{ID: 42, CommandName: "bash"}})
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	reader := bufio.NewReader(res.Body)
+	event, _ := reader.ReadString('\n')
+	data, _ := reader.ReadString('\n')
+	if event != "event: started\n" || !strings.Contains(data, `"ID":42`) {
+		t.Logf("fail: event was wrong: %q %q", event, data)
+		t.Fail()
+	}
+}
+
+func TestGetProcessDescendants(t *testing.T) {
+	processes := plib.Processes{
+		0:   {ID: 0, ParentProcess: 0, CommandName: "idle"},
+		1:   {ID: 1, ParentProcess: 0, CommandName: "systemd"},
+		42:  {ID: 42, ParentProcess: 1, CommandName: "containerd"},
+		430: {ID: 430, ParentProcess: 42, CommandName: "containerd-shim"},
+		421: {ID: 421, ParentProcess: 42, CommandName: "containerd-shim"},
+		500: {ID: 500, ParentProcess: 421, CommandName: "nginx"},
+		7:   {ID: 7, ParentProcess: 1, CommandName: "journald"},
+	}
+	root := getProcessDescendants(processes, 1)
+	if root.Process.ID != 1 || len(root.Children) != 2 {
+		t.Fatalf("fail: expected systemd with 2 children, actual: %d with %d children", root.Process.ID, len(root.Children))
+	}
+	if root.Children[0].Process.ID != 7 || root.Children[1].Process.ID != 42 {
+		t.Logf("fail: children were not ordered by pid: %d, %d", root.Children[0].Process.ID, root.Children[1].Process.ID)
+		t.Fail()
+	}
+	shims := root.Children[1].Children
+	if len(shims) != 2 || shims[0].Process.ID != 421 || len(shims[0].Children) != 1 || shims[0].Children[0].Process.ID != 500 {
+		t.Logf("fail: containerd subtree was not built correctly: %+v", shims)
+		t.Fail()
+	}
+
+	// the process that is its own parent must not recurse forever.
+	if idle := getProcessDescendants(processes, 0); len(idle.Children) != 1 {
+		t.Logf("fail: expected idle to have 1 child, actual: %d", len(idle.Children))
+		t.Fail()
+	}
+}
+
+func TestRenderProcessDetailsTabs(t *testing.T) {
+	inspector, err := plib.NewInspector(plib.InspectorConfig{CacheFilePath: filepath.Join(t.TempDir(), "proc.cache")})
+	if err != nil {
+		t.Skipf("no inspector on this platform: %s", err)
+	}
+	p := &plib.Process{ID: os.Getpid(), CommandName: "ui.test", OSSpecific: plib.ProcessStat{}}
+	tmpl, err := templateLoader{}.create(viewProcessDetails)
+	if err != nil {
+		t.Fatalf("fail: unexpected error parsing template: %s", err)
+	}
+
+	tests := map[string]string{
+		"":      "<th>Field</th>",
+		"bogus": "<th>Field</th>",
+		"env":   "<th>Variable</th>",
+		"files": "<th>Target</th>",
+	}
+	for tab, expected := range tests {
+		data := newDetailsData(inspector, p, url.Values{"tab": {tab}})
+		if data.Error != "" {
+			t.Fatalf("fail: failed retrieving tab %s: %s", tab, data.Error)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			t.Fatalf("fail: unexpected error rendering tab %s: %s", tab, err)
+		}
+		if !strings.Contains(buf.String(), expected) {
+			t.Logf("fail: expected tab (%s) to render %s", tab, expected)
+			t.Fail()
+		}
+	}
+
+	// secrets in the environment are masked.
+	stub := &stubResourceInspector{env: map[int][]string{1: {"PATH=/usr/bin", "API_TOKEN=hunter2"}}}
+	data := newDetailsData(stub, &plib.Process{ID: 1}, url.Values{"tab": {"env"}})
+	if len(data.Environment) != 2 || data.Environment[1] != "API_TOKEN="+plib.RedactedValue {
+		t.Logf("fail: expected the token to be redacted, actual: %v", data.Environment)
+		t.Fail()
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	processes := []*plib.Process{}
+	for i := 1; i <= 5; i++ {
+		processes = append(processes, &plib.Process{ID: i})
+	}
+	tests := []struct {
+		query    url.Values
+		expected []int
+		page     Pagination
+	}{
+		{url.Values{}, []int{1, 2, 3, 4, 5}, Pagination{Page: 1, Pages: 1}},
+		{url.Values{"limit": {"2"}}, []int{1, 2}, Pagination{Page: 1, Pages: 3, NextURL: "/?limit=2&page=2"}},
+		{url.Values{"limit": {"2"}, "page": {"2"}, "q": {"x"}}, []int{3, 4}, Pagination{Page: 2, Pages: 3, PrevURL: "/?limit=2&page=1&q=x", NextURL: "/?limit=2&page=3&q=x"}},
+		{url.Values{"limit": {"2"}, "page": {"9"}}, []int{5}, Pagination{Page: 3, Pages: 3, PrevURL: "/?limit=2&page=2"}},
+		{url.Values{"limit": {"-1"}, "page": {"zero"}}, []int{1, 2, 3, 4, 5}, Pagination{Page: 1, Pages: 1}},
+	}
+	for _, test := range tests {
+		result, page := newListingOptions(test.query).paginate(processes)
+		if page != test.page {
+			t.Logf("fail: query (%s) expected pagination: %+v, actual: %+v", test.query.Encode(), test.page, page)
+			t.Fail()
+		}
+		if len(result) != len(test.expected) {
+			t.Logf("fail: query (%s) expected processes: %v, actual: %d processes", test.query.Encode(), test.expected, len(result))
+			t.Fail()
+			continue
+		}
+		for i, pid := range test.expected {
+			if result[i].ID != pid {
+				t.Logf("fail: query (%s) expected processes: %v, actual pid %d at %d", test.query.Encode(), test.expected, result[i].ID, i)
+				t.Fail()
+			}
+		}
+	}
+
+	// an empty listing still has a single page.
+	if _, page := newListingOptions(url.Values{}).paginate(nil); page.Pages != 1 {
+		t.Logf("fail: expected 1 page for no processes, actual: %d", page.Pages)
+		t.Fail()
+	}
+}
+
+// stubInspector returns a fixed set of processes.
+type stubInspector struct {
+	ps plib.Processes
+}
+
+func (s *stubInspector) LoadProcesses() error                  { return nil }
+func (s *stubInspector) ClearProcessCache() error              { return nil }
+func (s *stubInspector) GetProcesses() (plib.Processes, error) { return s.ps, nil }
+func (s *stubInspector) GetLastLoadTime() time.Time            { return time.Time{} }
+
+func TestServeShutdown(t *testing.T) {
+	// find a free port to serve on.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fail: unexpected error finding a port: %s", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	ui := &UI{inspector: &stubInspector{ps: plib.Processes{}}, events: newEventHub()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- ui.Serve(ctx, UIConfig{Address: "127.0.0.1", Port: port, ShutdownTimeout: 5 * time.Second, DisableAccessLog: true})
+	}()
+
+	// wait for the server to accept connections, then open an event stream
+	// that would otherwise never end.
+	var res *http.Response
+	for i := 0; i < 100; i++ {
+		res, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, eventsPath))
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("fail: server never started: %s", err)
+	}
+	defer res.Body.Close()
+	ready, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, readinessPath))
+	if err != nil || ready.StatusCode != http.StatusOK {
+		t.Fatalf("fail: expected the server to be ready, got: %v, %v", ready, err)
+	}
+	ready.Body.Close()
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("fail: expected a graceful shutdown, got: %s", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("fail: server did not shut down while an event stream was open")
+	}
+}
+
+func TestHandleDiff(t *testing.T) {
+	inspector := &stubInspector{ps: plib.Processes{
+		1:  {ID: 1, CommandName: "systemd", BinarySHA: "aa11"},
+		42: {ID: 42, CommandName: "containerd", BinarySHA: "bb22"},
+	}}
+	ui := &UI{inspector: inspector}
+
+	w := httptest.NewRecorder()
+	ui.handleDiff(w, httptest.NewRequest("GET", diffPath, nil))
+	if !strings.Contains(w.Body.String(), "no previous snapshot") {
+		t.Log("fail: expected no diff before the first refresh")
+		t.Fail()
+	}
+
+	ui.handleRefresh(httptest.NewRecorder(), httptest.NewRequest("GET", refreshPath, nil))
+	inspector.ps = plib.Processes{
+		1:   {ID: 1, CommandName: "systemd", BinarySHA: "aa99"},
+		420: {ID: 420, CommandName: "bash", BinarySHA: "cc33"},
+	}
+	w = httptest.NewRecorder()
+	ui.handleDiff(w, httptest.NewRequest("GET", diffPath, nil))
+	body := w.Body.String()
+	for _, expected := range []string{"Appeared (1)", ">bash</a>", "Disappeared (1)", "<td>containerd</td>", "Binary Changed (1)", "<td>aa99</td>"} {
+		if !strings.Contains(body, expected) {
+			t.Logf("fail: expected diff to contain %q", expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestHandleDrift(t *testing.T) {
+	sha := func(c string) string { return strings.Repeat(c, 64) }
+	inspector := &stubInspector{ps: plib.Processes{
+		1:  {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", BinarySHA: sha("a")},
+		42: {ID: 42, ParentProcess: 1, CommandName: "containerd", CommandPath: "/usr/bin/containerd", BinarySHA: sha("b")},
+	}}
+	ui := &UI{inspector: inspector}
+
+	w := httptest.NewRecorder()
+	ui.handleDrift(w, httptest.NewRequest("GET", driftPath, nil))
+	if !strings.Contains(w.Body.String(), "no baseline") {
+		t.Log("fail: expected no drift without a baseline")
+		t.Fail()
+	}
+
+	b := baseline.Record("web", inspector.ps, time.Now())
+	ui.baseline = &b
+	inspector.ps = plib.Processes{
+		1:   {ID: 1, CommandName: "systemd", CommandPath: "/usr/lib/systemd/systemd", BinarySHA: sha("c")},
+		420: {ID: 420, CommandName: "miner", CommandPath: "/tmp/miner", BinarySHA: sha("d")},
+	}
+	w = httptest.NewRecorder()
+	ui.handleDrift(w, httptest.NewRequest("GET", driftPath, nil))
+	body := w.Body.String()
+	for _, expected := range []string{"Unapproved Binaries (1)", ">miner</a>", "Binary Changed (1)", "<td>" + sha("c") + "</td>", "Missing (1)", "<td>/usr/bin/containerd</td>"} {
+		if !strings.Contains(body, expected) {
+			t.Logf("fail: expected drift to contain %q", expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestHandleRefreshFragment(t *testing.T) {
+	ui := &UI{inspector: &stubInspector{ps: plib.Processes{
+		1:  {ID: 1, CommandName: "systemd"},
+		42: {ID: 42, CommandName: "bash"},
+		43: {ID: 43, CommandName: "bash"},
+	}}}
+
+	// without JavaScript, the page is reloaded with its filters.
+	w := httptest.NewRecorder()
+	ui.handleRefresh(w, httptest.NewRequest("GET", refreshPath+"?q=bash", nil))
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/?q=bash" {
+		t.Logf("fail: expected a redirect to /?q=bash, actual: %d %s", w.Code, w.Header().Get("Location"))
+		t.Fail()
+	}
+
+	w = httptest.NewRecorder()
+	ui.handleRefresh(w, httptest.NewRequest("GET", refreshPath+"?q=bash&limit=1&fragment=listing", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK {
+		t.Fatalf("fail: expected the listing fragment, actual: %d %s", w.Code, body)
+	}
+	if strings.Contains(body, "<html>") || strings.Contains(body, "<form") {
+		t.Logf("fail: expected only the listing, actual: %s", body)
+		t.Fail()
+	}
+	for _, expected := range []string{"Showing 2 of 3 processes", `<tr id="process-42">`, "/?limit=1&amp;page=2&amp;q=bash"} {
+		if !strings.Contains(body, expected) {
+			t.Logf("fail: expected %q in the listing", expected)
+			t.Fail()
+		}
+	}
+}
+
+func TestRenderProcessGraph(t *testing.T) {
+	processes := plib.Processes{
+		1:   {ID: 1, ParentProcess: 0, CommandName: "systemd"},
+		42:  {ID: 42, ParentProcess: 1, CommandName: "containerd"},
+		421: {ID: 421, ParentProcess: 42, CommandName: "containerd-shim"},
+		500: {ID: 500, ParentProcess: 421, CommandName: "nginx"},
+	}
+	ui := &UI{data: Data{PS: processes}}
+	w := httptest.NewRecorder()
+	ui.handleProcessTree(w, httptest.NewRequest("GET", processesTreePath+"42", nil))
+
+	// the graph is embedded as JSON: systemd, then containerd, selected, with
+	// its descendants.
+	expected := `{"ID":1,"Name":"systemd","Selected":false,"Children":[{"ID":42,"Name":"containerd","Selected":true,"Children":[{"ID":421,"Name":"containerd-shim","Selected":false,"Children":[{"ID":500,"Name":"nginx","Selected":false,"Children":[]}]}]}]}`
+	if !strings.Contains(w.Body.String(), expected) {
+		t.Logf("fail: expected the graph %s in the page", expected)
+		t.Fail()
+	}
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/arctir/proctor/plib"
+)
+
+// sampledInspector samples the utilization of the processes loaded by the
+// Inspector it embeds, storing it in the UI for the process table. It's loaded
+// under the UI's refreshLock.
+type sampledInspector struct {
+	plib.Inspector
+	ui      *UI
+	sampler *plib.UtilizationSampler
+}
+
+func (s sampledInspector) LoadProcesses() error {
+	if err := s.Inspector.LoadProcesses(); err != nil {
+		return err
+	}
+	ps, err := s.Inspector.GetProcesses()
+	if err != nil {
+		return err
+	}
+	s.ui.utilization = s.sampler.Sample(ps, time.Now())
+	return nil
+}
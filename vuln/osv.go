@@ -0,0 +1,296 @@
+// Package vuln looks up the published vulnerabilities affecting a
+// repository's releases and dependencies, and the software run by processes.
+// Dependencies and software are checked against the [OSV] database, which
+// aggregates advisories from GitHub, the Go vulnerability database, PyPI, and
+// others.
+//
+// [OSV]: https://osv.dev
+package vuln
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arctir/proctor/source"
+)
+
+const (
+	// The OSV API of the public osv.dev deployment.
+	DefaultOSVURL = "https://api.osv.dev"
+	// The amount of time to wait for OSV to respond.
+	osvTimeout = 30 * time.Second
+	// The maximum number of queries OSV accepts in a single batch.
+	osvBatchSize = 1000
+)
+
+// Vulnerability is a published vulnerability, such as a CVE or GitHub
+// security advisory.
+type Vulnerability struct {
+	// The OSV identifier (e.g. GHSA-xxxx-xxxx-xxxx or GO-2023-0001).
+	ID string
+	// Other identifiers of the same vulnerability, such as CVE IDs.
+	Aliases []string
+	Summary string
+	// The severity (e.g. HIGH) when reported by the vulnerability's source.
+	Severity  string
+	Published time.Time
+	Modified  time.Time
+	URL       string
+}
+
+// Finding is a dependency affected by a vulnerability.
+type Finding struct {
+	Dependency    source.Dependency
+	Vulnerability Vulnerability
+	// The versions of the dependency the vulnerability is fixed in. Empty
+	// when no fix has been published.
+	FixedVersions []string
+}
+
+// QueryOpts configures how OSV is queried.
+type QueryOpts struct {
+	// The base URL of the OSV API. Defaults to [DefaultOSVURL].
+	OSVURL string
+	// The client used to query OSV. Defaults to a client with a 30 second
+	// timeout.
+	HTTPClient *http.Client
+}
+
+// osvQuery identifies a package version in an OSV query.
+type osvQuery struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Version string `json:"version"`
+}
+
+// osvVuln is a vulnerability as returned by OSV's API.
+type osvVuln struct {
+	ID               string    `json:"id"`
+	Summary          string    `json:"summary"`
+	Aliases          []string  `json:"aliases"`
+	Published        time.Time `json:"published"`
+	Modified         time.Time `json:"modified"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+	Affected []struct {
+		Package struct {
+			Name      string `json:"name"`
+			Ecosystem string `json:"ecosystem"`
+		} `json:"package"`
+		Ranges []struct {
+			Events []struct {
+				Fixed string `json:"fixed"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+}
+
+// QueryDependencies returns the vulnerabilities affecting deps, as published
+// in OSV. Only dependencies pinned to an exact version are checked, since
+// the version of a dependency declared with a constraint (e.g. ^4.0.0)
+// depends on when it is installed; see [QueryVersion]. Findings are ordered
+// by dependency, then vulnerability ID.
+func QueryDependencies(deps []source.Dependency, opts ...QueryOpts) ([]Finding, error) {
+	conf := QueryOpts{}
+	if len(opts) > 0 {
+		conf = opts[len(opts)-1]
+	}
+	if conf.OSVURL == "" {
+		conf.OSVURL = DefaultOSVURL
+	}
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = &http.Client{Timeout: osvTimeout}
+	}
+
+	queried := []source.Dependency{}
+	queries := []osvQuery{}
+	for _, d := range deps {
+		version, ok := QueryVersion(d)
+		if !ok {
+			continue
+		}
+		q := osvQuery{Version: version}
+		q.Package.Name = d.Name
+		q.Package.Ecosystem = d.Ecosystem
+		queried = append(queried, d)
+		queries = append(queries, q)
+	}
+
+	findings := []Finding{}
+	vulns := map[string]*osvVuln{}
+	for start := 0; start < len(queries); start += osvBatchSize {
+		end := start + osvBatchSize
+		if end > len(queries) {
+			end = len(queries)
+		}
+		results, err := queryBatch(conf, queries[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for i, ids := range results {
+			d := queried[start+i]
+			for _, id := range ids {
+				v, ok := vulns[id]
+				if !ok {
+					v, err = getVuln(conf, id)
+					if err != nil {
+						return nil, err
+					}
+					vulns[id] = v
+				}
+				findings = append(findings, newFinding(d, v))
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Dependency.Name != findings[j].Dependency.Name {
+			return findings[i].Dependency.Name < findings[j].Dependency.Name
+		}
+		return findings[i].Vulnerability.ID < findings[j].Vulnerability.ID
+	})
+	return findings, nil
+}
+
+// QueryVersion returns the version of d to look up in OSV and whether d is
+// pinned to an exact version. Go versions are returned without their leading
+// "v", as recorded by OSV. The versions of operating-system packages, such as
+// those of the Debian:12 ecosystem, are installed versions, so are always
+// exact.
+func QueryVersion(d source.Dependency) (string, bool) {
+	version := strings.TrimSpace(d.Version)
+	if isOSEcosystem(d.Ecosystem) {
+		return version, version != ""
+	}
+	if version == "" || strings.ContainsAny(version, "^~<>!*|, ") {
+		return "", false
+	}
+	switch d.Ecosystem {
+	case source.GoEcosystem:
+		return strings.TrimPrefix(version, "v"), true
+	case source.NPMEcosystem:
+		version = strings.TrimPrefix(version, "=")
+		if _, err := source.ParseSemver(version); err != nil {
+			return "", false
+		}
+		return strings.TrimPrefix(version, "v"), true
+	case source.PyPIEcosystem:
+		if strings.Contains(version, "=") {
+			return "", false
+		}
+		return version, true
+	}
+	return "", false
+}
+
+// isOSEcosystem returns whether ecosystem is that of an operating system's
+// packages, which OSV identifies by distribution and release, e.g. Debian:12.
+func isOSEcosystem(ecosystem string) bool {
+	for _, prefix := range []string{"Debian:", "Alpine:", "Ubuntu:"} {
+		if strings.HasPrefix(ecosystem, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryBatch returns the IDs of the vulnerabilities affecting each query, in
+// the same order as queries.
+func queryBatch(conf QueryOpts, queries []osvQuery) ([][]string, error) {
+	body, err := json.Marshal(map[string][]osvQuery{"queries": queries})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating OSV query: %s", err)
+	}
+	response := struct {
+		Results []struct {
+			Vulns []struct {
+				ID string `json:"id"`
+			} `json:"vulns"`
+		} `json:"results"`
+	}{}
+	if err := osvRequest(conf, http.MethodPost, "/v1/querybatch", body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(queries) {
+		return nil, fmt.Errorf("failed querying OSV: expected %d results, received %d", len(queries), len(response.Results))
+	}
+	results := make([][]string, len(queries))
+	for i, r := range response.Results {
+		for _, v := range r.Vulns {
+			results[i] = append(results[i], v.ID)
+		}
+	}
+	return results, nil
+}
+
+// getVuln returns the details of the vulnerability with id.
+func getVuln(conf QueryOpts, id string) (*osvVuln, error) {
+	v := &osvVuln{}
+	if err := osvRequest(conf, http.MethodGet, "/v1/vulns/"+url.PathEscape(id), nil, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// osvRequest makes a request to the OSV API and decodes its JSON response
+// into out.
+func osvRequest(conf QueryOpts, method string, path string, body []byte, out interface{}) error {
+	req, err := http.NewRequest(method, strings.TrimSuffix(conf.OSVURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed creating OSV request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := conf.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed querying OSV: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("failed querying OSV (%s): %s %s", path, res.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed decoding OSV response: %s", err)
+	}
+	return nil
+}
+
+// newFinding returns the finding of v affecting d, including the versions of
+// d that fix v.
+func newFinding(d source.Dependency, v *osvVuln) Finding {
+	f := Finding{
+		Dependency: d,
+		Vulnerability: Vulnerability{
+			ID:        v.ID,
+			Aliases:   v.Aliases,
+			Summary:   v.Summary,
+			Severity:  v.DatabaseSpecific.Severity,
+			Published: v.Published,
+			Modified:  v.Modified,
+			URL:       "https://osv.dev/vulnerability/" + v.ID,
+		},
+		FixedVersions: []string{},
+	}
+	for _, a := range v.Affected {
+		if a.Package.Name != d.Name || a.Package.Ecosystem != d.Ecosystem {
+			continue
+		}
+		for _, r := range a.Ranges {
+			for _, e := range r.Events {
+				if e.Fixed != "" {
+					f.FixedVersions = append(f.FixedVersions, e.Fixed)
+				}
+			}
+		}
+	}
+	return f
+}
@@ -0,0 +1,190 @@
+package vuln
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/sbom"
+	"github.com/arctir/proctor/source"
+)
+
+const (
+	// The OSV package of the Go standard library and toolchain.
+	goStdlib = "stdlib"
+)
+
+// Ranks of the severities reported by OSV, which are those of GitHub security
+// advisories. Medium is accepted as an alias of moderate.
+var severityRanks = map[string]int{
+	"low":      1,
+	"moderate": 2,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// Component is software run by processes, identified as a package of an OSV
+// ecosystem, such as a Go module or a Debian source package.
+type Component struct {
+	Name      string
+	Version   string
+	Ecosystem string
+	// The paths of the running binaries that include the component, sorted.
+	Binaries []string
+	// The IDs of the processes running those binaries, sorted.
+	PIDs []int
+}
+
+// ProcessFinding is a component of running processes affected by a
+// vulnerability.
+type ProcessFinding struct {
+	Component     Component
+	Vulnerability Vulnerability
+	// The versions of the component the vulnerability is fixed in. Empty
+	// when no fix has been published.
+	FixedVersions []string
+}
+
+// Components returns the components run by the binaries of inv. These are
+// the Go standard library, main module, and dependencies of Go binaries, and
+// the operating-system packages that installed binaries. OS packages are only
+// returned for Debian, Ubuntu, and Alpine, whose advisories OSV publishes,
+// and are identified by their source package, as advisories are.
+// Components are ordered by ecosystem, name, and version.
+func Components(inv sbom.Inventory) []Component {
+	byKey := map[string]*Component{}
+	add := func(ecosystem string, name string, version string, b sbom.Binary) {
+		if name == "" || version == "" {
+			return
+		}
+		key := ecosystem + "/" + name + "@" + version
+		c, ok := byKey[key]
+		if !ok {
+			c = &Component{Name: name, Version: version, Ecosystem: ecosystem}
+			byKey[key] = c
+		}
+		c.Binaries = append(c.Binaries, b.Path)
+		c.PIDs = append(c.PIDs, b.PIDs...)
+	}
+
+	osEcosystem := osvEcosystem(inv.OS)
+	packages := map[string]host.Package{}
+	for _, p := range inv.Packages {
+		packages[p.Name] = p
+	}
+	for _, b := range inv.Binaries {
+		if bi := b.BuildInfo; bi != nil {
+			// the Go version may be followed by build details, e.g.
+			// go1.21.3 X:boringcrypto.
+			goVersion, _, _ := strings.Cut(bi.GoVersion, " ")
+			add(source.GoEcosystem, goStdlib, strings.TrimPrefix(goVersion, "go"), b)
+			if bi.ModuleVersion != "(devel)" {
+				add(source.GoEcosystem, bi.ModulePath, bi.ModuleVersion, b)
+			}
+			for _, m := range bi.Deps {
+				add(source.GoEcosystem, m.Path, m.Version, b)
+			}
+		}
+		if p, ok := packages[b.Package]; ok && osEcosystem != "" {
+			name := p.Source
+			if name == "" {
+				name = p.Name
+			}
+			add(osEcosystem, name, p.Version, b)
+		}
+	}
+
+	components := []Component{}
+	for _, c := range byKey {
+		sort.Strings(c.Binaries)
+		sort.Ints(c.PIDs)
+		components = append(components, *c)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		a, b := components[i], components[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+	return components
+}
+
+// QueryProcesses returns the vulnerabilities, as published in OSV, affecting
+// the components run by the binaries of inv; see [Components]. Findings are
+// ordered by component name, then vulnerability ID.
+func QueryProcesses(inv sbom.Inventory, opts ...QueryOpts) ([]ProcessFinding, error) {
+	components := Components(inv)
+	byKey := map[string]Component{}
+	deps := []source.Dependency{}
+	for _, c := range components {
+		byKey[c.Ecosystem+"/"+c.Name+"@"+c.Version] = c
+		deps = append(deps, source.Dependency{Name: c.Name, Version: c.Version, Ecosystem: c.Ecosystem})
+	}
+	findings, err := QueryDependencies(deps, opts...)
+	if err != nil {
+		return nil, err
+	}
+	pfs := []ProcessFinding{}
+	for _, f := range findings {
+		d := f.Dependency
+		pfs = append(pfs, ProcessFinding{
+			Component:     byKey[d.Ecosystem+"/"+d.Name+"@"+d.Version],
+			Vulnerability: f.Vulnerability,
+			FixedVersions: f.FixedVersions,
+		})
+	}
+	return pfs, nil
+}
+
+// ParseSeverity returns the normalized severity of s, such as high, or an
+// error when it isn't a severity reported by OSV.
+func ParseSeverity(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if _, ok := severityRanks[s]; !ok {
+		return "", fmt.Errorf("unknown severity %q; expected low, moderate, high, or critical", s)
+	}
+	if s == "medium" {
+		s = "moderate"
+	}
+	return s, nil
+}
+
+// AtLeast returns whether the severity of v is at least min, as returned by
+// [ParseSeverity]. Vulnerabilities whose source reports no severity, such as
+// those of the Go vulnerability database, never are.
+func (v Vulnerability) AtLeast(min string) bool {
+	rank, ok := severityRanks[strings.ToLower(v.Severity)]
+	return ok && rank >= severityRanks[min]
+}
+
+// osvEcosystem returns the OSV ecosystem of the packages of os, such as
+// Debian:12, or an empty string when OSV doesn't publish its advisories.
+func osvEcosystem(os host.OS) string {
+	switch os.Name {
+	case "debian":
+		if os.VersionID != "" {
+			return "Debian:" + os.VersionID
+		}
+	case "alpine":
+		// Alpine's ecosystems are per release branch, e.g. v3.18 for 3.18.4.
+		parts := strings.Split(os.VersionID, ".")
+		if len(parts) >= 2 {
+			return fmt.Sprintf("Alpine:v%s.%s", parts[0], parts[1])
+		}
+	case "ubuntu":
+		if os.VersionID == "" {
+			return ""
+		}
+		if strings.Contains(os.Version, "LTS") {
+			return "Ubuntu:" + os.VersionID + ":LTS"
+		}
+		return "Ubuntu:" + os.VersionID
+	}
+	return ""
+}
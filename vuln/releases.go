@@ -0,0 +1,85 @@
+package vuln
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arctir/proctor/platforms"
+	"github.com/arctir/proctor/source"
+)
+
+// AffectedRelease is a release of a repository affected by a security
+// advisory.
+type AffectedRelease struct {
+	Advisory platforms.Advisory
+	// The releases the advisory affects, sorted by semantic version.
+	Releases []source.Tag
+}
+
+// AffectedReleases returns, for each advisory, the tags in tags whose version
+// is within the vulnerable versions of any package the advisory affects. Tags
+// that are not semantic versions are ignored. An advisory affecting no tags is
+// still returned, with no releases.
+func AffectedReleases(advisories []platforms.Advisory, tags []source.Tag) ([]AffectedRelease, error) {
+	sorted := source.SortTagsBySemver(tags)
+	affected := []AffectedRelease{}
+	for _, a := range advisories {
+		ar := AffectedRelease{Advisory: a, Releases: []source.Tag{}}
+		for _, t := range sorted {
+			for _, p := range a.Affected {
+				ok, err := InRange(t.Name, p.VulnerableVersions)
+				if err != nil {
+					return nil, fmt.Errorf("failed checking advisory (%s): %s", a.ID, err)
+				}
+				if ok {
+					ar.Releases = append(ar.Releases, t)
+					break
+				}
+			}
+		}
+		affected = append(affected, ar)
+	}
+	return affected, nil
+}
+
+// InRange returns whether version is within versionRange, a comma separated
+// list of constraints that must all be met, such as ">= 1.0.0, < 1.2.3".
+// Constraints use the operators =, <, <=, >, and >=; a version without an
+// operator must match exactly. An empty range matches no versions.
+func InRange(version string, versionRange string) (bool, error) {
+	if strings.TrimSpace(versionRange) == "" {
+		return false, nil
+	}
+	v, err := source.ParseSemver(version)
+	if err != nil {
+		return false, err
+	}
+	for _, constraint := range strings.Split(versionRange, ",") {
+		constraint = strings.TrimSpace(constraint)
+		op := constraint[:len(constraint)-len(strings.TrimLeft(constraint, "<>="))]
+		bound, err := source.ParseSemver(strings.TrimSpace(constraint[len(op):]))
+		if err != nil {
+			return false, fmt.Errorf("invalid version range (%s): %s", versionRange, err)
+		}
+		c := v.Compare(bound)
+		var ok bool
+		switch op {
+		case "", "=", "==":
+			ok = c == 0
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		default:
+			return false, fmt.Errorf("invalid version range (%s): unknown operator (%s)", versionRange, op)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
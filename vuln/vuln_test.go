@@ -0,0 +1,196 @@
+package vuln
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/arctir/proctor/host"
+	"github.com/arctir/proctor/platforms"
+	"github.com/arctir/proctor/provenance"
+	"github.com/arctir/proctor/sbom"
+	"github.com/arctir/proctor/source"
+)
+
+func TestQueryVersion(t *testing.T) {
+	tests := map[string]struct {
+		dep      source.Dependency
+		expected string
+		pinned   bool
+	}{
+		"go":                {source.Dependency{Ecosystem: source.GoEcosystem, Version: "v1.2.3"}, "1.2.3", true},
+		"npm exact":         {source.Dependency{Ecosystem: source.NPMEcosystem, Version: "4.17.21"}, "4.17.21", true},
+		"npm caret":         {source.Dependency{Ecosystem: source.NPMEcosystem, Version: "^4.0.0"}, "", false},
+		"npm wildcard":      {source.Dependency{Ecosystem: source.NPMEcosystem, Version: "1.x"}, "", false},
+		"pypi pinned":       {source.Dependency{Ecosystem: source.PyPIEcosystem, Version: "2.28.1"}, "2.28.1", true},
+		"pypi constraint":   {source.Dependency{Ecosystem: source.PyPIEcosystem, Version: ">=2.8"}, "", false},
+		"unconstrained":     {source.Dependency{Ecosystem: source.PyPIEcosystem}, "", false},
+		"unknown ecosystem": {source.Dependency{Ecosystem: "crates.io", Version: "1.0.0"}, "", false},
+		"debian":            {source.Dependency{Ecosystem: "Debian:12", Version: "1:9.0~rc1-2+b1"}, "1:9.0~rc1-2+b1", true},
+	}
+	for name, test := range tests {
+		version, pinned := QueryVersion(test.dep)
+		if version != test.expected || pinned != test.pinned {
+			t.Logf("fail: %s: expected (%s, %t), actual: (%s, %t)", name, test.expected, test.pinned, version, pinned)
+			t.Fail()
+		}
+	}
+}
+
+func TestQueryDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			req := struct {
+				Queries []osvQuery `json:"queries"`
+			}{}
+			json.NewDecoder(r.Body).Decode(&req)
+			if len(req.Queries) != 2 {
+				t.Logf("fail: expected 2 queries, actual: %+v", req.Queries)
+				t.Fail()
+			}
+			w.Write([]byte(`{"results": [{"vulns": [{"id": "GO-2022-0001"}]}, {}]}`))
+		case "/v1/vulns/GO-2022-0001":
+			w.Write([]byte(`{
+				"id": "GO-2022-0001",
+				"summary": "Denial of service",
+				"aliases": ["CVE-2022-1234"],
+				"affected": [{"package": {"name": "golang.org/x/net", "ecosystem": "Go"}, "ranges": [{"events": [{"introduced": "0"}, {"fixed": "0.7.0"}]}]}]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	deps := []source.Dependency{
+		{Name: "golang.org/x/net", Version: "v0.5.0", Ecosystem: source.GoEcosystem},
+		{Name: "lodash", Version: "^4.0.0", Ecosystem: source.NPMEcosystem},
+		{Name: "requests", Version: "2.28.1", Ecosystem: source.PyPIEcosystem},
+	}
+	findings, err := QueryDependencies(deps, QueryOpts{OSVURL: server.URL})
+	if err != nil {
+		t.Fatalf("fail: unexpected error querying OSV: %s", err)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("fail: expected 1 finding, actual: %+v", findings)
+	}
+	f := findings[0]
+	if f.Dependency.Name != "golang.org/x/net" || f.Vulnerability.ID != "GO-2022-0001" || len(f.FixedVersions) != 1 || f.FixedVersions[0] != "0.7.0" {
+		t.Logf("fail: finding was wrong: %+v", f)
+		t.Fail()
+	}
+}
+
+func TestInRange(t *testing.T) {
+	tests := map[string]struct {
+		version  string
+		rng      string
+		expected bool
+	}{
+		"below upper bound":   {"v1.2.2", ">= 1.0.0, < 1.2.3", true},
+		"at upper bound":      {"v1.2.3", ">= 1.0.0, < 1.2.3", false},
+		"below lower bound":   {"v0.9.0", ">= 1.0.0, < 1.2.3", false},
+		"inclusive bound":     {"1.2.3", "<= 1.2.3", true},
+		"exact":               {"v2.0.0", "= 2.0.0", true},
+		"without operator":    {"v2.0.1", "2.0.0", false},
+		"empty range":         {"v1.0.0", "", false},
+		"pre-release is less": {"v1.2.3-rc.1", "< 1.2.3", true},
+	}
+	for name, test := range tests {
+		ok, err := InRange(test.version, test.rng)
+		if err != nil {
+			t.Logf("fail: %s: unexpected error: %s", name, err)
+			t.Fail()
+			continue
+		}
+		if ok != test.expected {
+			t.Logf("fail: %s: expected %t, actual: %t", name, test.expected, ok)
+			t.Fail()
+		}
+	}
+	if _, err := InRange("v1.0.0", "~> 1.0"); err == nil {
+		t.Log("fail: expected error for an unknown operator")
+		t.Fail()
+	}
+}
+
+func TestAffectedReleases(t *testing.T) {
+	advisories := []platforms.Advisory{{
+		ID:       "GHSA-aaaa-bbbb-cccc",
+		Affected: []platforms.AffectedPackage{{VulnerableVersions: ">= 0.2.0, < 0.3.1"}},
+	}}
+	tags := []source.Tag{{Name: "v0.3.1"}, {Name: "v0.1.0"}, {Name: "v0.3.0"}, {Name: "latest"}, {Name: "v0.2.0"}}
+	affected, err := AffectedReleases(advisories, tags)
+	if err != nil {
+		t.Fatalf("fail: unexpected error finding affected releases: %s", err)
+	}
+	releases := affected[0].Releases
+	if len(releases) != 2 || releases[0].Name != "v0.2.0" || releases[1].Name != "v0.3.0" {
+		t.Logf("fail: affected releases were wrong: %+v", releases)
+		t.Fail()
+	}
+}
+
+func TestQueryProcesses(t *testing.T) {
+	inv := sbom.Inventory{
+		OS: host.OS{Name: "debian", Version: "12 (bookworm)", VersionID: "12"},
+		Binaries: []sbom.Binary{
+			{Path: "/usr/bin/agent", PIDs: []int{10, 11}, BuildInfo: &provenance.BuildInfo{
+				GoVersion:     "go1.20.1",
+				ModulePath:    "github.com/example/agent",
+				ModuleVersion: "(devel)",
+				Deps:          []provenance.Module{{Path: "golang.org/x/net", Version: "v0.5.0"}},
+			}},
+			{Path: "/usr/sbin/sshd", PIDs: []int{700}, Package: "openssh-server"},
+		},
+		Packages: []host.Package{{Manager: host.DpkgManager, Name: "openssh-server", Source: "openssh", Version: "1:9.2p1-2"}},
+	}
+	components := Components(inv)
+	names := []string{}
+	for _, c := range components {
+		names = append(names, c.Ecosystem+"/"+c.Name+"@"+c.Version)
+	}
+	expected := []string{"Debian:12/openssh@1:9.2p1-2", "Go/golang.org/x/net@v0.5.0", "Go/stdlib@1.20.1"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("fail: expected components %v, actual: %v", expected, names)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			w.Write([]byte(`{"results": [{"vulns": [{"id": "DSA-5000-1"}]}, {"vulns": [{"id": "GHSA-xxxx"}]}, {}]}`))
+		case "/v1/vulns/DSA-5000-1":
+			w.Write([]byte(`{"id": "DSA-5000-1", "affected": [{"package": {"name": "openssh", "ecosystem": "Debian:12"}, "ranges": [{"events": [{"introduced": "0"}, {"fixed": "1:9.2p1-2+deb12u1"}]}]}]}`))
+		case "/v1/vulns/GHSA-xxxx":
+			w.Write([]byte(`{"id": "GHSA-xxxx", "database_specific": {"severity": "HIGH"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	findings, err := QueryProcesses(inv, QueryOpts{OSVURL: server.URL})
+	if err != nil {
+		t.Fatalf("fail: unexpected error querying OSV: %s", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("fail: expected 2 findings, actual: %+v", findings)
+	}
+	sshd := findings[1]
+	if sshd.Component.Name != "openssh" || !reflect.DeepEqual(sshd.Component.PIDs, []int{700}) || sshd.FixedVersions[0] != "1:9.2p1-2+deb12u1" {
+		t.Logf("fail: sshd finding was wrong: %+v", sshd)
+		t.Fail()
+	}
+	agent := findings[0]
+	if !reflect.DeepEqual(agent.Component.Binaries, []string{"/usr/bin/agent"}) || !reflect.DeepEqual(agent.Component.PIDs, []int{10, 11}) {
+		t.Logf("fail: agent finding was wrong: %+v", agent)
+		t.Fail()
+	}
+	if min, _ := ParseSeverity("medium"); !agent.Vulnerability.AtLeast(min) || agent.Vulnerability.AtLeast("critical") || sshd.Vulnerability.AtLeast("low") {
+		t.Log("fail: severity filtering was wrong")
+		t.Fail()
+	}
+}